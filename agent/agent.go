@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/schema"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/processors"
@@ -22,6 +23,14 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// leader is set if leader election is required for inputs marked as
+	// cluster singletons
+	leader *leaderElector
+
+	// draining is closed when the inputs start draining on shutdown to let
+	// the outputs flush more often
+	draining chan struct{}
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -46,6 +55,7 @@ func NewAgent(cfg *config.Config) *Agent {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+	spools map[*models.RunningInput]*spool
 }
 
 //  ______     ┌───────────┐     ______
@@ -124,7 +134,18 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	singletons, err := a.checkClusterSingletons(false)
+	if err != nil {
+		return err
+	}
+	if singletons {
+		if a.leader, err = newLeaderElector(a.Config.Agent); err != nil {
+			return err
+		}
+	}
+
 	startTime := time.Now()
+	a.draining = make(chan struct{})
 
 	log.Printf("D! [agent] Connecting outputs")
 	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
@@ -154,8 +175,27 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	var cu *clockSkewUnit
+	if a.Config.Agent.ClockSkewSource != "" {
+		next, cu, err = a.startClockSkew(next)
+		if err != nil {
+			return err
+		}
+	}
+
+	var status *statusServer
+	if a.Config.Agent.StatusAddress != "" {
+		log.Printf("D! [agent] Serving status on %q", a.Config.Agent.StatusAddress)
+		if status, err = newStatusServer(a.Config.Agent.StatusAddress, a.Config.Inputs); err != nil {
+			return fmt.Errorf("starting status server failed: %w", err)
+		}
+	}
+
 	iu, err := a.startInputs(next, a.Config.Inputs)
 	if err != nil {
+		if status != nil {
+			status.listener.Close()
+		}
 		return err
 	}
 
@@ -166,6 +206,22 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.runOutputs(ou)
 	}()
 
+	if a.leader != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.leader.run(ctx)
+		}()
+	}
+
+	if status != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.run(ctx)
+		}()
+	}
+
 	if au != nil {
 		wg.Add(1)
 		go func() {
@@ -188,6 +244,14 @@ func (a *Agent) Run(ctx context.Context) error {
 		}()
 	}
 
+	if cu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runClockSkew(ctx, cu)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -230,6 +294,9 @@ func (a *Agent) InitPlugins() error {
 		if err != nil {
 			return fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err)
 		}
+		if aggregator.Config.PersistState && a.Config.Persister == nil {
+			return fmt.Errorf("could not initialize aggregator %s: 'persist_state' requires the 'statefile' agent setting", aggregator.LogName())
+		}
 	}
 	for _, processor := range a.Config.AggProcessors {
 		err := processor.Init()
@@ -289,7 +356,11 @@ func (a *Agent) initPersister() error {
 	for _, aggregator := range a.Config.Aggregators {
 		plugin, ok := aggregator.Aggregator.(telegraf.StatefulPlugin)
 		if !ok {
-			continue
+			// Persist the metrics of the current period instead if requested
+			if !aggregator.Config.PersistState {
+				continue
+			}
+			plugin = aggregator
 		}
 
 		name := aggregator.LogName()
@@ -335,7 +406,21 @@ func (a *Agent) startInputs(
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:    dst,
+		spools: make(map[*models.RunningInput]*spool),
+	}
+
+	for _, input := range inputs {
+		if input.Config.Spool {
+			if _, ok := input.Input.(telegraf.ServiceInput); ok {
+				return nil, fmt.Errorf("input %s: 'spool' not supported for service inputs", input.LogName())
+			}
+			s, err := newSpool(input, a.Config.Agent.BufferDirectory, dst, a.outputCapacity)
+			if err != nil {
+				return nil, fmt.Errorf("input %s: %w", input.LogName(), err)
+			}
+			unit.spools[input] = s
+		}
 	}
 
 	for _, input := range inputs {
@@ -416,7 +501,11 @@ func (a *Agent) runInputs(
 		}
 		tickers = append(tickers, ticker)
 
-		acc := NewAccumulator(input, unit.dst)
+		dst := unit.dst
+		if s, found := unit.spools[input]; found {
+			dst = s.src
+		}
+		acc := NewAccumulator(input, dst)
 		acc.SetPrecision(getPrecision(precision, interval))
 
 		wg.Add(1)
@@ -425,12 +514,31 @@ func (a *Agent) runInputs(
 			a.gatherLoop(ctx, acc, input, ticker, interval)
 		}(input)
 	}
+
+	var spoolWG sync.WaitGroup
+	for _, s := range unit.spools {
+		spoolWG.Add(1)
+		go func(s *spool) {
+			defer spoolWG.Done()
+			s.run()
+		}(s)
+	}
+
 	defer stopTickers(tickers)
 	wg.Wait()
 
+	if timeout := time.Duration(a.Config.Agent.ShutdownDrainTimeout); timeout > 0 {
+		a.drainInputs(unit.inputs, timeout)
+	}
+
 	log.Printf("D! [agent] Stopping service inputs")
 	stopRunningInputs(unit.inputs)
 
+	for _, s := range unit.spools {
+		close(s.src)
+	}
+	spoolWG.Wait()
+
 	close(unit.dst)
 	log.Printf("D! [agent] Input channel closed")
 }
@@ -560,6 +668,10 @@ func (a *Agent) gatherLoop(
 	for {
 		select {
 		case <-ticker.Elapsed():
+			// Only the leader gathers inputs marked as cluster singleton
+			if input.Config.ClusterSingleton && a.leader != nil && !a.leader.isLeader() {
+				continue
+			}
 			err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
@@ -605,6 +717,47 @@ func (a *Agent) gatherOnce(
 	}
 }
 
+// startClockSkew sets up the clock skew detection between the inputs and
+// the given destination channel.
+func (a *Agent) startClockSkew(dst chan<- telegraf.Metric) (chan<- telegraf.Metric, *clockSkewUnit, error) {
+	monitor, err := newClockSkewMonitor(a.Config.Agent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src := make(chan telegraf.Metric, 100)
+	unit := &clockSkewUnit{
+		src:     src,
+		dst:     dst,
+		monitor: monitor,
+	}
+	return src, unit, nil
+}
+
+// runClockSkew periodically checks the clock offset and applies the
+// configured action to the metrics passing the unit. The destination channel
+// is closed when the source channel is closed.
+func (*Agent) runClockSkew(ctx context.Context, unit *clockSkewUnit) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		unit.monitor.run(ctx)
+	}()
+
+	for m := range unit.src {
+		unit.dst <- unit.monitor.apply(m)
+	}
+	close(unit.dst)
+
+	cancel()
+	wg.Wait()
+	log.Printf("D! [agent] Clock skew channel closed")
+}
+
 // startProcessors sets up the processor chain and calls Start on all
 // processors.  If an error occurs any started processors are Stopped.
 func (a *Agent) startProcessors(
@@ -699,6 +852,9 @@ func (a *Agent) runAggregators(
 	// that any metric created after start time will be aggregated.
 	for _, agg := range a.Config.Aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
+		if agg.Config.CalendarPeriod != "" {
+			since, until = agg.CalendarWindow(startTime)
+		}
 		agg.UpdateWindow(since, until)
 	}
 
@@ -779,6 +935,12 @@ func (a *Agent) push(
 		case <-time.After(until):
 			aggregator.Push(acc)
 		case <-ctx.Done():
+			// Keep the partial period if it is persisted across restarts
+			if aggregator.Config.PersistState && a.Config.Persister != nil {
+				if _, ok := aggregator.Aggregator.(telegraf.StatefulPlugin); !ok {
+					return
+				}
+			}
 			aggregator.Push(acc)
 			return
 		}
@@ -867,7 +1029,7 @@ func (a *Agent) runOutputs(
 			ticker := NewRollingTicker(interval, jitter)
 			defer ticker.Stop()
 
-			a.flushLoop(ctx, output, ticker)
+			a.flushLoop(ctx, output, ticker, a.draining)
 		}(output)
 	}
 
@@ -895,6 +1057,7 @@ func (a *Agent) flushLoop(
 	ctx context.Context,
 	output *models.RunningOutput,
 	ticker Ticker,
+	draining <-chan struct{},
 ) {
 	logError := func(err error) {
 		if err != nil {
@@ -907,6 +1070,16 @@ func (a *Agent) flushLoop(
 	watchForFlushSignal(flushRequested)
 	defer stopListeningForFlushSignal(flushRequested)
 
+	// Pending write of immediate metrics, nil if there is none
+	var immediate <-chan time.Time
+	immediateDelay := time.Duration(a.Config.Agent.ImmediateFlushDelay)
+
+	// Frequent flushes while draining inputs, nil if not draining
+	var drainFlush <-chan time.Time
+	drainTicker := time.NewTicker(drainFlushInterval)
+	drainTicker.Stop()
+	defer drainTicker.Stop()
+
 	for {
 		// Favor shutdown over other methods.
 		select {
@@ -926,6 +1099,23 @@ func (a *Agent) flushLoop(
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-output.BatchReady:
 			logError(a.flushBatch(output, output.WriteBatch))
+		case <-output.ImmediateReady:
+			// Collect further immediate metrics arriving within the delay
+			if immediate == nil {
+				immediate = time.After(immediateDelay)
+			}
+		case <-immediate:
+			immediate = nil
+			logError(a.flushBatch(output, output.Write))
+		case <-draining:
+			draining = nil
+			drainTicker.Reset(drainFlushInterval)
+			drainFlush = drainTicker.C
+			logError(a.flushOnce(output, ticker, output.Write))
+		case <-drainFlush:
+			if output.BufferLength() > 0 {
+				logError(a.flushOnce(output, ticker, output.Write))
+			}
 		}
 	}
 }
@@ -998,6 +1188,49 @@ func (a *Agent) Test(ctx context.Context, wait time.Duration) error {
 	return nil
 }
 
+// Schema runs the agent for a single gather like Test and returns the schema
+// of the configured inputs and the metrics observed after processing.
+func (a *Agent) Schema(ctx context.Context, wait time.Duration) (*schema.Schema, error) {
+	collector := schema.NewCollector()
+
+	src := make(chan telegraf.Metric, 100)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for metric := range src {
+			collector.Add(metric)
+			metric.Reject()
+		}
+	}()
+
+	if err := a.runTest(ctx, wait, src); err != nil {
+		return nil, err
+	}
+	wg.Wait()
+
+	s := &schema.Schema{
+		GlobalTags:   a.Config.Tags,
+		Plugins:      make([]schema.Plugin, 0, len(a.Config.Inputs)),
+		Measurements: collector.Measurements(),
+	}
+	for _, input := range a.Config.Inputs {
+		s.Plugins = append(s.Plugins, schema.Plugin{
+			Name:              input.Config.Name,
+			Alias:             input.Config.Alias,
+			NameOverride:      input.Config.NameOverride,
+			MeasurementPrefix: input.Config.MeasurementPrefix,
+			MeasurementSuffix: input.Config.MeasurementSuffix,
+			Tags:              input.Config.Tags,
+		})
+	}
+
+	if models.GlobalGatherErrors.Get() != 0 {
+		return s, fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	}
+	return s, nil
+}
+
 // runTest runs the agent and performs a single gather sending output to the
 // outputC. After gathering pauses for the wait duration to allow service
 // inputs to run.
@@ -1007,6 +1240,10 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 		return err
 	}
 
+	if _, err := a.checkClusterSingletons(true); err != nil {
+		return err
+	}
+
 	startTime := time.Now()
 
 	next := outputC
@@ -1103,6 +1340,10 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
+	if _, err := a.checkClusterSingletons(true); err != nil {
+		return err
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
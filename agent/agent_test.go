@@ -14,7 +14,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
@@ -30,6 +32,21 @@ func TestAgent_OmitHostname(t *testing.T) {
 	require.NotContains(t, c.Tags, "host")
 }
 
+func TestAgent_PersistStateRequiresStatefile(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[aggregators.minmax]]
+  period = "1h"
+  persist_state = true
+`)))
+	a := NewAgent(c)
+	require.ErrorContains(t, a.InitPlugins(), "'persist_state' requires the 'statefile' agent setting")
+
+	c.Agent.Statefile = filepath.Join(t.TempDir(), "state.json")
+	c.Persister = &persister.Persister{Filename: c.Agent.Statefile}
+	require.NoError(t, NewAgent(c).InitPlugins())
+}
+
 func TestAgent_LoadPlugin(t *testing.T) {
 	c := config.NewConfig()
 	c.InputFilters = []string{"mysql"}
@@ -119,6 +136,63 @@ func TestAgent_LoadOutput(t *testing.T) {
 	require.Len(t, a.Config.Outputs, 3)
 }
 
+type recordingOutput struct {
+	sync.Mutex
+	metrics []telegraf.Metric
+}
+
+func (*recordingOutput) SampleConfig() string { return "" }
+func (*recordingOutput) Connect() error       { return nil }
+func (*recordingOutput) Close() error         { return nil }
+
+func (o *recordingOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *recordingOutput) count() int {
+	o.Lock()
+	defer o.Unlock()
+	return len(o.metrics)
+}
+
+func TestFlushLoopImmediate(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.ImmediateFlushDelay = config.Duration(10 * time.Millisecond)
+	a := NewAgent(c)
+
+	plugin := &recordingOutput{}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "recording"}, 1000, 10000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := NewRollingTicker(time.Hour, 0)
+		defer ticker.Stop()
+		a.flushLoop(ctx, output, ticker, nil)
+	}()
+
+	// Regular metrics wait for the flush interval
+	output.AddMetric(testutil.TestMetric(1, "bulk"))
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, plugin.count())
+
+	// Immediate metrics are written together with the buffered ones
+	m := testutil.TestMetric(2, "alert")
+	metric.SetImmediate(m, true)
+	output.AddMetric(m)
+	require.Eventually(t, func() bool {
+		return plugin.count() == 2
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Seconds between the NTP epoch (1900) and the Unix epoch (1970)
+const ntpEpochOffset = 2208988800
+
+// clockSkewUnit is a pipeline stage between the inputs and the processors
+// checking the system clock offset against a reference and tagging or
+// correcting the metric timestamps if the offset exceeds the threshold.
+//
+//  ______     ┌────────────┐     ______
+// ()_____)──▶ │ Clock skew │──▶ ()_____)
+//             └────────────┘
+
+type clockSkewUnit struct {
+	src     <-chan telegraf.Metric
+	dst     chan<- telegraf.Metric
+	monitor *clockSkewMonitor
+}
+
+type clockSkewMonitor struct {
+	source    string
+	server    string
+	interval  time.Duration
+	threshold time.Duration
+	action    string
+
+	// offset of the system clock to the reference in nanoseconds,
+	// positive values indicate the system clock running behind
+	offset atomic.Int64
+	skewed atomic.Bool
+	query  func() (time.Duration, error)
+
+	offsetStat selfstat.Stat
+}
+
+func newClockSkewMonitor(cfg *config.AgentConfig) (*clockSkewMonitor, error) {
+	c := &clockSkewMonitor{
+		source:    cfg.ClockSkewSource,
+		server:    cfg.ClockSkewServer,
+		interval:  time.Duration(cfg.ClockSkewCheckInterval),
+		threshold: time.Duration(cfg.ClockSkewThreshold),
+		action:    cfg.ClockSkewAction,
+		offsetStat: selfstat.Register("agent", "clock_offset_ns", map[string]string{
+			"source": cfg.ClockSkewSource,
+		}),
+	}
+
+	switch c.source {
+	case "ntp":
+		if c.server == "" {
+			c.server = "pool.ntp.org"
+		}
+		c.query = func() (time.Duration, error) {
+			return queryNTPOffset(c.server, 5*time.Second)
+		}
+	case "kernel":
+		c.query = queryKernelOffset
+	default:
+		return nil, fmt.Errorf("invalid clock skew source %q", c.source)
+	}
+
+	switch c.action {
+	case "":
+		c.action = "tag"
+	case "tag", "correct":
+	default:
+		return nil, fmt.Errorf("invalid clock skew action %q", c.action)
+	}
+
+	if c.interval <= 0 {
+		c.interval = 5 * time.Minute
+	}
+	if c.threshold <= 0 {
+		c.threshold = time.Second
+	}
+
+	return c, nil
+}
+
+// run periodically updates the clock offset until the context is done.
+func (c *clockSkewMonitor) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.update()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *clockSkewMonitor) update() {
+	offset, err := c.query()
+	if err != nil {
+		log.Printf("W! [agent] Determining clock offset via %s failed: %v", c.source, err)
+		return
+	}
+	c.offset.Store(int64(offset))
+	c.offsetStat.Set(int64(offset))
+
+	skewed := offset.Abs() > c.threshold
+	if skewed != c.skewed.Swap(skewed) {
+		if skewed {
+			log.Printf("W! [agent] Clock offset of %s exceeds threshold of %s", offset, c.threshold)
+		} else {
+			log.Printf("I! [agent] Clock offset of %s back within threshold of %s", offset, c.threshold)
+		}
+	}
+}
+
+// apply tags or corrects the given metric if the clock is skewed.
+func (c *clockSkewMonitor) apply(m telegraf.Metric) telegraf.Metric {
+	if !c.skewed.Load() {
+		return m
+	}
+
+	switch c.action {
+	case "tag":
+		m.AddTag("clock_skewed", "true")
+	case "correct":
+		m.SetTime(m.Time().Add(time.Duration(c.offset.Load())))
+	}
+	return m
+}
+
+// queryNTPOffset determines the offset of the local clock to the given NTP
+// server using a single SNTP request (RFC 4330).
+func queryNTPOffset(server string, timeout time.Duration) (time.Duration, error) {
+	addr := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		addr = net.JoinHostPort(server, "123")
+	}
+
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	// Leap indicator 0, version 4, client mode
+	request := make([]byte, 48)
+	request[0] = 0x23
+
+	originate := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, err
+	}
+	destination := time.Now()
+
+	if n < 48 {
+		return 0, fmt.Errorf("short NTP response of %d bytes", n)
+	}
+	if mode := response[0] & 0x07; mode != 4 {
+		return 0, fmt.Errorf("unexpected NTP mode %d", mode)
+	}
+	if stratum := response[1]; stratum == 0 {
+		return 0, errors.New("received kiss-of-death NTP response")
+	}
+
+	receive := fromNTPTimestamp(response[32:40])
+	transmit := fromNTPTimestamp(response[40:48])
+
+	return (receive.Sub(originate) + transmit.Sub(destination)) / 2, nil
+}
+
+func fromNTPTimestamp(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+	fraction := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(seconds, (fraction*1e9)>>32)
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package agent
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// queryKernelOffset returns the clock offset estimated by the kernel's time
+// synchronization (e.g. as disciplined by chronyd or ntpd).
+func queryKernelOffset() (time.Duration, error) {
+	var tx unix.Timex
+	state, err := unix.Adjtimex(&tx)
+	if err != nil {
+		return 0, err
+	}
+	if state == unix.TIME_ERROR || tx.Status&unix.STA_UNSYNC != 0 {
+		return 0, errors.New("kernel clock is not synchronized")
+	}
+
+	// The offset is in microseconds unless nanosecond resolution is enabled
+	if tx.Status&unix.STA_NANO != 0 {
+		return time.Duration(tx.Offset), nil
+	}
+	return time.Duration(tx.Offset) * time.Microsecond, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package agent
+
+import (
+	"errors"
+	"time"
+)
+
+func queryKernelOffset() (time.Duration, error) {
+	return 0, errors.New("kernel clock offset is only supported on Linux")
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestClockSkewInvalidSettings(t *testing.T) {
+	_, err := newClockSkewMonitor(&config.AgentConfig{ClockSkewSource: "gps"})
+	require.ErrorContains(t, err, "invalid clock skew source")
+
+	_, err = newClockSkewMonitor(&config.AgentConfig{
+		ClockSkewSource: "ntp",
+		ClockSkewAction: "drop",
+	})
+	require.ErrorContains(t, err, "invalid clock skew action")
+}
+
+func TestClockSkewApply(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name         string
+		action       string
+		offset       time.Duration
+		expectedTags map[string]string
+		expectedTime time.Time
+	}{
+		{
+			name:         "within threshold",
+			action:       "tag",
+			offset:       500 * time.Millisecond,
+			expectedTags: map[string]string{},
+			expectedTime: ts,
+		},
+		{
+			name:         "tag",
+			action:       "tag",
+			offset:       -5 * time.Second,
+			expectedTags: map[string]string{"clock_skewed": "true"},
+			expectedTime: ts,
+		},
+		{
+			name:         "correct",
+			action:       "correct",
+			offset:       -5 * time.Second,
+			expectedTags: map[string]string{},
+			expectedTime: ts.Add(-5 * time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := newClockSkewMonitor(&config.AgentConfig{
+				ClockSkewSource:    "kernel",
+				ClockSkewThreshold: config.Duration(time.Second),
+				ClockSkewAction:    tt.action,
+			})
+			require.NoError(t, err)
+			monitor.query = func() (time.Duration, error) { return tt.offset, nil }
+			monitor.update()
+
+			m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, ts)
+			m = monitor.apply(m)
+			require.Equal(t, tt.expectedTags, m.Tags())
+			require.Equal(t, tt.expectedTime, m.Time())
+		})
+	}
+}
+
+func TestClockSkewNTPQuery(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Fake server running 10 seconds ahead of the local clock
+	go func() {
+		buf := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		now := time.Now().Add(10 * time.Second)
+		response := make([]byte, 48)
+		response[0] = 0x24 // version 4, server mode
+		response[1] = 2    // stratum
+		copy(response[32:40], ntpTimestamp(now))
+		copy(response[40:48], ntpTimestamp(now))
+		_, _ = conn.WriteTo(response, addr)
+	}()
+
+	offset, err := queryNTPOffset(conn.LocalAddr().String(), time.Second)
+	require.NoError(t, err)
+	require.InDelta(t, float64(10*time.Second), float64(offset), float64(100*time.Millisecond))
+}
+
+func ntpTimestamp(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/1e9))
+	return b
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// drainFlushInterval is the interval of flushing the outputs while draining
+// the inputs to deliver the outstanding metrics as fast as possible
+const drainFlushInterval = time.Second
+
+// drainInputs lets all inputs supporting it stop consuming new data and waits
+// at most the given timeout for the metrics of the consumed data to be
+// delivered. The outputs are flushed frequently during this phase.
+func (a *Agent) drainInputs(inputs []*models.RunningInput, timeout time.Duration) {
+	var drainable []*models.RunningInput
+	for _, input := range inputs {
+		if _, ok := input.Input.(telegraf.DrainableInput); ok {
+			drainable = append(drainable, input)
+		}
+	}
+	if len(drainable) == 0 {
+		return
+	}
+
+	log.Printf("I! [agent] Draining %d input(s), waiting up to %s for outstanding deliveries", len(drainable), timeout)
+	if a.draining != nil {
+		close(a.draining)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, input := range drainable {
+		wg.Add(1)
+		go func(input *models.RunningInput) {
+			defer wg.Done()
+			start := time.Now()
+			if err := input.Input.(telegraf.DrainableInput).Drain(ctx); err != nil {
+				log.Printf("W! [agent] Draining %s failed: %v", input.LogName(), err)
+				return
+			}
+			log.Printf("D! [agent] Drained %s in %s", input.LogName(), time.Since(start))
+		}(input)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// leaderElector determines if this agent is the leader of the fleet and thus
+// responsible for gathering inputs marked as cluster singletons.
+type leaderElector struct {
+	identity string
+	leader   atomic.Bool
+	run      func(ctx context.Context)
+
+	leaderStat selfstat.Stat
+}
+
+func newLeaderElector(cfg *config.AgentConfig) (*leaderElector, error) {
+	e := &leaderElector{
+		identity: cfg.Hostname,
+		leaderStat: selfstat.Register("agent", "leader", map[string]string{
+			"leader_election": cfg.LeaderElection,
+		}),
+	}
+	if e.identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		e.identity = hostname
+	}
+
+	duration := time.Duration(cfg.LeaderElectionLeaseDuration)
+	if duration <= 0 {
+		duration = 15 * time.Second
+	}
+
+	switch cfg.LeaderElection {
+	case "kubernetes":
+		run, err := e.kubernetes(cfg.LeaderElectionLease, duration)
+		if err != nil {
+			return nil, err
+		}
+		e.run = run
+	default:
+		return nil, fmt.Errorf("invalid leader election %q", cfg.LeaderElection)
+	}
+
+	return e, nil
+}
+
+// isLeader returns true if this agent currently holds the leadership
+func (e *leaderElector) isLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *leaderElector) setLeader(leader bool) {
+	if leader == e.leader.Swap(leader) {
+		return
+	}
+	if leader {
+		log.Printf("I! [agent] Became leader as %q, starting cluster singleton inputs", e.identity)
+		e.leaderStat.Set(1)
+	} else {
+		log.Printf("I! [agent] Lost leadership as %q, pausing cluster singleton inputs", e.identity)
+		e.leaderStat.Set(0)
+	}
+}
+
+// kubernetes sets up leader election using a Kubernetes Lease object given
+// as "namespace/name" or "name". The namespace defaults to the namespace of
+// the pod's service account.
+func (e *leaderElector) kubernetes(lease string, duration time.Duration) (func(context.Context), error) {
+	namespace, name, found := strings.Cut(lease, "/")
+	if !found {
+		namespace, name = "", lease
+	}
+	if name == "" {
+		name = "telegraf"
+	}
+	if namespace == "" {
+		namespace = "default"
+		if buf, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(buf))
+		}
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating Kubernetes client config failed: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating Kubernetes client failed: %w", err)
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: e.identity},
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   duration,
+		RenewDeadline:   duration * 2 / 3,
+		RetryPeriod:     duration / 5,
+		ReleaseOnCancel: true,
+		Name:            namespace + "/" + name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { e.setLeader(true) },
+			OnStoppedLeading: func() { e.setLeader(false) },
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating leader elector failed: %w", err)
+	}
+
+	// The elector returns when losing the leadership so keep competing for
+	// the lease until the context is done.
+	run := func(ctx context.Context) {
+		for ctx.Err() == nil {
+			elector.Run(ctx)
+		}
+	}
+	return run, nil
+}
+
+// checkClusterSingletons verifies the inputs marked as cluster singletons
+// can be handled by leader election. When only gathering once there is no
+// time to elect a leader, so singletons are rejected in this case.
+func (a *Agent) checkClusterSingletons(once bool) (bool, error) {
+	var found bool
+	for _, input := range a.Config.Inputs {
+		if !input.Config.ClusterSingleton {
+			continue
+		}
+		if _, ok := input.Input.(telegraf.ServiceInput); ok {
+			return false, fmt.Errorf("input %s: 'cluster_singleton' not supported for service inputs", input.LogName())
+		}
+		if once {
+			return false, fmt.Errorf("input %s: 'cluster_singleton' not supported in --once or --test mode", input.LogName())
+		}
+		found = true
+	}
+
+	if found && a.Config.Agent.LeaderElection == "" {
+		return false, errors.New("inputs marked as 'cluster_singleton' require 'leader_election' to be set")
+	}
+	return found, nil
+}
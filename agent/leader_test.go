@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type countingInput struct {
+	gathered chan struct{}
+}
+
+func (*countingInput) SampleConfig() string { return "" }
+
+func (i *countingInput) Gather(telegraf.Accumulator) error {
+	i.gathered <- struct{}{}
+	return nil
+}
+
+type singletonServiceInput struct {
+	countingInput
+}
+
+func (*singletonServiceInput) Start(telegraf.Accumulator) error { return nil }
+func (*singletonServiceInput) Stop()                            {}
+
+func TestCheckClusterSingletons(t *testing.T) {
+	c := config.NewConfig()
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&countingInput{}, &models.InputConfig{Name: "regular"}))
+	a := NewAgent(c)
+
+	found, err := a.checkClusterSingletons(false)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&countingInput{}, &models.InputConfig{
+		Name:             "singleton",
+		ClusterSingleton: true,
+	}))
+	_, err = a.checkClusterSingletons(false)
+	require.ErrorContains(t, err, "require 'leader_election'")
+
+	c.Agent.LeaderElection = "kubernetes"
+	found, err = a.checkClusterSingletons(false)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&singletonServiceInput{}, &models.InputConfig{
+		Name:             "service",
+		ClusterSingleton: true,
+	}))
+	_, err = a.checkClusterSingletons(false)
+	require.ErrorContains(t, err, "not supported for service inputs")
+}
+
+func TestCheckClusterSingletonsOnce(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.LeaderElection = "kubernetes"
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&countingInput{}, &models.InputConfig{
+		Name:             "singleton",
+		ClusterSingleton: true,
+	}))
+	a := NewAgent(c)
+
+	_, err := a.checkClusterSingletons(true)
+	require.ErrorContains(t, err, "not supported in --once or --test mode")
+}
+
+func TestOnceClusterSingleton(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.LeaderElection = "kubernetes"
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&countingInput{gathered: make(chan struct{}, 1)}, &models.InputConfig{
+		Name:             "singleton",
+		ClusterSingleton: true,
+	}))
+	a := NewAgent(c)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Once(context.Background(), 0)
+	}()
+
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, "not supported in --once or --test mode")
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "running once did not return")
+	}
+}
+
+func TestLeaderElectionInvalid(t *testing.T) {
+	_, err := newLeaderElector(&config.AgentConfig{LeaderElection: "raft"})
+	require.EqualError(t, err, `invalid leader election "raft"`)
+}
+
+func TestGatherLoopClusterSingleton(t *testing.T) {
+	input := &countingInput{gathered: make(chan struct{}, 10)}
+	ri := models.NewRunningInput(input, &models.InputConfig{
+		Name:             "singleton",
+		ClusterSingleton: true,
+	})
+	require.NoError(t, ri.Init())
+
+	a := NewAgent(config.NewConfig())
+	a.leader = &leaderElector{
+		identity:   "test",
+		leaderStat: selfstat.Register("agent", "leader", map[string]string{"leader_election": "test"}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interval := 10 * time.Millisecond
+	ticker := NewUnalignedTicker(interval, 0, 0)
+	defer ticker.Stop()
+
+	acc := &testutil.Accumulator{}
+	go a.gatherLoop(ctx, acc, ri, ticker, interval)
+
+	// Non-leaders must not gather
+	select {
+	case <-input.gathered:
+		require.FailNow(t, "gathered without being the leader")
+	case <-time.After(10 * interval):
+	}
+
+	// Leaders must gather
+	a.leader.setLeader(true)
+	select {
+	case <-input.gathered:
+	case <-time.After(time.Second):
+		require.FailNow(t, "not gathered while being the leader")
+	}
+}
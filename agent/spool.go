@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tidwall/wal"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// spool is a pipeline stage between a pull-based input and the processors.
+// While the buffer of an output is full, the metrics of the input are written
+// to a disk queue instead of being dropped by the output buffer. The queued
+// metrics are replayed in order once all outputs have room again.
+//
+//  ______     ┌───────┐     ______
+// ()_____)──▶ │ Spool │──▶ ()_____)
+//             └───┬───┘
+//                 ▼
+//              [ disk ]
+
+type spool struct {
+	name  string
+	path  string
+	limit int
+	file  *wal.Log
+
+	src  chan telegraf.Metric
+	dst  chan<- telegraf.Metric
+	free func() int
+
+	size    selfstat.Stat
+	dropped selfstat.Stat
+}
+
+// Interval for replaying spooled metrics if the input does not produce any
+const spoolReplayInterval = time.Second
+
+var registerSpoolGob = sync.OnceFunc(metric.Init)
+
+func newSpool(input *models.RunningInput, directory string, dst chan<- telegraf.Metric, free func() int) (*spool, error) {
+	if directory == "" {
+		return nil, errors.New("'spool' requires the agent's 'buffer_directory' to be set")
+	}
+	if input.Config.SpoolLimit < 0 {
+		return nil, errors.New("'spool_limit' must not be negative")
+	}
+	registerSpoolGob()
+
+	tags := map[string]string{"input": input.Config.Name}
+	if input.Config.Alias != "" {
+		tags["alias"] = input.Config.Alias
+	}
+	s := &spool{
+		name:    input.LogName(),
+		path:    filepath.Join(directory, "spool", input.ID()),
+		limit:   input.Config.SpoolLimit,
+		src:     make(chan telegraf.Metric, 100),
+		dst:     dst,
+		free:    free,
+		size:    selfstat.Register("spool", "size", tags),
+		dropped: selfstat.Register("spool", "metrics_dropped", tags),
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	if n := s.length(); n > 0 {
+		log.Printf("I! [%s] Found %d spooled metrics from a previous run", s.name, n)
+	}
+	s.size.Set(int64(s.length()))
+
+	return s, nil
+}
+
+func (s *spool) open() error {
+	f, err := wal.Open(s.path, nil)
+	if err != nil {
+		return fmt.Errorf("opening spool %q failed: %w", s.path, err)
+	}
+	s.file = f
+	return nil
+}
+
+// run forwards or spools the metrics of the input until the source channel is
+// closed. Metrics remaining in the spool are kept on disk for the next run.
+func (s *spool) run() {
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case m, ok := <-s.src:
+			if !ok {
+				if err := s.file.Close(); err != nil {
+					log.Printf("E! [%s] Closing spool failed: %v", s.name, err)
+				}
+				return
+			}
+			s.add(m)
+		case <-ticker.C:
+			s.replay()
+		}
+	}
+}
+
+func (s *spool) add(m telegraf.Metric) {
+	// Tracking metrics must be delivered to notify the input
+	if _, ok := m.(telegraf.TrackingMetric); ok {
+		s.dst <- m
+		return
+	}
+
+	// Pass through the metric as long as there is room and no older metrics
+	// are waiting to keep the order
+	if s.length() == 0 && s.free() > 0 {
+		s.dst <- m
+		return
+	}
+
+	if err := s.write(m); err != nil {
+		log.Printf("E! [%s] Spooling metric failed, dropping it: %v", s.name, err)
+		s.dropped.Incr(1)
+		m.Drop()
+		return
+	}
+	s.replay()
+}
+
+func (s *spool) write(m telegraf.Metric) error {
+	data, err := metric.ToBytes(m)
+	if err != nil {
+		return err
+	}
+
+	last, err := s.file.LastIndex()
+	if err != nil {
+		return err
+	}
+	if err := s.file.Write(last+1, data); err != nil {
+		return err
+	}
+	m.Accept()
+
+	// Drop the oldest metrics when exceeding the limit
+	if s.limit > 0 {
+		if n := s.length() - s.limit; n > 0 {
+			first, err := s.file.FirstIndex()
+			if err != nil {
+				return err
+			}
+			if err := s.file.TruncateFront(first + uint64(n)); err != nil {
+				return err
+			}
+			s.dropped.Incr(int64(n))
+		}
+	}
+	s.size.Set(int64(s.length()))
+	return nil
+}
+
+// replay forwards as many spooled metrics as the outputs have room for
+func (s *spool) replay() {
+	n := min(s.length(), s.free())
+	if n <= 0 {
+		return
+	}
+
+	first, err := s.file.FirstIndex()
+	if err != nil {
+		log.Printf("E! [%s] Reading spool failed: %v", s.name, err)
+		return
+	}
+	for i := range uint64(n) {
+		data, err := s.file.Read(first + i)
+		if err != nil {
+			log.Printf("E! [%s] Reading spool failed: %v", s.name, err)
+			return
+		}
+		m, err := metric.FromBytes(data)
+		if err != nil {
+			log.Printf("E! [%s] Decoding spooled metric failed, dropping it: %v", s.name, err)
+			s.dropped.Incr(1)
+		} else {
+			s.dst <- m
+		}
+	}
+
+	// The WAL cannot be truncated completely so recreate it if all metrics
+	// are replayed
+	if n == s.length() {
+		err = s.reset()
+	} else {
+		err = s.file.TruncateFront(first + uint64(n))
+	}
+	if err != nil {
+		log.Printf("E! [%s] Removing replayed metrics from spool failed: %v", s.name, err)
+	}
+	s.size.Set(int64(s.length()))
+}
+
+func (s *spool) reset() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *spool) length() int {
+	first, err := s.file.FirstIndex()
+	if err != nil || first == 0 {
+		return 0
+	}
+	last, err := s.file.LastIndex()
+	if err != nil {
+		return 0
+	}
+	return int(last - first + 1)
+}
+
+// outputCapacity returns the minimum number of metrics the outputs can buffer
+// before dropping metrics
+func (a *Agent) outputCapacity() int {
+	free := math.MaxInt
+	for _, output := range a.Config.Outputs {
+		free = min(free, output.MetricBufferLimit-output.BufferLength())
+	}
+	return max(free, 0)
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSpoolOutputFull(t *testing.T) {
+	input := models.NewRunningInput(&countingInput{}, &models.InputConfig{Name: "spooled", ID: "spooled", Spool: true})
+	dst := make(chan telegraf.Metric, 100)
+	free := 1
+	s, err := newSpool(input, t.TempDir(), dst, func() int { return free })
+	require.NoError(t, err)
+	defer s.file.Close()
+
+	// Metrics are passed through while the outputs have room
+	s.add(spoolMetric(0))
+	require.Len(t, dst, 1)
+	require.Zero(t, s.length())
+
+	// Metrics are spooled while the outputs are full
+	free = 0
+	for i := 1; i <= 3; i++ {
+		s.add(spoolMetric(i))
+	}
+	require.Len(t, dst, 1)
+	require.Equal(t, 3, s.length())
+
+	// Replay only as many metrics as there is room for
+	free = 2
+	s.replay()
+	require.Len(t, dst, 3)
+	require.Equal(t, 1, s.length())
+
+	// New metrics are queued behind the spooled ones to keep the order
+	free = 10
+	s.add(spoolMetric(4))
+	require.Zero(t, s.length())
+	close(dst)
+
+	expected := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		expected = append(expected, spoolMetric(i))
+	}
+	actual := make([]telegraf.Metric, 0, 5)
+	for m := range dst {
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSpoolLimit(t *testing.T) {
+	input := models.NewRunningInput(&countingInput{}, &models.InputConfig{
+		Name:       "limited",
+		ID:         "limited",
+		Spool:      true,
+		SpoolLimit: 2,
+	})
+	dst := make(chan telegraf.Metric, 100)
+	s, err := newSpool(input, t.TempDir(), dst, func() int { return 0 })
+	require.NoError(t, err)
+	defer s.file.Close()
+
+	for i := range 5 {
+		s.add(spoolMetric(i))
+	}
+	require.Equal(t, 2, s.length())
+	require.Equal(t, int64(3), s.dropped.Get())
+
+	// The oldest metrics are dropped
+	s.free = func() int { return 10 }
+	s.replay()
+	close(dst)
+	actual := make([]telegraf.Metric, 0, 2)
+	for m := range dst {
+		actual = append(actual, m)
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{spoolMetric(3), spoolMetric(4)}, actual)
+}
+
+func TestSpoolPersisted(t *testing.T) {
+	dir := t.TempDir()
+	input := models.NewRunningInput(&countingInput{}, &models.InputConfig{Name: "persisted", ID: "persisted", Spool: true})
+	dst := make(chan telegraf.Metric, 100)
+	s, err := newSpool(input, dir, dst, func() int { return 0 })
+	require.NoError(t, err)
+	s.add(spoolMetric(0))
+	s.add(spoolMetric(1))
+
+	// Closing the source keeps the spooled metrics on disk
+	close(s.src)
+	s.run()
+
+	s, err = newSpool(input, dir, dst, func() int { return 10 })
+	require.NoError(t, err)
+	defer s.file.Close()
+	require.Equal(t, 2, s.length())
+	s.replay()
+	require.Len(t, dst, 2)
+	require.Zero(t, s.length())
+}
+
+func TestSpoolInvalid(t *testing.T) {
+	c := config.NewConfig()
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&countingInput{}, &models.InputConfig{Name: "regular", Spool: true}))
+	a := NewAgent(c)
+	_, err := a.startInputs(make(chan telegraf.Metric), c.Inputs)
+	require.ErrorContains(t, err, "requires the agent's 'buffer_directory'")
+
+	c.Agent.BufferDirectory = t.TempDir()
+	c.Inputs[0].Config.SpoolLimit = -1
+	_, err = a.startInputs(make(chan telegraf.Metric), c.Inputs)
+	require.ErrorContains(t, err, "'spool_limit' must not be negative")
+
+	c.Inputs[0] = models.NewRunningInput(&singletonServiceInput{}, &models.InputConfig{Name: "service", Spool: true})
+	_, err = a.startInputs(make(chan telegraf.Metric), c.Inputs)
+	require.ErrorContains(t, err, "not supported for service inputs")
+}
+
+func spoolMetric(i int) telegraf.Metric {
+	return metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// statusServer serves the state of the service inputs via HTTP
+type statusServer struct {
+	listener net.Listener
+	server   *http.Server
+	inputs   []*models.RunningInput
+}
+
+type inputStatus struct {
+	Name    string                 `json:"name"`
+	Alias   string                 `json:"alias,omitempty"`
+	ID      string                 `json:"id"`
+	Ready   bool                   `json:"ready"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type agentStatus struct {
+	Ready  bool          `json:"ready"`
+	Inputs []inputStatus `json:"inputs"`
+}
+
+func newStatusServer(address string, inputs []*models.RunningInput) (*statusServer, error) {
+	// Only report service inputs and plugins providing their status
+	s := &statusServer{}
+	for _, input := range inputs {
+		_, service := input.Input.(telegraf.ServiceInput)
+		_, reporter := input.Input.(telegraf.StatusReporter)
+		if service || reporter {
+			s.inputs = append(s.inputs, input)
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", s.serveStatus)
+	mux.HandleFunc("/ready", s.serveReady)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// run serves the requests until the context is done
+func (s *statusServer) run(ctx context.Context) {
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving status failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("E! [agent] Shutting down status server failed: %v", err)
+		// Forcefully close remaining connections, e.g. hanging clients
+		if err := s.server.Close(); err != nil {
+			log.Printf("E! [agent] Closing status server failed: %v", err)
+		}
+	}
+}
+
+func (s *statusServer) status() agentStatus {
+	status := agentStatus{
+		Ready:  true,
+		Inputs: make([]inputStatus, 0, len(s.inputs)),
+	}
+	for _, input := range s.inputs {
+		is := inputStatus{
+			Name:  input.Config.Name,
+			Alias: input.Config.Alias,
+			ID:    input.ID(),
+			Ready: true,
+		}
+		if reporter, ok := input.Input.(telegraf.StatusReporter); ok {
+			is.Ready, is.Details = reporter.Status()
+		}
+		status.Ready = status.Ready && is.Ready
+		status.Inputs = append(status.Inputs, is)
+	}
+	return status
+}
+
+func (s *statusServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	buf, err := json.Marshal(s.status())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(buf); err != nil {
+		log.Printf("E! [agent] Writing status failed: %v", err)
+	}
+}
+
+func (s *statusServer) serveReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.status().Ready {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
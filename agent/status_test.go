@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+type statusServiceInput struct {
+	ready atomic.Bool
+}
+
+func (*statusServiceInput) SampleConfig() string              { return "" }
+func (*statusServiceInput) Gather(telegraf.Accumulator) error { return nil }
+func (*statusServiceInput) Start(telegraf.Accumulator) error  { return nil }
+func (*statusServiceInput) Stop()                             {}
+
+func (i *statusServiceInput) Status() (bool, map[string]interface{}) {
+	return i.ready.Load(), map[string]interface{}{"connected": i.ready.Load()}
+}
+
+func TestStatusServer(t *testing.T) {
+	reporter := &statusServiceInput{}
+	inputs := []*models.RunningInput{
+		models.NewRunningInput(&countingInput{}, &models.InputConfig{Name: "regular"}),
+		models.NewRunningInput(&singletonServiceInput{}, &models.InputConfig{Name: "service"}),
+		models.NewRunningInput(reporter, &models.InputConfig{Name: "reporter", Alias: "foo"}),
+	}
+	s, err := newStatusServer("127.0.0.1:0", inputs)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	addr := "http://" + s.listener.Addr().String()
+
+	// Avoid keep-alive connections delaying the shutdown of the server
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	// The input reporting its status is not ready yet
+	resp, err := client.Get(addr + "/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = client.Get(addr + "/status")
+	require.NoError(t, err)
+	var status agentStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.False(t, status.Ready)
+	require.Len(t, status.Inputs, 2)
+	require.Equal(t, "service", status.Inputs[0].Name)
+	require.True(t, status.Inputs[0].Ready)
+	require.Equal(t, "reporter", status.Inputs[1].Name)
+	require.Equal(t, "foo", status.Inputs[1].Alias)
+	require.False(t, status.Inputs[1].Ready)
+	require.Equal(t, map[string]interface{}{"connected": false}, status.Inputs[1].Details)
+
+	// All inputs are ready
+	reporter.ready.Store(true)
+	resp, err = client.Get(addr + "/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "status server did not shut down")
+	}
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/models"
+)
+
+// Validate initializes all plugins of the configuration without starting
+// them, e.g. to check a new configuration before reloading. In contrast to
+// InitPlugins all failing plugins are reported. If probe is set, each output
+// is additionally connected and closed again to check its connectivity,
+// waiting at most the given timeout per output.
+func (a *Agent) Validate(ctx context.Context, probe bool, timeout time.Duration) error {
+	var errs []error
+	for _, input := range a.Config.Inputs {
+		if tp, ok := input.Input.(snmp.TranslatorPlugin); ok {
+			tp.SetTranslator(a.Config.Agent.SnmpTranslator)
+		}
+		if err := input.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("could not initialize input %s: %w", input.LogName(), err))
+		}
+	}
+	for _, processor := range a.Config.Processors {
+		if err := processor.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err))
+		}
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		if err := aggregator.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("could not initialize aggregator %s: %w", aggregator.LogName(), err))
+		}
+	}
+	for _, processor := range a.Config.AggProcessors {
+		if err := processor.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err))
+		}
+	}
+	for _, output := range a.Config.Outputs {
+		if err := output.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("could not initialize output %s: %w", output.LogName(), err))
+		}
+	}
+
+	// Probing uninitialized outputs is pointless
+	if len(errs) > 0 || !probe {
+		return errors.Join(errs...)
+	}
+
+	for _, output := range a.Config.Outputs {
+		if err := probeOutput(ctx, output, timeout); err != nil {
+			errs = append(errs, fmt.Errorf("could not connect output %s: %w", output.LogName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// probeOutput connects the output and closes the connection again. The
+// plugin is used directly to circumvent the startup-error behavior of the
+// running output as a retry would hide a failing connection. Outputs cannot
+// be cancelled while connecting, so on timeout the output is closed to abort
+// the connection attempt.
+func probeOutput(ctx context.Context, output *models.RunningOutput, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- output.Output.Connect()
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			closeProbe(output)
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	// Wait for the aborted attempt to not leave any work running and close
+	// the connection in case it succeeded in the meantime
+	closeProbe(output)
+	select {
+	case err := <-done:
+		if err == nil {
+			closeProbe(output)
+		}
+	case <-time.After(timeout):
+		output.Log().Warn("Connection attempt still running after closing the output")
+	}
+	return fmt.Errorf("connecting failed: %w", ctx.Err())
+}
+
+func closeProbe(output *models.RunningOutput) {
+	if err := output.Output.Close(); err != nil {
+		output.Log().Debugf("Closing probe connection failed: %v", err)
+	}
+}
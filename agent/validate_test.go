@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+type mockProbeOutput struct {
+	initErr    error
+	connectErr error
+	block      chan struct{}
+	abort      chan struct{}
+	closed     bool
+	returned   bool
+
+	closeOnce sync.Once
+}
+
+func (*mockProbeOutput) SampleConfig() string { return "" }
+
+func (o *mockProbeOutput) Init() error {
+	return o.initErr
+}
+
+func (o *mockProbeOutput) Connect() error {
+	defer func() { o.returned = true }()
+	if o.block != nil {
+		select {
+		case <-o.block:
+		case <-o.abort:
+			return errors.New("aborted")
+		}
+	}
+	return o.connectErr
+}
+
+func (o *mockProbeOutput) Close() error {
+	o.closed = true
+	if o.abort != nil {
+		o.closeOnce.Do(func() { close(o.abort) })
+	}
+	return nil
+}
+
+func (*mockProbeOutput) Write([]telegraf.Metric) error { return nil }
+
+func newValidateAgent(outputs ...*mockProbeOutput) *Agent {
+	c := config.NewConfig()
+	for i, o := range outputs {
+		c.Outputs = append(c.Outputs, models.NewRunningOutput(o, &models.OutputConfig{
+			Name:  "probe",
+			Alias: string(rune('a' + i)),
+		}, 10, 100))
+	}
+	return NewAgent(c)
+}
+
+func TestValidateInit(t *testing.T) {
+	a := newValidateAgent(
+		&mockProbeOutput{initErr: errors.New("first")},
+		&mockProbeOutput{},
+		&mockProbeOutput{initErr: errors.New("second")},
+	)
+
+	err := a.Validate(context.Background(), true, time.Second)
+	require.ErrorContains(t, err, "could not initialize output outputs.probe::a: first")
+	require.ErrorContains(t, err, "could not initialize output outputs.probe::c: second")
+}
+
+func TestValidateProbe(t *testing.T) {
+	ok := &mockProbeOutput{}
+	block := make(chan struct{})
+	defer close(block)
+	a := newValidateAgent(
+		ok,
+		&mockProbeOutput{connectErr: errors.New("connection refused")},
+		&mockProbeOutput{block: block},
+	)
+
+	// Without probing the connection is not checked
+	require.NoError(t, a.Validate(context.Background(), false, time.Second))
+	require.False(t, ok.closed)
+
+	err := a.Validate(context.Background(), true, 100*time.Millisecond)
+	require.ErrorContains(t, err, "could not connect output outputs.probe::b: connection refused")
+	require.ErrorContains(t, err, "could not connect output outputs.probe::c")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotContains(t, err.Error(), "outputs.probe::a")
+	require.True(t, ok.closed)
+}
+
+func TestValidateProbeTimeoutAborts(t *testing.T) {
+	output := &mockProbeOutput{
+		block: make(chan struct{}),
+		abort: make(chan struct{}),
+	}
+	a := newValidateAgent(output)
+
+	err := a.Validate(context.Background(), true, 100*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The connection attempt is aborted before reporting
+	require.True(t, output.closed)
+	require.True(t, output.returned)
+}
@@ -0,0 +1,76 @@
+// Command handling for the "schema" command
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func getSchemaCommands(m App, outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "schema",
+			Usage: "print the schema of the metrics produced by the configuration as JSON",
+			Description: `
+The 'schema' command loads your configuration, runs a single gather of
+all inputs including processors and aggregators and prints the measurements
+with their tags and fields and the observed field types as JSON. Outputs are
+not used. Tags or fields not present in all samples of a measurement are
+marked as optional.
+
+To print the schema of the default configuration run
+
+> telegraf schema
+
+Use the '--test-wait' flag to wait for service inputs to collect metrics, e.g.
+
+> telegraf --config telegraf.conf schema --test-wait 10
+`,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "input-filter",
+					Usage: "filter the inputs to enable, separator is ':'",
+				},
+				&cli.StringFlag{
+					Name:  "processor-filter",
+					Usage: "filter the processors to enable, separator is ':'",
+				},
+				&cli.StringFlag{
+					Name:  "aggregator-filter",
+					Usage: "filter the aggregators to enable, separator is ':'",
+				},
+				&cli.IntFlag{
+					Name:  "test-wait",
+					Usage: "wait up to this many seconds for service inputs to collect metrics",
+				},
+			},
+			Action: func(cCtx *cli.Context) error {
+				filters := processFilterFlags(cCtx)
+				g := GlobalFlags{
+					config:     cCtx.StringSlice("config"),
+					configDir:  cCtx.StringSlice("config-directory"),
+					plugindDir: cCtx.String("plugin-directory"),
+					password:   cCtx.String("password"),
+					debug:      cCtx.Bool("debug"),
+				}
+				w := WindowFlags{}
+				m.Init(nil, filters, g, w)
+
+				s, err := m.Schema(time.Duration(cCtx.Int("test-wait")) * time.Second)
+				if s == nil {
+					return err
+				}
+
+				encoder := json.NewEncoder(outputBuffer)
+				encoder.SetIndent("", "  ")
+				if eerr := encoder.Encode(s); eerr != nil {
+					return eerr
+				}
+				return err
+			},
+		},
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/schema"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -74,6 +77,19 @@ func (m *MockTelegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return s, nil
 }
 
+func (m *MockTelegraf) Schema(_ time.Duration) (*schema.Schema, error) {
+	if len(m.config) == 0 {
+		return nil, errors.New("no inputs found")
+	}
+	collector := schema.NewCollector()
+	collector.Add(metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"usage_idle": 99.5}, time.Unix(0, 0)))
+	collector.Add(metric.New("cpu", nil, map[string]interface{}{"usage_idle": int64(100)}, time.Unix(0, 0)))
+	return &schema.Schema{
+		Plugins:      []schema.Plugin{{Name: "cpu"}},
+		Measurements: collector.Measurements(),
+	}, nil
+}
+
 type MockSecretStore struct {
 	Secrets map[string][]byte
 }
@@ -472,6 +488,48 @@ func TestCommandVersion(t *testing.T) {
 	}
 }
 
+func TestCommandSchema(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	args = append(args, "--config", "telegraf.conf", "schema")
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	expected := `{
+  "plugins": [
+    {
+      "name": "cpu"
+    }
+  ],
+  "measurements": [
+    {
+      "name": "cpu",
+      "samples": 2,
+      "tags": [
+        {
+          "name": "cpu",
+          "optional": true
+        }
+      ],
+      "fields": [
+        {
+          "name": "usage_idle",
+          "types": [
+            "float",
+            "integer"
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+	require.Equal(t, expected, buf.String())
+
+	args = os.Args[0:1]
+	args = append(args, "schema")
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "no inputs found")
+}
+
 // Users should use the version subcommand
 func TestFlagVersion(t *testing.T) {
 	tests := []struct {
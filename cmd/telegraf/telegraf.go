@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -21,7 +22,9 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/config/kubernetes"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/schema"
 	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -33,6 +36,10 @@ import (
 
 var stop chan struct{}
 
+// Channels to temporarily stop the agent and resume it with a freshly loaded
+// configuration, only used by the Windows service
+var pause, resume chan struct{}
+
 type GlobalFlags struct {
 	config                 []string
 	configDir              []string
@@ -68,6 +75,9 @@ type App interface {
 	// Secret store commands
 	ListSecretStores() ([]string, error)
 	GetSecretStore(string) (telegraf.SecretStore, error)
+
+	// Schema command
+	Schema(time.Duration) (*schema.Schema, error)
 }
 
 type Telegraf struct {
@@ -135,6 +145,20 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return store, nil
 }
 
+func (t *Telegraf) Schema(wait time.Duration) (*schema.Schema, error) {
+	t.quiet = true
+	c, err := t.loadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Inputs) == 0 {
+		return nil, errors.New("no inputs found, probably invalid config file provided")
+	}
+
+	ag := agent.NewAgent(c)
+	return ag.Schema(context.Background(), wait)
+}
+
 func (t *Telegraf) reloadLoop() error {
 	reloadConfig := false
 	reload := make(chan bool, 1)
@@ -146,57 +170,51 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
-		if t.watchConfig != "" {
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					continue
-				}
-
-				if _, err := os.Stat(fConfig); err != nil {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfig)
-				}
-			}
-			for _, fConfigDirectory := range t.configDir {
-				if _, err := os.Stat(fConfigDirectory); err != nil {
-					log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
-				} else {
-					go t.watchLocalConfig(ctx, signals, fConfigDirectory)
-				}
-			}
-		}
-		if t.configURLWatchInterval > 0 {
-			remoteConfigs := make([]string, 0)
-			for _, fConfig := range t.configFiles {
-				if isURL(fConfig) {
-					remoteConfigs = append(remoteConfigs, fConfig)
-				}
-			}
-			if len(remoteConfigs) > 0 {
-				go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
-			}
-		}
+		watchCtx, watchCancel := context.WithCancel(ctx)
+		t.startConfigWatchers(watchCtx, signals)
+		paused := make(chan struct{}, 1)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Println("I! Reloading Telegraf config")
-					// May need to update the list of known config files
-					// if a delete or create occured. That way on the reload
-					// we ensure we watch the correct files.
-					if err := t.getConfigFiles(); err != nil {
-						log.Println("E! Error loading config files: ", err)
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Println("I! Reloading Telegraf config")
+						// May need to update the list of known config files
+						// if a delete or create occured. That way on the reload
+						// we ensure we watch the correct files.
+						if err := t.getConfigFiles(); err != nil {
+							log.Println("E! Error loading config files: ", err)
+						}
+						if err := t.validateReload(ctx); err != nil {
+							log.Printf("E! Validating new config failed, keeping current config: %v", err)
+							// The watchers stop after detecting a change so
+							// restart them to catch the next modification.
+							watchCancel()
+							watchCtx, watchCancel = context.WithCancel(ctx)
+							t.startConfigWatchers(watchCtx, signals)
+							continue
+						}
+						<-reload
+						reload <- true
 					}
+					watchCancel()
+					cancel()
+				case err := <-t.pprofErr:
+					log.Printf("E! pprof server failed: %v", err)
+					watchCancel()
+					cancel()
+				case <-pause:
+					log.Println("I! Pausing Telegraf")
+					paused <- struct{}{}
 					<-reload
 					reload <- true
+					watchCancel()
+					cancel()
+				case <-stop:
+					watchCancel()
+					cancel()
 				}
-				cancel()
-			case err := <-t.pprofErr:
-				log.Printf("E! pprof server failed: %v", err)
-				cancel()
-			case <-stop:
-				cancel()
+				return
 			}
 		}()
 
@@ -205,11 +223,96 @@ func (t *Telegraf) reloadLoop() error {
 			return fmt.Errorf("[telegraf] Error running agent: %w", err)
 		}
 		reloadConfig = true
+
+		select {
+		case <-paused:
+			log.Println("I! Telegraf paused")
+			select {
+			case <-resume:
+				log.Println("I! Continuing Telegraf")
+			case <-stop:
+				return nil
+			}
+		default:
+		}
 	}
 
 	return nil
 }
 
+func (t *Telegraf) startConfigWatchers(ctx context.Context, signals chan os.Signal) {
+	if t.watchConfig != "" {
+		for _, fConfig := range t.configFiles {
+			if isKubernetesURL(fConfig) {
+				go t.watchKubernetesConfig(ctx, signals, fConfig)
+				continue
+			}
+			if isURL(fConfig) {
+				continue
+			}
+
+			if _, err := os.Stat(fConfig); err != nil {
+				log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfig)
+			}
+		}
+		for _, fConfigDirectory := range t.configDir {
+			if _, err := os.Stat(fConfigDirectory); err != nil {
+				log.Printf("W! Cannot watch config directory %s: %s", fConfigDirectory, err)
+			} else {
+				go t.watchLocalConfig(ctx, signals, fConfigDirectory)
+			}
+		}
+	}
+	if t.configURLWatchInterval > 0 {
+		remoteConfigs := make([]string, 0)
+		for _, fConfig := range t.configFiles {
+			if isURL(fConfig) && !isKubernetesURL(fConfig) {
+				remoteConfigs = append(remoteConfigs, fConfig)
+			}
+		}
+		if len(remoteConfigs) > 0 {
+			go t.watchRemoteConfigs(ctx, signals, t.configURLWatchInterval, remoteConfigs)
+		}
+	}
+}
+
+// validateReload loads the new configuration and validates it in a sandbox
+// if enabled by the 'reload_validation' setting of the running configuration.
+// The differences to the running configuration are logged before validating.
+func (t *Telegraf) validateReload(ctx context.Context) error {
+	if t.cfg == nil || t.cfg.Agent.ReloadValidation == "" {
+		return nil
+	}
+
+	c := config.NewConfig()
+	c.Sandbox = true
+	c.Agent.Quiet = t.quiet
+	c.Agent.ConfigURLRetryAttempts = t.configURLRetryAttempts
+	c.OutputFilters = t.outputFilters
+	c.InputFilters = t.inputFilters
+	c.SecretStoreFilters = t.secretstoreFilters
+	if err := c.LoadAll(t.configFiles...); err != nil {
+		return err
+	}
+
+	diff := config.Diff(t.cfg, c)
+	if len(diff) == 0 {
+		log.Println("I! New config does not change any plugin")
+	}
+	for _, d := range diff {
+		log.Printf("I! New config %s", d)
+	}
+
+	probe := t.cfg.Agent.ReloadValidation == "connect"
+	timeout := time.Duration(t.cfg.Agent.ReloadValidationTimeout)
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return agent.NewAgent(c).Validate(ctx, probe, timeout)
+}
+
 func (t *Telegraf) watchLocalConfig(ctx context.Context, signals chan os.Signal, fConfig string) {
 	var mytomb tomb.Tomb
 	var watcher watch.FileWatcher
@@ -295,6 +398,53 @@ func (t *Telegraf) watchRemoteConfigs(ctx context.Context, signals chan os.Signa
 	}
 }
 
+func (*Telegraf) watchKubernetesConfig(ctx context.Context, signals chan os.Signal, configURL string) {
+	u, err := url.Parse(configURL)
+	if err != nil {
+		log.Printf("E! Cannot watch Kubernetes config %q: %v\n", configURL, err)
+		return
+	}
+	source, err := kubernetes.NewSource(u)
+	if err != nil {
+		log.Printf("E! Cannot watch Kubernetes config %q: %v\n", configURL, err)
+		return
+	}
+	// Remember the current config to detect changes missed while restarting
+	// the watch
+	current, err := source.Fetch(ctx)
+	if err != nil {
+		log.Printf("E! Cannot watch Kubernetes config %q: %v\n", configURL, err)
+		return
+	}
+	log.Printf("I! Kubernetes config watcher started for %s\n", configURL)
+
+	for {
+		err := source.WaitForChange(ctx)
+		if err == nil {
+			log.Printf("I! Kubernetes config %q modified\n", configURL)
+			signals <- syscall.SIGHUP
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Watches are closed by the API server regularly, so restart them
+		log.Printf("D! Restarting Kubernetes config watcher for %q: %v\n", configURL, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+
+		if data, err := source.Fetch(ctx); err == nil && !bytes.Equal(data, current) {
+			log.Printf("I! Kubernetes config %q modified\n", configURL)
+			signals <- syscall.SIGHUP
+			return
+		}
+	}
+}
+
 func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 	// If no other options are specified, load the config file and run.
 	c := config.NewConfig()
@@ -345,6 +495,7 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		if c, err = t.loadConfiguration(); err != nil {
 			return err
 		}
+		t.cfg = c
 	}
 
 	if !(t.test || t.testWait != 0) && len(c.Outputs) == 0 {
@@ -362,6 +513,12 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		return fmt.Errorf("agent flush_interval must be positive; found %v", c.Agent.Interval)
 	}
 
+	switch c.Agent.ReloadValidation {
+	case "", "init", "connect":
+	default:
+		return fmt.Errorf("invalid 'reload_validation' setting %q", c.Agent.ReloadValidation)
+	}
+
 	// Setup logging as configured.
 	logConfig := &logger.Config{
 		Debug:               c.Agent.Debug || t.debug,
@@ -476,3 +633,9 @@ func isURL(str string) bool {
 	u, err := url.Parse(str)
 	return err == nil && u.Scheme != "" && u.Host != ""
 }
+
+// isKubernetesURL checks if string references Kubernetes custom resources
+func isKubernetesURL(str string) bool {
+	u, err := url.Parse(str)
+	return err == nil && u.Scheme == kubernetes.Scheme && u.Host != ""
+}
@@ -96,12 +96,12 @@ func (t *Telegraf) Run() error {
 
 // Handler for the Windows service framework
 func (t *Telegraf) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
-	// Mark the status as startup pending until we are fully started
-	const accepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.StartPending}
-	defer func() {
-		changes <- svc.Status{State: svc.Stopped}
-	}()
+	// Mark the status as startup pending until we are fully started. The
+	// final stopped state is reported by the service framework including
+	// the exit code to allow the service manager to trigger the configured
+	// recovery actions on failures.
+	const accepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndContinue
+	changes <- svc.Status{State: svc.StartPending, WaitHint: serviceWaitHint}
 
 	// Create a eventlog logger for  all service related things
 	svclog, err := eventlog.Open(t.serviceName)
@@ -125,6 +125,8 @@ func (t *Telegraf) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<
 	// react to service change requests
 	loopErr := make(chan error)
 	stop = make(chan struct{})
+	pause = make(chan struct{})
+	resume = make(chan struct{})
 	defer close(loopErr)
 	defer close(stop)
 	go func() {
@@ -135,13 +137,7 @@ func (t *Telegraf) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<
 	for {
 		select {
 		case err := <-loopErr:
-			if err != nil {
-				if lerr := svclog.Error(100, err.Error()); lerr != nil {
-					log.Printf("E! Logging error %q failed: %s", err, lerr)
-				}
-				return true, 3
-			}
-			return false, 0
+			return serviceExitCode(svclog, err)
 		case c := <-r:
 			switch c.Cmd {
 			case svc.Interrogate:
@@ -150,9 +146,27 @@ func (t *Telegraf) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<
 				time.Sleep(100 * time.Millisecond)
 				changes <- c.CurrentStatus
 			case svc.Stop, svc.Shutdown:
-				changes <- svc.Status{State: svc.StopPending}
+				changes <- svc.Status{State: svc.StopPending, WaitHint: serviceWaitHint}
 				var empty struct{}
 				stop <- empty // signal reloadLoop to finish (context cancel)
+			case svc.Pause:
+				// Stop the agent but keep the process running
+				changes <- svc.Status{State: svc.PausePending, WaitHint: serviceWaitHint}
+				select {
+				case pause <- struct{}{}:
+				case err := <-loopErr:
+					return serviceExitCode(svclog, err)
+				}
+				changes <- svc.Status{State: svc.Paused, Accepts: accepted}
+			case svc.Continue:
+				// Restart the agent with a freshly loaded configuration
+				changes <- svc.Status{State: svc.ContinuePending, WaitHint: serviceWaitHint}
+				select {
+				case resume <- struct{}{}:
+				case err := <-loopErr:
+					return serviceExitCode(svclog, err)
+				}
+				changes <- svc.Status{State: svc.Running, Accepts: accepted}
 			default:
 				msg := fmt.Sprintf("Unexpected control request #%d", c)
 				if lerr := svclog.Error(100, msg); lerr != nil {
@@ -163,6 +177,22 @@ func (t *Telegraf) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<
 	}
 }
 
+// Time in milliseconds the service manager should wait for pending state
+// changes before considering the service as hanging
+const serviceWaitHint = 30000
+
+// serviceExitCode logs the error of the processing loop and returns the
+// corresponding service-specific exit code
+func serviceExitCode(svclog *eventlog.Log, err error) (bool, uint32) {
+	if err == nil {
+		return false, 0
+	}
+	if lerr := svclog.Error(100, err.Error()); lerr != nil {
+		log.Printf("E! Logging error %q failed: %s", err, lerr)
+	}
+	return true, 3
+}
+
 type serviceConfig struct {
 	displayName  string
 	restartDelay string
@@ -242,6 +272,11 @@ func installService(name string, cfg *serviceConfig) error {
 		if err := service.SetRecoveryActions(recovery, 10); err != nil {
 			return err
 		}
+		// Also restart if the service stopped with an error exit code, e.g.
+		// due to an error in the configuration, not only on crashes
+		if err := service.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+			return err
+		}
 	}
 
 	// Register the event as a source of eventlog events
@@ -378,13 +413,23 @@ func queryService(name string) (string, error) {
 	service := &mgr.Service{Handle: svchandle, Name: name}
 	defer service.Close()
 
-	// Query the service state and report it to the user
+	// Query the service state and report it to the user including the exit
+	// code of a failed service
 	status, err := service.Query()
 	if err != nil {
 		return "", fmt.Errorf("querying service state failed: %w", err)
 	}
 
-	return stateDescription(status.State), nil
+	state := stateDescription(status.State)
+	if status.State == svc.Stopped {
+		switch {
+		case status.Win32ExitCode == uint32(windows.ERROR_SERVICE_SPECIFIC_ERROR):
+			state += fmt.Sprintf(" with exit code %d", status.ServiceSpecificExitCode)
+		case status.Win32ExitCode != 0:
+			state += fmt.Sprintf(" with error %q", windows.Errno(status.Win32ExitCode).Error())
+		}
+	}
+	return state, nil
 }
 
 func stateDescription(state svc.State) string {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -25,6 +26,7 @@ import (
 	"github.com/influxdata/toml/ast"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config/kubernetes"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/models"
@@ -95,6 +97,11 @@ type Config struct {
 
 	NumberSecrets uint64
 
+	// Sandbox marks configurations only used for validation, e.g. before
+	// reloading. Outputs always use a memory buffer to not interfere with the
+	// buffer files of the running agent.
+	Sandbox bool
+
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
 }
@@ -123,6 +130,7 @@ func NewConfig() *Config {
 			Interval:                   Duration(10 * time.Second),
 			RoundInterval:              true,
 			FlushInterval:              Duration(10 * time.Second),
+			ImmediateFlushDelay:        Duration(100 * time.Millisecond),
 			LogfileRotationMaxArchives: 5,
 		},
 
@@ -197,6 +205,11 @@ type AgentConfig struct {
 	// ie, a jitter of 5s and interval 10s means flushes will happen every 10-15s
 	FlushJitter Duration
 
+	// ImmediateFlushDelay is the maximum time metrics marked as immediate are
+	// held back before writing them to the outputs. Immediate metrics arriving
+	// within the delay are written together.
+	ImmediateFlushDelay Duration
+
 	// MetricBatchSize is the maximum number of metrics that is written to an
 	// output plugin in one call.
 	MetricBatchSize int
@@ -281,6 +294,10 @@ type AgentConfig struct {
 	// startup. Set to -1 for unlimited attempts.
 	ConfigURLRetryAttempts int `toml:"config_url_retry_attempts"`
 
+	// ShutdownDrainTimeout is the maximum time to wait for service inputs to
+	// deliver the metrics of already consumed data on shutdown.
+	ShutdownDrainTimeout Duration `toml:"shutdown_drain_timeout"`
+
 	// BufferStrategy is the metric buffer type to use for a given output plugin.
 	// Supported types currently are "memory" and "disk".
 	BufferStrategy string `toml:"buffer_strategy"`
@@ -288,6 +305,59 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// BufferDiskLimit is the maximum size of the metric data buffered on disk
+	// per output plugin when using the "disk" buffer strategy.
+	BufferDiskLimit Size `toml:"buffer_disk_limit"`
+
+	// BufferFsync is the policy for syncing the disk buffer to disk.
+	// Supported policies are "always", "flush" and "never".
+	BufferFsync string `toml:"buffer_fsync"`
+
+	// ClockSkewSource enables the clock skew detection using the given
+	// reference. Supported sources are "ntp" and "kernel".
+	ClockSkewSource string `toml:"clock_skew_source"`
+
+	// ClockSkewServer is the NTP server to query for the "ntp" source.
+	ClockSkewServer string `toml:"clock_skew_server"`
+
+	// ClockSkewCheckInterval is the interval for checking the clock offset.
+	ClockSkewCheckInterval Duration `toml:"clock_skew_check_interval"`
+
+	// ClockSkewThreshold is the maximum tolerated clock offset.
+	ClockSkewThreshold Duration `toml:"clock_skew_threshold"`
+
+	// ClockSkewAction defines the handling of metrics while the clock offset
+	// exceeds the threshold. Supported are "tag" to add a "clock_skewed" tag
+	// and "correct" to shift the metric timestamps by the offset.
+	ClockSkewAction string `toml:"clock_skew_action"`
+
+	// LeaderElection enables leader election among agents using the given
+	// backend. Currently only "kubernetes" is supported. Inputs marked as
+	// "cluster_singleton" are only gathered by the leader.
+	LeaderElection string `toml:"leader_election"`
+
+	// LeaderElectionLease is the lease to compete for, given as
+	// "namespace/name" for the "kubernetes" backend.
+	LeaderElectionLease string `toml:"leader_election_lease"`
+
+	// LeaderElectionLeaseDuration is the time non-leaders wait before trying
+	// to acquire the leadership after the last renewal by the leader.
+	LeaderElectionLeaseDuration Duration `toml:"leader_election_lease_duration"`
+
+	// StatusAddress is the address to serve the status of the service inputs
+	// on, e.g. for readiness probes. Leave empty to disable.
+	StatusAddress string `toml:"status_address"`
+
+	// ReloadValidation enables validating a new configuration before
+	// applying it on reload. Supported are "init" to initialize all plugins
+	// and "connect" to additionally probe the connectivity of outputs. If the
+	// validation fails, the current configuration is kept.
+	ReloadValidation string `toml:"reload_validation"`
+
+	// ReloadValidationTimeout is the maximum time to wait for an output to
+	// connect when probing its connectivity.
+	ReloadValidationTimeout Duration `toml:"reload_validation_timeout"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -756,6 +826,9 @@ func LoadConfigFileWithRetries(config string, urlRetryAttempts int) ([]byte, boo
 		case "https", "http":
 			data, err := fetchConfig(u, urlRetryAttempts)
 			return data, true, err
+		case kubernetes.Scheme:
+			data, err := fetchKubernetesConfig(u)
+			return data, true, err
 		default:
 			return nil, true, fmt.Errorf("scheme %q not supported", u.Scheme)
 		}
@@ -816,6 +889,17 @@ func fetchConfig(u *url.URL, urlRetryAttempts int) ([]byte, error) {
 	}
 }
 
+func fetchKubernetesConfig(u *url.URL) ([]byte, error) {
+	source, err := kubernetes.NewSource(u)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return source.Fetch(ctx)
+}
+
 func requestURLConfig(req *http.Request) ([]byte, error) {
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -1336,9 +1420,10 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		}
 	}
 
-	// Check the number of misses against the threshold
+	// Check the number of misses against the threshold. Options only valid
+	// for inputs are not part of the general options and skipped here.
 	for key, count := range missCount {
-		if count <= missCountThreshold {
+		if count <= missCountThreshold || key == "immediate" {
 			continue
 		}
 		if err := c.missingTomlField(nil, key); err != nil {
@@ -1375,6 +1460,25 @@ func (c *Config) buildAggregator(name string, tbl *ast.Table) (*models.Aggregato
 	}
 
 	conf.DropOriginal = c.getFieldBool(tbl, "drop_original")
+	conf.PersistState = c.getFieldBool(tbl, "persist_state")
+	conf.MaxPendingMetrics = c.getFieldInt(tbl, "max_pending_metrics")
+	conf.CalendarPeriod = c.getFieldString(tbl, "calendar_period")
+	switch conf.CalendarPeriod {
+	case "", "daily", "weekly", "monthly":
+	default:
+		return nil, fmt.Errorf("invalid calendar period %q for aggregator %s", conf.CalendarPeriod, name)
+	}
+	if conf.CalendarPeriod != "" {
+		timezone := c.getFieldString(tbl, "calendar_timezone")
+		if timezone == "" {
+			timezone = "Local"
+		}
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar timezone for aggregator %s: %w", name, err)
+		}
+		conf.Location = loc
+	}
 	conf.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	conf.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
 	conf.NameOverride = c.getFieldString(tbl, "name_override")
@@ -1526,6 +1630,17 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	cp.CollectionOffset, _ = c.getFieldDuration(tbl, "collection_offset")
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
+	cp.ClusterSingleton = c.getFieldBool(tbl, "cluster_singleton")
+	cp.Spool = c.getFieldBool(tbl, "spool")
+	cp.SpoolLimit = c.getFieldInt(tbl, "spool_limit")
+	cp.Provenance = c.getFieldBool(tbl, "provenance")
+	cp.Immediate = c.getFieldBool(tbl, "immediate")
+	if cp.Provenance {
+		cp.Hostname = c.Agent.Hostname
+		if cp.Hostname == "" {
+			cp.Hostname, _ = os.Hostname()
+		}
+	}
 
 	cp.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	cp.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1571,6 +1686,25 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 		Filter:          filter,
 		BufferStrategy:  c.Agent.BufferStrategy,
 		BufferDirectory: c.Agent.BufferDirectory,
+		BufferDiskLimit: int64(c.Agent.BufferDiskLimit),
+		BufferFsync:     c.Agent.BufferFsync,
+	}
+
+	// Allow to override the agent's buffer settings per output
+	if strategy := c.getFieldString(tbl, "buffer_strategy"); strategy != "" {
+		oc.BufferStrategy = strategy
+	}
+	if directory := c.getFieldString(tbl, "buffer_directory"); directory != "" {
+		oc.BufferDirectory = directory
+	}
+	if limit, found := c.getFieldSize(tbl, "buffer_disk_limit"); found {
+		oc.BufferDiskLimit = limit
+	}
+	if fsync := c.getFieldString(tbl, "buffer_fsync"); fsync != "" {
+		oc.BufferFsync = fsync
+	}
+	if c.Sandbox {
+		oc.BufferStrategy = "memory"
 	}
 
 	// TODO: support FieldPass/FieldDrop on outputs
@@ -1585,6 +1719,7 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.ProvenanceFields = c.getFieldBool(tbl, "provenance_fields")
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1603,17 +1738,19 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
-		"buffer_strategy", "buffer_directory",
-		"collection_jitter", "collection_offset",
+		"buffer_strategy", "buffer_directory", "buffer_disk_limit", "buffer_fsync",
+		"calendar_period", "calendar_timezone",
+		"cluster_singleton", "collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
 		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_pending_metrics", "metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
+		"pass", "period", "persist_state", "precision", "provenance", "provenance_fields",
+		"spool", "spool_limit",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior":
 
 	// Secret-store options to ignore
@@ -1757,6 +1894,32 @@ func (c *Config) getFieldInt64(tbl *ast.Table, fieldName string) int64 {
 	return 0
 }
 
+func (c *Config) getFieldSize(tbl *ast.Table, fieldName string) (int64, bool) {
+	if node, ok := tbl.Fields[fieldName]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Integer:
+				i, err := v.Int()
+				if err != nil {
+					c.addError(tbl, fmt.Errorf("unexpected int type %q, expecting int", v.Value))
+					return 0, false
+				}
+				return i, true
+			case *ast.String:
+				var size Size
+				if err := size.UnmarshalText([]byte(v.Value)); err != nil {
+					c.addError(tbl, fmt.Errorf("error parsing size: %w", err))
+					return 0, false
+				}
+				return int64(size), true
+			}
+			c.addError(tbl, fmt.Errorf("found unexpected format while parsing %q, expecting size", fieldName))
+		}
+	}
+
+	return 0, false
+}
+
 func (c *Config) getFieldStringSlice(tbl *ast.Table, fieldName string) []string {
 	var target []string
 	if node, ok := tbl.Fields[fieldName]; ok {
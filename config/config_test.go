@@ -441,6 +441,12 @@ func TestConfig_FieldNotDefined(t *testing.T) {
 			expected: "line 1: configuration specified the fields [\"not_a_field\"], but they were not used. " +
 				"This is either a typo or this config option does not exist in this version.",
 		},
+		{
+			name:     "input only option in processor plugin",
+			filename: "./testdata/invalid_field_processor_immediate.toml",
+			expected: "line 1: configuration specified the fields [\"immediate\"], but they were not used. " +
+				"This is either a typo or this config option does not exist in this version.",
+		},
 	}
 
 	for _, tt := range tests {
@@ -452,6 +458,23 @@ func TestConfig_FieldNotDefined(t *testing.T) {
 	}
 }
 
+func TestConfig_InputImmediate(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  immediate = true
+`)))
+	require.Len(t, c.Inputs, 1)
+	require.True(t, c.Inputs[0].Config.Immediate)
+
+	c = config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[outputs.http]]
+  immediate = true
+`))
+	require.ErrorContains(t, err, `configuration specified the fields ["immediate"], but they were not used`)
+}
+
 func TestConfig_WrongFieldType(t *testing.T) {
 	c := config.NewConfig()
 	err := c.LoadConfig("./testdata/wrong_field_type.toml")
@@ -517,6 +540,44 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_OutputBufferOverride(t *testing.T) {
+	dir := t.TempDir()
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(fmt.Sprintf(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = %q
+  buffer_disk_limit = "1MiB"
+  buffer_fsync = "flush"
+
+[[outputs.http]]
+  alias = "local"
+  buffer_strategy = "memory"
+
+[[outputs.http]]
+  alias = "remote"
+  buffer_disk_limit = 1024
+  buffer_fsync = "never"
+`, dir))))
+	require.Len(t, c.Outputs, 2)
+	defer func() {
+		for _, o := range c.Outputs {
+			o.Close()
+		}
+	}()
+
+	local := c.Outputs[0].Config
+	require.Equal(t, "memory", local.BufferStrategy)
+	require.Equal(t, int64(1024*1024), local.BufferDiskLimit)
+	require.Equal(t, "flush", local.BufferFsync)
+
+	remote := c.Outputs[1].Config
+	require.Equal(t, "disk", remote.BufferStrategy)
+	require.Equal(t, dir, remote.BufferDirectory)
+	require.Equal(t, int64(1024), remote.BufferDiskLimit)
+	require.Equal(t, "never", remote.BufferFsync)
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+type identifiable interface {
+	ID() string
+	LogName() string
+}
+
+// Diff returns a human-readable list of the differences between the current
+// and the updated configuration, e.g. to report the effect of a reload.
+// Plugins are identified by their name and alias and compared using their
+// ID, i.e. a plugin is considered changed if any of its options changed.
+func Diff(current, updated *Config) []string {
+	var diff []string
+	if !reflect.DeepEqual(current.Agent, updated.Agent) {
+		diff = append(diff, "changed agent settings")
+	}
+	if !maps.Equal(current.Tags, updated.Tags) {
+		diff = append(diff, "changed global tags")
+	}
+
+	diff = append(diff, diffPlugins(current.Inputs, updated.Inputs)...)
+	diff = append(diff, diffPlugins(current.Processors, updated.Processors)...)
+	diff = append(diff, diffPlugins(current.Aggregators, updated.Aggregators)...)
+	diff = append(diff, diffPlugins(current.AggProcessors, updated.AggProcessors)...)
+	diff = append(diff, diffPlugins(current.Outputs, updated.Outputs)...)
+	return diff
+}
+
+func diffPlugins[T identifiable](current, updated []T) []string {
+	// Count the instances with the same configuration per plugin name
+	instances := make(map[string]map[string]int)
+	for _, p := range current {
+		if instances[p.LogName()] == nil {
+			instances[p.LogName()] = make(map[string]int)
+		}
+		instances[p.LogName()][p.ID()]++
+	}
+	for _, p := range updated {
+		if instances[p.LogName()] == nil {
+			instances[p.LogName()] = make(map[string]int)
+		}
+		instances[p.LogName()][p.ID()]--
+	}
+
+	diff := make([]string, 0)
+	for _, name := range slices.Sorted(maps.Keys(instances)) {
+		var added, removed int
+		for _, count := range instances[name] {
+			if count > 0 {
+				removed += count
+			} else {
+				added -= count
+			}
+		}
+
+		// Pair removed and added instances as those are most likely
+		// modifications of the same plugin
+		changed := min(added, removed)
+		for range changed {
+			diff = append(diff, "changed "+name)
+		}
+		for range added - changed {
+			diff = append(diff, "added "+name)
+		}
+		for range removed - changed {
+			diff = append(diff, "removed "+name)
+		}
+	}
+	return diff
+}
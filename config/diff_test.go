@@ -0,0 +1,94 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestDiff(t *testing.T) {
+	current := config.NewConfig()
+	require.NoError(t, current.LoadConfigData([]byte(`
+[[inputs.file]]
+  paths = ["a.txt"]
+
+[[inputs.memcached]]
+  servers = ["localhost:11211"]
+
+[[inputs.procstat]]
+  alias = "web"
+
+[[processors.processor]]
+  option = "foo"
+
+[[outputs.http]]
+  url = "http://localhost:8080"
+`)))
+
+	updated := config.NewConfig()
+	require.NoError(t, updated.LoadConfigData([]byte(`
+[[inputs.file]]
+  paths = ["a.txt"]
+
+[[inputs.file]]
+  paths = ["b.txt"]
+
+[[inputs.memcached]]
+  servers = ["localhost:11212"]
+
+[[processors.processor]]
+  option = "foo"
+
+[[outputs.http]]
+  url = "http://localhost:8080"
+`)))
+
+	expected := []string{
+		"added inputs.file",
+		"changed inputs.memcached",
+		"removed inputs.procstat::web",
+	}
+	require.Equal(t, expected, config.Diff(current, updated))
+	require.Empty(t, config.Diff(current, current))
+}
+
+func TestDiffAgentSettings(t *testing.T) {
+	current := config.NewConfig()
+	require.NoError(t, current.LoadConfigData([]byte(`
+[agent]
+  interval = "10s"
+[global_tags]
+  dc = "a"
+`)))
+
+	updated := config.NewConfig()
+	require.NoError(t, updated.LoadConfigData([]byte(`
+[agent]
+  interval = "20s"
+[global_tags]
+  dc = "b"
+`)))
+
+	expected := []string{
+		"changed agent settings",
+		"changed global tags",
+	}
+	require.Equal(t, expected, config.Diff(current, updated))
+}
+
+func TestSandboxUsesMemoryBuffer(t *testing.T) {
+	c := config.NewConfig()
+	c.Sandbox = true
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  buffer_strategy = "disk"
+  buffer_directory = "/nonexistent"
+
+[[outputs.http]]
+  url = "http://localhost:8080"
+`)))
+	require.Len(t, c.Outputs, 1)
+	require.Equal(t, "memory", c.Outputs[0].Config.BufferStrategy)
+}
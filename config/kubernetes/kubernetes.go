@@ -0,0 +1,257 @@
+// Package kubernetes materializes Telegraf plugin configurations from
+// TelegrafInput and TelegrafOutput custom resources in a Kubernetes cluster.
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Scheme is the URL scheme used to reference custom resources as config
+const Scheme = "kubernetes"
+
+// AllNamespaces is the URL host selecting resources in all namespaces
+const AllNamespaces = "_all"
+
+// Group and version of the custom resources
+const (
+	Group   = "telegraf.influxdata.com"
+	Version = "v1alpha1"
+)
+
+var pluginNameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+type resource struct {
+	kind     string
+	category string
+	gvr      schema.GroupVersionResource
+}
+
+var resources = []resource{
+	{
+		kind:     "TelegrafInput",
+		category: "inputs",
+		gvr:      schema.GroupVersionResource{Group: Group, Version: Version, Resource: "telegrafinputs"},
+	},
+	{
+		kind:     "TelegrafOutput",
+		category: "outputs",
+		gvr:      schema.GroupVersionResource{Group: Group, Version: Version, Resource: "telegrafoutputs"},
+	},
+}
+
+// Source reads the custom resources of a namespace from the cluster
+type Source struct {
+	namespace string
+	selector  string
+	client    dynamic.Interface
+}
+
+// NewSource creates a source from a URL of the form
+//
+//	kubernetes://<namespace>[?selector=<label selector>&kubeconfig=<path>]
+//
+// using "_all" as namespace to select the resources of all namespaces. The
+// in-cluster configuration is used if no kubeconfig file is given.
+func NewSource(u *url.URL) (*Source, error) {
+	if u.Scheme != Scheme {
+		return nil, fmt.Errorf("invalid scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("namespace required")
+	}
+
+	namespace := u.Host
+	if namespace == AllNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	query := u.Query()
+	var cfg *rest.Config
+	var err error
+	if path := query.Get("kubeconfig"); path != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", path)
+	} else {
+		cfg, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating client config failed: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating client failed: %w", err)
+	}
+
+	return &Source{
+		namespace: namespace,
+		selector:  query.Get("selector"),
+		client:    client,
+	}, nil
+}
+
+// Fetch lists the custom resources and renders them as TOML configuration.
+// Resources are sorted by namespace and name to produce a stable config.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	var buf strings.Builder
+	for _, r := range resources {
+		list, err := s.client.Resource(r.gvr).Namespace(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: s.selector})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s resources failed: %w", r.kind, err)
+		}
+
+		items := list.Items
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].GetNamespace() != items[j].GetNamespace() {
+				return items[i].GetNamespace() < items[j].GetNamespace()
+			}
+			return items[i].GetName() < items[j].GetName()
+		})
+
+		for i := range items {
+			if err := render(&buf, r, &items[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return []byte(buf.String()), nil
+}
+
+// WaitForChange blocks until any of the custom resources is added, modified
+// or deleted, or the context is cancelled.
+func (s *Source) WaitForChange(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changed := make(chan error, len(resources))
+	for _, r := range resources {
+		// Start watching from the current state to only get new events
+		ri := s.client.Resource(r.gvr).Namespace(s.namespace)
+		list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: s.selector})
+		if err != nil {
+			return fmt.Errorf("listing %s resources failed: %w", r.kind, err)
+		}
+		w, err := ri.Watch(ctx, metav1.ListOptions{
+			LabelSelector:   s.selector,
+			ResourceVersion: list.GetResourceVersion(),
+		})
+		if err != nil {
+			return fmt.Errorf("watching %s resources failed: %w", r.kind, err)
+		}
+
+		go func(w watch.Interface, kind string) {
+			defer w.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-w.ResultChan():
+					if !ok {
+						changed <- fmt.Errorf("watch of %s resources closed", kind)
+						return
+					}
+					switch event.Type {
+					case watch.Added, watch.Modified, watch.Deleted:
+						changed <- nil
+						return
+					case watch.Error:
+						changed <- fmt.Errorf("watching %s resources failed: %v", kind, event.Object)
+						return
+					}
+				}
+			}
+		}(w, r.kind)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-changed:
+		return err
+	}
+}
+
+// render writes the plugin configuration of the given resource. The spec
+// contains the plugin name in "plugin" and the plugin settings as TOML in
+// "config". The alias defaults to "<namespace>/<name>" of the resource.
+func render(buf *strings.Builder, r resource, obj *unstructured.Unstructured) error {
+	id := obj.GetNamespace() + "/" + obj.GetName()
+
+	plugin, _, err := unstructured.NestedString(obj.Object, "spec", "plugin")
+	if err != nil || plugin == "" {
+		return fmt.Errorf("%s %s: missing plugin name", r.kind, id)
+	}
+	if !pluginNameRe.MatchString(plugin) {
+		return fmt.Errorf("%s %s: invalid plugin name %q", r.kind, id, plugin)
+	}
+	alias, _, err := unstructured.NestedString(obj.Object, "spec", "alias")
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid alias: %w", r.kind, id, err)
+	}
+	if alias == "" {
+		alias = id
+	}
+	settings, _, err := unstructured.NestedString(obj.Object, "spec", "config")
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid config: %w", r.kind, id, err)
+	}
+	settings = strings.TrimSpace(settings)
+	if err := checkSettings(r.category, plugin, settings); err != nil {
+		return fmt.Errorf("%s %s: invalid config: %w", r.kind, id, err)
+	}
+
+	fmt.Fprintf(buf, "# %s %s\n", r.kind, id)
+	fmt.Fprintf(buf, "[[%s.%s]]\n", r.category, plugin)
+	fmt.Fprintf(buf, "alias = %s\n", strconv.Quote(alias))
+	if settings != "" {
+		buf.WriteString(settings + "\n")
+	}
+	buf.WriteString("\n")
+
+	return nil
+}
+
+// checkSettings makes sure the settings only configure the plugin itself.
+// Any table not nested in the plugin's table, e.g. "[agent]" or another
+// plugin, would be added to the configuration of the agent.
+func checkSettings(category, plugin, settings string) error {
+	root, err := toml.Parse([]byte(fmt.Sprintf("[[%s.%s]]\n%s", category, plugin, settings)))
+	if err != nil {
+		return err
+	}
+	for name := range root.Fields {
+		if name != category {
+			return fmt.Errorf("table %q not allowed", name)
+		}
+	}
+	tbl, ok := root.Fields[category].(*ast.Table)
+	if !ok {
+		return fmt.Errorf("invalid table %q", category)
+	}
+	for name := range tbl.Fields {
+		if name != plugin {
+			return fmt.Errorf("table %q not allowed", category+"."+name)
+		}
+	}
+	if instances, ok := tbl.Fields[plugin].([]*ast.Table); !ok || len(instances) != 1 {
+		return fmt.Errorf("table %q not allowed", category+"."+plugin)
+	}
+	return nil
+}
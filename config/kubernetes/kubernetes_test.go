@@ -0,0 +1,189 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newResource(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": Group + "/" + Version,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func newFakeClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{
+		resources[0].gvr: "TelegrafInputList",
+		resources[1].gvr: "TelegrafOutputList",
+	}
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+}
+
+func TestNewSourceInvalid(t *testing.T) {
+	u, err := url.Parse("kubernetes:///")
+	require.NoError(t, err)
+	_, err = NewSource(u)
+	require.EqualError(t, err, "namespace required")
+
+	u, err = url.Parse("https://default")
+	require.NoError(t, err)
+	_, err = NewSource(u)
+	require.EqualError(t, err, `invalid scheme "https"`)
+}
+
+func TestFetch(t *testing.T) {
+	client := newFakeClient(
+		newResource("TelegrafInput", "team-b", "redis", map[string]interface{}{
+			"plugin": "redis",
+			"config": "servers = [\"tcp://redis.team-b:6379\"]\n",
+		}),
+		newResource("TelegrafInput", "team-a", "cpu", map[string]interface{}{
+			"plugin": "cpu",
+			"alias":  "cpu-team-a",
+		}),
+		newResource("TelegrafOutput", "team-a", "file", map[string]interface{}{
+			"plugin": "file",
+			"config": "files = [\"stdout\"]\n[outputs.file.tagpass]\n  namespace = [\"team-a\"]",
+		}),
+	)
+	source := &Source{client: client}
+
+	expected := `# TelegrafInput team-a/cpu
+[[inputs.cpu]]
+alias = "cpu-team-a"
+
+# TelegrafInput team-b/redis
+[[inputs.redis]]
+alias = "team-b/redis"
+servers = ["tcp://redis.team-b:6379"]
+
+# TelegrafOutput team-a/file
+[[outputs.file]]
+alias = "team-a/file"
+files = ["stdout"]
+[outputs.file.tagpass]
+  namespace = ["team-a"]
+
+`
+	data, err := source.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+
+	// Restrict to a single namespace
+	source.namespace = "team-b"
+	data, err = source.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, string(data), "[[inputs.redis]]")
+	require.NotContains(t, string(data), "[[inputs.cpu]]")
+}
+
+func TestFetchInvalidPlugin(t *testing.T) {
+	client := newFakeClient(
+		newResource("TelegrafInput", "default", "bad", map[string]interface{}{
+			"plugin": "cpu]]\n[agent",
+		}),
+	)
+	source := &Source{client: client}
+
+	_, err := source.Fetch(context.Background())
+	require.ErrorContains(t, err, "invalid plugin name")
+}
+
+func TestFetchConfigInjection(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "agent table",
+			config:   "files = [\"stdout\"]\n[agent]\n  debug = true",
+			expected: `table "agent" not allowed`,
+		},
+		{
+			name:     "plugin of other category",
+			config:   "[[inputs.exec]]\n  commands = [\"id\"]",
+			expected: `table "inputs" not allowed`,
+		},
+		{
+			name:     "other plugin",
+			config:   "[[outputs.exec]]\n  command = [\"id\"]",
+			expected: `table "outputs.exec" not allowed`,
+		},
+		{
+			name:     "second instance",
+			config:   "[[outputs.file]]\n  files = [\"/etc/passwd\"]",
+			expected: `table "outputs.file" not allowed`,
+		},
+		{
+			name:     "invalid toml",
+			config:   "files = [",
+			expected: "invalid config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient(
+				newResource("TelegrafOutput", "default", "file", map[string]interface{}{
+					"plugin": "file",
+					"config": tt.config,
+				}),
+			)
+			source := &Source{client: client}
+
+			_, err := source.Fetch(context.Background())
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestWaitForChange(t *testing.T) {
+	client := newFakeClient()
+	source := &Source{namespace: "default", client: client}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- source.WaitForChange(ctx)
+	}()
+
+	// Create resources until the watch picks up the change
+	var count int
+	require.Eventually(t, func() bool {
+		count++
+		name := fmt.Sprintf("cpu-%d", count)
+		obj := newResource("TelegrafInput", "default", name, map[string]interface{}{"plugin": "cpu"})
+		if _, err := client.Resource(resources[0].gvr).Namespace("default").Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			t.Logf("creating resource failed: %v", err)
+			return false
+		}
+
+		select {
+		case err := <-done:
+			return err == nil
+		default:
+			return false
+		}
+	}, 3*time.Second, 100*time.Millisecond)
+}
@@ -1,5 +1,7 @@
 package telegraf
 
+import "context"
+
 type Input interface {
 	PluginDescriber
 
@@ -21,3 +23,25 @@ type ServiceInput interface {
 	// to the accumulator before returning.
 	Stop()
 }
+
+// StoppableInput is a regular Input releasing resources, e.g. connections
+// kept across gathers, when the agent stops. In contrast to a ServiceInput
+// it is not started and gathered like any other Input.
+type StoppableInput interface {
+	Input
+
+	// Stop releases the resources of the plugin. Gather is not called
+	// after Stop.
+	Stop()
+}
+
+// DrainableInput is a ServiceInput supporting a graceful shutdown, e.g. to
+// acknowledge all consumed messages before exiting.
+type DrainableInput interface {
+	ServiceInput
+
+	// Drain stops consuming new data and waits until the tracking metrics
+	// of the data already consumed are delivered or the context is done.
+	// Stop is called after Drain returns.
+	Drain(ctx context.Context) error
+}
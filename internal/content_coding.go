@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/golang/snappy"
 	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zlib"
 	"github.com/klauspost/compress/zstd"
 	"github.com/klauspost/pgzip"
+	"github.com/pierrec/lz4/v4"
 )
 
 const defaultMaxDecompressionSize int64 = 500 * 1024 * 1024 // 500MB
@@ -113,6 +115,10 @@ func NewContentEncoder(encoding string, options ...EncodingOption) (ContentEncod
 		return NewZlibEncoder(options...)
 	case "zstd":
 		return NewZstdEncoder(options...)
+	case "snappy":
+		return NewSnappyEncoder(options...)
+	case "lz4":
+		return NewLZ4Encoder(options...)
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -156,6 +162,10 @@ func NewContentDecoder(encoding string, options ...DecodingOption) (ContentDecod
 		return NewZlibDecoder(options...), nil
 	case "zstd":
 		return NewZstdDecoder(options...)
+	case "snappy":
+		return NewSnappyDecoder(options...), nil
+	case "lz4":
+		return NewLZ4Decoder(options...), nil
 	default:
 		return nil, errors.New("invalid value for content_encoding")
 	}
@@ -318,6 +328,70 @@ func (e *ZstdEncoder) Encode(data []byte) ([]byte, error) {
 	return e.encoder.EncodeAll(data, make([]byte, 0, len(data))), nil
 }
 
+// SnappyEncoder compresses the buffer using the snappy block format.
+type SnappyEncoder struct {
+	buf []byte
+}
+
+func NewSnappyEncoder(options ...EncodingOption) (*SnappyEncoder, error) {
+	if len(options) > 0 {
+		return nil, errors.New("snappy encoder does not support options")
+	}
+
+	return &SnappyEncoder{}, nil
+}
+
+func (e *SnappyEncoder) Encode(data []byte) ([]byte, error) {
+	e.buf = snappy.Encode(e.buf[:cap(e.buf)], data)
+	return e.buf, nil
+}
+
+// LZ4Encoder compresses the buffer using the LZ4 frame format.
+type LZ4Encoder struct {
+	writer *lz4.Writer
+	buf    *bytes.Buffer
+}
+
+func NewLZ4Encoder(options ...EncodingOption) (*LZ4Encoder, error) {
+	cfg := encoderConfig{level: 0}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	// Map the levels
+	levels := []lz4.CompressionLevel{
+		lz4.Fast, lz4.Level1, lz4.Level2, lz4.Level3, lz4.Level4,
+		lz4.Level5, lz4.Level6, lz4.Level7, lz4.Level8, lz4.Level9,
+	}
+	if cfg.level < 0 || cfg.level >= len(levels) {
+		return nil, errors.New("invalid compression level, only 0 to 9 are supported")
+	}
+	level := levels[cfg.level]
+
+	var buf bytes.Buffer
+	w := lz4.NewWriter(&buf)
+	if err := w.Apply(lz4.CompressionLevelOption(level)); err != nil {
+		return nil, err
+	}
+	return &LZ4Encoder{
+		writer: w,
+		buf:    &buf,
+	}, nil
+}
+
+func (e *LZ4Encoder) Encode(data []byte) ([]byte, error) {
+	e.buf.Reset()
+	e.writer.Reset(e.buf)
+
+	if _, err := e.writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := e.writer.Close(); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
 // IdentityEncoder is a null encoder that applies no transformation.
 type IdentityEncoder struct{}
 
@@ -480,6 +554,91 @@ func (d *ZstdDecoder) Decode(data []byte) ([]byte, error) {
 	return d.decoder.DecodeAll(data, nil)
 }
 
+// snappyStreamMagic is the stream identifier chunk starting data in the
+// snappy framing format
+var snappyStreamMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+// SnappyDecoder decompresses buffers with snappy compression. Both, the block
+// and the framing format are supported.
+type SnappyDecoder struct {
+	buf                  *bytes.Buffer
+	block                []byte
+	maxDecompressionSize int64
+}
+
+func NewSnappyDecoder(options ...DecodingOption) *SnappyDecoder {
+	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return &SnappyDecoder{
+		buf:                  new(bytes.Buffer),
+		maxDecompressionSize: cfg.maxDecompressionSize,
+	}
+}
+
+func (*SnappyDecoder) SetEncoding(string) {}
+
+func (d *SnappyDecoder) Decode(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, snappyStreamMagic) {
+		d.buf.Reset()
+		n, err := io.CopyN(d.buf, snappy.NewReader(bytes.NewReader(data)), d.maxDecompressionSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		} else if n == d.maxDecompressionSize {
+			return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+		}
+		return d.buf.Bytes(), nil
+	}
+
+	// Check the size before decoding to avoid excessive allocations
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if int64(size) >= d.maxDecompressionSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+	}
+	d.block, err = snappy.Decode(d.block[:cap(d.block)], data)
+	return d.block, err
+}
+
+// LZ4Decoder decompresses buffers with LZ4 frame compression.
+type LZ4Decoder struct {
+	reader               *lz4.Reader
+	buf                  *bytes.Buffer
+	maxDecompressionSize int64
+}
+
+func NewLZ4Decoder(options ...DecodingOption) *LZ4Decoder {
+	cfg := decoderConfig{maxDecompressionSize: defaultMaxDecompressionSize}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return &LZ4Decoder{
+		reader:               lz4.NewReader(nil),
+		buf:                  new(bytes.Buffer),
+		maxDecompressionSize: cfg.maxDecompressionSize,
+	}
+}
+
+func (*LZ4Decoder) SetEncoding(string) {}
+
+func (d *LZ4Decoder) Decode(data []byte) ([]byte, error) {
+	d.reader.Reset(bytes.NewReader(data))
+	d.buf.Reset()
+
+	n, err := io.CopyN(d.buf, d.reader, d.maxDecompressionSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	} else if n == d.maxDecompressionSize {
+		return nil, fmt.Errorf("size of decoded data exceeds allowed size %d", d.maxDecompressionSize)
+	}
+	return d.buf.Bytes(), nil
+}
+
 // IdentityDecoder is a null decoder that returns the input.
 type IdentityDecoder struct {
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/require"
 )
 
@@ -112,6 +113,88 @@ func TestZstdReuse(t *testing.T) {
 	require.Equal(t, "doody", string(actual))
 }
 
+func TestSnappyEncodeDecode(t *testing.T) {
+	enc, err := NewSnappyEncoder()
+	require.NoError(t, err)
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+
+	payload, err = enc.Encode([]byte("doody"))
+	require.NoError(t, err)
+
+	actual, err = dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "doody", string(actual))
+}
+
+func TestSnappyStreamDecode(t *testing.T) {
+	var b bytes.Buffer
+	w := snappy.NewBufferedWriter(&b)
+	_, err := w.Write([]byte("howdy"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(maxDecompressionSize))
+	actual, err := dec.Decode(b.Bytes())
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+}
+
+func TestSnappyEncodeDecodeWithTooLargeMessage(t *testing.T) {
+	enc, err := NewSnappyEncoder()
+	require.NoError(t, err)
+	dec := NewSnappyDecoder(WithMaxDecompressionSize(3))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	_, err = dec.Decode(payload)
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 3")
+}
+
+func TestLZ4EncodeDecode(t *testing.T) {
+	enc, err := NewLZ4Encoder()
+	require.NoError(t, err)
+	dec := NewLZ4Decoder(WithMaxDecompressionSize(maxDecompressionSize))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+
+	payload, err = enc.Encode([]byte("doody"))
+	require.NoError(t, err)
+
+	actual, err = dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "doody", string(actual))
+}
+
+func TestLZ4EncodeDecodeWithTooLargeMessage(t *testing.T) {
+	enc, err := NewLZ4Encoder()
+	require.NoError(t, err)
+	dec := NewLZ4Decoder(WithMaxDecompressionSize(3))
+
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	_, err = dec.Decode(payload)
+	require.ErrorContains(t, err, "size of decoded data exceeds allowed size 3")
+}
+
 func TestIdentityEncodeDecode(t *testing.T) {
 	dec := NewIdentityDecoder(WithMaxDecompressionSize(maxDecompressionSize))
 	enc, err := NewIdentityEncoder()
@@ -181,10 +264,19 @@ func TestCompressionLevel(t *testing.T) {
 			validLevels: []int{1, 3, 7, 11},
 			errormsg:    "invalid compression level",
 		},
+		{
+			algorithm:   "lz4",
+			validLevels: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			errormsg:    "invalid compression level",
+		},
 		{
 			algorithm: "identity",
 			errormsg:  "does not support options",
 		},
+		{
+			algorithm: "snappy",
+			errormsg:  "does not support options",
+		},
 	}
 
 	for _, tt := range tests {
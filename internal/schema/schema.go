@@ -0,0 +1,145 @@
+// Package schema derives the measurement, tag and field schema produced by
+// a configuration from the configured plugins and observed metrics.
+package schema
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Schema describes the metrics produced by a configuration
+type Schema struct {
+	GlobalTags   map[string]string `json:"global_tags,omitempty"`
+	Plugins      []Plugin          `json:"plugins"`
+	Measurements []Measurement     `json:"measurements"`
+}
+
+// Plugin describes the configuration of an input plugin affecting the
+// produced metrics
+type Plugin struct {
+	Name              string            `json:"name"`
+	Alias             string            `json:"alias,omitempty"`
+	NameOverride      string            `json:"name_override,omitempty"`
+	MeasurementPrefix string            `json:"name_prefix,omitempty"`
+	MeasurementSuffix string            `json:"name_suffix,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+}
+
+// Measurement describes the tags and fields observed for a measurement
+type Measurement struct {
+	Name    string  `json:"name"`
+	Samples int     `json:"samples"`
+	Tags    []Tag   `json:"tags"`
+	Fields  []Field `json:"fields"`
+}
+
+// Tag describes a tag key. Optional tags were not present in all samples.
+type Tag struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Field describes a field key and the observed value types. Optional fields
+// were not present in all samples.
+type Field struct {
+	Name     string   `json:"name"`
+	Types    []string `json:"types"`
+	Optional bool     `json:"optional,omitempty"`
+}
+
+type measurement struct {
+	samples int
+	tags    map[string]int
+	fields  map[string]int
+	types   map[string]map[string]bool
+}
+
+// Collector accumulates the schema of the observed metrics
+type Collector struct {
+	measurements map[string]*measurement
+	sync.Mutex
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{measurements: make(map[string]*measurement)}
+}
+
+// Add records the measurement, tags and field types of the metric
+func (c *Collector) Add(m telegraf.Metric) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, found := c.measurements[m.Name()]
+	if !found {
+		entry = &measurement{
+			tags:   make(map[string]int),
+			fields: make(map[string]int),
+			types:  make(map[string]map[string]bool),
+		}
+		c.measurements[m.Name()] = entry
+	}
+	entry.samples++
+
+	for _, tag := range m.TagList() {
+		entry.tags[tag.Key]++
+	}
+	for _, field := range m.FieldList() {
+		entry.fields[field.Key]++
+		if entry.types[field.Key] == nil {
+			entry.types[field.Key] = make(map[string]bool)
+		}
+		entry.types[field.Key][fieldType(field.Value)] = true
+	}
+}
+
+// Measurements returns the observed measurements sorted by name
+func (c *Collector) Measurements() []Measurement {
+	c.Lock()
+	defer c.Unlock()
+
+	result := make([]Measurement, 0, len(c.measurements))
+	for _, name := range slices.Sorted(maps.Keys(c.measurements)) {
+		entry := c.measurements[name]
+		m := Measurement{
+			Name:    name,
+			Samples: entry.samples,
+			Tags:    make([]Tag, 0, len(entry.tags)),
+			Fields:  make([]Field, 0, len(entry.fields)),
+		}
+		for _, key := range slices.Sorted(maps.Keys(entry.tags)) {
+			m.Tags = append(m.Tags, Tag{
+				Name:     key,
+				Optional: entry.tags[key] < entry.samples,
+			})
+		}
+		for _, key := range slices.Sorted(maps.Keys(entry.fields)) {
+			m.Fields = append(m.Fields, Field{
+				Name:     key,
+				Types:    slices.Sorted(maps.Keys(entry.types[key])),
+				Optional: entry.fields[key] < entry.samples,
+			})
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func fieldType(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "float"
+	case int64:
+		return "integer"
+	case uint64:
+		return "unsigned"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "unknown"
+}
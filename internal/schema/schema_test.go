@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	c.Add(metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": uint64(1), "free": int64(2)}, time.Unix(0, 0)))
+	c.Add(metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"used": 1.5}, time.Unix(0, 0)))
+	c.Add(metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"busy": true, "state": "ok"}, time.Unix(0, 0)))
+
+	expected := []Measurement{
+		{
+			Name:    "cpu",
+			Samples: 1,
+			Tags:    []Tag{{Name: "cpu"}},
+			Fields: []Field{
+				{Name: "busy", Types: []string{"boolean"}},
+				{Name: "state", Types: []string{"string"}},
+			},
+		},
+		{
+			Name:    "mem",
+			Samples: 2,
+			Tags:    []Tag{{Name: "host"}},
+			Fields: []Field{
+				{Name: "free", Types: []string{"integer"}, Optional: true},
+				{Name: "used", Types: []string{"float", "unsigned"}},
+			},
+		},
+	}
+	require.Equal(t, expected, c.Measurements())
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -27,13 +30,13 @@ func (l *eventLogger) Close() error {
 	return l.eventlog.Close()
 }
 
-func (l *eventLogger) Print(level telegraf.LogLevel, _ time.Time, prefix string, _ map[string]interface{}, args ...interface{}) {
+func (l *eventLogger) Print(level telegraf.LogLevel, _ time.Time, prefix string, attr map[string]interface{}, args ...interface{}) {
 	// Skip debug and beyond as they cannot be logged
 	if level >= telegraf.Debug {
 		return
 	}
 
-	msg := level.Indicator() + " " + prefix + fmt.Sprint(args...)
+	msg := level.Indicator() + " " + prefix + fmt.Sprint(args...) + formatEventAttributes(attr)
 
 	var err error
 	switch level {
@@ -47,8 +50,31 @@ func (l *eventLogger) Print(level telegraf.LogLevel, _ time.Time, prefix string,
 	if err != nil {
 		l.errlog.Printf("E! Writing log message failed: %v", err)
 	}
+}
+
+// formatEventAttributes formats the attributes as sorted "key=value" lines
+// separated from the message by an empty line to allow filtering events in
+// the event viewer, e.g. by plugin
+func formatEventAttributes(attr map[string]interface{}) string {
+	if len(attr) == 0 {
+		return ""
+	}
 
-	// TODO attributes...
+	keys := make([]string, 0, len(attr))
+	for k := range attr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	buf.WriteString("\r\n")
+	for _, k := range keys {
+		buf.WriteString("\r\n")
+		buf.WriteString(k)
+		buf.WriteString("=")
+		buf.WriteString(strconv.Quote(fmt.Sprint(attr[k])))
+	}
+	return buf.String()
 }
 
 func createEventLogger(cfg *Config) (sink, error) {
@@ -91,3 +91,15 @@ func TestRestrictedEventLogIntegration(t *testing.T) {
 	require.Len(t, events, 1)
 	require.Contains(t, events, Event{Message: "Error message", Level: Error})
 }
+
+func TestFormatEventAttributes(t *testing.T) {
+	require.Empty(t, formatEventAttributes(nil))
+
+	attr := map[string]interface{}{
+		"plugin":   "cpu",
+		"category": "inputs",
+		"alias":    "my cpu",
+	}
+	expected := "\r\n\r\nalias=\"my cpu\"\r\ncategory=\"inputs\"\r\nplugin=\"cpu\""
+	require.Equal(t, expected, formatEventAttributes(attr))
+}
@@ -146,6 +146,28 @@ type UnwrappableMetric interface {
 	Unwrap() Metric
 }
 
+// Provenance describes the origin of a metric for tracing it through
+// multi-agent pipelines
+type Provenance struct {
+	// Plugin and Alias identify the input plugin creating the metric
+	Plugin string
+	Alias  string
+	// Host is the name of the host running the agent
+	Host string
+	// Source and Offset identify the originating message for consuming
+	// inputs e.g. the Kafka topic and partition and the message offset
+	Source string
+	Offset string
+}
+
+// NativeMetric is the representation of a metric in the data model of the
+// protocol it was received with. It is carried alongside the metric so that
+// plugins supporting the same protocol can avoid lossy conversions.
+type NativeMetric interface {
+	// Format returns the name of the data model, e.g. "otlp"
+	Format() string
+}
+
 type TrackingMetric interface {
 	// TrackingID returns the ID used for tracking the metric
 	TrackingID() TrackingID
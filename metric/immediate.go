@@ -0,0 +1,24 @@
+package metric
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// IsImmediate returns true if the metric should be written to the outputs
+// without waiting for the next flush interval.
+func IsImmediate(m telegraf.Metric) bool {
+	if raw := unwrap(m); raw != nil {
+		return raw.MetricImmediate
+	}
+	return false
+}
+
+// SetImmediate marks the metric to be written to the outputs within the
+// agent's 'immediate_flush_delay' instead of the next flush interval. Service
+// inputs can use this for alert-style events. Metrics not created by this
+// package cannot be marked and are left unchanged.
+func SetImmediate(m telegraf.Metric, immediate bool) {
+	if raw := unwrap(m); raw != nil {
+		raw.MetricImmediate = immediate
+	}
+}
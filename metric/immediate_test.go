@@ -0,0 +1,26 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestImmediate(t *testing.T) {
+	m := New("alert", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.False(t, IsImmediate(m))
+
+	SetImmediate(m, true)
+	require.True(t, IsImmediate(m))
+	require.True(t, IsImmediate(m.Copy()))
+	require.True(t, IsImmediate(FromMetric(m)))
+
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.True(t, IsImmediate(tm))
+
+	SetImmediate(m, false)
+	require.False(t, IsImmediate(m))
+}
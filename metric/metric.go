@@ -16,6 +16,12 @@ type metric struct {
 	MetricTime   time.Time
 
 	MetricType telegraf.ValueType
+
+	MetricProvenance *telegraf.Provenance
+	MetricImmediate  bool
+
+	// The native representation is not exported as it cannot be serialized
+	native telegraf.NativeMetric
 }
 
 func New(
@@ -73,6 +79,11 @@ func FromMetric(other telegraf.Metric) telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(other.FieldList())),
 		MetricTime:   other.Time(),
 		MetricType:   other.Type(),
+
+		MetricProvenance: GetProvenance(other),
+		MetricImmediate:  IsImmediate(other),
+
+		native: GetNative(other),
 	}
 
 	for i, tag := range other.TagList() {
@@ -253,6 +264,11 @@ func (m *metric) Copy() telegraf.Metric {
 		MetricFields: make([]*telegraf.Field, len(m.MetricFields)),
 		MetricTime:   m.MetricTime,
 		MetricType:   m.MetricType,
+
+		MetricProvenance: m.MetricProvenance,
+		MetricImmediate:  m.MetricImmediate,
+
+		native: m.native,
 	}
 
 	for i, tag := range m.MetricTags {
@@ -0,0 +1,26 @@
+package metric
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// GetNative returns the native representation attached to the metric or nil
+// if there is none. The returned value must not be modified as it is shared
+// between copies of the metric.
+func GetNative(m telegraf.Metric) telegraf.NativeMetric {
+	if raw := unwrap(m); raw != nil {
+		return raw.native
+	}
+	return nil
+}
+
+// SetNative attaches the native representation to the metric replacing any
+// existing one. Passing nil removes the representation. The representation
+// is not serialized, so metrics restored from the disk buffer lose it.
+// Metrics not created by this package cannot carry a native representation
+// and are left unchanged.
+func SetNative(m telegraf.Metric, native telegraf.NativeMetric) {
+	if raw := unwrap(m); raw != nil {
+		raw.native = native
+	}
+}
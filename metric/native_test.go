@@ -0,0 +1,43 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+type mockNative struct{}
+
+func (*mockNative) Format() string {
+	return "mock"
+}
+
+func TestNative(t *testing.T) {
+	m := New("cpu", nil, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Nil(t, GetNative(m))
+
+	native := &mockNative{}
+	SetNative(m, native)
+	require.Same(t, native, GetNative(m))
+	require.Same(t, native, GetNative(m.Copy()))
+	require.Same(t, native, GetNative(FromMetric(m)))
+
+	// Tracking metrics must expose the representation of the wrapped metric
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.Same(t, native, GetNative(tm))
+
+	// The representation is dropped on serialization
+	Init()
+	buf, err := ToBytes(m)
+	require.NoError(t, err)
+	decoded, err := FromBytes(buf)
+	require.NoError(t, err)
+	require.Nil(t, GetNative(decoded))
+	require.Equal(t, m.Name(), decoded.Name())
+
+	SetNative(m, nil)
+	require.Nil(t, GetNative(m))
+}
@@ -0,0 +1,72 @@
+package metric
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// GetProvenance returns the provenance attached to the metric or nil if the
+// metric does not carry provenance information. The returned value must not
+// be modified as it is shared between copies of the metric.
+func GetProvenance(m telegraf.Metric) *telegraf.Provenance {
+	if raw := unwrap(m); raw != nil {
+		return raw.MetricProvenance
+	}
+	return nil
+}
+
+// SetProvenance attaches the provenance to the metric replacing any existing
+// information. Passing nil removes the provenance. Metrics not created by
+// this package cannot carry provenance and are left unchanged.
+func SetProvenance(m telegraf.Metric, p *telegraf.Provenance) {
+	if raw := unwrap(m); raw != nil {
+		raw.MetricProvenance = p
+	}
+}
+
+// SetProvenanceOffset sets the source and offset of the originating message
+// keeping the other provenance information.
+func SetProvenanceOffset(m telegraf.Metric, source, offset string) {
+	var p telegraf.Provenance
+	if current := GetProvenance(m); current != nil {
+		p = *current
+	}
+	p.Source = source
+	p.Offset = offset
+	SetProvenance(m, &p)
+}
+
+// ProvenanceFields returns the non-empty provenance information of the metric
+// as fields with the given key prefix.
+func ProvenanceFields(m telegraf.Metric, prefix string) map[string]string {
+	p := GetProvenance(m)
+	if p == nil {
+		return nil
+	}
+
+	fields := make(map[string]string, 5)
+	for k, v := range map[string]string{
+		"plugin": p.Plugin,
+		"alias":  p.Alias,
+		"host":   p.Host,
+		"source": p.Source,
+		"offset": p.Offset,
+	} {
+		if v != "" {
+			fields[prefix+k] = v
+		}
+	}
+	return fields
+}
+
+func unwrap(m telegraf.Metric) *metric {
+	for {
+		switch v := m.(type) {
+		case *metric:
+			return v
+		case telegraf.UnwrappableMetric:
+			m = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
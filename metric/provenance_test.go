@@ -0,0 +1,51 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestProvenance(t *testing.T) {
+	m := New("cpu", nil, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Nil(t, GetProvenance(m))
+	require.Empty(t, ProvenanceFields(m, "provenance_"))
+
+	SetProvenance(m, &telegraf.Provenance{Plugin: "kafka_consumer", Host: "agent01"})
+	SetProvenanceOffset(m, "telegraf/0", "123")
+	expected := &telegraf.Provenance{
+		Plugin: "kafka_consumer",
+		Host:   "agent01",
+		Source: "telegraf/0",
+		Offset: "123",
+	}
+	require.Equal(t, expected, GetProvenance(m))
+	require.Equal(t, expected, GetProvenance(m.Copy()))
+	require.Equal(t, expected, GetProvenance(FromMetric(m)))
+
+	fields := map[string]string{
+		"provenance_plugin": "kafka_consumer",
+		"provenance_host":   "agent01",
+		"provenance_source": "telegraf/0",
+		"provenance_offset": "123",
+	}
+	require.Equal(t, fields, ProvenanceFields(m, "provenance_"))
+
+	// Tracking metrics must expose the provenance of the wrapped metric
+	tm, _ := WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.Equal(t, expected, GetProvenance(tm))
+
+	// The provenance must survive serialization e.g. for the disk buffer
+	Init()
+	buf, err := ToBytes(m)
+	require.NoError(t, err)
+	decoded, err := FromBytes(buf)
+	require.NoError(t, err)
+	require.Equal(t, expected, GetProvenance(decoded))
+
+	SetProvenance(m, nil)
+	require.Nil(t, GetProvenance(m))
+}
@@ -52,8 +52,20 @@ type BufferStats struct {
 	BufferLimit    selfstat.Stat
 }
 
+// DiskBufferOptions contains the settings specific to the "disk" strategy
+type DiskBufferOptions struct {
+	// Limit is the maximum size of the buffered metric data in bytes,
+	// zero means no limit
+	Limit int64
+
+	// Fsync is the policy for syncing the buffer to disk, supported are
+	// "always", the default, syncing after each write, "flush" syncing before
+	// each write to the output and "never" leaving syncing to the OS
+	Fsync string
+}
+
 // NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buffer, error) {
+func NewBuffer(name, id, alias string, capacity int, strategy, path string, disk DiskBufferOptions) (Buffer, error) {
 	registerGob()
 
 	bs := NewBufferStats(name, alias, capacity)
@@ -62,7 +74,7 @@ func NewBuffer(name, id, alias string, capacity int, strategy, path string) (Buf
 	case "", "memory":
 		return NewMemoryBuffer(capacity, bs)
 	case "disk":
-		return NewDiskBuffer(name, id, path, bs)
+		return NewDiskBuffer(name, id, path, disk, bs)
 	}
 	return nil, fmt.Errorf("invalid buffer strategy %q", strategy)
 }
@@ -17,11 +17,16 @@ type DiskBuffer struct {
 	BufferStats
 	sync.Mutex
 
-	file *wal.Log
-	path string
+	file  *wal.Log
+	path  string
+	fsync string
+
+	size  int64 // Size of the metric data in the buffer in bytes
+	limit int64 // Maximum size of the metric data, zero for no limit
 
 	batchFirst uint64 // Index of the first metric in the batch
 	batchSize  uint64 // Number of metrics currently in the batch
+	batchBytes []int  // Size of the entries read for the batch including skipped ones
 
 	// Ending point of metrics read from disk on telegraf launch.
 	// Used to know whether to discard tracking metrics.
@@ -33,9 +38,22 @@ type DiskBuffer struct {
 	isEmpty bool
 }
 
-func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error) {
+func NewDiskBuffer(name, id, path string, opts DiskBufferOptions, stats BufferStats) (*DiskBuffer, error) {
+	walOpts := *wal.DefaultOptions
+	switch opts.Fsync {
+	case "", "always":
+		opts.Fsync = "always"
+	case "flush", "never":
+		walOpts.NoSync = true
+	default:
+		return nil, fmt.Errorf("invalid buffer fsync policy %q", opts.Fsync)
+	}
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("invalid buffer disk limit %d", opts.Limit)
+	}
+
 	filePath := filepath.Join(path, id)
-	walFile, err := wal.Open(filePath, nil)
+	walFile, err := wal.Open(filePath, &walOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open wal file: %w", err)
 	}
@@ -50,9 +68,18 @@ func NewDiskBuffer(name, id, path string, stats BufferStats) (*DiskBuffer, error
 		BufferStats: stats,
 		file:        walFile,
 		path:        filePath,
+		fsync:       opts.Fsync,
+		limit:       opts.Limit,
 	}
 	if buf.length() > 0 {
 		buf.originalEnd = buf.writeIndex()
+		for index := buf.readIndex(); index < buf.originalEnd; index++ {
+			data, err := walFile.Read(index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read wal file: %w", err)
+			}
+			buf.size += int64(len(data))
+		}
 	}
 	return buf, nil
 }
@@ -113,9 +140,16 @@ func (b *DiskBuffer) addSingleMetric(m telegraf.Metric) bool {
 	if err != nil {
 		panic(err)
 	}
+	// Drop new metrics if the limit is reached as removing the oldest ones
+	// would interfere with batches currently being written
+	if b.limit > 0 && b.size+int64(len(data)) > b.limit {
+		b.metricDropped(m)
+		return false
+	}
 	err = b.file.Write(b.writeIndex(), data)
 	if err == nil {
 		b.metricAdded()
+		b.size += int64(len(data))
 		return true
 	}
 	return false
@@ -129,10 +163,16 @@ func (b *DiskBuffer) Batch(batchSize int) []telegraf.Metric {
 		// no metrics in the wal file, so return an empty array
 		return []telegraf.Metric{}
 	}
+	if b.fsync == "flush" {
+		if err := b.file.Sync(); err != nil {
+			log.Printf("E! Syncing wal file %s failed: %v", b.path, err)
+		}
+	}
 	b.batchFirst = b.readIndex()
 	var metrics []telegraf.Metric
 
 	b.batchSize = 0
+	b.batchBytes = b.batchBytes[:0]
 	readIndex := b.batchFirst
 	endIndex := b.writeIndex()
 	for batchSize > 0 && readIndex < endIndex {
@@ -141,6 +181,7 @@ func (b *DiskBuffer) Batch(batchSize int) []telegraf.Metric {
 			panic(err)
 		}
 		readIndex++
+		b.batchBytes = append(b.batchBytes, len(data))
 
 		m, err := metric.FromBytes(data)
 
@@ -186,7 +227,11 @@ func (b *DiskBuffer) Accept(batch []telegraf.Metric) {
 	}
 	if b.length() == len(batch) {
 		b.emptyFile()
+		b.size = 0
 	} else {
+		for _, n := range b.batchBytes[:min(len(batch), len(b.batchBytes))] {
+			b.size -= int64(n)
+		}
 		err := b.file.TruncateFront(b.batchFirst + uint64(len(batch)))
 		if err != nil {
 			log.Printf("E! batch length: %d, batchFirst: %d, batchSize: %d", len(batch), b.batchFirst, b.batchSize)
@@ -222,6 +267,7 @@ func (b *DiskBuffer) Close() error {
 func (b *DiskBuffer) resetBatch() {
 	b.batchFirst = 0
 	b.batchSize = 0
+	b.batchBytes = b.batchBytes[:0]
 }
 
 // This is very messy and not ideal, but serves as the only way I can find currently
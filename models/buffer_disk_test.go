@@ -19,7 +19,7 @@ func TestDiskBufferRetainsTrackingInformation(t *testing.T) {
 	var delivered int
 	mm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) { delivered++ })
 
-	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir())
+	buf, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferOptions{})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -78,7 +78,7 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	walfile.Close()
 
 	// Create a buffer
-	buf, err := NewBuffer("123", "123", "", 0, "disk", path)
+	buf, err := NewBuffer("123", "123", "", 0, "disk", path, DiskBufferOptions{})
 	require.NoError(t, err)
 	buf.Stats().MetricsAdded.Set(0)
 	buf.Stats().MetricsWritten.Set(0)
@@ -93,3 +93,46 @@ func TestDiskBufferTrackingDroppedFromOldWal(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, batch)
 }
+
+func TestDiskBufferLimit(t *testing.T) {
+	registerGob()
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	data, err := metric.ToBytes(m)
+	require.NoError(t, err)
+	entrySize := int64(len(data))
+
+	path := t.TempDir()
+	opts := DiskBufferOptions{Limit: 3 * entrySize, Fsync: "flush"}
+	buf, err := NewBuffer("test", "123", "", 0, "disk", path, opts)
+	require.NoError(t, err)
+	buf.Stats().MetricsDropped.Set(0)
+
+	// Metrics exceeding the limit are dropped
+	require.Equal(t, 2, buf.Add(m.Copy(), m.Copy(), m.Copy(), m.Copy(), m.Copy()))
+	require.Equal(t, 3, buf.Len())
+	require.Equal(t, int64(2), buf.Stats().MetricsDropped.Get())
+
+	// Writing metrics frees space for new ones
+	batch := buf.Batch(2)
+	require.Len(t, batch, 2)
+	buf.Accept(batch)
+	require.Zero(t, buf.Add(m.Copy(), m.Copy()))
+	require.Equal(t, 1, buf.Add(m.Copy()))
+	require.Equal(t, 3, buf.Len())
+	require.NoError(t, buf.Close())
+
+	// The size of existing metrics is restored on startup
+	buf, err = NewBuffer("test", "123", "", 0, "disk", path, opts)
+	require.NoError(t, err)
+	defer buf.Close()
+	require.Equal(t, 3, buf.Len())
+	require.Equal(t, 1, buf.Add(m.Copy()))
+}
+
+func TestDiskBufferInvalidOptions(t *testing.T) {
+	_, err := NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferOptions{Fsync: "sometimes"})
+	require.ErrorContains(t, err, `invalid buffer fsync policy "sometimes"`)
+
+	_, err = NewBuffer("test", "123", "", 0, "disk", t.TempDir(), DiskBufferOptions{Limit: -1})
+	require.ErrorContains(t, err, "invalid buffer disk limit -1")
+}
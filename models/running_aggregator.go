@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -18,12 +20,21 @@ type RunningAggregator struct {
 	periodEnd   time.Time
 	log         telegraf.Logger
 
+	// Metrics of the current period kept for persisting the state
+	pending  []telegraf.Metric
+	restored *aggregatorState
+
 	MetricsPushed   selfstat.Stat
 	MetricsFiltered selfstat.Stat
 	MetricsDropped  selfstat.Stat
+	PendingDropped  selfstat.Stat
 	PushTime        selfstat.Stat
 }
 
+// DefaultMaxPendingMetrics is the default limit of metrics kept per period
+// for persisting the state of an aggregator
+const DefaultMaxPendingMetrics = 100000
+
 func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConfig) *RunningAggregator {
 	tags := map[string]string{"aggregator": config.Name}
 	if config.Alias != "" {
@@ -58,6 +69,11 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 			"metrics_dropped",
 			tags,
 		),
+		PendingDropped: selfstat.Register(
+			"aggregate",
+			"pending_metrics_dropped",
+			tags,
+		),
 		PushTime: selfstat.Register(
 			"aggregate",
 			"push_time_ns",
@@ -73,11 +89,21 @@ type AggregatorConfig struct {
 	Alias        string
 	ID           string
 	DropOriginal bool
+	PersistState bool
 	Period       time.Duration
 	Delay        time.Duration
 	Grace        time.Duration
 	LogLevel     string
 
+	// Maximum number of metrics kept per period for persisting the state,
+	// defaults to DefaultMaxPendingMetrics
+	MaxPendingMetrics int
+
+	// Calendar-aligned period ("daily", "weekly" or "monthly") in the given
+	// location overriding the fixed-duration period
+	CalendarPeriod string
+	Location       *time.Location
+
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
@@ -110,6 +136,33 @@ func (r *RunningAggregator) Period() time.Duration {
 	return r.Config.Period
 }
 
+// CalendarWindow returns the calendar-aligned aggregation window containing
+// the given time. The boundaries are computed on the local calendar of the
+// configured location so days may be shorter or longer than 24 hours
+// during daylight-saving transitions.
+func (r *RunningAggregator) CalendarWindow(t time.Time) (since, until time.Time) {
+	loc := r.Config.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+
+	switch r.Config.CalendarPeriod {
+	case "weekly":
+		// Weeks start on Monday according to ISO 8601
+		offset := (int(t.Weekday()) + 6) % 7
+		since = time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+		until = since.AddDate(0, 0, 7)
+	case "monthly":
+		since = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		until = since.AddDate(0, 1, 0)
+	default:
+		since = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		until = since.AddDate(0, 0, 1)
+	}
+	return since, until
+}
+
 func (r *RunningAggregator) EndPeriod() time.Time {
 	return r.periodEnd
 }
@@ -118,6 +171,83 @@ func (r *RunningAggregator) UpdateWindow(start, until time.Time) {
 	r.periodStart = start
 	r.periodEnd = until
 	r.log.Debugf("Updated aggregation range [%s, %s]", start, until)
+
+	if r.restored != nil {
+		r.replay()
+	}
+}
+
+// aggregatorState holds the metrics of an aggregation period persisted
+// across restarts
+type aggregatorState struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Metrics     [][]byte  `json:"metrics"`
+}
+
+func (r *RunningAggregator) GetState() interface{} {
+	registerGob()
+
+	r.Lock()
+	defer r.Unlock()
+
+	state := aggregatorState{
+		PeriodStart: r.periodStart,
+		PeriodEnd:   r.periodEnd,
+		Metrics:     make([][]byte, 0, len(r.pending)),
+	}
+	for _, m := range r.pending {
+		buf, err := metric.ToBytes(m)
+		if err != nil {
+			r.log.Errorf("Serializing metric for state failed: %v", err)
+			continue
+		}
+		state.Metrics = append(state.Metrics, buf)
+	}
+	return state
+}
+
+func (r *RunningAggregator) SetState(state interface{}) error {
+	s, ok := state.(aggregatorState)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", state)
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.restored = &s
+
+	return nil
+}
+
+// replay adds the metrics of the restored state falling into the current
+// aggregation window to the aggregator
+func (r *RunningAggregator) replay() {
+	registerGob()
+
+	state := r.restored
+	r.restored = nil
+
+	var added, dropped int
+	for _, buf := range state.Metrics {
+		m, err := metric.FromBytes(buf)
+		if err != nil {
+			if !errors.Is(err, metric.ErrSkipTracking) {
+				r.log.Errorf("Restoring metric from state failed: %v", err)
+			}
+			continue
+		}
+		if m.Time().Before(r.periodStart.Add(-r.Config.Grace)) || m.Time().After(r.periodEnd.Add(r.Config.Delay)) {
+			dropped++
+			continue
+		}
+		r.Aggregator.Add(m)
+		r.keep(m)
+		added++
+	}
+	r.MetricsDropped.Incr(int64(dropped))
+	r.log.Debugf("Restored %d metrics of period [%s, %s], discarded %d outside of the current window",
+		added, state.PeriodStart, state.PeriodEnd, dropped)
 }
 
 func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.Metric {
@@ -167,15 +297,49 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	}
 
 	r.Aggregator.Add(m)
+	if r.persistMetrics() {
+		r.keep(m)
+	}
 	return r.Config.DropOriginal
 }
 
+// persistMetrics returns true if the metrics of the current period are kept
+// for persisting the state, i.e. if the aggregator does not persist its own
+// state
+func (r *RunningAggregator) persistMetrics() bool {
+	if !r.Config.PersistState {
+		return false
+	}
+	_, stateful := r.Aggregator.(telegraf.StatefulPlugin)
+	return !stateful
+}
+
+// keep adds the metric to the pending metrics of the current period unless
+// the limit is reached
+func (r *RunningAggregator) keep(m telegraf.Metric) {
+	limit := r.Config.MaxPendingMetrics
+	if limit <= 0 {
+		limit = DefaultMaxPendingMetrics
+	}
+	if len(r.pending) >= limit {
+		if r.PendingDropped.Get() == 0 {
+			r.log.Warnf("Reached limit of %d pending metrics, metrics exceeding the limit of a period are not persisted", limit)
+		}
+		r.PendingDropped.Incr(1)
+		return
+	}
+	r.pending = append(r.pending, m)
+}
+
 func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Lock()
 	defer r.Unlock()
 
 	since := r.periodEnd
 	until := r.periodEnd.Add(r.Config.Period)
+	if r.Config.CalendarPeriod != "" {
+		_, until = r.CalendarWindow(since)
+	}
 	r.UpdateWindow(since, until)
 
 	start := time.Now()
@@ -183,6 +347,7 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	elapsed := time.Since(start)
 	r.PushTime.Incr(elapsed.Nanoseconds())
 	r.Aggregator.Reset()
+	r.pending = nil
 }
 
 func (r *RunningAggregator) Log() telegraf.Logger {
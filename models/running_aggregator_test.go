@@ -1,12 +1,14 @@
 package models
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -239,6 +241,191 @@ func TestRunningAggregatorAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestRunningAggregatorPersistState(t *testing.T) {
+	config := &AggregatorConfig{
+		Name:         "TestRunningAggregator",
+		ID:           "aggregator",
+		PersistState: true,
+		Filter: Filter{
+			NamePass: []string{"*"},
+		},
+		Period: time.Hour,
+	}
+	require.NoError(t, config.Filter.Compile())
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	filename := filepath.Join(t.TempDir(), "states.json")
+
+	// Accumulate part of the period and store the state on shutdown
+	ra := NewRunningAggregator(&mockAggregator{}, config)
+	ra.UpdateWindow(start, start.Add(time.Hour))
+	for i, offset := range []time.Duration{time.Minute, 10 * time.Minute} {
+		m := testutil.MustMetric("RITest",
+			map[string]string{},
+			map[string]interface{}{"value": int64(i + 1)},
+			start.Add(offset),
+		)
+		require.False(t, ra.Add(m))
+	}
+	p := &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register(ra.ID(), ra))
+	require.NoError(t, p.Store())
+
+	// Restore the state in a new instance within the same period and add
+	// more metrics
+	restored := NewRunningAggregator(&mockAggregator{}, config)
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register(restored.ID(), restored))
+	require.NoError(t, p.Load())
+	restored.UpdateWindow(start, start.Add(time.Hour))
+
+	m := testutil.MustMetric("RITest",
+		map[string]string{},
+		map[string]interface{}{"value": int64(4)},
+		start.Add(30*time.Minute),
+	)
+	require.False(t, restored.Add(m))
+
+	var acc testutil.Accumulator
+	restored.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(7), acc.Metrics[0].Fields["sum"])
+
+	// Metrics of an elapsed period must not be restored
+	elapsed := NewRunningAggregator(&mockAggregator{}, config)
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register(elapsed.ID(), elapsed))
+	require.NoError(t, p.Load())
+	elapsed.UpdateWindow(start.Add(time.Hour), start.Add(2*time.Hour))
+
+	acc.ClearMetrics()
+	elapsed.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(0), acc.Metrics[0].Fields["sum"])
+}
+
+func TestRunningAggregatorPersistStateLimit(t *testing.T) {
+	config := &AggregatorConfig{
+		Name:              "TestRunningAggregator",
+		PersistState:      true,
+		MaxPendingMetrics: 2,
+		Filter: Filter{
+			NamePass: []string{"*"},
+		},
+		Period: time.Hour,
+	}
+	require.NoError(t, config.Filter.Compile())
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ra := NewRunningAggregator(&mockAggregator{}, config)
+	ra.UpdateWindow(start, start.Add(time.Hour))
+	for i := range 5 {
+		m := testutil.MustMetric("RITest",
+			map[string]string{},
+			map[string]interface{}{"value": int64(i + 1)},
+			start.Add(time.Duration(i)*time.Minute),
+		)
+		require.False(t, ra.Add(m))
+	}
+
+	// All metrics are aggregated but only the limit is kept for the state
+	state, ok := ra.GetState().(aggregatorState)
+	require.True(t, ok)
+	require.Len(t, state.Metrics, 2)
+	require.Equal(t, int64(3), ra.PendingDropped.Get())
+
+	var acc testutil.Accumulator
+	ra.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(15), acc.Metrics[0].Fields["sum"])
+}
+
+func TestRunningAggregatorCalendarWindow(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		period   string
+		input    time.Time
+		expected []time.Time
+	}{
+		{
+			name:   "daily",
+			period: "daily",
+			input:  time.Date(2024, 6, 5, 13, 30, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 6, 5, 0, 0, 0, 0, loc),
+				time.Date(2024, 6, 6, 0, 0, 0, 0, loc),
+				time.Date(2024, 6, 7, 0, 0, 0, 0, loc),
+			},
+		},
+		{
+			name:   "daily across daylight-saving start",
+			period: "daily",
+			input:  time.Date(2024, 3, 9, 23, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 3, 9, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 10, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 11, 0, 0, 0, 0, loc),
+			},
+		},
+		{
+			name:   "weekly",
+			period: "weekly",
+			input:  time.Date(2024, 6, 2, 10, 0, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 5, 27, 0, 0, 0, 0, loc),
+				time.Date(2024, 6, 3, 0, 0, 0, 0, loc),
+				time.Date(2024, 6, 10, 0, 0, 0, 0, loc),
+			},
+		},
+		{
+			name:   "monthly",
+			period: "monthly",
+			input:  time.Date(2024, 1, 31, 23, 59, 0, 0, loc),
+			expected: []time.Time{
+				time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+				time.Date(2024, 2, 1, 0, 0, 0, 0, loc),
+				time.Date(2024, 3, 1, 0, 0, 0, 0, loc),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ra := NewRunningAggregator(&mockAggregator{}, &AggregatorConfig{
+				Name:           "TestRunningAggregator",
+				CalendarPeriod: tt.period,
+				Location:       loc,
+				Period:         time.Second,
+			})
+
+			// The input is given in UTC to check the conversion to the location
+			since, until := ra.CalendarWindow(tt.input.UTC())
+			require.True(t, tt.expected[0].Equal(since), "since %s", since)
+			require.True(t, tt.expected[1].Equal(until), "until %s", until)
+
+			// Pushing advances to the next calendar period
+			ra.UpdateWindow(since, until)
+			ra.Push(&testutil.Accumulator{})
+			require.True(t, tt.expected[2].Equal(ra.EndPeriod()), "end %s", ra.EndPeriod())
+		})
+	}
+
+	// The day of the daylight-saving start only has 23 hours
+	ra := NewRunningAggregator(&mockAggregator{}, &AggregatorConfig{
+		Name:           "TestRunningAggregator",
+		CalendarPeriod: "daily",
+		Location:       loc,
+	})
+	since, until := ra.CalendarWindow(time.Date(2024, 3, 10, 12, 0, 0, 0, loc))
+	require.Equal(t, 23*time.Hour, until.Sub(since))
+}
+
 type mockAggregator struct {
 	sum int64
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
+	telegraf_metric "github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -92,6 +93,12 @@ type InputConfig struct {
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
+	ClusterSingleton     bool
+	Spool                bool
+	SpoolLimit           int
+	Provenance           bool
+	Hostname             string
+	Immediate            bool
 
 	NameOverride            string
 	MeasurementPrefix       string
@@ -171,7 +178,10 @@ func (r *RunningInput) Start(acc telegraf.Accumulator) error {
 }
 
 func (r *RunningInput) Stop() {
-	if plugin, ok := r.Input.(telegraf.ServiceInput); ok {
+	switch plugin := r.Input.(type) {
+	case telegraf.ServiceInput:
+		plugin.Stop()
+	case telegraf.StoppableInput:
 		plugin.Stop()
 	}
 }
@@ -217,6 +227,17 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		makemetric(metric, "", "", "", local, global)
 	}
 
+	if r.Config.Provenance {
+		r.addProvenance(metric)
+	} else {
+		telegraf_metric.SetProvenance(metric, nil)
+	}
+
+	// Keep metrics marked by the plugin itself
+	if r.Config.Immediate {
+		telegraf_metric.SetImmediate(metric, true)
+	}
+
 	switch r.Config.TimeSource {
 	case "collection_start":
 		metric.SetTime(r.gatherStart)
@@ -230,6 +251,19 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 	return metric
 }
 
+// addProvenance identifies the plugin and host as origin of the metric keeping
+// the source and offset set by the plugin
+func (r *RunningInput) addProvenance(m telegraf.Metric) {
+	var p telegraf.Provenance
+	if current := telegraf_metric.GetProvenance(m); current != nil {
+		p = *current
+	}
+	p.Plugin = r.Config.Name
+	p.Alias = r.Config.Alias
+	p.Host = r.Config.Hostname
+	telegraf_metric.SetProvenance(m, &p)
+}
+
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	// Try to connect if we are not yet started up
 	if plugin, ok := r.Input.(telegraf.ServiceInput); ok && !r.started {
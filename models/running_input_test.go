@@ -243,6 +243,53 @@ func TestRunningInputMakeMetricNameSuffix(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestRunningInputMakeMetricProvenance(t *testing.T) {
+	now := time.Now()
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:       "TestRunningInput",
+		Alias:      "foo",
+		Provenance: true,
+		Hostname:   "agent01",
+	})
+
+	m := metric.New("RITest", nil, map[string]interface{}{"value": int64(101)}, now)
+	metric.SetProvenanceOffset(m, "topic/1", "42")
+	actual := ri.MakeMetric(m)
+	expected := &telegraf.Provenance{
+		Plugin: "TestRunningInput",
+		Alias:  "foo",
+		Host:   "agent01",
+		Source: "topic/1",
+		Offset: "42",
+	}
+	require.Equal(t, expected, metric.GetProvenance(actual))
+	require.Equal(t, expected, metric.GetProvenance(actual.Copy()))
+
+	// Provenance set by plugins is removed if not enabled
+	ri = NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput"})
+	m = metric.New("RITest", nil, map[string]interface{}{"value": int64(101)}, now)
+	metric.SetProvenanceOffset(m, "topic/1", "42")
+	require.Nil(t, metric.GetProvenance(ri.MakeMetric(m)))
+}
+
+func TestRunningInputMakeMetricImmediate(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:      "TestRunningInput",
+		Immediate: true,
+	})
+	m := metric.New("RITest", nil, map[string]interface{}{"value": int64(101)}, time.Now())
+	require.True(t, metric.IsImmediate(ri.MakeMetric(m)))
+
+	// Metrics marked by the plugin are kept
+	ri = NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput"})
+	m = metric.New("RITest", nil, map[string]interface{}{"value": int64(101)}, time.Now())
+	metric.SetImmediate(m, true)
+	require.True(t, metric.IsImmediate(ri.MakeMetric(m)))
+
+	m = metric.New("RITest", nil, map[string]interface{}{"value": int64(101)}, time.Now())
+	require.False(t, metric.IsImmediate(ri.MakeMetric(m)))
+}
+
 func TestRunningInputMetricErrorCounters(t *testing.T) {
 	ri := NewRunningInput(&mockInput{}, &InputConfig{
 		Name: "TestMetricErrorCounters",
@@ -487,6 +534,19 @@ func TestRunningInputMakeMetricWithGatherEndTimeSource(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestRunningInputStopStoppableInput(t *testing.T) {
+	plugin := &stoppableInput{}
+	ri := NewRunningInput(plugin, &InputConfig{Name: "stoppable"})
+	require.NoError(t, ri.Init())
+
+	// The plugin is no service input so starting it is a no-op
+	require.NoError(t, ri.Start(&testutil.Accumulator{}))
+	require.False(t, plugin.stopped)
+
+	ri.Stop()
+	require.True(t, plugin.stopped)
+}
+
 type mockInput struct{}
 
 func (t *mockInput) SampleConfig() string {
@@ -496,3 +556,12 @@ func (t *mockInput) SampleConfig() string {
 func (t *mockInput) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
+
+type stoppableInput struct {
+	mockInput
+	stopped bool
+}
+
+func (t *stoppableInput) Stop() {
+	t.stopped = true
+}
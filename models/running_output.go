@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
+	telegraf_metric "github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -40,8 +41,12 @@ type OutputConfig struct {
 
 	BufferStrategy  string
 	BufferDirectory string
+	BufferDiskLimit int64
+	BufferFsync     string
 
 	LogLevel string
+
+	ProvenanceFields bool
 }
 
 // RunningOutput contains the output configuration
@@ -59,7 +64,8 @@ type RunningOutput struct {
 	WriteTime       selfstat.Stat
 	StartupErrors   selfstat.Stat
 
-	BatchReady chan time.Time
+	BatchReady     chan time.Time
+	ImmediateReady chan time.Time
 
 	buffer Buffer
 	log    telegraf.Logger
@@ -104,7 +110,11 @@ func NewRunningOutput(
 		batchSize = DefaultMetricBatchSize
 	}
 
-	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory)
+	diskOpts := DiskBufferOptions{
+		Limit: config.BufferDiskLimit,
+		Fsync: config.BufferFsync,
+	}
+	b, err := NewBuffer(config.Name, config.ID, config.Alias, bufferLimit, config.BufferStrategy, config.BufferDirectory, diskOpts)
 	if err != nil {
 		panic(err)
 	}
@@ -112,6 +122,7 @@ func NewRunningOutput(
 	ro := &RunningOutput{
 		buffer:            b,
 		BatchReady:        make(chan time.Time, 1),
+		ImmediateReady:    make(chan time.Time, 1),
 		Output:            output,
 		Config:            config,
 		MetricBufferLimit: bufferLimit,
@@ -264,9 +275,23 @@ func (r *RunningOutput) add(metric telegraf.Metric) {
 		metric.AddSuffix(r.Config.NameSuffix)
 	}
 
+	if r.Config.ProvenanceFields {
+		for k, v := range telegraf_metric.ProvenanceFields(metric, "provenance_") {
+			metric.AddField(k, v)
+		}
+	}
+
+	immediate := telegraf_metric.IsImmediate(metric)
 	dropped := r.buffer.Add(metric)
 	atomic.AddInt64(&r.droppedMetrics, int64(dropped))
 
+	if immediate {
+		select {
+		case r.ImmediateReady <- time.Now():
+		default:
+		}
+	}
+
 	count := atomic.AddInt64(&r.newMetricsCount, 1)
 	if count == int64(r.MetricBatchSize) {
 		atomic.StoreInt64(&r.newMetricsCount, 0)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -218,6 +219,53 @@ func TestRunningOutputNameSuffix(t *testing.T) {
 	require.Equal(t, "metric1_suffix", m.Metrics()[0].Name())
 }
 
+// Test that the provenance is added as fields
+func TestRunningOutputProvenanceFields(t *testing.T) {
+	conf := &OutputConfig{
+		ProvenanceFields: true,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	input := testutil.TestMetric(101, "metric1")
+	metric.SetProvenance(input, &telegraf.Provenance{Plugin: "test", Host: "agent01", Offset: "42"})
+	ro.AddMetric(input)
+	ro.AddMetric(testutil.TestMetric(101, "metric2"))
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 2)
+	expected := map[string]interface{}{
+		"value":             int64(101),
+		"provenance_plugin": "test",
+		"provenance_host":   "agent01",
+		"provenance_offset": "42",
+	}
+	require.Equal(t, expected, m.Metrics()[0].Fields())
+	require.Equal(t, map[string]interface{}{"value": int64(101)}, m.Metrics()[1].Fields())
+	require.Len(t, input.FieldList(), 1)
+}
+
+// Test that immediate metrics signal the agent to write them
+func TestRunningOutputImmediate(t *testing.T) {
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, &OutputConfig{}, 1000, 10000)
+
+	ro.AddMetric(testutil.TestMetric(101, "metric1"))
+	require.Empty(t, ro.ImmediateReady)
+
+	input := testutil.TestMetric(101, "alert")
+	metric.SetImmediate(input, true)
+	ro.AddMetric(input)
+	ro.AddMetric(input)
+	require.Len(t, ro.ImmediateReady, 1)
+	require.Empty(t, ro.BatchReady)
+
+	<-ro.ImmediateReady
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 3)
+}
+
 // Test that we can write metrics with simple default setup.
 func TestRunningOutputDefault(t *testing.T) {
 	conf := &OutputConfig{
@@ -1,10 +1,12 @@
 package models
 
 import (
+	"io"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -93,6 +95,26 @@ func (r *RunningParser) ParseLine(line string) (telegraf.Metric, error) {
 	return m, err
 }
 
+// ParseStream parses the data of the reader calling the given function for
+// each metric. Parsers not supporting streaming read all data at once.
+func (r *RunningParser) ParseStream(reader io.Reader, fn func(telegraf.Metric) error) error {
+	// Exclude the time spent in the callback from the parsing time
+	var count int64
+	var callbackTime time.Duration
+	start := time.Now()
+	err := parsers.ParseStream(r.Parser, reader, func(m telegraf.Metric) error {
+		count++
+		callbackStart := time.Now()
+		defer func() { callbackTime += time.Since(callbackStart) }()
+		return fn(m)
+	})
+	elapsed := time.Since(start) - callbackTime
+	r.ParseTime.Incr(elapsed.Nanoseconds())
+	r.MetricsParsed.Incr(count)
+
+	return err
+}
+
 func (r *RunningParser) SetDefaultTags(tags map[string]string) {
 	r.Parser.SetDefaultTags(tags)
 }
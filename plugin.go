@@ -37,6 +37,17 @@ type PluginWithID interface {
 	ID() string
 }
 
+// StatusReporter is an interface for plugins reporting their state via the
+// status endpoint of the agent, e.g. for readiness probes of orchestration
+// systems.
+type StatusReporter interface {
+	// Status returns if the plugin is ready and details about its state. The
+	// details must be serializable to JSON.
+	// Note: This function is called concurrently to the other functions of
+	// the plugin.
+	Status() (ready bool, details map[string]interface{})
+}
+
 // StatefulPlugin contains the functions that plugins must implement to
 // persist an internal state across Telegraf runs.
 // Note that plugins may define a persister that is not part of the
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.percentiles
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/percentiles" // register plugin
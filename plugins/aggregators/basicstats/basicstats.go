@@ -3,11 +3,13 @@ package basicstats
 
 import (
 	_ "embed"
+	"errors"
 	"math"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/series"
 )
 
 //go:embed sample.conf
@@ -16,9 +18,11 @@ var sampleConfig string
 type BasicStats struct {
 	Stats []string `toml:"stats"`
 	Log   telegraf.Logger
+	series.Config
 
 	cache       map[uint64]aggregate
 	statsConfig *configuredStats
+	series      *series.Tracker
 }
 
 type configuredStats struct {
@@ -73,6 +77,12 @@ func (*BasicStats) SampleConfig() string {
 
 func (b *BasicStats) Add(in telegraf.Metric) {
 	id := in.HashID()
+	for _, evicted := range b.series.Touch(id) {
+		delete(b.cache, evicted)
+	}
+	for _, expired := range b.series.Expire() {
+		delete(b.cache, expired)
+	}
 	if _, ok := b.cache[id]; !ok {
 		// hit an uncached metric, create caches for first time:
 		a := aggregate{
@@ -297,6 +307,7 @@ func (b *BasicStats) initConfiguredStats() {
 
 func (b *BasicStats) Reset() {
 	b.cache = make(map[uint64]aggregate)
+	b.series.Clear()
 }
 
 func convert(in interface{}) (float64, bool) {
@@ -315,6 +326,13 @@ func convert(in interface{}) (float64, bool) {
 func (b *BasicStats) Init() error {
 	b.initConfiguredStats()
 
+	if b.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+	if b.MaxSeries > 0 || b.SeriesTTL > 0 {
+		b.series = series.NewTracker(b.Config, map[string]string{"aggregator": "basicstats"}, b.Log)
+	}
+
 	return nil
 }
 
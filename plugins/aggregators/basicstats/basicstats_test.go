@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -824,3 +825,58 @@ func TestBasicStatsWithOnlyFirst(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "m1", expectedFields, expectedTags)
 }
+
+func TestBasicStatsMaxSeries(t *testing.T) {
+	aggregator := NewBasicStats()
+	aggregator.Stats = []string{"count"}
+	aggregator.MaxSeries = 1
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+
+	other := m1.Copy()
+	other.AddTag("foo", "baz")
+
+	// The statistics of the first series restart after being evicted
+	aggregator.Add(m1)
+	aggregator.Add(other)
+	aggregator.Add(m2)
+
+	acc := testutil.Accumulator{}
+	aggregator.Push(&acc)
+
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	acc.AssertContainsTaggedFields(t, "m1", map[string]interface{}{
+		"a_count": float64(1),
+		"b_count": float64(1),
+		"c_count": float64(1),
+		"d_count": float64(1),
+		"e_count": float64(1),
+		"f_count": float64(1),
+		"g_count": float64(1),
+	}, map[string]string{"foo": "bar"})
+
+	aggregator.Reset()
+	require.Zero(t, aggregator.series.Len())
+}
+
+func TestBasicStatsSeriesTTL(t *testing.T) {
+	aggregator := NewBasicStats()
+	aggregator.Stats = []string{"count"}
+	aggregator.SeriesTTL = config.Duration(time.Millisecond)
+	aggregator.Log = testutil.Logger{}
+	require.NoError(t, aggregator.Init())
+
+	other := m1.Copy()
+	other.AddTag("foo", "baz")
+
+	// Series not seen within the time-to-live are dropped
+	aggregator.Add(m1)
+	time.Sleep(10 * time.Millisecond)
+	aggregator.Add(other)
+
+	acc := testutil.Accumulator{}
+	aggregator.Push(&acc)
+
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, "baz", acc.GetTelegrafMetrics()[0].Tags()["foo"])
+}
@@ -3,11 +3,13 @@ package derivative
 
 import (
 	_ "embed"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/series"
 )
 
 //go:embed sample.conf
@@ -18,7 +20,10 @@ type Derivative struct {
 	Suffix      string          `toml:"suffix"`
 	MaxRollOver uint            `toml:"max_roll_over"`
 	Log         telegraf.Logger `toml:"-"`
-	cache       map[uint64]*aggregate
+	series.Config
+
+	cache  map[uint64]*aggregate
+	series *series.Tracker
 }
 
 type aggregate struct {
@@ -49,6 +54,9 @@ func (*Derivative) SampleConfig() string {
 
 func (d *Derivative) Add(in telegraf.Metric) {
 	id := in.HashID()
+	for _, evicted := range d.series.Touch(id) {
+		delete(d.cache, evicted)
+	}
 	current, ok := d.cache[id]
 	if !ok {
 		// hit an uncached metric, create caches for first time:
@@ -165,14 +173,25 @@ func (d *Derivative) Reset() {
 			d.Log.Debugf("Roll-Over %q for the %d time.", aggregate.name, aggregate.rollOver)
 		} else {
 			delete(d.cache, id)
+			d.series.Remove(id)
 			d.Log.Debugf("Removed %q from cache.", aggregate.name)
 		}
 	}
+	for _, id := range d.series.Expire() {
+		delete(d.cache, id)
+	}
 }
 
 func (d *Derivative) Init() error {
 	d.Suffix = strings.TrimSpace(d.Suffix)
 	d.Variable = strings.TrimSpace(d.Variable)
+
+	if d.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+	if d.MaxSeries > 0 || d.SeriesTTL > 0 {
+		d.series = series.NewTracker(d.Config, map[string]string{"aggregator": "derivative"}, d.Log)
+	}
 	return nil
 }
 
@@ -6,7 +6,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/series"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -416,3 +418,47 @@ func TestCalculatesCorrectDerivativeOnTwoConsecutivePeriods(t *testing.T) {
 		"value_rate": 2.0,
 	})
 }
+
+func TestMaxSeries(t *testing.T) {
+	derivative := NewDerivative()
+	derivative.Log = testutil.Logger{}
+	derivative.Config = series.Config{MaxSeries: 1}
+	require.NoError(t, derivative.Init())
+
+	other := start.Copy()
+	other.AddTag("state", "other")
+
+	// Each new series evicts the previous one, so no derivative is computed
+	derivative.Add(start)
+	derivative.Add(other)
+	derivative.Add(finish)
+	require.Len(t, derivative.cache, 1)
+
+	var acc testutil.Accumulator
+	derivative.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestSeriesTTL(t *testing.T) {
+	derivative := NewDerivative()
+	derivative.Log = testutil.Logger{}
+	derivative.Config = series.Config{SeriesTTL: config.Duration(time.Millisecond)}
+	require.NoError(t, derivative.Init())
+
+	derivative.Add(start)
+	var acc testutil.Accumulator
+	derivative.Push(&acc)
+	derivative.Reset()
+	require.Len(t, derivative.cache, 1)
+
+	// The series is dropped before reaching the maximum number of roll-overs
+	time.Sleep(10 * time.Millisecond)
+	derivative.Reset()
+	require.Empty(t, derivative.cache)
+}
+
+func TestInitNegativeMaxSeries(t *testing.T) {
+	derivative := NewDerivative()
+	derivative.Config = series.Config{MaxSeries: -1}
+	require.ErrorContains(t, derivative.Init(), "'max_series' must not be negative")
+}
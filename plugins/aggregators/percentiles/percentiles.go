@@ -0,0 +1,215 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package percentiles
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Suffix of string fields containing serialized sketches
+const sketchSuffix = "_sketch"
+
+type Percentiles struct {
+	Percentiles      []float64       `toml:"percentiles"`
+	Algorithm        string          `toml:"algorithm"`
+	Compression      float64         `toml:"compression"`
+	RelativeAccuracy float64         `toml:"relative_accuracy"`
+	EmitSketch       bool            `toml:"emit_sketch"`
+	Log              telegraf.Logger `toml:"-"`
+
+	newSketch newSketchFunc
+	suffixes  []string
+	cache     map[uint64]*aggregate
+}
+
+type aggregate struct {
+	name    string
+	tags    map[string]string
+	fields  map[string]sketch
+	ordered []string
+}
+
+func (*Percentiles) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Percentiles) Init() error {
+	switch p.Algorithm {
+	case "", "t-digest":
+		p.newSketch = func() (sketch, error) { return newTDigest(p.Compression) }
+	case "ddsketch":
+		p.newSketch = func() (sketch, error) { return newDDSketch(p.RelativeAccuracy) }
+	default:
+		return fmt.Errorf("unknown algorithm %q", p.Algorithm)
+	}
+	if _, err := p.newSketch(); err != nil {
+		return fmt.Errorf("cannot create %q sketch: %w", p.Algorithm, err)
+	}
+
+	if len(p.Percentiles) == 0 {
+		p.Percentiles = []float64{50, 90, 99, 99.9}
+	}
+	seen := make(map[string]bool, len(p.Percentiles))
+	p.suffixes = make([]string, 0, len(p.Percentiles))
+	for _, pct := range p.Percentiles {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("percentile %v out of range", pct)
+		}
+		// Use the digits of the percentile as name, e.g. 'p999' for 99.9
+		suffix := "_p" + strings.ReplaceAll(strconv.FormatFloat(pct, 'f', -1, 64), ".", "")
+		if seen[suffix] {
+			return fmt.Errorf("duplicate percentile %v", pct)
+		}
+		seen[suffix] = true
+		p.suffixes = append(p.suffixes, suffix)
+	}
+
+	p.Reset()
+	return nil
+}
+
+func (p *Percentiles) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, found := p.cache[id]
+	if !found {
+		a = &aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]sketch),
+		}
+		p.cache[id] = a
+	}
+
+	// Metrics carrying sketches also contain the percentiles derived from
+	// the sketches which must not be added as values
+	derived := make(map[string]bool)
+	for _, field := range in.FieldList() {
+		if name, isSketch := strings.CutSuffix(field.Key, sketchSuffix); isSketch {
+			for _, suffix := range p.suffixes {
+				derived[name+suffix] = true
+			}
+			derived[name+"_count"] = true
+		}
+	}
+
+	for _, field := range in.FieldList() {
+		// Merge sketches serialized by other instances, e.g. of a different
+		// Telegraf instance consuming the same data
+		if s, ok := field.Value.(string); ok {
+			name, isSketch := strings.CutSuffix(field.Key, sketchSuffix)
+			if !isSketch {
+				continue
+			}
+			if err := p.merge(a, name, s); err != nil {
+				p.Log.Errorf("Merging sketch of field %q failed: %v", name, err)
+			}
+			continue
+		}
+
+		v, ok := convert(field.Value)
+		if !ok || derived[field.Key] {
+			continue
+		}
+		s, err := p.sketch(a, field.Key)
+		if err != nil {
+			p.Log.Errorf("Creating sketch for field %q failed: %v", field.Key, err)
+			continue
+		}
+		if err := s.Add(v); err != nil {
+			p.Log.Errorf("Adding value of field %q failed: %v", field.Key, err)
+		}
+	}
+}
+
+// sketch returns the sketch of the field, creating it if necessary
+func (p *Percentiles) sketch(a *aggregate, field string) (sketch, error) {
+	if s, found := a.fields[field]; found {
+		return s, nil
+	}
+	s, err := p.newSketch()
+	if err != nil {
+		return nil, err
+	}
+	a.fields[field] = s
+	a.ordered = append(a.ordered, field)
+	return s, nil
+}
+
+func (p *Percentiles) merge(a *aggregate, field, encoded string) error {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+	if len(data) == 0 {
+		return errors.New("empty sketch")
+	}
+	s, err := p.sketch(a, field)
+	if err != nil {
+		return err
+	}
+	return s.Merge(data)
+}
+
+func (p *Percentiles) Push(acc telegraf.Accumulator) {
+	for _, a := range p.cache {
+		fields := make(map[string]interface{}, len(a.fields)*(len(p.suffixes)+2))
+		for _, name := range a.ordered {
+			s := a.fields[name]
+			if s.Count() == 0 {
+				continue
+			}
+			for i, pct := range p.Percentiles {
+				fields[name+p.suffixes[i]] = s.Quantile(pct / 100)
+			}
+			fields[name+"_count"] = s.Count()
+
+			if p.EmitSketch {
+				data, err := s.Marshal()
+				if err != nil {
+					p.Log.Errorf("Serializing sketch of field %q failed: %v", name, err)
+					continue
+				}
+				fields[name+sketchSuffix] = base64.StdEncoding.EncodeToString(data)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(a.name, fields, a.tags)
+		}
+	}
+}
+
+func (p *Percentiles) Reset() {
+	p.cache = make(map[uint64]*aggregate)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("percentiles", func() telegraf.Aggregator {
+		return &Percentiles{
+			Compression:      100,
+			RelativeAccuracy: 0.01,
+		}
+	})
+}
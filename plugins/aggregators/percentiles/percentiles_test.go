@@ -0,0 +1,193 @@
+package percentiles
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Percentiles
+		expected string
+	}{
+		{
+			name:     "unknown algorithm",
+			plugin:   &Percentiles{Algorithm: "hdr"},
+			expected: `unknown algorithm "hdr"`,
+		},
+		{
+			name:     "invalid compression",
+			plugin:   &Percentiles{Algorithm: "t-digest"},
+			expected: `cannot create "t-digest" sketch`,
+		},
+		{
+			name:     "invalid accuracy",
+			plugin:   &Percentiles{Algorithm: "ddsketch", RelativeAccuracy: 1},
+			expected: "relative accuracy 1 out of range",
+		},
+		{
+			name:     "percentile out of range",
+			plugin:   &Percentiles{Compression: 100, Percentiles: []float64{101}},
+			expected: "percentile 101 out of range",
+		},
+		{
+			name:     "duplicate percentile",
+			plugin:   &Percentiles{Compression: 100, Percentiles: []float64{90, 99, 90}},
+			expected: "duplicate percentile 90",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	for _, algorithm := range []string{"t-digest", "ddsketch"} {
+		t.Run(algorithm, func(t *testing.T) {
+			plugin := &Percentiles{
+				Algorithm:        algorithm,
+				Compression:      100,
+				RelativeAccuracy: 0.01,
+				Log:              testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			// Add the values 1 to 10000 in random order
+			for _, v := range rand.Perm(10000) {
+				plugin.Add(metric.New(
+					"test",
+					map[string]string{"foo": "bar"},
+					map[string]interface{}{"latency": int64(v + 1), "status": "ok"},
+					time.Now(),
+				))
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, 1)
+			m := metrics[0]
+			require.Equal(t, "test", m.Name())
+			require.Equal(t, map[string]string{"foo": "bar"}, m.Tags())
+
+			count, found := m.GetField("latency_count")
+			require.True(t, found)
+			require.Equal(t, uint64(10000), count)
+			for field, expected := range map[string]float64{
+				"latency_p50":  5000,
+				"latency_p90":  9000,
+				"latency_p99":  9900,
+				"latency_p999": 9990,
+			} {
+				actual, found := m.GetField(field)
+				require.True(t, found, field)
+				require.InEpsilon(t, expected, actual, 0.01, field)
+			}
+			require.False(t, m.HasField("status_p50"))
+			require.False(t, m.HasField("latency_sketch"))
+
+			// No metrics are emitted after the reset
+			plugin.Reset()
+			acc.ClearMetrics()
+			plugin.Push(&acc)
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestMergeSketches(t *testing.T) {
+	for _, algorithm := range []string{"t-digest", "ddsketch"} {
+		t.Run(algorithm, func(t *testing.T) {
+			// Two edge instances see half of the values each
+			var edge []telegraf.Metric
+			for i := range 2 {
+				plugin := &Percentiles{
+					Algorithm:        algorithm,
+					Compression:      100,
+					RelativeAccuracy: 0.01,
+					EmitSketch:       true,
+					Log:              testutil.Logger{},
+				}
+				require.NoError(t, plugin.Init())
+				for v := i; v < 1000; v += 2 {
+					plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"latency": float64(v)}, time.Now()))
+				}
+
+				var acc testutil.Accumulator
+				plugin.Push(&acc)
+				require.Len(t, acc.GetTelegrafMetrics(), 1)
+				edge = append(edge, acc.GetTelegrafMetrics()...)
+			}
+
+			// The central instance merges the sketches while ignoring the
+			// percentiles computed by the edge instances
+			plugin := &Percentiles{
+				Algorithm:        algorithm,
+				Compression:      100,
+				RelativeAccuracy: 0.01,
+				Log:              testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			for _, m := range edge {
+				plugin.Add(m)
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			require.Empty(t, acc.Errors)
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, 1)
+			m := metrics[0]
+
+			count, found := m.GetField("latency_count")
+			require.True(t, found)
+			require.Equal(t, uint64(1000), count)
+			p90, found := m.GetField("latency_p90")
+			require.True(t, found)
+			require.InEpsilon(t, 900, p90, 0.02)
+			require.False(t, m.HasField("latency_p50_p50"))
+		})
+	}
+}
+
+func TestMergeMismatch(t *testing.T) {
+	edge := &Percentiles{Algorithm: "ddsketch", RelativeAccuracy: 0.05, EmitSketch: true, Log: testutil.Logger{}}
+	require.NoError(t, edge.Init())
+	edge.Add(metric.New("test", map[string]string{}, map[string]interface{}{"latency": 42.0}, time.Now()))
+	var acc testutil.Accumulator
+	edge.Push(&acc)
+	encoded, found := acc.GetTelegrafMetrics()[0].GetField("latency_sketch")
+	require.True(t, found)
+
+	plugin := &Percentiles{Algorithm: "ddsketch", RelativeAccuracy: 0.01}
+	require.NoError(t, plugin.Init())
+	s, err := plugin.newSketch()
+	require.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(encoded.(string))
+	require.NoError(t, err)
+	require.ErrorContains(t, s.Merge(data), "relative accuracy does not match")
+}
+
+func TestDDSketchNegativeValues(t *testing.T) {
+	s, err := newDDSketch(0.01)
+	require.NoError(t, err)
+	for v := -50; v <= 50; v++ {
+		require.NoError(t, s.Add(float64(v)))
+	}
+	require.InDelta(t, -50, s.Quantile(0), 0.5)
+	require.InDelta(t, 0, s.Quantile(0.5), 1e-9)
+	require.InDelta(t, 50, s.Quantile(1), 0.5)
+	require.InDelta(t, -25, s.Quantile(0.25), 0.25)
+}
@@ -0,0 +1,212 @@
+package percentiles
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/caio/go-tdigest"
+)
+
+// sketch summarizes the distribution of values and can be merged with other
+// sketches of the same type and parameters
+type sketch interface {
+	Add(value float64) error
+	Quantile(q float64) float64
+	Count() uint64
+	Marshal() ([]byte, error)
+	Merge(data []byte) error
+}
+
+type newSketchFunc func() (sketch, error)
+
+type tdigestSketch struct {
+	*tdigest.TDigest
+}
+
+func newTDigest(compression float64) (sketch, error) {
+	t, err := tdigest.New(tdigest.Compression(compression))
+	if err != nil {
+		return nil, err
+	}
+	return &tdigestSketch{t}, nil
+}
+
+func (s *tdigestSketch) Marshal() ([]byte, error) {
+	return s.AsBytes()
+}
+
+func (s *tdigestSketch) Merge(data []byte) error {
+	other, err := tdigest.FromBytes(bytes.NewReader(data), tdigest.Compression(s.Compression()))
+	if err != nil {
+		return err
+	}
+	return s.TDigest.Merge(other)
+}
+
+// ddsketch implements the DDSketch algorithm guaranteeing a relative error
+// of the quantiles, see https://arxiv.org/abs/1908.10693. Values are counted
+// in buckets with logarithmically growing boundaries.
+type ddsketch struct {
+	gamma    float64
+	logGamma float64
+	positive map[int32]uint64
+	negative map[int32]uint64
+	zeros    uint64
+	count    uint64
+}
+
+// Identifier and version of the serialized ddsketch
+const ddsketchEncoding = 1
+
+func newDDSketch(accuracy float64) (sketch, error) {
+	if accuracy <= 0 || accuracy >= 1 {
+		return nil, fmt.Errorf("relative accuracy %v out of range", accuracy)
+	}
+	gamma := (1 + accuracy) / (1 - accuracy)
+	return &ddsketch{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		positive: make(map[int32]uint64),
+		negative: make(map[int32]uint64),
+	}, nil
+}
+
+func (s *ddsketch) Add(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("cannot add value %v", value)
+	}
+	switch {
+	case value > 0:
+		s.positive[s.index(value)]++
+	case value < 0:
+		s.negative[s.index(-value)]++
+	default:
+		s.zeros++
+	}
+	s.count++
+	return nil
+}
+
+func (s *ddsketch) index(value float64) int32 {
+	return int32(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value returns the representative value of the bucket with the given index
+func (s *ddsketch) value(index int32) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+func (s *ddsketch) Count() uint64 {
+	return s.count
+}
+
+func (s *ddsketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+
+	rank := uint64(q * float64(s.count-1))
+	var seen uint64
+
+	// Negative values in ascending order, i.e. descending absolute values
+	for _, idx := range sortedIndices(s.negative, true) {
+		if seen += s.negative[idx]; seen > rank {
+			return -s.value(idx)
+		}
+	}
+	if seen += s.zeros; seen > rank {
+		return 0
+	}
+	indices := sortedIndices(s.positive, false)
+	for _, idx := range indices {
+		if seen += s.positive[idx]; seen > rank {
+			return s.value(idx)
+		}
+	}
+	return s.value(indices[len(indices)-1])
+}
+
+func sortedIndices(buckets map[int32]uint64, descending bool) []int32 {
+	indices := make([]int32, 0, len(buckets))
+	for idx := range buckets {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		if descending {
+			return indices[i] > indices[j]
+		}
+		return indices[i] < indices[j]
+	})
+	return indices
+}
+
+// Marshal serializes the sketch as version, gamma, zero count and the
+// index and count of the positive and negative buckets
+func (s *ddsketch) Marshal() ([]byte, error) {
+	buf := make([]byte, 0, 1+8+binary.MaxVarintLen64*(1+2*(len(s.positive)+len(s.negative)+1)))
+	buf = append(buf, ddsketchEncoding)
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.gamma))
+	buf = binary.AppendUvarint(buf, s.zeros)
+	for _, buckets := range []map[int32]uint64{s.positive, s.negative} {
+		buf = binary.AppendUvarint(buf, uint64(len(buckets)))
+		for _, idx := range sortedIndices(buckets, false) {
+			buf = binary.AppendVarint(buf, int64(idx))
+			buf = binary.AppendUvarint(buf, buckets[idx])
+		}
+	}
+	return buf, nil
+}
+
+func (s *ddsketch) Merge(data []byte) error {
+	if len(data) < 9 || data[0] != ddsketchEncoding {
+		return errors.New("unknown encoding")
+	}
+	if gamma := math.Float64frombits(binary.LittleEndian.Uint64(data[1:9])); gamma != s.gamma {
+		return fmt.Errorf("relative accuracy does not match (gamma %v instead of %v)", gamma, s.gamma)
+	}
+	r := bytes.NewReader(data[9:])
+
+	zeros, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("reading zero count failed: %w", err)
+	}
+
+	// Decode the buckets first to not modify the sketch on errors
+	decoded := make([]map[int32]uint64, 0, 2)
+	for range 2 {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("reading bucket count failed: %w", err)
+		}
+		if n > uint64(r.Len()) {
+			return errors.New("invalid bucket count")
+		}
+		buckets := make(map[int32]uint64, n)
+		for range n {
+			idx, err := binary.ReadVarint(r)
+			if err != nil {
+				return fmt.Errorf("reading bucket index failed: %w", err)
+			}
+			count, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("reading bucket value failed: %w", err)
+			}
+			buckets[int32(idx)] += count
+		}
+		decoded = append(decoded, buckets)
+	}
+
+	s.zeros += zeros
+	s.count += zeros
+	for i, target := range []map[int32]uint64{s.positive, s.negative} {
+		for idx, count := range decoded[i] {
+			target[idx] += count
+			s.count += count
+		}
+	}
+	return nil
+}
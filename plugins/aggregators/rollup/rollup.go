@@ -0,0 +1,245 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rollup
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/series"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableFunctions = []string{"count", "min", "max", "mean", "sum", "first", "last"}
+
+type Rollup struct {
+	Rules     []*rule         `toml:"rule"`
+	MaxSeries int             `toml:"max_series"`
+	Log       telegraf.Logger `toml:"-"`
+
+	cache  map[uint64]*aggregate
+	series *series.Tracker
+}
+
+type rule struct {
+	Measurements []string `toml:"measurements"`
+	Fields       []string `toml:"fields"`
+	GroupBy      []string `toml:"group_by"`
+	Functions    []string `toml:"functions"`
+
+	measurementFilter filter.Filter
+	fieldFilter       filter.Filter
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	rule   *rule
+	fields map[string]*stats
+}
+
+type stats struct {
+	count   int64
+	numeric bool
+	min     float64
+	max     float64
+	sum     float64
+	first   interface{}
+	last    interface{}
+}
+
+func (*Rollup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rollup) Init() error {
+	if len(r.Rules) == 0 {
+		return errors.New("no rules configured")
+	}
+	if r.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+
+	for i, rl := range r.Rules {
+		if len(rl.Measurements) == 0 {
+			rl.Measurements = []string{"*"}
+		}
+		if len(rl.Functions) == 0 {
+			rl.Functions = []string{"mean"}
+		}
+		for _, fn := range rl.Functions {
+			if !slices.Contains(availableFunctions, fn) {
+				return fmt.Errorf("rule %d: unknown function %q", i+1, fn)
+			}
+		}
+
+		var err error
+		if rl.measurementFilter, err = filter.Compile(rl.Measurements); err != nil {
+			return fmt.Errorf("rule %d: creating measurement filter failed: %w", i+1, err)
+		}
+		if rl.fieldFilter, err = filter.Compile(rl.Fields); err != nil {
+			return fmt.Errorf("rule %d: creating field filter failed: %w", i+1, err)
+		}
+	}
+
+	if r.MaxSeries > 0 {
+		r.series = series.NewTracker(series.Config{MaxSeries: r.MaxSeries}, map[string]string{"aggregator": "rollup"}, r.Log)
+	}
+	r.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (r *Rollup) Add(in telegraf.Metric) {
+	// The first rule matching the measurement applies
+	var rl *rule
+	for _, candidate := range r.Rules {
+		if candidate.measurementFilter.Match(in.Name()) {
+			rl = candidate
+			break
+		}
+	}
+	if rl == nil {
+		return
+	}
+
+	// Only keep the tags to group by, all other tags are rolled up
+	tags := in.TagList()
+	if len(rl.GroupBy) > 0 {
+		grouped := make([]*telegraf.Tag, 0, len(rl.GroupBy))
+		for _, tag := range tags {
+			if slices.Contains(rl.GroupBy, tag.Key) {
+				grouped = append(grouped, tag)
+			}
+		}
+		tags = grouped
+	}
+
+	id := groupID(in.Name(), tags)
+	for _, evicted := range r.series.Touch(id) {
+		delete(r.cache, evicted)
+	}
+	a, found := r.cache[id]
+	if !found {
+		a = &aggregate{
+			name:   in.Name(),
+			tags:   make(map[string]string, len(tags)),
+			rule:   rl,
+			fields: make(map[string]*stats),
+		}
+		for _, tag := range tags {
+			a.tags[tag.Key] = tag.Value
+		}
+		r.cache[id] = a
+	}
+
+	for _, field := range in.FieldList() {
+		if rl.fieldFilter != nil && !rl.fieldFilter.Match(field.Key) {
+			continue
+		}
+
+		s, found := a.fields[field.Key]
+		if !found {
+			s = &stats{first: field.Value}
+			s.min, s.numeric = convert(field.Value)
+			s.max = s.min
+			a.fields[field.Key] = s
+		}
+		s.count++
+		s.last = field.Value
+		if !s.numeric {
+			continue
+		}
+		v, ok := convert(field.Value)
+		if !ok {
+			// The field changed its type, so only the count, first and last
+			// value are meaningful
+			s.numeric = false
+			continue
+		}
+		s.min = min(s.min, v)
+		s.max = max(s.max, v)
+		s.sum += v
+	}
+}
+
+func (r *Rollup) Push(acc telegraf.Accumulator) {
+	for _, a := range r.cache {
+		fields := make(map[string]interface{}, len(a.fields)*len(a.rule.Functions))
+		for key, s := range a.fields {
+			for _, fn := range a.rule.Functions {
+				switch fn {
+				case "count":
+					fields[key+"_count"] = s.count
+				case "first":
+					fields[key+"_first"] = s.first
+				case "last":
+					fields[key+"_last"] = s.last
+				}
+				if !s.numeric {
+					continue
+				}
+				switch fn {
+				case "min":
+					fields[key+"_min"] = s.min
+				case "max":
+					fields[key+"_max"] = s.max
+				case "mean":
+					fields[key+"_mean"] = s.sum / float64(s.count)
+				case "sum":
+					fields[key+"_sum"] = s.sum
+				}
+			}
+		}
+
+		if len(fields) > 0 {
+			acc.AddFields(a.name, fields, a.tags)
+		}
+	}
+}
+
+func (r *Rollup) Reset() {
+	r.cache = make(map[uint64]*aggregate)
+	r.series.Clear()
+}
+
+// groupID identifies the group of the metric by its name and the sorted tags
+// to group by
+func groupID(name string, tags []*telegraf.Tag) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	for _, tag := range tags {
+		h.Write([]byte(tag.Key))
+		h.Write([]byte{0})
+		h.Write([]byte(tag.Value))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("rollup", func() telegraf.Aggregator {
+		return &Rollup{}
+	})
+}
@@ -0,0 +1,162 @@
+package rollup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var input = []telegraf.Metric{
+	metric.New("cpu",
+		map[string]string{"agent": "edge-01", "region": "eu", "cpu": "cpu-total"},
+		map[string]interface{}{"usage_idle": float64(90), "usage_user": float64(8)},
+		time.Unix(0, 0),
+	),
+	metric.New("cpu",
+		map[string]string{"agent": "edge-02", "region": "eu", "cpu": "cpu-total"},
+		map[string]interface{}{"usage_idle": float64(70), "usage_user": float64(20)},
+		time.Unix(0, 0),
+	),
+	metric.New("cpu",
+		map[string]string{"agent": "edge-03", "region": "us", "cpu": "cpu-total"},
+		map[string]interface{}{"usage_idle": float64(50), "usage_user": float64(40)},
+		time.Unix(0, 0),
+	),
+	metric.New("system",
+		map[string]string{"agent": "edge-01", "region": "eu"},
+		map[string]interface{}{"load1": float64(0.5), "n_users": int64(2), "uptime_format": "1 day"},
+		time.Unix(0, 0),
+	),
+	metric.New("system",
+		map[string]string{"agent": "edge-01", "region": "eu"},
+		map[string]interface{}{"load1": float64(1.5), "n_users": int64(4), "uptime_format": "2 days"},
+		time.Unix(10, 0),
+	),
+	metric.New("disk",
+		map[string]string{"agent": "edge-01", "region": "eu"},
+		map[string]interface{}{"used": int64(100)},
+		time.Unix(0, 0),
+	),
+}
+
+func TestRollup(t *testing.T) {
+	plugin := &Rollup{
+		Rules: []*rule{
+			{
+				Measurements: []string{"cpu"},
+				Fields:       []string{"usage_idle"},
+				GroupBy:      []string{"region"},
+				Functions:    []string{"min", "max", "mean", "count"},
+			},
+			{
+				Measurements: []string{"sys*"},
+				Functions:    []string{"sum", "first", "last"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{
+				"usage_idle_min":   float64(70),
+				"usage_idle_max":   float64(90),
+				"usage_idle_mean":  float64(80),
+				"usage_idle_count": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("cpu",
+			map[string]string{"region": "us"},
+			map[string]interface{}{
+				"usage_idle_min":   float64(50),
+				"usage_idle_max":   float64(50),
+				"usage_idle_mean":  float64(50),
+				"usage_idle_count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("system",
+			map[string]string{"agent": "edge-01", "region": "eu"},
+			map[string]interface{}{
+				"load1_sum":           float64(2),
+				"load1_first":         float64(0.5),
+				"load1_last":          float64(1.5),
+				"n_users_sum":         float64(6),
+				"n_users_first":       int64(2),
+				"n_users_last":        int64(4),
+				"uptime_format_first": "1 day",
+				"uptime_format_last":  "2 days",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The state is cleared after the period
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestDefaultRule(t *testing.T) {
+	plugin := &Rollup{
+		Rules: []*rule{{GroupBy: []string{"region"}}},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{"usage_idle_mean": float64(80), "usage_user_mean": float64(14)},
+			time.Unix(0, 0),
+		),
+		metric.New("cpu",
+			map[string]string{"region": "us"},
+			map[string]interface{}{"usage_idle_mean": float64(50), "usage_user_mean": float64(40)},
+			time.Unix(0, 0),
+		),
+		metric.New("system",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{"load1_mean": float64(1), "n_users_mean": float64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New("disk",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{"used_mean": float64(100)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Rollup{}
+	require.ErrorContains(t, plugin.Init(), "no rules configured")
+
+	plugin = &Rollup{Rules: []*rule{{Functions: []string{"median"}}}}
+	require.ErrorContains(t, plugin.Init(), `rule 1: unknown function "median"`)
+}
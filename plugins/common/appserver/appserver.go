@@ -0,0 +1,116 @@
+// Package appserver provides a common metric schema for application servers
+// such as php-fpm, uWSGI and gunicorn. Using the unified schema allows to
+// monitor mixed fleets of application servers with the same queries.
+package appserver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Names of the metric schemas available in the application server plugins
+const (
+	SchemaNative  = "native"
+	SchemaUnified = "unified"
+)
+
+// CheckSchema returns an error if the given schema is unknown
+func CheckSchema(schema string) error {
+	switch schema {
+	case SchemaNative, SchemaUnified:
+		return nil
+	}
+	return fmt.Errorf("invalid schema %q", schema)
+}
+
+// Pool holds the normalized statistics of a pool of workers of an application
+// server. Optional statistics are nil if not provided by the server.
+type Pool struct {
+	// Server is the type of the application server, e.g. 'phpfpm'
+	Server string
+	// Name of the pool, e.g. the php-fpm pool name or the uWSGI vassal
+	Name string
+	// Address the statistics were gathered from
+	Address string
+
+	// Number of worker processes in total, busy serving requests and idle
+	Workers     int64
+	BusyWorkers *int64
+	IdleWorkers *int64
+
+	// Number of connections waiting to be accepted and the size of the
+	// listen backlog
+	QueueDepth    *int64
+	MaxQueueDepth *int64
+
+	// Requests served by the pool since its start
+	Requests *int64
+
+	// Statistics of the individual workers if available
+	WorkerStats []Worker
+}
+
+// Worker holds the normalized statistics of a single worker
+type Worker struct {
+	// ID of the worker within the pool, usually the process ID
+	ID  string
+	PID int64
+	// State of the worker, either 'busy', 'idle' or a server specific state
+	State string
+
+	// Requests served by the worker since its start
+	Requests *int64
+	// Duration of the current or last request, or the average request
+	// duration depending on the server
+	RequestDuration *time.Duration
+}
+
+// Add adds the pool and worker statistics to the accumulator using the
+// unified 'app_server' and 'app_server_worker' metrics
+func (p *Pool) Add(acc telegraf.Accumulator, timestamp time.Time) {
+	tags := map[string]string{
+		"server":  p.Server,
+		"pool":    p.Name,
+		"address": p.Address,
+	}
+	fields := map[string]interface{}{
+		"workers": p.Workers,
+	}
+	addOptional(fields, "workers_busy", p.BusyWorkers)
+	addOptional(fields, "workers_idle", p.IdleWorkers)
+	addOptional(fields, "queue_depth", p.QueueDepth)
+	addOptional(fields, "queue_max", p.MaxQueueDepth)
+	addOptional(fields, "requests", p.Requests)
+	acc.AddFields("app_server", fields, tags, timestamp)
+
+	for _, w := range p.WorkerStats {
+		tags := map[string]string{
+			"server":  p.Server,
+			"pool":    p.Name,
+			"address": p.Address,
+			"worker":  w.ID,
+		}
+		fields := map[string]interface{}{
+			"pid":   w.PID,
+			"state": w.State,
+		}
+		addOptional(fields, "requests", w.Requests)
+		if w.RequestDuration != nil {
+			fields["request_duration_us"] = w.RequestDuration.Microseconds()
+		}
+		acc.AddFields("app_server_worker", fields, tags, timestamp)
+	}
+}
+
+// Int64 returns a pointer to the given value to fill optional statistics
+func Int64(v int64) *int64 {
+	return &v
+}
+
+func addOptional(fields map[string]interface{}, key string, v *int64) {
+	if v != nil {
+		fields[key] = *v
+	}
+}
@@ -0,0 +1,57 @@
+package appserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestPoolAdd(t *testing.T) {
+	duration := 1500 * time.Microsecond
+	pool := &Pool{
+		Server:      "phpfpm",
+		Name:        "www",
+		Address:     "/run/php/www.sock:status",
+		Workers:     2,
+		BusyWorkers: Int64(1),
+		QueueDepth:  Int64(0),
+		WorkerStats: []Worker{
+			{ID: "10", PID: 10, State: "busy", Requests: Int64(42), RequestDuration: &duration},
+			{ID: "11", PID: 11, State: "idle"},
+		},
+	}
+
+	var acc testutil.Accumulator
+	pool.Add(&acc, time.Unix(1700000000, 0))
+
+	tags := map[string]string{"server": "phpfpm", "pool": "www", "address": "/run/php/www.sock:status"}
+	workerTags := func(id string) map[string]string {
+		return map[string]string{"server": "phpfpm", "pool": "www", "address": "/run/php/www.sock:status", "worker": id}
+	}
+	expected := []telegraf.Metric{
+		metric.New("app_server", tags,
+			map[string]interface{}{"workers": int64(2), "workers_busy": int64(1), "queue_depth": int64(0)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New("app_server_worker", workerTags("10"),
+			map[string]interface{}{"pid": int64(10), "state": "busy", "requests": int64(42), "request_duration_us": int64(1500)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New("app_server_worker", workerTags("11"),
+			map[string]interface{}{"pid": int64(11), "state": "idle"},
+			time.Unix(1700000000, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestCheckSchema(t *testing.T) {
+	require.NoError(t, CheckSchema("native"))
+	require.NoError(t, CheckSchema("unified"))
+	require.ErrorContains(t, CheckSchema("app_server"), "invalid schema")
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+
+	"github.com/influxdata/telegraf/plugins/common/proxy"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// ClientConfig contains the settings of the HTTP client used to connect to
+// STS and the service endpoints, e.g. to trust the private CA of LocalStack or
+// a VPC interface endpoint or to connect via a proxy.
+type ClientConfig struct {
+	tls.ClientConfig
+	proxy.HTTPProxy
+}
+
+// CreateClient returns an HTTP client based on the default client of the SDK
+// with the configured TLS and proxy settings applied. If none of those are
+// set, nil is returned to keep the defaults of the SDK.
+func (c *ClientConfig) CreateClient() (aws.HTTPClient, error) {
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating TLS config failed: %w", err)
+	}
+	proxyFunc, err := c.HTTPProxy.Proxy()
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg == nil && proxyFunc == nil {
+		return nil, nil
+	}
+
+	client := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		if tlsCfg != nil {
+			tr.TLSClientConfig = tlsCfg
+		}
+		if proxyFunc != nil {
+			tr.Proxy = proxyFunc
+		}
+	})
+	return client, nil
+}
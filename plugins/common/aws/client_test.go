@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+func TestClientConfigPrivateCA(t *testing.T) {
+	server := &stsServer{}
+	ts := httptest.NewTLSServer(server)
+	defer ts.Close()
+	t.Setenv("AWS_ENDPOINT_URL_STS", ts.URL)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(block), 0600))
+
+	c := &CredentialConfig{
+		Region:    "eu-central-1",
+		AccessKey: config.NewSecret([]byte("AKIAROOT")),
+		SecretKey: config.NewSecret([]byte("secret")),
+		RoleARN:   "arn:aws:iam::111111111111:role/hub",
+	}
+
+	// The certificate of the endpoint is not trusted by default
+	cfg, err := c.Credentials()
+	require.NoError(t, err)
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	require.ErrorContains(t, err, "certificate")
+
+	client := &ClientConfig{ClientConfig: tls.ClientConfig{TLSCA: ca}}
+	c.HTTPClient, err = client.CreateClient()
+	require.NoError(t, err)
+	require.NotNil(t, c.HTTPClient)
+
+	cfg, err = c.Credentials()
+	require.NoError(t, err)
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "ASIAHOP1", creds.AccessKeyID)
+}
+
+func TestClientConfigDefault(t *testing.T) {
+	client := &ClientConfig{}
+	c, err := client.CreateClient()
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	client.HTTPProxyURL = "http://localhost:8888"
+	c, err = client.CreateClient()
+	require.NoError(t, err)
+	require.NotNil(t, c)
+}
@@ -2,32 +2,74 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+
+	telegraf_config "github.com/influxdata/telegraf/config"
 )
 
+// secretRefreshInterval is the time after which credentials taken from
+// secrets are resolved again to pick up rotated values from secret-stores
+const secretRefreshInterval = 5 * time.Minute
+
 // The endpoint_url supplied here is used for specific AWS service (Cloudwatch / Timestream / etc.)
 type CredentialConfig struct {
-	Region               string `toml:"region"`
-	AccessKey            string `toml:"access_key"`
-	SecretKey            string `toml:"secret_key"`
-	RoleARN              string `toml:"role_arn"`
-	Profile              string `toml:"profile"`
-	Filename             string `toml:"shared_credential_file"`
-	Token                string `toml:"token"`
-	EndpointURL          string `toml:"endpoint_url"`
-	RoleSessionName      string `toml:"role_session_name"`
-	WebIdentityTokenFile string `toml:"web_identity_token_file"`
+	Region               string                 `toml:"region"`
+	AccessKey            telegraf_config.Secret `toml:"access_key"`
+	SecretKey            telegraf_config.Secret `toml:"secret_key"`
+	RoleARN              string                 `toml:"role_arn"`
+	Profile              string                 `toml:"profile"`
+	Filename             string                 `toml:"shared_credential_file"`
+	Token                telegraf_config.Secret `toml:"token"`
+	EndpointURL          string                 `toml:"endpoint_url"`
+	RoleSessionName      string                 `toml:"role_session_name"`
+	WebIdentityTokenFile string                 `toml:"web_identity_token_file"`
+	ExternalID           string                 `toml:"external_id"`
+	SourceIdentity       string                 `toml:"source_identity"`
+	SessionTags          map[string]string      `toml:"session_tags"`
+	TransitiveTagKeys    []string               `toml:"transitive_tag_keys"`
+	RoleChain            []ChainedRole          `toml:"role_chain"`
+
+	// HTTPClient used for all requests including the ones to STS, e.g. as
+	// created by ClientConfig. The SDK default is used if unset.
+	HTTPClient aws.HTTPClient `toml:"-"`
+}
+
+// ChainedRole is a role assumed using the credentials of the previous role
+type ChainedRole struct {
+	RoleARN         string `toml:"role_arn"`
+	ExternalID      string `toml:"external_id"`
+	RoleSessionName string `toml:"role_session_name"`
 }
 
+// Credentials returns the AWS config with the credentials resolved from the
+// settings. Requests of clients created from the config are repeated once
+// with rebuilt credentials if they fail due to expired credentials.
 func (c *CredentialConfig) Credentials() (aws.Config, error) {
+	cfg, err := c.load()
+	if err != nil {
+		return aws.Config{}, err
+	}
+	return withCredentialRefresh(cfg, c.load), nil
+}
+
+func (c *CredentialConfig) load() (aws.Config, error) {
 	if c.RoleARN != "" {
 		return c.configWithAssumeCredentials()
 	}
+	if len(c.RoleChain) > 0 {
+		return aws.Config{}, errors.New("'role_chain' requires 'role_arn' to be set")
+	}
 	return c.configWithRootCredentials()
 }
 
@@ -36,6 +78,10 @@ func (c *CredentialConfig) configWithRootCredentials() (aws.Config, error) {
 		config.WithRegion(c.Region),
 	}
 
+	if c.HTTPClient != nil {
+		options = append(options, config.WithHTTPClient(c.HTTPClient))
+	}
+
 	if c.Profile != "" {
 		options = append(options, config.WithSharedConfigProfile(c.Profile))
 	}
@@ -43,8 +89,17 @@ func (c *CredentialConfig) configWithRootCredentials() (aws.Config, error) {
 		options = append(options, config.WithSharedCredentialsFiles([]string{c.Filename}))
 	}
 
-	if c.AccessKey != "" || c.SecretKey != "" {
-		provider := credentials.NewStaticCredentialsProvider(c.AccessKey, c.SecretKey, c.Token)
+	if !c.AccessKey.Empty() || !c.SecretKey.Empty() {
+		provider := &secretCredentialsProvider{
+			accessKey: &c.AccessKey,
+			secretKey: &c.SecretKey,
+			token:     &c.Token,
+		}
+		// Make sure the secrets can be resolved to fail early on
+		// misconfiguration instead of on the first request
+		if _, err := provider.Retrieve(context.Background()); err != nil {
+			return aws.Config{}, err
+		}
 		options = append(options, config.WithCredentialsProvider(provider))
 	}
 
@@ -61,6 +116,13 @@ func (c *CredentialConfig) configWithAssumeCredentials() (aws.Config, error) {
 	var provider aws.CredentialsProvider
 	stsService := sts.NewFromConfig(defaultConfig)
 	if c.WebIdentityTokenFile != "" {
+		// The session tags and source identity are taken from the token and
+		// an external ID is not applicable for web identities
+		if c.ExternalID != "" || c.SourceIdentity != "" || len(c.SessionTags) > 0 || len(c.TransitiveTagKeys) > 0 {
+			return aws.Config{}, errors.New(
+				"'external_id', 'source_identity', 'session_tags' and 'transitive_tag_keys' are not supported with 'web_identity_token_file'",
+			)
+		}
 		provider = stscreds.NewWebIdentityRoleProvider(
 			stsService,
 			c.RoleARN,
@@ -72,13 +134,92 @@ func (c *CredentialConfig) configWithAssumeCredentials() (aws.Config, error) {
 			},
 		)
 	} else {
-		provider = stscreds.NewAssumeRoleProvider(stsService, c.RoleARN, func(opts *stscreds.AssumeRoleOptions) {
-			if c.RoleSessionName != "" {
+		provider = stscreds.NewAssumeRoleProvider(stsService, c.RoleARN, c.assumeRoleOptions)
+	}
+
+	// Assume the chained roles using the credentials of the previous role.
+	// Transitive session tags and the source identity are passed along the
+	// chain by STS.
+	for i, role := range c.RoleChain {
+		if role.RoleARN == "" {
+			return aws.Config{}, fmt.Errorf("'role_arn' missing for role %d of 'role_chain'", i+1)
+		}
+		chainConfig := defaultConfig.Copy()
+		chainConfig.Credentials = aws.NewCredentialsCache(provider)
+		provider = stscreds.NewAssumeRoleProvider(sts.NewFromConfig(chainConfig), role.RoleARN, func(opts *stscreds.AssumeRoleOptions) {
+			if role.RoleSessionName != "" {
+				opts.RoleSessionName = role.RoleSessionName
+			} else if c.RoleSessionName != "" {
 				opts.RoleSessionName = c.RoleSessionName
 			}
+			if role.ExternalID != "" {
+				opts.ExternalID = aws.String(role.ExternalID)
+			}
 		})
 	}
 
 	defaultConfig.Credentials = aws.NewCredentialsCache(provider)
 	return defaultConfig, nil
 }
+
+func (c *CredentialConfig) assumeRoleOptions(opts *stscreds.AssumeRoleOptions) {
+	if c.RoleSessionName != "" {
+		opts.RoleSessionName = c.RoleSessionName
+	}
+	if c.ExternalID != "" {
+		opts.ExternalID = aws.String(c.ExternalID)
+	}
+	if c.SourceIdentity != "" {
+		opts.SourceIdentity = aws.String(c.SourceIdentity)
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.SessionTags)) {
+		opts.Tags = append(opts.Tags, types.Tag{Key: aws.String(k), Value: aws.String(c.SessionTags[k])})
+	}
+	opts.TransitiveTagKeys = c.TransitiveTagKeys
+}
+
+// secretCredentialsProvider provides the explicit credentials stored in
+// secrets. The credentials expire after the refresh interval so the SDK's
+// credential cache resolves the secrets again and picks up rotated values.
+type secretCredentialsProvider struct {
+	accessKey *telegraf_config.Secret
+	secretKey *telegraf_config.Secret
+	token     *telegraf_config.Secret
+}
+
+func (p *secretCredentialsProvider) Retrieve(context.Context) (aws.Credentials, error) {
+	accessKey, err := getSecret(p.accessKey)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("getting 'access_key' failed: %w", err)
+	}
+	secretKey, err := getSecret(p.secretKey)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("getting 'secret_key' failed: %w", err)
+	}
+	token, err := getSecret(p.token)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("getting 'token' failed: %w", err)
+	}
+
+	// Reuse the validation of the SDK's static provider
+	creds, err := credentials.NewStaticCredentialsProvider(accessKey, secretKey, token).Retrieve(context.Background())
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	creds.Source = "TelegrafSecretCredentials"
+	creds.CanExpire = true
+	creds.Expires = time.Now().Add(secretRefreshInterval)
+	return creds, nil
+}
+
+func getSecret(secret *telegraf_config.Secret) (string, error) {
+	if secret.Empty() {
+		return "", nil
+	}
+	buf, err := secret.Get()
+	if err != nil {
+		return "", err
+	}
+	defer buf.Destroy()
+	return buf.String(), nil
+}
@@ -0,0 +1,193 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// stsServer mocks the AssumeRole action of the AWS Security Token Service
+// and records the requests
+type stsServer struct {
+	requests []url.Values
+	keys     []string
+	sync.Mutex
+}
+
+var credentialRegexp = regexp.MustCompile(`Credential=([^/]+)/`)
+
+func (s *stsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.requests = append(s.requests, r.PostForm)
+	var key string
+	if match := credentialRegexp.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+		key = match[1]
+	}
+	s.keys = append(s.keys, key)
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIAHOP%d</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>%s/telegraf</Arn>
+      <AssumedRoleId>AROA:telegraf</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+  <ResponseMetadata>
+    <RequestId>c6104cbe-af31-11e0-8154-cbc7ccf896c7</RequestId>
+  </ResponseMetadata>
+</AssumeRoleResponse>`, len(s.requests), r.PostForm.Get("RoleArn"))
+}
+
+func TestAssumeRoleChain(t *testing.T) {
+	server := &stsServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	t.Setenv("AWS_ENDPOINT_URL_STS", ts.URL)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	c := &CredentialConfig{
+		Region:            "eu-central-1",
+		AccessKey:         config.NewSecret([]byte("AKIAROOT")),
+		SecretKey:         config.NewSecret([]byte("secret")),
+		RoleARN:           "arn:aws:iam::111111111111:role/hub",
+		RoleSessionName:   "telegraf",
+		ExternalID:        "hub-external-id",
+		SourceIdentity:    "telegraf-agent",
+		SessionTags:       map[string]string{"Team": "observability", "Project": "metrics"},
+		TransitiveTagKeys: []string{"Project"},
+		RoleChain: []ChainedRole{
+			{RoleARN: "arn:aws:iam::222222222222:role/collector", ExternalID: "spoke-external-id"},
+		},
+	}
+	cfg, err := c.Credentials()
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "ASIAHOP2", creds.AccessKeyID)
+
+	server.Lock()
+	defer server.Unlock()
+	require.Len(t, server.requests, 2)
+
+	// The first role is assumed using the root credentials
+	first := server.requests[0]
+	require.Equal(t, "AKIAROOT", server.keys[0])
+	require.Equal(t, "AssumeRole", first.Get("Action"))
+	require.Equal(t, "arn:aws:iam::111111111111:role/hub", first.Get("RoleArn"))
+	require.Equal(t, "telegraf", first.Get("RoleSessionName"))
+	require.Equal(t, "hub-external-id", first.Get("ExternalId"))
+	require.Equal(t, "telegraf-agent", first.Get("SourceIdentity"))
+	require.Equal(t, "Project", first.Get("Tags.member.1.Key"))
+	require.Equal(t, "metrics", first.Get("Tags.member.1.Value"))
+	require.Equal(t, "Team", first.Get("Tags.member.2.Key"))
+	require.Equal(t, "observability", first.Get("Tags.member.2.Value"))
+	require.Equal(t, "Project", first.Get("TransitiveTagKeys.member.1"))
+
+	// The chained role is assumed using the credentials of the first role
+	second := server.requests[1]
+	require.Equal(t, "ASIAHOP1", server.keys[1])
+	require.Equal(t, "arn:aws:iam::222222222222:role/collector", second.Get("RoleArn"))
+	require.Equal(t, "telegraf", second.Get("RoleSessionName"))
+	require.Equal(t, "spoke-external-id", second.Get("ExternalId"))
+	require.Empty(t, second.Get("SourceIdentity"))
+	require.Empty(t, second.Get("Tags.member.1.Key"))
+}
+
+func TestAssumeRoleInvalid(t *testing.T) {
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	c := &CredentialConfig{
+		Region:    "eu-central-1",
+		RoleChain: []ChainedRole{{RoleARN: "arn:aws:iam::222222222222:role/collector"}},
+	}
+	_, err := c.Credentials()
+	require.ErrorContains(t, err, "'role_chain' requires 'role_arn'")
+
+	c = &CredentialConfig{
+		Region:               "eu-central-1",
+		RoleARN:              "arn:aws:iam::111111111111:role/hub",
+		WebIdentityTokenFile: "/var/run/secrets/token",
+		SessionTags:          map[string]string{"Team": "observability"},
+	}
+	_, err = c.Credentials()
+	require.ErrorContains(t, err, "not supported with 'web_identity_token_file'")
+
+	c = &CredentialConfig{
+		Region:    "eu-central-1",
+		RoleARN:   "arn:aws:iam::111111111111:role/hub",
+		RoleChain: []ChainedRole{{ExternalID: "foo"}},
+	}
+	_, err = c.Credentials()
+	require.ErrorContains(t, err, "'role_arn' missing for role 1")
+}
+
+func TestSecretCredentialsRotation(t *testing.T) {
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	// Simulate a dynamic secret from a secret-store
+	key := "AKIAFIRST"
+	accessKey := config.NewSecret([]byte("@{store:access_key}"))
+	require.NoError(t, accessKey.Link(map[string]telegraf.ResolveFunc{
+		"@{store:access_key}": func() ([]byte, bool, error) { return []byte(key), true, nil },
+	}))
+
+	c := &CredentialConfig{
+		Region:    "eu-central-1",
+		AccessKey: accessKey,
+		SecretKey: config.NewSecret([]byte("secret")),
+	}
+	cfg, err := c.Credentials()
+	require.NoError(t, err)
+
+	provider := &secretCredentialsProvider{accessKey: &c.AccessKey, secretKey: &c.SecretKey, token: &c.Token}
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKIAFIRST", creds.AccessKeyID)
+	require.True(t, creds.CanExpire)
+
+	creds, err = cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKIAFIRST", creds.AccessKeyID)
+
+	// The rotated secret is picked up on the next retrieval
+	key = "AKIASECOND"
+	creds, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKIASECOND", creds.AccessKeyID)
+}
+
+func TestSecretCredentialsUnresolvable(t *testing.T) {
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	c := &CredentialConfig{
+		Region:    "eu-central-1",
+		AccessKey: config.NewSecret([]byte("@{store:access_key}")),
+		SecretKey: config.NewSecret([]byte("secret")),
+	}
+	_, err := c.Credentials()
+	require.ErrorContains(t, err, "getting 'access_key' failed")
+}
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// minRebuildInterval limits how often the credentials are rebuilt if many
+// requests fail concurrently due to the same expired credentials
+const minRebuildInterval = 10 * time.Second
+
+// expiredCredentialErrorCodes are the API error codes returned by the AWS
+// services if the credentials used to sign the request expired
+var expiredCredentialErrorCodes = []string{
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"InvalidToken",
+	"TokenRefreshRequired",
+}
+
+// refreshingCredentials provides the credentials of the wrapped provider and
+// rebuilds the provider from the settings on the next retrieval after being
+// invalidated, e.g. to re-read a rotated web identity token.
+type refreshingCredentials struct {
+	provider aws.CredentialsProvider
+	rebuild  func() (aws.Config, error)
+	stale    bool
+	rebuilt  time.Time
+	sync.Mutex
+}
+
+func (r *refreshingCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	r.Lock()
+	if r.stale {
+		cfg, err := r.rebuild()
+		if err != nil {
+			r.Unlock()
+			return aws.Credentials{}, err
+		}
+		r.provider = cfg.Credentials
+		r.stale = false
+		r.rebuilt = time.Now()
+	}
+	provider := r.provider
+	r.Unlock()
+
+	if provider == nil {
+		return aws.Credentials{}, errors.New("no credentials provider")
+	}
+	return provider.Retrieve(ctx)
+}
+
+// invalidate marks the credentials for rebuilding unless they were rebuilt
+// recently, e.g. due to another request failing with the same credentials
+func (r *refreshingCredentials) invalidate() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.stale || time.Since(r.rebuilt) < minRebuildInterval {
+		return
+	}
+	if cache, ok := r.provider.(*aws.CredentialsCache); ok {
+		cache.Invalidate()
+	}
+	r.stale = true
+}
+
+// withCredentialRefresh wraps the credentials of the config and adds a
+// middleware to all clients created from the config repeating requests
+// failing due to expired credentials.
+func withCredentialRefresh(cfg aws.Config, rebuild func() (aws.Config, error)) aws.Config {
+	creds := &refreshingCredentials{
+		provider: cfg.Credentials,
+		rebuild:  rebuild,
+	}
+	cfg.Credentials = creds
+	cfg.APIOptions = append(cfg.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(&refreshMiddleware{creds: creds}, middleware.Before)
+	})
+	return cfg
+}
+
+// refreshMiddleware repeats a request once with rebuilt credentials if it
+// failed due to expired credentials. It is placed in front of the identity
+// resolution, signing and the SDK's retry loop so the repeated request is
+// signed with the new credentials.
+type refreshMiddleware struct {
+	creds *refreshingCredentials
+}
+
+func (*refreshMiddleware) ID() string {
+	return "TelegrafCredentialRefresh"
+}
+
+func (m *refreshMiddleware) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	middleware.FinalizeOutput, middleware.Metadata, error,
+) {
+	req, ok := in.Request.(*smithyhttp.Request)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+	retry := req.Clone()
+
+	out, metadata, err := next.HandleFinalize(ctx, in)
+	if err == nil || !isExpiredCredentials(err) {
+		return out, metadata, err
+	}
+	m.creds.invalidate()
+	if rerr := retry.RewindStream(); rerr != nil {
+		return out, metadata, err
+	}
+
+	in.Request = retry
+	return next.HandleFinalize(ctx, in)
+}
+
+func isExpiredCredentials(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return slices.Contains(expiredCredentialErrorCodes, apiErr.ErrorCode())
+}
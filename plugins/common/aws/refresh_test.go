@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestCredentialRefresh(t *testing.T) {
+	// Reject requests signed with the first key as expired
+	var requests atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var key string
+		if match := credentialRegexp.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+			key = match[1]
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		if key == "AKIAFIRST" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <Error><Type>Sender</Type><Code>ExpiredToken</Code><Message>The security token included in the request is expired</Message></Error>
+  <RequestId>c6104cbe-af31-11e0-8154-cbc7ccf896c7</RequestId>
+</ErrorResponse>`)
+			return
+		}
+		fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>arn:aws:iam::111111111111:user/%s</Arn>
+    <UserId>%s</UserId>
+    <Account>111111111111</Account>
+  </GetCallerIdentityResult>
+  <ResponseMetadata><RequestId>c6104cbe-af31-11e0-8154-cbc7ccf896c7</RequestId></ResponseMetadata>
+</GetCallerIdentityResponse>`, key, key)
+	}))
+	defer ts.Close()
+	t.Setenv("AWS_ENDPOINT_URL_STS", ts.URL)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	// Simulate a secret rotated after the credentials were loaded
+	key := "AKIAFIRST"
+	accessKey := config.NewSecret([]byte("@{store:access_key}"))
+	require.NoError(t, accessKey.Link(map[string]telegraf.ResolveFunc{
+		"@{store:access_key}": func() ([]byte, bool, error) { return []byte(key), true, nil },
+	}))
+	c := &CredentialConfig{
+		Region:    "eu-central-1",
+		AccessKey: accessKey,
+		SecretKey: config.NewSecret([]byte("secret")),
+	}
+	cfg, err := c.Credentials()
+	require.NoError(t, err)
+	client := sts.NewFromConfig(cfg)
+
+	// Fails without rotation as the rebuilt credentials are still expired
+	_, err = client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	require.ErrorContains(t, err, "ExpiredToken")
+	require.Equal(t, int64(2), requests.Load())
+
+	// Recovers with the rebuilt credentials once the rebuild interval passed
+	key = "AKIASECOND"
+	creds, ok := cfg.Credentials.(*refreshingCredentials)
+	require.True(t, ok)
+	creds.Lock()
+	creds.rebuilt = creds.rebuilt.Add(-minRebuildInterval)
+	creds.Unlock()
+
+	out, err := client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	require.NoError(t, err)
+	require.Equal(t, "AKIASECOND", *out.UserId)
+	require.Equal(t, int64(4), requests.Load())
+}
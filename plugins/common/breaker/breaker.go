@@ -0,0 +1,198 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Config contains the settings for the circuit breakers of outputs writing to
+// one of multiple endpoints
+type Config struct {
+	CircuitFailureThreshold int             `toml:"circuit_failure_threshold"`
+	CircuitOpenTimeout      config.Duration `toml:"circuit_open_timeout"`
+}
+
+// State of a circuit
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Breaker tracks the health of a single endpoint. After the configured number
+// of consecutive failures the circuit opens and the endpoint is skipped until
+// the open timeout elapsed. Afterwards a single probe request is allowed
+// (half-open) closing the circuit on success or opening it again on failure.
+// A nil breaker, or one with a zero failure threshold, allows all requests.
+type Breaker struct {
+	endpoint  string
+	threshold int
+	timeout   time.Duration
+	log       telegraf.Logger
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	opened   time.Time
+	probing  bool
+
+	stateStat   selfstat.Stat
+	transitions selfstat.Stat
+	failed      selfstat.Stat
+
+	// Mockable time for testing
+	now func() time.Time
+}
+
+// New creates a breaker for the given endpoint. The tags are used for the
+// 'internal_circuit_breaker' statistics and should identify the plugin, e.g.
+// 'output=influxdb', the endpoint is added as 'endpoint' tag.
+func New(cfg Config, endpoint string, tags map[string]string, log telegraf.Logger) *Breaker {
+	stags := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		stags[k] = v
+	}
+	stags["endpoint"] = endpoint
+
+	timeout := time.Duration(cfg.CircuitOpenTimeout)
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &Breaker{
+		endpoint:    endpoint,
+		threshold:   cfg.CircuitFailureThreshold,
+		timeout:     timeout,
+		log:         log,
+		stateStat:   selfstat.Register("circuit_breaker", "state", stags),
+		transitions: selfstat.Register("circuit_breaker", "transitions", stags),
+		failed:      selfstat.Register("circuit_breaker", "failures", stags),
+		now:         time.Now,
+	}
+}
+
+// Allow returns true if a request may be sent to the endpoint. An open
+// circuit turns half-open once the timeout elapsed and then only allows a
+// single probe request until its result is reported.
+func (b *Breaker) Allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.opened) < b.timeout {
+			return false
+		}
+		b.transition(HalfOpen)
+		b.probing = true
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// Success reports a successful request closing the circuit
+func (b *Breaker) Success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != Closed {
+		b.transition(Closed)
+	}
+}
+
+// Failure reports a failed request opening the circuit if the threshold of
+// consecutive failures is reached or if the failed request was a probe
+func (b *Breaker) Failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failed.Incr(1)
+	b.failures++
+	b.probing = false
+	switch b.state {
+	case Closed:
+		if b.failures >= b.threshold {
+			b.opened = b.now()
+			b.transition(Open)
+		}
+	case HalfOpen:
+		b.opened = b.now()
+		b.transition(Open)
+	}
+}
+
+// Cancel reports a request not telling anything about the health of the
+// endpoint, e.g. because it was never sent or was rejected due to a client
+// error. A pending probe is released so another request can probe the
+// endpoint.
+func (b *Breaker) Cancel() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current state of the circuit
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) transition(state State) {
+	switch state {
+	case Open:
+		b.log.Warnf("Circuit for %q opened after %d consecutive failure(s), retrying in %s", b.endpoint, b.failures, b.timeout)
+	case HalfOpen:
+		b.log.Debugf("Circuit for %q half-open, probing endpoint", b.endpoint)
+	case Closed:
+		b.log.Infof("Circuit for %q closed, endpoint recovered", b.endpoint)
+	}
+	b.state = state
+	b.stateStat.Set(int64(state))
+	b.transitions.Incr(1)
+}
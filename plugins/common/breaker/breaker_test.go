@@ -0,0 +1,100 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestBreakerDisabled(t *testing.T) {
+	var nilBreaker *Breaker
+	require.True(t, nilBreaker.Allow())
+	nilBreaker.Failure()
+	require.Equal(t, Closed, nilBreaker.State())
+
+	b := New(Config{}, "http://localhost:8086", map[string]string{"output": "test-disabled"}, testutil.Logger{})
+	for range 10 {
+		b.Failure()
+		require.True(t, b.Allow())
+	}
+	require.Equal(t, Closed, b.State())
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	cfg := Config{
+		CircuitFailureThreshold: 2,
+		CircuitOpenTimeout:      config.Duration(10 * time.Second),
+	}
+	b := New(cfg, "http://localhost:8086", map[string]string{"output": "test-transitions"}, testutil.Logger{})
+	now := time.Unix(1700000000, 0)
+	b.now = func() time.Time { return now }
+
+	// Failures below the threshold keep the circuit closed
+	require.True(t, b.Allow())
+	b.Failure()
+	require.Equal(t, Closed, b.State())
+	require.True(t, b.Allow())
+	b.Failure()
+	require.Equal(t, Open, b.State())
+	require.False(t, b.Allow())
+
+	// After the timeout a single probe is allowed and a failed probe opens
+	// the circuit again
+	now = now.Add(10 * time.Second)
+	require.True(t, b.Allow())
+	require.Equal(t, HalfOpen, b.State())
+	require.False(t, b.Allow())
+	b.Failure()
+	require.Equal(t, Open, b.State())
+	require.False(t, b.Allow())
+
+	// A successful probe closes the circuit
+	now = now.Add(10 * time.Second)
+	require.True(t, b.Allow())
+	b.Success()
+	require.Equal(t, Closed, b.State())
+	require.True(t, b.Allow())
+	require.True(t, b.Allow())
+
+	require.Equal(t, int64(Closed), b.stateStat.Get())
+	require.Equal(t, int64(5), b.transitions.Get())
+	require.Equal(t, int64(3), b.failed.Get())
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := New(Config{CircuitFailureThreshold: 2}, "http://localhost:8086", map[string]string{"output": "test-reset"}, testutil.Logger{})
+	b.Failure()
+	b.Success()
+	b.Failure()
+	require.Equal(t, Closed, b.State())
+	b.Failure()
+	require.Equal(t, Open, b.State())
+}
+
+func TestBreakerCancelReleasesProbe(t *testing.T) {
+	cfg := Config{
+		CircuitFailureThreshold: 1,
+		CircuitOpenTimeout:      config.Duration(10 * time.Second),
+	}
+	b := New(cfg, "http://localhost:8086", map[string]string{"output": "test-cancel"}, testutil.Logger{})
+	now := time.Unix(1700000000, 0)
+	b.now = func() time.Time { return now }
+
+	require.True(t, b.Allow())
+	b.Failure()
+	require.Equal(t, Open, b.State())
+
+	// A canceled probe neither opens nor closes the circuit but allows
+	// another probe
+	now = now.Add(10 * time.Second)
+	require.True(t, b.Allow())
+	require.False(t, b.Allow())
+	b.Cancel()
+	require.Equal(t, HalfOpen, b.State())
+	require.True(t, b.Allow())
+	require.Equal(t, int64(1), b.failed.Get())
+}
@@ -0,0 +1,83 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileConfig configures the file checkpoint store
+type FileConfig struct {
+	Path string `toml:"path"`
+}
+
+// FileStore persists the checkpoints as JSON document in a local file
+type FileStore struct {
+	path        string
+	checkpoints map[string]string
+	sync.Mutex
+}
+
+func NewFileStore(cfg *FileConfig) (*FileStore, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("checkpoint file path required")
+	}
+
+	s := &FileStore{
+		path:        cfg.Path,
+		checkpoints: make(map[string]string),
+	}
+
+	buf, err := os.ReadFile(cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint file failed: %w", err)
+	}
+	if err := json.Unmarshal(buf, &s.checkpoints); err != nil {
+		return nil, fmt.Errorf("decoding checkpoint file failed: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) GetCheckpoint(streamName, shardID string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.checkpoints[streamName+"/"+shardID], nil
+}
+
+func (s *FileStore) SetCheckpoint(streamName, shardID, sequenceNumber string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.checkpoints[streamName+"/"+shardID] = sequenceNumber
+
+	buf, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it to avoid corrupting the
+	// checkpoints in case of a crash
+	tmpfile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(buf); err != nil {
+		tmpfile.Close()
+		return err
+	}
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfile.Name(), s.path)
+}
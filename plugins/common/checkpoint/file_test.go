@@ -0,0 +1,35 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+
+	store, err := NewFileStore(&FileConfig{Path: path})
+	require.NoError(t, err)
+
+	seq, err := store.GetCheckpoint("stream", "shardId-000000000000")
+	require.NoError(t, err)
+	require.Empty(t, seq)
+
+	require.NoError(t, store.SetCheckpoint("stream", "shardId-000000000000", "100"))
+	require.NoError(t, store.SetCheckpoint("stream", "shardId-000000000001", "200"))
+	require.NoError(t, store.SetCheckpoint("stream", "shardId-000000000000", "101"))
+
+	// Reopen the store to check persistence
+	store, err = NewFileStore(&FileConfig{Path: path})
+	require.NoError(t, err)
+
+	seq, err = store.GetCheckpoint("stream", "shardId-000000000000")
+	require.NoError(t, err)
+	require.Equal(t, "101", seq)
+
+	seq, err = store.GetCheckpoint("stream", "shardId-000000000001")
+	require.NoError(t, err)
+	require.Equal(t, "200", seq)
+}
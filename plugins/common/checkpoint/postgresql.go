@@ -0,0 +1,104 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	_ "github.com/jackc/pgx/v4/stdlib" // to register stdlib from PostgreSQL Driver and Toolkit
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// PostgreSQLConfig configures the PostgreSQL checkpoint store
+type PostgreSQLConfig struct {
+	AppName   string          `toml:"app_name"`
+	Address   config.Secret   `toml:"address"`
+	TableName string          `toml:"table_name"`
+	Timeout   config.Duration `toml:"timeout"`
+}
+
+// PostgreSQLStore persists the checkpoints in a PostgreSQL table with the
+// columns "namespace", "shard_id" and "sequence_number" where the first two
+// form the primary key.
+type PostgreSQLStore struct {
+	namespace string
+	timeout   time.Duration
+	db        *sql.DB
+
+	getQuery string
+	setQuery string
+}
+
+func NewPostgreSQLStore(cfg *PostgreSQLConfig) (*PostgreSQLStore, error) {
+	if cfg.AppName == "" {
+		return nil, errors.New("checkpoint app name required")
+	}
+	if cfg.TableName == "" {
+		return nil, errors.New("checkpoint table name required")
+	}
+
+	addr, err := cfg.Address.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting address failed: %w", err)
+	}
+	defer addr.Destroy()
+
+	db, err := sql.Open("pgx", addr.String())
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to postgresql failed: %w", err)
+	}
+
+	table := pgx.Identifier{cfg.TableName}.Sanitize()
+	return &PostgreSQLStore{
+		namespace: cfg.AppName,
+		timeout:   timeout,
+		db:        db,
+		getQuery:  "SELECT sequence_number FROM " + table + " WHERE namespace = $1 AND shard_id = $2",
+		setQuery: "INSERT INTO " + table + " (namespace, shard_id, sequence_number) VALUES ($1, $2, $3) " +
+			"ON CONFLICT (namespace, shard_id) DO UPDATE SET sequence_number = EXCLUDED.sequence_number",
+	}, nil
+}
+
+func (s *PostgreSQLStore) GetCheckpoint(streamName, shardID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var sequenceNumber string
+	err := s.db.QueryRowContext(ctx, s.getQuery, s.key(streamName), shardID).Scan(&sequenceNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return sequenceNumber, err
+}
+
+func (s *PostgreSQLStore) SetCheckpoint(streamName, shardID, sequenceNumber string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, s.setQuery, s.key(streamName), shardID, sequenceNumber)
+	return err
+}
+
+func (s *PostgreSQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *PostgreSQLStore) key(streamName string) string {
+	return s.namespace + "-" + streamName
+}
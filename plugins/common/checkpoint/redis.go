@@ -0,0 +1,109 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// RedisConfig configures the Redis checkpoint store
+type RedisConfig struct {
+	AppName  string          `toml:"app_name"`
+	Address  string          `toml:"address"`
+	Username config.Secret   `toml:"username"`
+	Password config.Secret   `toml:"password"`
+	Database int             `toml:"database"`
+	Timeout  config.Duration `toml:"timeout"`
+	tls.ClientConfig
+}
+
+// RedisStore persists the checkpoints as keys in Redis
+type RedisStore struct {
+	appName string
+	timeout time.Duration
+	client  *redis.Client
+}
+
+func NewRedisStore(cfg *RedisConfig) (*RedisStore, error) {
+	if cfg.AppName == "" {
+		return nil, errors.New("checkpoint app name required")
+	}
+	if cfg.Address == "" {
+		return nil, errors.New("checkpoint redis address required")
+	}
+
+	username, err := cfg.Username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+
+	password, err := cfg.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	tlsCfg, err := cfg.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:      cfg.Address,
+		Username:  username.String(),
+		Password:  password.String(),
+		DB:        cfg.Database,
+		TLSConfig: tlsCfg,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connecting to redis failed: %w", err)
+	}
+
+	return &RedisStore{
+		appName: cfg.AppName,
+		timeout: timeout,
+		client:  client,
+	}, nil
+}
+
+func (s *RedisStore) GetCheckpoint(streamName, shardID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	val, err := s.client.Get(ctx, s.key(streamName, shardID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return val, err
+}
+
+func (s *RedisStore) SetCheckpoint(streamName, shardID, sequenceNumber string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return s.client.Set(ctx, s.key(streamName, shardID), sequenceNumber, 0).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func (s *RedisStore) key(streamName, shardID string) string {
+	return s.appName + ":checkpoint:" + streamName + ":" + shardID
+}
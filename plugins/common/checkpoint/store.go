@@ -0,0 +1,15 @@
+// Package checkpoint provides the stores and the delivery tracking used by
+// stream consumers to persist the progress of each shard of a stream.
+package checkpoint
+
+// Store persists the last processed sequence number per stream and shard
+type Store interface {
+	GetCheckpoint(streamName, shardID string) (string, error)
+	SetCheckpoint(streamName, shardID, sequenceNumber string) error
+}
+
+// NoopStore implements the storage interface with discard
+type NoopStore struct{}
+
+func (NoopStore) SetCheckpoint(string, string, string) error   { return nil }
+func (NoopStore) GetCheckpoint(string, string) (string, error) { return "", nil }
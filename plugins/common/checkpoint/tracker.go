@@ -0,0 +1,150 @@
+package checkpoint
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type shardKey struct {
+	stream string
+	shard  string
+}
+
+// Record is a record of a shard waiting for its delivery
+type Record struct {
+	shard     shardKey
+	seq       string
+	done      bool
+	delivered bool
+}
+
+// NewRecord creates a record with the given sequence number read from the
+// shard of the stream
+func NewRecord(stream, shard, seq string) *Record {
+	return &Record{shard: shardKey{stream: stream, shard: shard}, seq: seq}
+}
+
+// shardProgress holds the records of a shard in the order they were read and
+// the high-water mark of the shard, i.e. the highest sequence number for which
+// all previous records of the shard are done
+type shardProgress struct {
+	pending []*Record
+	latest  string
+	updates int
+}
+
+// Tracker keeps track of the delivery progress of each shard independently
+// and persists the high-water mark of the shards. Updates are coalesced per
+// shard and written periodically or after a number of updates.
+type Tracker struct {
+	batch  int
+	shards map[shardKey]*shardProgress
+	write  func(stream, shard, seq string)
+
+	sync.Mutex
+}
+
+// NewTracker creates a tracker calling the write function to persist a
+// checkpoint after the given number of updates of a shard. With a batch size
+// of zero checkpoints are only written by Flush.
+func NewTracker(batch int, write func(stream, shard, seq string)) *Tracker {
+	return &Tracker{
+		batch:  batch,
+		shards: make(map[shardKey]*shardProgress),
+		write:  write,
+	}
+}
+
+// Track registers a record read from its shard. Records of a shard must be
+// tracked in the order they are read.
+func (t *Tracker) Track(r *Record) {
+	t.Lock()
+	defer t.Unlock()
+
+	progress, found := t.shards[r.shard]
+	if !found {
+		progress = &shardProgress{}
+		t.shards[r.shard] = progress
+	}
+	progress.pending = append(progress.pending, r)
+}
+
+// Complete marks the record as done and advances the high-water mark of its
+// shard up to the first record still pending. Records that failed to be
+// delivered do not block the shard but are never used as checkpoint.
+func (t *Tracker) Complete(r *Record, delivered bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	progress, found := t.shards[r.shard]
+	if !found || r.done {
+		return
+	}
+	r.done = true
+	r.delivered = delivered
+
+	var n int
+	for n < len(progress.pending) && progress.pending[n].done {
+		// Records re-read after a reconnect must not move the mark backwards
+		if seq := progress.pending[n].seq; progress.pending[n].delivered && SeqLess(progress.latest, seq) {
+			progress.latest = seq
+			progress.updates++
+		}
+		n++
+	}
+	progress.pending = progress.pending[n:]
+
+	if t.batch > 0 && progress.updates >= t.batch {
+		t.persist(r.shard, progress)
+	}
+}
+
+// Flush persists the checkpoints of all shards with updates
+func (t *Tracker) Flush() {
+	t.Lock()
+	defer t.Unlock()
+
+	for key, progress := range t.shards {
+		t.persist(key, progress)
+	}
+}
+
+func (t *Tracker) persist(key shardKey, progress *shardProgress) {
+	if progress.updates == 0 {
+		return
+	}
+	t.write(key.stream, key.shard, progress.latest)
+	progress.updates = 0
+}
+
+// Run periodically flushes the checkpoints until the context is done and
+// flushes the remaining updates on exit
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	defer t.Flush()
+
+	if interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Flush()
+		}
+	}
+}
+
+// SeqLess returns true if sequence number a is lower than b. Sequence numbers
+// are decimal numbers of up to 128 digits without leading zeros.
+func SeqLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,131 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type writtenCheckpoint struct {
+	stream, shard, seq string
+}
+
+func TestTrackerContiguous(t *testing.T) {
+	var written []writtenCheckpoint
+	tracker := NewTracker(0, func(stream, shard, seq string) {
+		written = append(written, writtenCheckpoint{stream, shard, seq})
+	})
+
+	r1 := NewRecord("stream", "shard-0", "1")
+	r2 := NewRecord("stream", "shard-0", "2")
+	r3 := NewRecord("stream", "shard-0", "3")
+	r10 := NewRecord("stream", "shard-1", "10")
+	for _, r := range []*Record{r1, r2, r3, r10} {
+		tracker.Track(r)
+	}
+
+	// Out-of-order delivery must not advance the checkpoint past a pending record
+	tracker.Complete(r2, true)
+	tracker.Complete(r10, true)
+	tracker.Flush()
+	require.Equal(t, []writtenCheckpoint{{"stream", "shard-1", "10"}}, written)
+
+	// Delivering the first record advances to the highest contiguous record
+	written = nil
+	tracker.Complete(r1, true)
+	tracker.Flush()
+	require.Equal(t, []writtenCheckpoint{{"stream", "shard-0", "2"}}, written)
+
+	// Failed records do not block the shard but are never persisted
+	written = nil
+	tracker.Complete(r3, false)
+	tracker.Flush()
+	require.Empty(t, written)
+
+	// Completing a record twice is ignored
+	tracker.Complete(r3, true)
+	tracker.Flush()
+	require.Empty(t, written)
+}
+
+func TestTrackerIndependentShards(t *testing.T) {
+	var written []writtenCheckpoint
+	tracker := NewTracker(1, func(stream, shard, seq string) {
+		written = append(written, writtenCheckpoint{stream, shard, seq})
+	})
+
+	// Higher sequence numbers of one shard must not suppress the checkpoints
+	// of other shards or streams
+	high := NewRecord("a", "shard-0", "49590338271490256608559692538361571095921575989136588898")
+	low := NewRecord("a", "shard-1", "49590338271490256608559692538361571095921575989136588800")
+	other := NewRecord("b", "shard-0", "1")
+	for _, r := range []*Record{high, low, other} {
+		tracker.Track(r)
+	}
+	tracker.Complete(high, true)
+	tracker.Complete(low, true)
+	tracker.Complete(other, true)
+
+	require.Equal(t, []writtenCheckpoint{
+		{"a", "shard-0", high.seq},
+		{"a", "shard-1", low.seq},
+		{"b", "shard-0", "1"},
+	}, written)
+}
+
+func TestTrackerBatch(t *testing.T) {
+	var written []writtenCheckpoint
+	tracker := NewTracker(2, func(stream, shard, seq string) {
+		written = append(written, writtenCheckpoint{stream, shard, seq})
+	})
+
+	records := []*Record{
+		NewRecord("stream", "shard-0", "1"),
+		NewRecord("stream", "shard-0", "2"),
+		NewRecord("stream", "shard-0", "3"),
+	}
+	for _, r := range records {
+		tracker.Track(r)
+	}
+	tracker.Complete(records[0], true)
+	require.Empty(t, written)
+	tracker.Complete(records[1], true)
+	require.Equal(t, []writtenCheckpoint{{"stream", "shard-0", "2"}}, written)
+	tracker.Complete(records[2], true)
+	require.Len(t, written, 1)
+
+	// Remaining updates are written when stopping
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tracker.Run(ctx, time.Hour)
+	require.Equal(t, []writtenCheckpoint{
+		{"stream", "shard-0", "2"},
+		{"stream", "shard-0", "3"},
+	}, written)
+}
+
+func TestTrackerReread(t *testing.T) {
+	var written []writtenCheckpoint
+	tracker := NewTracker(1, func(stream, shard, seq string) {
+		written = append(written, writtenCheckpoint{stream, shard, seq})
+	})
+
+	first := NewRecord("stream", "shard-0", "100")
+	tracker.Track(first)
+	tracker.Complete(first, true)
+
+	// Records read again after reconnecting must not move the checkpoint back
+	reread := NewRecord("stream", "shard-0", "99")
+	next := NewRecord("stream", "shard-0", "101")
+	tracker.Track(reread)
+	tracker.Track(next)
+	tracker.Complete(reread, true)
+	tracker.Complete(next, true)
+
+	require.Equal(t, []writtenCheckpoint{
+		{"stream", "shard-0", "100"},
+		{"stream", "shard-0", "101"},
+	}, written)
+}
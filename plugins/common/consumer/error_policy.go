@@ -0,0 +1,173 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// ErrHalt is returned if the message could not be parsed and the consumer
+// should stop consuming without acknowledging the message
+var ErrHalt = errors.New("halting consumption due to parse error")
+
+const (
+	defaultRetries    = 3
+	defaultRetryDelay = config.Duration(time.Second)
+)
+
+// ErrorPolicyConfig selects how consumer inputs handle messages that cannot
+// be parsed
+type ErrorPolicyConfig struct {
+	ParseErrorPolicy     string          `toml:"parse_error_policy"`
+	ParseErrorRetries    int             `toml:"parse_error_retries"`
+	ParseErrorRetryDelay config.Duration `toml:"parse_error_retry_delay"`
+	DeadLetterFile       string          `toml:"dead_letter_file"`
+}
+
+// ErrorPolicy applies the configured policy to messages failing to parse and
+// keeps the 'internal_consumer' statistics. A nil policy drops the messages.
+type ErrorPolicy struct {
+	policy     string
+	retries    int
+	retryDelay time.Duration
+	tags       map[string]string
+	log        telegraf.Logger
+
+	deadLetter     *os.File
+	deadLetterLock sync.Mutex
+
+	parseErrors  selfstat.Stat
+	parseRetries selfstat.Stat
+	dropped      selfstat.Stat
+	deadLettered selfstat.Stat
+	halted       selfstat.Stat
+}
+
+// deadLetterRecord is written as one JSON line per message to the dead-letter file
+type deadLetterRecord struct {
+	Time    time.Time         `json:"time"`
+	Tags    map[string]string `json:"tags"`
+	Source  string            `json:"source"`
+	Error   string            `json:"error"`
+	Payload []byte            `json:"payload"`
+}
+
+// NewErrorPolicy validates the configuration and creates the policy. The tags
+// are used for the 'internal_consumer' statistics and dead-letter records and
+// should identify the plugin, e.g. 'input=kafka_consumer'.
+func NewErrorPolicy(cfg ErrorPolicyConfig, tags map[string]string, log telegraf.Logger) (*ErrorPolicy, error) {
+	if cfg.ParseErrorPolicy == "" {
+		cfg.ParseErrorPolicy = "drop"
+	}
+	if !choice.Contains(cfg.ParseErrorPolicy, []string{"drop", "retry", "dead_letter", "halt"}) {
+		return nil, fmt.Errorf("invalid 'parse_error_policy' %q", cfg.ParseErrorPolicy)
+	}
+	if cfg.ParseErrorRetries < 0 {
+		return nil, errors.New("'parse_error_retries' must not be negative")
+	}
+	if cfg.ParseErrorRetries == 0 {
+		cfg.ParseErrorRetries = defaultRetries
+	}
+	if cfg.ParseErrorRetryDelay <= 0 {
+		cfg.ParseErrorRetryDelay = defaultRetryDelay
+	}
+
+	p := &ErrorPolicy{
+		policy:       cfg.ParseErrorPolicy,
+		retries:      cfg.ParseErrorRetries,
+		retryDelay:   time.Duration(cfg.ParseErrorRetryDelay),
+		tags:         tags,
+		log:          log,
+		parseErrors:  selfstat.Register("consumer", "parse_errors", tags),
+		parseRetries: selfstat.Register("consumer", "parse_retries", tags),
+		dropped:      selfstat.Register("consumer", "messages_dropped", tags),
+		deadLettered: selfstat.Register("consumer", "messages_dead_lettered", tags),
+		halted:       selfstat.Register("consumer", "halted", tags),
+	}
+
+	if p.policy == "dead_letter" {
+		if cfg.DeadLetterFile == "" {
+			return nil, errors.New("'dead_letter_file' is required for the 'dead_letter' policy")
+		}
+		f, err := os.OpenFile(cfg.DeadLetterFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("opening dead-letter file failed: %w", err)
+		}
+		p.deadLetter = f
+	}
+
+	return p, nil
+}
+
+// Parse calls the parse function and applies the policy if parsing fails.
+// The source, e.g. the topic, and the raw payload identify the message in
+// the dead-letter file. A non-nil error means the message did not produce
+// any metrics; the consumer must stop consuming without acknowledging the
+// message if the error is ErrHalt and discard the message otherwise.
+func (p *ErrorPolicy) Parse(source string, payload []byte, parse func() ([]telegraf.Metric, error)) ([]telegraf.Metric, error) {
+	metrics, err := parse()
+	if err == nil || p == nil {
+		return metrics, err
+	}
+	p.parseErrors.Incr(1)
+
+	switch p.policy {
+	case "retry":
+		for attempt := 1; attempt <= p.retries; attempt++ {
+			time.Sleep(p.retryDelay)
+			p.parseRetries.Incr(1)
+			if metrics, err = parse(); err == nil {
+				return metrics, nil
+			}
+			p.log.Debugf("Retry %d of %d parsing message from %q failed: %v", attempt, p.retries, source, err)
+		}
+	case "dead_letter":
+		if werr := p.writeDeadLetter(source, payload, err); werr != nil {
+			p.dropped.Incr(1)
+			return nil, fmt.Errorf("%w; writing dead-letter record failed: %w", err, werr)
+		}
+		p.deadLettered.Incr(1)
+		return nil, fmt.Errorf("message from %q written to dead-letter file: %w", source, err)
+	case "halt":
+		p.halted.Incr(1)
+		return nil, fmt.Errorf("%w: message from %q: %w", ErrHalt, source, err)
+	}
+
+	p.dropped.Incr(1)
+	return nil, err
+}
+
+func (p *ErrorPolicy) writeDeadLetter(source string, payload []byte, perr error) error {
+	buf, err := json.Marshal(&deadLetterRecord{
+		Time:    time.Now(),
+		Tags:    p.tags,
+		Source:  source,
+		Error:   perr.Error(),
+		Payload: payload,
+	})
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+
+	p.deadLetterLock.Lock()
+	defer p.deadLetterLock.Unlock()
+	_, err = p.deadLetter.Write(buf)
+	return err
+}
+
+// Close releases the dead-letter file if any
+func (p *ErrorPolicy) Close() error {
+	if p == nil || p.deadLetter == nil {
+		return nil
+	}
+	return p.deadLetter.Close()
+}
@@ -0,0 +1,107 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var errParse = errors.New("invalid line")
+
+func failingParser(failures int) func() ([]telegraf.Metric, error) {
+	var calls int
+	return func() ([]telegraf.Metric, error) {
+		calls++
+		if calls <= failures {
+			return nil, errParse
+		}
+		return []telegraf.Metric{metric.New("test", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))}, nil
+	}
+}
+
+func TestDrop(t *testing.T) {
+	p, err := NewErrorPolicy(ErrorPolicyConfig{}, map[string]string{"input": "drop"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	metrics, err := p.Parse("topic", []byte("foo"), failingParser(1))
+	require.ErrorIs(t, err, errParse)
+	require.NotErrorIs(t, err, ErrHalt)
+	require.Empty(t, metrics)
+	require.Equal(t, int64(1), p.dropped.Get())
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *ErrorPolicy
+	_, err := p.Parse("topic", []byte("foo"), failingParser(1))
+	require.ErrorIs(t, err, errParse)
+	require.NoError(t, p.Close())
+}
+
+func TestRetry(t *testing.T) {
+	cfg := ErrorPolicyConfig{
+		ParseErrorPolicy:     "retry",
+		ParseErrorRetries:    2,
+		ParseErrorRetryDelay: config.Duration(time.Millisecond),
+	}
+	p, err := NewErrorPolicy(cfg, map[string]string{"input": "retry"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	metrics, err := p.Parse("topic", []byte("foo"), failingParser(2))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, int64(2), p.parseRetries.Get())
+
+	_, err = p.Parse("topic", []byte("foo"), failingParser(3))
+	require.ErrorIs(t, err, errParse)
+	require.Equal(t, int64(1), p.dropped.Get())
+}
+
+func TestDeadLetter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dead_letter.jsonl")
+	cfg := ErrorPolicyConfig{
+		ParseErrorPolicy: "dead_letter",
+		DeadLetterFile:   filename,
+	}
+	p, err := NewErrorPolicy(cfg, map[string]string{"input": "dead_letter"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	_, err = p.Parse("topic", []byte("foo"), failingParser(1))
+	require.ErrorContains(t, err, "written to dead-letter file")
+	require.NoError(t, p.Close())
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var record deadLetterRecord
+	require.NoError(t, json.Unmarshal(buf, &record))
+	require.Equal(t, "topic", record.Source)
+	require.Equal(t, "invalid line", record.Error)
+	require.Equal(t, []byte("foo"), record.Payload)
+	require.Equal(t, map[string]string{"input": "dead_letter"}, record.Tags)
+}
+
+func TestHalt(t *testing.T) {
+	p, err := NewErrorPolicy(ErrorPolicyConfig{ParseErrorPolicy: "halt"}, map[string]string{"input": "halt"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	_, err = p.Parse("topic", []byte("foo"), failingParser(1))
+	require.ErrorIs(t, err, ErrHalt)
+	require.ErrorIs(t, err, errParse)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := NewErrorPolicy(ErrorPolicyConfig{ParseErrorPolicy: "ignore"}, nil, testutil.Logger{})
+	require.ErrorContains(t, err, "invalid 'parse_error_policy'")
+
+	_, err = NewErrorPolicy(ErrorPolicyConfig{ParseErrorPolicy: "dead_letter"}, nil, testutil.Logger{})
+	require.ErrorContains(t, err, "'dead_letter_file' is required")
+}
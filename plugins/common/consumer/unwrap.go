@@ -0,0 +1,126 @@
+package consumer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+)
+
+// UnwrapConfig selects the inner payloads of messages wrapped in an envelope
+// such as SNS notifications, EventBridge events or Debezium change events
+type UnwrapConfig struct {
+	UnwrapPaths    []string `toml:"unwrap_paths"`
+	UnwrapEncoding string   `toml:"unwrap_encoding"`
+}
+
+// Unwrapper extracts the inner payloads from the envelope before parsing. A
+// nil unwrapper passes the messages to the parser unchanged.
+type Unwrapper struct {
+	paths  []string
+	base64 bool
+}
+
+// NewUnwrapper validates the configuration and creates the unwrapper. Nil is
+// returned if no path is configured.
+func NewUnwrapper(cfg UnwrapConfig) (*Unwrapper, error) {
+	if cfg.UnwrapEncoding == "" {
+		cfg.UnwrapEncoding = "none"
+	}
+	if !choice.Contains(cfg.UnwrapEncoding, []string{"none", "base64"}) {
+		return nil, fmt.Errorf("invalid 'unwrap_encoding' %q", cfg.UnwrapEncoding)
+	}
+	if len(cfg.UnwrapPaths) == 0 {
+		if cfg.UnwrapEncoding != "none" {
+			return nil, errors.New("'unwrap_encoding' requires 'unwrap_paths'")
+		}
+		return nil, nil
+	}
+	for _, path := range cfg.UnwrapPaths {
+		if path == "" {
+			return nil, errors.New("empty path in 'unwrap_paths'")
+		}
+	}
+
+	return &Unwrapper{
+		paths:  cfg.UnwrapPaths,
+		base64: cfg.UnwrapEncoding == "base64",
+	}, nil
+}
+
+// Unwrap returns the payloads found at the configured paths of the JSON
+// envelope. Arrays result in one payload per element and strings are used
+// without quotes so that embedded documents can be parsed.
+func (u *Unwrapper) Unwrap(msg []byte) ([][]byte, error) {
+	if u == nil {
+		return [][]byte{msg}, nil
+	}
+	if !gjson.ValidBytes(msg) {
+		return nil, errors.New("envelope is not valid JSON")
+	}
+
+	var payloads [][]byte
+	for _, path := range u.paths {
+		result := gjson.GetBytes(msg, path)
+		if !result.Exists() {
+			return nil, fmt.Errorf("path %q not found in envelope", path)
+		}
+
+		values := []gjson.Result{result}
+		if result.IsArray() {
+			values = result.Array()
+		}
+		for _, v := range values {
+			payload, err := u.payload(v)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", path, err)
+			}
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads, nil
+}
+
+// Parse unwraps the message and returns the metrics of all inner payloads
+func (u *Unwrapper) Parse(parser telegraf.Parser, msg []byte) ([]telegraf.Metric, error) {
+	if u == nil {
+		return parser.Parse(msg)
+	}
+
+	payloads, err := u.Unwrap(msg)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping message failed: %w", err)
+	}
+
+	var metrics []telegraf.Metric
+	for _, payload := range payloads {
+		m, err := parser.Parse(payload)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (u *Unwrapper) payload(v gjson.Result) ([]byte, error) {
+	if v.Type != gjson.String {
+		if u.base64 {
+			return nil, fmt.Errorf("cannot decode %s as base64", v.Type)
+		}
+		return []byte(v.Raw), nil
+	}
+
+	if !u.base64 {
+		return []byte(v.Str), nil
+	}
+	payload, err := base64.StdEncoding.DecodeString(v.Str)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 failed: %w", err)
+	}
+	return payload, nil
+}
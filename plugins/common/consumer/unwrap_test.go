@@ -0,0 +1,144 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestUnwrapperInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      UnwrapConfig
+		expected string
+	}{
+		{
+			name:     "invalid encoding",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"Message"}, UnwrapEncoding: "hex"},
+			expected: `invalid 'unwrap_encoding' "hex"`,
+		},
+		{
+			name:     "encoding without paths",
+			cfg:      UnwrapConfig{UnwrapEncoding: "base64"},
+			expected: "'unwrap_encoding' requires 'unwrap_paths'",
+		},
+		{
+			name:     "empty path",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{""}},
+			expected: "empty path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewUnwrapper(tt.cfg)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+
+	u, err := NewUnwrapper(UnwrapConfig{})
+	require.NoError(t, err)
+	require.Nil(t, u)
+}
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      UnwrapConfig
+		msg      string
+		expected []string
+	}{
+		{
+			name:     "SNS notification",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"Message"}},
+			msg:      `{"Type":"Notification","Message":"{\"value\":42}"}`,
+			expected: []string{`{"value":42}`},
+		},
+		{
+			name:     "EventBridge event",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"detail"}},
+			msg:      `{"source":"aws.ec2","detail":{"state":"running"}}`,
+			expected: []string{`{"state":"running"}`},
+		},
+		{
+			name:     "array",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"logEvents.#.message"}},
+			msg:      `{"logEvents":[{"message":"a"},{"message":"b"}]}`,
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "multiple paths",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"payload.before", "payload.after"}},
+			msg:      `{"payload":{"before":{"id":1},"after":{"id":2}}}`,
+			expected: []string{`{"id":1}`, `{"id":2}`},
+		},
+		{
+			name:     "base64",
+			cfg:      UnwrapConfig{UnwrapPaths: []string{"data"}, UnwrapEncoding: "base64"},
+			msg:      `{"data":"Y3B1IHZhbHVlPTE="}`,
+			expected: []string{"cpu value=1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := NewUnwrapper(tt.cfg)
+			require.NoError(t, err)
+
+			payloads, err := u.Unwrap([]byte(tt.msg))
+			require.NoError(t, err)
+
+			actual := make([]string, 0, len(payloads))
+			for _, p := range payloads {
+				actual = append(actual, string(p))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	u, err := NewUnwrapper(UnwrapConfig{UnwrapPaths: []string{"Message"}, UnwrapEncoding: "base64"})
+	require.NoError(t, err)
+
+	_, err = u.Unwrap([]byte("cpu value=1"))
+	require.ErrorContains(t, err, "not valid JSON")
+
+	_, err = u.Unwrap([]byte(`{"Subject":"foo"}`))
+	require.ErrorContains(t, err, `path "Message" not found`)
+
+	_, err = u.Unwrap([]byte(`{"Message":"not base64!"}`))
+	require.ErrorContains(t, err, "decoding base64 failed")
+
+	_, err = u.Unwrap([]byte(`{"Message":42}`))
+	require.ErrorContains(t, err, "cannot decode Number as base64")
+}
+
+func TestUnwrapperParse(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+
+	// A nil unwrapper passes the message to the parser
+	var u *Unwrapper
+	actual, err := u.Parse(parser, []byte("cpu value=1 0\nmem value=2 0\n"))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	u, err = NewUnwrapper(UnwrapConfig{UnwrapPaths: []string{"Records.#.data"}})
+	require.NoError(t, err)
+	actual, err = u.Parse(parser, []byte(`{"Records":[{"data":"cpu value=1 0"},{"data":"mem value=2 0"}]}`))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	_, err = u.Parse(parser, []byte(`{"Records":[{"data":"cpu value="}]}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package fanout
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// Config contains the settings for gathering multiple targets, e.g. servers
+// or URLs, concurrently
+type Config struct {
+	MaxConcurrency int             `toml:"max_concurrency"`
+	TargetTimeout  config.Duration `toml:"target_timeout"`
+}
+
+// Gather calls the gather function for each of the targets with at most
+// 'max_concurrency' calls running at the same time, an unlimited number if
+// zero. If 'target_timeout' is set, the context passed to the function is
+// canceled after the timeout and a target exceeding the timeout is reported
+// as error with all its metrics being dropped; the remaining targets are
+// gathered without waiting for the function to return. The name function
+// identifies a target in the error messages. Errors are added to the
+// accumulator.
+func Gather[T any](
+	cfg Config,
+	acc telegraf.Accumulator,
+	targets []T,
+	name func(T) string,
+	gather func(context.Context, telegraf.Accumulator, T) error,
+) {
+	limit := cfg.MaxConcurrency
+	if limit <= 0 || limit > len(targets) {
+		limit = len(targets)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, limit)
+	for _, target := range targets {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(target T) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			gatherTarget(time.Duration(cfg.TargetTimeout), acc, target, name, gather)
+		}(target)
+	}
+	wg.Wait()
+}
+
+func gatherTarget[T any](
+	timeout time.Duration,
+	acc telegraf.Accumulator,
+	target T,
+	name func(T) string,
+	gather func(context.Context, telegraf.Accumulator, T) error,
+) {
+	if timeout <= 0 {
+		acc.AddError(gather(context.Background(), acc, target))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tacc := &targetAccumulator{Accumulator: acc}
+	done := make(chan error, 1)
+	go func() {
+		done <- gather(ctx, tacc, target)
+	}()
+
+	select {
+	case err := <-done:
+		acc.AddError(err)
+	case <-ctx.Done():
+		tacc.expired.Store(true)
+		acc.AddError(fmt.Errorf("gathering %q timed out after %s", name(target), timeout))
+	}
+}
+
+// targetAccumulator drops all metrics and errors of a target after the
+// target exceeded its timeout
+type targetAccumulator struct {
+	telegraf.Accumulator
+	expired atomic.Bool
+}
+
+func (a *targetAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if !a.expired.Load() {
+		a.Accumulator.AddFields(measurement, fields, tags, t...)
+	}
+}
+
+func (a *targetAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if !a.expired.Load() {
+		a.Accumulator.AddGauge(measurement, fields, tags, t...)
+	}
+}
+
+func (a *targetAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if !a.expired.Load() {
+		a.Accumulator.AddCounter(measurement, fields, tags, t...)
+	}
+}
+
+func (a *targetAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if !a.expired.Load() {
+		a.Accumulator.AddSummary(measurement, fields, tags, t...)
+	}
+}
+
+func (a *targetAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	if !a.expired.Load() {
+		a.Accumulator.AddHistogram(measurement, fields, tags, t...)
+	}
+}
+
+func (a *targetAccumulator) AddMetric(m telegraf.Metric) {
+	if !a.expired.Load() {
+		a.Accumulator.AddMetric(m)
+	}
+}
+
+func (a *targetAccumulator) AddError(err error) {
+	if !a.expired.Load() {
+		a.Accumulator.AddError(err)
+	}
+}
@@ -0,0 +1,70 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGatherConcurrency(t *testing.T) {
+	targets := make([]int, 0, 10)
+	for i := range 10 {
+		targets = append(targets, i)
+	}
+
+	var running, peak atomic.Int64
+	var acc testutil.Accumulator
+	Gather(Config{MaxConcurrency: 3}, &acc, targets, strconv.Itoa, func(_ context.Context, acc telegraf.Accumulator, target int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+
+		if target == 5 {
+			return errors.New("failed")
+		}
+		acc.AddFields("test", map[string]interface{}{"value": target}, nil)
+		return nil
+	})
+
+	require.Equal(t, int64(3), peak.Load())
+	require.Len(t, acc.GetTelegrafMetrics(), 9)
+	require.Len(t, acc.Errors, 1)
+}
+
+func TestGatherTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	cfg := Config{TargetTimeout: config.Duration(50 * time.Millisecond)}
+	var acc testutil.Accumulator
+	Gather(cfg, &acc, []string{"fast", "slow"}, func(s string) string { return s }, func(ctx context.Context, acc telegraf.Accumulator, target string) error {
+		if target == "slow" {
+			<-ctx.Done()
+			<-release
+		}
+		acc.AddFields("test", map[string]interface{}{"value": 1}, map[string]string{"target": target})
+		return nil
+	})
+
+	// Metrics of the slow target are dropped even if added after the timeout
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "fast", metrics[0].Tags()["target"])
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `gathering "slow" timed out after 50ms`)
+}
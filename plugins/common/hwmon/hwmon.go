@@ -0,0 +1,250 @@
+package hwmon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Attribute files of a sensor following the naming scheme of
+// https://www.kernel.org/doc/Documentation/hwmon/sysfs-interface.rst
+// e.g. 'temp1_input' or 'fan2_min'.
+var attributeRegexp = regexp.MustCompile(`^(in|fan|temp|curr|power|energy|humidity|intrusion)(\d+)_(\w+)$`)
+
+// Sensor represents a single sensor channel of a hwmon chip such as 'temp1'
+type Sensor struct {
+	// Chip is the name of the chip as reported by the driver, e.g. 'coretemp'
+	Chip string
+	// Adapter is the lm-sensors style bus identifier of the chip, e.g.
+	// 'isa-0000' or 'pci-00c3'
+	Adapter string
+	// Device is the base name of the device the chip is attached to
+	Device string
+	// Type is the sensor type, e.g. 'temp', 'in' or 'fan'
+	Type string
+	// Index is the channel number of the sensor within the chip
+	Index int
+	// Label is the label of the sensor as provided by the driver or the
+	// label quirks table, empty if none is available
+	Label string
+	// Values contains the attributes of the sensor, e.g. 'input' or 'max',
+	// converted to base units (degree Celsius, Volt, Ampere, Watt, Joule, ...)
+	Values map[string]float64
+}
+
+// Name returns the name of the sensor channel, e.g. 'temp1'
+func (s *Sensor) Name() string {
+	return s.Type + strconv.Itoa(s.Index)
+}
+
+// ChipName returns the chip name in the form used by lm-sensors, e.g.
+// 'coretemp-isa-0000'
+func (s *Sensor) ChipName() string {
+	return s.Chip + "-" + s.Adapter
+}
+
+// Gather collects all sensors exposed by the hwmon class below the given
+// sysfs root, usually '/sys'.
+func Gather(syspath string) ([]Sensor, error) {
+	chips, err := filepath.Glob(filepath.Join(syspath, "class", "hwmon", "hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(chips, func(i, j int) bool {
+		return chipNumber(chips[i]) < chipNumber(chips[j])
+	})
+
+	var sensors []Sensor
+	for _, chip := range chips {
+		sensors = append(sensors, gatherChip(chip)...)
+	}
+	return sensors, nil
+}
+
+func gatherChip(path string) []Sensor {
+	// Some older kernels (e.g. CentOS) expose the attributes in the
+	// additional 'device' directory instead of the hwmon directory itself
+	dir := path
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	if !hasAttributes(entries) {
+		dir = filepath.Join(path, "device")
+		if entries, err = os.ReadDir(dir); err != nil || !hasAttributes(entries) {
+			return nil
+		}
+	}
+
+	// Determine the device and use its name as fallback for the chip name
+	var device string
+	if target, err := os.Readlink(filepath.Join(path, "device")); err == nil {
+		device = filepath.Base(target)
+	}
+	name := device
+	if buf, err := readFile(dir, path, "name"); err == nil {
+		name = buf
+	}
+	q := quirks[name]
+
+	// Group the attributes by sensor channel
+	byName := make(map[string]*Sensor)
+	var sensors []*Sensor
+	for _, entry := range entries {
+		parts := attributeRegexp.FindStringSubmatch(entry.Name())
+		if parts == nil || parts[3] == "label" {
+			continue
+		}
+		sensorType, attribute := parts[1], parts[3]
+
+		buf, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			// Some attributes are write-only or return errors for
+			// disconnected sensors, so simply skip those
+			continue
+		}
+		raw, err := strconv.ParseFloat(strings.TrimSpace(string(buf)), 64)
+		if err != nil {
+			continue
+		}
+
+		id := parts[1] + parts[2]
+		s, found := byName[id]
+		if !found {
+			index, err := strconv.Atoi(parts[2])
+			if err != nil {
+				continue
+			}
+			s = &Sensor{
+				Chip:   name,
+				Device: device,
+				Type:   sensorType,
+				Index:  index,
+				Values: make(map[string]float64),
+			}
+			if label, err := readFile(dir, "", id+"_label"); err == nil {
+				s.Label = label
+			} else if label, ok := q.labels[id]; ok {
+				s.Label = label
+			}
+			byName[id] = s
+			sensors = append(sensors, s)
+		}
+		s.Values[attribute] = raw / q.divisor(id, sensorType, attribute)
+	}
+	if len(sensors) == 0 {
+		return nil
+	}
+
+	adapter := adapterName(path)
+	sort.Slice(sensors, func(i, j int) bool {
+		if sensors[i].Type != sensors[j].Type {
+			return sensors[i].Type < sensors[j].Type
+		}
+		return sensors[i].Index < sensors[j].Index
+	})
+	result := make([]Sensor, 0, len(sensors))
+	for _, s := range sensors {
+		s.Adapter = adapter
+		result = append(result, *s)
+	}
+	return result
+}
+
+// adapterName determines the bus identifier in the form used by libsensors,
+// e.g. 'pci-00c3' or 'i2c-1-48', from the device the chip is attached to.
+func adapterName(path string) string {
+	// Class devices such as NVMe controllers are not on a bus themselves so
+	// we also try the parent device.
+	link := filepath.Join(path, "device")
+	for i := 0; i < 2; i++ {
+		target, err := os.Readlink(link)
+		if err != nil {
+			break
+		}
+		subsystem, err := os.Readlink(filepath.Join(link, "subsystem"))
+		if err != nil {
+			break
+		}
+		if adapter, ok := formatAdapter(filepath.Base(subsystem), filepath.Base(target)); ok {
+			return adapter
+		}
+		link = filepath.Join(link, "device")
+	}
+
+	// Chips without a device or on unknown busses are reported as virtual
+	// by libsensors
+	return "virtual-0"
+}
+
+func formatAdapter(subsystem, device string) (string, bool) {
+	switch subsystem {
+	case "pci":
+		// Format 'dddd:bb:ss.f'
+		var domain, bus, slot, function int
+		if _, err := fmt.Sscanf(device, "%x:%x:%x.%x", &domain, &bus, &slot, &function); err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("pci-%04x", (domain<<16)+(bus<<8)+(slot<<3)+function), true
+	case "i2c":
+		// Format '<bus>-<address>'
+		var bus, addr int
+		if _, err := fmt.Sscanf(device, "%d-%x", &bus, &addr); err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("i2c-%d-%02x", bus, addr), true
+	case "platform", "of_platform":
+		// Format '<driver>.<address>', e.g. 'coretemp.0' or 'nct6775.656'
+		var addr int
+		if idx := strings.LastIndex(device, "."); idx >= 0 {
+			if v, err := strconv.Atoi(device[idx+1:]); err == nil {
+				addr = v
+			}
+		}
+		return fmt.Sprintf("isa-%04x", addr), true
+	case "acpi":
+		// Format '<hid>:<instance>', e.g. 'ACPI000D:00'
+		var addr int64
+		if idx := strings.LastIndex(device, ":"); idx >= 0 {
+			if v, err := strconv.ParseInt(device[idx+1:], 16, 64); err == nil {
+				addr = v
+			}
+		}
+		return fmt.Sprintf("acpi-%x", addr), true
+	}
+	return "", false
+}
+
+func hasAttributes(entries []os.DirEntry) bool {
+	for _, entry := range entries {
+		if attributeRegexp.MatchString(entry.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile reads and trims the given file in the directory and falls back to
+// the alternative directory if not found.
+func readFile(dir, alternative, name string) (string, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil && alternative != "" && alternative != dir {
+		buf, err = os.ReadFile(filepath.Join(alternative, name))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func chipNumber(path string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "hwmon"))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+//go:build linux
+
+package hwmon
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGather(t *testing.T) {
+	expected := []Sensor{
+		{
+			Chip:    "coretemp",
+			Adapter: "isa-0000",
+			Device:  "coretemp.0",
+			Type:    "temp",
+			Index:   1,
+			Label:   "Package id 0",
+			Values:  map[string]float64{"input": 77.0, "crit": 92.0, "crit_alarm": 0},
+		},
+		{
+			Chip:    "coretemp",
+			Adapter: "isa-0000",
+			Device:  "coretemp.0",
+			Type:    "temp",
+			Index:   2,
+			Label:   "Core 0",
+			Values:  map[string]float64{"input": 75.0},
+		},
+		{
+			Chip:    "nct6798",
+			Adapter: "isa-0290",
+			Device:  "nct6775.656",
+			Type:    "fan",
+			Index:   2,
+			Values:  map[string]float64{"input": 1205, "pulses": 2},
+		},
+		{
+			Chip:    "nct6798",
+			Adapter: "isa-0290",
+			Device:  "nct6775.656",
+			Type:    "in",
+			Index:   0,
+			Values:  map[string]float64{"input": 1.032, "max": 1.744, "alarm": 0},
+		},
+		{
+			Chip:    "nct6798",
+			Adapter: "isa-0290",
+			Device:  "nct6775.656",
+			Type:    "in",
+			Index:   10,
+			Values:  map[string]float64{"input": 0.01},
+		},
+		{
+			Chip:    "sfctemp",
+			Adapter: "isa-0000",
+			Device:  "120e0000.temperature-sensor",
+			Type:    "temp",
+			Index:   1,
+			Label:   "CPU",
+			Values:  map[string]float64{"input": 45.123},
+		},
+		{
+			Chip:    "k10temp",
+			Adapter: "virtual-0",
+			Device:  "k10temp",
+			Type:    "temp",
+			Index:   1,
+			Label:   "Tctl",
+			Values:  map[string]float64{"input": 33.25},
+		},
+		{
+			Chip:    "nvme",
+			Adapter: "virtual-0",
+			Device:  "0000:01:00.0",
+			Type:    "temp",
+			Index:   1,
+			Label:   "Composite",
+			Values:  map[string]float64{"input": 35.85, "crit": 84.85, "alarm": 0},
+		},
+		{
+			Chip:    "power_meter",
+			Adapter: "virtual-0",
+			Type:    "power",
+			Index:   1,
+			Values:  map[string]float64{"average": 15.5, "average_interval": 300},
+		},
+	}
+
+	sensors, err := Gather(filepath.Join("testdata", "sys"))
+	require.NoError(t, err)
+	require.Len(t, sensors, len(expected))
+	for i, s := range sensors {
+		require.Equal(t, expected[i].Chip, s.Chip)
+		require.Equal(t, expected[i].Adapter, s.Adapter)
+		require.Equal(t, expected[i].Device, s.Device)
+		require.Equal(t, expected[i].Type, s.Type)
+		require.Equal(t, expected[i].Index, s.Index)
+		require.Equal(t, expected[i].Label, s.Label)
+		require.Len(t, s.Values, len(expected[i].Values))
+		for k, v := range expected[i].Values {
+			require.InDelta(t, v, s.Values[k], 1e-9, "%s %s", s.Name(), k)
+		}
+	}
+}
+
+func TestGatherNoHwmon(t *testing.T) {
+	sensors, err := Gather(t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, sensors)
+}
+
+func TestFormatAdapter(t *testing.T) {
+	tests := []struct {
+		subsystem string
+		device    string
+		expected  string
+	}{
+		{subsystem: "pci", device: "0000:00:18.3", expected: "pci-00c3"},
+		{subsystem: "pci", device: "0000:01:00.0", expected: "pci-0100"},
+		{subsystem: "i2c", device: "1-0048", expected: "i2c-1-48"},
+		{subsystem: "platform", device: "coretemp.0", expected: "isa-0000"},
+		{subsystem: "platform", device: "nct6775.656", expected: "isa-0290"},
+		{subsystem: "acpi", device: "ACPI000D:00", expected: "acpi-0"},
+		{subsystem: "acpi", device: "LNXTHERM:01", expected: "acpi-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subsystem+"_"+tt.device, func(t *testing.T) {
+			actual, ok := formatAdapter(tt.subsystem, tt.device)
+			require.True(t, ok)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, ok := formatAdapter("nvme", "nvme0")
+	require.False(t, ok)
+}
@@ -0,0 +1,71 @@
+package hwmon
+
+import "strings"
+
+// Divisors to convert the units of the sysfs interface to base units per
+// sensor type, see
+// https://www.kernel.org/doc/Documentation/hwmon/sysfs-interface.rst
+var typeDivisors = map[string]float64{
+	"in":       1e3, // millivolt
+	"temp":     1e3, // millidegree Celsius
+	"curr":     1e3, // milliampere
+	"power":    1e6, // microwatt
+	"energy":   1e6, // microjoule
+	"humidity": 1e3, // milli-percent
+}
+
+// quirk describes deviations of a chip from the sysfs interface
+type quirk struct {
+	// labels for sensors where the driver does not provide a label file
+	labels map[string]string
+	// divisors for sensors not reporting in the units of the sysfs
+	// interface, overriding the default divisor of the type
+	divisors map[string]float64
+}
+
+// quirks indexed by chip name; mostly drivers found on single board computers
+// which do not provide labels and are not covered by lm-sensors' configuration
+var quirks = map[string]quirk{
+	// Raspberry Pi firmware under-voltage detection
+	"rpi_volt": {
+		labels: map[string]string{"in0": "Under-voltage"},
+	},
+	// StarFive JH7110 (RISC-V, e.g. VisionFive 2)
+	"sfctemp": {
+		labels: map[string]string{"temp1": "CPU"},
+	},
+	// Allwinner D1 / T-Head C906 (RISC-V) and Allwinner H-series (ARM)
+	"cpu_thermal": {
+		labels: map[string]string{"temp1": "CPU"},
+	},
+	"gpu_thermal": {
+		labels: map[string]string{"temp1": "GPU"},
+	},
+	// Fan controlled via PWM with tachometer, e.g. on Raspberry Pi or
+	// Rockchip boards
+	"pwmfan": {
+		labels: map[string]string{"fan1": "PWM Fan"},
+	},
+}
+
+// divisor returns the unit conversion divisor of the given sensor attribute
+func (q quirk) divisor(id, sensorType, attribute string) float64 {
+	// Alarms, faults and other flags are reported as-is. The same holds for
+	// intervals where lm-sensors reports seconds instead of milliseconds.
+	switch {
+	case strings.HasSuffix(attribute, "alarm"), strings.HasSuffix(attribute, "beep"),
+		attribute == "fault", attribute == "enable", attribute == "type",
+		attribute == "div", attribute == "pulses", attribute == "mode":
+		return 1
+	case strings.HasSuffix(attribute, "interval"):
+		return 1e3
+	}
+
+	if d, found := q.divisors[id]; found {
+		return d
+	}
+	if d, found := typeDivisors[sensorType]; found {
+		return d
+	}
+	return 1
+}
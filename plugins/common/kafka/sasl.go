@@ -1,13 +1,22 @@
 package kafka
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/IBM/sarama"
+	"github.com/aws/aws-msk-iam-sasl-signer-go/signer"
+	"github.com/aws/aws-sdk-go-v2/aws"
+
 	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 )
 
+// SASLTypeAWSMSKIAM is the mechanism name used for authenticating against
+// AWS MSK using IAM, the token is transferred using OAUTHBEARER
+const SASLTypeAWSMSKIAM = "AWS_MSK_IAM"
+
 type SASLAuth struct {
 	SASLUsername   config.Secret     `toml:"sasl_username"`
 	SASLPassword   config.Secret     `toml:"sasl_password"`
@@ -25,6 +34,9 @@ type SASLAuth struct {
 
 	// OAUTHBEARER config
 	SASLAccessToken config.Secret `toml:"sasl_access_token"`
+
+	// AWS_MSK_IAM config
+	common_aws.CredentialConfig
 }
 
 // SetSASLConfig configures SASL for kafka (sarama)
@@ -42,7 +54,14 @@ func (k *SASLAuth) SetSASLConfig(cfg *sarama.Config) error {
 	cfg.Net.SASL.Password = password.String()
 	defer password.Destroy()
 
-	if k.SASLMechanism != "" {
+	if k.SASLMechanism == SASLTypeAWSMSKIAM {
+		provider, err := k.mskTokenProvider()
+		if err != nil {
+			return err
+		}
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		cfg.Net.SASL.TokenProvider = provider
+	} else if k.SASLMechanism != "" {
 		cfg.Net.SASL.Mechanism = sarama.SASLMechanism(k.SASLMechanism)
 		switch cfg.Net.SASL.Mechanism {
 		case sarama.SASLTypeSCRAMSHA256:
@@ -96,6 +115,34 @@ func (k *SASLAuth) Token() (*sarama.AccessToken, error) {
 	}, nil
 }
 
+// mskTokenProvider creates a token provider signing the MSK IAM authentication
+// payload with the configured AWS credentials
+func (k *SASLAuth) mskTokenProvider() (*mskTokenProvider, error) {
+	awsCfg, err := k.CredentialConfig.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting AWS credentials failed: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return nil, fmt.Errorf("'region' is required for %s", SASLTypeAWSMSKIAM)
+	}
+	return &mskTokenProvider{region: awsCfg.Region, credentials: awsCfg.Credentials}, nil
+}
+
+// mskTokenProvider generates a new short-lived token for every authentication
+// so refreshed or re-assumed credentials are picked up
+type mskTokenProvider struct {
+	region      string
+	credentials aws.CredentialsProvider
+}
+
+func (p *mskTokenProvider) Token() (*sarama.AccessToken, error) {
+	token, _, err := signer.GenerateAuthTokenFromCredentialsProvider(context.Background(), p.region, p.credentials)
+	if err != nil {
+		return nil, fmt.Errorf("generating MSK IAM token failed: %w", err)
+	}
+	return &sarama.AccessToken{Token: token}, nil
+}
+
 func SASLVersion(kafkaVersion sarama.KafkaVersion, saslVersion *int) (int16, error) {
 	if saslVersion == nil {
 		if kafkaVersion.IsAtLeast(sarama.V1_0_0_0) {
@@ -0,0 +1,103 @@
+package kpl
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is mandated by the KPL aggregation format
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Aggregator packs user records into an aggregated record of limited size.
+// Partition keys shared by multiple user records are only stored once.
+type Aggregator struct {
+	maxSize  int
+	keys     []string
+	keyIndex map[string]uint64
+	records  [][]byte
+	size     int
+}
+
+// NewAggregator creates an aggregator for records of at most the given size
+// in bytes including the header and digest
+func NewAggregator(maxSize int) *Aggregator {
+	a := &Aggregator{maxSize: maxSize}
+	a.Reset()
+	return a
+}
+
+// Add appends the user record to the aggregated record. It returns false if
+// the record does not fit into the remaining space, leaving the aggregated
+// record unchanged.
+func (a *Aggregator) Add(partitionKey string, data []byte) bool {
+	index, found := a.keyIndex[partitionKey]
+	if !found {
+		index = uint64(len(a.keys))
+	}
+
+	var record []byte
+	record = protowire.AppendTag(record, 1, protowire.VarintType)
+	record = protowire.AppendVarint(record, index)
+	record = protowire.AppendTag(record, 3, protowire.BytesType)
+	record = protowire.AppendBytes(record, data)
+
+	size := protowire.SizeTag(3) + protowire.SizeBytes(len(record))
+	if !found {
+		size += protowire.SizeTag(1) + protowire.SizeBytes(len(partitionKey))
+	}
+	if a.size+size > a.maxSize {
+		return false
+	}
+
+	if !found {
+		a.keyIndex[partitionKey] = index
+		a.keys = append(a.keys, partitionKey)
+	}
+	a.records = append(a.records, record)
+	a.size += size
+	return true
+}
+
+// Len returns the number of user records added
+func (a *Aggregator) Len() int {
+	return len(a.records)
+}
+
+// Size returns the size of the aggregated record in bytes
+func (a *Aggregator) Size() int {
+	return a.size
+}
+
+// PartitionKey returns the partition key of the first user record, to be used
+// as partition key of the aggregated record
+func (a *Aggregator) PartitionKey() string {
+	if len(a.keys) == 0 {
+		return ""
+	}
+	return a.keys[0]
+}
+
+// Bytes returns the aggregated record containing all user records added
+func (a *Aggregator) Bytes() []byte {
+	msg := make([]byte, 0, a.size-len(Magic)-md5.Size)
+	for _, key := range a.keys {
+		msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+		msg = protowire.AppendString(msg, key)
+	}
+	for _, record := range a.records {
+		msg = protowire.AppendTag(msg, 3, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, record)
+	}
+
+	digest := md5.Sum(msg) //nolint:gosec // MD5 is mandated by the KPL aggregation format
+	data := make([]byte, 0, a.size)
+	data = append(data, Magic...)
+	data = append(data, msg...)
+	return append(data, digest[:]...)
+}
+
+// Reset removes all user records
+func (a *Aggregator) Reset() {
+	a.keys = a.keys[:0]
+	a.keyIndex = make(map[string]uint64)
+	a.records = a.records[:0]
+	a.size = len(Magic) + md5.Size
+}
@@ -0,0 +1,139 @@
+// Package kpl implements the record aggregation format of the Kinesis Producer
+// Library (KPL), packing multiple user records into a single Kinesis record.
+package kpl
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // MD5 is mandated by the KPL aggregation format
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Magic is the header of aggregated records followed by a protobuf message and
+// its MD5 digest, see
+// https://github.com/awslabs/amazon-kinesis-producer/blob/master/aggregation-format.md
+var Magic = []byte{0xf3, 0x89, 0x9a, 0xc2}
+
+// Record is a user record contained in an aggregated record
+type Record struct {
+	PartitionKey string
+	Data         []byte
+}
+
+// IsAggregated checks if the given data is a KPL aggregated record by
+// verifying the magic header and the MD5 digest of the protobuf message.
+func IsAggregated(data []byte) bool {
+	if len(data) <= len(Magic)+md5.Size || !bytes.HasPrefix(data, Magic) {
+		return false
+	}
+	msg := data[len(Magic) : len(data)-md5.Size]
+	digest := md5.Sum(msg) //nolint:gosec // MD5 is mandated by the KPL aggregation format
+	return bytes.Equal(digest[:], data[len(data)-md5.Size:])
+}
+
+// Deaggregate splits the given aggregated record into the contained user
+// records. The data must have been checked using IsAggregated before.
+func Deaggregate(data []byte) ([]Record, error) {
+	msg := data[len(Magic) : len(data)-md5.Size]
+
+	// Decode the AggregatedRecord message, the explicit hash key table is not
+	// relevant for consuming and thus skipped
+	var partitionKeys []string
+	var records []rawRecord
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			partitionKeys = append(partitionKeys, string(v))
+			msg = msg[n:]
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			r, err := decodeRecord(v)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, r)
+			msg = msg[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, msg)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			msg = msg[n:]
+		}
+	}
+
+	result := make([]Record, 0, len(records))
+	for i, r := range records {
+		if r.partitionKeyIndex >= uint64(len(partitionKeys)) {
+			return nil, fmt.Errorf("partition key index %d of record %d out of range", r.partitionKeyIndex, i)
+		}
+		result = append(result, Record{
+			PartitionKey: partitionKeys[r.partitionKeyIndex],
+			Data:         r.data,
+		})
+	}
+
+	return result, nil
+}
+
+type rawRecord struct {
+	partitionKeyIndex uint64
+	data              []byte
+}
+
+// decodeRecord decodes a single Record message of the aggregated record
+func decodeRecord(msg []byte) (rawRecord, error) {
+	var r rawRecord
+	var hasIndex bool
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return r, protowire.ParseError(n)
+		}
+		msg = msg[n:]
+
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return r, protowire.ParseError(n)
+			}
+			r.partitionKeyIndex = v
+			hasIndex = true
+			msg = msg[n:]
+		case num == 3 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return r, protowire.ParseError(n)
+			}
+			r.data = v
+			msg = msg[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, msg)
+			if n < 0 {
+				return r, protowire.ParseError(n)
+			}
+			msg = msg[n:]
+		}
+	}
+
+	if !hasIndex {
+		return r, errors.New("record without partition key index")
+	}
+	return r, nil
+}
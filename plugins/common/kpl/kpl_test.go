@@ -0,0 +1,50 @@
+package kpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	a := NewAggregator(1024)
+	require.True(t, a.Add("tenant-a", []byte("cpu value=1i")))
+	require.True(t, a.Add("tenant-b", []byte("cpu value=2i")))
+	require.True(t, a.Add("tenant-a", []byte("cpu value=3i")))
+	require.Equal(t, 3, a.Len())
+	require.Equal(t, "tenant-a", a.PartitionKey())
+
+	data := a.Bytes()
+	require.Len(t, data, a.Size())
+	require.True(t, IsAggregated(data))
+
+	records, err := Deaggregate(data)
+	require.NoError(t, err)
+	require.Equal(t, []Record{
+		{PartitionKey: "tenant-a", Data: []byte("cpu value=1i")},
+		{PartitionKey: "tenant-b", Data: []byte("cpu value=2i")},
+		{PartitionKey: "tenant-a", Data: []byte("cpu value=3i")},
+	}, records)
+
+	// Records of other formats are not detected as aggregated
+	require.False(t, IsAggregated([]byte("cpu value=1i")))
+	data[len(data)-1] ^= 0xff
+	require.False(t, IsAggregated(data))
+}
+
+func TestAggregateSizeLimit(t *testing.T) {
+	a := NewAggregator(64)
+	require.True(t, a.Add("key", make([]byte, 30)))
+
+	// Records exceeding the limit are rejected without changing the record
+	size := a.Size()
+	require.False(t, a.Add("other", make([]byte, 30)))
+	require.Equal(t, size, a.Size())
+	require.Equal(t, 1, a.Len())
+	require.LessOrEqual(t, len(a.Bytes()), 64)
+
+	a.Reset()
+	require.Zero(t, a.Len())
+	require.True(t, a.Add("other", make([]byte, 30)))
+	require.Equal(t, "other", a.PartitionKey())
+}
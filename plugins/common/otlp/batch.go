@@ -0,0 +1,171 @@
+package otlp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Batch collects metrics carrying an OTLP representation to send the
+// original data points instead of converting the metrics.
+type Batch struct {
+	natives map[*Native][]telegraf.Metric
+	order   []*Native
+}
+
+func NewBatch() *Batch {
+	return &Batch{natives: make(map[*Native][]telegraf.Metric)}
+}
+
+// Add adds the metric to the batch and returns false if the metric does not
+// carry an OTLP representation.
+func (b *Batch) Add(m telegraf.Metric) bool {
+	n, ok := metric.GetNative(m).(*Native)
+	if !ok {
+		return false
+	}
+	if _, found := b.natives[n]; !found {
+		b.order = append(b.order, n)
+	}
+	b.natives[n] = append(b.natives[n], m)
+	return true
+}
+
+// Build adds the data points to the given metrics, merging data points of
+// the same metric, scope and resource. The data points are used if all
+// metrics created from it are part of the batch and only their tags were
+// modified; the tag modifications are applied to the attributes. All other
+// metrics are returned for conversion.
+func (b *Batch) Build(md pmetric.Metrics) []telegraf.Metric {
+	builder := &builder{
+		md:        md,
+		resources: make(map[string]*resourceEntry),
+	}
+
+	var remaining []telegraf.Metric
+	for _, n := range b.order {
+		metrics := b.natives[n]
+		if !builder.add(n, metrics) {
+			remaining = append(remaining, metrics...)
+		}
+	}
+	return remaining
+}
+
+type builder struct {
+	md        pmetric.Metrics
+	resources map[string]*resourceEntry
+}
+
+type resourceEntry struct {
+	rm     pmetric.ResourceMetrics
+	scopes map[string]*scopeEntry
+}
+
+type scopeEntry struct {
+	sm      pmetric.ScopeMetrics
+	metrics map[string]pmetric.Metric
+}
+
+func (b *builder) add(n *Native, metrics []telegraf.Metric) bool {
+	if len(metrics) != len(n.views) {
+		return false
+	}
+	used := make([]bool, len(n.views))
+	matches := make([]int, 0, len(metrics))
+	for _, m := range metrics {
+		idx := n.match(m, used)
+		if idx < 0 {
+			return false
+		}
+		used[idx] = true
+		matches = append(matches, idx)
+	}
+
+	resource := pcommon.NewResource()
+	n.resource.CopyTo(resource)
+	scope := pcommon.NewInstrumentationScope()
+	n.scope.CopyTo(scope)
+	dp := pmetric.NewMetric()
+	n.metric.CopyTo(dp)
+	for i, m := range metrics {
+		applyTags(n.views[matches[i]], m.Tags(), resource, scope, dataPointAttributes(dp))
+	}
+
+	// Find or create the resource, scope and metric to add the data point to
+	rkey := n.resourceSchemaURL + "\x00" + attributesKey(resource.Attributes())
+	re, found := b.resources[rkey]
+	if !found {
+		rm := b.md.ResourceMetrics().AppendEmpty()
+		rm.SetSchemaUrl(n.resourceSchemaURL)
+		resource.CopyTo(rm.Resource())
+		re = &resourceEntry{rm: rm, scopes: make(map[string]*scopeEntry)}
+		b.resources[rkey] = re
+	}
+
+	skey := strings.Join([]string{n.scopeSchemaURL, scope.Name(), scope.Version(), attributesKey(scope.Attributes())}, "\x00")
+	se, found := re.scopes[skey]
+	if !found {
+		sm := re.rm.ScopeMetrics().AppendEmpty()
+		sm.SetSchemaUrl(n.scopeSchemaURL)
+		scope.CopyTo(sm.Scope())
+		se = &scopeEntry{sm: sm, metrics: make(map[string]pmetric.Metric)}
+		re.scopes[skey] = se
+	}
+
+	mkey := metricKey(dp)
+	target, found := se.metrics[mkey]
+	if !found {
+		target = se.sm.Metrics().AppendEmpty()
+		dp.MoveTo(target)
+		se.metrics[mkey] = target
+		return true
+	}
+
+	switch dp.Type() {
+	case pmetric.MetricTypeGauge:
+		dp.Gauge().DataPoints().MoveAndAppendTo(target.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		dp.Sum().DataPoints().MoveAndAppendTo(target.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		dp.Histogram().DataPoints().MoveAndAppendTo(target.Histogram().DataPoints())
+	case pmetric.MetricTypeExponentialHistogram:
+		dp.ExponentialHistogram().DataPoints().MoveAndAppendTo(target.ExponentialHistogram().DataPoints())
+	case pmetric.MetricTypeSummary:
+		dp.Summary().DataPoints().MoveAndAppendTo(target.Summary().DataPoints())
+	}
+	return true
+}
+
+// attributesKey returns a string identifying the attributes independent of
+// their order
+func attributesKey(attributes pcommon.Map) string {
+	parts := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		parts = append(parts, k+"="+v.Type().String()+":"+v.AsString())
+		return true
+	})
+	sort.Strings(parts)
+	return strings.Join(parts, "\x00")
+}
+
+// metricKey returns a string identifying the metric data points can be
+// merged into
+func metricKey(m pmetric.Metric) string {
+	parts := []string{m.Name(), m.Description(), m.Unit(), m.Type().String(), attributesKey(m.Metadata())}
+	switch m.Type() {
+	case pmetric.MetricTypeSum:
+		parts = append(parts, m.Sum().AggregationTemporality().String(), strconv.FormatBool(m.Sum().IsMonotonic()))
+	case pmetric.MetricTypeHistogram:
+		parts = append(parts, m.Histogram().AggregationTemporality().String())
+	case pmetric.MetricTypeExponentialHistogram:
+		parts = append(parts, m.ExponentialHistogram().AggregationTemporality().String())
+	}
+	return strings.Join(parts, "\x00")
+}
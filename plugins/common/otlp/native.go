@@ -0,0 +1,224 @@
+package otlp
+
+import (
+	"maps"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	semconv "go.opentelemetry.io/collector/semconv/v1.16.0"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Native is the OTLP representation of a single data point together with
+// its metric, instrumentation scope and resource. The Telegraf metrics
+// created from the data point are recorded as views to detect modifications
+// made in the pipeline.
+type Native struct {
+	resource          pcommon.Resource
+	resourceSchemaURL string
+	scope             pcommon.InstrumentationScope
+	scopeSchemaURL    string
+	metric            pmetric.Metric
+	views             []view
+}
+
+// view is the state of a Telegraf metric created from the data point
+type view struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	time   time.Time
+}
+
+func (*Native) Format() string {
+	return "otlp"
+}
+
+// Split returns the native representations of all data points contained in
+// the given metrics.
+func Split(md pmetric.Metrics) []*Native {
+	natives := make([]*Native, 0, md.DataPointCount())
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				m := sm.Metrics().At(k)
+				for l := range dataPointCount(m) {
+					n := &Native{
+						resource:          pcommon.NewResource(),
+						resourceSchemaURL: rm.SchemaUrl(),
+						scope:             pcommon.NewInstrumentationScope(),
+						scopeSchemaURL:    sm.SchemaUrl(),
+						metric:            singleDataPoint(m, l),
+					}
+					rm.Resource().CopyTo(n.resource)
+					sm.Scope().CopyTo(n.scope)
+					natives = append(natives, n)
+				}
+			}
+		}
+	}
+	return natives
+}
+
+// Metrics returns the data point as metrics e.g. for conversion
+func (n *Native) Metrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.SetSchemaUrl(n.resourceSchemaURL)
+	n.resource.CopyTo(rm.Resource())
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.SetSchemaUrl(n.scopeSchemaURL)
+	n.scope.CopyTo(sm.Scope())
+	n.metric.CopyTo(sm.Metrics().AppendEmpty())
+	return md
+}
+
+// Attach records the current state of the metrics created from the data
+// point and attaches the native representation to them. The metrics must
+// not be modified by the caller afterwards.
+func (n *Native) Attach(metrics []telegraf.Metric) {
+	n.views = make([]view, 0, len(metrics))
+	for _, m := range metrics {
+		n.views = append(n.views, view{
+			name:   m.Name(),
+			tags:   m.Tags(),
+			fields: m.Fields(),
+			time:   m.Time(),
+		})
+		metric.SetNative(m, n)
+	}
+}
+
+// match returns the index of an unused view with the name, fields and
+// timestamp of the metric or -1 if there is none
+func (n *Native) match(m telegraf.Metric, used []bool) int {
+	for i, v := range n.views {
+		if used[i] || v.name != m.Name() || !v.time.Equal(m.Time()) {
+			continue
+		}
+		if reflect.DeepEqual(v.fields, m.Fields()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyTags updates the attributes of the resource, scope and data point to
+// reflect the tag modifications between the view and the metric. New tags
+// are added as data point attributes.
+func applyTags(v view, tags map[string]string, resource pcommon.Resource, scope pcommon.InstrumentationScope, attributes pcommon.Map) {
+	if maps.Equal(v.tags, tags) {
+		return
+	}
+
+	for k := range v.tags {
+		if _, found := tags[k]; found {
+			continue
+		}
+		attributes.Remove(k)
+		scope.Attributes().Remove(k)
+		resource.Attributes().Remove(k)
+		switch k {
+		case semconv.OtelLibraryName:
+			scope.SetName("")
+		case semconv.OtelLibraryVersion:
+			scope.SetVersion("")
+		}
+	}
+
+	for k, value := range tags {
+		if current, found := v.tags[k]; found && current == value {
+			continue
+		}
+		// Keep the attribute where the conversion took the value from
+		switch {
+		case hasKey(attributes, k):
+			attributes.PutStr(k, value)
+		case k == semconv.OtelLibraryName:
+			scope.SetName(value)
+		case k == semconv.OtelLibraryVersion:
+			scope.SetVersion(value)
+		case hasKey(scope.Attributes(), k):
+			scope.Attributes().PutStr(k, value)
+		case hasKey(resource.Attributes(), k):
+			resource.Attributes().PutStr(k, value)
+		default:
+			attributes.PutStr(k, value)
+		}
+	}
+}
+
+func hasKey(attributes pcommon.Map, key string) bool {
+	_, found := attributes.Get(key)
+	return found
+}
+
+func dataPointCount(m pmetric.Metric) int {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return m.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		return m.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		return m.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		return m.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		return m.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// singleDataPoint returns a copy of the metric containing only the data
+// point with the given index
+func singleDataPoint(src pmetric.Metric, i int) pmetric.Metric {
+	dst := pmetric.NewMetric()
+	dst.SetName(src.Name())
+	dst.SetDescription(src.Description())
+	dst.SetUnit(src.Unit())
+	src.Metadata().CopyTo(dst.Metadata())
+
+	switch src.Type() {
+	case pmetric.MetricTypeGauge:
+		src.Gauge().DataPoints().At(i).CopyTo(dst.SetEmptyGauge().DataPoints().AppendEmpty())
+	case pmetric.MetricTypeSum:
+		sum := dst.SetEmptySum()
+		sum.SetAggregationTemporality(src.Sum().AggregationTemporality())
+		sum.SetIsMonotonic(src.Sum().IsMonotonic())
+		src.Sum().DataPoints().At(i).CopyTo(sum.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeHistogram:
+		histogram := dst.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(src.Histogram().AggregationTemporality())
+		src.Histogram().DataPoints().At(i).CopyTo(histogram.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeExponentialHistogram:
+		histogram := dst.SetEmptyExponentialHistogram()
+		histogram.SetAggregationTemporality(src.ExponentialHistogram().AggregationTemporality())
+		src.ExponentialHistogram().DataPoints().At(i).CopyTo(histogram.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeSummary:
+		src.Summary().DataPoints().At(i).CopyTo(dst.SetEmptySummary().DataPoints().AppendEmpty())
+	}
+	return dst
+}
+
+// dataPointAttributes returns the attributes of the first data point
+func dataPointAttributes(m pmetric.Metric) pcommon.Map {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return m.Gauge().DataPoints().At(0).Attributes()
+	case pmetric.MetricTypeSum:
+		return m.Sum().DataPoints().At(0).Attributes()
+	case pmetric.MetricTypeHistogram:
+		return m.Histogram().DataPoints().At(0).Attributes()
+	case pmetric.MetricTypeExponentialHistogram:
+		return m.ExponentialHistogram().DataPoints().At(0).Attributes()
+	case pmetric.MetricTypeSummary:
+		return m.Summary().DataPoints().At(0).Attributes()
+	}
+	return pcommon.NewMap()
+}
@@ -0,0 +1,136 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func testData() pmetric.Metrics {
+	ts := pcommon.NewTimestampFromTime(time.Unix(1700000000, 0))
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "shop")
+	rm.Resource().Attributes().PutInt("process.pid", 42)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("checkout")
+
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("requests")
+	m.SetUnit("1")
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for _, method := range []string{"GET", "POST"} {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetTimestamp(ts)
+		dp.SetStartTimestamp(ts - 10)
+		dp.SetIntValue(3)
+		dp.Attributes().PutStr("method", method)
+	}
+	return md
+}
+
+// convert mimics the conversion done by the input plugin
+func convert(n *Native) []telegraf.Metric {
+	rm := n.Metrics().ResourceMetrics().At(0)
+	dp := rm.ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0)
+	tags := map[string]string{
+		"otel.library.name": rm.ScopeMetrics().At(0).Scope().Name(),
+		"method":            dp.Attributes().AsRaw()["method"].(string),
+		"service.name":      "shop",
+		"process.pid":       "42",
+	}
+	m := metric.New("requests", tags, map[string]interface{}{"counter": dp.IntValue()}, dp.Timestamp().AsTime())
+	metrics := []telegraf.Metric{m}
+	n.Attach(metrics)
+	return metrics
+}
+
+func TestSplit(t *testing.T) {
+	natives := Split(testData())
+	require.Len(t, natives, 2)
+
+	md := natives[1].Metrics()
+	require.Equal(t, 1, md.DataPointCount())
+	m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "requests", m.Name())
+	require.Equal(t, "1", m.Unit())
+	require.True(t, m.Sum().IsMonotonic())
+	require.Equal(t, pmetric.AggregationTemporalityDelta, m.Sum().AggregationTemporality())
+	method, found := m.Sum().DataPoints().At(0).Attributes().Get("method")
+	require.True(t, found)
+	require.Equal(t, "POST", method.Str())
+}
+
+func TestBatchRoundtrip(t *testing.T) {
+	input := testData()
+
+	batch := NewBatch()
+	for _, n := range Split(input) {
+		for _, m := range convert(n) {
+			require.True(t, batch.Add(m))
+		}
+	}
+
+	// The original structure must be restored including the data lost in
+	// the conversion like the unit, temporality and attribute types
+	md := pmetric.NewMetrics()
+	require.Empty(t, batch.Build(md))
+	require.Equal(t, input, md)
+}
+
+func TestBatchModified(t *testing.T) {
+	natives := Split(testData())
+	get := convert(natives[0])
+	post := convert(natives[1])
+
+	// Tag modifications are applied to the attributes of the origin while
+	// new tags become data point attributes
+	get[0].AddTag("service.name", "store")
+	get[0].AddTag("host", "server01")
+	get[0].RemoveTag("process.pid")
+	// Field modifications require a conversion
+	post[0].AddField("counter", int64(4))
+
+	legacy := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Now())
+
+	batch := NewBatch()
+	require.True(t, batch.Add(get[0]))
+	require.True(t, batch.Add(post[0]))
+	require.False(t, batch.Add(legacy))
+
+	md := pmetric.NewMetrics()
+	remaining := batch.Build(md)
+	require.Equal(t, []telegraf.Metric{post[0]}, remaining)
+
+	require.Equal(t, 1, md.DataPointCount())
+	rm := md.ResourceMetrics().At(0)
+	require.Equal(t, map[string]interface{}{"service.name": "store"}, rm.Resource().Attributes().AsRaw())
+	dp := rm.ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0)
+	require.Equal(t, map[string]interface{}{"method": "GET", "host": "server01"}, dp.Attributes().AsRaw())
+}
+
+func TestBatchIncomplete(t *testing.T) {
+	natives := Split(testData())
+	metrics := convert(natives[0])
+
+	// Metrics not created from the data point invalidate the representation
+	extra := metrics[0].Copy()
+	metric.SetNative(extra, natives[0])
+
+	batch := NewBatch()
+	require.True(t, batch.Add(metrics[0]))
+	require.True(t, batch.Add(extra))
+
+	md := pmetric.NewMetrics()
+	require.Len(t, batch.Build(md), 2)
+	require.Equal(t, 0, md.DataPointCount())
+}
@@ -10,9 +10,9 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/stdlib"
 
 	"github.com/influxdata/telegraf/config"
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
 )
 
 var socketRegexp = regexp.MustCompile(`/\.s\.PGSQL\.\d+$`)
@@ -25,9 +25,14 @@ type Config struct {
 	MaxOpen       int             `toml:"max_open"`
 	MaxLifetime   config.Duration `toml:"max_lifetime"`
 	IsPgBouncer   bool            `toml:"-"`
+	common_sql.AuthConfig
 }
 
 func (c *Config) CreateService() (*Service, error) {
+	if err := c.AuthConfig.Init(); err != nil {
+		return nil, err
+	}
+
 	addrSecret, err := c.Address.Get()
 	if err != nil {
 		return nil, fmt.Errorf("getting address failed: %w", err)
@@ -67,10 +72,13 @@ func (c *Config) CreateService() (*Service, error) {
 	return &Service{
 		SanitizedAddress:   sanitizedAddr,
 		ConnectionDatabase: connectionDatabase(sanitizedAddr),
-		maxIdle:            c.MaxIdle,
-		maxOpen:            c.MaxOpen,
-		maxLifetime:        time.Duration(c.MaxLifetime),
-		dsn:                stdlib.RegisterConnConfig(connConfig),
+		connConfig:         connConfig,
+		pool: common_sql.PoolConfig{
+			MaxIdle:     c.MaxIdle,
+			MaxOpen:     c.MaxOpen,
+			MaxLifetime: time.Duration(c.MaxLifetime),
+			Auth:        &c.AuthConfig,
+		},
 	}, nil
 }
 
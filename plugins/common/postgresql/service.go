@@ -2,10 +2,10 @@ package postgresql
 
 import (
 	"database/sql"
-	"time"
 
-	// Blank import required to register driver
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/jackc/pgx/v4"
+
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
 )
 
 // Service common functionality shared between the postgresql and postgresql_extensible
@@ -15,28 +15,24 @@ type Service struct {
 	SanitizedAddress   string
 	ConnectionDatabase string
 
-	dsn         string
-	maxIdle     int
-	maxOpen     int
-	maxLifetime time.Duration
+	connConfig *pgx.ConnConfig
+	pool       common_sql.PoolConfig
+	conn       *common_sql.Pool
 }
 
 func (p *Service) Start() error {
-	db, err := sql.Open("pgx", p.dsn)
+	conn, err := common_sql.OpenPgx(p.connConfig, p.pool)
 	if err != nil {
 		return err
 	}
-	p.DB = db
-
-	p.DB.SetMaxOpenConns(p.maxOpen)
-	p.DB.SetMaxIdleConns(p.maxIdle)
-	p.DB.SetConnMaxLifetime(p.maxLifetime)
+	p.conn = conn
+	p.DB = conn.DB
 
 	return nil
 }
 
 func (p *Service) Stop() {
-	if p.DB != nil {
-		p.DB.Close()
+	if p.conn != nil {
+		p.conn.Close()
 	}
 }
@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// DNSResolver performs the DNS queries of the cache, implemented by
+// net.Resolver
+type DNSResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNS caches forward and reverse DNS lookups
+type DNS struct {
+	*Cache
+	Resolver DNSResolver
+}
+
+// SharedDNS returns the DNS cache shared across all plugins. The cache must
+// be released via Release when not used anymore.
+func SharedDNS(cfg Config, log telegraf.Logger) *DNS {
+	return &DNS{Cache: Shared("dns", cfg, log), Resolver: net.DefaultResolver}
+}
+
+// LookupAddr returns the names for the given address
+func (d *DNS) LookupAddr(ctx context.Context, addr string, ttl time.Duration) ([]string, error) {
+	return Lookup(ctx, d.Cache, "ptr/"+addr, ttl, func(ctx context.Context) ([]string, error) {
+		return d.Resolver.LookupAddr(ctx, addr)
+	})
+}
+
+// LookupHost returns the addresses of the given host
+func (d *DNS) LookupHost(ctx context.Context, host string, ttl time.Duration) ([]string, error) {
+	return Lookup(ctx, d.Cache, "host/"+host, ttl, func(ctx context.Context) ([]string, error) {
+		return d.Resolver.LookupHost(ctx, host)
+	})
+}
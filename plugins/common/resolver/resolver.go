@@ -0,0 +1,299 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Forever can be used as TTL for values that never expire
+const Forever = time.Duration(math.MaxInt64)
+
+// Config contains the settings for persisting a shared cache
+type Config struct {
+	ResolverCacheFile string `toml:"resolver_cache_file"`
+}
+
+// Cache holds the results of lookups, e.g. DNS queries or metadata requests,
+// for a given time-to-live. Concurrent lookups of the same key are deduplicated
+// so only a single request is in flight. Errors are not cached. Caches
+// obtained via Shared are used by all plugins requesting the same name.
+type Cache struct {
+	name string
+	log  telegraf.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	group   singleflight.Group
+	path    string
+	refs    int
+
+	hits   selfstat.Stat
+	misses selfstat.Stat
+	errors selfstat.Stat
+	size   selfstat.Stat
+
+	// Mockable time for testing
+	now func() time.Time
+}
+
+// entry is a cached value. Entries loaded from the persisted cache only hold
+// the raw JSON of the value until it is first looked up.
+type entry struct {
+	Raw     json.RawMessage `json:"value"`
+	Expires time.Time       `json:"expires,omitempty"`
+	value   interface{}
+	decoded bool
+}
+
+var (
+	shared    = make(map[string]*Cache)
+	sharedMtx sync.Mutex
+)
+
+// New creates a cache not shared with other plugins. The name is used as
+// 'cache' tag of the 'internal_resolver' statistics.
+func New(name string, log telegraf.Logger) *Cache {
+	tags := map[string]string{"cache": name}
+	return &Cache{
+		name:    name,
+		log:     log,
+		entries: make(map[string]*entry),
+		hits:    selfstat.Register("resolver", "hits", tags),
+		misses:  selfstat.Register("resolver", "misses", tags),
+		errors:  selfstat.Register("resolver", "errors", tags),
+		size:    selfstat.Register("resolver", "entries", tags),
+		now:     time.Now,
+	}
+}
+
+// Shared returns the cache with the given name shared across all plugins. If
+// a cache file is configured and the cache is not yet persisted, the entries
+// are loaded from the file and are written back once the last user released
+// the cache. Each call must be paired with a call to Release.
+func Shared(name string, cfg Config, log telegraf.Logger) *Cache {
+	sharedMtx.Lock()
+	defer sharedMtx.Unlock()
+
+	c, found := shared[name]
+	if !found {
+		c = New(name, log)
+		shared[name] = c
+	}
+	c.refs++
+
+	if cfg.ResolverCacheFile != "" {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		switch c.path {
+		case "":
+			c.path = cfg.ResolverCacheFile
+			if err := c.load(); err != nil {
+				log.Warnf("Loading resolver cache %q failed: %v", c.path, err)
+			}
+		case cfg.ResolverCacheFile:
+		default:
+			log.Warnf("Resolver cache %q is already persisted to %q, ignoring %q", name, c.path, cfg.ResolverCacheFile)
+		}
+	}
+	return c
+}
+
+// Release drops a reference to a shared cache. The cache is persisted and
+// removed once the last reference is released.
+func (c *Cache) Release() {
+	if c == nil {
+		return
+	}
+
+	sharedMtx.Lock()
+	defer sharedMtx.Unlock()
+
+	c.refs--
+	if c.refs > 0 {
+		return
+	}
+	if shared[c.name] == c {
+		delete(shared, c.name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" {
+		return
+	}
+	if err := c.save(); err != nil {
+		c.log.Errorf("Saving resolver cache %q failed: %v", c.path, err)
+	}
+}
+
+// Lookup returns the cached value for the key or calls the fetch function to
+// get the value if the key is not cached or expired. The value is cached for
+// the given TTL, a zero or negative TTL only deduplicates concurrent lookups.
+// Values must be JSON serializable for persisting the cache.
+func Lookup[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, fetch func(context.Context) (T, error)) (T, error) {
+	if v, found := get[T](c, key); found {
+		c.hits.Incr(1)
+		return v, nil
+	}
+	c.misses.Incr(1)
+
+	// Fetch the value detached from the context of the first caller as other
+	// callers might wait for the result
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		v, err := fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, v, ttl)
+		return v, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			c.errors.Incr(1)
+			return zero, res.Err
+		}
+		v, ok := res.Val.(T)
+		if !ok {
+			return zero, fmt.Errorf("cached value for %q has unexpected type %T", key, res.Val)
+		}
+		return v, nil
+	}
+}
+
+// Len returns the number of cached entries including expired ones
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func get[T any](c *Cache, key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	e, found := c.entries[key]
+	if !found {
+		return zero, false
+	}
+	if !e.Expires.IsZero() && !c.now().Before(e.Expires) {
+		delete(c.entries, key)
+		c.size.Set(int64(len(c.entries)))
+		return zero, false
+	}
+
+	// Decode values loaded from file on first use
+	if !e.decoded {
+		var v T
+		if err := json.Unmarshal(e.Raw, &v); err != nil {
+			delete(c.entries, key)
+			c.size.Set(int64(len(c.entries)))
+			return zero, false
+		}
+		e.value = v
+		e.decoded = true
+	}
+	v, ok := e.value.(T)
+	return v, ok
+}
+
+func (c *Cache) set(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	e := &entry{value: value, decoded: true}
+	if ttl != Forever {
+		e.Expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+	c.size.Set(int64(len(c.entries)))
+}
+
+// load reads the persisted entries skipping expired ones, a missing file is
+// not an error
+func (c *Cache) load() error {
+	buf, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries map[string]*entry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return fmt.Errorf("decoding failed: %w", err)
+	}
+	now := c.now()
+	for k, e := range entries {
+		if e == nil || (!e.Expires.IsZero() && !now.Before(e.Expires)) {
+			continue
+		}
+		if _, found := c.entries[k]; !found {
+			c.entries[k] = e
+		}
+	}
+	c.size.Set(int64(len(c.entries)))
+	return nil
+}
+
+// save writes the non-expired entries to the cache file by replacing the
+// file atomically
+func (c *Cache) save() error {
+	now := c.now()
+	entries := make(map[string]*entry, len(c.entries))
+	for k, e := range c.entries {
+		if !e.Expires.IsZero() && !now.Before(e.Expires) {
+			continue
+		}
+		if e.decoded {
+			raw, err := json.Marshal(e.value)
+			if err != nil {
+				// Skip values that cannot be persisted
+				continue
+			}
+			e.Raw = raw
+		}
+		entries[k] = e
+	}
+
+	buf, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	tmpfile, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(buf); err != nil {
+		tmpfile.Close()
+		return err
+	}
+	if err := tmpfile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpfile.Name(), c.path)
+}
@@ -0,0 +1,181 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestLookupTTL(t *testing.T) {
+	c := New("test-ttl", testutil.Logger{})
+	now := time.Unix(1700000000, 0)
+	c.now = func() time.Time { return now }
+
+	var calls int
+	fetch := func(context.Context) (string, error) {
+		calls++
+		return "value", nil
+	}
+
+	for range 3 {
+		v, err := Lookup(context.Background(), c, "key", time.Minute, fetch)
+		require.NoError(t, err)
+		require.Equal(t, "value", v)
+	}
+	require.Equal(t, 1, calls)
+
+	now = now.Add(time.Minute)
+	_, err := Lookup(context.Background(), c, "key", time.Minute, fetch)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Values without TTL are not cached
+	_, err = Lookup(context.Background(), c, "uncached", 0, fetch)
+	require.NoError(t, err)
+	_, err = Lookup(context.Background(), c, "uncached", 0, fetch)
+	require.NoError(t, err)
+	require.Equal(t, 4, calls)
+
+	// Values cached forever never expire
+	_, err = Lookup(context.Background(), c, "forever", Forever, fetch)
+	require.NoError(t, err)
+	now = now.Add(100 * 365 * 24 * time.Hour)
+	_, err = Lookup(context.Background(), c, "forever", Forever, fetch)
+	require.NoError(t, err)
+	require.Equal(t, 5, calls)
+}
+
+func TestLookupErrorsNotCached(t *testing.T) {
+	c := New("test-errors", testutil.Logger{})
+
+	var calls int
+	fetch := func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("temporary failure")
+		}
+		return 42, nil
+	}
+
+	_, err := Lookup(context.Background(), c, "key", time.Minute, fetch)
+	require.ErrorContains(t, err, "temporary failure")
+	v, err := Lookup(context.Background(), c, "key", time.Minute, fetch)
+	require.NoError(t, err)
+	require.Equal(t, 42, v)
+	require.Equal(t, int64(1), c.errors.Get())
+}
+
+func TestLookupDeduplication(t *testing.T) {
+	c := New("test-dedup", testutil.Logger{})
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	fetch := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := Lookup(context.Background(), c, "key", time.Minute, fetch)
+			require.NoError(t, err)
+			require.Equal(t, "value", v)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	// Give the remaining lookups time to join the pending request
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestLookupCanceled(t *testing.T) {
+	c := New("test-canceled", testutil.Logger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Lookup(ctx, c, "key", time.Minute, func(context.Context) (string, error) {
+		time.Sleep(100 * time.Millisecond)
+		return "value", nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSharedPersistence(t *testing.T) {
+	cfg := Config{ResolverCacheFile: filepath.Join(t.TempDir(), "cache.json")}
+
+	c1 := Shared("test-persistence", cfg, testutil.Logger{})
+	c2 := Shared("test-persistence", cfg, testutil.Logger{})
+	require.Same(t, c1, c2)
+
+	_, err := Lookup(context.Background(), c1, "numbers", time.Hour, func(context.Context) ([]int, error) {
+		return []int{1, 2, 3}, nil
+	})
+	require.NoError(t, err)
+	_, err = Lookup(context.Background(), c1, "expired", time.Nanosecond, func(context.Context) (string, error) {
+		return "gone", nil
+	})
+	require.NoError(t, err)
+
+	// The cache is persisted after the last reference is released
+	c1.Release()
+	require.NoFileExists(t, cfg.ResolverCacheFile)
+	c2.Release()
+	require.FileExists(t, cfg.ResolverCacheFile)
+
+	c := Shared("test-persistence", cfg, testutil.Logger{})
+	defer c.Release()
+	require.NotSame(t, c1, c)
+	require.Equal(t, 1, c.Len())
+
+	v, err := Lookup(context.Background(), c, "numbers", time.Hour, func(context.Context) ([]int, error) {
+		return nil, errors.New("not cached")
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, v)
+}
+
+type mockDNSResolver struct {
+	calls int
+}
+
+func (m *mockDNSResolver) LookupAddr(context.Context, string) ([]string, error) {
+	m.calls++
+	return []string{"localhost."}, nil
+}
+
+func (m *mockDNSResolver) LookupHost(context.Context, string) ([]string, error) {
+	m.calls++
+	return []string{"127.0.0.1"}, nil
+}
+
+func TestDNS(t *testing.T) {
+	r := &mockDNSResolver{}
+	d := &DNS{Cache: New("test-dns", testutil.Logger{}), Resolver: r}
+
+	for range 2 {
+		names, err := d.LookupAddr(context.Background(), "127.0.0.1", time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, []string{"localhost."}, names)
+
+		addrs, err := d.LookupHost(context.Background(), "localhost", time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, []string{"127.0.0.1"}, addrs)
+	}
+	require.Equal(t, 2, r.calls)
+}
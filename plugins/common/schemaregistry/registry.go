@@ -0,0 +1,171 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema types supported by the registries
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+)
+
+// Client registers schemas in a Confluent compatible schema registry. Apicurio
+// registries can be used via their Confluent compatibility API.
+type Client struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	// Registered schema IDs by subject and schema
+	ids map[string]int
+	sync.Mutex
+}
+
+// NewClient creates a client for the registry at the given address. Basic
+// authentication credentials can be passed as user-info of the URL.
+func NewClient(addr, caCertPath string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate failed: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %q", caCertPath)
+		}
+		tlsCfg = &tls.Config{RootCAs: pool}
+	}
+
+	return &Client{
+		url:      strings.TrimSuffix(u.String(), "/"),
+		username: username,
+		password: password,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				MaxIdleConns:    10,
+				IdleConnTimeout: 90 * time.Second,
+			},
+			Timeout: timeout,
+		},
+		ids: make(map[string]int),
+	}, nil
+}
+
+// Register the schema under the given subject and return its ID. Registering
+// an already existing schema returns the ID of the existing schema, so the
+// registry is only queried once per subject and schema.
+func (c *Client) Register(subject, schemaType, schema string) (int, error) {
+	key := subject + "\x00" + schema
+
+	c.Lock()
+	defer c.Unlock()
+	if id, found := c.ids[key]; found {
+		return id, nil
+	}
+
+	request := struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType,omitempty"`
+	}{Schema: schema}
+	// Avro is the default type and old registries do not know the field
+	if schemaType != TypeAvro {
+		request.SchemaType = schemaType
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+
+	addr := c.url + "/subjects/" + url.PathEscape(subject) + "/versions"
+	req, err := http.NewRequest(http.MethodPost, addr, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("registering schema for subject %q failed with status %d: %s", subject, resp.StatusCode, string(msg))
+	}
+
+	var response struct {
+		ID *int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if response.ID == nil {
+		return 0, errors.New("malformed response from schema registry: no 'id' key")
+	}
+	c.ids[key] = *response.ID
+
+	return *response.ID, nil
+}
+
+// AppendHeader appends the header of the Confluent wire format consisting of
+// the magic byte and the schema ID to the buffer
+func AppendHeader(buf []byte, id int) []byte {
+	buf = append(buf, 0)
+	return binary.BigEndian.AppendUint32(buf, uint32(id))
+}
+
+// Sanitize the given name to be valid as Avro or Protobuf identifier
+func Sanitize(name string) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
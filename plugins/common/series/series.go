@@ -0,0 +1,143 @@
+package series
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Config limits the number of series kept in memory by stateful processors
+// and aggregators
+type Config struct {
+	MaxSeries int             `toml:"max_series"`
+	SeriesTTL config.Duration `toml:"series_ttl"`
+}
+
+// Tracker keeps track of the series of a plugin ordered by the time they were
+// last seen. The tracker does not hold any plugin state itself, instead it
+// returns the IDs of series to be evicted and the plugin removes the state of
+// those series. A nil tracker does not track anything.
+type Tracker struct {
+	maxSeries int
+	ttl       time.Duration
+	log       telegraf.Logger
+
+	order    *list.List
+	elements map[uint64]*list.Element
+	warned   bool
+
+	tracked        selfstat.Stat
+	evictedLimit   selfstat.Stat
+	evictedExpired selfstat.Stat
+
+	// Mockable time for testing
+	now func() time.Time
+}
+
+type entry struct {
+	id   uint64
+	seen time.Time
+}
+
+// NewTracker creates a tracker for the given configuration. The tags are
+// used for the 'internal_series' statistics and should identify the plugin,
+// e.g. 'aggregator=derivative'.
+func NewTracker(cfg Config, tags map[string]string, log telegraf.Logger) *Tracker {
+	return &Tracker{
+		maxSeries:      cfg.MaxSeries,
+		ttl:            time.Duration(cfg.SeriesTTL),
+		log:            log,
+		order:          list.New(),
+		elements:       make(map[uint64]*list.Element),
+		tracked:        selfstat.Register("series", "tracked", tags),
+		evictedLimit:   selfstat.Register("series", "evicted_limit", tags),
+		evictedExpired: selfstat.Register("series", "evicted_expired", tags),
+		now:            time.Now,
+	}
+}
+
+// Touch marks the series as seen. In case the series is new and the maximum
+// number of series is exceeded, the least recently seen series are removed
+// and their IDs are returned.
+func (t *Tracker) Touch(id uint64) []uint64 {
+	if t == nil {
+		return nil
+	}
+
+	if e, found := t.elements[id]; found {
+		e.Value.(*entry).seen = t.now()
+		t.order.MoveToFront(e)
+		return nil
+	}
+	t.elements[id] = t.order.PushFront(&entry{id: id, seen: t.now()})
+	t.tracked.Incr(1)
+
+	if t.maxSeries <= 0 || t.order.Len() <= t.maxSeries {
+		return nil
+	}
+	if !t.warned && t.log != nil {
+		t.log.Warnf("Maximum number of %d series reached, evicting least recently seen series", t.maxSeries)
+		t.warned = true
+	}
+	evicted := make([]uint64, 0, t.order.Len()-t.maxSeries)
+	for t.order.Len() > t.maxSeries {
+		evicted = append(evicted, t.remove(t.order.Back()))
+	}
+	t.evictedLimit.Incr(int64(len(evicted)))
+	return evicted
+}
+
+// Expire removes all series not seen within the configured time-to-live and
+// returns their IDs
+func (t *Tracker) Expire() []uint64 {
+	if t == nil || t.ttl <= 0 {
+		return nil
+	}
+
+	cutoff := t.now().Add(-t.ttl)
+	var expired []uint64
+	for e := t.order.Back(); e != nil && e.Value.(*entry).seen.Before(cutoff); e = t.order.Back() {
+		expired = append(expired, t.remove(e))
+	}
+	t.evictedExpired.Incr(int64(len(expired)))
+	return expired
+}
+
+// Remove stops tracking the given series, e.g. if the plugin dropped the
+// state of the series itself
+func (t *Tracker) Remove(id uint64) {
+	if t == nil {
+		return
+	}
+	if e, found := t.elements[id]; found {
+		t.remove(e)
+	}
+}
+
+// Clear stops tracking all series
+func (t *Tracker) Clear() {
+	if t == nil {
+		return
+	}
+	t.tracked.Incr(-int64(t.order.Len()))
+	t.order.Init()
+	clear(t.elements)
+}
+
+// Len returns the number of tracked series
+func (t *Tracker) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.order.Len()
+}
+
+func (t *Tracker) remove(e *list.Element) uint64 {
+	id := t.order.Remove(e).(*entry).id
+	delete(t.elements, id)
+	t.tracked.Incr(-1)
+	return id
+}
@@ -0,0 +1,74 @@
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestMaxSeries(t *testing.T) {
+	tracker := NewTracker(Config{MaxSeries: 3}, map[string]string{"test": t.Name()}, testutil.Logger{})
+	// The statistics are global and might be left over from previous runs
+	evicted := tracker.evictedLimit.Get()
+
+	for id := range uint64(3) {
+		require.Empty(t, tracker.Touch(id))
+	}
+	require.Equal(t, 3, tracker.Len())
+
+	// Seeing series 0 again should make series 1 the least recently seen one
+	require.Empty(t, tracker.Touch(0))
+	require.Equal(t, []uint64{1}, tracker.Touch(3))
+	require.Equal(t, []uint64{2}, tracker.Touch(4))
+	require.Equal(t, 3, tracker.Len())
+
+	require.Equal(t, evicted+2, tracker.evictedLimit.Get())
+	require.Equal(t, int64(3), tracker.tracked.Get())
+
+	tracker.Remove(0)
+	require.Equal(t, 2, tracker.Len())
+	tracker.Clear()
+	require.Zero(t, tracker.Len())
+	require.Zero(t, tracker.tracked.Get())
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := NewTracker(Config{SeriesTTL: config.Duration(time.Minute)}, map[string]string{"test": t.Name()}, testutil.Logger{})
+	tracker.now = func() time.Time { return now }
+	expired := tracker.evictedExpired.Get()
+
+	tracker.Touch(1)
+	tracker.Touch(2)
+	now = now.Add(45 * time.Second)
+	tracker.Touch(3)
+	tracker.Touch(1)
+	require.Empty(t, tracker.Expire())
+
+	now = now.Add(30 * time.Second)
+	require.Equal(t, []uint64{2}, tracker.Expire())
+	require.Equal(t, 2, tracker.Len())
+
+	now = now.Add(time.Minute)
+	require.ElementsMatch(t, []uint64{1, 3}, tracker.Expire())
+	require.Zero(t, tracker.Len())
+	require.Equal(t, expired+3, tracker.evictedExpired.Get())
+}
+
+func TestUnlimited(t *testing.T) {
+	tracker := NewTracker(Config{}, map[string]string{"test": t.Name()}, testutil.Logger{})
+	for id := range uint64(1000) {
+		require.Empty(t, tracker.Touch(id))
+	}
+	require.Empty(t, tracker.Expire())
+	require.Equal(t, 1000, tracker.Len())
+
+	var disabled *Tracker
+	require.Empty(t, disabled.Touch(1))
+	require.Empty(t, disabled.Expire())
+	require.Zero(t, disabled.Len())
+}
@@ -0,0 +1,220 @@
+package shim
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// BatchHeader is the prefix of the header line announcing a batch of metrics.
+// The full header is "#batch <id> <encoding> <length>" followed by a newline
+// and the payload of <length> bytes containing the metrics in influx line
+// protocol, encoded with the given content encoding. As the header is a
+// comment in line protocol, it is ignored by parsers not supporting batches.
+const BatchHeader = "#batch"
+
+// BatchConfig configures the batched transfer of metrics to Telegraf
+type BatchConfig struct {
+	// Maximum number of metrics in a batch
+	Size int
+	// Maximum time to wait for a batch to fill up before sending it
+	Timeout time.Duration
+	// Content encoding of the batch payload, e.g. "identity", "gzip" or
+	// "zstd"
+	Encoding string
+	// Wait for Telegraf to acknowledge the delivery of a batch before
+	// accepting the contained metrics. Telegraf sends "ack <id>" or
+	// "nack <id>" lines to the process' stdin for this purpose.
+	Acknowledge bool
+	// Maximum number of unacknowledged batches before blocking
+	MaxUndelivered int
+}
+
+// batcher groups the outgoing metrics and keeps track of the batches waiting
+// for acknowledgement
+type batcher struct {
+	BatchConfig
+
+	encoder internal.ContentEncoder
+	slots   chan empty
+	pending map[uint64][]telegraf.Metric
+	nextID  uint64
+	sync.Mutex
+}
+
+// SetBatching enables the batched transfer of metrics. This requires the
+// execd input to be configured with 'batch_framing = true'.
+func (s *Shim) SetBatching(cfg BatchConfig) error {
+	if cfg.Size < 1 {
+		cfg.Size = 1000
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Encoding == "" {
+		cfg.Encoding = "identity"
+	}
+	if cfg.MaxUndelivered < 1 {
+		cfg.MaxUndelivered = 100
+	}
+
+	encoder, err := internal.NewContentEncoder(cfg.Encoding)
+	if err != nil {
+		return fmt.Errorf("invalid encoding %q: %w", cfg.Encoding, err)
+	}
+
+	s.batch = &batcher{
+		BatchConfig: cfg,
+		encoder:     encoder,
+		slots:       make(chan empty, cfg.MaxUndelivered),
+		pending:     make(map[uint64][]telegraf.Metric),
+	}
+	return nil
+}
+
+func (s *Shim) writeBatchedMetrics(ctx context.Context) error {
+	serializer := &influx.Serializer{}
+	if err := serializer.Init(); err != nil {
+		return fmt.Errorf("creating serializer failed: %w", err)
+	}
+
+	ticker := time.NewTicker(s.batch.Timeout)
+	defer ticker.Stop()
+
+	batch := make([]telegraf.Metric, 0, s.batch.Size)
+	for {
+		select {
+		case m, open := <-s.metricCh:
+			if !open {
+				return s.writeBatch(ctx, serializer, batch)
+			}
+			batch = append(batch, m)
+			if len(batch) < s.batch.Size {
+				continue
+			}
+		case <-ticker.C:
+		}
+
+		if err := s.writeBatch(ctx, serializer, batch); err != nil {
+			return err
+		}
+		batch = make([]telegraf.Metric, 0, s.batch.Size)
+	}
+}
+
+func (s *Shim) writeBatch(ctx context.Context, serializer *influx.Serializer, batch []telegraf.Metric) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	payload, err := serializer.SerializeBatch(batch)
+	if err != nil {
+		rejectAll(batch)
+		return fmt.Errorf("failed to serialize batch: %w", err)
+	}
+	payload, err = s.batch.encoder.Encode(payload)
+	if err != nil {
+		rejectAll(batch)
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	id := s.batch.register(ctx, batch)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %d %s %d\n", BatchHeader, id, s.batch.Encoding, len(payload))
+	buf.Write(payload)
+	if _, err := s.stdout.Write(buf.Bytes()); err != nil {
+		s.batch.acknowledge(id, false)
+		return fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	if !s.batch.Acknowledge {
+		s.batch.acknowledge(id, true)
+	}
+	return nil
+}
+
+// register assigns an ID to the batch and keeps it until it is acknowledged.
+// The call blocks while the maximum number of unacknowledged batches is
+// reached unless the shim is shutting down.
+func (b *batcher) register(ctx context.Context, batch []telegraf.Metric) uint64 {
+	if b.Acknowledge {
+		select {
+		case b.slots <- empty{}:
+		case <-ctx.Done():
+		}
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.nextID++
+	b.pending[b.nextID] = batch
+	return b.nextID
+}
+
+// acknowledge accepts or rejects the metrics of the given batch
+func (b *batcher) acknowledge(id uint64, delivered bool) {
+	b.Lock()
+	batch, found := b.pending[id]
+	delete(b.pending, id)
+	b.Unlock()
+	if !found {
+		return
+	}
+
+	if b.Acknowledge {
+		select {
+		case <-b.slots:
+		default:
+		}
+	}
+	if delivered {
+		for _, m := range batch {
+			m.Accept()
+		}
+	} else {
+		rejectAll(batch)
+	}
+}
+
+// rejectPending rejects all batches still waiting for acknowledgement
+func (b *batcher) rejectPending() {
+	b.Lock()
+	ids := make([]uint64, 0, len(b.pending))
+	for id := range b.pending {
+		ids = append(ids, id)
+	}
+	b.Unlock()
+
+	for _, id := range ids {
+		b.acknowledge(id, false)
+	}
+}
+
+// parseAcknowledgement parses "ack <id>" and "nack <id>" lines sent by
+// Telegraf
+func parseAcknowledgement(line string) (id uint64, delivered, ok bool) {
+	verb, value, found := strings.Cut(strings.TrimSpace(line), " ")
+	if !found || (verb != "ack" && verb != "nack") {
+		return 0, false, false
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, false
+	}
+	return id, verb == "ack", true
+}
+
+func rejectAll(metrics []telegraf.Metric) {
+	for _, m := range metrics {
+		m.Reject()
+	}
+}
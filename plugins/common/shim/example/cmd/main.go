@@ -20,6 +20,10 @@ var pollIntervalDisabled = flag.Bool(
 	"set to true to disable polling. You want to use this when you are sending metrics on your own schedule",
 )
 var configFile = flag.String("config", "", "path to the config file for this plugin")
+var batchSize = flag.Int("batch_size", 0, "send metrics in batches of the given size, requires 'batch_framing' in execd")
+var batchTimeout = flag.Duration("batch_timeout", 1*time.Second, "maximum time to wait for a batch to fill up")
+var batchEncoding = flag.String("batch_encoding", "identity", "content encoding of the batches, e.g. gzip or zstd")
+var batchAck = flag.Bool("batch_ack", false, "accept metrics only after Telegraf acknowledged the delivery of the batch")
 var err error
 
 // This is designed to be simple; Just change the import above, and you're good.
@@ -47,6 +51,20 @@ func main() {
 	// create the shim. This is what will run your plugins.
 	shimLayer := shim.New()
 
+	// Optionally send the metrics in (compressed) batches
+	if *batchSize > 0 {
+		cfg := shim.BatchConfig{
+			Size:        *batchSize,
+			Timeout:     *batchTimeout,
+			Encoding:    *batchEncoding,
+			Acknowledge: *batchAck,
+		}
+		if err = shimLayer.SetBatching(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Err setting up batching: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// If no config is specified, all imported plugins are loaded.
 	// otherwise, follow what the config asks for.
 	// Check for settings from a config toml file,
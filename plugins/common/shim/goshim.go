@@ -51,6 +51,7 @@ type Shim struct {
 
 	// input only
 	gatherPromptCh chan empty
+	batch          *batcher
 }
 
 // New creates a new shim interface
@@ -55,7 +55,12 @@ func (s *Shim) RunInput(pollInterval time.Duration) error {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
-		err := s.writeProcessedMetrics()
+		var err error
+		if s.batch != nil {
+			err = s.writeBatchedMetrics(ctx)
+		} else {
+			err = s.writeProcessedMetrics()
+		}
 		if err != nil {
 			s.log.Warn(err.Error())
 		}
@@ -65,6 +70,12 @@ func (s *Shim) RunInput(pollInterval time.Duration) error {
 	go func() {
 		scanner := bufio.NewScanner(s.stdin)
 		for scanner.Scan() {
+			if s.batch != nil {
+				if id, delivered, ok := parseAcknowledgement(scanner.Text()); ok {
+					s.batch.acknowledge(id, delivered)
+					continue
+				}
+			}
 			// push a non-blocking message to trigger metric collection.
 			s.pushCollectMetricsRequest()
 		}
@@ -73,6 +84,12 @@ func (s *Shim) RunInput(pollInterval time.Duration) error {
 	}()
 
 	wg.Wait() // wait for writing to stdout to finish
+
+	// Batches not acknowledged until now will never be, so reject them to
+	// allow tracking inputs to redeliver the metrics
+	if s.batch != nil {
+		s.batch.rejectPending()
+	}
 	return nil
 }
 
@@ -3,6 +3,7 @@ package shim
 import (
 	"bufio"
 	"io"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 )
 
 func TestInputShimTimer(t *testing.T) {
@@ -146,3 +149,120 @@ func (i *serviceInput) Start(_ telegraf.Accumulator) error {
 
 func (i *serviceInput) Stop() {
 }
+
+func TestInputShimBatching(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	inp := &trackingInput{}
+	shim := New()
+	shim.stdin = stdinReader
+	shim.stdout = stdoutWriter
+	require.NoError(t, shim.AddInput(inp))
+	require.NoError(t, shim.SetBatching(BatchConfig{
+		Size:        2,
+		Timeout:     time.Hour,
+		Encoding:    "gzip",
+		Acknowledge: true,
+	}))
+
+	exited := make(chan bool, 1)
+	go func() {
+		if err := shim.Run(PollIntervalDisabled); err != nil {
+			t.Error(err)
+		}
+		exited <- true
+	}()
+
+	decoder, err := internal.NewContentDecoder("gzip")
+	require.NoError(t, err)
+	r := bufio.NewReader(stdoutReader)
+	readBatch := func() string {
+		header, err := r.ReadString('\n')
+		require.NoError(t, err)
+		parts := strings.Fields(header)
+		require.Len(t, parts, 4)
+		require.Equal(t, BatchHeader, parts[0])
+		require.Equal(t, "gzip", parts[2])
+		length, err := strconv.Atoi(parts[3])
+		require.NoError(t, err)
+		payload := make([]byte, length)
+		_, err = io.ReadFull(r, payload)
+		require.NoError(t, err)
+		data, err := decoder.Decode(payload)
+		require.NoError(t, err)
+		return parts[1] + " " + string(data)
+	}
+
+	expected := "measurement,tag=tag field=1i 1234000005678\nmeasurement,tag=tag field=2i 1234000005678\n"
+
+	// Acknowledged batches must be reported as delivered
+	_, err = stdinWriter.Write([]byte("\n"))
+	require.NoError(t, err)
+	require.Equal(t, "1 "+expected, readBatch())
+	_, err = stdinWriter.Write([]byte("ack 1\n"))
+	require.NoError(t, err)
+	require.True(t, (<-inp.acc.Delivered()).Delivered())
+
+	// Negative acknowledgements must reject the metrics
+	_, err = stdinWriter.Write([]byte("\n"))
+	require.NoError(t, err)
+	require.Equal(t, "2 "+expected, readBatch())
+	_, err = stdinWriter.Write([]byte("nack 2\n"))
+	require.NoError(t, err)
+	require.False(t, (<-inp.acc.Delivered()).Delivered())
+
+	// Unacknowledged batches are rejected on shutdown
+	_, err = stdinWriter.Write([]byte("\n"))
+	require.NoError(t, err)
+	require.Equal(t, "3 "+expected, readBatch())
+	require.NoError(t, stdinWriter.Close())
+	<-exited
+	require.False(t, (<-inp.acc.Delivered()).Delivered())
+}
+
+func TestParseAcknowledgement(t *testing.T) {
+	id, delivered, ok := parseAcknowledgement("ack 12\n")
+	require.True(t, ok)
+	require.True(t, delivered)
+	require.Equal(t, uint64(12), id)
+
+	id, delivered, ok = parseAcknowledgement("nack 3")
+	require.True(t, ok)
+	require.False(t, delivered)
+	require.Equal(t, uint64(3), id)
+
+	for _, line := range []string{"", "ack", "ack x", "foo 1"} {
+		_, _, ok := parseAcknowledgement(line)
+		require.False(t, ok, line)
+	}
+}
+
+type trackingInput struct {
+	acc telegraf.TrackingAccumulator
+}
+
+func (*trackingInput) SampleConfig() string {
+	return ""
+}
+
+func (i *trackingInput) Start(acc telegraf.Accumulator) error {
+	i.acc = acc.WithTracking(10)
+	return nil
+}
+
+func (*trackingInput) Stop() {}
+
+func (i *trackingInput) Gather(_ telegraf.Accumulator) error {
+	group := make([]telegraf.Metric, 0, 2)
+	for _, v := range []int64{1, 2} {
+		group = append(group, metric.New(
+			"measurement",
+			map[string]string{"tag": "tag"},
+			map[string]interface{}{"field": v},
+			time.Unix(1234, 5678),
+		))
+	}
+	i.acc.AddTrackingMetricGroup(group)
+	return nil
+}
@@ -198,6 +198,10 @@ func (l *packetListener) setupUDP(u *url.URL, ifname string, bufferSize int) err
 		}
 	}
 
+	if err := disableConnectionReset(conn); err != nil {
+		l.Log.Warnf("Disabling connection-reset reporting on %s socket failed: %v", u.Scheme, err)
+	}
+
 	l.conn = conn
 	return l.setupDecoder()
 }
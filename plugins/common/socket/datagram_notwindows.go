@@ -0,0 +1,10 @@
+//go:build !windows
+
+package socket
+
+import "net"
+
+// disableConnectionReset is only required on Windows
+func disableConnectionReset(*net.UDPConn) error {
+	return nil
+}
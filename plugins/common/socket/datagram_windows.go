@@ -0,0 +1,42 @@
+//go:build windows
+
+package socket
+
+import (
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// disableConnectionReset disables reporting ICMP "port unreachable" messages
+// as WSAECONNRESET errors on the next read of the UDP socket. Windows reports
+// those messages even for listening sockets, e.g. if a datagram was sent from
+// the listening port, which terminates the listener.
+func disableConnectionReset(conn *net.UDPConn) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var ioctlErr error
+	err = raw.Control(func(fd uintptr) {
+		enable := uint32(0)
+		var returned uint32
+		ioctlErr = windows.WSAIoctl(
+			windows.Handle(fd),
+			windows.SIO_UDP_CONNRESET,
+			(*byte)(unsafe.Pointer(&enable)),
+			uint32(unsafe.Sizeof(enable)),
+			nil,
+			0,
+			&returned,
+			nil,
+			0,
+		)
+	})
+	if err != nil {
+		return err
+	}
+	return ioctlErr
+}
@@ -0,0 +1,200 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"cloud.google.com/go/cloudsqlconn"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+
+	"github.com/influxdata/telegraf/internal/choice"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+)
+
+// AuthConfig selects how to authenticate against managed database services
+// instead of using a password contained in the data source name.
+type AuthConfig struct {
+	AuthMethod              string `toml:"auth_method"`
+	CloudSQLInstance        string `toml:"cloudsql_instance"`
+	CloudSQLCredentialsFile string `toml:"cloudsql_credentials_file"`
+	common_aws.CredentialConfig
+}
+
+// Init validates the authentication settings
+func (a *AuthConfig) Init() error {
+	if a.AuthMethod == "" {
+		a.AuthMethod = "password"
+	}
+	if !choice.Contains(a.AuthMethod, []string{"password", "aws_rds_iam", "gcp_cloudsql_iam"}) {
+		return fmt.Errorf("invalid 'auth_method' %q", a.AuthMethod)
+	}
+
+	switch a.AuthMethod {
+	case "aws_rds_iam":
+		if a.Region == "" {
+			return errors.New("'region' is required for the 'aws_rds_iam' authentication")
+		}
+	case "gcp_cloudsql_iam":
+		if a.CloudSQLInstance == "" {
+			return errors.New("'cloudsql_instance' is required for the 'gcp_cloudsql_iam' authentication")
+		}
+	}
+	return nil
+}
+
+// CheckDriver returns an error if the authentication method cannot be used
+// with the given driver
+func (a *AuthConfig) CheckDriver(driverName string) error {
+	if a.enabled() && driverName != "pgx" && driverName != "mysql" {
+		return fmt.Errorf("authentication method %q not supported for driver %q", a.AuthMethod, driverName)
+	}
+	return nil
+}
+
+// enabled returns true if the connections need to be set up by the
+// authentication instead of the driver
+func (a *AuthConfig) enabled() bool {
+	return a != nil && a.AuthMethod != "" && a.AuthMethod != "password"
+}
+
+// connector creates a connector for the given driver and data source name.
+// The returned cleanup function releases resources held by the connector.
+func (a *AuthConfig) connector(driverName, dsn string) (driver.Connector, func() error, error) {
+	switch driverName {
+	case "pgx":
+		cfg, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a.pgxConnector(cfg)
+	case "mysql":
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a.mysqlConnector(cfg)
+	}
+	return nil, nil, fmt.Errorf("authentication method %q not supported for driver %q", a.AuthMethod, driverName)
+}
+
+func (a *AuthConfig) pgxConnector(cfg *pgx.ConnConfig) (driver.Connector, func() error, error) {
+	switch a.AuthMethod {
+	case "aws_rds_iam":
+		creds, err := a.awsCredentials()
+		if err != nil {
+			return nil, nil, err
+		}
+		// Tokens are only valid for 15 minutes so create one per connection
+		beforeConnect := stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
+			endpoint := net.JoinHostPort(cc.Host, strconv.Itoa(int(cc.Port)))
+			token, err := auth.BuildAuthToken(ctx, endpoint, a.Region, cc.User, creds)
+			if err != nil {
+				return fmt.Errorf("building RDS authentication token failed: %w", err)
+			}
+			cc.Password = token
+			return nil
+		})
+		return stdlib.GetConnector(*cfg, beforeConnect), func() error { return nil }, nil
+	case "gcp_cloudsql_iam":
+		dialer, err := a.cloudSQLDialer()
+		if err != nil {
+			return nil, nil, err
+		}
+		// The connector encrypts the connection itself
+		cfg.TLSConfig = nil
+		cfg.Fallbacks = nil
+		cfg.DialFunc = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.Dial(ctx, a.CloudSQLInstance)
+		}
+		return stdlib.GetConnector(*cfg), dialer.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unknown authentication method %q", a.AuthMethod)
+}
+
+func (a *AuthConfig) mysqlConnector(cfg *mysql.Config) (driver.Connector, func() error, error) {
+	cleanup := func() error { return nil }
+
+	switch a.AuthMethod {
+	case "aws_rds_iam":
+		creds, err := a.awsCredentials()
+		if err != nil {
+			return nil, nil, err
+		}
+		endpoint := cfg.Addr
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			endpoint = net.JoinHostPort(endpoint, "3306")
+		}
+		// The token is sent as cleartext password which is only safe over
+		// TLS, therefore RDS requires TLS for IAM authentication.
+		cfg.AllowCleartextPasswords = true
+		err = cfg.Apply(mysql.BeforeConnect(func(ctx context.Context, c *mysql.Config) error {
+			token, err := auth.BuildAuthToken(ctx, endpoint, a.Region, c.User, creds)
+			if err != nil {
+				return fmt.Errorf("building RDS authentication token failed: %w", err)
+			}
+			c.Passwd = token
+			return nil
+		}))
+		if err != nil {
+			return nil, nil, err
+		}
+	case "gcp_cloudsql_iam":
+		dialer, err := a.cloudSQLDialer()
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = dialer.Close
+
+		// The dial functions are registered globally so use a unique name
+		// for each connector. The connector encrypts the connection itself.
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create UUID: %w", err)
+		}
+		network := "cloudsql-" + id.String()
+		mysql.RegisterDialContext(network, func(ctx context.Context, _ string) (net.Conn, error) {
+			return dialer.Dial(ctx, a.CloudSQLInstance)
+		})
+		cfg.Net = network
+		cfg.TLS = nil
+		cfg.TLSConfig = ""
+	default:
+		return nil, nil, fmt.Errorf("unknown authentication method %q", a.AuthMethod)
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		cleanup() //nolint:errcheck // ignore the error as we return the connector error
+		return nil, nil, err
+	}
+	return connector, cleanup, nil
+}
+
+func (a *AuthConfig) awsCredentials() (aws.CredentialsProvider, error) {
+	cfg, err := a.CredentialConfig.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting AWS credentials failed: %w", err)
+	}
+	return cfg.Credentials, nil
+}
+
+func (a *AuthConfig) cloudSQLDialer() (*cloudsqlconn.Dialer, error) {
+	opts := []cloudsqlconn.Option{cloudsqlconn.WithIAMAuthN()}
+	if a.CloudSQLCredentialsFile != "" {
+		opts = append(opts, cloudsqlconn.WithCredentialsFile(a.CloudSQLCredentialsFile))
+	}
+	dialer, err := cloudsqlconn.NewDialer(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating Cloud SQL dialer failed: %w", err)
+	}
+	return dialer, nil
+}
@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+// DefaultTimeout is used for health checks if no timeout is configured
+const DefaultTimeout = 5 * time.Second
+
+// PoolConfig contains the settings for a connection pool. A value of zero
+// for any of the limits means "unlimited" except for MaxIdle where zero
+// disables keeping idle connections.
+type PoolConfig struct {
+	MaxOpen     int
+	MaxIdle     int
+	MaxLifetime time.Duration
+	MaxIdleTime time.Duration
+
+	// HealthCheckQuery is executed to check the connection. If empty the
+	// server is pinged instead.
+	HealthCheckQuery string
+
+	// Timeout is applied to health checks and to queries executed with a
+	// context obtained by WithTimeout. A value of zero disables the timeout
+	// for queries while health checks use DefaultTimeout.
+	Timeout time.Duration
+
+	// Auth optionally authenticates the connections using the credentials
+	// of a cloud provider
+	Auth *AuthConfig
+}
+
+// Pool is a database handle with the configured limits applied
+type Pool struct {
+	*dbsql.DB
+
+	cfg     PoolConfig
+	cleanup func() error
+}
+
+// Open opens a pool for the given driver and data source name. No connection
+// is established, use Check to verify connectivity.
+func Open(driverName, dsn string, cfg PoolConfig) (*Pool, error) {
+	if cfg.Auth.enabled() {
+		connector, cleanup, err := cfg.Auth.connector(driverName, dsn)
+		if err != nil {
+			return nil, err
+		}
+		pool := OpenConnector(connector, cfg)
+		pool.cleanup = cleanup
+		return pool, nil
+	}
+
+	db, err := dbsql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newPool(db, cfg), nil
+}
+
+// OpenPgx opens a pool for the given pgx connection configuration
+func OpenPgx(connConfig *pgx.ConnConfig, cfg PoolConfig) (*Pool, error) {
+	if cfg.Auth.enabled() {
+		connector, cleanup, err := cfg.Auth.pgxConnector(connConfig)
+		if err != nil {
+			return nil, err
+		}
+		pool := OpenConnector(connector, cfg)
+		pool.cleanup = cleanup
+		return pool, nil
+	}
+	return OpenConnector(stdlib.GetConnector(*connConfig), cfg), nil
+}
+
+// OpenConnector opens a pool using the given connector. This allows drivers
+// to provide credentials, e.g. short-lived tokens, for each new connection.
+func OpenConnector(connector driver.Connector, cfg PoolConfig) *Pool {
+	return newPool(dbsql.OpenDB(connector), cfg)
+}
+
+func newPool(db *dbsql.DB, cfg PoolConfig) *Pool {
+	db.SetMaxOpenConns(cfg.MaxOpen)
+	db.SetMaxIdleConns(cfg.MaxIdle)
+	db.SetConnMaxLifetime(cfg.MaxLifetime)
+	db.SetConnMaxIdleTime(cfg.MaxIdleTime)
+
+	return &Pool{DB: db, cfg: cfg}
+}
+
+// WithTimeout returns a context bound by the configured timeout which should
+// be used for executing a single query. The context is not bound if no
+// timeout is configured.
+func (p *Pool) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.cfg.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.cfg.Timeout)
+}
+
+// Close closes the pool and releases resources used for authentication
+func (p *Pool) Close() error {
+	err := p.DB.Close()
+	if p.cleanup != nil {
+		if cerr := p.cleanup(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Check verifies the server is reachable by running the health-check query
+// or by pinging the server if no query is configured
+func (p *Pool) Check(ctx context.Context) error {
+	timeout := p.cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if p.cfg.HealthCheckQuery == "" {
+		return p.PingContext(ctx)
+	}
+
+	if _, err := p.ExecContext(ctx, p.cfg.HealthCheckQuery); err != nil {
+		return fmt.Errorf("health-check query failed: %w", err)
+	}
+	return nil
+}
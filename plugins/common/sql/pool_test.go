@@ -0,0 +1,58 @@
+//go:build !mips && !mipsle && !mips64 && !ppc64 && !riscv64 && !loong64 && !mips64le && !(windows && (386 || arm))
+
+package sql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestPoolLimits(t *testing.T) {
+	pool, err := Open("sqlite", filepath.Join(t.TempDir(), "db"), PoolConfig{MaxOpen: 3})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Equal(t, 3, pool.Stats().MaxOpenConnections)
+	require.Zero(t, pool.cfg.Timeout)
+
+	// Queries are not bound without timeout
+	ctx, cancel := pool.WithTimeout(context.Background())
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	require.False(t, hasDeadline)
+}
+
+func TestPoolCheck(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "db")
+
+	pool, err := Open("sqlite", dsn, PoolConfig{})
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, pool.Check(context.Background()))
+
+	pool, err = Open("sqlite", dsn, PoolConfig{HealthCheckQuery: "SELECT 1"})
+	require.NoError(t, err)
+	defer pool.Close()
+	require.NoError(t, pool.Check(context.Background()))
+
+	pool, err = Open("sqlite", dsn, PoolConfig{HealthCheckQuery: "SELECT * FROM missing"})
+	require.NoError(t, err)
+	defer pool.Close()
+	require.ErrorContains(t, pool.Check(context.Background()), "health-check query failed")
+}
+
+func TestPoolTimeout(t *testing.T) {
+	pool, err := Open("sqlite", filepath.Join(t.TempDir(), "db"), PoolConfig{Timeout: time.Millisecond})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx, cancel := pool.WithTimeout(context.Background())
+	defer cancel()
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
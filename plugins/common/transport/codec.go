@@ -0,0 +1,305 @@
+package transport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Maximum number of entries in the dictionary of a connection
+const dictionaryLimit = 65536
+
+// Field value types
+const (
+	fieldFloat uint8 = iota
+	fieldInt
+	fieldUint
+	fieldString
+	fieldBool
+)
+
+// Encoder serializes metrics into the body of a batch. Measurement names, tag
+// keys and field keys are sent only once per connection and referenced by
+// their index in the dictionary afterwards, as those repeat in every batch
+// while tag values often do not. The dictionary is shared by the encoder and
+// decoder of a connection, so both must process the same batches in the same
+// order and must be recreated when reconnecting.
+//
+// A batch consists of the number of metrics followed by the metrics:
+//
+//	metric: ref name | uint8 type | varint timestamp | uvarint #tags | tags | uvarint #fields | fields
+//	tag:    ref key | string value
+//	field:  ref key | uint8 value type | value
+//	ref:    uvarint index+1 into the dictionary, or 0 followed by a string
+//	string: uvarint length | bytes
+//
+// Strings sent after a zero reference are added to the dictionary until its
+// limit is reached.
+type Encoder struct {
+	dict map[string]uint64
+	buf  []byte
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{dict: make(map[string]uint64)}
+}
+
+// Encode serializes the given metrics. The returned buffer is only valid until
+// the next call.
+func (e *Encoder) Encode(metrics []telegraf.Metric) []byte {
+	e.buf = binary.AppendUvarint(e.buf[:0], uint64(len(metrics)))
+	for _, m := range metrics {
+		e.ref(m.Name())
+		e.buf = append(e.buf, byte(m.Type()))
+		e.buf = binary.AppendVarint(e.buf, m.Time().UnixNano())
+
+		tags := m.TagList()
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(tags)))
+		for _, tag := range tags {
+			e.ref(tag.Key)
+			e.string(tag.Value)
+		}
+
+		fields := m.FieldList()
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(fields)))
+		for _, field := range fields {
+			e.ref(field.Key)
+			e.value(field.Value)
+		}
+	}
+	return e.buf
+}
+
+func (e *Encoder) ref(s string) {
+	if idx, found := e.dict[s]; found {
+		e.buf = binary.AppendUvarint(e.buf, idx+1)
+		return
+	}
+	e.buf = append(e.buf, 0)
+	e.string(s)
+	if len(e.dict) < dictionaryLimit {
+		e.dict[s] = uint64(len(e.dict))
+	}
+}
+
+func (e *Encoder) string(s string) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *Encoder) value(v interface{}) {
+	switch v := v.(type) {
+	case float64:
+		e.buf = append(e.buf, fieldFloat)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case int64:
+		e.buf = append(e.buf, fieldInt)
+		e.buf = binary.AppendVarint(e.buf, v)
+	case uint64:
+		e.buf = append(e.buf, fieldUint)
+		e.buf = binary.AppendUvarint(e.buf, v)
+	case string:
+		e.buf = append(e.buf, fieldString)
+		e.string(v)
+	case bool:
+		e.buf = append(e.buf, fieldBool)
+		if v {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+	default:
+		// Metrics only contain the above types, anything else is sent as
+		// string to not break the stream
+		e.buf = append(e.buf, fieldString)
+		e.string(fmt.Sprintf("%v", v))
+	}
+}
+
+// Decoder deserializes the metrics encoded by an Encoder
+type Decoder struct {
+	dict []string
+	data []byte
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+// Decode deserializes the metrics of a batch. An error leaves the dictionary
+// in an undefined state, so the connection must be closed.
+func (d *Decoder) Decode(data []byte) ([]telegraf.Metric, error) {
+	d.data = data
+
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// Each metric takes at least five bytes
+	if n > uint64(len(d.data))/5 {
+		return nil, fmt.Errorf("invalid number of metrics %d", n)
+	}
+
+	metrics := make([]telegraf.Metric, 0, n)
+	for range n {
+		m, err := d.metric()
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	if len(d.data) > 0 {
+		return nil, fmt.Errorf("%d bytes of trailing data", len(d.data))
+	}
+	return metrics, nil
+}
+
+func (d *Decoder) metric() (telegraf.Metric, error) {
+	name, err := d.ref()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) < 1 {
+		return nil, errTruncated
+	}
+	vt := telegraf.ValueType(d.data[0])
+	d.data = d.data[1:]
+	ts, err := d.varint()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	tags := make(map[string]string, n)
+	for range n {
+		key, err := d.ref()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+
+	n, err = d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	fields := make(map[string]interface{}, n)
+	for range n {
+		key, err := d.ref()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = value
+	}
+
+	return metric.New(name, tags, fields, time.Unix(0, ts), vt), nil
+}
+
+func (d *Decoder) ref() (string, error) {
+	idx, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if idx > 0 {
+		if idx > uint64(len(d.dict)) {
+			return "", fmt.Errorf("invalid dictionary reference %d", idx)
+		}
+		return d.dict[idx-1], nil
+	}
+
+	s, err := d.string()
+	if err != nil {
+		return "", err
+	}
+	if len(d.dict) < dictionaryLimit {
+		d.dict = append(d.dict, s)
+	}
+	return s, nil
+}
+
+func (d *Decoder) string() (string, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(d.data)) {
+		return "", errTruncated
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s, nil
+}
+
+func (d *Decoder) value() (interface{}, error) {
+	if len(d.data) < 1 {
+		return nil, errTruncated
+	}
+	t := d.data[0]
+	d.data = d.data[1:]
+
+	switch t {
+	case fieldFloat:
+		if len(d.data) < 8 {
+			return nil, errTruncated
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(d.data[:8]))
+		d.data = d.data[8:]
+		return v, nil
+	case fieldInt:
+		return d.varint()
+	case fieldUint:
+		return d.uvarint()
+	case fieldString:
+		return d.string()
+	case fieldBool:
+		if len(d.data) < 1 {
+			return nil, errTruncated
+		}
+		v := d.data[0] != 0
+		d.data = d.data[1:]
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid field type %d", t)
+}
+
+func (d *Decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *Decoder) varint() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
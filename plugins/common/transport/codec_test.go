@@ -0,0 +1,165 @@
+package transport
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestCodecRoundtrip(t *testing.T) {
+	batches := [][]telegraf.Metric{
+		{
+			metric.New(
+				"cpu",
+				map[string]string{"host": "a", "cpu": "cpu0"},
+				map[string]interface{}{
+					"usage_idle":  float64(99.5),
+					"count":       int64(-42),
+					"total":       uint64(1 << 40),
+					"state":       "idle",
+					"online":      true,
+					"empty_value": "",
+				},
+				time.Unix(1700000000, 123456789),
+				telegraf.Gauge,
+			),
+			metric.New("mem", map[string]string{}, map[string]interface{}{"used": int64(1)}, time.Unix(0, 0)),
+		},
+		// Second batch reuses the dictionary entries of the first one
+		{
+			metric.New(
+				"cpu",
+				map[string]string{"host": "b", "cpu": "cpu1"},
+				map[string]interface{}{"usage_idle": float64(12.25), "online": false},
+				time.Unix(1700000010, 0),
+				telegraf.Counter,
+			),
+		},
+		{},
+	}
+
+	encoder := NewEncoder()
+	decoder := NewDecoder()
+	var sizes []int
+	for _, expected := range batches {
+		actual, err := decoder.Decode(encoder.Encode(expected))
+		require.NoError(t, err)
+		testutil.RequireMetricsEqual(t, expected, actual)
+		sizes = append(sizes, len(encoder.buf))
+	}
+
+	// Reencoding the first batch only sends references and tag values
+	data := encoder.Encode(batches[0])
+	require.Less(t, len(data), sizes[0])
+	require.NotContains(t, string(data), "usage_idle")
+	actual, err := decoder.Decode(data)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, batches[0], actual)
+}
+
+func TestCodecDictionaryLimit(t *testing.T) {
+	encoder := NewEncoder()
+	decoder := NewDecoder()
+	metrics := make([]telegraf.Metric, 0, dictionaryLimit+10)
+	for i := range dictionaryLimit + 10 {
+		m := metric.New("m", map[string]string{}, map[string]interface{}{"f" + strconv.Itoa(i): int64(i)}, time.Unix(0, 0))
+		metrics = append(metrics, m)
+	}
+	for range 2 {
+		actual, err := decoder.Decode(encoder.Encode(metrics))
+		require.NoError(t, err)
+		require.Len(t, actual, len(metrics))
+		for i, m := range actual {
+			require.Equal(t, metrics[i].FieldList(), m.FieldList())
+		}
+	}
+	require.Len(t, encoder.dict, dictionaryLimit)
+	require.Len(t, decoder.dict, dictionaryLimit)
+}
+
+func TestCodecInvalid(t *testing.T) {
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	data := NewEncoder().Encode([]telegraf.Metric{m})
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{
+			name: "empty",
+			err:  "unexpected end of data",
+		},
+		{
+			name: "truncated",
+			data: data[:len(data)-3],
+			err:  "unexpected end of data",
+		},
+		{
+			name: "trailing data",
+			data: append(bytes.Clone(data), 0),
+			err:  "1 bytes of trailing data",
+		},
+		{
+			name: "unknown reference",
+			data: []byte{1, 5, 0, 0, 0, 0},
+			err:  "invalid dictionary reference 5",
+		},
+		{
+			name: "too many metrics",
+			data: []byte{100, 0, 0},
+			err:  "invalid number of metrics 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder().Decode(tt.data)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestFrameRoundtrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteHello(&buf))
+	batch := &Batch{Seq: 42, Compression: 2, Data: []byte("data")}
+	require.NoError(t, WriteFrame(&buf, FrameBatch, batch.Marshal()))
+	ack := &Ack{Seq: 42, Status: AckError, Message: "failed"}
+	require.NoError(t, WriteFrame(&buf, FrameAck, ack.Marshal()))
+
+	require.NoError(t, ReadHello(&buf))
+
+	ft, payload, err := ReadFrame(&buf, DefaultMaxFrameSize)
+	require.NoError(t, err)
+	require.Equal(t, FrameBatch, ft)
+	var actualBatch Batch
+	require.NoError(t, actualBatch.Unmarshal(payload))
+	require.Equal(t, *batch, actualBatch)
+
+	ft, payload, err = ReadFrame(&buf, DefaultMaxFrameSize)
+	require.NoError(t, err)
+	require.Equal(t, FrameAck, ft)
+	var actualAck Ack
+	require.NoError(t, actualAck.Unmarshal(payload))
+	require.Equal(t, *ack, actualAck)
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, FrameBatch, make([]byte, 100)))
+	_, _, err := ReadFrame(&buf, 50)
+	require.ErrorContains(t, err, "frame size 101 exceeds maximum of 50 bytes")
+}
+
+func TestHelloInvalid(t *testing.T) {
+	require.ErrorContains(t, ReadHello(bytes.NewBufferString("GET / HTTP/1.1")), "not a telegraf transport connection")
+	require.ErrorContains(t, ReadHello(bytes.NewBufferString("TLGF\x07")), "unsupported protocol version 7")
+}
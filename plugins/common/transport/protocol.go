@@ -0,0 +1,177 @@
+// Package transport implements the protocol used between the 'telegraf'
+// output and input plugins to forward metrics from one agent to another.
+//
+// After connecting, the client sends a hello consisting of the magic bytes and
+// the protocol version which the server echoes if it supports the version.
+// Afterwards, the client sends batches of metrics, each acknowledged by the
+// server. Before the first batch, the client may identify itself by sending
+// the name of the agent. All messages are sent as frames prefixed by their
+// length:
+//
+//	frame: uint32 length | uint8 type | payload
+//	batch: uint64 sequence | uint8 compression | compressed metrics
+//	ack:   uint64 sequence | uint8 status | message
+//	agent: name
+//
+// The encoding of the metrics is described in the Encoder documentation.
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	magic = "TLGF"
+
+	// Version of the protocol
+	Version = 1
+
+	// DefaultMaxFrameSize is the default maximum size of a frame in bytes
+	DefaultMaxFrameSize = 64 * 1024 * 1024
+)
+
+// FrameType identifies the content of a frame
+type FrameType uint8
+
+const (
+	FrameBatch FrameType = 1
+	FrameAck   FrameType = 2
+	FrameAgent FrameType = 3
+)
+
+// AckStatus is the result of processing a batch
+type AckStatus uint8
+
+const (
+	// AckOK is sent if the batch was accepted
+	AckOK AckStatus = 0
+	// AckError is sent if the batch could not be processed and should be
+	// retried
+	AckError AckStatus = 1
+)
+
+// Compression algorithms applied to the metrics of a batch, the value is the
+// content encoding name
+var compressions = []string{"identity", "gzip", "zstd", "snappy", "lz4"}
+
+// CompressionID returns the identifier of the given compression algorithm
+func CompressionID(name string) (uint8, error) {
+	for i, c := range compressions {
+		if c == name {
+			return uint8(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown compression %q", name)
+}
+
+// CompressionName returns the content encoding for the given identifier
+func CompressionName(id uint8) (string, error) {
+	if int(id) >= len(compressions) {
+		return "", fmt.Errorf("unknown compression %d", id)
+	}
+	return compressions[id], nil
+}
+
+// WriteHello sends the magic bytes and the protocol version
+func WriteHello(w io.Writer) error {
+	_, err := w.Write(append([]byte(magic), Version))
+	return err
+}
+
+// ReadHello reads the magic bytes and checks the protocol version
+func ReadHello(r io.Reader) error {
+	var buf [len(magic) + 1]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return err
+	}
+	if !bytes.Equal(buf[:len(magic)], []byte(magic)) {
+		return errors.New("not a telegraf transport connection")
+	}
+	if v := buf[len(magic)]; v != Version {
+		return fmt.Errorf("unsupported protocol version %d", v)
+	}
+	return nil
+}
+
+// WriteFrame sends the given payload as a frame of the given type
+func WriteFrame(w io.Writer, t FrameType, payload []byte) error {
+	buf := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)+1))
+	buf[4] = byte(t)
+	_, err := w.Write(append(buf, payload...))
+	return err
+}
+
+// ReadFrame reads the next frame and returns its type and payload. Frames
+// larger than the given size are rejected.
+func ReadFrame(r io.Reader, maxSize int) (FrameType, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[:4]))
+	if size < 1 {
+		return 0, nil, errors.New("invalid frame size")
+	}
+	if size > maxSize {
+		return 0, nil, fmt.Errorf("frame size %d exceeds maximum of %d bytes", size, maxSize)
+	}
+
+	payload := make([]byte, size-1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return FrameType(header[4]), payload, nil
+}
+
+// Batch is the payload of a batch frame
+type Batch struct {
+	Seq         uint64
+	Compression uint8
+	Data        []byte
+}
+
+func (b *Batch) Marshal() []byte {
+	buf := make([]byte, 9, 9+len(b.Data))
+	binary.BigEndian.PutUint64(buf[:8], b.Seq)
+	buf[8] = b.Compression
+	return append(buf, b.Data...)
+}
+
+func (b *Batch) Unmarshal(payload []byte) error {
+	if len(payload) < 9 {
+		return errors.New("batch too short")
+	}
+	b.Seq = binary.BigEndian.Uint64(payload[:8])
+	b.Compression = payload[8]
+	b.Data = payload[9:]
+	return nil
+}
+
+// Ack is the payload of an acknowledgement frame
+type Ack struct {
+	Seq     uint64
+	Status  AckStatus
+	Message string
+}
+
+func (a *Ack) Marshal() []byte {
+	buf := make([]byte, 9, 9+len(a.Message))
+	binary.BigEndian.PutUint64(buf[:8], a.Seq)
+	buf[8] = byte(a.Status)
+	return append(buf, a.Message...)
+}
+
+func (a *Ack) Unmarshal(payload []byte) error {
+	if len(payload) < 9 {
+		return errors.New("ack too short")
+	}
+	a.Seq = binary.BigEndian.Uint64(payload[:8])
+	a.Status = AckStatus(payload[8])
+	a.Message = string(payload[9:])
+	return nil
+}
@@ -0,0 +1,334 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package airflow
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum page size accepted by the API in the default configuration
+const pageLimit = 100
+
+type Airflow struct {
+	URL        string          `toml:"url"`
+	Username   config.Secret   `toml:"username"`
+	Password   config.Secret   `toml:"password"`
+	Token      config.Secret   `toml:"token"`
+	Lookback   config.Duration `toml:"lookback"`
+	DagInclude []string        `toml:"dag_include"`
+	DagExclude []string        `toml:"dag_exclude"`
+	Log        telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client    *http.Client
+	dagFilter filter.Filter
+}
+
+func (*Airflow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Airflow) Init() error {
+	if a.URL == "" {
+		a.URL = "http://localhost:8080"
+	}
+	if _, err := url.Parse(a.URL); err != nil {
+		return fmt.Errorf("parsing 'url' failed: %w", err)
+	}
+	a.URL = strings.TrimRight(a.URL, "/")
+
+	if !a.Token.Empty() && (!a.Username.Empty() || !a.Password.Empty()) {
+		return errors.New("either 'token' or 'username' and 'password' can be set")
+	}
+	if a.Lookback <= 0 {
+		return errors.New("'lookback' must be positive")
+	}
+
+	var err error
+	if a.dagFilter, err = filter.NewIncludeExcludeFilter(a.DagInclude, a.DagExclude); err != nil {
+		return fmt.Errorf("creating DAG filter failed: %w", err)
+	}
+
+	a.client, err = a.HTTPClientConfig.CreateClient(context.Background(), a.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+
+	return nil
+}
+
+func (a *Airflow) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	if err := a.gatherHealth(acc, now); err != nil {
+		acc.AddError(err)
+	}
+
+	if err := a.gatherDags(acc, now); err != nil {
+		acc.AddError(err)
+	}
+
+	if err := a.gatherTaskInstances(acc, now); err != nil {
+		acc.AddError(err)
+	}
+
+	return nil
+}
+
+func (a *Airflow) gatherHealth(acc telegraf.Accumulator, now time.Time) error {
+	var status healthStatus
+	if err := a.get("/api/v1/health", nil, &status); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"metadatabase_healthy": status.Metadatabase.Status == "healthy",
+		"scheduler_healthy":    status.Scheduler.Status == "healthy",
+	}
+	if status.Scheduler.LatestHeartbeat != nil {
+		fields["scheduler_heartbeat_age_s"] = now.Sub(*status.Scheduler.LatestHeartbeat).Seconds()
+	}
+
+	// Only reported by newer Airflow versions and if the component is enabled
+	if status.Triggerer != nil && status.Triggerer.Status != "" {
+		fields["triggerer_healthy"] = status.Triggerer.Status == "healthy"
+	}
+	if status.DagProcessor != nil && status.DagProcessor.Status != "" {
+		fields["dag_processor_healthy"] = status.DagProcessor.Status == "healthy"
+	}
+
+	acc.AddFields("airflow_health", fields, map[string]string{}, now)
+	return nil
+}
+
+// gatherDags reports the state of every DAG together with the number of
+// queued and running DAG runs as well as the runs finished within the lookback
+func (a *Airflow) gatherDags(acc telegraf.Accumulator, now time.Time) error {
+	dags := make(map[string]*dag)
+	err := a.paginate("/api/v1/dags", nil, func(page []byte) (int, error) {
+		var response dagCollection
+		if err := json.Unmarshal(page, &response); err != nil {
+			return 0, err
+		}
+		for _, d := range response.Dags {
+			if a.dagFilter.Match(d.DagID) {
+				dags[d.DagID] = d
+			}
+		}
+		return len(response.Dags), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	runs := make(map[string]map[string]int, len(dags))
+	countRuns := func(page []byte) (int, error) {
+		var response dagRunCollection
+		if err := json.Unmarshal(page, &response); err != nil {
+			return 0, err
+		}
+		for _, run := range response.DagRuns {
+			if _, found := dags[run.DagID]; !found {
+				continue
+			}
+			if runs[run.DagID] == nil {
+				runs[run.DagID] = make(map[string]int)
+			}
+			runs[run.DagID][run.State]++
+		}
+		return len(response.DagRuns), nil
+	}
+
+	active := url.Values{"state": []string{"queued", "running"}}
+	if err := a.paginate("/api/v1/dags/~/dagRuns", active, countRuns); err != nil {
+		return err
+	}
+	finished := url.Values{
+		"state":        []string{"success", "failed"},
+		"end_date_gte": []string{now.Add(-time.Duration(a.Lookback)).UTC().Format(time.RFC3339)},
+	}
+	if err := a.paginate("/api/v1/dags/~/dagRuns", finished, countRuns); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(dags))
+	for id := range dags {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		d := dags[id]
+		fields := map[string]interface{}{
+			"is_paused":    d.IsPaused,
+			"is_active":    d.IsActive,
+			"runs_queued":  runs[id]["queued"],
+			"runs_running": runs[id]["running"],
+			"runs_success": runs[id]["success"],
+			"runs_failed":  runs[id]["failed"],
+		}
+		acc.AddFields("airflow_dag", fields, map[string]string{"dag_id": id}, now)
+	}
+
+	return nil
+}
+
+// gatherTaskInstances counts the task instances per DAG and state updated
+// within the lookback
+func (a *Airflow) gatherTaskInstances(acc telegraf.Accumulator, now time.Time) error {
+	type key struct {
+		dagID, state string
+	}
+	counts := make(map[key]int)
+
+	params := url.Values{
+		"updated_at_gte": []string{now.Add(-time.Duration(a.Lookback)).UTC().Format(time.RFC3339)},
+	}
+	err := a.paginate("/api/v1/dags/~/dagRuns/~/taskInstances", params, func(page []byte) (int, error) {
+		var response taskInstanceCollection
+		if err := json.Unmarshal(page, &response); err != nil {
+			return 0, err
+		}
+		for _, ti := range response.TaskInstances {
+			if !a.dagFilter.Match(ti.DagID) {
+				continue
+			}
+			// Task instances without a state have not been scheduled yet
+			state := ti.State
+			if state == "" {
+				state = "none"
+			}
+			counts[key{ti.DagID, state}]++
+		}
+		return len(response.TaskInstances), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].dagID != keys[j].dagID {
+			return keys[i].dagID < keys[j].dagID
+		}
+		return keys[i].state < keys[j].state
+	})
+
+	for _, k := range keys {
+		tags := map[string]string{
+			"dag_id": k.dagID,
+			"state":  k.state,
+		}
+		acc.AddFields("airflow_task_instances", map[string]interface{}{"count": counts[k]}, tags, now)
+	}
+
+	return nil
+}
+
+// paginate requests all pages of a collection endpoint, the handler returns
+// the number of entries of a page
+func (a *Airflow) paginate(path string, params url.Values, handler func([]byte) (int, error)) error {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("limit", strconv.Itoa(pageLimit))
+
+	for offset := 0; ; offset += pageLimit {
+		query.Set("offset", strconv.Itoa(offset))
+
+		var page json.RawMessage
+		if err := a.get(path, query, &page); err != nil {
+			return err
+		}
+		n, err := handler(page)
+		if err != nil {
+			return fmt.Errorf("parsing response of %q failed: %w", path, err)
+		}
+		if n < pageLimit {
+			return nil
+		}
+	}
+}
+
+func (a *Airflow) get(path string, query url.Values, v interface{}) error {
+	address := a.URL + path
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if !a.Token.Empty() {
+		token, err := a.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	} else if !a.Username.Empty() {
+		username, err := a.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := a.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting %q failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting %q returned HTTP status %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %q failed: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("airflow", func() telegraf.Input {
+		return &Airflow{
+			Lookback: config.Duration(time.Hour),
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
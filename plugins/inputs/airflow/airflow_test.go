@@ -0,0 +1,201 @@
+package airflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var fn string
+		switch r.URL.Path {
+		case "/api/v1/health":
+			fn = "testdata/health.json"
+		case "/api/v1/dags":
+			fn = "testdata/dags.json"
+		case "/api/v1/dags/~/dagRuns":
+			fn = "testdata/dag_runs_active.json"
+			if r.URL.Query().Get("end_date_gte") != "" {
+				fn = "testdata/dag_runs_finished.json"
+			}
+		case "/api/v1/dags/~/dagRuns/~/taskInstances":
+			if _, err := time.Parse(time.RFC3339, r.URL.Query().Get("updated_at_gte")); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fn = "testdata/task_instances.json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		response, err := os.ReadFile(fn)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Airflow{
+		URL:        server.URL,
+		Username:   config.NewSecret([]byte("admin")),
+		Password:   config.NewSecret([]byte("secret")),
+		Lookback:   config.Duration(time.Hour),
+		DagExclude: []string{"tmp_*"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"airflow_health",
+			map[string]string{},
+			map[string]interface{}{
+				"metadatabase_healthy":  true,
+				"scheduler_healthy":     true,
+				"dag_processor_healthy": false,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_dag",
+			map[string]string{"dag_id": "etl_orders"},
+			map[string]interface{}{
+				"is_paused":    false,
+				"is_active":    true,
+				"runs_queued":  1,
+				"runs_running": 1,
+				"runs_success": 2,
+				"runs_failed":  1,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_dag",
+			map[string]string{"dag_id": "report_weekly"},
+			map[string]interface{}{
+				"is_paused":    true,
+				"is_active":    true,
+				"runs_queued":  0,
+				"runs_running": 0,
+				"runs_success": 0,
+				"runs_failed":  0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_task_instances",
+			map[string]string{"dag_id": "etl_orders", "state": "none"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_task_instances",
+			map[string]string{"dag_id": "etl_orders", "state": "running"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_task_instances",
+			map[string]string{"dag_id": "etl_orders", "state": "success"},
+			map[string]interface{}{"count": 2},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"airflow_task_instances",
+			map[string]string{"dag_id": "etl_orders", "state": "up_for_retry"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.IgnoreFields("scheduler_heartbeat_age_s"))
+
+	// The heartbeat age depends on the current time
+	age, found := actual[0].GetField("scheduler_heartbeat_age_s")
+	require.True(t, found)
+	require.Greater(t, age, float64(0))
+}
+
+func TestPagination(t *testing.T) {
+	const total = 2*pageLimit + 17
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response := dagCollection{TotalEntries: total}
+		for i := offset; i < min(offset+limit, total); i++ {
+			response.Dags = append(response.Dags, &dag{DagID: fmt.Sprintf("dag_%03d", i), IsActive: true})
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Airflow{
+		URL:      server.URL,
+		Lookback: config.Duration(time.Hour),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var dags int
+	require.NoError(t, plugin.paginate("/api/v1/dags", nil, func(page []byte) (int, error) {
+		var response dagCollection
+		if err := json.Unmarshal(page, &response); err != nil {
+			return 0, err
+		}
+		dags += len(response.Dags)
+		return len(response.Dags), nil
+	}))
+	require.Equal(t, total, dags)
+	require.Equal(t, 3, requests)
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Airflow{
+		Username: config.NewSecret([]byte("admin")),
+		Token:    config.NewSecret([]byte("token")),
+		Lookback: config.Duration(time.Hour),
+	}
+	require.ErrorContains(t, plugin.Init(), "either 'token' or 'username' and 'password'")
+}
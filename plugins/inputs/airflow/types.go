@@ -0,0 +1,49 @@
+package airflow
+
+import "time"
+
+type componentStatus struct {
+	Status string `json:"status"`
+}
+
+type healthStatus struct {
+	Metadatabase componentStatus `json:"metadatabase"`
+	Scheduler    struct {
+		Status          string     `json:"status"`
+		LatestHeartbeat *time.Time `json:"latest_scheduler_heartbeat"`
+	} `json:"scheduler"`
+	Triggerer    *componentStatus `json:"triggerer"`
+	DagProcessor *componentStatus `json:"dag_processor"`
+}
+
+type dag struct {
+	DagID    string `json:"dag_id"`
+	IsPaused bool   `json:"is_paused"`
+	IsActive bool   `json:"is_active"`
+}
+
+type dagCollection struct {
+	Dags         []*dag `json:"dags"`
+	TotalEntries int    `json:"total_entries"`
+}
+
+type dagRun struct {
+	DagID string `json:"dag_id"`
+	State string `json:"state"`
+}
+
+type dagRunCollection struct {
+	DagRuns      []dagRun `json:"dag_runs"`
+	TotalEntries int      `json:"total_entries"`
+}
+
+type taskInstance struct {
+	DagID  string `json:"dag_id"`
+	TaskID string `json:"task_id"`
+	State  string `json:"state"`
+}
+
+type taskInstanceCollection struct {
+	TaskInstances []taskInstance `json:"task_instances"`
+	TotalEntries  int            `json:"total_entries"`
+}
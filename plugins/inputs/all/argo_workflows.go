@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.argo_workflows
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/argo_workflows" // register plugin
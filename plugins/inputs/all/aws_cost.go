@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.aws_cost
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/aws_cost" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.beegfs
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/beegfs" // register plugin
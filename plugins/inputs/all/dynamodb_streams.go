@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.dynamodb_streams
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/dynamodb_streams" // register plugin
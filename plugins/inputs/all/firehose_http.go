@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.firehose_http
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/firehose_http" // register plugin
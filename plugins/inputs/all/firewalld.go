@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.firewalld
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/firewalld" // register plugin
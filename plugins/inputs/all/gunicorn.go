@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.gunicorn
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/gunicorn" // register plugin
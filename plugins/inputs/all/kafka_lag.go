@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.kafka_lag
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/kafka_lag" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.linux_security
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/linux_security" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.macos_log
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/macos_log" // register plugin
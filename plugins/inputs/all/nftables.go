@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.nftables
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/nftables" // register plugin
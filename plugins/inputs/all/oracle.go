@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.oracle
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/oracle" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.pbs
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/pbs" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.powermetrics
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/powermetrics" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.process_flows
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/process_flows" // register plugin
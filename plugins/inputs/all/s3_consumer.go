@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.s3_consumer
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/s3_consumer" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.sqs_consumer
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/sqs_consumer" // register plugin
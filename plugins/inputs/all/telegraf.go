@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.telegraf
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/telegraf" // register plugin
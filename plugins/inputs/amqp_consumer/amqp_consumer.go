@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	common_consumer "github.com/influxdata/telegraf/plugins/common/consumer"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -53,14 +54,18 @@ type AMQPConsumer struct {
 	Timeout                config.Duration   `toml:"timeout"`
 	Log                    telegraf.Logger   `toml:"-"`
 	tls.ClientConfig
+	common_consumer.ErrorPolicyConfig
+	common_consumer.UnwrapConfig
 
 	deliveries map[telegraf.TrackingID]amqp.Delivery
 
-	parser  telegraf.Parser
-	conn    *amqp.Connection
-	wg      *sync.WaitGroup
-	cancel  context.CancelFunc
-	decoder internal.ContentDecoder
+	parser    telegraf.Parser
+	policy    *common_consumer.ErrorPolicy
+	unwrapper *common_consumer.Unwrapper
+	conn      *amqp.Connection
+	wg        *sync.WaitGroup
+	cancel    context.CancelFunc
+	decoder   internal.ContentDecoder
 }
 
 func (a *externalAuth) Mechanism() string {
@@ -108,6 +113,18 @@ func (a *AMQPConsumer) Init() error {
 		a.MaxUndeliveredMessages = 1000
 	}
 
+	policy, err := common_consumer.NewErrorPolicy(a.ErrorPolicyConfig, map[string]string{"input": "amqp_consumer"}, a.Log)
+	if err != nil {
+		return err
+	}
+	a.policy = policy
+
+	unwrapper, err := common_consumer.NewUnwrapper(a.UnwrapConfig)
+	if err != nil {
+		return err
+	}
+	a.unwrapper = unwrapper
+
 	return nil
 }
 
@@ -179,6 +196,12 @@ func (a *AMQPConsumer) Gather(_ telegraf.Accumulator) error {
 }
 
 func (a *AMQPConsumer) Stop() {
+	defer func() {
+		if err := a.policy.Close(); err != nil {
+			a.Log.Errorf("Closing dead-letter file failed: %v", err)
+		}
+	}()
+
 	// We did not connect successfully so there is nothing to do here.
 	if a.conn == nil || a.conn.IsClosed() {
 		return
@@ -426,6 +449,13 @@ func (a *AMQPConsumer) process(ctx context.Context, msgs <-chan amqp.Delivery, a
 				if err != nil {
 					acc.AddError(err)
 					<-sem
+					if errors.Is(err, common_consumer.ErrHalt) {
+						// Closing the connection returns all unacknowledged
+						// messages to the queue
+						a.Log.Error("Stopped consuming due to parse error, restart to resume")
+						a.conn.Close()
+						return
+					}
 				}
 			}
 		}
@@ -448,8 +478,13 @@ func (a *AMQPConsumer) onMessage(acc telegraf.TrackingAccumulator, d amqp.Delive
 		return err
 	}
 
-	metrics, err := a.parser.Parse(body)
+	metrics, err := a.policy.Parse(d.RoutingKey, body, func() ([]telegraf.Metric, error) {
+		return a.unwrapper.Parse(a.parser, body)
+	})
 	if err != nil {
+		if errors.Is(err, common_consumer.ErrHalt) {
+			return err
+		}
 		onError()
 		return err
 	}
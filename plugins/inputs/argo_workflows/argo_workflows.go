@@ -0,0 +1,268 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package argo_workflows
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const defaultServiceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+var workflowResource = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "workflows",
+}
+
+// Labels set by the workflow controller on workflows created from templates
+var templateLabels = []string{
+	"workflows.argoproj.io/cron-workflow",
+	"workflows.argoproj.io/workflow-template",
+	"workflows.argoproj.io/cluster-workflow-template",
+}
+
+type ArgoWorkflows struct {
+	URL             string          `toml:"url"`
+	BearerToken     string          `toml:"bearer_token"`
+	Namespace       string          `toml:"namespace"`
+	LabelSelector   string          `toml:"label_selector"`
+	Lookback        config.Duration `toml:"lookback"`
+	ResponseTimeout config.Duration `toml:"response_timeout"`
+	Log             telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	client dynamic.Interface
+	// Workflows finished before the cutoff were already reported
+	cutoff time.Time
+}
+
+func (*ArgoWorkflows) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *ArgoWorkflows) Init() error {
+	if a.client != nil {
+		return nil
+	}
+
+	var restConfig *rest.Config
+	if a.URL == "" {
+		cfg, err := rest.InClusterConfig()
+		if err != nil {
+			return fmt.Errorf("getting in-cluster config failed: %w", err)
+		}
+		restConfig = cfg
+	} else {
+		if a.BearerToken == "" {
+			a.BearerToken = defaultServiceAccountPath
+		}
+		restConfig = &rest.Config{
+			Host: a.URL,
+			TLSClientConfig: rest.TLSClientConfig{
+				ServerName: a.ServerName,
+				Insecure:   a.InsecureSkipVerify,
+				CAFile:     a.TLSCA,
+				CertFile:   a.TLSCert,
+				KeyFile:    a.TLSKey,
+			},
+			BearerTokenFile: a.BearerToken,
+		}
+	}
+	restConfig.Timeout = time.Duration(a.ResponseTimeout)
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	a.client = client
+
+	return nil
+}
+
+func (a *ArgoWorkflows) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+	if a.cutoff.IsZero() {
+		a.cutoff = now.Add(-time.Duration(a.Lookback))
+	}
+
+	workflows, err := a.list()
+	if err != nil {
+		return err
+	}
+
+	type phaseKey struct {
+		namespace, phase string
+	}
+	phases := make(map[phaseKey]int)
+
+	cutoff := a.cutoff
+	for _, wf := range workflows {
+		w := parseWorkflow(&wf)
+		phases[phaseKey{w.namespace, w.phase}]++
+
+		switch {
+		case w.finished.IsZero() && !w.started.IsZero():
+			// Running workflows are reported on every collection with the
+			// time elapsed so far
+			a.addWorkflow(acc, w, now.Sub(w.started), now)
+		case !w.finished.IsZero() && w.finished.After(cutoff):
+			// Finished workflows are only reported once
+			a.addWorkflow(acc, w, w.finished.Sub(w.started), w.finished)
+			if w.finished.After(a.cutoff) {
+				a.cutoff = w.finished
+			}
+		}
+	}
+
+	keys := make([]phaseKey, 0, len(phases))
+	for k := range phases {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].phase < keys[j].phase
+	})
+	for _, k := range keys {
+		tags := map[string]string{
+			"namespace": k.namespace,
+			"phase":     k.phase,
+		}
+		acc.AddGauge("argo_workflows", map[string]interface{}{"count": phases[k]}, tags, now)
+	}
+
+	return nil
+}
+
+func (a *ArgoWorkflows) list() ([]unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.ResponseTimeout))
+	defer cancel()
+
+	var workflows []unstructured.Unstructured
+	options := metav1.ListOptions{
+		LabelSelector: a.LabelSelector,
+		Limit:         500,
+	}
+	for {
+		list, err := a.client.Resource(workflowResource).Namespace(a.Namespace).List(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("listing workflows failed: %w", err)
+		}
+		workflows = append(workflows, list.Items...)
+		if list.GetContinue() == "" {
+			return workflows, nil
+		}
+		options.Continue = list.GetContinue()
+	}
+}
+
+func (*ArgoWorkflows) addWorkflow(acc telegraf.Accumulator, w *workflow, duration time.Duration, ts time.Time) {
+	tags := map[string]string{
+		"namespace": w.namespace,
+		"template":  w.template,
+		"phase":     w.phase,
+	}
+	fields := map[string]interface{}{
+		"name":       w.name,
+		"duration_s": duration.Seconds(),
+	}
+	if w.estimated > 0 {
+		fields["estimated_duration_s"] = w.estimated.Seconds()
+	}
+	if w.progressTotal > 0 {
+		fields["nodes_completed"] = w.progressDone
+		fields["nodes_total"] = w.progressTotal
+	}
+	acc.AddFields("argo_workflow", fields, tags, ts)
+}
+
+type workflow struct {
+	name          string
+	namespace     string
+	template      string
+	phase         string
+	started       time.Time
+	finished      time.Time
+	estimated     time.Duration
+	progressDone  int64
+	progressTotal int64
+}
+
+func parseWorkflow(obj *unstructured.Unstructured) *workflow {
+	w := &workflow{
+		name:      obj.GetName(),
+		namespace: obj.GetNamespace(),
+	}
+
+	// Group workflows created from the same template, cron workflow or
+	// generate-name prefix to limit the series cardinality
+	labels := obj.GetLabels()
+	for _, label := range templateLabels {
+		if v := labels[label]; v != "" {
+			w.template = v
+			break
+		}
+	}
+	if w.template == "" {
+		if ref, found, _ := unstructured.NestedString(obj.Object, "spec", "workflowTemplateRef", "name"); found && ref != "" {
+			w.template = ref
+		} else if prefix := strings.TrimRight(obj.GetGenerateName(), "-"); prefix != "" {
+			w.template = prefix
+		} else {
+			w.template = w.name
+		}
+	}
+
+	status, _, _ := unstructured.NestedMap(obj.Object, "status")
+	w.phase, _, _ = unstructured.NestedString(status, "phase")
+	if w.phase == "" {
+		// Workflows not yet picked up by the controller
+		w.phase = "Pending"
+	}
+	if v, _, _ := unstructured.NestedString(status, "startedAt"); v != "" {
+		w.started, _ = time.Parse(time.RFC3339, v)
+	}
+	if v, _, _ := unstructured.NestedString(status, "finishedAt"); v != "" {
+		w.finished, _ = time.Parse(time.RFC3339, v)
+	}
+	if v, found, _ := unstructured.NestedInt64(status, "estimatedDuration"); found {
+		w.estimated = time.Duration(v) * time.Second
+	}
+	if v, _, _ := unstructured.NestedString(status, "progress"); v != "" {
+		if done, total, found := strings.Cut(v, "/"); found {
+			w.progressDone, _ = strconv.ParseInt(done, 10, 64)
+			w.progressTotal, _ = strconv.ParseInt(total, 10, 64)
+		}
+	}
+
+	return w
+}
+
+func init() {
+	inputs.Add("argo_workflows", func() telegraf.Input {
+		return &ArgoWorkflows{
+			Lookback:        config.Duration(time.Hour),
+			ResponseTimeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package argo_workflows
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newWorkflow(namespace, name string, labels map[string]interface{}, status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Workflow",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+				"labels":    labels,
+			},
+			"status": status,
+		},
+	}
+}
+
+func TestGather(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ts := func(d time.Duration) string {
+		return now.Add(d).Format(time.RFC3339)
+	}
+
+	running := newWorkflow("data", "nightly-etl-1729123200", map[string]interface{}{
+		"workflows.argoproj.io/cron-workflow": "nightly-etl",
+	}, map[string]interface{}{
+		"phase":             "Running",
+		"startedAt":         ts(-10 * time.Minute),
+		"finishedAt":        nil,
+		"estimatedDuration": int64(900),
+		"progress":          "3/5",
+	})
+	succeeded := newWorkflow("data", "nightly-etl-1729036800", map[string]interface{}{
+		"workflows.argoproj.io/cron-workflow": "nightly-etl",
+	}, map[string]interface{}{
+		"phase":      "Succeeded",
+		"startedAt":  ts(-40 * time.Minute),
+		"finishedAt": ts(-25 * time.Minute),
+		"progress":   "5/5",
+	})
+	failed := newWorkflow("ml", "train-x7k2p", nil, map[string]interface{}{
+		"phase":      "Failed",
+		"startedAt":  ts(-3 * time.Hour),
+		"finishedAt": ts(-2 * time.Hour),
+		"progress":   "1/4",
+	})
+	failed.SetGenerateName("train-")
+	pending := newWorkflow("ml", "train-q9w4z", nil, nil)
+	pending.SetGenerateName("train-")
+
+	scheme := runtime.NewScheme()
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{workflowResource: "WorkflowList"},
+		running, succeeded, failed, pending,
+	)
+
+	plugin := &ArgoWorkflows{
+		Lookback:        config.Duration(time.Hour),
+		ResponseTimeout: config.Duration(time.Second),
+		Log:             testutil.Logger{},
+		client:          client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"argo_workflow",
+			map[string]string{"namespace": "data", "template": "nightly-etl", "phase": "Running"},
+			map[string]interface{}{
+				"name":                 "nightly-etl-1729123200",
+				"estimated_duration_s": float64(900),
+				"nodes_completed":      int64(3),
+				"nodes_total":          int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"argo_workflow",
+			map[string]string{"namespace": "data", "template": "nightly-etl", "phase": "Succeeded"},
+			map[string]interface{}{
+				"name":            "nightly-etl-1729036800",
+				"duration_s":      float64(900),
+				"nodes_completed": int64(5),
+				"nodes_total":     int64(5),
+			},
+			now.Add(-25*time.Minute),
+		),
+		metric.New(
+			"argo_workflows",
+			map[string]string{"namespace": "data", "phase": "Running"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"argo_workflows",
+			map[string]string{"namespace": "data", "phase": "Succeeded"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"argo_workflows",
+			map[string]string{"namespace": "ml", "phase": "Failed"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"argo_workflows",
+			map[string]string{"namespace": "ml", "phase": "Pending"},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics(), testutil.IgnoreFields("duration_s"))
+
+	// Finished workflows are timestamped with their end time and only reported once
+	for _, m := range actual {
+		if m.Name() != "argo_workflow" {
+			continue
+		}
+		duration, found := m.GetField("duration_s")
+		require.True(t, found)
+		if phase, _ := m.GetTag("phase"); phase == "Succeeded" {
+			require.Equal(t, now.Add(-25*time.Minute), m.Time())
+			require.InDelta(t, float64(900), duration, 0)
+		} else {
+			require.GreaterOrEqual(t, duration, float64(600))
+		}
+	}
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	var reported int
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "argo_workflow" {
+			reported++
+			phase, _ := m.GetTag("phase")
+			require.Equal(t, "Running", phase)
+		}
+	}
+	require.Equal(t, 1, reported)
+}
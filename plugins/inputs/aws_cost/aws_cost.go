@@ -0,0 +1,281 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package aws_cost
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits set by AWS, see
+// https://docs.aws.amazon.com/aws-cost-management/latest/APIReference/API_GetCostAndUsage.html
+const (
+	maxGroupBy       = 2
+	maxHourlyHistory = 14 * 24 * time.Hour
+)
+
+// Metrics not measured in a currency
+var usageMetrics = map[string]bool{
+	"UsageQuantity":         true,
+	"NormalizedUsageAmount": true,
+}
+
+type AWSCost struct {
+	Granularity string          `toml:"granularity"`
+	Lookback    config.Duration `toml:"lookback"`
+	CostMetrics []string        `toml:"cost_metrics"`
+	GroupBy     []string        `toml:"group_by"`
+	Log         telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client      costExplorerClient
+	granularity types.Granularity
+	groups      []types.GroupDefinition
+	tagNames    []string
+	now         func() time.Time
+}
+
+// costExplorerClient contains the Cost Explorer API used, implemented by
+// costexplorer.Client
+type costExplorerClient interface {
+	GetCostAndUsage(
+		ctx context.Context,
+		params *costexplorer.GetCostAndUsageInput,
+		optFns ...func(*costexplorer.Options),
+	) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+func (*AWSCost) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AWSCost) Init() error {
+	switch a.Granularity {
+	case "", "daily":
+		a.granularity = types.GranularityDaily
+		if a.Lookback == 0 {
+			a.Lookback = config.Duration(72 * time.Hour)
+		}
+	case "hourly":
+		a.granularity = types.GranularityHourly
+		if a.Lookback == 0 {
+			a.Lookback = config.Duration(6 * time.Hour)
+		}
+		if time.Duration(a.Lookback) > maxHourlyHistory {
+			return errors.New("'lookback' must not exceed 14 days for hourly granularity")
+		}
+	case "monthly":
+		a.granularity = types.GranularityMonthly
+		if a.Lookback == 0 {
+			a.Lookback = config.Duration(24 * time.Hour)
+		}
+	default:
+		return fmt.Errorf("invalid 'granularity' %q", a.Granularity)
+	}
+	if a.Lookback < 0 {
+		return errors.New("'lookback' must not be negative")
+	}
+
+	if len(a.CostMetrics) == 0 {
+		a.CostMetrics = []string{"UnblendedCost"}
+	}
+
+	if len(a.GroupBy) > maxGroupBy {
+		return fmt.Errorf("at most %d 'group_by' entries are supported", maxGroupBy)
+	}
+	a.groups = make([]types.GroupDefinition, 0, len(a.GroupBy))
+	a.tagNames = make([]string, 0, len(a.GroupBy))
+	for _, g := range a.GroupBy {
+		kind, key, found := strings.Cut(g, ":")
+		if !found {
+			kind, key = "dimension", g
+		}
+		if key == "" {
+			return fmt.Errorf("invalid 'group_by' entry %q", g)
+		}
+
+		var group types.GroupDefinition
+		switch kind {
+		case "dimension":
+			group = types.GroupDefinition{Type: types.GroupDefinitionTypeDimension, Key: aws.String(key)}
+			a.tagNames = append(a.tagNames, internal.SnakeCase(key))
+		case "tag":
+			group = types.GroupDefinition{Type: types.GroupDefinitionTypeTag, Key: aws.String(key)}
+			a.tagNames = append(a.tagNames, "tag_"+key)
+		case "cost_category":
+			group = types.GroupDefinition{Type: types.GroupDefinitionTypeCostCategory, Key: aws.String(key)}
+			a.tagNames = append(a.tagNames, "cost_category_"+key)
+		default:
+			return fmt.Errorf("invalid 'group_by' type %q", kind)
+		}
+		a.groups = append(a.groups, group)
+	}
+
+	// Cost Explorer is only available in the us-east-1 region
+	if a.Region == "" {
+		a.Region = "us-east-1"
+	}
+
+	if a.now == nil {
+		a.now = time.Now
+	}
+
+	return nil
+}
+
+func (a *AWSCost) Gather(acc telegraf.Accumulator) error {
+	if a.client == nil {
+		httpClient, err := a.ClientConfig.CreateClient()
+		if err != nil {
+			return err
+		}
+		a.CredentialConfig.HTTPClient = httpClient
+
+		cfg, err := a.CredentialConfig.Credentials()
+		if err != nil {
+			return err
+		}
+		a.client = costexplorer.NewFromConfig(cfg, func(o *costexplorer.Options) {
+			if a.EndpointURL != "" {
+				o.BaseEndpoint = &a.EndpointURL
+			}
+		})
+	}
+
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: a.granularity,
+		Metrics:     a.CostMetrics,
+		TimePeriod:  a.timePeriod(),
+		GroupBy:     a.groups,
+	}
+	for {
+		out, err := a.client.GetCostAndUsage(context.Background(), input)
+		if err != nil {
+			return fmt.Errorf("getting cost and usage failed: %w", err)
+		}
+		for _, result := range out.ResultsByTime {
+			a.addResult(acc, result)
+		}
+
+		if out.NextPageToken == nil || *out.NextPageToken == "" {
+			return nil
+		}
+		input.NextPageToken = out.NextPageToken
+	}
+}
+
+// timePeriod returns the period covering the lookback aligned to the
+// granularity. The end is exclusive and includes the current, incomplete
+// period to report estimated costs.
+func (a *AWSCost) timePeriod() *types.DateInterval {
+	now := a.now().UTC()
+	start := now.Add(-time.Duration(a.Lookback))
+
+	switch a.granularity {
+	case types.GranularityHourly:
+		start = start.Truncate(time.Hour)
+		end := now.Truncate(time.Hour).Add(time.Hour)
+		return &types.DateInterval{
+			Start: aws.String(start.Format(time.RFC3339)),
+			End:   aws.String(end.Format(time.RFC3339)),
+		}
+	case types.GranularityMonthly:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	end := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return &types.DateInterval{
+		Start: aws.String(start.Format(time.DateOnly)),
+		End:   aws.String(end.Format(time.DateOnly)),
+	}
+}
+
+func (a *AWSCost) addResult(acc telegraf.Accumulator, result types.ResultByTime) {
+	if result.TimePeriod == nil {
+		return
+	}
+	ts, err := parsePeriod(aws.ToString(result.TimePeriod.Start))
+	if err != nil {
+		acc.AddError(fmt.Errorf("parsing start of period failed: %w", err))
+		return
+	}
+
+	// Without grouping only the total is reported
+	if len(a.groups) == 0 {
+		a.addMetric(acc, nil, result.Total, result.Estimated, ts)
+		return
+	}
+	for _, group := range result.Groups {
+		a.addMetric(acc, group.Keys, group.Metrics, result.Estimated, ts)
+	}
+}
+
+func (a *AWSCost) addMetric(acc telegraf.Accumulator, keys []string, values map[string]types.MetricValue, estimated bool, ts time.Time) {
+	tags := make(map[string]string, len(keys)+2)
+	tags["granularity"] = strings.ToLower(string(a.granularity))
+	for i, key := range keys {
+		if i >= len(a.tagNames) {
+			break
+		}
+		// Tag and cost category keys are prefixed with their name, untagged
+		// resources are reported without the tag
+		if a.groups[i].Type != types.GroupDefinitionTypeDimension {
+			_, key, _ = strings.Cut(key, "$")
+		}
+		if key != "" {
+			tags[a.tagNames[i]] = key
+		}
+	}
+
+	fields := make(map[string]interface{}, len(values)+1)
+	for name, value := range values {
+		amount, err := strconv.ParseFloat(aws.ToString(value.Amount), 64)
+		if err != nil {
+			a.Log.Errorf("Parsing amount %q of %q failed: %v", aws.ToString(value.Amount), name, err)
+			continue
+		}
+		fields[internal.SnakeCase(name)] = amount
+		if !usageMetrics[name] && value.Unit != nil {
+			tags["currency"] = *value.Unit
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	fields["estimated"] = estimated
+
+	acc.AddFields("aws_cost", fields, tags, ts)
+}
+
+func parsePeriod(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func init() {
+	inputs.Add("aws_cost", func() telegraf.Input {
+		return &AWSCost{}
+	})
+}
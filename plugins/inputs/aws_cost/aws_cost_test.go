@@ -0,0 +1,275 @@
+package aws_cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockClient serves the pages in order and records the requests
+type mockClient struct {
+	pages    []*costexplorer.GetCostAndUsageOutput
+	requests []*costexplorer.GetCostAndUsageInput
+}
+
+func (c *mockClient) GetCostAndUsage(
+	_ context.Context,
+	params *costexplorer.GetCostAndUsageInput,
+	_ ...func(*costexplorer.Options),
+) (*costexplorer.GetCostAndUsageOutput, error) {
+	request := *params
+	c.requests = append(c.requests, &request)
+	page := c.pages[0]
+	c.pages = c.pages[1:]
+	return page, nil
+}
+
+func cost(amount string) types.MetricValue {
+	return types.MetricValue{Amount: aws.String(amount), Unit: aws.String("USD")}
+}
+
+func period(start, end string) *types.DateInterval {
+	return &types.DateInterval{Start: aws.String(start), End: aws.String(end)}
+}
+
+func fixedNow() time.Time {
+	return time.Date(2024, 6, 14, 10, 25, 0, 0, time.UTC)
+}
+
+func TestGatherTotal(t *testing.T) {
+	client := &mockClient{
+		pages: []*costexplorer.GetCostAndUsageOutput{
+			{
+				ResultsByTime: []types.ResultByTime{
+					{
+						TimePeriod: period("2024-06-13", "2024-06-14"),
+						Total: map[string]types.MetricValue{
+							"UnblendedCost": cost("12.5"),
+							"UsageQuantity": {Amount: aws.String("42"), Unit: aws.String("N/A")},
+						},
+					},
+					{
+						TimePeriod: period("2024-06-14", "2024-06-15"),
+						Total: map[string]types.MetricValue{
+							"UnblendedCost": cost("3.25"),
+							"UsageQuantity": {Amount: aws.String("7"), Unit: aws.String("N/A")},
+						},
+						Estimated: true,
+					},
+				},
+			},
+		},
+	}
+
+	plugin := &AWSCost{
+		Lookback:    config.Duration(24 * time.Hour),
+		CostMetrics: []string{"UnblendedCost", "UsageQuantity"},
+		Log:         testutil.Logger{},
+		client:      client,
+		now:         fixedNow,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"aws_cost",
+			map[string]string{"granularity": "daily", "currency": "USD"},
+			map[string]interface{}{"unblended_cost": 12.5, "usage_quantity": 42.0, "estimated": false},
+			time.Date(2024, 6, 13, 0, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"aws_cost",
+			map[string]string{"granularity": "daily", "currency": "USD"},
+			map[string]interface{}{"unblended_cost": 3.25, "usage_quantity": 7.0, "estimated": true},
+			time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	require.Len(t, client.requests, 1)
+	require.Equal(t, types.GranularityDaily, client.requests[0].Granularity)
+	require.Equal(t, period("2024-06-13", "2024-06-15"), client.requests[0].TimePeriod)
+	require.Empty(t, client.requests[0].GroupBy)
+}
+
+func TestGatherGrouped(t *testing.T) {
+	client := &mockClient{
+		pages: []*costexplorer.GetCostAndUsageOutput{
+			{
+				ResultsByTime: []types.ResultByTime{
+					{
+						TimePeriod: period("2024-06-14T09:00:00Z", "2024-06-14T10:00:00Z"),
+						Groups: []types.Group{
+							{
+								Keys:    []string{"AWS Lambda", "team$backend"},
+								Metrics: map[string]types.MetricValue{"UnblendedCost": cost("0.42")},
+							},
+							{
+								Keys:    []string{"Amazon Simple Storage Service", "team$"},
+								Metrics: map[string]types.MetricValue{"UnblendedCost": cost("1.5")},
+							},
+						},
+						Estimated: true,
+					},
+				},
+				NextPageToken: aws.String("next"),
+			},
+			{
+				ResultsByTime: []types.ResultByTime{
+					{
+						TimePeriod: period("2024-06-14T10:00:00Z", "2024-06-14T11:00:00Z"),
+						Groups: []types.Group{
+							{
+								Keys:    []string{"AWS Lambda", "team$backend"},
+								Metrics: map[string]types.MetricValue{"UnblendedCost": cost("0.1")},
+							},
+						},
+						Estimated: true,
+					},
+				},
+			},
+		},
+	}
+
+	plugin := &AWSCost{
+		Granularity: "hourly",
+		Lookback:    config.Duration(time.Hour),
+		GroupBy:     []string{"SERVICE", "tag:team"},
+		Log:         testutil.Logger{},
+		client:      client,
+		now:         fixedNow,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"aws_cost",
+			map[string]string{"granularity": "hourly", "currency": "USD", "service": "AWS Lambda", "tag_team": "backend"},
+			map[string]interface{}{"unblended_cost": 0.42, "estimated": true},
+			time.Date(2024, 6, 14, 9, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"aws_cost",
+			map[string]string{"granularity": "hourly", "currency": "USD", "service": "Amazon Simple Storage Service"},
+			map[string]interface{}{"unblended_cost": 1.5, "estimated": true},
+			time.Date(2024, 6, 14, 9, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"aws_cost",
+			map[string]string{"granularity": "hourly", "currency": "USD", "service": "AWS Lambda", "tag_team": "backend"},
+			map[string]interface{}{"unblended_cost": 0.1, "estimated": true},
+			time.Date(2024, 6, 14, 10, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The pages must be requested with the same parameters
+	require.Len(t, client.requests, 2)
+	require.Nil(t, client.requests[0].NextPageToken)
+	require.Equal(t, "next", aws.ToString(client.requests[1].NextPageToken))
+	for _, request := range client.requests {
+		require.Equal(t, period("2024-06-14T09:00:00Z", "2024-06-14T11:00:00Z"), request.TimePeriod)
+		require.Equal(t, []types.GroupDefinition{
+			{Type: types.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")},
+			{Type: types.GroupDefinitionTypeTag, Key: aws.String("team")},
+		}, request.GroupBy)
+	}
+}
+
+func TestTimePeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		granularity string
+		lookback    time.Duration
+		expected    *types.DateInterval
+	}{
+		{
+			name:     "daily default",
+			expected: period("2024-06-11", "2024-06-15"),
+		},
+		{
+			name:        "hourly default",
+			granularity: "hourly",
+			expected:    period("2024-06-14T04:00:00Z", "2024-06-14T11:00:00Z"),
+		},
+		{
+			name:        "monthly",
+			granularity: "monthly",
+			lookback:    30 * 24 * time.Hour,
+			expected:    period("2024-05-01", "2024-06-15"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &AWSCost{
+				Granularity: tt.granularity,
+				Lookback:    config.Duration(tt.lookback),
+				now:         fixedNow,
+			}
+			require.NoError(t, plugin.Init())
+			require.Equal(t, tt.expected, plugin.timePeriod())
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AWSCost
+		expected string
+	}{
+		{
+			name:     "invalid granularity",
+			plugin:   &AWSCost{Granularity: "weekly"},
+			expected: `invalid 'granularity' "weekly"`,
+		},
+		{
+			name:     "hourly lookback too long",
+			plugin:   &AWSCost{Granularity: "hourly", Lookback: config.Duration(15 * 24 * time.Hour)},
+			expected: "'lookback' must not exceed 14 days",
+		},
+		{
+			name:     "negative lookback",
+			plugin:   &AWSCost{Lookback: config.Duration(-time.Hour)},
+			expected: "'lookback' must not be negative",
+		},
+		{
+			name:     "too many groups",
+			plugin:   &AWSCost{GroupBy: []string{"SERVICE", "REGION", "tag:team"}},
+			expected: "at most 2 'group_by' entries are supported",
+		},
+		{
+			name:     "empty group key",
+			plugin:   &AWSCost{GroupBy: []string{"tag:"}},
+			expected: `invalid 'group_by' entry "tag:"`,
+		},
+		{
+			name:     "invalid group type",
+			plugin:   &AWSCost{GroupBy: []string{"label:team"}},
+			expected: `invalid 'group_by' type "label"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
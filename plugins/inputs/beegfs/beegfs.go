@@ -0,0 +1,215 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package beegfs
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Node entry of the node list, e.g. "storage01 [ID: 1]"
+var nodeRe = regexp.MustCompile(`^(\S+)\s+\[ID:\s*(\d+)\]`)
+
+type BeeGFS struct {
+	Binary         string          `toml:"binary"`
+	UseSudo        bool            `toml:"use_sudo"`
+	ConfigFile     string          `toml:"config_file"`
+	NodeTypes      []string        `toml:"node_types"`
+	MaxConcurrency int             `toml:"max_concurrency"`
+	Timeout        config.Duration `toml:"timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	run func(args ...string) ([]byte, error)
+}
+
+type node struct {
+	nodeType string
+	name     string
+	id       string
+}
+
+func (*BeeGFS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BeeGFS) Init() error {
+	if b.Binary == "" {
+		b.Binary = "/usr/bin/beegfs-ctl"
+	}
+	if len(b.NodeTypes) == 0 {
+		b.NodeTypes = []string{"meta", "storage"}
+	}
+	for _, t := range b.NodeTypes {
+		if t != "meta" && t != "storage" {
+			return fmt.Errorf("invalid node type %q", t)
+		}
+	}
+	if b.MaxConcurrency < 1 {
+		b.MaxConcurrency = 4
+	}
+	if b.Timeout <= 0 {
+		b.Timeout = config.Duration(5 * time.Second)
+	}
+	b.run = b.beegfsCtl
+
+	return nil
+}
+
+func (b *BeeGFS) Gather(acc telegraf.Accumulator) error {
+	var nodes []node
+	for _, t := range b.NodeTypes {
+		n, err := b.listNodes(t)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
+		nodes = append(nodes, n...)
+	}
+
+	// Query the nodes concurrently as each call has a round-trip to the
+	// node, limiting the number of parallel calls to protect the cluster
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, b.MaxConcurrency)
+	for _, n := range nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(n node) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := b.gatherNode(acc, n); err != nil {
+				acc.AddError(fmt.Errorf("gathering %s node %q failed: %w", n.nodeType, n.name, err))
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (b *BeeGFS) listNodes(nodeType string) ([]node, error) {
+	out, err := b.run("--listnodes", "--nodetype="+nodeType)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []node
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		match := nodeRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		nodes = append(nodes, node{nodeType: nodeType, name: match[1], id: match[2]})
+	}
+	return nodes, scanner.Err()
+}
+
+func (b *BeeGFS) gatherNode(acc telegraf.Accumulator, n node) error {
+	out, err := b.run("--serverstats", "--nodetype="+n.nodeType, "--nodeid="+n.id, "--history=10")
+	if err != nil {
+		return err
+	}
+
+	columns, values, err := parseServerStats(out)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{}, len(columns))
+	var ts time.Time
+	for i, column := range columns {
+		v, err := strconv.ParseUint(values[i], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing value %q of column %q failed: %w", values[i], column, err)
+		}
+		if column == "time_index" {
+			ts = time.Unix(int64(v), 0)
+			continue
+		}
+		fields[strings.ToLower(column)] = v
+	}
+
+	tags := map[string]string{
+		"node_type": n.nodeType,
+		"node":      n.name,
+		"node_id":   n.id,
+	}
+	if ts.IsZero() {
+		acc.AddFields("beegfs", fields, tags)
+	} else {
+		acc.AddFields("beegfs", fields, tags, ts)
+	}
+	return nil
+}
+
+// parseServerStats returns the column names and the most recent row of the
+// server statistics table
+func parseServerStats(out []byte) (columns, values []string, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 || strings.HasPrefix(parts[0], "=") {
+			continue
+		}
+		if parts[0] == "time_index" {
+			columns = parts
+			continue
+		}
+		if columns != nil && len(parts) == len(columns) {
+			values = parts
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if columns == nil {
+		return nil, nil, errors.New("no statistics header found")
+	}
+	if values == nil {
+		return nil, nil, errors.New("no statistics found")
+	}
+	return columns, values, nil
+}
+
+func (b *BeeGFS) beegfsCtl(args ...string) ([]byte, error) {
+	if b.ConfigFile != "" {
+		args = append([]string{"--cfgFile=" + b.ConfigFile}, args...)
+	}
+
+	name := b.Binary
+	if b.UseSudo {
+		args = append([]string{name}, args...)
+		name = "sudo"
+	}
+
+	cmd := exec.Command(name, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := internal.RunTimeout(cmd, time.Duration(b.Timeout)); err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", strings.Join(cmd.Args, " "), err)
+	}
+	return out.Bytes(), nil
+}
+
+func init() {
+	inputs.Add("beegfs", func() telegraf.Input {
+		return &BeeGFS{}
+	})
+}
@@ -0,0 +1,131 @@
+package beegfs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const metaNodes = `meta01 [ID: 1]
+meta02 [ID: 2]
+`
+
+const storageNodes = `storage01 [ID: 101]
+`
+
+const metaStats = `====== 10 s ======
+time_index      reqs      qlen       bsy
+1718354430        10         0         0
+1718354431        57         1         2
+`
+
+const storageStats = `====== 10 s ======
+time_index write_KiB  read_KiB      reqs      qlen       bsy
+1718354431      1024      2048        12         0         1
+`
+
+func TestGather(t *testing.T) {
+	plugin := &BeeGFS{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(args ...string) ([]byte, error) {
+		switch strings.Join(args, " ") {
+		case "--listnodes --nodetype=meta":
+			return []byte(metaNodes), nil
+		case "--listnodes --nodetype=storage":
+			return []byte(storageNodes), nil
+		case "--serverstats --nodetype=meta --nodeid=1 --history=10":
+			return []byte(metaStats), nil
+		case "--serverstats --nodetype=meta --nodeid=2 --history=10":
+			return nil, errors.New("node unreachable")
+		case "--serverstats --nodetype=storage --nodeid=101 --history=10":
+			return []byte(storageStats), nil
+		}
+		return nil, errors.New("unexpected call")
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Errors on one node must not affect the others
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `meta node "meta02"`)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"beegfs",
+			map[string]string{"node_type": "meta", "node": "meta01", "node_id": "1"},
+			map[string]interface{}{
+				"reqs": uint64(57),
+				"qlen": uint64(1),
+				"bsy":  uint64(2),
+			},
+			time.Unix(1718354431, 0),
+		),
+		metric.New(
+			"beegfs",
+			map[string]string{"node_type": "storage", "node": "storage01", "node_id": "101"},
+			map[string]interface{}{
+				"write_kib": uint64(1024),
+				"read_kib":  uint64(2048),
+				"reqs":      uint64(12),
+				"qlen":      uint64(0),
+				"bsy":       uint64(1),
+			},
+			time.Unix(1718354431, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestGatherConcurrency(t *testing.T) {
+	var nodes strings.Builder
+	for i := range 10 {
+		fmt.Fprintf(&nodes, "storage%02d [ID: %d]\n", i, i)
+	}
+
+	var mu sync.Mutex
+	var running, peak int
+	plugin := &BeeGFS{
+		NodeTypes:      []string{"storage"},
+		MaxConcurrency: 3,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(args ...string) ([]byte, error) {
+		if args[0] == "--listnodes" {
+			return []byte(nodes.String()), nil
+		}
+
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return []byte(storageStats), nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 10)
+	require.LessOrEqual(t, peak, 3)
+}
+
+func TestInitInvalidNodeType(t *testing.T) {
+	plugin := &BeeGFS{NodeTypes: []string{"client"}}
+	require.ErrorContains(t, plugin.Init(), "invalid node type")
+}
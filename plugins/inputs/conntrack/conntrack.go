@@ -9,9 +9,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/vishvananda/netlink"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -40,10 +43,13 @@ const (
 )
 
 type Conntrack struct {
-	Collect []string `toml:"collect"`
-	Dirs    []string `toml:"dirs"`
-	Files   []string `toml:"files"`
-	ps      system.PS
+	Collect   []string `toml:"collect"`
+	Dirs      []string `toml:"dirs"`
+	Files     []string `toml:"files"`
+	Namespace string   `toml:"namespace"`
+
+	ps        system.PS
+	listFlows func(namespace string) ([]*netlink.ConntrackFlow, error)
 }
 
 func (*Conntrack) SampleConfig() string {
@@ -53,14 +59,31 @@ func (*Conntrack) SampleConfig() string {
 func (c *Conntrack) Init() error {
 	c.setDefaults()
 
-	if err := choice.CheckSlice(c.Collect, []string{"all", "percpu"}); err != nil {
+	if err := choice.CheckSlice(c.Collect, []string{"all", "percpu", "table"}); err != nil {
 		return fmt.Errorf("config option 'collect': %w", err)
 	}
 
+	// The files and statistics in /proc belong to the namespace of Telegraf
+	// so only the table can be collected for other namespaces
+	if c.Namespace != "" {
+		if len(c.Collect) != 1 || c.Collect[0] != "table" {
+			return errors.New(`'namespace' requires collect = ["table"]`)
+		}
+		handle, err := netlinkHandle(c.Namespace)
+		if err != nil {
+			return err
+		}
+		handle.Close()
+	}
+
 	return nil
 }
 
 func (c *Conntrack) Gather(acc telegraf.Accumulator) error {
+	if c.Namespace != "" {
+		return c.gatherTable(acc)
+	}
+
 	var metricKey string
 	fields := make(map[string]interface{})
 
@@ -95,6 +118,13 @@ func (c *Conntrack) Gather(acc telegraf.Accumulator) error {
 	}
 
 	for _, metric := range c.Collect {
+		if metric == "table" {
+			if err := c.gatherTable(acc); err != nil {
+				acc.AddError(err)
+			}
+			continue
+		}
+
 		perCPU := metric == "percpu"
 		stats, err := c.ps.NetConntrack(perCPU)
 		if err != nil {
@@ -138,6 +168,10 @@ func (c *Conntrack) Gather(acc telegraf.Accumulator) error {
 	}
 
 	if len(fields) == 0 {
+		// The table statistics do not depend on the files
+		if slices.Contains(c.Collect, "table") {
+			return nil
+		}
 		return errors.New("conntrack input failed to collect metrics, make sure that the kernel module is loaded")
 	}
 
@@ -158,7 +192,8 @@ func (c *Conntrack) setDefaults() {
 func init() {
 	inputs.Add(inputName, func() telegraf.Input {
 		return &Conntrack{
-			ps: system.NewSystemPS(),
+			ps:        system.NewSystemPS(),
+			listFlows: listFlows,
 		}
 	})
 }
@@ -7,11 +7,16 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/net"
 	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs/system"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -344,3 +349,85 @@ func TestCollectPsSystemInit(t *testing.T) {
 	// make sure Conntrack.ps gets initialized without mocking
 	require.NoError(t, err)
 }
+
+func TestCollectTable(t *testing.T) {
+	flow := func(family, protocol uint8, zone uint16) *netlink.ConntrackFlow {
+		return &netlink.ConntrackFlow{
+			FamilyType: family,
+			Forward:    netlink.IPTuple{Protocol: protocol},
+			Zone:       zone,
+		}
+	}
+
+	c := &Conntrack{
+		Collect: []string{"table"},
+		Dirs:    []string{t.TempDir()},
+		listFlows: func(string) ([]*netlink.ConntrackFlow, error) {
+			return []*netlink.ConntrackFlow{
+				flow(syscall.AF_INET, syscall.IPPROTO_TCP, 0),
+				flow(syscall.AF_INET, syscall.IPPROTO_TCP, 0),
+				flow(syscall.AF_INET, syscall.IPPROTO_UDP, 0),
+				flow(syscall.AF_INET, syscall.IPPROTO_TCP, 5),
+				flow(syscall.AF_INET6, syscall.IPPROTO_ICMPV6, 0),
+				flow(syscall.AF_INET, 253, 0),
+			}, nil
+		},
+	}
+	require.NoError(t, c.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, c.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"conntrack_table",
+			map[string]string{"family": "ipv4", "protocol": "tcp", "zone": "0"},
+			map[string]interface{}{"entries": 2},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"conntrack_table",
+			map[string]string{"family": "ipv4", "protocol": "udp", "zone": "0"},
+			map[string]interface{}{"entries": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"conntrack_table",
+			map[string]string{"family": "ipv4", "protocol": "tcp", "zone": "5"},
+			map[string]interface{}{"entries": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"conntrack_table",
+			map[string]string{"family": "ipv6", "protocol": "icmpv6", "zone": "0"},
+			map[string]interface{}{"entries": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"conntrack_table",
+			map[string]string{"family": "ipv4", "protocol": "253", "zone": "0"},
+			map[string]interface{}{"entries": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestNamespaceRequiresTable(t *testing.T) {
+	c := &Conntrack{
+		Collect:   []string{"all", "table"},
+		Namespace: "blue",
+	}
+	require.EqualError(t, c.Init(), `'namespace' requires collect = ["table"]`)
+
+	c = &Conntrack{
+		Collect:   []string{"table"},
+		Namespace: "telegraf-does-not-exist",
+	}
+	require.ErrorContains(t, c.Init(), `opening namespace "telegraf-does-not-exist" failed`)
+}
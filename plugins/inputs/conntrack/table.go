@@ -0,0 +1,103 @@
+//go:build linux
+
+package conntrack
+
+import (
+	"fmt"
+	"strconv"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+
+	"github.com/influxdata/telegraf"
+)
+
+var protocolNames = map[uint8]string{
+	syscall.IPPROTO_ICMP:    "icmp",
+	syscall.IPPROTO_TCP:     "tcp",
+	syscall.IPPROTO_UDP:     "udp",
+	syscall.IPPROTO_DCCP:    "dccp",
+	syscall.IPPROTO_GRE:     "gre",
+	syscall.IPPROTO_ICMPV6:  "icmpv6",
+	syscall.IPPROTO_SCTP:    "sctp",
+	syscall.IPPROTO_UDPLITE: "udplite",
+}
+
+type tableKey struct {
+	family   string
+	protocol string
+	zone     string
+}
+
+// gatherTable counts the entries of the conntrack table per address family,
+// protocol and zone
+func (c *Conntrack) gatherTable(acc telegraf.Accumulator) error {
+	flows, err := c.listFlows(c.Namespace)
+	if err != nil {
+		return fmt.Errorf("listing conntrack table failed: %w", err)
+	}
+
+	counts := make(map[tableKey]int)
+	for _, flow := range flows {
+		key := tableKey{
+			family:   "ipv4",
+			protocol: protocolNames[flow.Forward.Protocol],
+			zone:     strconv.FormatUint(uint64(flow.Zone), 10),
+		}
+		if flow.FamilyType == syscall.AF_INET6 {
+			key.family = "ipv6"
+		}
+		if key.protocol == "" {
+			key.protocol = strconv.FormatUint(uint64(flow.Forward.Protocol), 10)
+		}
+		counts[key]++
+	}
+
+	for key, count := range counts {
+		tags := map[string]string{
+			"family":   key.family,
+			"protocol": key.protocol,
+			"zone":     key.zone,
+		}
+		if c.Namespace != "" {
+			tags["namespace"] = c.Namespace
+		}
+		acc.AddGauge(inputName+"_table", map[string]interface{}{"entries": count}, tags)
+	}
+	return nil
+}
+
+// listFlows dumps the conntrack table of the given network namespace or the
+// namespace of the process if empty
+func listFlows(namespace string) ([]*netlink.ConntrackFlow, error) {
+	handle, err := netlinkHandle(namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	var flows []*netlink.ConntrackFlow
+	for _, family := range []netlink.InetFamily{syscall.AF_INET, syscall.AF_INET6} {
+		f, err := handle.ConntrackTableList(netlink.ConntrackTable, family)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, f...)
+	}
+	return flows, nil
+}
+
+func netlinkHandle(namespace string) (*netlink.Handle, error) {
+	if namespace == "" {
+		return netlink.NewHandle(syscall.NETLINK_NETFILTER)
+	}
+
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("opening namespace %q failed: %w", namespace, err)
+	}
+	defer ns.Close()
+
+	return netlink.NewHandleAt(ns, syscall.NETLINK_NETFILTER)
+}
@@ -3,13 +3,17 @@ package consul
 
 import (
 	_ "embed"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 
 	"github.com/influxdata/telegraf"
 	telegraf_config "github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -18,15 +22,16 @@ import (
 var sampleConfig string
 
 type Consul struct {
-	Address       string `toml:"address"`
-	Scheme        string `toml:"scheme"`
-	Token         string `toml:"token"`
-	Username      string `toml:"username"`
-	Password      string `toml:"password"`
-	Datacentre    string `toml:"datacentre" deprecated:"1.10.0;1.35.0;use 'datacenter' instead"`
-	Datacenter    string `toml:"datacenter"`
-	TagDelimiter  string `toml:"tag_delimiter"`
-	MetricVersion int    `toml:"metric_version"`
+	Address       string   `toml:"address"`
+	Scheme        string   `toml:"scheme"`
+	Token         string   `toml:"token"`
+	Username      string   `toml:"username"`
+	Password      string   `toml:"password"`
+	Datacentre    string   `toml:"datacentre" deprecated:"1.10.0;1.35.0;use 'datacenter' instead"`
+	Datacenter    string   `toml:"datacenter"`
+	TagDelimiter  string   `toml:"tag_delimiter"`
+	MetricVersion int      `toml:"metric_version"`
+	Collect       []string `toml:"collect"`
 	Log           telegraf.Logger
 	tls.ClientConfig
 
@@ -49,6 +54,13 @@ func (c *Consul) Init() error {
 		)
 	}
 
+	if len(c.Collect) == 0 {
+		c.Collect = []string{"health_checks"}
+	}
+	if err := choice.CheckSlice(c.Collect, []string{"health_checks", "autopilot", "connect_proxies"}); err != nil {
+		return fmt.Errorf(`cannot verify "collect" setting: %w`, err)
+	}
+
 	config := api.DefaultConfig()
 
 	if c.Address != "" {
@@ -92,13 +104,41 @@ func (c *Consul) Init() error {
 }
 
 func (c *Consul) Gather(acc telegraf.Accumulator) error {
-	checks, _, err := c.client.Health().State("any", nil)
+	if choice.Contains("health_checks", c.Collect) {
+		checks, _, err := c.client.Health().State("any", nil)
+		if err != nil {
+			return err
+		}
+		c.gatherHealthCheck(acc, checks)
+	}
 
-	if err != nil {
-		return err
+	if choice.Contains("autopilot", c.Collect) {
+		reply, err := c.client.Operator().AutopilotServerHealth(nil)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying autopilot health failed: %w", err))
+		} else {
+			gatherAutopilotHealth(acc, reply)
+		}
 	}
 
-	c.gatherHealthCheck(acc, checks)
+	if choice.Contains("connect_proxies", c.Collect) {
+		services, err := c.client.Agent().ServicesWithFilter(`Kind != ""`)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying proxy services failed: %w", err))
+			return nil
+		}
+
+		status := make(map[string]string, len(services))
+		for id := range services {
+			s, _, err := c.client.Agent().AgentHealthServiceByID(id)
+			if err != nil {
+				acc.AddError(fmt.Errorf("querying health of %q failed: %w", id, err))
+				continue
+			}
+			status[id] = s
+		}
+		gatherConnectProxies(acc, services, status)
+	}
 
 	return nil
 }
@@ -144,6 +184,88 @@ func (c *Consul) gatherHealthCheck(acc telegraf.Accumulator, checks []*api.Healt
 	}
 }
 
+// gatherAutopilotHealth adds the raft health of the cluster as seen by the
+// autopilot of the leader and the state of each server
+func gatherAutopilotHealth(acc telegraf.Accumulator, reply *api.OperatorHealthReply) {
+	var voters int
+	for _, server := range reply.Servers {
+		tags := map[string]string{
+			"server_id":   server.ID,
+			"server_name": server.Name,
+			"address":     server.Address,
+			"version":     server.Version,
+			"serf_status": server.SerfStatus,
+		}
+
+		fields := map[string]interface{}{
+			"healthy":    server.Healthy,
+			"leader":     server.Leader,
+			"voter":      server.Voter,
+			"last_term":  server.LastTerm,
+			"last_index": server.LastIndex,
+		}
+		if server.LastContact != nil {
+			fields["last_contact_ms"] = float64(server.LastContact.Duration()) / float64(time.Millisecond)
+		}
+		if server.Voter {
+			voters++
+		}
+
+		acc.AddGauge("consul_raft_server", fields, tags)
+	}
+
+	fields := map[string]interface{}{
+		"healthy":           reply.Healthy,
+		"failure_tolerance": reply.FailureTolerance,
+		"servers":           len(reply.Servers),
+		"voters":            voters,
+	}
+	acc.AddGauge("consul_autopilot", fields, map[string]string{})
+}
+
+// gatherConnectProxies adds the health of the service-mesh proxies and
+// gateways registered with the local agent
+func gatherConnectProxies(acc telegraf.Accumulator, services map[string]*api.AgentService, status map[string]string) {
+	ids := make([]string, 0, len(services))
+	for id := range services {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		service := services[id]
+		state, found := status[id]
+		if !found {
+			continue
+		}
+
+		tags := map[string]string{
+			"service_id":   service.ID,
+			"service_name": service.Service,
+			"kind":         string(service.Kind),
+		}
+
+		fields := map[string]interface{}{
+			"passing":  0,
+			"critical": 0,
+			"warning":  0,
+			"port":     service.Port,
+		}
+		if state == api.HealthPassing || state == api.HealthCritical || state == api.HealthWarning {
+			fields[state] = 1
+		}
+
+		if service.Proxy != nil {
+			if service.Proxy.DestinationServiceName != "" {
+				tags["destination_service"] = service.Proxy.DestinationServiceName
+			}
+			fields["upstreams"] = len(service.Proxy.Upstreams)
+		}
+
+		acc.AddGauge("consul_connect_proxy", fields, tags)
+	}
+}
+
 func init() {
 	inputs.Add("consul", func() telegraf.Input {
 		return &Consul{}
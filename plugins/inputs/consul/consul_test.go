@@ -2,8 +2,13 @@ package consul
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -135,3 +140,182 @@ func TestGatherHealthCheckWithDelimitedTagsV2(t *testing.T) {
 
 	acc.AssertContainsTaggedFields(t, "consul_health_checks", expectedFields, expectedTags)
 }
+
+func TestGatherAutopilotHealth(t *testing.T) {
+	reply := &api.OperatorHealthReply{
+		Healthy:          true,
+		FailureTolerance: 1,
+		Servers: []api.ServerHealth{
+			{
+				ID:          "e349749b-3303-3ddf-959c-b5885a0e1f6e",
+				Name:        "node1",
+				Address:     "10.0.0.11:8300",
+				SerfStatus:  "alive",
+				Version:     "1.19.1",
+				Leader:      true,
+				LastContact: api.NewReadableDuration(0),
+				LastTerm:    3,
+				LastIndex:   2481,
+				Healthy:     true,
+				Voter:       true,
+			},
+			{
+				ID:          "5a1e2f7d-6c3b-8a9e-1f0d-4b2c7e8a9d10",
+				Name:        "node2",
+				Address:     "10.0.0.12:8300",
+				SerfStatus:  "alive",
+				Version:     "1.19.1",
+				LastContact: api.NewReadableDuration(12500 * time.Microsecond),
+				LastTerm:    3,
+				LastIndex:   2479,
+				Healthy:     true,
+				Voter:       false,
+			},
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"consul_raft_server",
+			map[string]string{
+				"server_id":   "e349749b-3303-3ddf-959c-b5885a0e1f6e",
+				"server_name": "node1",
+				"address":     "10.0.0.11:8300",
+				"version":     "1.19.1",
+				"serf_status": "alive",
+			},
+			map[string]interface{}{
+				"healthy":         true,
+				"leader":          true,
+				"voter":           true,
+				"last_term":       uint64(3),
+				"last_index":      uint64(2481),
+				"last_contact_ms": float64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"consul_raft_server",
+			map[string]string{
+				"server_id":   "5a1e2f7d-6c3b-8a9e-1f0d-4b2c7e8a9d10",
+				"server_name": "node2",
+				"address":     "10.0.0.12:8300",
+				"version":     "1.19.1",
+				"serf_status": "alive",
+			},
+			map[string]interface{}{
+				"healthy":         true,
+				"leader":          false,
+				"voter":           false,
+				"last_term":       uint64(3),
+				"last_index":      uint64(2479),
+				"last_contact_ms": float64(12.5),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"consul_autopilot",
+			map[string]string{},
+			map[string]interface{}{
+				"healthy":           true,
+				"failure_tolerance": 1,
+				"servers":           2,
+				"voters":            1,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	var acc testutil.Accumulator
+	gatherAutopilotHealth(&acc, reply)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherConnectProxies(t *testing.T) {
+	services := map[string]*api.AgentService{
+		"web-sidecar-proxy": {
+			Kind:    api.ServiceKindConnectProxy,
+			ID:      "web-sidecar-proxy",
+			Service: "web-sidecar-proxy",
+			Port:    21000,
+			Proxy: &api.AgentServiceConnectProxyConfig{
+				DestinationServiceName: "web",
+				DestinationServiceID:   "web",
+				Upstreams: []api.Upstream{
+					{DestinationName: "db", LocalBindPort: 9191},
+					{DestinationName: "cache", LocalBindPort: 9192},
+				},
+			},
+		},
+		"mesh-gateway": {
+			Kind:    api.ServiceKindMeshGateway,
+			ID:      "mesh-gateway",
+			Service: "mesh-gateway",
+			Port:    8443,
+			Proxy:   &api.AgentServiceConnectProxyConfig{},
+		},
+		"api-sidecar-proxy": {
+			Kind:    api.ServiceKindConnectProxy,
+			ID:      "api-sidecar-proxy",
+			Service: "api-sidecar-proxy",
+		},
+	}
+	// The health of the API proxy could not be queried
+	status := map[string]string{
+		"web-sidecar-proxy": api.HealthPassing,
+		"mesh-gateway":      api.HealthCritical,
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"consul_connect_proxy",
+			map[string]string{
+				"service_id":   "mesh-gateway",
+				"service_name": "mesh-gateway",
+				"kind":         "mesh-gateway",
+			},
+			map[string]interface{}{
+				"passing":   0,
+				"critical":  1,
+				"warning":   0,
+				"port":      8443,
+				"upstreams": 0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"consul_connect_proxy",
+			map[string]string{
+				"service_id":          "web-sidecar-proxy",
+				"service_name":        "web-sidecar-proxy",
+				"kind":                "connect-proxy",
+				"destination_service": "web",
+			},
+			map[string]interface{}{
+				"passing":   1,
+				"critical":  0,
+				"warning":   0,
+				"port":      21000,
+				"upstreams": 2,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	var acc testutil.Accumulator
+	gatherConnectProxies(&acc, services, status)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &Consul{
+		MetricVersion: 2,
+		Collect:       []string{"health_checks", "sessions"},
+	}
+	require.ErrorContains(t, plugin.Init(), `cannot verify "collect" setting`)
+}
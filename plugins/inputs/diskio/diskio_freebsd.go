@@ -0,0 +1,162 @@
+package diskio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// geomConfXML returns the GEOM configuration of the system and is replaced
+// in tests.
+var geomConfXML = func() (string, error) {
+	return unix.Sysctl("kern.geom.confxml")
+}
+
+type diskInfoCache struct {
+	modifiedAt int64 // Unix Nano timestamp of the last modification of the device. This value is used to invalidate the cache
+	values     map[string]string
+}
+
+type geomMesh struct {
+	Classes []geomClass `xml:"class"`
+}
+
+type geomClass struct {
+	Name  string `xml:"name"`
+	Geoms []geom `xml:"geom"`
+}
+
+type geom struct {
+	Name      string         `xml:"name"`
+	Consumers []geomConsumer `xml:"consumer"`
+	Providers []geomProvider `xml:"provider"`
+}
+
+type geomConsumer struct {
+	Provider struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"provider"`
+}
+
+type geomProvider struct {
+	ID          string `xml:"id,attr"`
+	Name        string `xml:"name"`
+	MediaSize   string `xml:"mediasize"`
+	SectorSize  string `xml:"sectorsize"`
+	StripeSize  string `xml:"stripesize"`
+	Description string `xml:"config>descr"`
+	Ident       string `xml:"config>ident"`
+	LunID       string `xml:"config>lunid"`
+	Rotation    string `xml:"config>rotationrate"`
+	Type        string `xml:"config>type"`
+	Label       string `xml:"config>label"`
+}
+
+func (d *DiskIO) diskInfo(devName string) (map[string]string, error) {
+	// Check if the device exists
+	path := "/dev/" + devName
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	// Check if we already got a cached and valid entry
+	if ic, ok := d.infoCache[devName]; ok && stat.Mtim.Nano() == ic.modifiedAt {
+		return ic.values, nil
+	}
+
+	conf, err := geomConfXML()
+	if err != nil {
+		return nil, fmt.Errorf("error reading GEOM configuration: %w", err)
+	}
+	info, err := parseGeomInfo(conf, devName)
+	if err != nil {
+		return nil, err
+	}
+
+	d.infoCache[devName] = diskInfoCache{
+		modifiedAt: stat.Mtim.Nano(),
+		values:     info,
+	}
+
+	return info, nil
+}
+
+// parseGeomInfo extracts the properties of the given GEOM provider from the
+// XML configuration exposed by the kernel. Labels (e.g. 'gpt/boot' or
+// 'diskid/DISK-XYZ') referring to the provider are returned as 'DEVLINKS'
+// analogous to udev on Linux.
+func parseGeomInfo(conf, devName string) (map[string]string, error) {
+	var mesh geomMesh
+	if err := xml.Unmarshal([]byte(conf), &mesh); err != nil {
+		return nil, fmt.Errorf("parsing GEOM configuration failed: %w", err)
+	}
+
+	var info map[string]string
+	var id string
+	for _, c := range mesh.Classes {
+		for _, g := range c.Geoms {
+			for _, p := range g.Providers {
+				if p.Name != devName {
+					continue
+				}
+				id = p.ID
+				info = map[string]string{
+					"GEOM_CLASS": c.Name,
+					"GEOM_NAME":  g.Name,
+				}
+				for k, v := range map[string]string{
+					"MEDIASIZE":    p.MediaSize,
+					"SECTORSIZE":   p.SectorSize,
+					"STRIPESIZE":   p.StripeSize,
+					"DESCR":        p.Description,
+					"IDENT":        p.Ident,
+					"LUNID":        p.LunID,
+					"ROTATIONRATE": p.Rotation,
+					"TYPE":         p.Type,
+					"LABEL":        p.Label,
+				} {
+					if v != "" {
+						info[k] = v
+					}
+				}
+			}
+		}
+	}
+	if info == nil {
+		return nil, fmt.Errorf("no GEOM provider found for %q", devName)
+	}
+
+	// Collect all labels attached to the provider
+	var devlinks []string
+	for _, c := range mesh.Classes {
+		if c.Name != "LABEL" {
+			continue
+		}
+		for _, g := range c.Geoms {
+			for _, cons := range g.Consumers {
+				if cons.Provider.Ref != id {
+					continue
+				}
+				for _, p := range g.Providers {
+					devlinks = append(devlinks, "/dev/"+p.Name)
+				}
+			}
+		}
+	}
+	if len(devlinks) > 0 {
+		info["DEVLINKS"] = strings.Join(devlinks, " ")
+	}
+
+	return info, nil
+}
+
+func resolveName(name string) string {
+	return strings.TrimPrefix(name, "/dev/")
+}
+
+func getDeviceWWID(_ string) string {
+	return ""
+}
@@ -0,0 +1,66 @@
+package diskio
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGeomInfo(t *testing.T) {
+	buf, err := os.ReadFile("testdata/geom_confxml.xml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		device   string
+		expected map[string]string
+	}{
+		{
+			name:   "disk",
+			device: "ada0",
+			expected: map[string]string{
+				"GEOM_CLASS":   "DISK",
+				"GEOM_NAME":    "ada0",
+				"MEDIASIZE":    "256060514304",
+				"SECTORSIZE":   "512",
+				"STRIPESIZE":   "4096",
+				"DESCR":        "Samsung SSD 860 EVO 250GB",
+				"IDENT":        "S3Z9NB0K123456A",
+				"LUNID":        "5002538e40a1b2c3",
+				"ROTATIONRATE": "0",
+				"DEVLINKS":     "/dev/diskid/DISK-S3Z9NB0K123456A",
+			},
+		},
+		{
+			name:   "partition",
+			device: "ada0p2",
+			expected: map[string]string{
+				"GEOM_CLASS": "PART",
+				"GEOM_NAME":  "ada0",
+				"MEDIASIZE":  "255523602432",
+				"SECTORSIZE": "512",
+				"STRIPESIZE": "4096",
+				"TYPE":       "freebsd-zfs",
+				"LABEL":      "zfs0",
+				"DEVLINKS":   "/dev/gpt/zfs0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseGeomInfo(string(buf), tt.device)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+func TestParseGeomInfoUnknown(t *testing.T) {
+	buf, err := os.ReadFile("testdata/geom_confxml.xml")
+	require.NoError(t, err)
+
+	_, err = parseGeomInfo(string(buf), "nvd0")
+	require.ErrorContains(t, err, "no GEOM provider found")
+}
@@ -0,0 +1,600 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dynamodb_streams
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/harlow/kinesis-consumer/store/ddb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/common/checkpoint"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var once sync.Once
+
+const (
+	defaultMaxUndeliveredMessages = 1000
+	maxRetryInterval              = time.Minute
+)
+
+type DynamoDBStreams struct {
+	TableName              string                       `toml:"table_name"`
+	StreamARN              string                       `toml:"stream_arn"`
+	Source                 string                       `toml:"source"`
+	KinesisStream          string                       `toml:"kinesis_stream"`
+	ShardIteratorType      string                       `toml:"shard_iterator_type"`
+	EventNames             []string                     `toml:"event_names"`
+	PollInterval           config.Duration              `toml:"poll_interval"`
+	ShardDiscoveryInterval config.Duration              `toml:"shard_discovery_interval"`
+	MaxUndeliveredMessages int                          `toml:"max_undelivered_messages"`
+	CheckpointBackend      string                       `toml:"checkpoint_backend"`
+	DynamoDB               *dynamoDB                    `toml:"checkpoint_dynamodb"`
+	File                   *checkpoint.FileConfig       `toml:"checkpoint_file"`
+	Redis                  *checkpoint.RedisConfig      `toml:"checkpoint_redis"`
+	PostgreSQL             *checkpoint.PostgreSQLConfig `toml:"checkpoint_postgresql"`
+	CheckpointInterval     config.Duration              `toml:"checkpoint_interval"`
+	CheckpointBatch        int                          `toml:"checkpoint_batch"`
+	Log                    telegraf.Logger              `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	reader    streamReader
+	parser    telegraf.Parser
+	parserTex sync.Mutex
+	acc       telegraf.TrackingAccumulator
+	sem       chan struct{}
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	checkpoint checkpoint.Store
+	tracker    *checkpoint.Tracker
+	records    map[telegraf.TrackingID]*checkpoint.Record
+	recordsTex sync.Mutex
+
+	recordsRead      selfstat.Stat
+	parseErrors      selfstat.Stat
+	readErrors       selfstat.Stat
+	checkpointWrites selfstat.Stat
+	checkpointErrors selfstat.Stat
+}
+
+type dynamoDB struct {
+	AppName   string `toml:"app_name"`
+	TableName string `toml:"table_name"`
+}
+
+// shardState is the reading state of a shard known to the scheduler
+type shardState struct {
+	parent   string
+	initial  bool
+	started  bool
+	finished bool
+}
+
+func (*DynamoDBStreams) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DynamoDBStreams) Init() error {
+	switch d.Source {
+	case "", "dynamodb":
+		d.Source = "dynamodb"
+		if d.TableName == "" && d.StreamARN == "" {
+			return errors.New("either 'table_name' or 'stream_arn' is required")
+		}
+		if d.TableName != "" && d.StreamARN != "" {
+			return errors.New("'table_name' and 'stream_arn' are mutually exclusive")
+		}
+	case "kinesis":
+		if d.KinesisStream == "" {
+			return errors.New("'kinesis_stream' is required for the 'kinesis' source")
+		}
+	default:
+		return fmt.Errorf("invalid source %q", d.Source)
+	}
+
+	switch d.ShardIteratorType {
+	case "":
+		d.ShardIteratorType = "TRIM_HORIZON"
+	case "TRIM_HORIZON", "LATEST":
+	default:
+		return fmt.Errorf("invalid shard iterator type %q", d.ShardIteratorType)
+	}
+
+	for _, name := range d.EventNames {
+		switch name {
+		case "INSERT", "MODIFY", "REMOVE":
+		default:
+			return fmt.Errorf("invalid event name %q", name)
+		}
+	}
+
+	if d.PollInterval <= 0 {
+		d.PollInterval = config.Duration(time.Second)
+	}
+	if d.ShardDiscoveryInterval <= 0 {
+		d.ShardDiscoveryInterval = config.Duration(time.Minute)
+	}
+	if d.MaxUndeliveredMessages <= 0 {
+		d.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+
+	switch d.CheckpointBackend {
+	case "":
+		d.CheckpointBackend = "none"
+	case "none":
+	case "dynamodb":
+		if d.DynamoDB == nil {
+			return errors.New("'checkpoint_dynamodb' section required for 'dynamodb' checkpoint backend")
+		}
+	case "file":
+		if d.File == nil {
+			return errors.New("'checkpoint_file' section required for 'file' checkpoint backend")
+		}
+	case "redis":
+		if d.Redis == nil {
+			return errors.New("'checkpoint_redis' section required for 'redis' checkpoint backend")
+		}
+	case "postgresql":
+		if d.PostgreSQL == nil {
+			return errors.New("'checkpoint_postgresql' section required for 'postgresql' checkpoint backend")
+		}
+	default:
+		return fmt.Errorf("invalid checkpoint backend %q", d.CheckpointBackend)
+	}
+
+	if d.CheckpointInterval < 0 {
+		return errors.New("'checkpoint_interval' must not be negative")
+	}
+	if d.CheckpointBatch < 0 {
+		return errors.New("'checkpoint_batch' must not be negative")
+	}
+
+	return nil
+}
+
+func (d *DynamoDBStreams) SetParser(parser telegraf.Parser) {
+	d.parser = parser
+}
+
+func (d *DynamoDBStreams) Start(acc telegraf.Accumulator) error {
+	var cfg aws.Config
+	if d.reader == nil || d.CheckpointBackend == "dynamodb" {
+		httpClient, err := d.ClientConfig.CreateClient()
+		if err != nil {
+			return err
+		}
+		d.CredentialConfig.HTTPClient = httpClient
+
+		if cfg, err = d.CredentialConfig.Credentials(); err != nil {
+			return err
+		}
+		if d.EndpointURL != "" {
+			cfg.BaseEndpoint = &d.EndpointURL
+		}
+	}
+
+	if d.reader == nil {
+		reader, err := d.createReader(cfg)
+		if err != nil {
+			return err
+		}
+		d.reader = reader
+	}
+
+	store, err := d.createCheckpointStore(cfg)
+	if err != nil {
+		return fmt.Errorf("creating checkpoint store failed: %w", err)
+	}
+	d.checkpoint = store
+
+	tags := map[string]string{"stream": d.reader.name()}
+	d.recordsRead = selfstat.Register("dynamodb_streams", "records_read", tags)
+	d.parseErrors = selfstat.Register("dynamodb_streams", "parse_errors", tags)
+	d.readErrors = selfstat.Register("dynamodb_streams", "read_errors", tags)
+	d.checkpointWrites = selfstat.Register("dynamodb_streams", "checkpoint_writes", tags)
+	d.checkpointErrors = selfstat.Register("dynamodb_streams", "checkpoint_errors", tags)
+
+	d.acc = acc.WithTracking(d.MaxUndeliveredMessages)
+	d.records = make(map[telegraf.TrackingID]*checkpoint.Record, d.MaxUndeliveredMessages)
+	d.sem = make(chan struct{}, d.MaxUndeliveredMessages)
+
+	// Write each checkpoint immediately if not batching
+	batch := d.CheckpointBatch
+	if d.CheckpointInterval == 0 && batch == 0 {
+		batch = 1
+	}
+	d.tracker = checkpoint.NewTracker(batch, d.writeCheckpoint)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.onDelivery(ctx)
+	}()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.tracker.Run(ctx, time.Duration(d.CheckpointInterval))
+	}()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.schedule(ctx)
+	}()
+
+	return nil
+}
+
+func (*DynamoDBStreams) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (d *DynamoDBStreams) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+	d.closeCheckpointStore()
+}
+
+func (d *DynamoDBStreams) createReader(cfg aws.Config) (streamReader, error) {
+	if d.Source == "kinesis" {
+		return &kinesisReader{client: kinesis.NewFromConfig(cfg), stream: d.KinesisStream}, nil
+	}
+
+	arn := d.StreamARN
+	if arn == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var err error
+		if arn, err = latestStreamARN(ctx, dynamodb.NewFromConfig(cfg), d.TableName); err != nil {
+			return nil, err
+		}
+		d.Log.Debugf("Consuming stream %q of table %q", arn, d.TableName)
+	}
+	return &dynamodbReader{client: dynamodbstreams.NewFromConfig(cfg), arn: arn, table: d.TableName}, nil
+}
+
+// schedule periodically discovers the shards of the stream and starts reading
+// each shard once its parent shard is read completely. This keeps the changes
+// of an item in order as the item's changes move to the child shard when the
+// parent is closed.
+func (d *DynamoDBStreams) schedule(ctx context.Context) {
+	shards := make(map[string]*shardState)
+	finished := make(chan string)
+	initial := true
+
+	discover := func() {
+		list, err := d.reader.listShards(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.readErrors.Incr(1)
+				d.Log.Errorf("Discovering shards failed: %v", err)
+			}
+			return
+		}
+
+		listed := make(map[string]bool, len(list))
+		for _, s := range list {
+			listed[s.id] = true
+			if _, found := shards[s.id]; !found {
+				shards[s.id] = &shardState{parent: s.parent, initial: initial}
+			}
+		}
+		initial = false
+
+		// Forget about shards read completely and removed due to retention
+		for id, state := range shards {
+			if state.finished && !listed[id] {
+				delete(shards, id)
+			}
+		}
+	}
+
+	start := func() {
+		for id, state := range shards {
+			if state.started {
+				continue
+			}
+			if parent, found := shards[state.parent]; found && !parent.finished {
+				continue
+			}
+			state.started = true
+
+			// Shards created after starting contain new changes only
+			iteratorType := "TRIM_HORIZON"
+			if state.initial {
+				iteratorType = d.ShardIteratorType
+			}
+
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				d.readShard(ctx, id, iteratorType)
+				select {
+				case <-ctx.Done():
+				case finished <- id:
+				}
+			}()
+		}
+	}
+
+	discover()
+	start()
+
+	ticker := time.NewTicker(time.Duration(d.ShardDiscoveryInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			discover()
+		case id := <-finished:
+			d.Log.Debugf("Shard %q is closed and read completely", id)
+			if state, found := shards[id]; found {
+				state.finished = true
+			}
+		}
+		start()
+	}
+}
+
+// readShard reads the records of the shard until the shard is closed and all
+// its records are read or the context is done. Reading resumes after the
+// checkpoint of the shard if any.
+func (d *DynamoDBStreams) readShard(ctx context.Context, shardID, iteratorType string) {
+	stream := d.reader.name()
+
+	last, err := d.checkpoint.GetCheckpoint(stream, shardID)
+	if err != nil {
+		d.Log.Errorf("Getting checkpoint of shard %q failed: %v", shardID, err)
+	}
+
+	var iterator string
+	var attempt int
+	for {
+		if iterator == "" {
+			var err error
+			if last != "" {
+				iterator, err = d.reader.iterator(ctx, shardID, "AFTER_SEQUENCE_NUMBER", last)
+			} else {
+				iterator, err = d.reader.iterator(ctx, shardID, iteratorType, "")
+			}
+			if errors.Is(err, errTrimmed) {
+				d.Log.Warnf("Position %q of shard %q expired, reading from the oldest record", last, shardID)
+				last, iteratorType = "", "TRIM_HORIZON"
+				continue
+			}
+			if err != nil || iterator == "" {
+				if ctx.Err() != nil {
+					return
+				}
+				d.readErrors.Incr(1)
+				d.Log.Errorf("Getting iterator of shard %q failed: %v", shardID, err)
+				if !d.wait(ctx, d.retryDelay(attempt)) {
+					return
+				}
+				attempt++
+				continue
+			}
+		}
+
+		records, next, err := d.reader.records(ctx, iterator)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// Iterators expire after a few minutes, so start over after the
+			// last record read
+			d.readErrors.Incr(1)
+			d.Log.Errorf("Reading records of shard %q failed: %v", shardID, err)
+			iterator = ""
+			if !d.wait(ctx, d.retryDelay(attempt)) {
+				return
+			}
+			attempt++
+			continue
+		}
+		attempt = 0
+
+		for _, r := range records {
+			select {
+			case <-ctx.Done():
+				return
+			case d.sem <- struct{}{}:
+			}
+			d.onRecord(stream, shardID, r)
+			last = r.seq
+		}
+
+		if next == "" {
+			return
+		}
+		iterator = next
+
+		if len(records) == 0 && !d.wait(ctx, time.Duration(d.PollInterval)) {
+			return
+		}
+	}
+}
+
+// onRecord parses the record into metrics and adds them as a tracking group.
+// Records without metrics are done immediately to not block the checkpoint of
+// the shard.
+func (d *DynamoDBStreams) onRecord(stream, shardID string, r streamRecord) {
+	d.recordsRead.Incr(1)
+
+	record := checkpoint.NewRecord(stream, shardID, r.seq)
+	d.tracker.Track(record)
+
+	metrics, err := d.parse(r)
+	if err != nil {
+		d.parseErrors.Incr(1)
+		d.Log.Errorf("Parsing record %q of shard %q failed: %v", r.seq, shardID, err)
+	}
+	if len(metrics) == 0 {
+		<-d.sem
+		d.tracker.Complete(record, true)
+		return
+	}
+
+	d.recordsTex.Lock()
+	id := d.acc.AddTrackingMetricGroup(metrics)
+	d.records[id] = record
+	d.recordsTex.Unlock()
+}
+
+func (d *DynamoDBStreams) parse(r streamRecord) ([]telegraf.Metric, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(d.EventNames) > 0 && !slices.Contains(d.EventNames, r.event.EventName) {
+		return nil, nil
+	}
+
+	data, err := r.event.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	d.parserTex.Lock()
+	metrics, err := d.parser.Parse(data)
+	d.parserTex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		once.Do(func() {
+			d.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+	}
+	return metrics, nil
+}
+
+func (d *DynamoDBStreams) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info := <-d.acc.Delivered():
+			d.recordsTex.Lock()
+			record, ok := d.records[info.ID()]
+			if !ok {
+				d.recordsTex.Unlock()
+				continue
+			}
+			<-d.sem
+			delete(d.records, info.ID())
+			d.recordsTex.Unlock()
+
+			if !info.Delivered() {
+				d.Log.Debug("Metric group failed to process")
+			}
+			d.tracker.Complete(record, info.Delivered())
+		}
+	}
+}
+
+// retryDelay returns the delay before the given retry attempt, doubling the
+// poll interval with each attempt
+func (d *DynamoDBStreams) retryDelay(attempt int) time.Duration {
+	delay := time.Duration(d.PollInterval)
+	if attempt < 16 {
+		delay <<= attempt
+	}
+	return min(delay, maxRetryInterval)
+}
+
+// wait returns false if the context is done before the delay passed
+func (*DynamoDBStreams) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// writeCheckpoint persists the sequence number of the shard in the store
+func (d *DynamoDBStreams) writeCheckpoint(stream, shardID, sequenceNum string) {
+	if err := d.checkpoint.SetCheckpoint(stream, shardID, sequenceNum); err != nil {
+		d.checkpointErrors.Incr(1)
+		d.Log.Debugf("Setting checkpoint failed: %v", err)
+		return
+	}
+	d.checkpointWrites.Incr(1)
+}
+
+func (d *DynamoDBStreams) createCheckpointStore(cfg aws.Config) (checkpoint.Store, error) {
+	switch d.CheckpointBackend {
+	case "dynamodb":
+		return ddb.New(
+			d.DynamoDB.AppName,
+			d.DynamoDB.TableName,
+			ddb.WithDynamoClient(dynamodb.NewFromConfig(cfg)),
+			ddb.WithMaxInterval(time.Second*10),
+		)
+	case "file":
+		return checkpoint.NewFileStore(d.File)
+	case "redis":
+		return checkpoint.NewRedisStore(d.Redis)
+	case "postgresql":
+		return checkpoint.NewPostgreSQLStore(d.PostgreSQL)
+	}
+	return &checkpoint.NoopStore{}, nil
+}
+
+func (d *DynamoDBStreams) closeCheckpointStore() {
+	switch c := d.checkpoint.(type) {
+	case interface{ Shutdown() error }:
+		if err := c.Shutdown(); err != nil {
+			d.Log.Errorf("Shutting down checkpoint store failed: %v", err)
+		}
+	case io.Closer:
+		if err := c.Close(); err != nil {
+			d.Log.Errorf("Closing checkpoint store failed: %v", err)
+		}
+	}
+	d.checkpoint = nil
+}
+
+func init() {
+	inputs.Add("dynamodb_streams", func() telegraf.Input {
+		return &DynamoDBStreams{
+			Source:                 "dynamodb",
+			ShardIteratorType:      "TRIM_HORIZON",
+			PollInterval:           config.Duration(time.Second),
+			ShardDiscoveryInterval: config.Duration(time.Minute),
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		}
+	})
+}
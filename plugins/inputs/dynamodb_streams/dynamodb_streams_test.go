@@ -0,0 +1,438 @@
+package dynamodb_streams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/checkpoint"
+	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestConvertStreamRecord(t *testing.T) {
+	record := &types.Record{
+		EventID:     aws.String("1"),
+		EventName:   types.OperationTypeModify,
+		EventSource: aws.String("aws:dynamodb"),
+		AwsRegion:   aws.String("us-east-1"),
+		Dynamodb: &types.StreamRecord{
+			ApproximateCreationDateTime: aws.Time(time.UnixMilli(1700000000123)),
+			SequenceNumber:              aws.String("100"),
+			SizeBytes:                   aws.Int64(64),
+			StreamViewType:              types.StreamViewTypeNewAndOldImages,
+			Keys: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: "o-1"},
+			},
+			NewImage: map[string]types.AttributeValue{
+				"id":     &types.AttributeValueMemberS{Value: "o-1"},
+				"total":  &types.AttributeValueMemberN{Value: "42.5"},
+				"paid":   &types.AttributeValueMemberBOOL{Value: true},
+				"note":   &types.AttributeValueMemberNULL{Value: true},
+				"blob":   &types.AttributeValueMemberB{Value: []byte("hi")},
+				"tags":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+				"counts": &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+				"items": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"qty": &types.AttributeValueMemberN{Value: "3"},
+					}},
+				}},
+			},
+			OldImage: map[string]types.AttributeValue{
+				"id":    &types.AttributeValueMemberS{Value: "o-1"},
+				"total": &types.AttributeValueMemberN{Value: "40"},
+			},
+		},
+	}
+
+	event, err := fromStreamRecord(record, "Orders")
+	require.NoError(t, err)
+	data, err := event.encode()
+	require.NoError(t, err)
+
+	expected := `{"eventID":"1","eventName":"MODIFY","eventSource":"aws:dynamodb","awsRegion":"us-east-1",` +
+		`"tableName":"Orders","sequenceNumber":"100","approximateCreationDateTime":1700000000123,"sizeBytes":64,` +
+		`"streamViewType":"NEW_AND_OLD_IMAGES","keys":{"id":"o-1"},` +
+		`"newImage":{"blob":"aGk=","counts":[1,2],"id":"o-1","items":[{"qty":3}],"note":null,"paid":true,"tags":["a","b"],"total":42.5},` +
+		`"oldImage":{"id":"o-1","total":40}}`
+	require.JSONEq(t, expected, string(data))
+}
+
+func TestConvertKinesisRecord(t *testing.T) {
+	data := `{
+		"awsRegion": "us-east-1",
+		"eventID": "2",
+		"eventName": "REMOVE",
+		"userIdentity": {"principalId": "dynamodb.amazonaws.com", "type": "Service"},
+		"tableName": "Orders",
+		"dynamodb": {
+			"ApproximateCreationDateTime": 1700000000123456,
+			"ApproximateCreationDateTimePrecision": "MICROSECOND",
+			"Keys": {"id": {"S": "o-1"}},
+			"OldImage": {
+				"id": {"S": "o-1"},
+				"total": {"N": "40"},
+				"blob": {"B": "aGk="},
+				"counts": {"NS": ["1", "2"]},
+				"items": {"L": [{"M": {"qty": {"N": "3"}}}, {"NULL": true}]}
+			},
+			"SizeBytes": 32
+		},
+		"eventSource": "aws:dynamodb"
+	}`
+
+	event, err := fromKinesisRecord([]byte(data), "200")
+	require.NoError(t, err)
+	encoded, err := event.encode()
+	require.NoError(t, err)
+
+	expected := `{"eventID":"2","eventName":"REMOVE","eventSource":"aws:dynamodb","awsRegion":"us-east-1",` +
+		`"tableName":"Orders","sequenceNumber":"200","approximateCreationDateTime":1700000000123,"sizeBytes":32,` +
+		`"userIdentity":{"principalId":"dynamodb.amazonaws.com","type":"Service"},"keys":{"id":"o-1"},` +
+		`"oldImage":{"blob":"aGk=","counts":[1,2],"id":"o-1","items":[{"qty":3},null],"total":40}}`
+	require.JSONEq(t, expected, string(encoded))
+
+	_, err = fromKinesisRecord([]byte(`{"dynamodb": {"Keys": {"id": {"X": "1"}}}}`), "201")
+	require.ErrorContains(t, err, `unsupported attribute type "X"`)
+}
+
+func TestConsume(t *testing.T) {
+	// The child shard must only be read after its closed parent
+	reader := newMockReader()
+	reader.addShard("parent", "", true, insertEvent("1", "o-1", 10), insertEvent("2", "o-2", 20))
+	reader.addShard("child", "parent", false, insertEvent("3", "o-1", 11))
+
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	parser := &json.Parser{
+		MetricName: "orders",
+		TagKeys:    []string{"eventName", "keys_id"},
+		TimeKey:    "approximateCreationDateTime",
+		TimeFormat: "unix_ms",
+	}
+	require.NoError(t, parser.Init())
+
+	plugin := &DynamoDBStreams{
+		StreamARN:    "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/stream/2024-01-01T00:00:00.000",
+		PollInterval: config.Duration(10 * time.Millisecond),
+		Log:          testutil.Logger{},
+		reader:       reader,
+	}
+	plugin.SetParser(parser)
+	plugin.CheckpointBackend = "file"
+	plugin.File = &checkpoint.FileConfig{Path: path}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		metric.New("orders",
+			map[string]string{"eventName": "INSERT", "keys_id": "o-1"},
+			map[string]interface{}{"newImage_total": float64(10)},
+			time.UnixMilli(1700000000000),
+		),
+		metric.New("orders",
+			map[string]string{"eventName": "INSERT", "keys_id": "o-2"},
+			map[string]interface{}{"newImage_total": float64(20)},
+			time.UnixMilli(1700000000000),
+		),
+		metric.New("orders",
+			map[string]string{"eventName": "INSERT", "keys_id": "o-1"},
+			map[string]interface{}{"newImage_total": float64(11)},
+			time.UnixMilli(1700000000000),
+		),
+	}
+	metrics := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// Checkpoints are only written for delivered records
+	metrics[0].Accept()
+	metrics[2].Accept()
+	require.Eventually(t, func() bool {
+		return len(plugin.sem) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	plugin.Stop()
+
+	store, err := checkpoint.NewFileStore(&checkpoint.FileConfig{Path: path})
+	require.NoError(t, err)
+	seq, err := store.GetCheckpoint("stream", "parent")
+	require.NoError(t, err)
+	require.Equal(t, "1", seq)
+	seq, err = store.GetCheckpoint("stream", "child")
+	require.NoError(t, err)
+	require.Equal(t, "3", seq)
+
+	// Reading resumes after the checkpoints
+	parser = &json.Parser{
+		MetricName: "orders",
+		TagKeys:    []string{"eventName", "keys_id"},
+		TimeKey:    "approximateCreationDateTime",
+		TimeFormat: "unix_ms",
+	}
+	require.NoError(t, parser.Init())
+
+	plugin = &DynamoDBStreams{
+		StreamARN:    "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/stream/2024-01-01T00:00:00.000",
+		PollInterval: config.Duration(10 * time.Millisecond),
+		Log:          testutil.Logger{},
+		reader:       reader,
+	}
+	plugin.SetParser(parser)
+	plugin.CheckpointBackend = "file"
+	plugin.File = &checkpoint.FileConfig{Path: path}
+	require.NoError(t, plugin.Init())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, map[string]string{"eventName": "INSERT", "keys_id": "o-2"}, acc.GetTelegrafMetrics()[0].Tags())
+	require.Equal(t, []string{"parent:1", "child:3"}, reader.afterSequence())
+}
+
+func TestEventNames(t *testing.T) {
+	remove := insertEvent("2", "o-1", 0)
+	remove.EventName = "REMOVE"
+
+	reader := newMockReader()
+	reader.addShard("shard", "", false, insertEvent("1", "o-1", 10), remove, insertEvent("3", "o-2", 20))
+
+	parser := &json.Parser{
+		MetricName: "orders",
+		TagKeys:    []string{"eventName", "keys_id"},
+		TimeKey:    "approximateCreationDateTime",
+		TimeFormat: "unix_ms",
+	}
+	require.NoError(t, parser.Init())
+
+	plugin := &DynamoDBStreams{
+		StreamARN:    "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/stream/2024-01-01T00:00:00.000",
+		PollInterval: config.Duration(10 * time.Millisecond),
+		Log:          testutil.Logger{},
+		reader:       reader,
+	}
+	plugin.SetParser(parser)
+	plugin.EventNames = []string{"INSERT"}
+	plugin.MaxUndeliveredMessages = 1
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Filtered events do not take a slot of the undelivered messages
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	acc.GetTelegrafMetrics()[0].Accept()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, "o-2", acc.GetTelegrafMetrics()[1].Tags()["keys_id"])
+}
+
+func TestReadErrors(t *testing.T) {
+	reader := newMockReader()
+	reader.addShard("shard", "", false, insertEvent("1", "o-1", 10))
+	reader.failures = 2
+
+	parser := &json.Parser{
+		MetricName: "orders",
+		TagKeys:    []string{"eventName", "keys_id"},
+		TimeKey:    "approximateCreationDateTime",
+		TimeFormat: "unix_ms",
+	}
+	require.NoError(t, parser.Init())
+
+	plugin := &DynamoDBStreams{
+		StreamARN:    "arn:aws:dynamodb:us-east-1:123456789012:table/Orders/stream/2024-01-01T00:00:00.000",
+		PollInterval: config.Duration(10 * time.Millisecond),
+		Log:          testutil.Logger{},
+		reader:       reader,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Reading is retried after failures
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.GreaterOrEqual(t, plugin.readErrors.Get(), int64(2))
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DynamoDBStreams
+		expected string
+	}{
+		{
+			name:     "no table",
+			plugin:   &DynamoDBStreams{},
+			expected: "either 'table_name' or 'stream_arn' is required",
+		},
+		{
+			name:     "table and stream",
+			plugin:   &DynamoDBStreams{TableName: "a", StreamARN: "b"},
+			expected: "mutually exclusive",
+		},
+		{
+			name:     "kinesis without stream",
+			plugin:   &DynamoDBStreams{Source: "kinesis"},
+			expected: "'kinesis_stream' is required",
+		},
+		{
+			name:     "invalid iterator type",
+			plugin:   &DynamoDBStreams{TableName: "a", ShardIteratorType: "AT_TIMESTAMP"},
+			expected: `invalid shard iterator type "AT_TIMESTAMP"`,
+		},
+		{
+			name:     "invalid event name",
+			plugin:   &DynamoDBStreams{TableName: "a", EventNames: []string{"UPDATE"}},
+			expected: `invalid event name "UPDATE"`,
+		},
+		{
+			name:     "missing checkpoint section",
+			plugin:   &DynamoDBStreams{TableName: "a", CheckpointBackend: "redis"},
+			expected: "'checkpoint_redis' section required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func insertEvent(seq, id string, total int) *changeEvent {
+	return &changeEvent{
+		EventName:                   "INSERT",
+		SequenceNumber:              seq,
+		ApproximateCreationDateTime: 1700000000000,
+		Keys:                        map[string]interface{}{"id": id},
+		NewImage:                    map[string]interface{}{"id": id, "total": total},
+	}
+}
+
+type mockShard struct {
+	parent string
+	closed bool
+	events []*changeEvent
+}
+
+// mockReader serves the records of the shards with iterators of the form
+// "<shard>:<index>"
+type mockReader struct {
+	shards   map[string]*mockShard
+	order    []string
+	after    []string
+	failures int
+	sync.Mutex
+}
+
+func newMockReader() *mockReader {
+	return &mockReader{shards: make(map[string]*mockShard)}
+}
+
+func (r *mockReader) addShard(id, parent string, closed bool, events ...*changeEvent) {
+	r.shards[id] = &mockShard{parent: parent, closed: closed, events: events}
+	r.order = append(r.order, id)
+}
+
+func (r *mockReader) afterSequence() []string {
+	r.Lock()
+	defer r.Unlock()
+	return r.after
+}
+
+func (*mockReader) name() string {
+	return "stream"
+}
+
+func (r *mockReader) listShards(context.Context) ([]shard, error) {
+	// List children first to check the ordering
+	shards := make([]shard, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		id := r.order[i]
+		shards = append(shards, shard{id: id, parent: r.shards[id].parent})
+	}
+	return shards, nil
+}
+
+func (r *mockReader) iterator(_ context.Context, shardID, iteratorType, seq string) (string, error) {
+	s, found := r.shards[shardID]
+	if !found {
+		return "", fmt.Errorf("unknown shard %q", shardID)
+	}
+
+	switch iteratorType {
+	case "TRIM_HORIZON":
+		return shardID + ":0", nil
+	case "AFTER_SEQUENCE_NUMBER":
+		r.Lock()
+		r.after = append(r.after, shardID+":"+seq)
+		r.Unlock()
+		for i, e := range s.events {
+			if e.SequenceNumber == seq {
+				return shardID + ":" + strconv.Itoa(i+1), nil
+			}
+		}
+		return "", errTrimmed
+	}
+	return "", fmt.Errorf("unexpected iterator type %q", iteratorType)
+}
+
+func (r *mockReader) records(_ context.Context, iterator string) ([]streamRecord, string, error) {
+	r.Lock()
+	if r.failures > 0 {
+		r.failures--
+		r.Unlock()
+		return nil, "", errors.New("throttled")
+	}
+	r.Unlock()
+
+	shardID, pos, _ := strings.Cut(iterator, ":")
+	idx, err := strconv.Atoi(pos)
+	if err != nil {
+		return nil, "", err
+	}
+	s := r.shards[shardID]
+
+	// The parent must be read completely before the child
+	if parent, found := r.shards[s.parent]; found && !parent.closed {
+		return nil, "", fmt.Errorf("reading child %q of open shard", shardID)
+	}
+
+	records := make([]streamRecord, 0, len(s.events)-idx)
+	for _, e := range s.events[idx:] {
+		records = append(records, streamRecord{seq: e.SequenceNumber, event: e})
+	}
+
+	next := shardID + ":" + strconv.Itoa(len(s.events))
+	if s.closed {
+		next = ""
+	}
+	return records, next, nil
+}
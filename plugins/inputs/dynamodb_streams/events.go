@@ -0,0 +1,288 @@
+package dynamodb_streams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// changeEvent is the document handed to the parser for each change of an item.
+// The same document is created for records of DynamoDB Streams and Kinesis
+// Data Streams for DynamoDB. Attribute values are converted to plain JSON,
+// i.e. numbers are JSON numbers, binary values are base64 encoded and sets
+// are arrays.
+type changeEvent struct {
+	EventID                     string                 `json:"eventID"`
+	EventName                   string                 `json:"eventName"`
+	EventSource                 string                 `json:"eventSource,omitempty"`
+	AWSRegion                   string                 `json:"awsRegion,omitempty"`
+	TableName                   string                 `json:"tableName,omitempty"`
+	SequenceNumber              string                 `json:"sequenceNumber"`
+	ApproximateCreationDateTime int64                  `json:"approximateCreationDateTime,omitempty"`
+	SizeBytes                   int64                  `json:"sizeBytes,omitempty"`
+	StreamViewType              string                 `json:"streamViewType,omitempty"`
+	UserIdentity                *userIdentity          `json:"userIdentity,omitempty"`
+	Keys                        map[string]interface{} `json:"keys"`
+	NewImage                    map[string]interface{} `json:"newImage,omitempty"`
+	OldImage                    map[string]interface{} `json:"oldImage,omitempty"`
+}
+
+// userIdentity is set for items deleted by the time-to-live process
+type userIdentity struct {
+	PrincipalID string `json:"principalId"`
+	Type        string `json:"type"`
+}
+
+// fromStreamRecord creates the change event of a DynamoDB Streams record
+func fromStreamRecord(r *types.Record, table string) (*changeEvent, error) {
+	if r.Dynamodb == nil {
+		return nil, errors.New("record without stream data")
+	}
+
+	event := &changeEvent{
+		EventName:      string(r.EventName),
+		TableName:      table,
+		StreamViewType: string(r.Dynamodb.StreamViewType),
+	}
+	if r.EventID != nil {
+		event.EventID = *r.EventID
+	}
+	if r.EventSource != nil {
+		event.EventSource = *r.EventSource
+	}
+	if r.AwsRegion != nil {
+		event.AWSRegion = *r.AwsRegion
+	}
+	if r.Dynamodb.SequenceNumber != nil {
+		event.SequenceNumber = *r.Dynamodb.SequenceNumber
+	}
+	if r.Dynamodb.ApproximateCreationDateTime != nil {
+		event.ApproximateCreationDateTime = r.Dynamodb.ApproximateCreationDateTime.UnixMilli()
+	}
+	if r.Dynamodb.SizeBytes != nil {
+		event.SizeBytes = *r.Dynamodb.SizeBytes
+	}
+	if r.UserIdentity != nil {
+		event.UserIdentity = &userIdentity{}
+		if r.UserIdentity.PrincipalId != nil {
+			event.UserIdentity.PrincipalID = *r.UserIdentity.PrincipalId
+		}
+		if r.UserIdentity.Type != nil {
+			event.UserIdentity.Type = *r.UserIdentity.Type
+		}
+	}
+
+	var err error
+	if event.Keys, err = fromStreamItem(r.Dynamodb.Keys); err != nil {
+		return nil, fmt.Errorf("converting keys failed: %w", err)
+	}
+	if event.NewImage, err = fromStreamItem(r.Dynamodb.NewImage); err != nil {
+		return nil, fmt.Errorf("converting new image failed: %w", err)
+	}
+	if event.OldImage, err = fromStreamItem(r.Dynamodb.OldImage); err != nil {
+		return nil, fmt.Errorf("converting old image failed: %w", err)
+	}
+	return event, nil
+}
+
+func fromStreamItem(item map[string]types.AttributeValue) (map[string]interface{}, error) {
+	if item == nil {
+		return nil, nil
+	}
+	converted := make(map[string]interface{}, len(item))
+	for k, av := range item {
+		v, err := fromStreamAttribute(av)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", k, err)
+		}
+		converted[k] = v
+	}
+	return converted, nil
+}
+
+func fromStreamAttribute(av types.AttributeValue) (interface{}, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value), nil
+	case *types.AttributeValueMemberB:
+		return v.Value, nil
+	case *types.AttributeValueMemberBOOL:
+		return v.Value, nil
+	case *types.AttributeValueMemberNULL:
+		return nil, nil
+	case *types.AttributeValueMemberSS:
+		return v.Value, nil
+	case *types.AttributeValueMemberNS:
+		values := make([]json.Number, 0, len(v.Value))
+		for _, n := range v.Value {
+			values = append(values, json.Number(n))
+		}
+		return values, nil
+	case *types.AttributeValueMemberBS:
+		return v.Value, nil
+	case *types.AttributeValueMemberM:
+		return fromStreamItem(v.Value)
+	case *types.AttributeValueMemberL:
+		values := make([]interface{}, 0, len(v.Value))
+		for _, e := range v.Value {
+			converted, err := fromStreamAttribute(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, converted)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute type %T", av)
+}
+
+// kinesisRecord is the record format of Kinesis Data Streams for DynamoDB, see
+// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/kds.html
+type kinesisRecord struct {
+	AWSRegion    string        `json:"awsRegion"`
+	EventID      string        `json:"eventID"`
+	EventName    string        `json:"eventName"`
+	EventSource  string        `json:"eventSource"`
+	TableName    string        `json:"tableName"`
+	UserIdentity *userIdentity `json:"userIdentity"`
+	Dynamodb     struct {
+		ApproximateCreationDateTime          int64                      `json:"ApproximateCreationDateTime"`
+		ApproximateCreationDateTimePrecision string                     `json:"ApproximateCreationDateTimePrecision"`
+		Keys                                 map[string]json.RawMessage `json:"Keys"`
+		NewImage                             map[string]json.RawMessage `json:"NewImage"`
+		OldImage                             map[string]json.RawMessage `json:"OldImage"`
+		SizeBytes                            int64                      `json:"SizeBytes"`
+	} `json:"dynamodb"`
+}
+
+// fromKinesisRecord creates the change event of a Kinesis Data Streams for
+// DynamoDB record. The sequence number is the one of the Kinesis record.
+func fromKinesisRecord(data []byte, seq string) (*changeEvent, error) {
+	var r kinesisRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decoding record failed: %w", err)
+	}
+
+	event := &changeEvent{
+		EventID:        r.EventID,
+		EventName:      r.EventName,
+		EventSource:    r.EventSource,
+		AWSRegion:      r.AWSRegion,
+		TableName:      r.TableName,
+		SequenceNumber: seq,
+		SizeBytes:      r.Dynamodb.SizeBytes,
+		UserIdentity:   r.UserIdentity,
+	}
+
+	// The creation time is in milliseconds unless stated otherwise
+	event.ApproximateCreationDateTime = r.Dynamodb.ApproximateCreationDateTime
+	if r.Dynamodb.ApproximateCreationDateTimePrecision == "MICROSECOND" {
+		event.ApproximateCreationDateTime = time.UnixMicro(r.Dynamodb.ApproximateCreationDateTime).UnixMilli()
+	}
+
+	var err error
+	if event.Keys, err = fromJSONItem(r.Dynamodb.Keys); err != nil {
+		return nil, fmt.Errorf("converting keys failed: %w", err)
+	}
+	if event.NewImage, err = fromJSONItem(r.Dynamodb.NewImage); err != nil {
+		return nil, fmt.Errorf("converting new image failed: %w", err)
+	}
+	if event.OldImage, err = fromJSONItem(r.Dynamodb.OldImage); err != nil {
+		return nil, fmt.Errorf("converting old image failed: %w", err)
+	}
+	return event, nil
+}
+
+func fromJSONItem(item map[string]json.RawMessage) (map[string]interface{}, error) {
+	if item == nil {
+		return nil, nil
+	}
+	converted := make(map[string]interface{}, len(item))
+	for k, raw := range item {
+		v, err := fromJSONAttribute(raw)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", k, err)
+		}
+		converted[k] = v
+	}
+	return converted, nil
+}
+
+// fromJSONAttribute converts an attribute value in DynamoDB JSON, e.g.
+// {"N": "42"}, to plain JSON
+func fromJSONAttribute(raw json.RawMessage) (interface{}, error) {
+	var av map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &av); err != nil {
+		return nil, err
+	}
+	if len(av) != 1 {
+		return nil, fmt.Errorf("invalid attribute value %s", raw)
+	}
+
+	for typ, value := range av {
+		switch typ {
+		case "S", "B", "SS", "BS", "BOOL":
+			// Binary values are already base64 encoded
+			var v interface{}
+			err := json.Unmarshal(value, &v)
+			return v, err
+		case "NULL":
+			return nil, nil
+		case "N":
+			var v string
+			err := json.Unmarshal(value, &v)
+			return json.Number(strings.TrimSpace(v)), err
+		case "NS":
+			var v []string
+			if err := json.Unmarshal(value, &v); err != nil {
+				return nil, err
+			}
+			values := make([]json.Number, 0, len(v))
+			for _, n := range v {
+				values = append(values, json.Number(n))
+			}
+			return values, nil
+		case "M":
+			var v map[string]json.RawMessage
+			if err := json.Unmarshal(value, &v); err != nil {
+				return nil, err
+			}
+			return fromJSONItem(v)
+		case "L":
+			var v []json.RawMessage
+			if err := json.Unmarshal(value, &v); err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, 0, len(v))
+			for _, e := range v {
+				converted, err := fromJSONAttribute(e)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, converted)
+			}
+			return values, nil
+		}
+		return nil, fmt.Errorf("unsupported attribute type %q", typ)
+	}
+	return nil, nil
+}
+
+// encode serializes the event for the parser. Binary values are encoded as
+// base64 strings by the JSON encoder.
+func (e *changeEvent) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
@@ -0,0 +1,212 @@
+package dynamodb_streams
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streams_types "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesis_types "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// errTrimmed is returned if the requested position in the shard is no longer
+// available due to the retention of the stream
+var errTrimmed = errors.New("position trimmed from shard")
+
+type shard struct {
+	id     string
+	parent string
+}
+
+// streamRecord is a record read from a shard. Records failing to convert are
+// returned with their error to not block the checkpoint of the shard.
+type streamRecord struct {
+	seq   string
+	event *changeEvent
+	err   error
+}
+
+// streamReader provides the shards and records of a DynamoDB Streams or a
+// Kinesis data stream
+type streamReader interface {
+	// name returns the identifier of the stream used for checkpoints
+	name() string
+
+	// listShards returns all shards of the stream including closed ones
+	listShards(ctx context.Context) ([]shard, error)
+
+	// iterator returns the shard iterator of the given type, the sequence
+	// number is only used for the AFTER_SEQUENCE_NUMBER type
+	iterator(ctx context.Context, shardID, iteratorType, seq string) (string, error)
+
+	// records returns the records at the iterator and the next iterator of
+	// the shard, which is empty if the shard is closed and all records are read
+	records(ctx context.Context, iterator string) ([]streamRecord, string, error)
+}
+
+type tableClient interface {
+	DescribeTable(context.Context, *dynamodb.DescribeTableInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+type streamsClient interface {
+	DescribeStream(context.Context, *dynamodbstreams.DescribeStreamInput, ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(context.Context, *dynamodbstreams.GetShardIteratorInput, ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(context.Context, *dynamodbstreams.GetRecordsInput, ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+type kinesisClient interface {
+	ListShards(context.Context, *kinesis.ListShardsInput, ...func(*kinesis.Options)) (*kinesis.ListShardsOutput, error)
+	GetShardIterator(context.Context, *kinesis.GetShardIteratorInput, ...func(*kinesis.Options)) (*kinesis.GetShardIteratorOutput, error)
+	GetRecords(context.Context, *kinesis.GetRecordsInput, ...func(*kinesis.Options)) (*kinesis.GetRecordsOutput, error)
+}
+
+// latestStreamARN returns the ARN of the current stream of the given table
+func latestStreamARN(ctx context.Context, client tableClient, table string) (string, error) {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return "", fmt.Errorf("describing table %q failed: %w", table, err)
+	}
+	if out.Table == nil || out.Table.LatestStreamArn == nil {
+		return "", fmt.Errorf("table %q has no stream enabled", table)
+	}
+	return *out.Table.LatestStreamArn, nil
+}
+
+// dynamodbReader reads the records of a DynamoDB stream
+type dynamodbReader struct {
+	client streamsClient
+	arn    string
+	table  string
+}
+
+func (r *dynamodbReader) name() string {
+	return r.arn
+}
+
+func (r *dynamodbReader) listShards(ctx context.Context) ([]shard, error) {
+	var shards []shard
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(r.arn)}
+	for {
+		out, err := r.client.DescribeStream(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("describing stream failed: %w", err)
+		}
+		if out.StreamDescription == nil {
+			return shards, nil
+		}
+		if out.StreamDescription.TableName != nil {
+			r.table = *out.StreamDescription.TableName
+		}
+		for _, s := range out.StreamDescription.Shards {
+			shards = append(shards, shard{id: aws.ToString(s.ShardId), parent: aws.ToString(s.ParentShardId)})
+		}
+		if out.StreamDescription.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+		input.ExclusiveStartShardId = out.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+func (r *dynamodbReader) iterator(ctx context.Context, shardID, iteratorType, seq string) (string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(r.arn),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: streams_types.ShardIteratorType(iteratorType),
+	}
+	if seq != "" {
+		input.SequenceNumber = aws.String(seq)
+	}
+	out, err := r.client.GetShardIterator(ctx, input)
+	if err != nil {
+		var trimmed *streams_types.TrimmedDataAccessException
+		if errors.As(err, &trimmed) {
+			return "", errTrimmed
+		}
+		return "", err
+	}
+	return aws.ToString(out.ShardIterator), nil
+}
+
+func (r *dynamodbReader) records(ctx context.Context, iterator string) ([]streamRecord, string, error) {
+	out, err := r.client.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: aws.String(iterator)})
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := make([]streamRecord, 0, len(out.Records))
+	for i := range out.Records {
+		rec := &out.Records[i]
+		var seq string
+		if rec.Dynamodb != nil {
+			seq = aws.ToString(rec.Dynamodb.SequenceNumber)
+		}
+		event, err := fromStreamRecord(rec, r.table)
+		records = append(records, streamRecord{seq: seq, event: event, err: err})
+	}
+	return records, aws.ToString(out.NextShardIterator), nil
+}
+
+// kinesisReader reads the records of a Kinesis data stream used as
+// destination of a DynamoDB table
+type kinesisReader struct {
+	client kinesisClient
+	stream string
+}
+
+func (r *kinesisReader) name() string {
+	return r.stream
+}
+
+func (r *kinesisReader) listShards(ctx context.Context) ([]shard, error) {
+	var shards []shard
+	input := &kinesis.ListShardsInput{StreamName: aws.String(r.stream)}
+	for {
+		out, err := r.client.ListShards(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("listing shards failed: %w", err)
+		}
+		for _, s := range out.Shards {
+			shards = append(shards, shard{id: aws.ToString(s.ShardId), parent: aws.ToString(s.ParentShardId)})
+		}
+		if out.NextToken == nil {
+			return shards, nil
+		}
+		// The stream name must not be set if continuing the listing
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+}
+
+func (r *kinesisReader) iterator(ctx context.Context, shardID, iteratorType, seq string) (string, error) {
+	input := &kinesis.GetShardIteratorInput{
+		StreamName:        aws.String(r.stream),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: kinesis_types.ShardIteratorType(iteratorType),
+	}
+	if seq != "" {
+		input.StartingSequenceNumber = aws.String(seq)
+	}
+	out, err := r.client.GetShardIterator(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.ShardIterator), nil
+}
+
+func (r *kinesisReader) records(ctx context.Context, iterator string) ([]streamRecord, string, error) {
+	out, err := r.client.GetRecords(ctx, &kinesis.GetRecordsInput{ShardIterator: aws.String(iterator)})
+	if err != nil {
+		return nil, "", err
+	}
+
+	records := make([]streamRecord, 0, len(out.Records))
+	for _, rec := range out.Records {
+		seq := aws.ToString(rec.SequenceNumber)
+		event, err := fromKinesisRecord(rec.Data, seq)
+		records = append(records, streamRecord{seq: seq, event: event, err: err})
+	}
+	return records, aws.ToString(out.NextShardIterator), nil
+}
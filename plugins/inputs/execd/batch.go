@@ -0,0 +1,184 @@
+package execd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Header announcing a batch of metrics as written by the execd shim, see
+// plugins/common/shim for details on the format
+const batchHeader = "#batch "
+
+type batchInfo struct {
+	id    uint64
+	stdin io.Writer
+}
+
+func (e *Execd) startBatching(acc telegraf.Accumulator) {
+	e.trackingAcc = acc.WithTracking(e.MaxUndeliveredBatches)
+	e.decoders = make(map[string]internal.ContentDecoder)
+	e.batches = make(map[telegraf.TrackingID]batchInfo)
+	e.delivered = make(map[telegraf.TrackingID]bool)
+	e.done = make(chan struct{})
+
+	e.wg.Add(1)
+	go e.handleDelivery()
+}
+
+func (e *Execd) cmdReadOutBatched(out io.Reader) {
+	// Remember the stdin of the process instance producing the batches to
+	// not acknowledge batches of a previous instance after a restart
+	stdin := e.process.Stdin
+	rdr := bufio.NewReaderSize(out, int(e.BufferSize))
+
+	for {
+		line, err := rdr.ReadBytes('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) {
+				break
+			}
+			e.acc.AddError(fmt.Errorf("error reading stdout: %w", err))
+			continue
+		}
+
+		if !strings.HasPrefix(string(line), batchHeader) {
+			// Unbatched data, e.g. from applications not using the shim
+			metrics, err := e.parser.Parse(line)
+			if err != nil {
+				e.acc.AddError(fmt.Errorf("parse error: %w", err))
+			}
+			for _, metric := range metrics {
+				e.acc.AddMetric(metric)
+			}
+			continue
+		}
+
+		id, encoding, length, err := parseBatchHeader(string(line))
+		if err != nil {
+			e.acc.AddError(err)
+			continue
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(rdr, payload); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				e.acc.AddError(fmt.Errorf("error reading batch %d: %w", id, err))
+			}
+			break
+		}
+
+		metrics, err := e.decodeBatch(encoding, payload)
+		if err != nil {
+			e.acc.AddError(fmt.Errorf("error decoding batch %d: %w", id, err))
+			e.acknowledge(batchInfo{id: id, stdin: stdin}, false)
+			continue
+		}
+		if len(metrics) == 0 {
+			e.acknowledge(batchInfo{id: id, stdin: stdin}, true)
+			continue
+		}
+
+		trackingID := e.trackingAcc.AddTrackingMetricGroup(metrics)
+		e.registerBatch(trackingID, batchInfo{id: id, stdin: stdin})
+	}
+}
+
+func (e *Execd) decodeBatch(encoding string, payload []byte) ([]telegraf.Metric, error) {
+	decoder, found := e.decoders[encoding]
+	if !found {
+		var err error
+		decoder, err = internal.NewContentDecoder(encoding)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding %q", encoding)
+		}
+		e.decoders[encoding] = decoder
+	}
+
+	data, err := decoder.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+	return e.parser.Parse(data)
+}
+
+// registerBatch remembers the batch until the delivery of the metrics is
+// reported. As this might happen before the batch is registered, check for
+// any early delivery reports first.
+func (e *Execd) registerBatch(trackingID telegraf.TrackingID, batch batchInfo) {
+	e.batchesLock.Lock()
+	delivered, found := e.delivered[trackingID]
+	if found {
+		delete(e.delivered, trackingID)
+	} else {
+		e.batches[trackingID] = batch
+	}
+	e.batchesLock.Unlock()
+
+	if found {
+		e.acknowledge(batch, delivered)
+	}
+}
+
+func (e *Execd) handleDelivery() {
+	defer e.wg.Done()
+	for {
+		select {
+		case <-e.done:
+			return
+		case info := <-e.trackingAcc.Delivered():
+			e.batchesLock.Lock()
+			batch, found := e.batches[info.ID()]
+			if found {
+				delete(e.batches, info.ID())
+			} else {
+				e.delivered[info.ID()] = info.Delivered()
+			}
+			e.batchesLock.Unlock()
+
+			if found {
+				e.acknowledge(batch, info.Delivered())
+			}
+		}
+	}
+}
+
+// acknowledge reports the delivery state of the batch back to the process
+func (e *Execd) acknowledge(batch batchInfo, delivered bool) {
+	verb := "ack"
+	if !delivered {
+		verb = "nack"
+	}
+
+	e.stdinLock.Lock()
+	defer e.stdinLock.Unlock()
+
+	// Skip batches of previous process instances
+	if batch.stdin != e.process.Stdin {
+		return
+	}
+	if _, err := fmt.Fprintf(batch.stdin, "%s %d\n", verb, batch.id); err != nil {
+		e.Log.Errorf("Acknowledging batch %d failed: %v", batch.id, err)
+	}
+}
+
+// parseBatchHeader parses the "#batch <id> <encoding> <length>" header line
+func parseBatchHeader(line string) (id uint64, encoding string, length int, err error) {
+	parts := strings.Fields(strings.TrimPrefix(line, batchHeader))
+	if len(parts) != 3 {
+		return 0, "", 0, fmt.Errorf("invalid batch header %q", strings.TrimSpace(line))
+	}
+	if id, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return 0, "", 0, fmt.Errorf("invalid batch id %q: %w", parts[0], err)
+	}
+	if length, err = strconv.Atoi(parts[2]); err != nil || length < 0 {
+		return 0, "", 0, fmt.Errorf("invalid batch length %q", parts[2])
+	}
+	return id, parts[1], length, nil
+}
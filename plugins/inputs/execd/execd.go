@@ -27,18 +27,29 @@ var sampleConfig string
 var once sync.Once
 
 type Execd struct {
-	Command      []string        `toml:"command"`
-	Environment  []string        `toml:"environment"`
-	BufferSize   config.Size     `toml:"buffer_size"`
-	Signal       string          `toml:"signal"`
-	RestartDelay config.Duration `toml:"restart_delay"`
-	StopOnError  bool            `toml:"stop_on_error"`
-	Log          telegraf.Logger `toml:"-"`
+	Command               []string        `toml:"command"`
+	Environment           []string        `toml:"environment"`
+	BufferSize            config.Size     `toml:"buffer_size"`
+	Signal                string          `toml:"signal"`
+	RestartDelay          config.Duration `toml:"restart_delay"`
+	StopOnError           bool            `toml:"stop_on_error"`
+	BatchFraming          bool            `toml:"batch_framing"`
+	MaxUndeliveredBatches int             `toml:"max_undelivered_batches"`
+	Log                   telegraf.Logger `toml:"-"`
 
 	process      *process.Process
 	acc          telegraf.Accumulator
 	parser       telegraf.Parser
 	outputReader func(io.Reader)
+
+	trackingAcc telegraf.TrackingAccumulator
+	decoders    map[string]internal.ContentDecoder
+	batches     map[telegraf.TrackingID]batchInfo
+	delivered   map[telegraf.TrackingID]bool
+	batchesLock sync.Mutex
+	stdinLock   sync.Mutex
+	done        chan struct{}
+	wg          sync.WaitGroup
 }
 
 func (*Execd) SampleConfig() string {
@@ -72,6 +83,10 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("error creating new process: %w", err)
 	}
 	e.process.ReadStdoutFn = e.outputReader
+	if e.BatchFraming {
+		e.startBatching(acc)
+		e.process.ReadStdoutFn = e.cmdReadOutBatched
+	}
 	e.process.ReadStderrFn = e.cmdReadErr
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.StopOnError = e.StopOnError
@@ -93,6 +108,10 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 
 func (e *Execd) Stop() {
 	e.process.Stop()
+	if e.done != nil {
+		close(e.done)
+		e.wg.Wait()
+	}
 }
 
 func (e *Execd) cmdReadOut(out io.Reader) {
@@ -178,9 +197,10 @@ func (e *Execd) cmdReadErr(out io.Reader) {
 func init() {
 	inputs.Add("execd", func() telegraf.Input {
 		return &Execd{
-			Signal:       "none",
-			RestartDelay: config.Duration(10 * time.Second),
-			BufferSize:   config.Size(64 * 1024),
+			Signal:                "none",
+			RestartDelay:          config.Duration(10 * time.Second),
+			BufferSize:            config.Size(64 * 1024),
+			MaxUndeliveredBatches: 100,
 		}
 	})
 }
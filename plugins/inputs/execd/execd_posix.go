@@ -29,6 +29,8 @@ func (e *Execd) Gather(_ telegraf.Accumulator) error {
 	case "SIGUSR2":
 		return osProcess.Signal(syscall.SIGUSR2)
 	case "STDIN":
+		e.stdinLock.Lock()
+		defer e.stdinLock.Unlock()
 		if osStdin, ok := e.process.Stdin.(*os.File); ok {
 			if err := osStdin.SetWriteDeadline(time.Now().Add(1 * time.Second)); err != nil {
 				return fmt.Errorf("setting write deadline failed: %w", err)
@@ -2,8 +2,10 @@ package execd
 
 import (
 	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -14,6 +16,8 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
@@ -457,3 +461,93 @@ func runLoggingProgram() error {
 	}
 	return nil
 }
+
+func TestBatchFraming(t *testing.T) {
+	parser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
+	require.NoError(t, parser.Init())
+
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinReader.Close()
+
+	var acc testutil.Accumulator
+	e := &Execd{
+		BatchFraming:          true,
+		MaxUndeliveredBatches: 10,
+		BufferSize:            config.Size(64 * 1024),
+		process:               &process.Process{Stdin: stdinWriter},
+		acc:                   &acc,
+		Log:                   testutil.Logger{},
+	}
+	e.SetParser(parser)
+	e.startBatching(&acc)
+	defer func() {
+		close(e.done)
+		e.wg.Wait()
+	}()
+
+	encoder, err := internal.NewGzipEncoder()
+	require.NoError(t, err)
+	compressed, err := encoder.Encode([]byte("cpu value=2i 1587128639239000000\ncpu value=3i 1587128639239000000\n"))
+	require.NoError(t, err)
+
+	acks := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stdinReader)
+		for scanner.Scan() {
+			acks <- scanner.Text()
+		}
+	}()
+	readAck := func() string {
+		select {
+		case ack := <-acks:
+			return ack
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for acknowledgement")
+		}
+		return ""
+	}
+
+	var stdout bytes.Buffer
+	stdout.WriteString("#batch 1 identity 33\ncpu value=1i 1587128639239000000\n")
+	fmt.Fprintf(&stdout, "#batch 2 gzip %d\n", len(compressed))
+	stdout.Write(compressed)
+	stdout.WriteString("mem value=4i 1587128639239000000\n")
+	stdout.WriteString("#batch 3 foo 0\n")
+	e.cmdReadOutBatched(&stdout)
+
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `unsupported encoding "foo"`)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 4)
+	values := make([]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		v, _ := m.GetField("value")
+		values = append(values, v)
+	}
+	require.Equal(t, []interface{}{int64(1), int64(2), int64(3), int64(4)}, values)
+
+	// The decoding error is reported immediately, the other batches only after
+	// the delivery of the contained metrics
+	require.Equal(t, "nack 3", readAck())
+
+	metrics[0].Accept()
+	require.Equal(t, "ack 1", readAck())
+
+	metrics[1].Accept()
+	metrics[2].Reject()
+	require.Equal(t, "nack 2", readAck())
+}
+
+func TestParseBatchHeader(t *testing.T) {
+	id, encoding, length, err := parseBatchHeader("#batch 42 zstd 1024\n")
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), id)
+	require.Equal(t, "zstd", encoding)
+	require.Equal(t, 1024, length)
+
+	for _, header := range []string{"#batch 42 zstd", "#batch x gzip 10", "#batch 1 gzip -1"} {
+		_, _, _, err := parseBatchHeader(header)
+		require.Error(t, err, header)
+	}
+}
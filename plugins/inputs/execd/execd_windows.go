@@ -19,6 +19,8 @@ func (e *Execd) Gather(_ telegraf.Accumulator) error {
 
 	switch e.Signal {
 	case "STDIN":
+		e.stdinLock.Lock()
+		defer e.stdinLock.Unlock()
 		if osStdin, ok := e.process.Stdin.(*os.File); ok {
 			if err := osStdin.SetWriteDeadline(time.Now().Add(1 * time.Second)); err != nil {
 				if !errors.Is(err, os.ErrNoDeadline) {
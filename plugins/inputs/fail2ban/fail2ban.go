@@ -8,8 +8,10 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -33,11 +35,16 @@ var (
 	}
 )
 
-const cmd = "fail2ban-client"
+const (
+	cmd           = "fail2ban-client"
+	defaultSocket = "/var/run/fail2ban/fail2ban.sock"
+)
 
 type Fail2ban struct {
-	UseSudo bool   `toml:"use_sudo"`
-	Socket  string `toml:"socket"`
+	Method  string          `toml:"method"`
+	UseSudo bool            `toml:"use_sudo"`
+	Socket  string          `toml:"socket"`
+	Timeout config.Duration `toml:"timeout"`
 	path    string
 }
 
@@ -47,6 +54,25 @@ func (*Fail2ban) SampleConfig() string {
 
 func (f *Fail2ban) Init() error {
 	// Set defaults
+	if f.Timeout <= 0 {
+		f.Timeout = config.Duration(5 * time.Second)
+	}
+
+	switch f.Method {
+	case "", "exec":
+		f.Method = "exec"
+	case "socket":
+		if f.UseSudo {
+			return errors.New("'use_sudo' is not supported for method 'socket'")
+		}
+		if f.Socket == "" {
+			f.Socket = defaultSocket
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid method %q", f.Method)
+	}
+
 	if f.path == "" {
 		path, err := exec.LookPath(cmd)
 		if err != nil {
@@ -64,6 +90,17 @@ func (f *Fail2ban) Init() error {
 }
 
 func (f *Fail2ban) Gather(acc telegraf.Accumulator) error {
+	if f.Method == "socket" {
+		jails, err := f.gatherSocket()
+		if err != nil {
+			return err
+		}
+		for jail, fields := range jails {
+			acc.AddFields("fail2ban", fields, map[string]string{"jail": jail})
+		}
+		return nil
+	}
+
 	if len(f.path) == 0 {
 		return errors.New("fail2ban-client not found: verify that fail2ban is installed and that fail2ban-client is in your PATH")
 	}
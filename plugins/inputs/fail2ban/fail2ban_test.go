@@ -1,14 +1,21 @@
 package fail2ban
 
 import (
+	"bytes"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -85,6 +92,117 @@ func TestGather(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "fail2ban", fields3, tags3)
 }
 
+func TestGatherSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows as unix sockets are not supported")
+	}
+
+	socket := filepath.Join(t.TempDir(), "fail2ban.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serveFakeSocket(t, conn)
+		}
+	}()
+
+	plugin := &Fail2ban{
+		Method: "socket",
+		Socket: socket,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"fail2ban",
+			map[string]string{"jail": "postfix"},
+			map[string]interface{}{"failed": 4, "banned": 3},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"fail2ban",
+			map[string]string{"jail": "sshd"},
+			map[string]interface{}{"failed": 0, "banned": 2},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Fail2ban{Method: "foo"}
+	require.ErrorContains(t, plugin.Init(), "invalid method")
+
+	plugin = &Fail2ban{Method: "socket", UseSudo: true}
+	require.ErrorContains(t, plugin.Init(), "not supported")
+}
+
+func TestDecodeResponse(t *testing.T) {
+	// pickle.dumps((1, -2, 1 << 40, None, True, 'ä', {'a': [1.5]}), 2)
+	data := []byte("\x80\x02(K\x01J\xfe\xff\xff\xff\x8a\x06\x00\x00\x00\x00\x00\x01N\x88X\x02\x00\x00\x00\xc3\xa4q\x00}q\x01" +
+		"X\x01\x00\x00\x00aq\x02]q\x03G?\xf8\x00\x00\x00\x00\x00\x00astq\x04.")
+	decoded, err := decodeResponse(data)
+	require.NoError(t, err)
+
+	expected := []interface{}{
+		int64(1),
+		int64(-2),
+		int64(1 << 40),
+		nil,
+		true,
+		"ä",
+		map[interface{}]interface{}{"a": []interface{}{1.5}},
+	}
+	require.Equal(t, expected, decoded)
+
+	_, err = decodeResponse([]byte("\x80\x02c__builtin__\nValueError\n."))
+	require.ErrorContains(t, err, "unsupported pickle opcode")
+}
+
+// serveFakeSocket answers the request on the connection with the pickled
+// response stored in the testdata directory
+func serveFakeSocket(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+
+	var request []byte
+	buf := make([]byte, 1024)
+	for !bytes.HasSuffix(request, endCommand) {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		request = append(request, buf[:n]...)
+	}
+	decoded, err := decodeResponse(bytes.TrimSuffix(request, endCommand))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var command []string
+	for _, arg := range decoded.([]interface{}) {
+		command = append(command, arg.(string))
+	}
+	response, err := os.ReadFile(filepath.Join("testdata", strings.Join(command, "_")+".pickle"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := conn.Write(append(response, endCommand...)); err != nil {
+		t.Error(err)
+	}
+}
+
 func fakeExecCommand(command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestHelperProcess", "--", command}
 	cs = append(cs, args...)
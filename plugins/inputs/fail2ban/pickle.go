@@ -0,0 +1,335 @@
+package fail2ban
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// Subset of the Python pickle opcodes used by the fail2ban protocol
+const (
+	opMark           = '('
+	opStop           = '.'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opBinFloat       = 'G'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opBinBytes       = 'B'
+	opShortBinBytes  = 'C'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opAppends        = 'e'
+	opEmptyDict      = '}'
+	opEmptyList      = ']'
+	opEmptyTuple     = ')'
+	opSetItem        = 's'
+	opSetItems       = 'u'
+	opTuple          = 't'
+	opBinGet         = 'h'
+	opLongBinGet     = 'j'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opProto          = 0x80
+	opTuple1         = 0x85
+	opTuple2         = 0x86
+	opTuple3         = 0x87
+	opNewTrue        = 0x88
+	opNewFalse       = 0x89
+	opLong1          = 0x8a
+	opShortBinUni    = 0x8c
+	opBinUnicode8    = 0x8d
+	opEmptySet       = 0x8f
+	opAddItems       = 0x90
+	opFrozenSet      = 0x91
+	opMemoize        = 0x94
+	opFrame          = 0x95
+)
+
+// mark is the marker object put on the stack by the MARK opcode
+type mark struct{}
+
+// encodeCommand pickles the given command as a list of strings using
+// protocol version 2
+func encodeCommand(command []string) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{opProto, 2, opEmptyList, opMark})
+	for _, arg := range command {
+		buf.WriteByte(opBinUnicode)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(arg)))
+		buf.WriteString(arg)
+	}
+	buf.Write([]byte{opAppends, opStop})
+	return buf.Bytes()
+}
+
+// decodeResponse unpickles the given data into Go values. Lists and tuples
+// are returned as []interface{}, dictionaries as map[interface{}]interface{},
+// integers as int64 and strings as string.
+func decodeResponse(data []byte) (interface{}, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+	var stack []interface{}
+	memo := make(map[uint32]interface{})
+
+	pop := func() (interface{}, error) {
+		if len(stack) == 0 {
+			return nil, errors.New("stack underflow")
+		}
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v, nil
+	}
+	popMark := func() ([]interface{}, error) {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if _, ok := stack[i].(mark); ok {
+				items := append([]interface{}(nil), stack[i+1:]...)
+				stack = stack[:i]
+				return items, nil
+			}
+		}
+		return nil, errors.New("mark not found")
+	}
+	readN := func(n uint64) ([]byte, error) {
+		if n > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid length %d", n)
+		}
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		return buf, err
+	}
+	readUint := func(size int) (uint64, error) {
+		buf, err := readN(uint64(size))
+		if err != nil {
+			return 0, err
+		}
+		var v uint64
+		for i := size - 1; i >= 0; i-- {
+			v = v<<8 | uint64(buf[i])
+		}
+		return v, nil
+	}
+
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading opcode failed: %w", err)
+		}
+
+		switch op {
+		case opStop:
+			return pop()
+		case opProto:
+			if _, err := r.ReadByte(); err != nil {
+				return nil, err
+			}
+		case opFrame:
+			if _, err := readN(8); err != nil {
+				return nil, err
+			}
+		case opMark:
+			stack = append(stack, mark{})
+		case opNone:
+			stack = append(stack, nil)
+		case opNewTrue:
+			stack = append(stack, true)
+		case opNewFalse:
+			stack = append(stack, false)
+		case opBinInt:
+			v, err := readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, int64(int32(uint32(v))))
+		case opBinInt1, opBinInt2:
+			size := 1
+			if op == opBinInt2 {
+				size = 2
+			}
+			v, err := readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, int64(v))
+		case opLong1:
+			n, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			buf, err := readN(uint64(n))
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeLong(buf)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opBinFloat:
+			buf, err := readN(8)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, math.Float64frombits(binary.BigEndian.Uint64(buf)))
+		case opShortBinString, opShortBinBytes, opShortBinUni:
+			n, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			buf, err := readN(uint64(n))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(buf))
+		case opBinString, opBinBytes, opBinUnicode:
+			n, err := readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			buf, err := readN(n)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(buf))
+		case opBinUnicode8:
+			n, err := readUint(8)
+			if err != nil {
+				return nil, err
+			}
+			buf, err := readN(n)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, string(buf))
+		case opEmptyList, opEmptyTuple:
+			stack = append(stack, []interface{}{})
+		case opEmptyDict:
+			stack = append(stack, make(map[interface{}]interface{}))
+		case opEmptySet:
+			stack = append(stack, []interface{}{})
+		case opTuple, opFrozenSet:
+			items, err := popMark()
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, items)
+		case opTuple1, opTuple2, opTuple3:
+			n := int(op-opTuple1) + 1
+			if len(stack) < n {
+				return nil, errors.New("stack underflow")
+			}
+			items := append([]interface{}(nil), stack[len(stack)-n:]...)
+			stack = append(stack[:len(stack)-n], items)
+		case opAppend:
+			v, err := pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := appendItems(stack, v); err != nil {
+				return nil, err
+			}
+		case opAppends, opAddItems:
+			items, err := popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := appendItems(stack, items...); err != nil {
+				return nil, err
+			}
+		case opSetItem, opSetItems:
+			var items []interface{}
+			if op == opSetItem {
+				if len(stack) < 2 {
+					return nil, errors.New("stack underflow")
+				}
+				items = append(items, stack[len(stack)-2:]...)
+				stack = stack[:len(stack)-2]
+			} else if items, err = popMark(); err != nil {
+				return nil, err
+			}
+			if len(stack) == 0 || len(items)%2 != 0 {
+				return nil, errors.New("invalid dictionary items")
+			}
+			dict, ok := stack[len(stack)-1].(map[interface{}]interface{})
+			if !ok {
+				return nil, errors.New("setting items on non-dictionary")
+			}
+			for i := 0; i < len(items); i += 2 {
+				dict[items[i]] = items[i+1]
+			}
+		case opMemoize:
+			if len(stack) == 0 {
+				return nil, errors.New("stack underflow")
+			}
+			memo[uint32(len(memo))] = stack[len(stack)-1]
+		case opBinPut, opLongBinPut:
+			size := 1
+			if op == opLongBinPut {
+				size = 4
+			}
+			idx, err := readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			if len(stack) == 0 {
+				return nil, errors.New("stack underflow")
+			}
+			memo[uint32(idx)] = stack[len(stack)-1]
+		case opBinGet, opLongBinGet:
+			size := 1
+			if op == opLongBinGet {
+				size = 4
+			}
+			idx, err := readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			v, found := memo[uint32(idx)]
+			if !found {
+				return nil, fmt.Errorf("memo entry %d not found", idx)
+			}
+			stack = append(stack, v)
+		default:
+			return nil, fmt.Errorf("unsupported pickle opcode 0x%02x", op)
+		}
+	}
+}
+
+// appendItems appends the items to the list on top of the stack. As lists
+// are stored as slices, the stack entry is updated in place.
+func appendItems(stack []interface{}, items ...interface{}) error {
+	if len(stack) == 0 {
+		return errors.New("stack underflow")
+	}
+	list, ok := stack[len(stack)-1].([]interface{})
+	if !ok {
+		return errors.New("appending to non-list")
+	}
+	stack[len(stack)-1] = append(list, items...)
+	return nil
+}
+
+// decodeLong decodes a little-endian two's complement integer
+func decodeLong(buf []byte) (int64, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	be := make([]byte, len(buf))
+	for i, b := range buf {
+		be[len(buf)-1-i] = b
+	}
+	v := new(big.Int).SetBytes(be)
+	if buf[len(buf)-1]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(buf)*8)))
+	}
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("integer %v out of range", v)
+	}
+	return v.Int64(), nil
+}
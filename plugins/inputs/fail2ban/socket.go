@@ -0,0 +1,126 @@
+package fail2ban
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Marker terminating requests and responses on the fail2ban socket
+var endCommand = []byte("<F2B_END_COMMAND>")
+
+// query sends the command to the fail2ban server via its unix socket and
+// returns the decoded result value
+func query(socket string, timeout time.Duration, command ...string) (interface{}, error) {
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to socket failed: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, fmt.Errorf("setting deadline failed: %w", err)
+	}
+
+	request := append(encodeCommand(command), endCommand...)
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("sending command failed: %w", err)
+	}
+
+	var response []byte
+	buf := make([]byte, 4096)
+	for !bytes.HasSuffix(response, endCommand) {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("reading response failed: %w", err)
+		}
+		response = append(response, buf[:n]...)
+	}
+	response = bytes.TrimSuffix(response, endCommand)
+
+	decoded, err := decodeResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+
+	// The server replies with a (code, value) tuple where a non-zero code
+	// signals an error
+	result, ok := decoded.([]interface{})
+	if !ok || len(result) != 2 {
+		return nil, fmt.Errorf("unexpected response %v", decoded)
+	}
+	if code, ok := result[0].(int64); !ok || code != 0 {
+		return nil, fmt.Errorf("command %q failed: %v", strings.Join(command, " "), result[1])
+	}
+	return result[1], nil
+}
+
+// lookup returns the value for the given key in a list of (key, value)
+// pairs as returned by the status commands
+func lookup(value interface{}, key string) (interface{}, bool) {
+	pairs, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, p := range pairs {
+		pair, ok := p.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		if k, ok := pair[0].(string); ok && k == key {
+			return pair[1], true
+		}
+	}
+	return nil, false
+}
+
+func (f *Fail2ban) gatherSocket() (map[string]map[string]interface{}, error) {
+	timeout := time.Duration(f.Timeout)
+
+	status, err := query(f.Socket, timeout, "status")
+	if err != nil {
+		return nil, err
+	}
+	raw, found := lookup(status, "Jail list")
+	if !found {
+		return nil, errors.New("jail list not found in status")
+	}
+	list, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected jail list %v", raw)
+	}
+
+	jails := make(map[string]map[string]interface{})
+	for _, jail := range strings.Split(list, ",") {
+		jail = strings.TrimSpace(jail)
+		if jail == "" {
+			continue
+		}
+
+		status, err := query(f.Socket, timeout, "status", jail)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]interface{})
+		for _, section := range []string{"Filter", "Actions"} {
+			values, found := lookup(status, section)
+			if !found {
+				continue
+			}
+			for _, target := range metricsTargets {
+				key := strings.TrimSuffix(target.target, ":")
+				if v, found := lookup(values, key); found {
+					if n, ok := v.(int64); ok {
+						fields[target.field] = int(n)
+					}
+				}
+			}
+		}
+		jails[jail] = fields
+	}
+	return jails, nil
+}
@@ -0,0 +1,331 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package firehose_http
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"crypto/tls"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Firehose limits the size of a request to 64 MiB before compression
+const defaultMaxBodySize = 64 * 1024 * 1024
+
+// Headers set by Firehose, see
+// https://docs.aws.amazon.com/firehose/latest/dev/httpdeliveryrequestresponse.html
+const (
+	headerRequestID        = "X-Amz-Firehose-Request-Id"
+	headerAccessKey        = "X-Amz-Firehose-Access-Key"
+	headerCommonAttributes = "X-Amz-Firehose-Common-Attributes"
+)
+
+type FirehoseHTTP struct {
+	ServiceAddress string          `toml:"service_address"`
+	Paths          []string        `toml:"paths"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	WriteTimeout   config.Duration `toml:"write_timeout"`
+	MaxBodySize    config.Size     `toml:"max_body_size"`
+	AccessKey      config.Secret   `toml:"access_key"`
+	ParameterTags  []string        `toml:"parameter_tags"`
+	Log            telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	parser   telegraf.Parser
+	acc      telegraf.Accumulator
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	requestsReceived selfstat.Stat
+	recordsReceived  selfstat.Stat
+}
+
+// Request envelope sent by Firehose
+type request struct {
+	RequestID string `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	Records   []struct {
+		Data string `json:"data"`
+	} `json:"records"`
+}
+
+// Response expected by Firehose, the error message is only set for failures
+type response struct {
+	RequestID    string `json:"requestId"`
+	Timestamp    int64  `json:"timestamp"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// Request parameters configured for the Firehose stream
+type commonAttributes struct {
+	CommonAttributes map[string]string `json:"commonAttributes"`
+}
+
+func (*FirehoseHTTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FirehoseHTTP) Init() error {
+	if f.ServiceAddress == "" {
+		f.ServiceAddress = ":443"
+	}
+	if len(f.Paths) == 0 {
+		f.Paths = []string{"/telegraf"}
+	}
+	if f.MaxBodySize == 0 {
+		f.MaxBodySize = config.Size(defaultMaxBodySize)
+	}
+	if f.ReadTimeout < config.Duration(time.Second) {
+		f.ReadTimeout = config.Duration(10 * time.Second)
+	}
+	if f.WriteTimeout < config.Duration(time.Second) {
+		f.WriteTimeout = config.Duration(10 * time.Second)
+	}
+
+	tags := map[string]string{"address": f.ServiceAddress}
+	f.requestsReceived = selfstat.Register("firehose_http", "requests_received", tags)
+	f.recordsReceived = selfstat.Register("firehose_http", "records_received", tags)
+
+	return nil
+}
+
+func (f *FirehoseHTTP) SetParser(parser telegraf.Parser) {
+	f.parser = parser
+}
+
+func (f *FirehoseHTTP) Start(acc telegraf.Accumulator) error {
+	f.acc = acc
+
+	tlsConf, err := f.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConf != nil {
+		f.listener, err = tls.Listen("tcp", f.ServiceAddress, tlsConf)
+	} else {
+		f.listener, err = net.Listen("tcp", f.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:      f,
+		ReadTimeout:  time.Duration(f.ReadTimeout),
+		WriteTimeout: time.Duration(f.WriteTimeout),
+		TLSConfig:    tlsConf,
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		if err := server.Serve(f.listener); err != nil && !errors.Is(err, net.ErrClosed) {
+			f.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+
+	f.Log.Infof("Listening on %s", f.listener.Addr().String())
+
+	return nil
+}
+
+func (*FirehoseHTTP) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (f *FirehoseHTTP) Stop() {
+	if f.listener != nil {
+		f.listener.Close()
+	}
+	f.wg.Wait()
+}
+
+func (f *FirehoseHTTP) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	f.requestsReceived.Incr(1)
+
+	requestID := req.Header.Get(headerRequestID)
+	if !slices.Contains(f.Paths, req.URL.Path) {
+		f.fail(res, requestID, http.StatusNotFound, "not found")
+		return
+	}
+	if req.Method != http.MethodPost {
+		f.fail(res, requestID, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if ok, err := f.authenticate(req); err != nil {
+		f.Log.Errorf("Getting access key failed: %v", err)
+		f.fail(res, requestID, http.StatusInternalServerError, "internal error")
+		return
+	} else if !ok {
+		f.fail(res, requestID, http.StatusUnauthorized, "invalid access key")
+		return
+	}
+	if req.ContentLength > int64(f.MaxBodySize) {
+		f.fail(res, requestID, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+
+	metrics, status, err := f.decode(res, req, requestID)
+	if err != nil {
+		f.Log.Errorf("Processing request %q failed: %v", requestID, err)
+		f.fail(res, requestID, status, err.Error())
+		return
+	}
+
+	for _, m := range metrics {
+		f.acc.AddMetric(m)
+	}
+
+	f.respond(res, http.StatusOK, response{RequestID: requestID, Timestamp: time.Now().UnixMilli()})
+}
+
+func (f *FirehoseHTTP) authenticate(req *http.Request) (bool, error) {
+	if f.AccessKey.Empty() {
+		return true, nil
+	}
+
+	key, err := f.AccessKey.Get()
+	if err != nil {
+		return false, err
+	}
+	defer key.Destroy()
+
+	provided := []byte(req.Header.Get(headerAccessKey))
+	return subtle.ConstantTimeCompare(provided, key.Bytes()) == 1, nil
+}
+
+// decode parses all records of the request and returns the resulting metrics
+// or the HTTP status code to respond with in case of an error. The request is
+// rejected as a whole to avoid duplicate metrics as Firehose retries failed
+// requests including all records.
+func (f *FirehoseHTTP) decode(res http.ResponseWriter, req *http.Request, requestID string) ([]telegraf.Metric, int, error) {
+	body := http.MaxBytesReader(res, req.Body, int64(f.MaxBodySize))
+	defer body.Close()
+
+	var reader io.Reader = body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		r, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("decompressing body failed: %w", err)
+		}
+		defer r.Close()
+		reader = io.LimitReader(r, int64(f.MaxBodySize))
+	}
+
+	var r request
+	if err := json.NewDecoder(reader).Decode(&r); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, http.StatusRequestEntityTooLarge, errors.New("request body too large")
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("decoding request failed: %w", err)
+	}
+	if r.RequestID != requestID {
+		return nil, http.StatusBadRequest, fmt.Errorf("request ID %q does not match header", r.RequestID)
+	}
+
+	tags, err := f.parameterTags(req)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(r.Records))
+	for i, record := range r.Records {
+		data, err := base64.StdEncoding.DecodeString(record.Data)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("decoding record %d failed: %w", i, err)
+		}
+		parsed, err := f.parser.Parse(data)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("parsing record %d failed: %w", i, err)
+		}
+		for _, m := range parsed {
+			for k, v := range tags {
+				m.AddTag(k, v)
+			}
+		}
+		metrics = append(metrics, parsed...)
+	}
+	f.recordsReceived.Incr(int64(len(r.Records)))
+
+	return metrics, http.StatusOK, nil
+}
+
+func (f *FirehoseHTTP) parameterTags(req *http.Request) (map[string]string, error) {
+	header := req.Header.Get(headerCommonAttributes)
+	if len(f.ParameterTags) == 0 || header == "" {
+		return nil, nil
+	}
+
+	var attributes commonAttributes
+	if err := json.Unmarshal([]byte(header), &attributes); err != nil {
+		return nil, fmt.Errorf("decoding request parameters failed: %w", err)
+	}
+
+	if slices.Contains(f.ParameterTags, "*") {
+		return attributes.CommonAttributes, nil
+	}
+	tags := make(map[string]string, len(f.ParameterTags))
+	for _, k := range f.ParameterTags {
+		if v, found := attributes.CommonAttributes[k]; found {
+			tags[k] = v
+		}
+	}
+	return tags, nil
+}
+
+func (f *FirehoseHTTP) fail(res http.ResponseWriter, requestID string, status int, msg string) {
+	selfstat.Register("firehose_http", "bad_requests", map[string]string{
+		"address":     f.ServiceAddress,
+		"status_code": strconv.Itoa(status),
+	}).Incr(1)
+
+	f.respond(res, status, response{
+		RequestID:    requestID,
+		Timestamp:    time.Now().UnixMilli(),
+		ErrorMessage: msg,
+	})
+}
+
+func (f *FirehoseHTTP) respond(res http.ResponseWriter, status int, r response) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		f.Log.Errorf("Encoding response failed: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	if _, err := res.Write(body); err != nil {
+		f.Log.Debugf("Writing response failed: %v", err)
+	}
+}
+
+func init() {
+	inputs.Add("firehose_http", func() telegraf.Input {
+		return &FirehoseHTTP{
+			ServiceAddress: ":443",
+			Paths:          []string{"/telegraf"},
+		}
+	})
+}
@@ -0,0 +1,342 @@
+package firehose_http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newRequest(t *testing.T, id string, records ...string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/telegraf", bytes.NewReader(newBody(t, id, records...)))
+	req.Header.Set(headerRequestID, id)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func newBody(t *testing.T, id string, records ...string) []byte {
+	t.Helper()
+
+	r := request{RequestID: id, Timestamp: time.Now().UnixMilli()}
+	for _, record := range records {
+		r.Records = append(r.Records, struct {
+			Data string `json:"data"`
+		}{Data: base64.StdEncoding.EncodeToString([]byte(record))})
+	}
+	body, err := json.Marshal(r)
+	require.NoError(t, err)
+	return body
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) response {
+	t.Helper()
+
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var r response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+	require.NotZero(t, r.Timestamp)
+	return r
+}
+
+func TestWrite(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &FirehoseHTTP{
+		ServiceAddress: "localhost:0",
+		Log:            testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.acc = acc
+
+	req := newRequest(t, "ed4acda5-034f-9f42-bba1-f29aea6d7d8f",
+		"cpu,host=a usage=1 1700000000000000000\n",
+		"cpu,host=b usage=2 1700000000000000000\nmem,host=b used=3i 1700000000000000000\n",
+	)
+	rec := httptest.NewRecorder()
+	plugin.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	resp := decodeResponse(t, rec)
+	require.Equal(t, "ed4acda5-034f-9f42-bba1-f29aea6d7d8f", resp.RequestID)
+	require.Empty(t, resp.ErrorMessage)
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage": 2.0}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"used": int64(3)}, time.Unix(1700000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestWriteGzip(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &FirehoseHTTP{
+		ServiceAddress: "localhost:0",
+		Log:            testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.acc = acc
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(newBody(t, "42", "cpu usage=1 1700000000000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/telegraf", &buf)
+	req.Header.Set(headerRequestID, "42")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	plugin.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestParameterTags(t *testing.T) {
+	attributes := `{"commonAttributes":{"env":"prod","team":"observability"}}`
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected map[string]string
+	}{
+		{
+			name:     "none",
+			expected: map[string]string{},
+		},
+		{
+			name:     "selected",
+			keys:     []string{"env", "missing"},
+			expected: map[string]string{"env": "prod"},
+		},
+		{
+			name:     "all",
+			keys:     []string{"*"},
+			expected: map[string]string{"env": "prod", "team": "observability"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &testutil.Accumulator{}
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+
+			plugin := &FirehoseHTTP{
+				ServiceAddress: "localhost:0",
+				Log:            testutil.Logger{},
+			}
+			plugin.SetParser(parser)
+			require.NoError(t, plugin.Init())
+			plugin.acc = acc
+			plugin.ParameterTags = tt.keys
+
+			req := newRequest(t, "42", "cpu usage=1 1700000000000000000\n")
+			req.Header.Set(headerCommonAttributes, attributes)
+			rec := httptest.NewRecorder()
+			plugin.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, 1)
+			require.Equal(t, tt.expected, metrics[0].Tags())
+		})
+	}
+}
+
+func TestAccessKey(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &FirehoseHTTP{
+		ServiceAddress: "localhost:0",
+		Log:            testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.acc = acc
+	plugin.AccessKey = config.NewSecret([]byte("super-secret"))
+
+	// Missing key
+	req := newRequest(t, "1", "cpu usage=1\n")
+	rec := httptest.NewRecorder()
+	plugin.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	resp := decodeResponse(t, rec)
+	require.Equal(t, "1", resp.RequestID)
+	require.Equal(t, "invalid access key", resp.ErrorMessage)
+
+	// Wrong key
+	req = newRequest(t, "2", "cpu usage=1\n")
+	req.Header.Set(headerAccessKey, "super-secre")
+	rec = httptest.NewRecorder()
+	plugin.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Correct key
+	req = newRequest(t, "3", "cpu usage=1\n")
+	req.Header.Set(headerAccessKey, "super-secret")
+	rec = httptest.NewRecorder()
+	plugin.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func(t *testing.T) *http.Request
+		status  int
+		message string
+	}{
+		{
+			name: "wrong path",
+			request: func(t *testing.T) *http.Request {
+				req := newRequest(t, "42", "cpu usage=1\n")
+				req.URL.Path = "/other"
+				return req
+			},
+			status:  http.StatusNotFound,
+			message: "not found",
+		},
+		{
+			name: "wrong method",
+			request: func(t *testing.T) *http.Request {
+				req := newRequest(t, "42", "cpu usage=1\n")
+				req.Method = http.MethodPut
+				return req
+			},
+			status:  http.StatusMethodNotAllowed,
+			message: "method not allowed",
+		},
+		{
+			name: "invalid json",
+			request: func(*testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/telegraf", strings.NewReader("{"))
+				req.Header.Set(headerRequestID, "42")
+				return req
+			},
+			status:  http.StatusBadRequest,
+			message: "decoding request failed",
+		},
+		{
+			name: "request id mismatch",
+			request: func(t *testing.T) *http.Request {
+				req := newRequest(t, "41", "cpu usage=1\n")
+				req.Header.Set(headerRequestID, "42")
+				return req
+			},
+			status:  http.StatusBadRequest,
+			message: `request ID "41" does not match header`,
+		},
+		{
+			name: "invalid base64",
+			request: func(*testing.T) *http.Request {
+				body := `{"requestId":"42","timestamp":1,"records":[{"data":"!!"}]}`
+				req := httptest.NewRequest(http.MethodPost, "/telegraf", strings.NewReader(body))
+				req.Header.Set(headerRequestID, "42")
+				return req
+			},
+			status:  http.StatusBadRequest,
+			message: "decoding record 0 failed",
+		},
+		{
+			name: "unparsable record",
+			request: func(t *testing.T) *http.Request {
+				return newRequest(t, "42", "cpu usage=1\n", "not line protocol")
+			},
+			status:  http.StatusBadRequest,
+			message: "parsing record 1 failed",
+		},
+		{
+			name: "too large",
+			request: func(t *testing.T) *http.Request {
+				return newRequest(t, "42", strings.Repeat("cpu usage=1\n", 100))
+			},
+			status:  http.StatusRequestEntityTooLarge,
+			message: "request body too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := &testutil.Accumulator{}
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+
+			plugin := &FirehoseHTTP{
+				ServiceAddress: "localhost:0",
+				Log:            testutil.Logger{},
+			}
+			plugin.SetParser(parser)
+			require.NoError(t, plugin.Init())
+			plugin.acc = acc
+			plugin.MaxBodySize = config.Size(1024)
+
+			rec := httptest.NewRecorder()
+			plugin.ServeHTTP(rec, tt.request(t))
+			require.Equal(t, tt.status, rec.Code)
+
+			resp := decodeResponse(t, rec)
+			require.Equal(t, "42", resp.RequestID)
+			require.Contains(t, resp.ErrorMessage, tt.message)
+
+			// Requests are rejected as a whole
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &FirehoseHTTP{
+		ServiceAddress: "localhost:0",
+		Log:            testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.acc = acc
+	require.NoError(t, plugin.Start(acc))
+	defer plugin.Stop()
+
+	req := newRequest(t, "42", "cpu usage=1 1700000000000000000\n")
+	req.RequestURI = ""
+	req.URL.Scheme = "http"
+	req.URL.Host = plugin.listener.Addr().String()
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package firewalld
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	destination   = "org.fedoraproject.FirewallD1"
+	objectPath    = "/org/fedoraproject/FirewallD1"
+	interfaceMain = "org.fedoraproject.FirewallD1"
+	interfaceZone = "org.fedoraproject.FirewallD1.zone"
+)
+
+type client interface {
+	defaultZone() (string, error)
+	activeZones() ([]string, error)
+	zoneSettings(zone string) (map[string]dbus.Variant, error)
+	panicMode() (bool, error)
+	close() error
+}
+
+type dbusClient struct {
+	conn    *dbus.Conn
+	obj     dbus.BusObject
+	timeout time.Duration
+}
+
+func newDBusClient(timeout time.Duration) (*dbusClient, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to system bus failed: %w", err)
+	}
+	return &dbusClient{
+		conn:    conn,
+		obj:     conn.Object(destination, objectPath),
+		timeout: timeout,
+	}, nil
+}
+
+func (c *dbusClient) call(method string, result interface{}, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.obj.CallWithContext(ctx, method, 0, args...).Store(result); err != nil {
+		return fmt.Errorf("calling %q failed: %w", method, err)
+	}
+	return nil
+}
+
+func (c *dbusClient) defaultZone() (string, error) {
+	var zone string
+	err := c.call(interfaceMain+".getDefaultZone", &zone)
+	return zone, err
+}
+
+func (c *dbusClient) activeZones() ([]string, error) {
+	var active map[string]map[string][]string
+	if err := c.call(interfaceZone+".getActiveZones", &active); err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0, len(active))
+	for zone := range active {
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+func (c *dbusClient) zoneSettings(zone string) (map[string]dbus.Variant, error) {
+	var settings map[string]dbus.Variant
+	err := c.call(interfaceZone+".getZoneSettings2", &settings, zone)
+	return settings, err
+}
+
+func (c *dbusClient) panicMode() (bool, error) {
+	var enabled bool
+	err := c.call(interfaceMain+".queryPanicMode", &enabled)
+	return enabled, err
+}
+
+func (c *dbusClient) close() error {
+	return c.conn.Close()
+}
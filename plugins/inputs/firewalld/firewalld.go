@@ -0,0 +1,168 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package firewalld
+
+import (
+	_ "embed"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Zone settings reported as the number of entries
+var countedSettings = []string{
+	"interfaces",
+	"sources",
+	"services",
+	"ports",
+	"protocols",
+	"source_ports",
+	"forward_ports",
+	"icmp_blocks",
+	"rich_rules",
+}
+
+// Zone settings reported as boolean flags
+var flagSettings = []string{
+	"masquerade",
+	"forward",
+	"icmp_block_inversion",
+}
+
+type Firewalld struct {
+	Zones   []string        `toml:"zones"`
+	Timeout config.Duration `toml:"timeout"`
+	Log     telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	client client
+}
+
+func (*Firewalld) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Firewalld) Init() error {
+	if len(f.Zones) == 0 {
+		f.Zones = []string{"*"}
+	}
+	if f.Timeout <= 0 {
+		f.Timeout = config.Duration(5 * time.Second)
+	}
+
+	zf, err := filter.Compile(f.Zones)
+	if err != nil {
+		return fmt.Errorf("creating zone filter failed: %w", err)
+	}
+	f.filter = zf
+
+	return nil
+}
+
+func (f *Firewalld) Start(telegraf.Accumulator) error {
+	if f.client != nil {
+		return nil
+	}
+
+	c, err := newDBusClient(time.Duration(f.Timeout))
+	if err != nil {
+		return err
+	}
+	f.client = c
+
+	return nil
+}
+
+func (f *Firewalld) Gather(acc telegraf.Accumulator) error {
+	panicMode, err := f.client.panicMode()
+	if err != nil {
+		return err
+	}
+	defaultZone, err := f.client.defaultZone()
+	if err != nil {
+		return err
+	}
+	active, err := f.client.activeZones()
+	if err != nil {
+		return err
+	}
+	acc.AddFields("firewalld", map[string]interface{}{
+		"panic_mode":   panicMode,
+		"active_zones": len(active),
+	}, nil)
+
+	// The default zone applies to all traffic not matching any other zone
+	// so it is always considered
+	zones := active
+	if defaultZone != "" && !slices.Contains(zones, defaultZone) {
+		zones = append(zones, defaultZone)
+	}
+	slices.Sort(zones)
+
+	for _, zone := range zones {
+		if !f.filter.Match(zone) {
+			continue
+		}
+
+		settings, err := f.client.zoneSettings(zone)
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering zone %q failed: %w", zone, err))
+			continue
+		}
+
+		tags := map[string]string{"zone": zone}
+		if v, found := settings["target"]; found {
+			if target, ok := v.Value().(string); ok {
+				tags["target"] = target
+			}
+		}
+
+		fields := map[string]interface{}{
+			"default": zone == defaultZone,
+		}
+		for _, key := range countedSettings {
+			fields[key] = 0
+			if v, found := settings[key]; found {
+				if rv := reflect.ValueOf(v.Value()); rv.Kind() == reflect.Slice {
+					fields[key] = rv.Len()
+				}
+			}
+		}
+		for _, key := range flagSettings {
+			if v, found := settings[key]; found {
+				if b, ok := v.Value().(bool); ok {
+					fields[key] = b
+				}
+			}
+		}
+		acc.AddFields("firewalld_zone", fields, tags)
+	}
+
+	return nil
+}
+
+func (f *Firewalld) Stop() {
+	if f.client == nil {
+		return
+	}
+	if err := f.client.close(); err != nil {
+		f.Log.Errorf("Closing connection failed: %v", err)
+	}
+	f.client = nil
+}
+
+func init() {
+	inputs.Add("firewalld", func() telegraf.Input {
+		return &Firewalld{}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package firewalld
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Firewalld struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*Firewalld) SampleConfig() string { return sampleConfig }
+
+func (n *Firewalld) Init() error {
+	n.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Firewalld) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("firewalld", func() telegraf.Input {
+		return &Firewalld{}
+	})
+}
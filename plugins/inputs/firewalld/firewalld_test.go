@@ -0,0 +1,157 @@
+//go:build linux
+
+package firewalld
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockClient struct {
+	defaultZoneName string
+	active          []string
+	settings        map[string]map[string]dbus.Variant
+	panic           bool
+}
+
+func (m *mockClient) defaultZone() (string, error) {
+	return m.defaultZoneName, nil
+}
+
+func (m *mockClient) activeZones() ([]string, error) {
+	return m.active, nil
+}
+
+func (m *mockClient) zoneSettings(zone string) (map[string]dbus.Variant, error) {
+	s, found := m.settings[zone]
+	if !found {
+		return nil, errors.New("invalid zone")
+	}
+	return s, nil
+}
+
+func (m *mockClient) panicMode() (bool, error) {
+	return m.panic, nil
+}
+
+func (*mockClient) close() error {
+	return nil
+}
+
+func TestGather(t *testing.T) {
+	client := &mockClient{
+		defaultZoneName: "public",
+		active:          []string{"trusted"},
+		settings: map[string]map[string]dbus.Variant{
+			"public": {
+				"target":      dbus.MakeVariant("default"),
+				"services":    dbus.MakeVariant([]string{"ssh", "dhcpv6-client", "cockpit"}),
+				"ports":       dbus.MakeVariant([][]interface{}{{"8080", "tcp"}}),
+				"interfaces":  dbus.MakeVariant([]string{"eth0"}),
+				"rich_rules":  dbus.MakeVariant([]string{`rule family="ipv4" source address="10.0.0.1" drop`}),
+				"masquerade":  dbus.MakeVariant(false),
+				"forward":     dbus.MakeVariant(true),
+				"icmp_blocks": dbus.MakeVariant([]string{}),
+			},
+			"trusted": {
+				"target":     dbus.MakeVariant("ACCEPT"),
+				"sources":    dbus.MakeVariant([]string{"192.168.1.0/24", "192.168.2.0/24"}),
+				"masquerade": dbus.MakeVariant(true),
+			},
+		},
+	}
+
+	plugin := &Firewalld{
+		Log:    testutil.Logger{},
+		client: client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"firewalld",
+			map[string]string{},
+			map[string]interface{}{
+				"panic_mode":   false,
+				"active_zones": 1,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewalld_zone",
+			map[string]string{"zone": "public", "target": "default"},
+			map[string]interface{}{
+				"default":       true,
+				"interfaces":    1,
+				"sources":       0,
+				"services":      3,
+				"ports":         1,
+				"protocols":     0,
+				"source_ports":  0,
+				"forward_ports": 0,
+				"icmp_blocks":   0,
+				"rich_rules":    1,
+				"masquerade":    false,
+				"forward":       true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"firewalld_zone",
+			map[string]string{"zone": "trusted", "target": "ACCEPT"},
+			map[string]interface{}{
+				"default":       false,
+				"interfaces":    0,
+				"sources":       2,
+				"services":      0,
+				"ports":         0,
+				"protocols":     0,
+				"source_ports":  0,
+				"forward_ports": 0,
+				"icmp_blocks":   0,
+				"rich_rules":    0,
+				"masquerade":    true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherZoneFilter(t *testing.T) {
+	client := &mockClient{
+		defaultZoneName: "public",
+		active:          []string{"public", "trusted"},
+		settings: map[string]map[string]dbus.Variant{
+			"public": {"target": dbus.MakeVariant("default")},
+		},
+	}
+
+	plugin := &Firewalld{
+		Zones:  []string{"pub*"},
+		Log:    testutil.Logger{},
+		client: client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.True(t, acc.HasTag("firewalld_zone", "zone"))
+	require.Equal(t, "public", acc.TagValue("firewalld_zone", "zone"))
+}
@@ -0,0 +1,175 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package gpfs
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Requests sent to mmpmon for gathering the per-filesystem and per-node
+// I/O statistics
+const requests = "fs_io_s\nio_s\n"
+
+// Mapping of the mmpmon keys to field names
+var counters = map[string]string{
+	"_br_":  "bytes_read",
+	"_bw_":  "bytes_written",
+	"_oc_":  "opens",
+	"_cc_":  "closes",
+	"_rdc_": "reads",
+	"_wc_":  "writes",
+	"_dir_": "readdirs",
+	"_iu_":  "inode_updates",
+}
+
+type GPFS struct {
+	Binary  string          `toml:"binary"`
+	UseSudo bool            `toml:"use_sudo"`
+	Timeout config.Duration `toml:"timeout"`
+	Log     telegraf.Logger `toml:"-"`
+
+	run func(input string) ([]byte, error)
+}
+
+func (*GPFS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GPFS) Init() error {
+	if g.Binary == "" {
+		g.Binary = "/usr/lpp/mmfs/bin/mmpmon"
+	}
+	if g.Timeout <= 0 {
+		g.Timeout = config.Duration(5 * time.Second)
+	}
+	g.run = g.mmpmon
+
+	return nil
+}
+
+func (g *GPFS) Gather(acc telegraf.Accumulator) error {
+	out, err := g.run(requests)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := g.parseLine(acc, line); err != nil {
+			acc.AddError(err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLine decodes a response line of mmpmon in parseable format (-p)
+// consisting of the response type followed by key-value pairs, e.g.
+// _fs_io_s_ _n_ 10.0.0.1 _nn_ node1 _rc_ 0 _t_ 1066660148 _tu_ 407431 ...
+func (g *GPFS) parseLine(acc telegraf.Accumulator, line string) error {
+	parts := strings.Fields(line)
+	if len(parts) < 3 || len(parts)%2 != 1 {
+		return fmt.Errorf("malformed response %q", line)
+	}
+
+	var measurement string
+	switch parts[0] {
+	case "_fs_io_s_":
+		measurement = "gpfs_filesystem"
+	case "_io_s_":
+		measurement = "gpfs_node"
+	default:
+		g.Log.Debugf("Ignoring unknown response type %q", parts[0])
+		return nil
+	}
+
+	values := make(map[string]string, len(parts)/2)
+	for i := 1; i < len(parts); i += 2 {
+		values[parts[i]] = parts[i+1]
+	}
+
+	if rc := values["_rc_"]; rc != "0" {
+		// Return code 1 signals that no file system is mounted
+		if rc == "1" && measurement == "gpfs_filesystem" {
+			return nil
+		}
+		return fmt.Errorf("request %q failed with return code %s", strings.Trim(parts[0], "_"), rc)
+	}
+
+	tags := map[string]string{"node": values["_nn_"]}
+	if measurement == "gpfs_filesystem" {
+		tags["cluster"] = values["_cl_"]
+		tags["filesystem"] = values["_fs_"]
+	}
+
+	fields := make(map[string]interface{}, len(counters)+1)
+	for key, name := range counters {
+		raw, found := values[key]
+		if !found {
+			continue
+		}
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %q value %q failed: %w", name, raw, err)
+		}
+		fields[name] = v
+	}
+	if raw, found := values["_d_"]; found {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parsing disks value %q failed: %w", raw, err)
+		}
+		fields["disks"] = v
+	}
+
+	// Use the timestamp of the statistics given in seconds and microseconds
+	ts := time.Now()
+	if sec, err := strconv.ParseInt(values["_t_"], 10, 64); err == nil {
+		usec, _ := strconv.ParseInt(values["_tu_"], 10, 64)
+		ts = time.Unix(sec, usec*int64(time.Microsecond))
+	}
+
+	acc.AddCounter(measurement, fields, tags, ts)
+	return nil
+}
+
+func (g *GPFS) mmpmon(input string) ([]byte, error) {
+	name := g.Binary
+	args := []string{"-p", "-s"}
+	if g.UseSudo {
+		args = append([]string{name}, args...)
+		name = "sudo"
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := internal.RunTimeout(cmd, time.Duration(g.Timeout)); err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", strings.Join(cmd.Args, " "), err)
+	}
+	return out.Bytes(), nil
+}
+
+func init() {
+	inputs.Add("gpfs", func() telegraf.Input {
+		return &GPFS{}
+	})
+}
@@ -0,0 +1,102 @@
+package gpfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	response, err := os.ReadFile(filepath.Join("testdata", "mmpmon.txt"))
+	require.NoError(t, err)
+
+	plugin := &GPFS{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(input string) ([]byte, error) {
+		require.Equal(t, "fs_io_s\nio_s\n", input)
+		return response, nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gpfs_filesystem",
+			map[string]string{"node": "node1", "cluster": "cluster1.example.com", "filesystem": "gpfs1"},
+			map[string]interface{}{
+				"disks":         int64(1),
+				"bytes_read":    uint64(6291456),
+				"bytes_written": uint64(314572800),
+				"opens":         uint64(10),
+				"closes":        uint64(16),
+				"reads":         uint64(101),
+				"writes":        uint64(300),
+				"readdirs":      uint64(7),
+				"inode_updates": uint64(2),
+			},
+			time.Unix(1066660148, 407431000),
+			telegraf.Counter,
+		),
+		metric.New(
+			"gpfs_filesystem",
+			map[string]string{"node": "node1", "cluster": "cluster1.example.com", "filesystem": "gpfs2"},
+			map[string]interface{}{
+				"disks":         int64(2),
+				"bytes_read":    uint64(2048),
+				"bytes_written": uint64(4096),
+				"opens":         uint64(3),
+				"closes":        uint64(3),
+				"reads":         uint64(1),
+				"writes":        uint64(2),
+				"readdirs":      uint64(0),
+				"inode_updates": uint64(5),
+			},
+			time.Unix(1066660148, 407455000),
+			telegraf.Counter,
+		),
+		metric.New(
+			"gpfs_node",
+			map[string]string{"node": "node1"},
+			map[string]interface{}{
+				"bytes_read":    uint64(6293504),
+				"bytes_written": uint64(314576896),
+				"opens":         uint64(13),
+				"closes":        uint64(19),
+				"reads":         uint64(102),
+				"writes":        uint64(302),
+				"readdirs":      uint64(7),
+				"inode_updates": uint64(7),
+			},
+			time.Unix(1066660148, 407431000),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestGatherErrors(t *testing.T) {
+	plugin := &GPFS{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(string) ([]byte, error) {
+		// No file system mounted is not an error but a failing node request is
+		return []byte("_fs_io_s_ _n_ 192.168.1.8 _nn_ node1 _rc_ 1 _t_ 1066660148 _tu_ 407431\n" +
+			"_io_s_ _n_ 192.168.1.8 _nn_ node1 _rc_ 2 _t_ 1066660148 _tu_ 407431\n" +
+			"_io_s_ _n_\n"), nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "return code 2")
+	require.ErrorContains(t, acc.Errors[1], "malformed response")
+}
@@ -0,0 +1,30 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package gunicorn
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Gunicorn struct {
+	PidFiles []string        `toml:"pid_files"`
+	Schema   string          `toml:"schema"`
+	Log      telegraf.Logger `toml:"-"`
+}
+
+func (*Gunicorn) SampleConfig() string {
+	return sampleConfig
+}
+
+func init() {
+	inputs.Add("gunicorn", func() telegraf.Input {
+		return &Gunicorn{
+			PidFiles: []string{"/run/gunicorn/*.pid"},
+		}
+	})
+}
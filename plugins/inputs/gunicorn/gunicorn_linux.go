@@ -0,0 +1,378 @@
+//go:build linux
+
+package gunicorn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/common/appserver"
+)
+
+// Clock ticks per second used for the CPU times in /proc/<pid>/stat, this is
+// fixed to 100 on all relevant architectures
+const clockTicks = 100
+
+// Prefix of the heartbeat files of the workers, see
+// gunicorn/workers/workertmp.py
+const heartbeatPrefix = "wgunicorn-"
+
+// monotonicNow returns the current time of the monotonic clock used by
+// gunicorn for the heartbeat of the workers
+var monotonicNow = defaultMonotonicNow
+
+func defaultMonotonicNow() (time.Duration, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, err
+	}
+	return time.Duration(ts.Nano()), nil
+}
+
+type process struct {
+	pid       int
+	ppid      int
+	state     string
+	userTime  float64
+	sysTime   float64
+	startTime float64
+	rss       int64
+}
+
+type worker struct {
+	process
+	heartbeat *time.Duration
+}
+
+func (g *Gunicorn) Init() error {
+	if g.Schema == "" {
+		g.Schema = appserver.SchemaNative
+	}
+	return appserver.CheckSchema(g.Schema)
+}
+
+func (g *Gunicorn) Gather(acc telegraf.Accumulator) error {
+	procPath := os.Getenv("HOST_PROC")
+	if procPath == "" {
+		procPath = "/proc"
+	}
+
+	for _, pattern := range g.PidFiles {
+		glob, err := globpath.Compile(pattern)
+		if err != nil {
+			acc.AddError(fmt.Errorf("could not compile glob %q: %w", pattern, err))
+			continue
+		}
+		for _, fn := range glob.Match() {
+			if err := g.gatherMaster(acc, procPath, fn); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", fn, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Gunicorn) gatherMaster(acc telegraf.Accumulator, procPath, pidFile string) error {
+	buf, err := os.ReadFile(pidFile)
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return fmt.Errorf("invalid PID: %w", err)
+	}
+	if _, err := readStat(procPath, pid); err != nil {
+		return fmt.Errorf("reading master process failed: %w", err)
+	}
+	timestamp := time.Now()
+
+	name := strings.TrimSuffix(filepath.Base(pidFile), filepath.Ext(pidFile))
+	if title := processTitle(procPath, pid); title != "" {
+		name = title
+	}
+
+	workers, err := findWorkers(procPath, pid)
+	if err != nil {
+		return err
+	}
+	uptime, err := systemUptime(procPath)
+	if err != nil {
+		return err
+	}
+
+	// The queue length is only available for TCP sockets, for unix sockets
+	// the kernel does not expose it
+	queue, err := listenQueue(procPath, pid)
+	if err != nil {
+		g.Log.Debugf("Reading listen queue of %d failed: %v", pid, err)
+	}
+
+	if g.Schema == appserver.SchemaUnified {
+		pool := &appserver.Pool{
+			Server:  "gunicorn",
+			Name:    name,
+			Address: pidFile,
+			Workers: int64(len(workers)),
+		}
+		pool.QueueDepth = queue
+		for _, w := range workers {
+			pool.WorkerStats = append(pool.WorkerStats, appserver.Worker{
+				ID:    strconv.Itoa(w.pid),
+				PID:   int64(w.pid),
+				State: w.state,
+			})
+		}
+		pool.Add(acc, timestamp)
+		return nil
+	}
+
+	tags := map[string]string{
+		"pool":     name,
+		"pid_file": pidFile,
+	}
+	fields := map[string]interface{}{
+		"pid":     pid,
+		"workers": len(workers),
+	}
+	if queue != nil {
+		fields["queue_depth"] = *queue
+	}
+	acc.AddFields("gunicorn", fields, tags, timestamp)
+
+	for _, w := range workers {
+		tags := map[string]string{
+			"pool":     name,
+			"pid_file": pidFile,
+			"worker":   strconv.Itoa(w.pid),
+		}
+		fields := map[string]interface{}{
+			"pid":             w.pid,
+			"state":           w.state,
+			"cpu_time_user":   w.userTime,
+			"cpu_time_system": w.sysTime,
+			"memory_rss":      w.rss,
+			"uptime_seconds":  uptime - w.startTime,
+		}
+		if w.heartbeat != nil {
+			fields["heartbeat_age_seconds"] = w.heartbeat.Seconds()
+		}
+		acc.AddFields("gunicorn_worker", fields, tags, timestamp)
+	}
+
+	return nil
+}
+
+// processTitle returns the application name from the process title set by
+// gunicorn if the setproctitle module is installed, e.g. 'gunicorn: master [app]'
+func processTitle(procPath string, pid int) string {
+	buf, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return ""
+	}
+	title := string(bytes.TrimRight(buf, "\x00"))
+	title, found := strings.CutPrefix(title, "gunicorn: master [")
+	if !found {
+		return ""
+	}
+	return strings.TrimSuffix(title, "]")
+}
+
+// findWorkers returns the child processes of the given master process
+func findWorkers(procPath string, master int) ([]worker, error) {
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var monotonic *time.Duration
+	var workers []worker
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		proc, err := readStat(procPath, pid)
+		if err != nil || proc.ppid != master {
+			// Processes might vanish while iterating
+			continue
+		}
+
+		w := worker{process: *proc}
+		if modified, err := heartbeat(procPath, pid); err == nil {
+			// Current gunicorn versions use the monotonic clock for the
+			// heartbeat while older versions use the wall clock
+			var age time.Duration
+			if modified.Before(time.Unix(1e9, 0)) {
+				if monotonic == nil {
+					now, err := monotonicNow()
+					if err != nil {
+						return nil, fmt.Errorf("reading monotonic clock failed: %w", err)
+					}
+					monotonic = &now
+				}
+				age = *monotonic - time.Duration(modified.UnixNano())
+			} else {
+				age = time.Since(modified)
+			}
+			w.heartbeat = &age
+		}
+		workers = append(workers, w)
+	}
+	return workers, nil
+}
+
+// readStat parses the relevant fields of /proc/<pid>/stat, see proc(5)
+func readStat(procPath string, pid int) (*process, error) {
+	buf, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	// The command name might contain spaces and parentheses
+	idx := bytes.LastIndexByte(buf, ')')
+	if idx < 0 {
+		return nil, errors.New("invalid stat format")
+	}
+	fields := strings.Fields(string(buf[idx+1:]))
+	if len(fields) < 22 {
+		return nil, errors.New("invalid stat format")
+	}
+
+	p := &process{pid: pid, state: processState(fields[0])}
+	values := make([]int64, len(fields))
+	for _, i := range []int{1, 11, 12, 19, 21} {
+		values[i], err = strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stat field %d failed: %w", i+3, err)
+		}
+	}
+	p.ppid = int(values[1])
+	p.userTime = float64(values[11]) / clockTicks
+	p.sysTime = float64(values[12]) / clockTicks
+	p.startTime = float64(values[19]) / clockTicks
+	p.rss = values[21] * int64(os.Getpagesize())
+
+	return p, nil
+}
+
+func processState(state string) string {
+	switch state {
+	case "R":
+		return "running"
+	case "S":
+		return "sleeping"
+	case "D":
+		return "blocked"
+	case "Z":
+		return "zombie"
+	case "T", "t":
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// heartbeat returns the modification time of the heartbeat file of a worker
+func heartbeat(procPath string, pid int) (time.Time, error) {
+	dir := filepath.Join(procPath, strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, entry := range entries {
+		fn := filepath.Join(dir, entry.Name())
+		target, err := os.Readlink(fn)
+		if err != nil {
+			continue
+		}
+		// The file is usually deleted after creation
+		target = strings.TrimSuffix(target, " (deleted)")
+		if !strings.HasPrefix(filepath.Base(target), heartbeatPrefix) {
+			continue
+		}
+		info, err := os.Stat(fn)
+		if err != nil {
+			continue
+		}
+		return info.ModTime(), nil
+	}
+	return time.Time{}, os.ErrNotExist
+}
+
+func systemUptime(procPath string) (float64, error) {
+	buf, err := os.ReadFile(filepath.Join(procPath, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(buf))
+	if len(fields) == 0 {
+		return 0, errors.New("invalid uptime format")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// listenQueue returns the number of connections waiting to be accepted on
+// the TCP sockets the given process is listening on. For listening sockets
+// the kernel reports this number as receive queue. The result is nil if the
+// process does not listen on any TCP socket.
+func listenQueue(procPath string, pid int) (*int64, error) {
+	base := filepath.Join(procPath, strconv.Itoa(pid))
+	entries, err := os.ReadDir(filepath.Join(base, "fd"))
+	if err != nil {
+		return nil, err
+	}
+	inodes := make(map[string]bool)
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(base, "fd", entry.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, found := strings.CutPrefix(target, "socket:["); found {
+			inodes[strings.TrimSuffix(inode, "]")] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	var queue *int64
+	for _, fn := range []string{"tcp", "tcp6"} {
+		buf, err := os.ReadFile(filepath.Join(base, "net", fn))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, line := range strings.Split(string(buf), "\n")[1:] {
+			fields := strings.Fields(line)
+			// Only consider sockets in LISTEN state owned by the process
+			if len(fields) < 10 || fields[3] != "0A" || !inodes[fields[9]] {
+				continue
+			}
+			_, rx, found := strings.Cut(fields[4], ":")
+			if !found {
+				continue
+			}
+			n, err := strconv.ParseInt(rx, 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing receive queue failed: %w", err)
+			}
+			if queue == nil {
+				queue = new(int64)
+			}
+			*queue += n
+		}
+	}
+	return queue, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package gunicorn
+
+import "github.com/influxdata/telegraf"
+
+func (g *Gunicorn) Init() error {
+	g.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Gunicorn) Gather(telegraf.Accumulator) error {
+	return nil
+}
@@ -0,0 +1,197 @@
+//go:build linux
+
+package gunicorn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// createProc creates a fake proc filesystem with a gunicorn master process
+// listening on a TCP socket and two workers, one of them with a heartbeat.
+func createProc(t *testing.T) (procPath, pidFile string) {
+	dir := t.TempDir()
+	procPath = filepath.Join(dir, "proc")
+
+	write := func(fn, content string) {
+		fn = filepath.Join(procPath, fn)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0750))
+		require.NoError(t, os.WriteFile(fn, []byte(content), 0640))
+	}
+	link := func(target, fn string) {
+		fn = filepath.Join(procPath, fn)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0750))
+		require.NoError(t, os.Symlink(target, fn))
+	}
+
+	write("uptime", "1000.00 3000.00\n")
+
+	write("100/stat", "100 (gunicorn) S 1 100 100 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 1 0 100 100000000 12800 0\n")
+	write("100/cmdline", "gunicorn: master [shop]\x00")
+	link("socket:[12345]", "100/fd/5")
+	link("/dev/null", "100/fd/0")
+	write("100/net/tcp",
+		"  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"+
+			"   0: 00000000:1F90 00000000:0000 0A 00000000:00000005 00:00000000 00000000    33        0 12345 1 0 100 0 0 10 0\n"+
+			"   1: 0100007F:0CEA 00000000:0000 0A 00000000:00000002 00:00000000 00000000     0        0 99999 1 0 100 0 0 10 0\n")
+
+	// Worker with a heartbeat using the monotonic clock
+	write("101/stat", "101 (gunicorn) R 100 100 100 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 1 0 40000 100000000 12800 0\n")
+	heartbeat := filepath.Join(dir, "wgunicorn-x1y2z3")
+	require.NoError(t, os.WriteFile(heartbeat, nil, 0640))
+	require.NoError(t, os.Chtimes(heartbeat, time.Unix(500, 0), time.Unix(500, 0)))
+	link(heartbeat+" (deleted)", "101/fd/7")
+	link(heartbeat, "101/fd/8")
+
+	// Worker without heartbeat
+	write("102/stat", "102 (gunicorn) S 100 100 100 0 -1 4194560 1000 0 0 0 100 20 0 0 20 0 1 0 90000 100000000 6400 0\n")
+
+	// Unrelated process
+	write("200/stat", "200 (bash) S 1 200 200 0 -1 4194560 1000 0 0 0 1 1 0 0 20 0 1 0 500 100000000 100 0\n")
+
+	pidFile = filepath.Join(dir, "app.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte("100\n"), 0640))
+
+	return procPath, pidFile
+}
+
+func TestGather(t *testing.T) {
+	procPath, pidFile := createProc(t)
+	t.Setenv("HOST_PROC", procPath)
+	monotonicNow = func() (time.Duration, error) {
+		return 510 * time.Second, nil
+	}
+	defer func() { monotonicNow = defaultMonotonicNow }()
+
+	plugin := &Gunicorn{
+		PidFiles: []string{filepath.Join(filepath.Dir(pidFile), "*.pid")},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	pagesize := int64(os.Getpagesize())
+	expected := []telegraf.Metric{
+		metric.New(
+			"gunicorn",
+			map[string]string{"pool": "shop", "pid_file": pidFile},
+			map[string]interface{}{
+				"pid":         100,
+				"workers":     2,
+				"queue_depth": int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gunicorn_worker",
+			map[string]string{"pool": "shop", "pid_file": pidFile, "worker": "101"},
+			map[string]interface{}{
+				"pid":                   101,
+				"state":                 "running",
+				"cpu_time_user":         2.5,
+				"cpu_time_system":       0.5,
+				"memory_rss":            12800 * pagesize,
+				"uptime_seconds":        600.0,
+				"heartbeat_age_seconds": 10.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gunicorn_worker",
+			map[string]string{"pool": "shop", "pid_file": pidFile, "worker": "102"},
+			map[string]interface{}{
+				"pid":             102,
+				"state":           "sleeping",
+				"cpu_time_user":   1.0,
+				"cpu_time_system": 0.2,
+				"memory_rss":      6400 * pagesize,
+				"uptime_seconds":  100.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherUnified(t *testing.T) {
+	procPath, pidFile := createProc(t)
+	t.Setenv("HOST_PROC", procPath)
+
+	plugin := &Gunicorn{
+		PidFiles: []string{pidFile},
+		Schema:   "unified",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"app_server",
+			map[string]string{"server": "gunicorn", "pool": "shop", "address": pidFile},
+			map[string]interface{}{
+				"workers":     int64(2),
+				"queue_depth": int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"app_server_worker",
+			map[string]string{"server": "gunicorn", "pool": "shop", "address": pidFile, "worker": "101"},
+			map[string]interface{}{
+				"pid":   int64(101),
+				"state": "running",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"app_server_worker",
+			map[string]string{"server": "gunicorn", "pool": "shop", "address": pidFile, "worker": "102"},
+			map[string]interface{}{
+				"pid":   int64(102),
+				"state": "sleeping",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherMissingMaster(t *testing.T) {
+	procPath, _ := createProc(t)
+	t.Setenv("HOST_PROC", procPath)
+
+	pidFile := filepath.Join(t.TempDir(), "stale.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte("4711"), 0640))
+
+	plugin := &Gunicorn{
+		PidFiles: []string{pidFile},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "reading master process failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInvalidSchema(t *testing.T) {
+	plugin := &Gunicorn{Schema: "foo"}
+	require.ErrorContains(t, plugin.Init(), "invalid schema")
+}
@@ -2,6 +2,7 @@
 package http_response
 
 import (
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -23,6 +24,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/cookie"
+	"github.com/influxdata/telegraf/plugins/common/fanout"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -59,6 +61,7 @@ type HTTPResponse struct {
 	Password config.Secret `toml:"password"`
 	tls.ClientConfig
 	cookie.CookieAuthConfig
+	fanout.Config
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -130,21 +133,15 @@ func (h *HTTPResponse) Init() error {
 
 // Gather gets all metric fields and tags and returns any errors it encounters
 func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
-	for _, c := range h.clients {
-		// Prepare data
-		var fields map[string]interface{}
-		var tags map[string]string
-
-		// Gather data
-		fields, tags, err := h.httpGather(c)
+	name := func(c client) string { return c.address }
+	fanout.Gather(h.Config, acc, h.clients, name, func(ctx context.Context, acc telegraf.Accumulator, c client) error {
+		fields, tags, err := h.httpGather(ctx, c)
 		if err != nil {
-			acc.AddError(err)
-			continue
+			return err
 		}
-
-		// Add metrics
 		acc.AddFields("http_response", fields, tags)
-	}
+		return nil
+	})
 
 	return nil
 }
@@ -305,7 +302,7 @@ func setError(err error, fields map[string]interface{}, tags map[string]string)
 }
 
 // HTTPGather gathers all fields and returns any errors it encounters
-func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string]string, error) {
+func (h *HTTPResponse) httpGather(ctx context.Context, cl client) (map[string]interface{}, map[string]string, error) {
 	// Prepare fields and tags
 	fields := make(map[string]interface{})
 	tags := map[string]string{"server": cl.address, "method": h.Method}
@@ -323,7 +320,7 @@ func (h *HTTPResponse) httpGather(cl client) (map[string]interface{}, map[string
 		body = strings.NewReader(values.Encode())
 	}
 
-	request, err := http.NewRequest(h.Method, cl.address, body)
+	request, err := http.NewRequestWithContext(ctx, h.Method, cl.address, body)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -479,6 +476,8 @@ func (h *HTTPResponse) setRequestAuth(request *http.Request) error {
 
 func init() {
 	inputs.Add("http_response", func() telegraf.Input {
-		return &HTTPResponse{}
+		return &HTTPResponse{
+			Config: fanout.Config{MaxConcurrency: 1},
+		}
 	})
 }
@@ -50,6 +50,14 @@ var (
 		"ShmemHugePages":  "shared_kb",
 		"FileHugePages":   "file_kb",
 	}
+
+	thpSettings = []string{"enabled", "defrag", "shmem_enabled"}
+
+	thpMetricsKhugepaged = map[string]string{
+		"pages_collapsed": "khugepaged_pages_collapsed",
+		"full_scans":      "khugepaged_full_scans",
+		"pages_to_scan":   "khugepaged_pages_to_scan",
+	}
 )
 
 const (
@@ -59,10 +67,15 @@ const (
 	numaNodePath = "/sys/devices/system/node"
 	// path to the meminfo file
 	meminfoPath = "/proc/meminfo"
+	// path to transparent huge page control directory
+	thpPath = "/sys/kernel/mm/transparent_hugepage"
+	// path to the vmstat file
+	vmstatPath = "/proc/vmstat"
 
 	rootHugepages    = "root"
 	perNodeHugepages = "per_node"
 	meminfoHugepages = "meminfo"
+	thpHugepages     = "thp"
 )
 
 type Hugepages struct {
@@ -71,10 +84,13 @@ type Hugepages struct {
 	gatherRoot    bool
 	gatherPerNode bool
 	gatherMeminfo bool
+	gatherTHP     bool
 
 	rootHugepagePath string
 	numaNodePath     string
 	meminfoPath      string
+	thpPath          string
+	vmstatPath       string
 }
 
 func (*Hugepages) SampleConfig() string {
@@ -90,6 +106,8 @@ func (h *Hugepages) Init() error {
 	h.rootHugepagePath = rootHugepagePath
 	h.numaNodePath = numaNodePath
 	h.meminfoPath = meminfoPath
+	h.thpPath = thpPath
+	h.vmstatPath = vmstatPath
 
 	return nil
 }
@@ -113,6 +131,12 @@ func (h *Hugepages) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if h.gatherTHP {
+		if err := h.gatherStatsTHP(acc); err != nil {
+			return fmt.Errorf("gathering transparent huge page stats failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -244,6 +268,67 @@ func (h *Hugepages) gatherStatsFromMeminfo(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// gatherStatsTHP collects the transparent huge page settings, khugepaged
+// statistics and the thp_* counters from the vmstat file
+func (h *Hugepages) gatherStatsTHP(acc telegraf.Accumulator) error {
+	metrics := make(map[string]interface{})
+
+	// the active setting is enclosed in brackets, e.g. "always [madvise] never"
+	for _, setting := range thpSettings {
+		content, err := os.ReadFile(filepath.Join(h.thpPath, setting))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		for _, value := range strings.Fields(string(content)) {
+			if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+				metrics[setting] = strings.Trim(value, "[]")
+				break
+			}
+		}
+	}
+
+	for file, metricName := range thpMetricsKhugepaged {
+		metricFullPath := filepath.Join(h.thpPath, "khugepaged", file)
+		metricBytes, err := os.ReadFile(metricFullPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+
+		metricValue, err := strconv.Atoi(string(bytes.TrimSuffix(metricBytes, newlineByte)))
+		if err != nil {
+			return fmt.Errorf("failed to convert content of %q: %w", metricFullPath, err)
+		}
+		metrics[metricName] = metricValue
+	}
+
+	vmstat, err := os.ReadFile(h.vmstatPath)
+	if err != nil {
+		return err
+	}
+	for _, line := range bytes.Split(vmstat, newlineByte) {
+		fields := bytes.Fields(line)
+		if len(fields) < 2 || !bytes.HasPrefix(fields[0], []byte("thp_")) {
+			continue
+		}
+		fieldName := string(fields[0])
+
+		fieldValue, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return fmt.Errorf("failed to convert content of %q: %w", fieldName, err)
+		}
+		metrics[strings.TrimPrefix(fieldName, "thp_")] = fieldValue
+	}
+
+	acc.AddFields("hugepages_"+thpHugepages, metrics, make(map[string]string))
+	return nil
+}
+
 func (h *Hugepages) parseHugepagesConfig() error {
 	// default
 	if h.Types == nil {
@@ -265,6 +350,8 @@ func (h *Hugepages) parseHugepagesConfig() error {
 			h.gatherPerNode = true
 		case meminfoHugepages:
 			h.gatherMeminfo = true
+		case thpHugepages:
+			h.gatherTHP = true
 		default:
 			return fmt.Errorf("provided hugepages type %q is not valid", hugepagesType)
 		}
@@ -22,6 +22,8 @@ func TestInit(t *testing.T) {
 		require.Equal(t, rootHugepagePath, h.rootHugepagePath)
 		require.Equal(t, numaNodePath, h.numaNodePath)
 		require.Equal(t, meminfoPath, h.meminfoPath)
+		require.Equal(t, thpPath, h.thpPath)
+		require.Equal(t, vmstatPath, h.vmstatPath)
 	})
 
 	t.Run("when empty hugepages types is provided then plugin should fail to initialize", func(t *testing.T) {
@@ -33,13 +35,14 @@ func TestInit(t *testing.T) {
 	})
 
 	t.Run("when valid hugepages types is provided then proper flags should be set", func(t *testing.T) {
-		h := Hugepages{Types: []string{"root", "per_node", "meminfo"}}
+		h := Hugepages{Types: []string{"root", "per_node", "meminfo", "thp"}}
 		err := h.Init()
 
 		require.NoError(t, err)
 		require.True(t, h.gatherRoot)
 		require.True(t, h.gatherPerNode)
 		require.True(t, h.gatherMeminfo)
+		require.True(t, h.gatherTHP)
 	})
 
 	t.Run("when hugepages types contains not supported value then plugin should fail to initialize", func(t *testing.T) {
@@ -143,6 +146,44 @@ func TestGather(t *testing.T) {
 		acc.AssertContainsFields(t, "hugepages_meminfo", expectedFields)
 	})
 
+	t.Run("when thp hugepages type is enabled then gather all transparent huge page metrics successfully", func(t *testing.T) {
+		h := Hugepages{
+			thpPath:    "./testdata/valid/mm/transparent_hugepage",
+			vmstatPath: "./testdata/valid/vmstat",
+			gatherTHP:  true,
+		}
+
+		acc := &testutil.Accumulator{}
+		require.NoError(t, h.Gather(acc))
+
+		expectedFields := map[string]interface{}{
+			"enabled":                    "madvise",
+			"defrag":                     "madvise",
+			"shmem_enabled":              "never",
+			"khugepaged_pages_collapsed": 12,
+			"khugepaged_full_scans":      34,
+			"khugepaged_pages_to_scan":   4096,
+			"fault_alloc":                1500,
+			"fault_fallback":             3,
+			"collapse_alloc":             12,
+			"collapse_alloc_failed":      0,
+			"split_page":                 7,
+			"swpout":                     0,
+		}
+		acc.AssertContainsFields(t, "hugepages_thp", expectedFields)
+	})
+
+	t.Run("when thp hugepages type is enabled but vmstat is missing then return error", func(t *testing.T) {
+		h := Hugepages{
+			thpPath:    "./testdata/valid/mm/transparent_hugepage",
+			vmstatPath: "./testdata/not_existing_path",
+			gatherTHP:  true,
+		}
+
+		acc := &testutil.Accumulator{}
+		require.Error(t, h.Gather(acc))
+	})
+
 	t.Run("when root hugepages type is enabled but path is invalid then return error", func(t *testing.T) {
 		h := Hugepages{
 			rootHugepagePath: "./testdata/not_existing_path",
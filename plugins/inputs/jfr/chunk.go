@@ -0,0 +1,617 @@
+package jfr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Layout of the chunk header, see jdk.jfr.internal.consumer.ChunkHeader
+const (
+	headerSize        = 68
+	fileStateOffset   = 64
+	flagsOffset       = 67
+	updatingHeader    = 255
+	flagCompressedInt = 1
+)
+
+// Reserved event type IDs of metadata and constant pool events
+const (
+	metadataEventType = 0
+	checkpointType    = 1
+)
+
+var magic = []byte("FLR\x00")
+
+var errIncomplete = errors.New("chunk incomplete")
+
+// header contains the information of a chunk header
+type header struct {
+	major          uint16
+	minor          uint16
+	size           int64
+	cpOffset       int64
+	metadataOffset int64
+	startNanos     int64
+	startTicks     int64
+	ticksPerSecond int64
+	compressed     bool
+}
+
+// class is a type described in the metadata of a chunk
+type class struct {
+	id         int64
+	name       string
+	superType  string
+	simpleType bool
+	fields     []field
+}
+
+// field is a field of a type described in the metadata of a chunk
+type field struct {
+	name         string
+	typeID       int64
+	constantPool bool
+	array        bool
+}
+
+// object is a decoded value of a complex type
+type object struct {
+	class  *class
+	values []interface{}
+}
+
+// poolRef is a reference to a value in a constant pool
+type poolRef struct {
+	typeID int64
+	key    int64
+}
+
+// chunk is a parsed JFR chunk including the metadata and constant pools
+type chunk struct {
+	header
+	data    []byte
+	classes map[int64]*class
+	byName  map[string]*class
+	pools   map[int64]map[int64]interface{}
+}
+
+// parseHeader parses the header of a chunk. An errIncomplete error is
+// returned if the JVM is currently updating the header or did not yet flush
+// any data to the chunk.
+func parseHeader(data []byte) (*header, error) {
+	if len(data) < headerSize {
+		return nil, errIncomplete
+	}
+	if string(data[:4]) != string(magic) {
+		return nil, errors.New("not a JFR chunk")
+	}
+	if data[fileStateOffset] == updatingHeader {
+		return nil, errIncomplete
+	}
+
+	h := &header{
+		major:          binary.BigEndian.Uint16(data[4:]),
+		minor:          binary.BigEndian.Uint16(data[6:]),
+		size:           int64(binary.BigEndian.Uint64(data[8:])),
+		cpOffset:       int64(binary.BigEndian.Uint64(data[16:])),
+		metadataOffset: int64(binary.BigEndian.Uint64(data[24:])),
+		startNanos:     int64(binary.BigEndian.Uint64(data[32:])),
+		startTicks:     int64(binary.BigEndian.Uint64(data[48:])),
+		ticksPerSecond: int64(binary.BigEndian.Uint64(data[56:])),
+		compressed:     data[flagsOffset]&flagCompressedInt != 0,
+	}
+	if h.major != 2 {
+		return nil, fmt.Errorf("unsupported JFR version %d.%d", h.major, h.minor)
+	}
+	if h.size < headerSize || h.metadataOffset <= 0 || h.ticksPerSecond <= 0 {
+		return nil, errIncomplete
+	}
+	return h, nil
+}
+
+// parseChunk parses the metadata and constant pools of the given chunk data
+func parseChunk(data []byte) (*chunk, error) {
+	h, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.size > int64(len(data)) {
+		return nil, errIncomplete
+	}
+	c := &chunk{
+		header:  *h,
+		data:    data[:h.size],
+		classes: make(map[int64]*class),
+		byName:  make(map[string]*class),
+		pools:   make(map[int64]map[int64]interface{}),
+	}
+	if err := c.parseMetadata(); err != nil {
+		return nil, fmt.Errorf("parsing metadata failed: %w", err)
+	}
+	if err := c.parseConstantPools(); err != nil {
+		return nil, fmt.Errorf("parsing constant pools failed: %w", err)
+	}
+	return c, nil
+}
+
+// element is a node of the metadata element tree
+type element struct {
+	name       string
+	attributes map[string]string
+	children   []*element
+}
+
+func (c *chunk) parseMetadata() error {
+	r := c.reader(c.metadataOffset)
+	r.int() // size
+	if t := r.long(); t != metadataEventType {
+		return fmt.Errorf("unexpected event type %d", t)
+	}
+	r.long() // start time
+	r.long() // duration
+	r.long() // metadata ID
+
+	n := r.int()
+	if r.err != nil || n < 0 || int(n) > len(c.data) {
+		return errors.New("invalid string table")
+	}
+	strs := make([]string, 0, n)
+	for range n {
+		s, _ := r.string(c)
+		str, _ := s.(string)
+		strs = append(strs, str)
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	root, err := r.element(strs, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range root.children {
+		if e.name != "metadata" {
+			continue
+		}
+		for _, ce := range e.children {
+			if ce.name != "class" {
+				continue
+			}
+			id, err := strconv.ParseInt(ce.attributes["id"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid class ID %q: %w", ce.attributes["id"], err)
+			}
+			cls := &class{
+				id:         id,
+				name:       ce.attributes["name"],
+				superType:  ce.attributes["superType"],
+				simpleType: ce.attributes["simpleType"] == "true",
+			}
+			for _, fe := range ce.children {
+				if fe.name != "field" {
+					continue
+				}
+				typeID, err := strconv.ParseInt(fe.attributes["class"], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid type of field %s.%s: %w", cls.name, fe.attributes["name"], err)
+				}
+				cls.fields = append(cls.fields, field{
+					name:         fe.attributes["name"],
+					typeID:       typeID,
+					constantPool: fe.attributes["constantPool"] == "true",
+					array:        fe.attributes["dimension"] == "1",
+				})
+			}
+			c.classes[id] = cls
+			c.byName[cls.name] = cls
+		}
+	}
+	return nil
+}
+
+// parseConstantPools follows the chain of constant pool events starting at
+// the latest one referenced by the header back to the first one
+func (c *chunk) parseConstantPools() error {
+	offset := c.cpOffset
+	for offset > 0 {
+		r := c.reader(offset)
+		r.int() // size
+		if t := r.long(); t != checkpointType {
+			return fmt.Errorf("unexpected event type %d at offset %d", t, offset)
+		}
+		r.long() // start time
+		r.long() // duration
+		delta := r.long()
+		r.byte() // checkpoint type
+		n := r.int()
+		for range n {
+			typeID := r.long()
+			count := r.int()
+			cls, found := c.classes[typeID]
+			if !found {
+				return fmt.Errorf("unknown constant pool type %d", typeID)
+			}
+			pool, found := c.pools[typeID]
+			if !found {
+				pool = make(map[int64]interface{}, count)
+				c.pools[typeID] = pool
+			}
+			for range count {
+				key := r.long()
+				pool[key] = r.value(c, cls)
+			}
+			if r.err != nil {
+				return r.err
+			}
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if delta == 0 {
+			break
+		}
+		offset += delta
+	}
+	return nil
+}
+
+// event is an event read from the chunk
+type event struct {
+	class *class
+	obj   *object
+}
+
+// events decodes the events in the given range of the chunk, metadata and
+// constant pool events are skipped. The end of the last event is returned.
+func (c *chunk) events(start, end int64, fn func(*event)) (int64, error) {
+	offset := start
+	for offset < end {
+		r := c.reader(offset)
+		size := r.int()
+		if r.err != nil || size <= 0 || offset+int64(size) > end {
+			return offset, fmt.Errorf("invalid event size %d at offset %d", size, offset)
+		}
+		typeID := r.long()
+		if typeID != metadataEventType && typeID != checkpointType {
+			if cls, found := c.classes[typeID]; found && fn != nil {
+				r.data = r.data[:offset+int64(size)]
+				v := r.object(c, cls)
+				if r.err != nil {
+					return offset, fmt.Errorf("decoding event %s at offset %d failed: %w", cls.name, offset, r.err)
+				}
+				fn(&event{class: cls, obj: v})
+			}
+		}
+		offset += int64(size)
+	}
+	return offset, nil
+}
+
+// time converts a tick value to a timestamp
+func (c *chunk) time(ticks int64) time.Time {
+	return time.Unix(0, c.startNanos+c.duration(ticks-c.startTicks).Nanoseconds())
+}
+
+// duration converts a tick span to a duration
+func (c *chunk) duration(ticks int64) time.Duration {
+	if c.ticksPerSecond == int64(time.Second) {
+		return time.Duration(ticks)
+	}
+	return time.Duration(float64(ticks) * float64(time.Second) / float64(c.ticksPerSecond))
+}
+
+// resolve returns the value referenced by constant pool references
+func (c *chunk) resolve(v interface{}) interface{} {
+	for range 8 {
+		ref, ok := v.(poolRef)
+		if !ok {
+			return v
+		}
+		v = c.pools[ref.typeID][ref.key]
+	}
+	return v
+}
+
+// get returns the resolved value of the field with the given name
+func (c *chunk) get(obj *object, name string) interface{} {
+	if obj == nil {
+		return nil
+	}
+	for i, f := range obj.class.fields {
+		if f.name == name && i < len(obj.values) {
+			return c.resolve(obj.values[i])
+		}
+	}
+	return nil
+}
+
+// int returns the integer value of the field with the given name
+func (c *chunk) int(obj *object, name string) int64 {
+	v, _ := c.get(obj, name).(int64)
+	return v
+}
+
+// text returns a string representation of the field with the given name.
+// Complex types such as classes or GC names are reduced to their name.
+func (c *chunk) text(obj *object, name string) string {
+	return c.stringOf(c.get(obj, name), 4)
+}
+
+func (c *chunk) stringOf(v interface{}, depth int) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case *object:
+		if depth == 0 {
+			return ""
+		}
+		// Classes are named in internal form, e.g. 'java/lang/Object'
+		if v.class.name == "java.lang.Class" {
+			return className(c.stringOf(c.get(v, "name"), depth-1))
+		}
+		for _, name := range []string{"name", "string", "cause", "javaName"} {
+			if s := c.stringOf(c.get(v, name), depth-1); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Element types of array classes, e.g. '[B' for 'byte[]'
+var arrayElementTypes = map[byte]string{
+	'B': "byte",
+	'C': "char",
+	'D': "double",
+	'F': "float",
+	'I': "int",
+	'J': "long",
+	'S': "short",
+	'Z': "boolean",
+}
+
+// className converts class names in the internal form of the JVM, such as
+// 'java/lang/String' or '[Ljava/lang/Object;', to the Java notation
+func className(name string) string {
+	elem := strings.TrimLeft(name, "[")
+	dims := len(name) - len(elem)
+	if dims > 0 && elem != "" {
+		if t, found := arrayElementTypes[elem[0]]; found && len(elem) == 1 {
+			elem = t
+		} else {
+			elem = strings.TrimSuffix(strings.TrimPrefix(elem, "L"), ";")
+		}
+	}
+	return strings.ReplaceAll(elem, "/", ".") + strings.Repeat("[]", dims)
+}
+
+// reader decodes the values of a chunk
+type reader struct {
+	data       []byte
+	pos        int64
+	compressed bool
+	err        error
+}
+
+func (c *chunk) reader(offset int64) *reader {
+	r := &reader{data: c.data, pos: offset, compressed: c.compressed}
+	if offset < 0 || offset >= int64(len(c.data)) {
+		r.err = fmt.Errorf("offset %d out of range", offset)
+	}
+	return r
+}
+
+func (r *reader) bytes(n int64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > int64(len(r.data)) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+// varint decodes a compressed integer, the ninth byte uses all eight bits
+func (r *reader) varint() int64 {
+	var v uint64
+	for i := range 8 {
+		b := r.byte()
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return int64(v)
+		}
+	}
+	return int64(v | uint64(r.byte())<<56)
+}
+
+func (r *reader) fixed(n int64) uint64 {
+	var v uint64
+	for _, b := range r.bytes(n) {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func (r *reader) long() int64 {
+	if r.compressed {
+		return r.varint()
+	}
+	return int64(r.fixed(8))
+}
+
+func (r *reader) int() int32 {
+	if r.compressed {
+		return int32(r.varint())
+	}
+	return int32(r.fixed(4))
+}
+
+func (r *reader) short() int16 {
+	if r.compressed {
+		return int16(r.varint())
+	}
+	return int16(r.fixed(2))
+}
+
+// String encodings, see jdk.jfr.internal.StringParser
+const (
+	stringNull = iota
+	stringEmpty
+	stringConstantPool
+	stringUTF8
+	stringCharArray
+	stringLatin1
+)
+
+// string decodes a string which might be a reference to the string pool
+func (r *reader) string(c *chunk) (interface{}, error) {
+	switch enc := r.byte(); enc {
+	case stringNull, stringEmpty:
+		return "", nil
+	case stringConstantPool:
+		key := r.long()
+		if cls, found := c.byName["java.lang.String"]; found {
+			return poolRef{typeID: cls.id, key: key}, nil
+		}
+		return "", nil
+	case stringUTF8:
+		return string(r.bytes(int64(r.int()))), nil
+	case stringCharArray:
+		n := r.int()
+		if n < 0 || int64(n) > int64(len(r.data)) {
+			r.err = errors.New("invalid string length")
+			return "", r.err
+		}
+		chars := make([]uint16, 0, n)
+		for range n {
+			chars = append(chars, uint16(r.short()))
+		}
+		return string(utf16.Decode(chars)), nil
+	case stringLatin1:
+		b := r.bytes(int64(r.int()))
+		runes := make([]rune, 0, len(b))
+		for _, c := range b {
+			runes = append(runes, rune(c))
+		}
+		return string(runes), nil
+	default:
+		r.err = fmt.Errorf("invalid string encoding %d", enc)
+		return "", r.err
+	}
+}
+
+// value decodes a value of the given type
+func (r *reader) value(c *chunk, cls *class) interface{} {
+	switch cls.name {
+	case "boolean":
+		return r.byte() != 0
+	case "byte":
+		return int64(int8(r.byte()))
+	case "char", "short":
+		return int64(r.short())
+	case "int":
+		return int64(r.int())
+	case "long":
+		return r.long()
+	case "float":
+		return float64(math.Float32frombits(uint32(r.fixed(4))))
+	case "double":
+		return math.Float64frombits(r.fixed(8))
+	case "java.lang.String":
+		s, _ := r.string(c)
+		return s
+	}
+	return r.object(c, cls)
+}
+
+// object decodes a value of a complex type field by field
+func (r *reader) object(c *chunk, cls *class) *object {
+	obj := &object{class: cls, values: make([]interface{}, 0, len(cls.fields))}
+	for _, f := range cls.fields {
+		if r.err != nil {
+			return obj
+		}
+		ft, found := c.classes[f.typeID]
+		if !found {
+			r.err = fmt.Errorf("unknown type %d of field %s.%s", f.typeID, cls.name, f.name)
+			return obj
+		}
+		if f.array {
+			n := r.int()
+			if n < 0 || int64(n) > int64(len(r.data)) {
+				r.err = fmt.Errorf("invalid array length %d", n)
+				return obj
+			}
+			values := make([]interface{}, 0, n)
+			for range n {
+				values = append(values, r.fieldValue(c, f, ft))
+			}
+			obj.values = append(obj.values, values)
+			continue
+		}
+		obj.values = append(obj.values, r.fieldValue(c, f, ft))
+	}
+	return obj
+}
+
+func (r *reader) fieldValue(c *chunk, f field, ft *class) interface{} {
+	if f.constantPool {
+		return poolRef{typeID: f.typeID, key: r.long()}
+	}
+	return r.value(c, ft)
+}
+
+// element decodes a node of the metadata element tree
+func (r *reader) element(strs []string, depth int) (*element, error) {
+	if depth > 16 {
+		return nil, errors.New("metadata nested too deeply")
+	}
+	str := func(idx int32) string {
+		if idx < 0 || int(idx) >= len(strs) {
+			r.err = fmt.Errorf("invalid string index %d", idx)
+			return ""
+		}
+		return strs[idx]
+	}
+
+	e := &element{name: str(r.int())}
+	n := r.int()
+	if n < 0 || int64(n) > int64(len(r.data)) {
+		return nil, fmt.Errorf("invalid attribute count %d", n)
+	}
+	e.attributes = make(map[string]string, n)
+	for range n {
+		k := str(r.int())
+		e.attributes[k] = str(r.int())
+	}
+	n = r.int()
+	for range n {
+		if r.err != nil {
+			break
+		}
+		child, err := r.element(strs, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		e.children = append(e.children, child)
+	}
+	return e, r.err
+}
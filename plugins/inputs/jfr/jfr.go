@@ -0,0 +1,282 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jfr
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Repository directories are named '<start time>_<pid>' by the JVM
+var repositoryRegexp = regexp.MustCompile(`_(\d+)$`)
+
+type JFR struct {
+	Files         []string        `toml:"files"`
+	Events        []string        `toml:"events"`
+	FromBeginning bool            `toml:"from_beginning"`
+	Log           telegraf.Logger `toml:"-"`
+
+	globs       []*globpath.GlobPath
+	enabled     map[string]bool
+	offsets     map[string]*chunkOffset
+	initialized bool
+
+	allocations map[aggregateKey]*allocation
+	locks       map[aggregateKey]*contention
+}
+
+// chunkOffset is the position up to which the events of a chunk are processed
+type chunkOffset struct {
+	startNanos int64
+	offset     int64
+}
+
+type aggregateKey struct {
+	pid   string
+	event string
+	class string
+}
+
+type allocation struct {
+	samples int64
+	weight  int64
+}
+
+type contention struct {
+	count    int64
+	duration time.Duration
+	max      time.Duration
+}
+
+func (*JFR) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JFR) Init() error {
+	if len(j.Files) == 0 {
+		return errors.New("no files configured")
+	}
+
+	if len(j.Events) == 0 {
+		j.Events = []string{"gc", "allocation", "lock_contention"}
+	}
+	if err := choice.CheckSlice(j.Events, []string{"gc", "allocation", "lock_contention"}); err != nil {
+		return fmt.Errorf("invalid 'events': %w", err)
+	}
+	j.enabled = make(map[string]bool, len(j.Events))
+	for _, e := range j.Events {
+		j.enabled[e] = true
+	}
+
+	for _, f := range j.Files {
+		g, err := globpath.Compile(f)
+		if err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", f, err)
+		}
+		j.globs = append(j.globs, g)
+	}
+
+	j.offsets = make(map[string]*chunkOffset)
+	j.allocations = make(map[aggregateKey]*allocation)
+	j.locks = make(map[aggregateKey]*contention)
+
+	return nil
+}
+
+func (j *JFR) Gather(acc telegraf.Accumulator) error {
+	seen := make(map[string]bool)
+	for _, g := range j.globs {
+		for _, fn := range g.Match() {
+			if seen[fn] {
+				continue
+			}
+			seen[fn] = true
+			if err := j.gatherChunk(acc, fn); err != nil {
+				acc.AddError(fmt.Errorf("reading %q failed: %w", fn, err))
+			}
+		}
+	}
+	j.initialized = true
+
+	// Forget about chunks removed by the JVM
+	for fn := range j.offsets {
+		if !seen[fn] {
+			delete(j.offsets, fn)
+		}
+	}
+
+	now := time.Now()
+	for k, v := range j.allocations {
+		tags := make(map[string]string, 2)
+		if k.class != "" {
+			tags["class"] = k.class
+		}
+		if k.pid != "" {
+			tags["pid"] = k.pid
+		}
+		fields := map[string]interface{}{
+			"samples":      v.samples,
+			"weight_bytes": v.weight,
+		}
+		acc.AddFields("jfr_allocation", fields, tags, now)
+	}
+	for k, v := range j.locks {
+		tags := map[string]string{"event": k.event}
+		if k.class != "" {
+			tags["class"] = k.class
+		}
+		if k.pid != "" {
+			tags["pid"] = k.pid
+		}
+		fields := map[string]interface{}{
+			"count":           v.count,
+			"duration_ns":     v.duration.Nanoseconds(),
+			"max_duration_ns": v.max.Nanoseconds(),
+		}
+		acc.AddFields("jfr_lock_contention", fields, tags, now)
+	}
+	clear(j.allocations)
+	clear(j.locks)
+
+	return nil
+}
+
+func (j *JFR) gatherChunk(acc telegraf.Accumulator, fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Check the header first to avoid reading chunks without new events
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		return err
+	}
+	h, err := parseHeader(buf)
+	if errors.Is(err, errIncomplete) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	state, found := j.offsets[fn]
+	if !found || state.startNanos != h.startNanos {
+		state = &chunkOffset{startNanos: h.startNanos, offset: headerSize}
+		if !j.initialized && !j.FromBeginning {
+			state.offset = h.size
+		}
+		j.offsets[fn] = state
+	}
+	if h.size <= state.offset {
+		return nil
+	}
+
+	data := make([]byte, h.size)
+	if _, err := f.ReadAt(data, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	c, err := parseChunk(data)
+	if errors.Is(err, errIncomplete) {
+		return nil
+	} else if err != nil {
+		// Do not try to parse the broken chunk again
+		state.offset = h.size
+		return err
+	}
+
+	var pid string
+	if match := repositoryRegexp.FindStringSubmatch(filepath.Base(filepath.Dir(fn))); match != nil {
+		pid = match[1]
+	}
+	end, err := c.events(state.offset, c.size, func(e *event) {
+		j.process(acc, c, e, pid)
+	})
+	if err != nil {
+		state.offset = c.size
+		return err
+	}
+	state.offset = end
+
+	return nil
+}
+
+func (j *JFR) process(acc telegraf.Accumulator, c *chunk, e *event, pid string) {
+	switch e.class.name {
+	case "jdk.GarbageCollection":
+		if !j.enabled["gc"] {
+			return
+		}
+		tags := map[string]string{
+			"name":  c.text(e.obj, "name"),
+			"cause": c.text(e.obj, "cause"),
+		}
+		if pid != "" {
+			tags["pid"] = pid
+		}
+		fields := map[string]interface{}{
+			"gc_id":            c.int(e.obj, "gcId"),
+			"duration_ns":      c.duration(c.int(e.obj, "duration")).Nanoseconds(),
+			"sum_of_pauses_ns": c.duration(c.int(e.obj, "sumOfPauses")).Nanoseconds(),
+			"longest_pause_ns": c.duration(c.int(e.obj, "longestPause")).Nanoseconds(),
+		}
+		acc.AddFields("jfr_gc", fields, tags, c.time(c.int(e.obj, "startTime")))
+	case "jdk.ObjectAllocationSample":
+		if !j.enabled["allocation"] {
+			return
+		}
+		key := aggregateKey{pid: pid, class: c.text(e.obj, "objectClass")}
+		a, found := j.allocations[key]
+		if !found {
+			a = &allocation{}
+			j.allocations[key] = a
+		}
+		a.samples++
+		a.weight += c.int(e.obj, "weight")
+	case "jdk.JavaMonitorEnter":
+		j.contention(c, e, pid, "monitor_enter", "monitorClass")
+	case "jdk.ThreadPark":
+		j.contention(c, e, pid, "thread_park", "parkedClass")
+	}
+}
+
+func (j *JFR) contention(c *chunk, e *event, pid, name, classField string) {
+	if !j.enabled["lock_contention"] {
+		return
+	}
+	key := aggregateKey{pid: pid, event: name, class: c.text(e.obj, classField)}
+	l, found := j.locks[key]
+	if !found {
+		l = &contention{}
+		j.locks[key] = l
+	}
+	d := c.duration(c.int(e.obj, "duration"))
+	l.count++
+	l.duration += d
+	l.max = max(l.max, d)
+}
+
+func init() {
+	inputs.Add("jfr", func() telegraf.Input {
+		return &JFR{}
+	})
+}
@@ -0,0 +1,670 @@
+package jfr
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalidEvents(t *testing.T) {
+	plugin := &JFR{
+		Files:  []string{"/tmp/*.jfr"},
+		Events: []string{"gc", "jit"},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid 'events'")
+}
+
+func TestGatherFromBeginning(t *testing.T) {
+	for _, compressed := range []bool{true, false} {
+		t.Run("compressed "+strconv.FormatBool(compressed), func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), "2024_05_14_09_12_45_4242", "2024_05_14_09_12_45.jfr")
+			w := newChunkWriter(compressed)
+			w.flush(firstEvents)
+			w.flush(secondEvents)
+			w.write(t, fn)
+
+			plugin := &JFR{
+				Files:         []string{filepath.Join(filepath.Dir(fn), "*.jfr")},
+				FromBeginning: true,
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			expected := []telegraf.Metric{
+				gcMetric("4242", 1, "G1New", "G1 Evacuation Pause", 1500*time.Millisecond, 10*time.Millisecond, 8*time.Millisecond),
+				gcMetric("4242", 2, "G1Old", "System.gc()", 3*time.Second, 250*time.Millisecond, 40*time.Millisecond),
+				metric.New(
+					"jfr_allocation",
+					map[string]string{"pid": "4242", "class": "java.lang.String"},
+					map[string]interface{}{"samples": int64(2), "weight_bytes": int64(3072)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"jfr_allocation",
+					map[string]string{"pid": "4242", "class": "byte[]"},
+					map[string]interface{}{"samples": int64(1), "weight_bytes": int64(65536)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"jfr_lock_contention",
+					map[string]string{"pid": "4242", "event": "monitor_enter", "class": "com.example.Cache"},
+					map[string]interface{}{"count": int64(2), "duration_ns": int64(35000000), "max_duration_ns": int64(30000000)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"jfr_lock_contention",
+					map[string]string{"pid": "4242", "event": "thread_park", "class": "java.util.concurrent.locks.ReentrantLock$NonfairSync"},
+					map[string]interface{}{"count": int64(1), "duration_ns": int64(2000000), "max_duration_ns": int64(2000000)},
+					time.Unix(0, 0),
+				),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+			// Garbage collections use the time of the event
+			for _, m := range acc.GetTelegrafMetrics() {
+				if m.Name() == "jfr_gc" && m.Fields()["gc_id"] == int64(1) {
+					require.Equal(t, chunkStart.Add(1500*time.Millisecond).UnixNano(), m.Time().UnixNano())
+				}
+			}
+
+			// Aggregates are reset and chunks are only processed once
+			acc.ClearMetrics()
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestGatherIncremental(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "2024_05_14_09_12_45_4242", "2024_05_14_09_12_45.jfr")
+	w := newChunkWriter(true)
+	w.flush(firstEvents)
+	w.write(t, fn)
+
+	plugin := &JFR{
+		Files:  []string{filepath.Join(filepath.Dir(fn), "*.jfr")},
+		Events: []string{"gc", "lock_contention"},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Events existing at startup are skipped
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Only the events of the next flush should be processed
+	w.flush(secondEvents)
+	w.write(t, fn)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		gcMetric("4242", 2, "G1Old", "System.gc()", 3*time.Second, 250*time.Millisecond, 40*time.Millisecond),
+		metric.New(
+			"jfr_lock_contention",
+			map[string]string{"pid": "4242", "event": "monitor_enter", "class": "com.example.Cache"},
+			map[string]interface{}{"count": int64(1), "duration_ns": int64(30000000), "max_duration_ns": int64(30000000)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"jfr_lock_contention",
+			map[string]string{"pid": "4242", "event": "thread_park", "class": "java.util.concurrent.locks.ReentrantLock$NonfairSync"},
+			map[string]interface{}{"count": int64(1), "duration_ns": int64(2000000), "max_duration_ns": int64(2000000)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUpdatingHeader(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "2024_05_14_09_12_45.jfr")
+	w := newChunkWriter(true)
+	w.flush(firstEvents)
+	w.data[fileStateOffset] = updatingHeader
+	w.write(t, fn)
+
+	plugin := &JFR{
+		Files:         []string{fn},
+		FromBeginning: true,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Once the header is complete the events are processed without a pid
+	w.data[fileStateOffset] = 1
+	w.write(t, fn)
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.NotEmpty(t, acc.GetTelegrafMetrics())
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.False(t, m.HasTag("pid"))
+	}
+}
+
+func TestGatherInvalidChunk(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "2024_05_14_09_12_45.jfr")
+	require.NoError(t, os.WriteFile(fn, []byte("this is not a flight recording at all, but it is long enough for a header"), 0600))
+
+	plugin := &JFR{
+		Files: []string{fn},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "not a JFR chunk")
+}
+
+func TestClassName(t *testing.T) {
+	tests := map[string]string{
+		"java/lang/String":        "java.lang.String",
+		"[B":                      "byte[]",
+		"[[J":                     "long[][]",
+		"[Ljava/lang/Object;":     "java.lang.Object[]",
+		"com/example/Outer$Inner": "com.example.Outer$Inner",
+		"[":                       "[]",
+	}
+	for name, expected := range tests {
+		require.Equal(t, expected, className(name), name)
+	}
+}
+
+func gcMetric(pid string, id int64, name, cause string, start, sum, longest time.Duration) telegraf.Metric {
+	return metric.New(
+		"jfr_gc",
+		map[string]string{"pid": pid, "name": name, "cause": cause},
+		map[string]interface{}{
+			"gc_id":            id,
+			"duration_ns":      (sum + time.Millisecond).Nanoseconds(),
+			"sum_of_pauses_ns": sum.Nanoseconds(),
+			"longest_pause_ns": longest.Nanoseconds(),
+		},
+		chunkStart.Add(start),
+	)
+}
+
+// Test data, the tick rate differs from nanoseconds to check the conversion
+var (
+	chunkStart     = time.Date(2024, 5, 14, 9, 12, 45, 0, time.UTC)
+	startTicks     = int64(1000)
+	ticksPerSecond = int64(2000000000)
+)
+
+func ticks(d time.Duration) int64 {
+	return int64(d.Seconds() * float64(ticksPerSecond))
+}
+
+func at(d time.Duration) int64 {
+	return startTicks + ticks(d)
+}
+
+// Type IDs of the metadata
+const (
+	typeLong         = 20
+	typeInt          = 21
+	typeBoolean      = 22
+	typeFloat        = 23
+	typeString       = 24
+	typeThread       = 30
+	typeClass        = 31
+	typeSymbol       = 32
+	typeGCName       = 33
+	typeGCCause      = 34
+	typeStackTrace   = 35
+	typeStackFrame   = 36
+	typeGC           = 100
+	typeAllocation   = 101
+	typeMonitor      = 102
+	typeThreadPark   = 103
+	typeCPULoad      = 104
+	typeUnreferenced = 105
+)
+
+type testField struct {
+	name  string
+	typ   int64
+	cp    bool
+	array bool
+}
+
+type testClass struct {
+	id     int64
+	name   string
+	fields []testField
+}
+
+var eventFields = []testField{
+	{name: "startTime", typ: typeLong},
+	{name: "duration", typ: typeLong},
+	{name: "eventThread", typ: typeThread, cp: true},
+	{name: "stackTrace", typ: typeStackTrace, cp: true},
+}
+
+var testClasses = []testClass{
+	{id: typeLong, name: "long"},
+	{id: typeInt, name: "int"},
+	{id: typeBoolean, name: "boolean"},
+	{id: typeFloat, name: "float"},
+	{id: typeString, name: "java.lang.String"},
+	{id: typeThread, name: "java.lang.Thread", fields: []testField{
+		{name: "osName", typ: typeString},
+		{name: "osThreadId", typ: typeLong},
+		{name: "javaName", typ: typeString},
+		{name: "javaThreadId", typ: typeLong},
+	}},
+	{id: typeClass, name: "java.lang.Class", fields: []testField{
+		{name: "name", typ: typeSymbol, cp: true},
+		{name: "modifiers", typ: typeInt},
+	}},
+	{id: typeSymbol, name: "jdk.types.Symbol", fields: []testField{
+		{name: "string", typ: typeString},
+	}},
+	{id: typeGCName, name: "jdk.types.GCName", fields: []testField{
+		{name: "name", typ: typeString},
+	}},
+	{id: typeGCCause, name: "jdk.types.GCCause", fields: []testField{
+		{name: "cause", typ: typeString},
+	}},
+	{id: typeStackTrace, name: "jdk.types.StackTrace", fields: []testField{
+		{name: "truncated", typ: typeBoolean},
+		{name: "frames", typ: typeStackFrame, array: true},
+	}},
+	{id: typeStackFrame, name: "jdk.types.StackFrame", fields: []testField{
+		{name: "lineNumber", typ: typeInt},
+		{name: "bytecodeIndex", typ: typeInt},
+	}},
+	{id: typeGC, name: "jdk.GarbageCollection", fields: []testField{
+		{name: "startTime", typ: typeLong},
+		{name: "duration", typ: typeLong},
+		{name: "gcId", typ: typeInt},
+		{name: "name", typ: typeGCName, cp: true},
+		{name: "cause", typ: typeGCCause, cp: true},
+		{name: "sumOfPauses", typ: typeLong},
+		{name: "longestPause", typ: typeLong},
+	}},
+	{id: typeAllocation, name: "jdk.ObjectAllocationSample", fields: []testField{
+		{name: "startTime", typ: typeLong},
+		{name: "eventThread", typ: typeThread, cp: true},
+		{name: "stackTrace", typ: typeStackTrace, cp: true},
+		{name: "objectClass", typ: typeClass, cp: true},
+		{name: "weight", typ: typeLong},
+	}},
+	{id: typeMonitor, name: "jdk.JavaMonitorEnter", fields: append(eventFields[:4:4],
+		testField{name: "monitorClass", typ: typeClass, cp: true},
+		testField{name: "previousOwner", typ: typeThread, cp: true},
+		testField{name: "address", typ: typeLong},
+	)},
+	{id: typeThreadPark, name: "jdk.ThreadPark", fields: append(eventFields[:4:4],
+		testField{name: "parkedClass", typ: typeClass, cp: true},
+		testField{name: "timeout", typ: typeLong},
+		testField{name: "until", typ: typeLong},
+		testField{name: "address", typ: typeLong},
+	)},
+	{id: typeCPULoad, name: "jdk.CPULoad", fields: []testField{
+		{name: "startTime", typ: typeLong},
+		{name: "jvmUser", typ: typeFloat},
+		{name: "jvmSystem", typ: typeFloat},
+		{name: "machineTotal", typ: typeFloat},
+	}},
+	{id: typeUnreferenced, name: "jdk.Unreferenced", fields: []testField{
+		{name: "startTime", typ: typeLong},
+	}},
+}
+
+// Values encoded with a special string encoding
+type (
+	stringRef int64
+	latin1    string
+	charArray string
+)
+
+type testEvent struct {
+	typ    int64
+	values []interface{}
+}
+
+type testPool struct {
+	typ    int64
+	values map[int64][]interface{}
+}
+
+type testFlush struct {
+	pools  []testPool
+	events []testEvent
+}
+
+var firstEvents = testFlush{
+	pools: []testPool{
+		{typ: typeString, values: map[int64][]interface{}{1: {"main"}}},
+		{typ: typeThread, values: map[int64][]interface{}{
+			1: {stringRef(1), int64(4711), stringRef(1), int64(1)},
+			2: {"worker-1", int64(4712), charArray("worker-1"), int64(22)},
+		}},
+		{typ: typeSymbol, values: map[int64][]interface{}{
+			1: {"java/lang/String"},
+			2: {latin1("[B")},
+			3: {"com/example/Cache"},
+		}},
+		{typ: typeClass, values: map[int64][]interface{}{
+			1: {int64(1), int64(17)},
+			2: {int64(2), int64(1041)},
+			3: {int64(3), int64(1)},
+		}},
+		{typ: typeGCName, values: map[int64][]interface{}{1: {"G1New"}, 2: {"G1Old"}}},
+		{typ: typeGCCause, values: map[int64][]interface{}{1: {"G1 Evacuation Pause"}, 2: {"System.gc()"}}},
+		{typ: typeStackTrace, values: map[int64][]interface{}{
+			1: {false, []interface{}{
+				[]interface{}{int64(42), int64(7)},
+				[]interface{}{int64(-1), int64(0)},
+			}},
+		}},
+	},
+	events: []testEvent{
+		{typ: typeCPULoad, values: []interface{}{at(time.Second), float32(0.25), float32(0.05), float32(0.5)}},
+		{typ: typeGC, values: []interface{}{
+			at(1500 * time.Millisecond), ticks(11 * time.Millisecond), int64(1), int64(1), int64(1),
+			ticks(10 * time.Millisecond), ticks(8 * time.Millisecond),
+		}},
+		{typ: typeAllocation, values: []interface{}{at(time.Second), int64(1), int64(1), int64(1), int64(1024)}},
+		{typ: typeAllocation, values: []interface{}{at(time.Second), int64(2), int64(1), int64(1), int64(2048)}},
+		{typ: typeAllocation, values: []interface{}{at(time.Second), int64(2), int64(1), int64(2), int64(65536)}},
+		{typ: typeMonitor, values: []interface{}{
+			at(2 * time.Second), ticks(5 * time.Millisecond), int64(1), int64(1), int64(3), int64(2), int64(0x7f00),
+		}},
+	},
+}
+
+var secondEvents = testFlush{
+	pools: []testPool{
+		{typ: typeSymbol, values: map[int64][]interface{}{
+			4: {"java/util/concurrent/locks/ReentrantLock$NonfairSync"},
+		}},
+		{typ: typeClass, values: map[int64][]interface{}{
+			4: {int64(4), int64(8)},
+		}},
+	},
+	events: []testEvent{
+		{typ: typeGC, values: []interface{}{
+			at(3 * time.Second), ticks(251 * time.Millisecond), int64(2), int64(2), int64(2),
+			ticks(250 * time.Millisecond), ticks(40 * time.Millisecond),
+		}},
+		{typ: typeMonitor, values: []interface{}{
+			at(3 * time.Second), ticks(30 * time.Millisecond), int64(2), int64(1), int64(3), int64(1), int64(0x7f00),
+		}},
+		{typ: typeThreadPark, values: []interface{}{
+			at(4 * time.Second), ticks(2 * time.Millisecond), int64(2), int64(1), int64(4), int64(0), int64(0), int64(0x7f10),
+		}},
+	},
+}
+
+// chunkWriter creates chunks in the format written by the JVM. Each flush
+// appends the events followed by a constant pool event linked to the
+// previous one and updates the header. The metadata is written once.
+type chunkWriter struct {
+	compressed bool
+	data       []byte
+	lastPool   int64
+	metadata   int64
+}
+
+func newChunkWriter(compressed bool) *chunkWriter {
+	w := &chunkWriter{compressed: compressed, data: make([]byte, headerSize)}
+	copy(w.data, magic)
+	binary.BigEndian.PutUint16(w.data[4:], 2)
+	binary.BigEndian.PutUint16(w.data[6:], 1)
+	binary.BigEndian.PutUint64(w.data[32:], uint64(chunkStart.UnixNano()))
+	binary.BigEndian.PutUint64(w.data[48:], uint64(startTicks))
+	binary.BigEndian.PutUint64(w.data[56:], uint64(ticksPerSecond))
+	w.data[fileStateOffset] = 1
+	if compressed {
+		w.data[flagsOffset] = flagCompressedInt
+	}
+	return w
+}
+
+func (w *chunkWriter) flush(f testFlush) {
+	if w.metadata == 0 {
+		w.metadata = int64(len(w.data))
+		w.data = append(w.data, w.event(metadataEventType, w.encodeMetadata())...)
+	}
+
+	for _, e := range f.events {
+		w.data = append(w.data, w.event(e.typ, w.encodeObject(e.typ, e.values))...)
+	}
+
+	offset := int64(len(w.data))
+	var buf []byte
+	buf = w.long(buf, 0)
+	buf = w.long(buf, 0)
+	if w.lastPool != 0 {
+		buf = w.long(buf, w.lastPool-offset)
+	} else {
+		buf = w.long(buf, 0)
+	}
+	buf = append(buf, 1)
+	buf = w.int(buf, int64(len(f.pools)))
+	for _, p := range f.pools {
+		buf = w.long(buf, p.typ)
+		buf = w.int(buf, int64(len(p.values)))
+		for k, v := range p.values {
+			buf = w.long(buf, k)
+			buf = append(buf, w.encodeValue(p.typ, v)...)
+		}
+	}
+	w.data = append(w.data, w.event(checkpointType, buf)...)
+	w.lastPool = offset
+
+	binary.BigEndian.PutUint64(w.data[8:], uint64(len(w.data)))
+	binary.BigEndian.PutUint64(w.data[16:], uint64(w.lastPool))
+	binary.BigEndian.PutUint64(w.data[24:], uint64(w.metadata))
+}
+
+func (w *chunkWriter) write(t *testing.T, fn string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0750))
+	require.NoError(t, os.WriteFile(fn, w.data, 0600))
+}
+
+// event prefixes the payload with the size and type, the size is padded to
+// four bytes as done by the JVM
+func (w *chunkWriter) event(typ int64, payload []byte) []byte {
+	body := w.long(nil, typ)
+	body = append(body, payload...)
+	size := uint32(len(body) + 4)
+	var buf []byte
+	if w.compressed {
+		buf = []byte{byte(size&0x7f | 0x80), byte(size>>7&0x7f | 0x80), byte(size>>14&0x7f | 0x80), byte(size >> 21)}
+	} else {
+		buf = binary.BigEndian.AppendUint32(nil, size)
+	}
+	return append(buf, body...)
+}
+
+func (w *chunkWriter) long(buf []byte, v int64) []byte {
+	if !w.compressed {
+		return binary.BigEndian.AppendUint64(buf, uint64(v))
+	}
+	u := uint64(v)
+	for range 8 {
+		if u < 0x80 {
+			return append(buf, byte(u))
+		}
+		buf = append(buf, byte(u&0x7f|0x80))
+		u >>= 7
+	}
+	return append(buf, byte(u))
+}
+
+func (w *chunkWriter) int(buf []byte, v int64) []byte {
+	if !w.compressed {
+		return binary.BigEndian.AppendUint32(buf, uint32(v))
+	}
+	return w.long(buf, int64(int32(v)))
+}
+
+func (w *chunkWriter) string(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case stringRef:
+		return w.long(append(buf, stringConstantPool), int64(v))
+	case latin1:
+		buf = w.int(append(buf, stringLatin1), int64(len(v)))
+		return append(buf, v...)
+	case charArray:
+		buf = w.int(append(buf, stringCharArray), int64(len(v)))
+		for _, c := range v {
+			if w.compressed {
+				buf = w.long(buf, int64(c))
+			} else {
+				buf = binary.BigEndian.AppendUint16(buf, uint16(c))
+			}
+		}
+		return buf
+	case string:
+		if v == "" {
+			return append(buf, stringEmpty)
+		}
+		buf = w.int(append(buf, stringUTF8), int64(len(v)))
+		return append(buf, v...)
+	}
+	return append(buf, stringNull)
+}
+
+func (w *chunkWriter) encodeValue(typ int64, v interface{}) []byte {
+	switch typ {
+	case typeLong:
+		return w.long(nil, v.(int64))
+	case typeInt:
+		return w.int(nil, v.(int64))
+	case typeBoolean:
+		if v.(bool) {
+			return []byte{1}
+		}
+		return []byte{0}
+	case typeFloat:
+		return binary.BigEndian.AppendUint32(nil, math.Float32bits(v.(float32)))
+	case typeString:
+		return w.string(nil, v)
+	}
+	return w.encodeObject(typ, v.([]interface{}))
+}
+
+func (w *chunkWriter) encodeObject(typ int64, values []interface{}) []byte {
+	var buf []byte
+	for _, c := range testClasses {
+		if c.id != typ {
+			continue
+		}
+		for i, f := range c.fields {
+			encode := func(v interface{}) []byte {
+				if f.cp {
+					return w.long(nil, v.(int64))
+				}
+				return w.encodeValue(f.typ, v)
+			}
+			if f.array {
+				elements := values[i].([]interface{})
+				buf = w.int(buf, int64(len(elements)))
+				for _, e := range elements {
+					buf = append(buf, encode(e)...)
+				}
+				continue
+			}
+			buf = append(buf, encode(values[i])...)
+		}
+	}
+	return buf
+}
+
+func (w *chunkWriter) encodeMetadata() []byte {
+	var strs []string
+	index := make(map[string]int64)
+	intern := func(s string) int64 {
+		if i, found := index[s]; found {
+			return i
+		}
+		index[s] = int64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+
+	type node struct {
+		name     string
+		attrs    [][2]string
+		children []node
+	}
+	var encode func(buf []byte, n node) []byte
+	encode = func(buf []byte, n node) []byte {
+		buf = w.int(buf, intern(n.name))
+		buf = w.int(buf, int64(len(n.attrs)))
+		for _, a := range n.attrs {
+			buf = w.int(buf, intern(a[0]))
+			buf = w.int(buf, intern(a[1]))
+		}
+		buf = w.int(buf, int64(len(n.children)))
+		for _, c := range n.children {
+			buf = encode(buf, c)
+		}
+		return buf
+	}
+
+	metadata := node{name: "metadata"}
+	for _, c := range testClasses {
+		cn := node{name: "class", attrs: [][2]string{
+			{"name", c.name},
+			{"id", strconv.FormatInt(c.id, 10)},
+		}}
+		if c.id >= typeGC {
+			cn.attrs = append(cn.attrs, [2]string{"superType", "jdk.jfr.Event"})
+		}
+		for _, f := range c.fields {
+			fn := node{name: "field", attrs: [][2]string{
+				{"name", f.name},
+				{"class", strconv.FormatInt(f.typ, 10)},
+			}}
+			if f.cp {
+				fn.attrs = append(fn.attrs, [2]string{"constantPool", "true"})
+			}
+			if f.array {
+				fn.attrs = append(fn.attrs, [2]string{"dimension", "1"})
+			}
+			cn.children = append(cn.children, fn)
+		}
+		metadata.children = append(metadata.children, cn)
+	}
+	root := node{name: "root", children: []node{
+		metadata,
+		{name: "region", attrs: [][2]string{{"locale", "en_US"}, {"gmtOffset", "0"}}},
+	}}
+	tree := encode(nil, root)
+
+	buf := w.long(nil, 0)
+	buf = w.long(buf, 0)
+	buf = w.long(buf, 1)
+	buf = w.int(buf, int64(len(strs)))
+	for _, s := range strs {
+		buf = w.string(buf, s)
+	}
+	return append(buf, tree...)
+}
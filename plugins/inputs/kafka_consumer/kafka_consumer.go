@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +18,8 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	telegraf_metric "github.com/influxdata/telegraf/metric"
+	common_consumer "github.com/influxdata/telegraf/plugins/common/consumer"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -53,6 +56,8 @@ type KafkaConsumer struct {
 	ResolveCanonicalBootstrapServersOnly bool            `toml:"resolve_canonical_bootstrap_servers_only"`
 	Log                                  telegraf.Logger `toml:"-"`
 	kafka.ReadConfig
+	common_consumer.ErrorPolicyConfig
+	common_consumer.UnwrapConfig
 
 	consumerCreator consumerGroupCreator
 	consumer        consumerGroup
@@ -64,6 +69,8 @@ type KafkaConsumer struct {
 	fingerprint     string
 
 	parser    telegraf.Parser
+	policy    *common_consumer.ErrorPolicy
+	unwrapper *common_consumer.Unwrapper
 	topicLock sync.Mutex
 	wg        sync.WaitGroup
 	cancel    context.CancelFunc
@@ -77,11 +84,14 @@ type consumerGroupHandler struct {
 	msgHeaderToMetricName string
 	timestampSource       string
 
-	acc    telegraf.TrackingAccumulator
-	sem    semaphore
-	parser telegraf.Parser
-	wg     sync.WaitGroup
-	cancel context.CancelFunc
+	acc       telegraf.TrackingAccumulator
+	sem       semaphore
+	parser    telegraf.Parser
+	policy    *common_consumer.ErrorPolicy
+	unwrapper *common_consumer.Unwrapper
+	halt      context.CancelFunc
+	wg        sync.WaitGroup
+	cancel    context.CancelFunc
 
 	mu          sync.Mutex
 	undelivered map[telegraf.TrackingID]message
@@ -133,6 +143,18 @@ func (k *KafkaConsumer) Init() error {
 		k.ConsumerGroup = defaultConsumerGroup
 	}
 
+	policy, err := common_consumer.NewErrorPolicy(k.ErrorPolicyConfig, map[string]string{"input": "kafka_consumer"}, k.Log)
+	if err != nil {
+		return err
+	}
+	k.policy = policy
+
+	unwrapper, err := common_consumer.NewUnwrapper(k.UnwrapConfig)
+	if err != nil {
+		return err
+	}
+	k.unwrapper = unwrapper
+
 	switch k.TimestampSource {
 	case "":
 		k.TimestampSource = "metric"
@@ -276,6 +298,9 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			}
 			handler.msgHeadersToTags = msgHeadersMap
 			handler.timestampSource = k.TimestampSource
+			handler.policy = k.policy
+			handler.unwrapper = k.unwrapper
+			handler.halt = cancel
 
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
@@ -313,6 +338,10 @@ func (k *KafkaConsumer) Stop() {
 
 	k.cancel()
 	k.wg.Wait()
+
+	if err := k.policy.Close(); err != nil {
+		k.Log.Errorf("Closing dead-letter file failed: %v", err)
+	}
 }
 
 func (k *KafkaConsumer) compileTopicRegexps() error {
@@ -460,6 +489,9 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 			err := h.handle(session, msg)
 			if err != nil {
 				h.acc.AddError(err)
+				if errors.Is(err, common_consumer.ErrHalt) {
+					return nil
+				}
 			}
 		}
 	}
@@ -525,10 +557,18 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 			len(msg.Value), h.maxMessageLen)
 	}
 
-	metrics, err := h.parser.Parse(msg.Value)
+	metrics, err := h.policy.Parse(msg.Topic, msg.Value, func() ([]telegraf.Metric, error) {
+		return h.unwrapper.Parse(h.parser, msg.Value)
+	})
 	if err != nil {
-		session.MarkMessage(msg, "")
 		h.release()
+		if errors.Is(err, common_consumer.ErrHalt) {
+			// Stop consuming without marking the message so it is
+			// received again after a restart
+			h.halt()
+			return err
+		}
+		session.MarkMessage(msg, "")
 		return err
 	}
 
@@ -565,6 +605,13 @@ func (h *consumerGroupHandler) handle(session sarama.ConsumerGroupSession, msg *
 		}
 	}
 
+	// Keep the message position to allow tracing metrics back to the message
+	source := msg.Topic + "/" + strconv.FormatInt(int64(msg.Partition), 10)
+	offset := strconv.FormatInt(msg.Offset, 10)
+	for _, m := range metrics {
+		telegraf_metric.SetProvenanceOffset(m, source, offset)
+	}
+
 	// Do override the metric timestamp if required
 	switch h.timestampSource {
 	case "inner":
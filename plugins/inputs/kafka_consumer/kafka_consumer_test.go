@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	common_consumer "github.com/influxdata/telegraf/plugins/common/consumer"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -237,7 +238,8 @@ func TestStartStop(t *testing.T) {
 }
 
 type FakeConsumerGroupSession struct {
-	ctx context.Context
+	ctx    context.Context
+	marked int
 }
 
 func (s *FakeConsumerGroupSession) Claims() map[string][]int32 {
@@ -261,6 +263,7 @@ func (s *FakeConsumerGroupSession) ResetOffset(_ string, _ int32, _ int64, _ str
 }
 
 func (s *FakeConsumerGroupSession) MarkMessage(_ *sarama.ConsumerMessage, _ string) {
+	s.marked++
 }
 
 func (s *FakeConsumerGroupSession) Context() context.Context {
@@ -390,6 +393,7 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 		name                string
 		maxMessageLen       int
 		topicTag            string
+		unwrapPaths         []string
 		msg                 *sarama.ConsumerMessage
 		expected            []telegraf.Metric
 		expectedHandleError string
@@ -448,6 +452,41 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:        "unwrap envelope",
+			unwrapPaths: []string{"Records.#.value"},
+			msg: &sarama.ConsumerMessage{
+				Topic: "telegraf",
+				Value: []byte(`{"Records":[{"value":"42"},{"value":"43"}]}`),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"value": 42,
+					},
+					time.Now(),
+				),
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"value": 43,
+					},
+					time.Now(),
+				),
+			},
+		},
+		{
+			name:        "unwrap missing path",
+			unwrapPaths: []string{"Message"},
+			msg: &sarama.ConsumerMessage{
+				Topic: "telegraf",
+				Value: []byte(`{"Subject":"42"}`),
+			},
+			expectedHandleError: "unwrapping message failed: path \"Message\" not found in envelope",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -461,11 +500,15 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 			cg.maxMessageLen = tt.maxMessageLen
 			cg.topicTag = tt.topicTag
 
+			unwrapper, err := common_consumer.NewUnwrapper(common_consumer.UnwrapConfig{UnwrapPaths: tt.unwrapPaths})
+			require.NoError(t, err)
+			cg.unwrapper = unwrapper
+
 			ctx := context.Background()
 			session := &FakeConsumerGroupSession{ctx: ctx}
 
 			require.NoError(t, cg.reserve(ctx))
-			err := cg.handle(session, tt.msg)
+			err = cg.handle(session, tt.msg)
 			if tt.expectedHandleError != "" {
 				require.Error(t, err)
 				require.EqualValues(t, tt.expectedHandleError, err.Error())
@@ -478,6 +521,51 @@ func TestConsumerGroupHandlerHandle(t *testing.T) {
 	}
 }
 
+func TestConsumerGroupHandlerHalt(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	parser := value.Parser{
+		MetricName: "cpu",
+		DataType:   "int",
+	}
+	require.NoError(t, parser.Init())
+	cg := newConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
+
+	policy, err := common_consumer.NewErrorPolicy(
+		common_consumer.ErrorPolicyConfig{ParseErrorPolicy: "halt"},
+		map[string]string{"input": "kafka_consumer_test"},
+		testutil.Logger{},
+	)
+	require.NoError(t, err)
+	cg.policy = policy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var halted bool
+	cg.halt = func() {
+		halted = true
+		cancel()
+	}
+
+	session := &FakeConsumerGroupSession{ctx: ctx}
+	claim := &FakeConsumerGroupClaim{
+		messages: make(chan *sarama.ConsumerMessage, 1),
+	}
+	require.NoError(t, cg.Setup(session))
+
+	claim.messages <- &sarama.ConsumerMessage{
+		Topic: "telegraf",
+		Value: []byte("not a number"),
+	}
+	require.NoError(t, cg.ConsumeClaim(session, claim))
+	require.NoError(t, cg.Cleanup(session))
+
+	// The message must not be marked to receive it again after a restart
+	require.True(t, halted)
+	require.Zero(t, session.marked)
+	require.Len(t, acc.Errors, 1)
+	require.ErrorIs(t, acc.Errors[0], common_consumer.ErrHalt)
+}
+
 func TestExponentialBackoff(t *testing.T) {
 	var err error
 
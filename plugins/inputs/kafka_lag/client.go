@@ -0,0 +1,76 @@
+package kafka_lag
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// client abstracts the Kafka requests required for computing the lag
+type client interface {
+	groups() ([]string, error)
+	committed(group string) (map[string]map[int32]int64, error)
+	newest(topic string, partition int32) (int64, error)
+	close() error
+}
+
+type saramaClient struct {
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+}
+
+func newSaramaClient(brokers []string, cfg *sarama.Config) (client, error) {
+	c, err := sarama.NewClient(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := sarama.NewClusterAdminFromClient(c)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &saramaClient{client: c, admin: admin}, nil
+}
+
+func (c *saramaClient) groups() ([]string, error) {
+	groups, err := c.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (c *saramaClient) committed(group string) (map[string]map[int32]int64, error) {
+	resp, err := c.admin.ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Err != sarama.ErrNoError {
+		return nil, resp.Err
+	}
+
+	offsets := make(map[string]map[int32]int64, len(resp.Blocks))
+	for topic, partitions := range resp.Blocks {
+		offsets[topic] = make(map[int32]int64, len(partitions))
+		for partition, block := range partitions {
+			if block.Err != sarama.ErrNoError {
+				return nil, fmt.Errorf("topic %q partition %d: %w", topic, partition, block.Err)
+			}
+			offsets[topic][partition] = block.Offset
+		}
+	}
+	return offsets, nil
+}
+
+func (c *saramaClient) newest(topic string, partition int32) (int64, error) {
+	return c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+}
+
+func (c *saramaClient) close() error {
+	// Closing the admin also closes the underlying client
+	return c.admin.Close()
+}
@@ -0,0 +1,277 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kafka_lag
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/kafka"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type KafkaLag struct {
+	Brokers          []string        `toml:"brokers"`
+	Groups           []string        `toml:"groups"`
+	Topics           []string        `toml:"topics"`
+	PartitionMetrics bool            `toml:"partition_metrics"`
+	Log              telegraf.Logger `toml:"-"`
+	kafka.ReadConfig
+
+	config      *sarama.Config
+	groupFilter filter.Filter
+	topicFilter filter.Filter
+	newClient   func(brokers []string, cfg *sarama.Config) (client, error)
+	client      client
+	now         func() time.Time
+
+	// Offsets of the previous gather used to compute the rates
+	previous map[partitionKey]sample
+}
+
+type partitionKey struct {
+	group     string
+	topic     string
+	partition int32
+}
+
+type sample struct {
+	committed int64
+	newest    int64
+	time      time.Time
+}
+
+// summary accumulates the partition values of a group and topic
+type summary struct {
+	lag         int64
+	maxLag      int64
+	partitions  int
+	commitRate  float64
+	produceRate float64
+	rates       bool
+}
+
+func (*KafkaLag) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *KafkaLag) Init() error {
+	kafka.SetLogger(k.Log.Level())
+
+	if len(k.Brokers) == 0 {
+		return errors.New("no brokers configured")
+	}
+
+	if len(k.Groups) == 0 {
+		k.Groups = []string{"*"}
+	}
+	groupFilter, err := filter.Compile(k.Groups)
+	if err != nil {
+		return fmt.Errorf("creating group filter failed: %w", err)
+	}
+	k.groupFilter = groupFilter
+
+	if len(k.Topics) == 0 {
+		k.Topics = []string{"*"}
+	}
+	topicFilter, err := filter.Compile(k.Topics)
+	if err != nil {
+		return fmt.Errorf("creating topic filter failed: %w", err)
+	}
+	k.topicFilter = topicFilter
+
+	cfg := sarama.NewConfig()
+
+	// Kafka version 0.10.2.0 is required for listing the consumer groups
+	// and fetching all offsets of a group.
+	cfg.Version = sarama.V0_10_2_0
+	if k.Version != "" {
+		version, err := sarama.ParseKafkaVersion(k.Version)
+		if err != nil {
+			return fmt.Errorf("invalid version: %w", err)
+		}
+		cfg.Version = version
+	}
+
+	if err := k.SetConfig(cfg, k.Log); err != nil {
+		return fmt.Errorf("setting config failed: %w", err)
+	}
+	k.config = cfg
+
+	if k.newClient == nil {
+		k.newClient = newSaramaClient
+	}
+	if k.now == nil {
+		k.now = time.Now
+	}
+	k.previous = make(map[partitionKey]sample)
+
+	return nil
+}
+
+func (k *KafkaLag) Start(telegraf.Accumulator) error {
+	c, err := k.newClient(k.Brokers, k.config)
+	if err != nil {
+		return &internal.StartupError{
+			Err:   fmt.Errorf("connecting failed: %w", err),
+			Retry: errors.Is(err, sarama.ErrOutOfBrokers),
+		}
+	}
+	k.client = c
+	return nil
+}
+
+func (k *KafkaLag) Gather(acc telegraf.Accumulator) error {
+	groups, err := k.client.groups()
+	if err != nil {
+		return fmt.Errorf("listing consumer groups failed: %w", err)
+	}
+	slices.Sort(groups)
+
+	now := k.now()
+	current := make(map[partitionKey]sample, len(k.previous))
+
+	// The log-end offsets are shared by all groups consuming a partition
+	newest := make(map[string]map[int32]int64)
+
+	for _, group := range groups {
+		if !k.groupFilter.Match(group) {
+			continue
+		}
+
+		committed, err := k.client.committed(group)
+		if err != nil {
+			acc.AddError(fmt.Errorf("fetching offsets of group %q failed: %w", group, err))
+			continue
+		}
+
+		for _, topic := range slices.Sorted(maps.Keys(committed)) {
+			if !k.topicFilter.Match(topic) {
+				continue
+			}
+
+			if _, found := newest[topic]; !found {
+				newest[topic] = make(map[int32]int64)
+			}
+
+			var sum summary
+			for _, partition := range slices.Sorted(maps.Keys(committed[topic])) {
+				offset := committed[topic][partition]
+				if offset < 0 {
+					// The group did not commit an offset for this partition
+					continue
+				}
+
+				end, found := newest[topic][partition]
+				if !found {
+					end, err = k.client.newest(topic, partition)
+					if err != nil {
+						acc.AddError(fmt.Errorf("fetching log-end offset of topic %q partition %d failed: %w", topic, partition, err))
+						continue
+					}
+					newest[topic][partition] = end
+				}
+
+				key := partitionKey{group: group, topic: topic, partition: partition}
+				current[key] = sample{committed: offset, newest: end, time: now}
+
+				// The committed offset can be ahead of the log-end offset
+				// if the latter was queried before the commit happened
+				lag := max(end-offset, 0)
+				sum.lag += lag
+				sum.maxLag = max(sum.maxLag, lag)
+				sum.partitions++
+
+				fields := map[string]interface{}{
+					"committed_offset": offset,
+					"log_end_offset":   end,
+					"lag":              lag,
+				}
+				if commitRate, produceRate, ok := rates(k.previous[key], current[key]); ok {
+					fields["commit_rate"] = commitRate
+					fields["produce_rate"] = produceRate
+					fields["lag_trend"] = produceRate - commitRate
+					sum.commitRate += commitRate
+					sum.produceRate += produceRate
+					sum.rates = true
+				}
+
+				if k.PartitionMetrics {
+					tags := map[string]string{
+						"group":     group,
+						"topic":     topic,
+						"partition": fmt.Sprint(partition),
+					}
+					acc.AddGauge("kafka_lag_partition", fields, tags, now)
+				}
+			}
+
+			if sum.partitions == 0 {
+				continue
+			}
+			fields := map[string]interface{}{
+				"lag":        sum.lag,
+				"max_lag":    sum.maxLag,
+				"partitions": sum.partitions,
+			}
+			if sum.rates {
+				fields["commit_rate"] = sum.commitRate
+				fields["produce_rate"] = sum.produceRate
+				fields["lag_trend"] = sum.produceRate - sum.commitRate
+			}
+			tags := map[string]string{
+				"group": group,
+				"topic": topic,
+			}
+			acc.AddGauge("kafka_lag_topic", fields, tags, now)
+		}
+	}
+
+	// Partitions not seen anymore are dropped with the previous state
+	k.previous = current
+
+	return nil
+}
+
+func (k *KafkaLag) Stop() {
+	if k.client == nil {
+		return
+	}
+	if err := k.client.close(); err != nil {
+		k.Log.Errorf("Closing client failed: %v", err)
+	}
+	k.client = nil
+}
+
+// rates computes the commit and produce rates in offsets per second between
+// the two samples. No rates are computed for the first sample or if the
+// offsets went backwards, e.g. due to a recreated topic or an offset reset.
+func rates(prev, cur sample) (commitRate, produceRate float64, ok bool) {
+	if prev.time.IsZero() || !cur.time.After(prev.time) {
+		return 0, 0, false
+	}
+	if cur.committed < prev.committed || cur.newest < prev.newest {
+		return 0, 0, false
+	}
+	elapsed := cur.time.Sub(prev.time).Seconds()
+	commitRate = float64(cur.committed-prev.committed) / elapsed
+	produceRate = float64(cur.newest-prev.newest) / elapsed
+	return commitRate, produceRate, true
+}
+
+func init() {
+	inputs.Add("kafka_lag", func() telegraf.Input {
+		return &KafkaLag{PartitionMetrics: true}
+	})
+}
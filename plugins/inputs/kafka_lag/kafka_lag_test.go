@@ -0,0 +1,242 @@
+package kafka_lag
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockClient struct {
+	offsets map[string]map[string]map[int32]int64
+	newests map[string]map[int32]int64
+	calls   int
+	closed  bool
+}
+
+func (m *mockClient) groups() ([]string, error) {
+	groups := make([]string, 0, len(m.offsets))
+	for g := range m.offsets {
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (m *mockClient) committed(group string) (map[string]map[int32]int64, error) {
+	if group == "broken" {
+		return nil, errors.New("coordinator not available")
+	}
+	return m.offsets[group], nil
+}
+
+func (m *mockClient) newest(topic string, partition int32) (int64, error) {
+	m.calls++
+	return m.newests[topic][partition], nil
+}
+
+func (m *mockClient) close() error {
+	m.closed = true
+	return nil
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &KafkaLag{Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "no brokers configured")
+
+	plugin = &KafkaLag{
+		Brokers: []string{"localhost:9092"},
+		Log:     testutil.Logger{},
+	}
+	plugin.Version = "foo"
+	require.ErrorContains(t, plugin.Init(), "invalid version")
+}
+
+func TestGather(t *testing.T) {
+	c := &mockClient{
+		offsets: map[string]map[string]map[int32]int64{
+			"billing": {
+				"orders": {0: 90, 1: 200},
+			},
+			"shipping": {
+				"orders": {0: 100, 1: -1},
+			},
+		},
+		newests: map[string]map[int32]int64{
+			"orders": {0: 100, 1: 250},
+		},
+	}
+	now := time.Unix(1000, 0)
+	plugin := &KafkaLag{
+		Brokers:          []string{"localhost:9092"},
+		PartitionMetrics: true,
+		Log:              testutil.Logger{},
+		newClient:        func([]string, *sarama.Config) (client, error) { return c, nil },
+		now:              func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_lag_partition",
+			map[string]string{"group": "billing", "topic": "orders", "partition": "0"},
+			map[string]interface{}{"committed_offset": int64(90), "log_end_offset": int64(100), "lag": int64(10)},
+			now,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"kafka_lag_partition",
+			map[string]string{"group": "billing", "topic": "orders", "partition": "1"},
+			map[string]interface{}{"committed_offset": int64(200), "log_end_offset": int64(250), "lag": int64(50)},
+			now,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"kafka_lag_topic",
+			map[string]string{"group": "billing", "topic": "orders"},
+			map[string]interface{}{"lag": int64(60), "max_lag": int64(50), "partitions": 2},
+			now,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"kafka_lag_partition",
+			map[string]string{"group": "shipping", "topic": "orders", "partition": "0"},
+			map[string]interface{}{"committed_offset": int64(100), "log_end_offset": int64(100), "lag": int64(0)},
+			now,
+			telegraf.Gauge,
+		),
+		metric.New(
+			"kafka_lag_topic",
+			map[string]string{"group": "shipping", "topic": "orders"},
+			map[string]interface{}{"lag": int64(0), "max_lag": int64(0), "partitions": 1},
+			now,
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Log-end offsets are shared between the groups
+	require.Equal(t, 2, c.calls)
+}
+
+func TestGatherTrend(t *testing.T) {
+	c := &mockClient{
+		offsets: map[string]map[string]map[int32]int64{
+			"billing": {"orders": {0: 100}},
+		},
+		newests: map[string]map[int32]int64{
+			"orders": {0: 150},
+		},
+	}
+	now := time.Unix(1000, 0)
+	plugin := &KafkaLag{
+		Brokers:          []string{"localhost:9092"},
+		PartitionMetrics: true,
+		Log:              testutil.Logger{},
+		newClient:        func([]string, *sarama.Config) (client, error) { return c, nil },
+		now:              func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+	plugin.PartitionMetrics = false
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+
+	// The consumer falls behind within the next ten seconds
+	now = now.Add(10 * time.Second)
+	c.offsets["billing"]["orders"][0] = 200
+	c.newests["orders"][0] = 400
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_lag_topic",
+			map[string]string{"group": "billing", "topic": "orders"},
+			map[string]interface{}{
+				"lag":          int64(200),
+				"max_lag":      int64(200),
+				"partitions":   1,
+				"commit_rate":  float64(10),
+				"produce_rate": float64(25),
+				"lag_trend":    float64(15),
+			},
+			now,
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Resetting the offsets must not produce negative rates
+	now = now.Add(10 * time.Second)
+	c.offsets["billing"]["orders"][0] = 0
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"kafka_lag_topic",
+			map[string]string{"group": "billing", "topic": "orders"},
+			map[string]interface{}{"lag": int64(400), "max_lag": int64(400), "partitions": 1},
+			now,
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestGatherFilter(t *testing.T) {
+	c := &mockClient{
+		offsets: map[string]map[string]map[int32]int64{
+			"billing":  {"orders": {0: 1}, "__internal": {0: 1}},
+			"shipping": {"orders": {0: 1}},
+			"broken":   {},
+		},
+		newests: map[string]map[int32]int64{
+			"orders":     {0: 1},
+			"__internal": {0: 1},
+		},
+	}
+	now := time.Unix(1000, 0)
+	plugin := &KafkaLag{
+		Brokers:   []string{"localhost:9092"},
+		Groups:    []string{"b*"},
+		Topics:    []string{"orders"},
+		Log:       testutil.Logger{},
+		newClient: func([]string, *sarama.Config) (client, error) { return c, nil },
+		now:       func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.True(t, c.closed)
+
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `group "broken"`)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_lag_topic",
+			map[string]string{"group": "billing", "topic": "orders"},
+			map[string]interface{}{"lag": int64(0), "max_lag": int64(0), "partitions": 1},
+			now,
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
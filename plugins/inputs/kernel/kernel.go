@@ -31,12 +31,15 @@ var (
 
 type Kernel struct {
 	ConfigCollect []string `toml:"collect"`
+	PSICgroups    []string `toml:"psi_cgroups"`
 
 	optCollect      map[string]bool
 	statFile        string
 	entropyStatFile string
 	ksmStatsDir     string
 	psiDir          string
+	cgroupDir       string
+	numaNodeDir     string
 	procfs          procfs.FS
 }
 
@@ -64,6 +67,17 @@ func (k *Kernel) Init() error {
 			return fmt.Errorf("failed to initialize procfs on %s: %w", procdir, err)
 		}
 	}
+	if k.optCollect["psi_cgroup"] {
+		if _, err := os.Stat(filepath.Join(k.cgroupDir, "cgroup.controllers")); err != nil {
+			// per-cgroup pressure is only available with the unified hierarchy
+			return fmt.Errorf("cgroup v2 hierarchy not found at %q: %w", k.cgroupDir, err)
+		}
+		for _, pattern := range k.PSICgroups {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -163,6 +177,18 @@ func (k *Kernel) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if k.optCollect["psi_cgroup"] {
+		if err := k.gatherCgroupPressure(acc); err != nil {
+			return err
+		}
+	}
+
+	if k.optCollect["numa"] {
+		if err := k.gatherNUMA(acc); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -202,6 +228,9 @@ func init() {
 			entropyStatFile: "/proc/sys/kernel/random/entropy_avail",
 			ksmStatsDir:     "/sys/kernel/mm/ksm",
 			psiDir:          "/proc/pressure",
+			cgroupDir:       "/sys/fs/cgroup",
+			numaNodeDir:     "/sys/devices/system/node",
+			PSICgroups:      []string{"*.slice"},
 		}
 	})
 }
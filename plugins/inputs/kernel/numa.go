@@ -0,0 +1,104 @@
+//go:build linux
+
+package kernel
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Per-node memory information reported in kB and the corresponding field names
+var numaMeminfoFields = map[string]string{
+	"MemTotal":  "mem_total",
+	"MemFree":   "mem_free",
+	"MemUsed":   "mem_used",
+	"Active":    "active",
+	"Inactive":  "inactive",
+	"FilePages": "file_pages",
+	"AnonPages": "anon_pages",
+	"Shmem":     "shmem",
+	"Dirty":     "dirty",
+	"Writeback": "writeback",
+	"Slab":      "slab",
+}
+
+// Prefixes of the per-node vmstat counters indicating memory pressure, i.e.
+// reclaim activity, refaults and page migration between tiers
+var numaVmstatPrefixes = []string{"pgscan_", "pgsteal_", "pgdemote_", "pgpromote_", "workingset_"}
+
+// Gather per-NUMA-node memory statistics
+func (k *Kernel) gatherNUMA(acc telegraf.Accumulator) error {
+	nodes, err := filepath.Glob(filepath.Join(k.numaNodeDir, "node[0-9]*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range nodes {
+		node := strings.TrimPrefix(filepath.Base(dir), "node")
+		fields := make(map[string]interface{})
+
+		// Lines have the form "Node 0 MemTotal:       16384000 kB"
+		err := readKeyValues(filepath.Join(dir, "meminfo"), 2, func(key string, value int64) {
+			if name, found := numaMeminfoFields[strings.TrimSuffix(key, ":")]; found {
+				fields[name] = value * 1024
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read meminfo of node %s: %w", node, err)
+		}
+
+		err = readKeyValues(filepath.Join(dir, "numastat"), 0, func(key string, value int64) {
+			fields[key] = value
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read numastat of node %s: %w", node, err)
+		}
+
+		// Per-node vmstat is only available since kernel 4.8
+		err = readKeyValues(filepath.Join(dir, "vmstat"), 0, func(key string, value int64) {
+			for _, prefix := range numaVmstatPrefixes {
+				if strings.HasPrefix(key, prefix) {
+					fields[key] = value
+					return
+				}
+			}
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read vmstat of node %s: %w", node, err)
+		}
+
+		acc.AddFields("kernel_numa", fields, map[string]string{"node": node})
+	}
+	return nil
+}
+
+// readKeyValues calls the given function for each line of the file with the
+// key found at the given column and the integer value following it
+func readKeyValues(path string, column int, fn func(key string, value int64)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < column+2 {
+			continue
+		}
+		value, err := strconv.ParseInt(parts[column+1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q as an integer: %w", parts[column+1], err)
+		}
+		fn(parts[column], value)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestNUMAStats(t *testing.T) {
+	k := Kernel{
+		numaNodeDir:   "testdata/node",
+		ConfigCollect: []string{"numa"},
+	}
+	require.NoError(t, k.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.gatherNUMA(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"kernel_numa",
+			map[string]string{"node": "0"},
+			map[string]interface{}{
+				"mem_total":                int64(16777216000),
+				"mem_free":                 int64(8388608000),
+				"mem_used":                 int64(8388608000),
+				"active":                   int64(4194304000),
+				"inactive":                 int64(2097152000),
+				"dirty":                    int64(131072),
+				"writeback":                int64(0),
+				"file_pages":               int64(3145728000),
+				"anon_pages":               int64(2097152000),
+				"shmem":                    int64(10485760),
+				"slab":                     int64(524288000),
+				"numa_hit":                 int64(123456),
+				"numa_miss":                int64(12),
+				"numa_foreign":             int64(34),
+				"interleave_hit":           int64(56),
+				"local_node":               int64(123400),
+				"other_node":               int64(56),
+				"workingset_refault_anon":  int64(10),
+				"workingset_refault_file":  int64(200),
+				"workingset_activate_anon": int64(5),
+				"pgpromote_success":        int64(7),
+				"pgdemote_kswapd":          int64(3),
+				"pgsteal_kswapd":           int64(1000),
+				"pgsteal_direct":           int64(20),
+				"pgscan_kswapd":            int64(1500),
+				"pgscan_direct":            int64(40),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kernel_numa",
+			map[string]string{"node": "1"},
+			map[string]interface{}{
+				"mem_total":      int64(16777216000),
+				"mem_free":       int64(16384000000),
+				"mem_used":       int64(393216000),
+				"numa_hit":       int64(1000),
+				"numa_miss":      int64(0),
+				"numa_foreign":   int64(0),
+				"interleave_hit": int64(0),
+				"local_node":     int64(1000),
+				"other_node":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -3,7 +3,14 @@
 package kernel
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/procfs"
@@ -13,39 +20,136 @@ import (
 
 // Gather PSI metrics
 func (k *Kernel) gatherPressure(acc telegraf.Accumulator) error {
-	for _, resource := range []string{"cpu", "memory", "io"} {
+	for _, resource := range []string{"cpu", "memory", "io", "irq"} {
 		now := time.Now()
 		psiStats, err := k.procfs.PSIStatsForResource(resource)
 		if err != nil {
+			// irq pressure requires kernel 6.1 or later with CONFIG_IRQ_TIME_ACCOUNTING
+			if resource == "irq" && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
 			return fmt.Errorf("failed to read %s pressure: %w", resource, err)
 		}
 
-		stats := map[string]*procfs.PSILine{
-			"some": psiStats.Some,
-			"full": psiStats.Full,
+		// resource=cpu,type=full is omitted because it is always zero
+		addPressure(acc, resource, psiStats, map[string]string{}, resource != "cpu", now)
+	}
+	return nil
+}
+
+// Gather PSI metrics of the cgroups matching the configured patterns
+func (k *Kernel) gatherCgroupPressure(acc telegraf.Accumulator) error {
+	for _, pattern := range k.PSICgroups {
+		dirs, err := filepath.Glob(filepath.Join(k.cgroupDir, pattern))
+		if err != nil {
+			return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
 		}
 
-		for _, typ := range []string{"some", "full"} {
-			if resource == "cpu" && typ == "full" {
-				// resource=cpu,type=full is omitted because it is always zero
-				continue
+		for _, dir := range dirs {
+			cgroup, err := filepath.Rel(k.cgroupDir, dir)
+			if err != nil {
+				return err
 			}
 
-			tags := map[string]string{
-				"resource": resource,
-				"type":     typ,
-			}
-			stat := stats[typ]
+			for _, resource := range []string{"cpu", "memory", "io", "irq"} {
+				now := time.Now()
+				data, err := os.ReadFile(filepath.Join(dir, resource+".pressure"))
+				if errors.Is(err, os.ErrNotExist) {
+					// the controller is not enabled for the cgroup
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to read %s pressure of cgroup %q: %w", resource, cgroup, err)
+				}
 
-			acc.AddCounter("pressure", map[string]interface{}{
-				"total": stat.Total,
-			}, tags, now)
-			acc.AddGauge("pressure", map[string]interface{}{
-				"avg10":  stat.Avg10,
-				"avg60":  stat.Avg60,
-				"avg300": stat.Avg300,
-			}, tags, now)
+				psiStats, err := parsePressure(data)
+				if err != nil {
+					return fmt.Errorf("failed to parse %s pressure of cgroup %q: %w", resource, cgroup, err)
+				}
+
+				// cgroups report non-zero full cpu pressure in contrast to the system-wide values
+				addPressure(acc, resource, psiStats, map[string]string{"cgroup": cgroup}, true, now)
+			}
 		}
 	}
 	return nil
 }
+
+func addPressure(acc telegraf.Accumulator, resource string, psiStats procfs.PSIStats, baseTags map[string]string, withFull bool, now time.Time) {
+	stats := map[string]*procfs.PSILine{
+		"some": psiStats.Some,
+		"full": psiStats.Full,
+	}
+
+	for _, typ := range []string{"some", "full"} {
+		if typ == "full" && !withFull {
+			continue
+		}
+		stat := stats[typ]
+		if stat == nil {
+			continue
+		}
+
+		tags := make(map[string]string, len(baseTags)+2)
+		for k, v := range baseTags {
+			tags[k] = v
+		}
+		tags["resource"] = resource
+		tags["type"] = typ
+
+		acc.AddCounter("pressure", map[string]interface{}{
+			"total": stat.Total,
+		}, tags, now)
+		acc.AddGauge("pressure", map[string]interface{}{
+			"avg10":  stat.Avg10,
+			"avg60":  stat.Avg60,
+			"avg300": stat.Avg300,
+		}, tags, now)
+	}
+}
+
+// parsePressure parses the content of a cgroup pressure file in the same
+// format as the files in /proc/pressure
+func parsePressure(data []byte) (procfs.PSIStats, error) {
+	var psiStats procfs.PSIStats
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			continue
+		}
+
+		line := &procfs.PSILine{}
+		for _, kv := range parts[1:] {
+			key, value, found := strings.Cut(kv, "=")
+			if !found {
+				return psiStats, fmt.Errorf("malformed entry %q", kv)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				line.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				line.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				line.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				line.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return psiStats, fmt.Errorf("parsing %q failed: %w", kv, err)
+			}
+		}
+
+		switch parts[0] {
+		case "some":
+			psiStats.Some = line
+		case "full":
+			psiStats.Full = line
+		default:
+			return psiStats, fmt.Errorf("unknown pressure type %q", parts[0])
+		}
+	}
+	return psiStats, scanner.Err()
+}
@@ -163,8 +163,108 @@ func TestPSIStats(t *testing.T) {
 			time.Unix(0, 0),
 			telegraf.Counter,
 		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"resource": "irq",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"avg10":  float64(0.5),
+				"avg60":  float64(0.25),
+				"avg300": float64(0.1),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"resource": "irq",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"total": uint64(4242),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
 	}
 
 	actual := acc.GetTelegrafMetrics()
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestPSICgroupNoHierarchy(t *testing.T) {
+	k := Kernel{
+		cgroupDir:     "testdata/pressure",
+		ConfigCollect: []string{"psi_cgroup"},
+	}
+
+	require.ErrorContains(t, k.Init(), "cgroup v2 hierarchy not found")
+}
+
+func TestPSICgroupStats(t *testing.T) {
+	k := Kernel{
+		cgroupDir:     "testdata/cgroup",
+		PSICgroups:    []string{"*.slice"},
+		ConfigCollect: []string{"psi_cgroup"},
+	}
+	require.NoError(t, k.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.gatherCgroupPressure(&acc))
+
+	expected := make([]telegraf.Metric, 0, 12)
+	for _, e := range []struct {
+		cgroup   string
+		resource string
+		typ      string
+		avg      [3]float64
+		total    uint64
+	}{
+		{"system.slice", "cpu", "some", [3]float64{2, 1.5, 1}, 2000},
+		{"system.slice", "cpu", "full", [3]float64{1, 0.75, 0.5}, 1000},
+		{"system.slice", "memory", "some", [3]float64{0, 0, 0}, 300},
+		{"system.slice", "memory", "full", [3]float64{0, 0, 0}, 100},
+		{"user.slice", "cpu", "some", [3]float64{5, 4, 3}, 5000},
+		{"user.slice", "cpu", "full", [3]float64{0, 0, 0}, 0},
+	} {
+		tags := map[string]string{
+			"cgroup":   e.cgroup,
+			"resource": e.resource,
+			"type":     e.typ,
+		}
+		expected = append(expected,
+			metric.New(
+				"pressure",
+				tags,
+				map[string]interface{}{
+					"avg10":  e.avg[0],
+					"avg60":  e.avg[1],
+					"avg300": e.avg[2],
+				},
+				time.Unix(0, 0),
+				telegraf.Gauge,
+			),
+			metric.New(
+				"pressure",
+				tags,
+				map[string]interface{}{"total": e.total},
+				time.Unix(0, 0),
+				telegraf.Counter,
+			),
+		)
+	}
+
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestParsePressureInvalid(t *testing.T) {
+	_, err := parsePressure([]byte("some avg10=abc avg60=0.00 avg300=0.00 total=0\n"))
+	require.ErrorContains(t, err, "parsing \"avg10=abc\" failed")
+
+	_, err = parsePressure([]byte("partial avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"))
+	require.ErrorContains(t, err, "unknown pressure type")
+}
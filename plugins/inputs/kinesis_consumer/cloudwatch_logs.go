@@ -0,0 +1,56 @@
+package kinesis_consumer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+)
+
+// cloudwatchLogsEnvelope is the JSON document CloudWatch Logs subscription
+// filters deliver to Kinesis, see
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/SubscriptionFilters.html
+type cloudwatchLogsEnvelope struct {
+	MessageType         string                `json:"messageType"`
+	Owner               string                `json:"owner"`
+	LogGroup            string                `json:"logGroup"`
+	LogStream           string                `json:"logStream"`
+	SubscriptionFilters []string              `json:"subscriptionFilters"`
+	LogEvents           []cloudwatchLogsEvent `json:"logEvents"`
+}
+
+type cloudwatchLogsEvent struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// parseCloudWatchLogs unwraps the (already decompressed) subscription
+// envelope and feeds each log event message to the parser. Control messages
+// sent by CloudWatch to check the destination are skipped.
+func parseCloudWatchLogs(parser telegraf.Parser, data []byte) ([]telegraf.Metric, error) {
+	var envelope cloudwatchLogsEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("decoding CloudWatch Logs envelope failed: %w", err)
+	}
+
+	if envelope.MessageType == "CONTROL_MESSAGE" {
+		return nil, nil
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(envelope.LogEvents))
+	for _, event := range envelope.LogEvents {
+		ms, err := parser.Parse([]byte(event.Message))
+		if err != nil {
+			return nil, fmt.Errorf("parsing log event %q failed: %w", event.ID, err)
+		}
+		for _, m := range ms {
+			m.AddTag("owner", envelope.Owner)
+			m.AddTag("log_group", envelope.LogGroup)
+			m.AddTag("log_stream", envelope.LogStream)
+		}
+		metrics = append(metrics, ms...)
+	}
+
+	return metrics, nil
+}
@@ -2,17 +2,17 @@
 package kinesis_consumer
 
 import (
-	"bytes"
-	"compress/gzip"
-	"compress/zlib"
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"io"
-	"math/big"
+	"math"
+	"math/rand"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -23,53 +23,90 @@ import (
 	"github.com/harlow/kinesis-consumer/store/ddb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/common/checkpoint"
+	common_consumer "github.com/influxdata/telegraf/plugins/common/consumer"
+	"github.com/influxdata/telegraf/plugins/common/kpl"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
-var (
-	once sync.Once
-	// this is the largest sequence number allowed - https://docs.aws.amazon.com/kinesis/latest/APIReference/API_SequenceNumberRange.html
-	maxSeq = strToBint(strings.Repeat("9", 129))
-	negOne *big.Int
-)
+var once sync.Once
 
 const (
 	defaultMaxUndeliveredMessages = 1000
+	defaultReconnectInterval      = time.Second
+	defaultMaxReconnectInterval   = 5 * time.Minute
 )
 
 type (
 	KinesisConsumer struct {
-		StreamName             string    `toml:"streamname"`
-		ShardIteratorType      string    `toml:"shard_iterator_type"`
-		DynamoDB               *dynamoDB `toml:"checkpoint_dynamodb"`
-		MaxUndeliveredMessages int       `toml:"max_undelivered_messages"`
-		ContentEncoding        string    `toml:"content_encoding"`
+		StreamName             string                       `toml:"streamname"`
+		StreamNames            []string                     `toml:"streamnames"`
+		ShardIteratorType      string                       `toml:"shard_iterator_type"`
+		StartTimestamp         string                       `toml:"start_timestamp"`
+		StartSequenceNumber    string                       `toml:"start_sequence_number"`
+		CheckpointBackend      string                       `toml:"checkpoint_backend"`
+		DynamoDB               *dynamoDB                    `toml:"checkpoint_dynamodb"`
+		File                   *checkpoint.FileConfig       `toml:"checkpoint_file"`
+		Redis                  *checkpoint.RedisConfig      `toml:"checkpoint_redis"`
+		PostgreSQL             *checkpoint.PostgreSQLConfig `toml:"checkpoint_postgresql"`
+		CheckpointInterval     config.Duration              `toml:"checkpoint_interval"`
+		CheckpointBatch        int                          `toml:"checkpoint_batch"`
+		MaxUndeliveredMessages int                          `toml:"max_undelivered_messages"`
+		ContentEncoding        string                       `toml:"content_encoding"`
+		IncludeRecordMetadata  []string                     `toml:"include_record_metadata"`
+		MaxReconnectInterval   config.Duration              `toml:"max_reconnect_interval"`
+		MaxProcessingWorkers   int                          `toml:"max_processing_workers"`
+		MaxRecordsPerSecond    int                          `toml:"max_records_per_second"`
+		MaxBytesPerSecond      config.Size                  `toml:"max_bytes_per_second"`
+		ShardIDs               []string                     `toml:"shard_ids"`
+		ShardHashKeyRanges     []string                     `toml:"shard_hash_key_ranges"`
 
 		Log telegraf.Logger `toml:"-"`
 
-		cons   *consumer.Consumer
-		parser telegraf.Parser
-		cancel context.CancelFunc
-		acc    telegraf.TrackingAccumulator
-		sem    chan struct{}
-
-		checkpoint    consumer.Store
-		checkpoints   map[string]checkpoint
-		records       map[telegraf.TrackingID]string
-		checkpointTex sync.Mutex
-		recordsTex    sync.Mutex
-		wg            sync.WaitGroup
-
-		processContentEncodingFunc processContent
-
-		lastSeqNum *big.Int
+		consumers  []scanner
+		newScanner func(stream string) (scanner, error)
+		parser     telegraf.Parser
+		parserFunc telegraf.ParserFunc
+		policy     *common_consumer.ErrorPolicy
+		unwrapper  *common_consumer.Unwrapper
+		limiter    *rateLimiter
+		shards     *shardFilter
+		cancel     context.CancelFunc
+		stopScan   context.CancelFunc
+		scanners   sync.WaitGroup
+		acc        telegraf.TrackingAccumulator
+		sem        chan struct{}
+
+		connected   atomic.Bool
+		undelivered atomic.Int64
+
+		checkpoint checkpoint.Store
+		tracker    *checkpoint.Tracker
+		records    map[telegraf.TrackingID]*checkpoint.Record
+		recordsTex sync.Mutex
+		statsTex   sync.Mutex
+		wg         sync.WaitGroup
+
+		encoding          string
+		decoder           internal.ContentDecoder
+		decoderTex        sync.Mutex
+		jobs              chan *processingJob
+		startTimestamp    time.Time
+		reconnectInterval time.Duration
+		stats             map[string]*streamStats
 
 		common_aws.CredentialConfig
+		common_aws.ClientConfig
+		common_consumer.ErrorPolicyConfig
+		common_consumer.UnwrapConfig
 	}
 
 	dynamoDB struct {
@@ -77,26 +114,164 @@ type (
 		TableName string `toml:"table_name"`
 	}
 
-	checkpoint struct {
-		streamName string
-		shardID    string
+	// processingJob is a record handed over to the processing workers. The
+	// record is already tracked to keep the checkpoints in shard order.
+	processingJob struct {
+		stream string
+		record *consumer.Record
+		status *checkpoint.Record
 	}
 )
 
-type processContent func([]byte) ([]byte, error)
+// scanner consumes the records of a stream, implemented by consumer.Consumer
+type scanner interface {
+	Scan(ctx context.Context, fn consumer.ScanFunc) error
+}
 
 func (*KinesisConsumer) SampleConfig() string {
 	return sampleConfig
 }
 
 func (k *KinesisConsumer) Init() error {
-	return k.configureProcessContentEncodingFunc()
+	if k.ShardIteratorType == "" {
+		k.ShardIteratorType = "TRIM_HORIZON"
+	}
+
+	switch k.ShardIteratorType {
+	case "TRIM_HORIZON", "LATEST":
+	case "AT_TIMESTAMP":
+		if k.StartTimestamp == "" {
+			return errors.New("'start_timestamp' required for shard iterator type AT_TIMESTAMP")
+		}
+		ts, err := time.Parse(time.RFC3339, k.StartTimestamp)
+		if err != nil {
+			return fmt.Errorf("parsing 'start_timestamp' failed: %w", err)
+		}
+		k.startTimestamp = ts
+	case "AT_SEQUENCE_NUMBER":
+		if k.StartSequenceNumber == "" {
+			return errors.New("'start_sequence_number' required for shard iterator type AT_SEQUENCE_NUMBER")
+		}
+	default:
+		return fmt.Errorf("invalid shard iterator type %q", k.ShardIteratorType)
+	}
+
+	// Keep backward compatibility by using DynamoDB if configured
+	if k.CheckpointBackend == "" {
+		k.CheckpointBackend = "none"
+		if k.DynamoDB != nil {
+			k.CheckpointBackend = "dynamodb"
+		}
+	}
+
+	switch k.CheckpointBackend {
+	case "none":
+	case "dynamodb":
+		if k.DynamoDB == nil {
+			return errors.New("'checkpoint_dynamodb' section required for 'dynamodb' checkpoint backend")
+		}
+	case "file":
+		if k.File == nil {
+			return errors.New("'checkpoint_file' section required for 'file' checkpoint backend")
+		}
+	case "redis":
+		if k.Redis == nil {
+			return errors.New("'checkpoint_redis' section required for 'redis' checkpoint backend")
+		}
+	case "postgresql":
+		if k.PostgreSQL == nil {
+			return errors.New("'checkpoint_postgresql' section required for 'postgresql' checkpoint backend")
+		}
+	default:
+		return fmt.Errorf("invalid checkpoint backend %q", k.CheckpointBackend)
+	}
+
+	if k.CheckpointInterval < 0 {
+		return errors.New("'checkpoint_interval' must not be negative")
+	}
+	if k.CheckpointBatch < 0 {
+		return errors.New("'checkpoint_batch' must not be negative")
+	}
+
+	if k.reconnectInterval <= 0 {
+		k.reconnectInterval = defaultReconnectInterval
+	}
+	if k.MaxReconnectInterval == 0 {
+		k.MaxReconnectInterval = config.Duration(defaultMaxReconnectInterval)
+	}
+	if time.Duration(k.MaxReconnectInterval) < k.reconnectInterval {
+		return fmt.Errorf("'max_reconnect_interval' must be at least %s", k.reconnectInterval)
+	}
+
+	if k.MaxRecordsPerSecond < 0 {
+		return errors.New("'max_records_per_second' must not be negative")
+	}
+	if k.MaxBytesPerSecond > math.MaxInt32 {
+		return fmt.Errorf("'max_bytes_per_second' must not exceed %d", math.MaxInt32)
+	}
+	k.limiter = newRateLimiter(k.MaxRecordsPerSecond, int(k.MaxBytesPerSecond))
+
+	shards, err := newShardFilter(k.ShardIDs, k.ShardHashKeyRanges)
+	if err != nil {
+		return err
+	}
+	k.shards = shards
+
+	if k.MaxProcessingWorkers < 0 {
+		return errors.New("'max_processing_workers' must not be negative")
+	}
+	if k.MaxProcessingWorkers > 1 && k.parserFunc == nil {
+		return errors.New("parallel processing requires a parser function")
+	}
+
+	for _, item := range k.IncludeRecordMetadata {
+		switch item {
+		case "shard_id", "partition_key", "sequence_number", "approximate_arrival_timestamp":
+		default:
+			return fmt.Errorf("invalid record metadata %q", item)
+		}
+	}
+
+	// CloudWatch Logs subscription data is always gzip compressed
+	var encoding string
+	switch k.ContentEncoding {
+	case "none", "identity", "":
+		encoding = "identity"
+	case "cloudwatch_logs":
+		encoding = "gzip"
+	default:
+		encoding = k.ContentEncoding
+	}
+	decoder, err := internal.NewContentDecoder(encoding)
+	if err != nil || encoding == "auto" {
+		return fmt.Errorf("unknown content encoding %q", k.ContentEncoding)
+	}
+	k.encoding = encoding
+	k.decoder = decoder
+
+	policy, err := common_consumer.NewErrorPolicy(k.ErrorPolicyConfig, map[string]string{"input": "kinesis_consumer"}, k.Log)
+	if err != nil {
+		return err
+	}
+	k.policy = policy
+
+	unwrapper, err := common_consumer.NewUnwrapper(k.UnwrapConfig)
+	if err != nil {
+		return err
+	}
+	k.unwrapper = unwrapper
+
+	return nil
 }
 
 func (k *KinesisConsumer) SetParser(parser telegraf.Parser) {
 	k.parser = parser
 }
 
+func (k *KinesisConsumer) SetParserFunc(fn telegraf.ParserFunc) {
+	k.parserFunc = fn
+}
+
 func (k *KinesisConsumer) Start(ac telegraf.Accumulator) error {
 	err := k.connect(ac)
 	if err != nil {
@@ -107,17 +282,49 @@ func (k *KinesisConsumer) Start(ac telegraf.Accumulator) error {
 }
 
 func (k *KinesisConsumer) Gather(acc telegraf.Accumulator) error {
-	if k.cons == nil {
+	if len(k.consumers) == 0 {
 		return k.connect(acc)
 	}
-	k.lastSeqNum = maxSeq
 
 	return nil
 }
 
 func (k *KinesisConsumer) Stop() {
+	k.connected.Store(false)
 	k.cancel()
 	k.wg.Wait()
+	k.closeCheckpointStore()
+	if err := k.policy.Close(); err != nil {
+		k.Log.Errorf("Closing dead-letter file failed: %v", err)
+	}
+}
+
+// Drain stops reading new records and waits until the metrics of the records
+// already read are delivered, so their checkpoints are written on Stop
+func (k *KinesisConsumer) Drain(ctx context.Context) error {
+	if k.stopScan == nil {
+		return nil
+	}
+	k.stopScan()
+	k.scanners.Wait()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for k.undelivered.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d record(s) still undelivered: %w", k.undelivered.Load(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// halt stops consuming all streams without checkpointing the current record
+// so it is read again after a restart
+func (k *KinesisConsumer) halt() {
+	k.Log.Error("Stopped consuming due to parse error, restart to resume")
+	k.cancel()
 }
 
 // GetCheckpoint wraps the checkpoint's GetCheckpoint function (called by consumer library)
@@ -125,20 +332,23 @@ func (k *KinesisConsumer) GetCheckpoint(streamName, shardID string) (string, err
 	return k.checkpoint.GetCheckpoint(streamName, shardID)
 }
 
-// SetCheckpoint wraps the checkpoint's SetCheckpoint function (called by consumer library)
-func (k *KinesisConsumer) SetCheckpoint(streamName, shardID, sequenceNumber string) error {
+// SetCheckpoint is called by the consumer library after handing over a record.
+// The checkpoints are only persisted by the tracker after the metrics of the
+// record are delivered.
+func (k *KinesisConsumer) SetCheckpoint(_, _, sequenceNumber string) error {
 	if sequenceNumber == "" {
 		return errors.New("sequence number should not be empty")
 	}
-
-	k.checkpointTex.Lock()
-	k.checkpoints[sequenceNumber] = checkpoint{streamName: streamName, shardID: shardID}
-	k.checkpointTex.Unlock()
-
 	return nil
 }
 
 func (k *KinesisConsumer) connect(ac telegraf.Accumulator) error {
+	httpClient, err := k.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	k.CredentialConfig.HTTPClient = httpClient
+
 	cfg, err := k.CredentialConfig.Credentials()
 	if err != nil {
 		return err
@@ -151,43 +361,81 @@ func (k *KinesisConsumer) connect(ac telegraf.Accumulator) error {
 	logWrapper := &telegrafLoggerWrapper{k.Log}
 	cfg.Logger = logWrapper
 	cfg.ClientLogMode = aws.LogRetries
-	client := kinesis.NewFromConfig(cfg)
-
-	k.checkpoint = &noopStore{}
-	if k.DynamoDB != nil {
-		var err error
-		k.checkpoint, err = ddb.New(
-			k.DynamoDB.AppName,
-			k.DynamoDB.TableName,
-			ddb.WithDynamoClient(dynamodb.NewFromConfig(cfg)),
-			ddb.WithMaxInterval(time.Second*10),
-		)
-		if err != nil {
-			return err
+	var client kinesisClient = kinesis.NewFromConfig(cfg)
+	if k.ShardIteratorType == "AT_SEQUENCE_NUMBER" {
+		client = &sequenceNumberClient{
+			kinesisClient:  client,
+			sequenceNumber: k.StartSequenceNumber,
+			log:            k.Log,
 		}
 	}
 
-	cons, err := consumer.New(
-		k.StreamName,
+	// Make sure a previous consumer is completely shut down before replacing
+	// the checkpoint store
+	k.wg.Wait()
+	k.closeCheckpointStore()
+	k.checkpoint, err = k.createCheckpointStore(cfg)
+	if err != nil {
+		return fmt.Errorf("creating checkpoint store failed: %w", err)
+	}
+
+	streams, err := k.resolveStreams(client)
+	if err != nil {
+		return err
+	}
+
+	opts := []consumer.Option{
 		consumer.WithClient(client),
 		consumer.WithShardIteratorType(k.ShardIteratorType),
 		consumer.WithStore(k),
 		consumer.WithLogger(logWrapper),
-	)
-	if err != nil {
-		return err
+	}
+	if k.ShardIteratorType == "AT_TIMESTAMP" {
+		opts = append(opts, consumer.WithTimestamp(k.startTimestamp))
 	}
 
-	k.cons = cons
+	k.newScanner = func(stream string) (scanner, error) {
+		if k.shards == nil {
+			return consumer.New(stream, opts...)
+		}
+		group := &filteredGroup{
+			AllGroup: consumer.NewAllGroup(client, k, stream, logWrapper),
+			filter:   k.shards,
+			log:      k.Log,
+		}
+		return consumer.New(stream, append(slices.Clip(opts), consumer.WithGroup(group))...)
+	}
+
+	consumers := make([]scanner, 0, len(streams))
+	for _, stream := range streams {
+		cons, err := k.newScanner(stream)
+		if err != nil {
+			return fmt.Errorf("creating consumer for stream %q failed: %w", stream, err)
+		}
+		consumers = append(consumers, cons)
+	}
+	k.consumers = consumers
 
 	k.acc = ac.WithTracking(k.MaxUndeliveredMessages)
-	k.records = make(map[telegraf.TrackingID]string, k.MaxUndeliveredMessages)
-	k.checkpoints = make(map[string]checkpoint, k.MaxUndeliveredMessages)
+	k.records = make(map[telegraf.TrackingID]*checkpoint.Record, k.MaxUndeliveredMessages)
 	k.sem = make(chan struct{}, k.MaxUndeliveredMessages)
+	k.undelivered.Store(0)
+
+	// Write each checkpoint immediately if not batching
+	batch := k.CheckpointBatch
+	if k.CheckpointInterval == 0 && batch == 0 {
+		batch = 1
+	}
+	k.tracker = checkpoint.NewTracker(batch, k.writeCheckpoint)
 
 	ctx := context.Background()
 	ctx, k.cancel = context.WithCancel(ctx)
 
+	if err := k.startWorkers(ctx); err != nil {
+		k.cancel()
+		return err
+	}
+
 	k.wg.Add(1)
 	go func() {
 		defer k.wg.Done()
@@ -197,53 +445,360 @@ func (k *KinesisConsumer) connect(ac telegraf.Accumulator) error {
 	k.wg.Add(1)
 	go func() {
 		defer k.wg.Done()
-		err := k.cons.Scan(ctx, func(r *consumer.Record) error {
+		k.tracker.Run(ctx, time.Duration(k.CheckpointInterval))
+	}()
+
+	// Start one scanner per stream, all streams share the limit of
+	// undelivered messages and the checkpoint store. The scanners use their
+	// own context to stop reading while still handling deliveries on drain.
+	scanCtx, stopScan := context.WithCancel(ctx)
+	k.stopScan = stopScan
+	for i, cons := range consumers {
+		stream := streams[i]
+		k.wg.Add(1)
+		k.scanners.Add(1)
+		go func() {
+			defer k.wg.Done()
+			defer k.scanners.Done()
+			k.scan(scanCtx, stream, cons)
+		}()
+	}
+	k.connected.Store(true)
+
+	return nil
+}
+
+// startWorkers starts the workers parsing records concurrently if requested.
+// Each worker needs its own parser and decoder as those are not safe for
+// concurrent use.
+func (k *KinesisConsumer) startWorkers(ctx context.Context) error {
+	k.jobs = nil
+	if k.MaxProcessingWorkers < 2 {
+		return nil
+	}
+
+	k.jobs = make(chan *processingJob, k.MaxProcessingWorkers)
+	for range k.MaxProcessingWorkers {
+		parser, err := k.parserFunc()
+		if err != nil {
+			return fmt.Errorf("creating parser failed: %w", err)
+		}
+		decoder, err := internal.NewContentDecoder(k.encoding)
+		if err != nil {
+			return fmt.Errorf("creating decoder failed: %w", err)
+		}
+		k.wg.Add(1)
+		go func() {
+			defer k.wg.Done()
+			k.process(ctx, parser, decoder)
+		}()
+	}
+	return nil
+}
+
+// scan consumes the given stream until the context is done. If the scan
+// terminates, the consumer is recreated and resumes from the last checkpoint
+// after an exponentially growing delay.
+func (k *KinesisConsumer) scan(ctx context.Context, stream string, cons scanner) {
+	stats := k.getStats(stream)
+	maxInterval := time.Duration(k.MaxReconnectInterval)
+
+	var attempt int
+	for {
+		start := time.Now()
+		stats.setConnected(true)
+		err := cons.Scan(ctx, func(r *consumer.Record) error {
+			throttled, err := k.limiter.wait(ctx, len(r.Data))
+			stats.throttleTime.Incr(throttled.Nanoseconds())
+			if err != nil {
+				return err
+			}
+
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			case k.sem <- struct{}{}:
-				break
+				k.undelivered.Add(1)
 			}
-			err := k.onMessage(k.acc, r)
-			if err != nil {
-				<-k.sem
+			stats.recordsRead.Incr(1)
+			stats.bytesRead.Incr(int64(len(r.Data)))
+			if r.MillisBehindLatest != nil {
+				stats.setShardLag(r.ShardID, *r.MillisBehindLatest)
+			}
+
+			if k.jobs != nil {
+				if err := k.dispatch(ctx, stream, r); err != nil {
+					// The record stays incomplete to hold back the checkpoint
+					k.release()
+					return err
+				}
+				return nil
+			}
+
+			if err := k.onMessage(k.acc, stream, r); err != nil {
+				k.release()
+				stats.parseErrors.Incr(1)
 				k.Log.Errorf("Scan parser error: %v", err)
+				if errors.Is(err, common_consumer.ErrHalt) {
+					k.halt()
+					return err
+				}
 			}
 
 			return nil
 		})
-		if err != nil {
-			k.cancel()
-			k.Log.Errorf("Scan encountered an error: %v", err)
-			k.cons = nil
+		stats.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("scan terminated")
 		}
-	}()
 
-	return nil
+		// Start over with short delays if the scan was running for a while
+		if time.Since(start) > maxInterval {
+			attempt = 0
+		}
+		delay := backoff(k.reconnectInterval, maxInterval, attempt)
+		attempt++
+		k.Log.Errorf("Scan of stream %q encountered an error: %v; reconnecting in %s", stream, err, delay)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			stats.reconnects.Incr(1)
+			cons, err = k.newScanner(stream)
+			if err == nil {
+				break
+			}
+			delay = backoff(k.reconnectInterval, maxInterval, attempt)
+			attempt++
+			k.Log.Errorf("Recreating consumer for stream %q failed: %v; retrying in %s", stream, err, delay)
+		}
+	}
+}
+
+// backoff returns the delay for the given reconnection attempt, doubling the
+// initial delay for each attempt up to the maximum. A random jitter of up to
+// half of the delay is subtracted to avoid simultaneous reconnects.
+func backoff(initial, maxDelay time.Duration, attempt int) time.Duration {
+	delay := maxDelay
+	if attempt < 32 && initial<<attempt < maxDelay {
+		delay = initial << attempt
+	}
+	if jitter := int64(delay / 2); jitter > 0 {
+		delay -= time.Duration(rand.Int63n(jitter)) //nolint:gosec // no cryptographic randomness required
+	}
+	return delay
 }
 
-func (k *KinesisConsumer) onMessage(acc telegraf.TrackingAccumulator, r *consumer.Record) error {
-	data, err := k.processContentEncodingFunc(r.Data)
+// resolveStreams returns the names of the streams to consume. Names
+// containing glob patterns are matched against the streams of the account.
+func (k *KinesisConsumer) resolveStreams(client kinesisClient) ([]string, error) {
+	names := k.StreamNames
+	if k.StreamName != "" {
+		names = append([]string{k.StreamName}, names...)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no stream configured")
+	}
+
+	var patterns []string
+	streams := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.ContainsAny(name, "*?[") {
+			patterns = append(patterns, name)
+			continue
+		}
+		if !slices.Contains(streams, name) {
+			streams = append(streams, name)
+		}
+	}
+	if len(patterns) == 0 {
+		return streams, nil
+	}
+
+	f, err := filter.Compile(patterns)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("compiling stream patterns failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	paginator := kinesis.NewListStreamsPaginator(client, &kinesis.ListStreamsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing streams failed: %w", err)
+		}
+		for _, name := range page.StreamNames {
+			if f.Match(name) && !slices.Contains(streams, name) {
+				streams = append(streams, name)
+			}
+		}
+	}
+
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("no stream matching %q found", patterns)
 	}
-	metrics, err := k.parser.Parse(data)
+	k.Log.Debugf("Consuming streams %q", streams)
+
+	return streams, nil
+}
+
+func (k *KinesisConsumer) onMessage(acc telegraf.TrackingAccumulator, stream string, r *consumer.Record) error {
+	// The decoders reuse their buffers so we need to finish parsing before
+	// decoding the next record
+	k.decoderTex.Lock()
+	metrics, err := k.policy.Parse(stream, r.Data, func() ([]telegraf.Metric, error) {
+		return k.recordMetrics(k.parser, k.decoder, stream, r)
+	})
+	k.decoderTex.Unlock()
 	if err != nil {
 		return err
 	}
 
+	record := checkpoint.NewRecord(stream, r.ShardID, *r.SequenceNumber)
+	k.recordsTex.Lock()
+	id := acc.AddTrackingMetricGroup(metrics)
+	k.records[id] = record
+	if k.tracker != nil {
+		k.tracker.Track(record)
+	}
+	k.recordsTex.Unlock()
+
+	return nil
+}
+
+// dispatch tracks the record in the order it was read from the shard and
+// hands it over to the processing workers
+func (k *KinesisConsumer) dispatch(ctx context.Context, stream string, r *consumer.Record) error {
+	job := &processingJob{
+		stream: stream,
+		record: r,
+		status: checkpoint.NewRecord(stream, r.ShardID, *r.SequenceNumber),
+	}
+	k.tracker.Track(job.status)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case k.jobs <- job:
+	}
+	return nil
+}
+
+// process parses the records handed over by the scanners until the context
+// is done. Records failing to parse are skipped and do not block the
+// checkpoint of their shard.
+func (k *KinesisConsumer) process(ctx context.Context, parser telegraf.Parser, decoder internal.ContentDecoder) {
+	for {
+		var job *processingJob
+		select {
+		case <-ctx.Done():
+			return
+		case job = <-k.jobs:
+		}
+
+		metrics, err := k.policy.Parse(job.stream, job.record.Data, func() ([]telegraf.Metric, error) {
+			return k.recordMetrics(parser, decoder, job.stream, job.record)
+		})
+		if err != nil {
+			k.release()
+			k.getStats(job.stream).parseErrors.Incr(1)
+			k.Log.Errorf("Scan parser error: %v", err)
+			if errors.Is(err, common_consumer.ErrHalt) {
+				// Leave the record incomplete to hold back the checkpoint
+				k.halt()
+				return
+			}
+			k.tracker.Complete(job.status, true)
+			continue
+		}
+
+		k.recordsTex.Lock()
+		id := k.acc.AddTrackingMetricGroup(metrics)
+		k.records[id] = job.status
+		k.recordsTex.Unlock()
+	}
+}
+
+// recordMetrics returns the metrics of the given Kinesis record using the
+// given parser and decoder.
+func (k *KinesisConsumer) recordMetrics(parser telegraf.Parser, decoder internal.ContentDecoder, stream string, r *consumer.Record) ([]telegraf.Metric, error) {
+	// Split records aggregated by the Kinesis Producer Library into the
+	// contained user records. All resulting metrics are tracked as one group
+	// as the user records share the sequence number of the Kinesis record.
+	var metrics []telegraf.Metric
+	if kpl.IsAggregated(r.Data) {
+		userRecords, err := kpl.Deaggregate(r.Data)
+		if err != nil {
+			return nil, fmt.Errorf("de-aggregating KPL record failed: %w", err)
+		}
+		for _, ur := range userRecords {
+			sub := *r
+			sub.Data = ur.Data
+			sub.PartitionKey = &ur.PartitionKey
+			ms, err := k.parseRecord(parser, decoder, &sub)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, ms...)
+		}
+	} else {
+		ms, err := k.parseRecord(parser, decoder, r)
+		if err != nil {
+			return nil, err
+		}
+		metrics = ms
+	}
+
 	if len(metrics) == 0 {
 		once.Do(func() {
 			k.Log.Debug(internal.NoMetricsCreatedMsg)
 		})
 	}
 
-	k.recordsTex.Lock()
-	id := acc.AddTrackingMetricGroup(metrics)
-	k.records[id] = *r.SequenceNumber
-	k.recordsTex.Unlock()
+	// Identify the source stream if consuming multiple streams
+	if len(k.StreamNames) > 0 {
+		for _, m := range metrics {
+			m.AddTag("stream", stream)
+		}
+	}
 
-	return nil
+	// Keep the record position to allow tracing metrics back to the record
+	for _, m := range metrics {
+		metric.SetProvenanceOffset(m, stream+"/"+r.ShardID, *r.SequenceNumber)
+	}
+
+	return metrics, nil
+}
+
+// parseRecord decodes the content of the given (user) record and parses it
+// into metrics including the configured record metadata.
+func (k *KinesisConsumer) parseRecord(parser telegraf.Parser, decoder internal.ContentDecoder, r *consumer.Record) ([]telegraf.Metric, error) {
+	data, err := decoder.Decode(r.Data)
+	if err != nil {
+		return nil, err
+	}
+	var metrics []telegraf.Metric
+	if k.ContentEncoding == "cloudwatch_logs" {
+		metrics, err = parseCloudWatchLogs(parser, data)
+	} else {
+		metrics, err = k.unwrapper.Parse(parser, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		k.addRecordMetadata(m, r)
+	}
+	return metrics, nil
 }
 
 func (k *KinesisConsumer) onDelivery(ctx context.Context) {
@@ -253,83 +808,96 @@ func (k *KinesisConsumer) onDelivery(ctx context.Context) {
 			return
 		case info := <-k.acc.Delivered():
 			k.recordsTex.Lock()
-			sequenceNum, ok := k.records[info.ID()]
+			record, ok := k.records[info.ID()]
 			if !ok {
 				k.recordsTex.Unlock()
 				continue
 			}
-			<-k.sem
+			k.release()
 			delete(k.records, info.ID())
 			k.recordsTex.Unlock()
 
-			if info.Delivered() {
-				k.checkpointTex.Lock()
-				chk, ok := k.checkpoints[sequenceNum]
-				if !ok {
-					k.checkpointTex.Unlock()
-					continue
-				}
-				delete(k.checkpoints, sequenceNum)
-				k.checkpointTex.Unlock()
-
-				// at least once
-				if strToBint(sequenceNum).Cmp(k.lastSeqNum) > 0 {
-					continue
-				}
-
-				k.lastSeqNum = strToBint(sequenceNum)
-				if err := k.checkpoint.SetCheckpoint(chk.streamName, chk.shardID, sequenceNum); err != nil {
-					k.Log.Debugf("Setting checkpoint failed: %v", err)
-				}
-			} else {
+			if !info.Delivered() {
 				k.Log.Debug("Metric group failed to process")
 			}
+			k.tracker.Complete(record, info.Delivered())
 		}
 	}
 }
 
-func processGzip(data []byte) ([]byte, error) {
-	zipData, err := gzip.NewReader(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	defer zipData.Close()
-	return io.ReadAll(zipData)
+// release frees the slot of an undelivered record
+func (k *KinesisConsumer) release() {
+	<-k.sem
+	k.undelivered.Add(-1)
 }
 
-func processZlib(data []byte) ([]byte, error) {
-	zlibData, err := zlib.NewReader(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
+// writeCheckpoint persists the sequence number of the shard in the store
+func (k *KinesisConsumer) writeCheckpoint(stream, shardID, sequenceNum string) {
+	stats := k.getStats(stream)
+	if err := k.checkpoint.SetCheckpoint(stream, shardID, sequenceNum); err != nil {
+		stats.checkpointErrors.Incr(1)
+		k.Log.Debugf("Setting checkpoint failed: %v", err)
+		return
 	}
-	defer zlibData.Close()
-	return io.ReadAll(zlibData)
+	stats.checkpointWrites.Incr(1)
+	stats.setCheckpointed(time.Now())
 }
 
-func processNoOp(data []byte) ([]byte, error) {
-	return data, nil
+func (k *KinesisConsumer) createCheckpointStore(cfg aws.Config) (checkpoint.Store, error) {
+	switch k.CheckpointBackend {
+	case "dynamodb":
+		return ddb.New(
+			k.DynamoDB.AppName,
+			k.DynamoDB.TableName,
+			ddb.WithDynamoClient(dynamodb.NewFromConfig(cfg)),
+			ddb.WithMaxInterval(time.Second*10),
+		)
+	case "file":
+		return checkpoint.NewFileStore(k.File)
+	case "redis":
+		return checkpoint.NewRedisStore(k.Redis)
+	case "postgresql":
+		return checkpoint.NewPostgreSQLStore(k.PostgreSQL)
+	}
+	return &checkpoint.NoopStore{}, nil
 }
 
-func strToBint(s string) *big.Int {
-	n, ok := new(big.Int).SetString(s, 10)
-	if !ok {
-		return negOne
+func (k *KinesisConsumer) closeCheckpointStore() {
+	switch c := k.checkpoint.(type) {
+	case interface{ Shutdown() error }:
+		if err := c.Shutdown(); err != nil {
+			k.Log.Errorf("Shutting down checkpoint store failed: %v", err)
+		}
+	case io.Closer:
+		if err := c.Close(); err != nil {
+			k.Log.Errorf("Closing checkpoint store failed: %v", err)
+		}
 	}
-	return n
+	k.checkpoint = nil
 }
 
-func (k *KinesisConsumer) configureProcessContentEncodingFunc() error {
-	switch k.ContentEncoding {
-	case "gzip":
-		k.processContentEncodingFunc = processGzip
-	case "zlib":
-		k.processContentEncodingFunc = processZlib
-	case "none", "identity", "":
-		k.processContentEncodingFunc = processNoOp
-	default:
-		return fmt.Errorf("unknown content encoding %q", k.ContentEncoding)
+// addRecordMetadata adds the configured record metadata to the metric. The
+// shard ID and partition key are added as tags, the sequence number and the
+// approximate arrival timestamp (in nanoseconds) as fields.
+func (k *KinesisConsumer) addRecordMetadata(m telegraf.Metric, r *consumer.Record) {
+	for _, item := range k.IncludeRecordMetadata {
+		switch item {
+		case "shard_id":
+			m.AddTag("shard_id", r.ShardID)
+		case "partition_key":
+			if r.PartitionKey != nil {
+				m.AddTag("partition_key", *r.PartitionKey)
+			}
+		case "sequence_number":
+			if r.SequenceNumber != nil {
+				m.AddField("sequence_number", *r.SequenceNumber)
+			}
+		case "approximate_arrival_timestamp":
+			if r.ApproximateArrivalTimestamp != nil {
+				m.AddField("approximate_arrival_timestamp", r.ApproximateArrivalTimestamp.UnixNano())
+			}
+		}
 	}
-	return nil
 }
 
 type telegrafLoggerWrapper struct {
@@ -352,21 +920,13 @@ func (t *telegrafLoggerWrapper) Logf(classification logging.Classification, form
 	t.Logger.Tracef(format, v...)
 }
 
-// noopStore implements the storage interface with discard
-type noopStore struct{}
-
-func (n noopStore) SetCheckpoint(string, string, string) error   { return nil }
-func (n noopStore) GetCheckpoint(string, string) (string, error) { return "", nil }
-
 func init() {
-	negOne, _ = new(big.Int).SetString("-1", 10)
-
 	inputs.Add("kinesis_consumer", func() telegraf.Input {
 		return &KinesisConsumer{
 			ShardIteratorType:      "TRIM_HORIZON",
 			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
-			lastSeqNum:             maxSeq,
 			ContentEncoding:        "identity",
+			MaxReconnectInterval:   config.Duration(defaultMaxReconnectInterval),
 		}
 	})
 }
@@ -1,15 +1,31 @@
 package kinesis_consumer
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
 	consumer "github.com/harlow/kinesis-consumer"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/checkpoint"
+	"github.com/influxdata/telegraf/plugins/common/kpl"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -56,7 +72,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 	type fields struct {
 		ContentEncoding string
 		parser          telegraf.Parser
-		records         map[telegraf.TrackingID]string
+		records         map[telegraf.TrackingID]*checkpoint.Record
 	}
 	type args struct {
 		r *consumer.Record
@@ -77,7 +93,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			fields: fields{
 				ContentEncoding: "none",
 				parser:          parser,
-				records:         make(map[telegraf.TrackingID]string),
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -98,7 +114,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			fields: fields{
 				ContentEncoding: "",
 				parser:          parser,
-				records:         make(map[telegraf.TrackingID]string),
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -119,7 +135,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			fields: fields{
 				ContentEncoding: "identity",
 				parser:          parser,
-				records:         make(map[telegraf.TrackingID]string),
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -139,7 +155,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			name: "test no compression via no ContentEncoding",
 			fields: fields{
 				parser:  parser,
-				records: make(map[telegraf.TrackingID]string),
+				records: make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -160,7 +176,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			fields: fields{
 				ContentEncoding: "gzip",
 				parser:          parser,
-				records:         make(map[telegraf.TrackingID]string),
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -181,7 +197,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			fields: fields{
 				ContentEncoding: "zlib",
 				parser:          parser,
-				records:         make(map[telegraf.TrackingID]string),
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
 			},
 			args: args{
 				r: &consumer.Record{
@@ -216,7 +232,7 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 			require.NoError(t, err)
 
 			acc := testutil.Accumulator{}
-			if err := k.onMessage(acc.WithTracking(tt.expected.numberOfMetrics), tt.args.r); (err != nil) != tt.wantErr {
+			if err := k.onMessage(acc.WithTracking(tt.expected.numberOfMetrics), "", tt.args.r); (err != nil) != tt.wantErr {
 				t.Errorf("onMessage() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -232,3 +248,723 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestKinesisConsumer_onMessageCompression(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(0, 1510254469274000000),
+		),
+	}
+
+	for _, encoding := range []string{"snappy", "zstd", "lz4"} {
+		t.Run(encoding, func(t *testing.T) {
+			enc, err := internal.NewContentEncoder(encoding)
+			require.NoError(t, err)
+			data, err := enc.Encode([]byte("cpu value=42i 1510254469274000000\n"))
+			require.NoError(t, err)
+
+			k := &KinesisConsumer{
+				ContentEncoding: encoding,
+				parser:          parser,
+				records:         make(map[telegraf.TrackingID]*checkpoint.Record),
+			}
+			require.NoError(t, k.Init())
+
+			acc := testutil.Accumulator{}
+			record := &consumer.Record{
+				Record: types.Record{
+					Data:           data,
+					SequenceNumber: aws.String("anything"),
+				},
+			}
+			require.NoError(t, k.onMessage(acc.WithTracking(1), "", record))
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestKinesisConsumer_onMessageCloudWatchLogs(t *testing.T) {
+	envelope := []byte(`{
+  "messageType": "DATA_MESSAGE",
+  "owner": "123456789012",
+  "logGroup": "/aws/lambda/test",
+  "logStream": "2021/02/22/[$LATEST]abcdef",
+  "subscriptionFilters": ["test"],
+  "logEvents": [
+    {
+      "id": "1",
+      "timestamp": 1510254469274,
+      "message": "cpu value=42i 1510254469274000000"
+    },
+    {
+      "id": "2",
+      "timestamp": 1510254469275,
+      "message": "cpu value=43i 1510254469275000000"
+    }
+  ]
+}`)
+	control := []byte(`{
+  "messageType": "CONTROL_MESSAGE",
+  "owner": "CloudwatchLogs",
+  "logGroup": "",
+  "logStream": "",
+  "subscriptionFilters": [],
+  "logEvents": [
+    {
+      "id": "",
+      "timestamp": 1510254469274,
+      "message": "CWL CONTROL MESSAGE: Checking health of destination Kinesis stream."
+    }
+  ]
+}`)
+
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	k := &KinesisConsumer{
+		ContentEncoding: "cloudwatch_logs",
+		Log:             testutil.Logger{},
+		parser:          parser,
+		records:         make(map[telegraf.TrackingID]*checkpoint.Record),
+	}
+	require.NoError(t, k.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{
+				"owner":      "123456789012",
+				"log_group":  "/aws/lambda/test",
+				"log_stream": "2021/02/22/[$LATEST]abcdef",
+			},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(0, 1510254469274000000),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{
+				"owner":      "123456789012",
+				"log_group":  "/aws/lambda/test",
+				"log_stream": "2021/02/22/[$LATEST]abcdef",
+			},
+			map[string]interface{}{"value": int64(43)},
+			time.Unix(0, 1510254469275000000),
+		),
+	}
+
+	var acc testutil.Accumulator
+	r := &consumer.Record{
+		Record: types.Record{
+			Data:           compress(envelope),
+			SequenceNumber: aws.String("1"),
+		},
+	}
+	require.NoError(t, k.onMessage(acc.WithTracking(len(expected)), "", r))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Control messages must not produce any metrics
+	acc.ClearMetrics()
+	r = &consumer.Record{
+		Record: types.Record{
+			Data:           compress(control),
+			SequenceNumber: aws.String("2"),
+		},
+	}
+	require.NoError(t, k.onMessage(acc.WithTracking(1), "", r))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitShardIteratorType(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *KinesisConsumer
+		expected string
+	}{
+		{
+			name:   "default",
+			plugin: &KinesisConsumer{},
+		},
+		{
+			name:   "latest",
+			plugin: &KinesisConsumer{ShardIteratorType: "LATEST"},
+		},
+		{
+			name: "at timestamp",
+			plugin: &KinesisConsumer{
+				ShardIteratorType: "AT_TIMESTAMP",
+				StartTimestamp:    "2024-01-01T12:00:00Z",
+			},
+		},
+		{
+			name:     "at timestamp without timestamp",
+			plugin:   &KinesisConsumer{ShardIteratorType: "AT_TIMESTAMP"},
+			expected: "'start_timestamp' required",
+		},
+		{
+			name: "at timestamp with invalid timestamp",
+			plugin: &KinesisConsumer{
+				ShardIteratorType: "AT_TIMESTAMP",
+				StartTimestamp:    "yesterday",
+			},
+			expected: "parsing 'start_timestamp' failed",
+		},
+		{
+			name: "at sequence number",
+			plugin: &KinesisConsumer{
+				ShardIteratorType:   "AT_SEQUENCE_NUMBER",
+				StartSequenceNumber: "49590338271490256608559692538361571095921575989136588898",
+			},
+		},
+		{
+			name:     "at sequence number without sequence number",
+			plugin:   &KinesisConsumer{ShardIteratorType: "AT_SEQUENCE_NUMBER"},
+			expected: "'start_sequence_number' required",
+		},
+		{
+			name:     "invalid",
+			plugin:   &KinesisConsumer{ShardIteratorType: "AFTER_SEQUENCE_NUMBER"},
+			expected: "invalid shard iterator type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.plugin.Init()
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestKinesisConsumer_onMessageRecordMetadata(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	k := &KinesisConsumer{
+		IncludeRecordMetadata: []string{"shard_id", "partition_key", "sequence_number", "approximate_arrival_timestamp"},
+		Log:                   testutil.Logger{},
+		parser:                parser,
+		records:               make(map[telegraf.TrackingID]*checkpoint.Record),
+	}
+	require.NoError(t, k.Init())
+
+	arrival := time.Unix(1700000000, 123)
+	r := &consumer.Record{
+		Record: types.Record{
+			Data:                        []byte("cpu value=42i 1700000000000000000"),
+			PartitionKey:                aws.String("tenant-a"),
+			SequenceNumber:              aws.String("49590338271490256608559692538361571095921575989136588898"),
+			ApproximateArrivalTimestamp: &arrival,
+		},
+		ShardID: "shardId-000000000001",
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{
+				"shard_id":      "shardId-000000000001",
+				"partition_key": "tenant-a",
+			},
+			map[string]interface{}{
+				"value":                         int64(42),
+				"sequence_number":               "49590338271490256608559692538361571095921575989136588898",
+				"approximate_arrival_timestamp": arrival.UnixNano(),
+			},
+			time.Unix(1700000000, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.onMessage(acc.WithTracking(1), "", r))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	k = &KinesisConsumer{IncludeRecordMetadata: []string{"stream_name"}}
+	require.ErrorContains(t, k.Init(), "invalid record metadata")
+}
+
+func TestKinesisConsumer_onMessageKPL(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	k := &KinesisConsumer{
+		IncludeRecordMetadata: []string{"partition_key"},
+		Log:                   testutil.Logger{},
+		parser:                parser,
+		records:               make(map[telegraf.TrackingID]*checkpoint.Record),
+	}
+	require.NoError(t, k.Init())
+
+	data := kplAggregate(
+		[]string{"tenant-a", "tenant-b"},
+		[]uint64{0, 1, 0},
+		[]string{
+			"cpu value=1i 1700000000000000000",
+			"cpu value=2i 1700000000000000000",
+			"cpu value=3i 1700000000000000000",
+		},
+	)
+	r := &consumer.Record{
+		Record: types.Record{
+			Data:           data,
+			PartitionKey:   aws.String("aggregate"),
+			SequenceNumber: aws.String("1"),
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"partition_key": "tenant-a"}, map[string]interface{}{"value": int64(1)}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"partition_key": "tenant-b"}, map[string]interface{}{"value": int64(2)}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{"partition_key": "tenant-a"}, map[string]interface{}{"value": int64(3)}, time.Unix(1700000000, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.onMessage(acc.WithTracking(1), "", r))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Records with an invalid digest are passed to the parser unchanged
+	data[len(data)-1] ^= 0xff
+	r.Data = data
+	require.Error(t, k.onMessage(acc.WithTracking(1), "", r))
+}
+
+// kplAggregate creates a KPL aggregated record from the given user records
+func kplAggregate(partitionKeys []string, indices []uint64, records []string) []byte {
+	a := kpl.NewAggregator(1024 * 1024)
+	for i, record := range records {
+		a.Add(partitionKeys[indices[i]], []byte(record))
+	}
+	return a.Bytes()
+}
+
+func TestInitCheckpointBackend(t *testing.T) {
+	k := &KinesisConsumer{}
+	require.NoError(t, k.Init())
+	require.Equal(t, "none", k.CheckpointBackend)
+
+	k = &KinesisConsumer{DynamoDB: &dynamoDB{AppName: "test", TableName: "test"}}
+	require.NoError(t, k.Init())
+	require.Equal(t, "dynamodb", k.CheckpointBackend)
+
+	k = &KinesisConsumer{CheckpointBackend: "file"}
+	require.ErrorContains(t, k.Init(), "'checkpoint_file' section required")
+
+	k = &KinesisConsumer{CheckpointBackend: "etcd"}
+	require.ErrorContains(t, k.Init(), "invalid checkpoint backend")
+}
+
+type mockStreamLister struct {
+	kinesisClient
+	pages [][]string
+}
+
+func (m *mockStreamLister) ListStreams(_ context.Context, params *kinesis.ListStreamsInput, _ ...func(*kinesis.Options)) (*kinesis.ListStreamsOutput, error) {
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(*params.NextToken)
+	}
+	out := &kinesis.ListStreamsOutput{
+		StreamNames:    m.pages[page],
+		HasMoreStreams: aws.Bool(page+1 < len(m.pages)),
+	}
+	if page+1 < len(m.pages) {
+		out.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return out, nil
+}
+
+func TestResolveStreams(t *testing.T) {
+	client := &mockStreamLister{
+		pages: [][]string{
+			{"metrics-eu", "logs-eu"},
+			{"metrics-us", "logs-us"},
+		},
+	}
+
+	k := &KinesisConsumer{
+		StreamName:  "legacy",
+		StreamNames: []string{"metrics-*", "audit", "metrics-eu"},
+		Log:         testutil.Logger{},
+	}
+	streams, err := k.resolveStreams(client)
+	require.NoError(t, err)
+	require.Equal(t, []string{"legacy", "audit", "metrics-eu", "metrics-us"}, streams)
+
+	k = &KinesisConsumer{
+		StreamNames: []string{"traces-*"},
+		Log:         testutil.Logger{},
+	}
+	_, err = k.resolveStreams(client)
+	require.ErrorContains(t, err, "no stream matching")
+
+	k = &KinesisConsumer{Log: testutil.Logger{}}
+	_, err = k.resolveStreams(client)
+	require.ErrorContains(t, err, "no stream configured")
+}
+
+func TestKinesisConsumer_onMessageStreamTag(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	k := &KinesisConsumer{
+		StreamNames: []string{"a", "b"},
+		Log:         testutil.Logger{},
+		parser:      parser,
+		records:     make(map[telegraf.TrackingID]*checkpoint.Record),
+	}
+	require.NoError(t, k.Init())
+
+	r := &consumer.Record{
+		Record: types.Record{
+			Data:           []byte("cpu value=42i 1700000000000000000"),
+			SequenceNumber: aws.String("1"),
+		},
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"stream": "b"}, map[string]interface{}{"value": int64(42)}, time.Unix(1700000000, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.onMessage(acc.WithTracking(1), "b", r))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+type fakeScanner struct {
+	err error
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, _ consumer.ScanFunc) error {
+	if s.err != nil {
+		return s.err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestKinesisConsumer_scanReconnect(t *testing.T) {
+	k := &KinesisConsumer{
+		MaxReconnectInterval: config.Duration(10 * time.Millisecond),
+		Log:                  testutil.Logger{},
+		reconnectInterval:    time.Millisecond,
+	}
+	require.NoError(t, k.Init())
+
+	// Fail the initial scan and the first reconnect before scanning successfully
+	var created int
+	k.newScanner = func(string) (scanner, error) {
+		created++
+		if created == 1 {
+			return nil, errors.New("creation failed")
+		}
+		return &fakeScanner{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		k.scan(ctx, "test-reconnect", &fakeScanner{err: errors.New("scan failed")})
+	}()
+
+	require.Eventually(t, func() bool {
+		return k.getStats("test-reconnect").reconnects.Get() == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "scan did not terminate")
+	}
+	require.Equal(t, 2, created)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		delay := backoff(time.Second, 5*time.Second, attempt)
+		require.LessOrEqual(t, delay, expected)
+		require.Greater(t, delay, expected/2)
+	}
+	require.LessOrEqual(t, backoff(time.Second, 5*time.Second, 100), 5*time.Second)
+}
+
+type recordScanner struct {
+	records []*consumer.Record
+}
+
+func (s *recordScanner) Scan(ctx context.Context, fn consumer.ScanFunc) error {
+	for _, r := range s.records {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestKinesisConsumer_processingWorkers(t *testing.T) {
+	k := &KinesisConsumer{
+		MaxUndeliveredMessages: 100,
+		MaxProcessingWorkers:   4,
+		Log:                    testutil.Logger{},
+	}
+	k.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &influx.Parser{}
+		err := parser.Init()
+		return parser, err
+	})
+	require.NoError(t, k.Init())
+
+	// Create the records of a shard with an unparsable record in between
+	records := make([]*consumer.Record, 0, 50)
+	for i := range 50 {
+		data := fmt.Sprintf("cpu value=%di 1700000000000000000", i)
+		if i == 20 {
+			data = "invalid"
+		}
+		records = append(records, &consumer.Record{
+			Record: types.Record{
+				Data:           []byte(data),
+				SequenceNumber: aws.String(strconv.Itoa(100 + i)),
+			},
+			ShardID: "shardId-000000000000",
+		})
+	}
+
+	var checkpointsTex sync.Mutex
+	var checkpoints []string
+	var acc testutil.Accumulator
+	k.acc = acc.WithTracking(k.MaxUndeliveredMessages)
+	k.records = make(map[telegraf.TrackingID]*checkpoint.Record)
+	k.sem = make(chan struct{}, k.MaxUndeliveredMessages)
+	k.tracker = checkpoint.NewTracker(1, func(_, _, seq string) {
+		checkpointsTex.Lock()
+		defer checkpointsTex.Unlock()
+		checkpoints = append(checkpoints, seq)
+	})
+
+	parseErrors := k.getStats("test").parseErrors.Get()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, k.startWorkers(ctx))
+	k.wg.Add(2)
+	go func() {
+		defer k.wg.Done()
+		k.onDelivery(ctx)
+	}()
+	go func() {
+		defer k.wg.Done()
+		k.scan(ctx, "test", &recordScanner{records: records})
+	}()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 49 && k.getStats("test").parseErrors.Get() == parseErrors+1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Deliver the metrics in reverse order, the checkpoint must only advance
+	// once all previous records of the shard are done
+	metrics := acc.GetTelegrafMetrics()
+	for i := len(metrics) - 1; i >= 0; i-- {
+		metrics[i].Accept()
+	}
+	require.Eventually(t, func() bool {
+		checkpointsTex.Lock()
+		defer checkpointsTex.Unlock()
+		return len(checkpoints) > 0 && checkpoints[len(checkpoints)-1] == "149"
+	}, 3*time.Second, 10*time.Millisecond)
+
+	checkpointsTex.Lock()
+	for i := 1; i < len(checkpoints); i++ {
+		require.True(t, checkpoint.SeqLess(checkpoints[i-1], checkpoints[i]), "checkpoints not in order: %v", checkpoints)
+	}
+	checkpointsTex.Unlock()
+
+	cancel()
+	k.wg.Wait()
+}
+
+func TestKinesisConsumer_drain(t *testing.T) {
+	k := &KinesisConsumer{
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	k.SetParser(parser)
+	require.NoError(t, k.Init())
+
+	records := make([]*consumer.Record, 0, 5)
+	for i := range 5 {
+		records = append(records, &consumer.Record{
+			Record: types.Record{
+				Data:           []byte(fmt.Sprintf("cpu value=%di 1700000000000000000", i)),
+				SequenceNumber: aws.String(strconv.Itoa(100 + i)),
+			},
+			ShardID: "shardId-000000000000",
+		})
+	}
+
+	var acc testutil.Accumulator
+	k.acc = acc.WithTracking(k.MaxUndeliveredMessages)
+	k.records = make(map[telegraf.TrackingID]*checkpoint.Record)
+	k.sem = make(chan struct{}, k.MaxUndeliveredMessages)
+	k.tracker = checkpoint.NewTracker(1, func(_, _, _ string) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scanCtx, stopScan := context.WithCancel(ctx)
+	k.stopScan = stopScan
+	k.wg.Add(2)
+	k.scanners.Add(1)
+	go func() {
+		defer k.wg.Done()
+		k.onDelivery(ctx)
+	}()
+	go func() {
+		defer k.wg.Done()
+		defer k.scanners.Done()
+		k.scan(scanCtx, "test", &recordScanner{records: records})
+	}()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 5
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Draining times out while metrics are undelivered
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer timeoutCancel()
+	require.ErrorContains(t, k.Drain(timeoutCtx), "5 record(s) still undelivered")
+
+	// Draining finishes once all metrics are delivered
+	done := make(chan error, 1)
+	go func() {
+		done <- k.Drain(context.Background())
+	}()
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "drain did not finish")
+	}
+	require.Zero(t, k.undelivered.Load())
+
+	cancel()
+	k.wg.Wait()
+}
+
+func TestInitProcessingWorkers(t *testing.T) {
+	k := &KinesisConsumer{MaxProcessingWorkers: -1}
+	require.ErrorContains(t, k.Init(), "must not be negative")
+
+	k = &KinesisConsumer{MaxProcessingWorkers: 2}
+	require.ErrorContains(t, k.Init(), "requires a parser function")
+}
+
+func TestInitRateLimits(t *testing.T) {
+	k := &KinesisConsumer{MaxRecordsPerSecond: -1}
+	require.ErrorContains(t, k.Init(), "'max_records_per_second' must not be negative")
+
+	k = &KinesisConsumer{MaxBytesPerSecond: config.Size(math.MaxInt32 + 1)}
+	require.ErrorContains(t, k.Init(), "'max_bytes_per_second' must not exceed")
+
+	k = &KinesisConsumer{}
+	require.NoError(t, k.Init())
+	require.Nil(t, k.limiter)
+
+	k = &KinesisConsumer{MaxBytesPerSecond: config.Size(1024)}
+	require.NoError(t, k.Init())
+	require.NotNil(t, k.limiter)
+	require.Nil(t, k.limiter.records)
+	require.NotNil(t, k.limiter.bytes)
+}
+
+func TestRateLimiterOversizedRecord(t *testing.T) {
+	// Records exceeding the burst must not block forever
+	l := newRateLimiter(0, 10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := l.wait(ctx, 100)
+	require.NoError(t, err)
+}
+
+func TestKinesisConsumer_scanRateLimit(t *testing.T) {
+	k := &KinesisConsumer{
+		MaxUndeliveredMessages: 100,
+		MaxRecordsPerSecond:    2,
+		Log:                    testutil.Logger{},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	k.SetParser(parser)
+	require.NoError(t, k.Init())
+
+	records := make([]*consumer.Record, 0, 3)
+	for i := range 3 {
+		records = append(records, &consumer.Record{
+			Record: types.Record{
+				Data:           []byte(fmt.Sprintf("cpu value=%di 1700000000000000000", i)),
+				SequenceNumber: aws.String(strconv.Itoa(100 + i)),
+			},
+			ShardID: "shardId-000000000000",
+		})
+	}
+
+	var acc testutil.Accumulator
+	k.acc = acc.WithTracking(k.MaxUndeliveredMessages)
+	k.records = make(map[telegraf.TrackingID]*checkpoint.Record)
+	k.sem = make(chan struct{}, k.MaxUndeliveredMessages)
+	k.tracker = checkpoint.NewTracker(1, func(_, _, _ string) {})
+
+	stats := k.getStats("test-ratelimit")
+	throttled := stats.throttleTime.Get()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		k.scan(ctx, "test-ratelimit", &recordScanner{records: records})
+	}()
+
+	// The burst is read immediately while the next record is delayed by the
+	// limiter instead of being dropped
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, time.Second, time.Millisecond)
+	require.Never(t, func() bool {
+		return acc.NMetrics() > 2
+	}, 200*time.Millisecond, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 3
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Greater(t, stats.throttleTime.Get(), throttled)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "scan did not terminate")
+	}
+}
@@ -0,0 +1,57 @@
+package kinesis_consumer
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimiter limits the records and bytes read per second across all shards
+// and streams. Waiting blocks the scan of the shard and thereby applies
+// backpressure instead of dropping records.
+type rateLimiter struct {
+	records *rate.Limiter
+	bytes   *rate.Limiter
+}
+
+// newRateLimiter creates a limiter with a burst of one second worth of data.
+// A zero limit disables the respective limit and nil is returned if both are
+// disabled.
+func newRateLimiter(recordsPerSecond, bytesPerSecond int) *rateLimiter {
+	if recordsPerSecond <= 0 && bytesPerSecond <= 0 {
+		return nil
+	}
+
+	l := &rateLimiter{}
+	if recordsPerSecond > 0 {
+		l.records = rate.NewLimiter(rate.Limit(recordsPerSecond), recordsPerSecond)
+	}
+	if bytesPerSecond > 0 {
+		l.bytes = rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond)
+	}
+	return l
+}
+
+// wait blocks until a record of the given size may be processed and returns
+// the time spent waiting
+func (l *rateLimiter) wait(ctx context.Context, size int) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	start := time.Now()
+	if l.records != nil {
+		if err := l.records.Wait(ctx); err != nil {
+			return time.Since(start), err
+		}
+	}
+	if l.bytes != nil && size > 0 {
+		// Records larger than the burst would never be allowed so take the
+		// whole burst for those
+		if err := l.bytes.WaitN(ctx, min(size, l.bytes.Burst())); err != nil {
+			return time.Since(start), err
+		}
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,58 @@
+package kinesis_consumer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+
+	"github.com/influxdata/telegraf"
+)
+
+// kinesisClient is the subset of the Kinesis API used by the consumer library
+// and the plugin
+type kinesisClient interface {
+	GetRecords(ctx context.Context, params *kinesis.GetRecordsInput, optFns ...func(*kinesis.Options)) (*kinesis.GetRecordsOutput, error)
+	ListShards(ctx context.Context, params *kinesis.ListShardsInput, optFns ...func(*kinesis.Options)) (*kinesis.ListShardsOutput, error)
+	GetShardIterator(
+		ctx context.Context,
+		params *kinesis.GetShardIteratorInput,
+		optFns ...func(*kinesis.Options),
+	) (*kinesis.GetShardIteratorOutput, error)
+	ListStreams(ctx context.Context, params *kinesis.ListStreamsInput, optFns ...func(*kinesis.Options)) (*kinesis.ListStreamsOutput, error)
+}
+
+// sequenceNumberClient injects the configured starting sequence number into
+// shard iterator requests of type AT_SEQUENCE_NUMBER as the consumer library
+// does not support this iterator type natively.
+type sequenceNumberClient struct {
+	kinesisClient
+	sequenceNumber string
+	log            telegraf.Logger
+}
+
+func (c *sequenceNumberClient) GetShardIterator(
+	ctx context.Context,
+	params *kinesis.GetShardIteratorInput,
+	optFns ...func(*kinesis.Options),
+) (*kinesis.GetShardIteratorOutput, error) {
+	if params.ShardIteratorType != types.ShardIteratorTypeAtSequenceNumber {
+		return c.kinesisClient.GetShardIterator(ctx, params, optFns...)
+	}
+
+	params.StartingSequenceNumber = aws.String(c.sequenceNumber)
+	out, err := c.kinesisClient.GetShardIterator(ctx, params, optFns...)
+
+	// Sequence numbers are unique per shard so all other shards of the stream
+	// will reject the number. Start those shards from the oldest record.
+	var invalid *types.InvalidArgumentException
+	if errors.As(err, &invalid) {
+		c.log.Debugf("Sequence number not valid for shard %q, starting at %s", aws.ToString(params.ShardId), types.ShardIteratorTypeTrimHorizon)
+		params.ShardIteratorType = types.ShardIteratorTypeTrimHorizon
+		params.StartingSequenceNumber = nil
+		return c.kinesisClient.GetShardIterator(ctx, params, optFns...)
+	}
+	return out, err
+}
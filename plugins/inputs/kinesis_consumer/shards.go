@@ -0,0 +1,141 @@
+package kinesis_consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	consumer "github.com/harlow/kinesis-consumer"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// maxHashKey is the largest hash key of a stream, i.e. 2^128 - 1
+var maxHashKey = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+type hashKeyRange struct {
+	start *big.Int
+	end   *big.Int
+}
+
+// shardFilter selects the shards to consume by their ID or hash key range
+type shardFilter struct {
+	ids    filter.Filter
+	ranges []hashKeyRange
+}
+
+// newShardFilter creates a filter for the given shard ID patterns and hash
+// key ranges. Nil is returned if neither is given so all shards are consumed.
+func newShardFilter(ids, ranges []string) (*shardFilter, error) {
+	if len(ids) == 0 && len(ranges) == 0 {
+		return nil, nil
+	}
+
+	f, err := filter.Compile(ids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'shard_ids': %w", err)
+	}
+	sf := &shardFilter{ids: f}
+	for _, r := range ranges {
+		hkr, err := parseHashKeyRange(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'shard_hash_key_ranges' entry %q: %w", r, err)
+		}
+		sf.ranges = append(sf.ranges, hkr)
+	}
+	return sf, nil
+}
+
+// parseHashKeyRange parses a range of the form "<start>-<end>" with both
+// bounds being inclusive decimal hash keys
+func parseHashKeyRange(s string) (hashKeyRange, error) {
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		return hashKeyRange{}, errors.New("expected '<start>-<end>'")
+	}
+	start, ok := new(big.Int).SetString(strings.TrimSpace(startStr), 10)
+	if !ok || start.Sign() < 0 || start.Cmp(maxHashKey) > 0 {
+		return hashKeyRange{}, fmt.Errorf("invalid start hash key %q", startStr)
+	}
+	end, ok := new(big.Int).SetString(strings.TrimSpace(endStr), 10)
+	if !ok || end.Sign() < 0 || end.Cmp(maxHashKey) > 0 {
+		return hashKeyRange{}, fmt.Errorf("invalid end hash key %q", endStr)
+	}
+	if start.Cmp(end) > 0 {
+		return hashKeyRange{}, errors.New("start exceeds end")
+	}
+	return hashKeyRange{start: start, end: end}, nil
+}
+
+// match returns true if the shard ID matches one of the patterns or the hash
+// key range of the shard overlaps one of the configured ranges
+func (f *shardFilter) match(shard types.Shard) bool {
+	if f == nil {
+		return true
+	}
+	if f.ids != nil && f.ids.Match(aws.ToString(shard.ShardId)) {
+		return true
+	}
+	if len(f.ranges) == 0 || shard.HashKeyRange == nil {
+		return false
+	}
+
+	start, ok := new(big.Int).SetString(aws.ToString(shard.HashKeyRange.StartingHashKey), 10)
+	if !ok {
+		return false
+	}
+	end, ok := new(big.Int).SetString(aws.ToString(shard.HashKeyRange.EndingHashKey), 10)
+	if !ok {
+		return false
+	}
+	for _, r := range f.ranges {
+		if start.Cmp(r.end) <= 0 && end.Cmp(r.start) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredGroup hands out the shards of the stream matching the filter. All
+// other shards are marked as closed right away, so child shards resulting
+// from resharding are not blocked waiting for skipped parents.
+type filteredGroup struct {
+	*consumer.AllGroup
+	filter *shardFilter
+	log    telegraf.Logger
+}
+
+func (g *filteredGroup) Start(ctx context.Context, shardC chan types.Shard) error {
+	discovered := make(chan types.Shard)
+	go func() {
+		for {
+			var shard types.Shard
+			select {
+			case <-ctx.Done():
+				return
+			case shard = <-discovered:
+			}
+
+			id := aws.ToString(shard.ShardId)
+			if !g.filter.match(shard) {
+				g.log.Debugf("Skipping shard %q", id)
+				if err := g.AllGroup.CloseShard(ctx, id); err != nil {
+					g.log.Errorf("Closing skipped shard %q failed: %v", id, err)
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case shardC <- shard:
+			}
+		}
+	}()
+	return g.AllGroup.Start(ctx, discovered)
+}
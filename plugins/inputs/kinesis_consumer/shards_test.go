@@ -0,0 +1,130 @@
+package kinesis_consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	consumer "github.com/harlow/kinesis-consumer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const halfHashKey = "170141183460469231731687303715884105727"
+
+func newShard(id, parent, start, end string) types.Shard {
+	shard := types.Shard{
+		ShardId: aws.String(id),
+		HashKeyRange: &types.HashKeyRange{
+			StartingHashKey: aws.String(start),
+			EndingHashKey:   aws.String(end),
+		},
+	}
+	if parent != "" {
+		shard.ParentShardId = aws.String(parent)
+	}
+	return shard
+}
+
+func TestInitShardFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   []string
+		expected string
+	}{
+		{
+			name:     "missing separator",
+			ranges:   []string{"12345"},
+			expected: "expected '<start>-<end>'",
+		},
+		{
+			name:     "invalid start",
+			ranges:   []string{"abc-100"},
+			expected: `invalid start hash key "abc"`,
+		},
+		{
+			name:     "end exceeding maximum",
+			ranges:   []string{"0-340282366920938463463374607431768211456"},
+			expected: "invalid end hash key",
+		},
+		{
+			name:     "reversed",
+			ranges:   []string{"100-10"},
+			expected: "start exceeds end",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &KinesisConsumer{ShardHashKeyRanges: tt.ranges}
+			require.ErrorContains(t, k.Init(), tt.expected)
+		})
+	}
+
+	k := &KinesisConsumer{}
+	require.NoError(t, k.Init())
+	require.Nil(t, k.shards)
+}
+
+func TestShardFilterMatch(t *testing.T) {
+	f, err := newShardFilter([]string{"shardId-00000000000[5-9]"}, []string{"0-" + halfHashKey})
+	require.NoError(t, err)
+
+	// Matching by ID regardless of the hash key range
+	require.True(t, f.match(newShard("shardId-000000000007", "", "340282366920938463463374607431768211454", "340282366920938463463374607431768211455")))
+	// Hash key ranges fully contained, partially overlapping or adjacent
+	require.True(t, f.match(newShard("shardId-000000000000", "", "0", "100")))
+	require.True(t, f.match(newShard("shardId-000000000001", "", "100", "200000000000000000000000000000000000000")))
+	require.True(t, f.match(newShard("shardId-000000000002", "", halfHashKey, "340282366920938463463374607431768211455")))
+	require.False(t, f.match(newShard("shardId-000000000003", "", "170141183460469231731687303715884105728", "340282366920938463463374607431768211455")))
+	require.False(t, f.match(types.Shard{ShardId: aws.String("shardId-000000000004")}))
+}
+
+type mockShardLister struct {
+	kinesisClient
+	shards []types.Shard
+}
+
+func (m *mockShardLister) ListShards(context.Context, *kinesis.ListShardsInput, ...func(*kinesis.Options)) (*kinesis.ListShardsOutput, error) {
+	return &kinesis.ListShardsOutput{Shards: m.shards}, nil
+}
+
+func TestFilteredGroup(t *testing.T) {
+	// The child of a skipped parent must not wait for the parent to be
+	// consumed
+	client := &mockShardLister{
+		shards: []types.Shard{
+			newShard("shardId-000000000000", "", "170141183460469231731687303715884105728", "340282366920938463463374607431768211455"),
+			newShard("shardId-000000000001", "", "0", halfHashKey),
+			newShard("shardId-000000000002", "shardId-000000000000", "170141183460469231731687303715884105728", "200000000000000000000000000000000000000"),
+		},
+	}
+	f, err := newShardFilter([]string{"shardId-000000000002"}, []string{"0-1000"})
+	require.NoError(t, err)
+
+	k := &KinesisConsumer{Log: testutil.Logger{}}
+	group := &filteredGroup{
+		AllGroup: consumer.NewAllGroup(client, k, "test", &telegrafLoggerWrapper{k.Log}),
+		filter:   f,
+		log:      k.Log,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shardC := make(chan types.Shard, 3)
+	go group.Start(ctx, shardC) //nolint:errcheck // the mock client never fails
+
+	received := make(map[string]bool)
+	require.Eventually(t, func() bool {
+		select {
+		case shard := <-shardC:
+			received[aws.ToString(shard.ShardId)] = true
+		default:
+		}
+		return len(received) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, map[string]bool{"shardId-000000000001": true, "shardId-000000000002": true}, received)
+}
@@ -0,0 +1,127 @@
+package kinesis_consumer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// streamStats holds the internal statistics of a stream reported in the
+// "internal_kinesis_consumer" measurement and the state reported as status
+type streamStats struct {
+	stream           string
+	recordsRead      selfstat.Stat
+	bytesRead        selfstat.Stat
+	parseErrors      selfstat.Stat
+	checkpointWrites selfstat.Stat
+	checkpointErrors selfstat.Stat
+	reconnects       selfstat.Stat
+	throttleTime     selfstat.Stat
+
+	stateTex       sync.Mutex
+	connected      bool
+	lastCheckpoint time.Time
+	shardLag       map[string]int64
+}
+
+func newStreamStats(stream string) *streamStats {
+	tags := map[string]string{"stream": stream}
+	return &streamStats{
+		stream:           stream,
+		recordsRead:      selfstat.Register("kinesis_consumer", "records_read", tags),
+		bytesRead:        selfstat.Register("kinesis_consumer", "bytes_read", tags),
+		parseErrors:      selfstat.Register("kinesis_consumer", "parse_errors", tags),
+		checkpointWrites: selfstat.Register("kinesis_consumer", "checkpoint_writes", tags),
+		checkpointErrors: selfstat.Register("kinesis_consumer", "checkpoint_errors", tags),
+		reconnects:       selfstat.Register("kinesis_consumer", "reconnects", tags),
+		throttleTime:     selfstat.Register("kinesis_consumer", "throttle_time_ns", tags),
+	}
+}
+
+// getStats returns the statistics of the given stream
+func (k *KinesisConsumer) getStats(stream string) *streamStats {
+	k.statsTex.Lock()
+	defer k.statsTex.Unlock()
+
+	if k.stats == nil {
+		k.stats = make(map[string]*streamStats)
+	}
+	s, found := k.stats[stream]
+	if !found {
+		s = newStreamStats(stream)
+		k.stats[stream] = s
+	}
+	return s
+}
+
+// setShardLag records the time the consumer is behind the tip of the shard
+func (s *streamStats) setShardLag(shardID string, millis int64) {
+	tags := map[string]string{"stream": s.stream, "shard_id": shardID}
+	selfstat.Register("kinesis_consumer", "millis_behind_latest", tags).Set(millis)
+
+	s.stateTex.Lock()
+	defer s.stateTex.Unlock()
+	if s.shardLag == nil {
+		s.shardLag = make(map[string]int64)
+	}
+	s.shardLag[shardID] = millis
+}
+
+// setConnected records if the stream is currently scanned
+func (s *streamStats) setConnected(connected bool) {
+	s.stateTex.Lock()
+	defer s.stateTex.Unlock()
+	s.connected = connected
+}
+
+// setCheckpointed records the time of the last successful checkpoint
+func (s *streamStats) setCheckpointed(t time.Time) {
+	s.stateTex.Lock()
+	defer s.stateTex.Unlock()
+	s.lastCheckpoint = t
+}
+
+// status returns the state of the stream and if the stream is connected
+func (s *streamStats) status() (map[string]interface{}, bool) {
+	s.stateTex.Lock()
+	defer s.stateTex.Unlock()
+
+	shards := make(map[string]interface{}, len(s.shardLag))
+	for id, millis := range s.shardLag {
+		shards[id] = map[string]interface{}{"millis_behind_latest": millis}
+	}
+	status := map[string]interface{}{
+		"connected": s.connected,
+		"shards":    shards,
+	}
+	if !s.lastCheckpoint.IsZero() {
+		status["last_checkpoint"] = s.lastCheckpoint.Format(time.RFC3339Nano)
+	}
+	return status, s.connected
+}
+
+// Status reports the state of the consumed streams. The plugin is ready if
+// all streams are connected.
+func (k *KinesisConsumer) Status() (bool, map[string]interface{}) {
+	k.statsTex.Lock()
+	stats := make([]*streamStats, 0, len(k.stats))
+	for _, s := range k.stats {
+		stats = append(stats, s)
+	}
+	k.statsTex.Unlock()
+
+	ready := k.connected.Load()
+	streams := make(map[string]interface{}, len(stats))
+	for _, s := range stats {
+		status, connected := s.status()
+		streams[s.stream] = status
+		ready = ready && connected
+	}
+
+	return ready, map[string]interface{}{
+		"connected":            ready,
+		"undelivered_messages": k.undelivered.Load(),
+		"streams":              streams,
+	}
+}
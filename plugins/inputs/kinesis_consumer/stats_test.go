@@ -0,0 +1,93 @@
+package kinesis_consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestStats(t *testing.T) {
+	k := &KinesisConsumer{}
+
+	stats := k.getStats("test-stats")
+	require.Same(t, stats, k.getStats("test-stats"))
+	stats.recordsRead.Incr(2)
+	stats.bytesRead.Incr(128)
+	stats.checkpointWrites.Incr(1)
+	stats.setShardLag("shardId-000000000000", 1500)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"internal_kinesis_consumer",
+			map[string]string{"stream": "test-stats"},
+			map[string]interface{}{
+				"records_read":      int64(2),
+				"bytes_read":        int64(128),
+				"parse_errors":      int64(0),
+				"checkpoint_writes": int64(1),
+				"checkpoint_errors": int64(0),
+				"reconnects":        int64(0),
+				"throttle_time_ns":  int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"internal_kinesis_consumer",
+			map[string]string{"stream": "test-stats", "shard_id": "shardId-000000000000"},
+			map[string]interface{}{"millis_behind_latest": int64(1500)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range selfstat.Metrics() {
+		if v, found := m.GetTag("stream"); found && v == "test-stats" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestStatus(t *testing.T) {
+	k := &KinesisConsumer{}
+
+	// Not ready before connecting
+	ready, details := k.Status()
+	require.False(t, ready)
+	require.Equal(t, false, details["connected"])
+
+	k.connected.Store(true)
+	k.undelivered.Store(3)
+	stats := k.getStats("test-status")
+	stats.setConnected(true)
+	stats.setShardLag("shardId-000000000000", 250)
+	stats.setCheckpointed(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	ready, details = k.Status()
+	require.True(t, ready)
+	require.Equal(t, map[string]interface{}{
+		"connected":            true,
+		"undelivered_messages": int64(3),
+		"streams": map[string]interface{}{
+			"test-status": map[string]interface{}{
+				"connected":       true,
+				"last_checkpoint": "2024-01-02T03:04:05Z",
+				"shards": map[string]interface{}{
+					"shardId-000000000000": map[string]interface{}{"millis_behind_latest": int64(250)},
+				},
+			},
+		},
+	}, details)
+
+	// A disconnected stream makes the plugin unready
+	stats.setConnected(false)
+	ready, details = k.Status()
+	require.False(t, ready)
+	require.Equal(t, false, details["connected"])
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package linux_security
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// auditReader incrementally reads the audit log and counts the denials
+// since the start of the plugin. Rotations of the log are detected by a
+// changed inode or a shrinking file.
+type auditReader struct {
+	path   string
+	inode  uint64
+	offset int64
+
+	events          int64
+	selinuxDenials  int64
+	apparmorDenials int64
+}
+
+func (r *auditReader) update() error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Ino != r.inode {
+		r.inode = stat.Ino
+		r.offset = 0
+	}
+	if info.Size() < r.offset {
+		r.offset = 0
+	}
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			// Leave incomplete lines for the next read
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.offset += int64(len(line))
+		r.parse(line)
+	}
+}
+
+func (r *auditReader) parse(line string) {
+	r.events++
+	switch {
+	case strings.HasPrefix(line, "type=AVC ") && strings.Contains(line, "avc:  denied"):
+		r.selinuxDenials++
+	case strings.Contains(line, `apparmor="DENIED"`):
+		r.apparmorDenials++
+	}
+}
+
+func (r *auditReader) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"events":           r.events,
+		"selinux_denials":  r.selinuxDenials,
+		"apparmor_denials": r.apparmorDenials,
+	}
+}
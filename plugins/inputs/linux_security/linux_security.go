@@ -0,0 +1,206 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package linux_security
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableCollect = []string{"selinux", "apparmor", "audit", "files"}
+
+type LinuxSecurity struct {
+	PathSysfs    string          `toml:"host_sys"`
+	Collect      []string        `toml:"collect"`
+	AuditLog     string          `toml:"audit_log"`
+	ScanPaths    []string        `toml:"scan_paths"`
+	ScanInterval config.Duration `toml:"scan_interval"`
+	Log          telegraf.Logger `toml:"-"`
+
+	collect  map[string]bool
+	audit    *auditReader
+	scans    []fileScan
+	lastScan time.Time
+}
+
+func (*LinuxSecurity) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *LinuxSecurity) Init() error {
+	if err := choice.CheckSlice(l.Collect, availableCollect); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+	l.collect = make(map[string]bool, len(l.Collect))
+	for _, c := range l.Collect {
+		l.collect[c] = true
+	}
+
+	if l.collect["audit"] {
+		l.audit = &auditReader{path: l.AuditLog}
+	}
+
+	return nil
+}
+
+func (l *LinuxSecurity) Gather(acc telegraf.Accumulator) error {
+	if l.collect["selinux"] {
+		l.gatherSELinux(acc)
+	}
+	if l.collect["apparmor"] {
+		if err := l.gatherAppArmor(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering AppArmor status failed: %w", err))
+		}
+	}
+	if l.collect["audit"] {
+		if err := l.audit.update(); err != nil {
+			acc.AddError(fmt.Errorf("reading audit log failed: %w", err))
+		} else {
+			acc.AddCounter("linux_security_audit", l.audit.fields(), nil)
+		}
+	}
+	if l.collect["files"] {
+		l.gatherFiles(acc)
+	}
+
+	return nil
+}
+
+// gatherSELinux reports the SELinux status based on the selinuxfs mounted
+// below the sysfs path
+func (l *LinuxSecurity) gatherSELinux(acc telegraf.Accumulator) {
+	root := filepath.Join(l.PathSysfs, "fs", "selinux")
+
+	fields := map[string]interface{}{
+		"enabled": false,
+		"mode":    "disabled",
+	}
+	if enforce, err := readFileString(filepath.Join(root, "enforce")); err == nil {
+		fields["enabled"] = true
+		if enforce == "1" {
+			fields["mode"] = "enforcing"
+		} else {
+			fields["mode"] = "permissive"
+		}
+		if version, err := readFileInt(filepath.Join(root, "policyvers")); err == nil {
+			fields["policy_version"] = version
+		}
+		if deny, err := readFileInt(filepath.Join(root, "deny_unknown")); err == nil {
+			fields["deny_unknown"] = deny == 1
+		}
+	}
+
+	acc.AddFields("linux_security_selinux", fields, nil)
+}
+
+// gatherAppArmor reports the AppArmor status and the number of loaded
+// profiles per mode
+func (l *LinuxSecurity) gatherAppArmor(acc telegraf.Accumulator) error {
+	fields := map[string]interface{}{
+		"enabled": false,
+	}
+	enabled, err := readFileString(filepath.Join(l.PathSysfs, "module", "apparmor", "parameters", "enabled"))
+	if err != nil || enabled != "Y" {
+		acc.AddFields("linux_security_apparmor", fields, nil)
+		return nil
+	}
+	fields["enabled"] = true
+
+	root := filepath.Join(l.PathSysfs, "kernel", "security", "apparmor")
+	buf, err := os.ReadFile(filepath.Join(root, "profiles"))
+	if err != nil {
+		return err
+	}
+
+	// Each line has the form "<profile name> (<mode>)"
+	counts := map[string]int64{
+		"enforce":  0,
+		"complain": 0,
+		"kill":     0,
+	}
+	var total int64
+	for _, line := range strings.Split(strings.TrimSpace(string(buf)), "\n") {
+		idx := strings.LastIndex(line, " (")
+		if idx < 0 || !strings.HasSuffix(line, ")") {
+			continue
+		}
+		mode := line[idx+2 : len(line)-1]
+		counts[mode]++
+		total++
+	}
+	fields["profiles"] = total
+	for mode, count := range counts {
+		fields["profiles_"+mode] = count
+	}
+	if revision, err := readFileInt(filepath.Join(root, "revision")); err == nil {
+		fields["policy_revision"] = revision
+	}
+
+	acc.AddFields("linux_security_apparmor", fields, nil)
+	return nil
+}
+
+// gatherFiles reports the result of the latest file scan and rescans the
+// paths if the scan interval elapsed
+func (l *LinuxSecurity) gatherFiles(acc telegraf.Accumulator) {
+	if l.scans == nil || time.Since(l.lastScan) >= time.Duration(l.ScanInterval) {
+		l.scans = make([]fileScan, 0, len(l.ScanPaths))
+		for _, path := range l.ScanPaths {
+			l.scans = append(l.scans, scanPath(path, l.Log))
+		}
+		l.lastScan = time.Now()
+	}
+
+	for _, s := range l.scans {
+		fields := map[string]interface{}{
+			"world_writable_files": s.worldWritableFiles,
+			"world_writable_dirs":  s.worldWritableDirs,
+			"setuid_files":         s.setuidFiles,
+			"setgid_files":         s.setgidFiles,
+			"scan_duration_ms":     s.duration.Milliseconds(),
+		}
+		acc.AddFields("linux_security_files", fields, map[string]string{"path": s.path}, s.timestamp)
+	}
+}
+
+func readFileString(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+func readFileInt(path string) (int64, error) {
+	s, err := readFileString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func init() {
+	inputs.Add("linux_security", func() telegraf.Input {
+		return &LinuxSecurity{
+			PathSysfs:    "/sys",
+			Collect:      []string{"selinux", "apparmor", "audit"},
+			AuditLog:     "/var/log/audit/audit.log",
+			ScanPaths:    []string{"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/etc"},
+			ScanInterval: config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package linux_security
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type LinuxSecurity struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (l *LinuxSecurity) Init() error {
+	l.Log.Warn("Current platform is not supported")
+	return nil
+}
+func (*LinuxSecurity) SampleConfig() string                { return sampleConfig }
+func (*LinuxSecurity) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("linux_security", func() telegraf.Input {
+		return &LinuxSecurity{}
+	})
+}
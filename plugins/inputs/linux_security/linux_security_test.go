@@ -0,0 +1,198 @@
+//go:build linux
+
+package linux_security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0640))
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &LinuxSecurity{Collect: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'collect' setting")
+}
+
+func TestGatherSELinuxAppArmor(t *testing.T) {
+	sys := t.TempDir()
+	writeFile(t, filepath.Join(sys, "fs", "selinux", "enforce"), "1\n")
+	writeFile(t, filepath.Join(sys, "fs", "selinux", "policyvers"), "33\n")
+	writeFile(t, filepath.Join(sys, "fs", "selinux", "deny_unknown"), "0\n")
+	writeFile(t, filepath.Join(sys, "module", "apparmor", "parameters", "enabled"), "Y\n")
+	writeFile(t, filepath.Join(sys, "kernel", "security", "apparmor", "revision"), "12\n")
+	writeFile(t, filepath.Join(sys, "kernel", "security", "apparmor", "profiles"),
+		"/usr/sbin/cupsd (enforce)\n/usr/bin/man (complain)\nsnap.firefox (enforce)\n")
+
+	plugin := &LinuxSecurity{
+		PathSysfs: sys,
+		Collect:   []string{"selinux", "apparmor"},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"linux_security_selinux",
+			map[string]string{},
+			map[string]interface{}{
+				"enabled":        true,
+				"mode":           "enforcing",
+				"policy_version": int64(33),
+				"deny_unknown":   false,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"linux_security_apparmor",
+			map[string]string{},
+			map[string]interface{}{
+				"enabled":           true,
+				"profiles":          int64(3),
+				"profiles_enforce":  int64(2),
+				"profiles_complain": int64(1),
+				"profiles_kill":     int64(0),
+				"policy_revision":   int64(12),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherDisabled(t *testing.T) {
+	plugin := &LinuxSecurity{
+		PathSysfs: t.TempDir(),
+		Collect:   []string{"selinux", "apparmor"},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"linux_security_selinux",
+			map[string]string{},
+			map[string]interface{}{"enabled": false, "mode": "disabled"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"linux_security_apparmor",
+			map[string]string{},
+			map[string]interface{}{"enabled": false},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherAudit(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "audit.log"))
+	require.NoError(t, err)
+	logfile := filepath.Join(t.TempDir(), "audit.log")
+	writeFile(t, logfile, string(buf))
+
+	plugin := &LinuxSecurity{
+		Collect:  []string{"audit"},
+		AuditLog: logfile,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	acc.AssertContainsFields(t, "linux_security_audit", map[string]interface{}{
+		"events":           int64(4),
+		"selinux_denials":  int64(1),
+		"apparmor_denials": int64(1),
+	})
+
+	// Only new lines are counted, incomplete lines are kept for later
+	f, err := os.OpenFile(logfile, os.O_APPEND|os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.WriteString(`type=AVC msg=audit(1700000003.000:104): apparmor="DENIED" operation="exec"` + "\n" + "type=AVC")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	acc.AssertContainsFields(t, "linux_security_audit", map[string]interface{}{
+		"events":           int64(5),
+		"selinux_denials":  int64(1),
+		"apparmor_denials": int64(2),
+	})
+
+	// Rotated logs are read from the start
+	require.NoError(t, os.Remove(logfile))
+	writeFile(t, logfile, "type=AVC msg=audit(1700000004.000:105): avc:  denied  { write }\n")
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	acc.AssertContainsFields(t, "linux_security_audit", map[string]interface{}{
+		"events":           int64(6),
+		"selinux_denials":  int64(2),
+		"apparmor_denials": int64(2),
+	})
+}
+
+func TestGatherFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "regular"), "")
+	writeFile(t, filepath.Join(dir, "writable"), "")
+	writeFile(t, filepath.Join(dir, "sub", "suid"), "")
+	writeFile(t, filepath.Join(dir, "sub", "sgid"), "")
+	require.NoError(t, os.Chmod(filepath.Join(dir, "writable"), 0666))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "sub", "suid"), 0755|os.ModeSetuid))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "sub", "sgid"), 0755|os.ModeSetgid))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "open"), 0750))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "open"), 0777))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tmp"), 0750))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "tmp"), 0777|os.ModeSticky))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "writable"), filepath.Join(dir, "link")))
+
+	plugin := &LinuxSecurity{
+		Collect:      []string{"files"},
+		ScanPaths:    []string{dir},
+		ScanInterval: config.Duration(time.Hour),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Metrics, 1)
+	m := acc.Metrics[0]
+	require.Equal(t, "linux_security_files", m.Measurement)
+	require.Equal(t, map[string]string{"path": dir}, m.Tags)
+	require.Equal(t, int64(1), m.Fields["world_writable_files"])
+	require.Equal(t, int64(1), m.Fields["world_writable_dirs"])
+	require.Equal(t, int64(1), m.Fields["setuid_files"])
+	require.Equal(t, int64(1), m.Fields["setgid_files"])
+
+	// Results are reported until the next scan is due
+	require.NoError(t, os.Chmod(filepath.Join(dir, "writable"), 0644))
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(1), acc.Metrics[0].Fields["world_writable_files"])
+	require.Equal(t, m.Time, acc.Metrics[0].Time)
+}
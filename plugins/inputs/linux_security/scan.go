@@ -0,0 +1,69 @@
+//go:build linux
+
+package linux_security
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type fileScan struct {
+	path               string
+	timestamp          time.Time
+	duration           time.Duration
+	worldWritableFiles int64
+	worldWritableDirs  int64
+	setuidFiles        int64
+	setgidFiles        int64
+}
+
+// scanPath walks the given path without following symlinks and counts the
+// world-writable files, the world-writable directories without sticky bit
+// and the setuid and setgid files.
+func scanPath(path string, log telegraf.Logger) fileScan {
+	start := time.Now()
+	s := fileScan{path: path, timestamp: start}
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip inaccessible entries but continue with the remaining ones
+			log.Debugf("Scanning %q failed: %v", p, err)
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		mode := info.Mode()
+		switch {
+		case mode.IsDir():
+			if mode.Perm()&0o002 != 0 && mode&fs.ModeSticky == 0 {
+				s.worldWritableDirs++
+			}
+		case mode.IsRegular():
+			if mode.Perm()&0o002 != 0 {
+				s.worldWritableFiles++
+			}
+			if mode&fs.ModeSetuid != 0 {
+				s.setuidFiles++
+			}
+			if mode&fs.ModeSetgid != 0 {
+				s.setgidFiles++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Scanning %q failed: %v", path, err)
+	}
+	s.duration = time.Since(start)
+
+	return s
+}
@@ -34,8 +34,11 @@ type Lustre2 struct {
 	MgsProcfiles []string        `toml:"mgs_procfiles"`
 	OstProcfiles []string        `toml:"ost_procfiles"`
 	MdsProcfiles []string        `toml:"mds_procfiles"`
+	JobidPattern string          `toml:"jobid_pattern"`
 	Log          telegraf.Logger `toml:"-"`
 
+	jobidRegex *regexp.Regexp
+
 	// used by the testsuite to generate mock sysfs and procfs files
 	rootdir string
 
@@ -382,6 +385,17 @@ func (*Lustre2) SampleConfig() string {
 	return sampleConfig
 }
 
+func (l *Lustre2) Init() error {
+	if l.JobidPattern != "" {
+		re, err := regexp.Compile(l.JobidPattern)
+		if err != nil {
+			return fmt.Errorf("compiling 'jobid_pattern' failed: %w", err)
+		}
+		l.jobidRegex = re
+	}
+	return nil
+}
+
 func (l *Lustre2) GetLustreHealth() error {
 	// the linter complains about using an element containing '/' in filepath.Join()
 	// so we explicitly set the rootdir default to '/' in this function rather than
@@ -757,6 +771,7 @@ func (l *Lustre2) Gather(acc telegraf.Accumulator) error {
 		}
 		if len(tgs.job) > 0 {
 			tags["jobid"] = tgs.job
+			l.addJobTags(tgs.job, tags)
 		}
 		if len(tgs.client) > 0 {
 			tags["client"] = tgs.client
@@ -767,6 +782,23 @@ func (l *Lustre2) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// addJobTags attributes the job statistics by adding the named groups of the
+// job ID pattern as tags, e.g. to split the job ID into the job and user
+func (l *Lustre2) addJobTags(jobid string, tags map[string]string) {
+	if l.jobidRegex == nil {
+		return
+	}
+	match := l.jobidRegex.FindStringSubmatch(jobid)
+	if match == nil {
+		return
+	}
+	for i, name := range l.jobidRegex.SubexpNames() {
+		if name != "" && match[i] != "" {
+			tags[name] = match[i]
+		}
+	}
+}
+
 func init() {
 	inputs.Add("lustre2", func() telegraf.Input {
 		return &Lustre2{}
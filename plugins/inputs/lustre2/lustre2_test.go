@@ -463,6 +463,47 @@ func TestLustre2GeneratesJobstatsMetrics(t *testing.T) {
 	}
 }
 
+func TestLustre2JobidPattern(t *testing.T) {
+	rootdir := filepath.Join(t.TempDir(), "telegraf")
+	obddir := filepath.Join(rootdir, "proc", "fs", "lustre", "obdfilter", "OST0001")
+	require.NoError(t, os.MkdirAll(obddir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(obddir, "job_stats"), []byte(obdfilterJobStatsContents), 0640))
+
+	plugin := &Lustre2{
+		OstProcfiles: []string{"/proc/fs/lustre/obdfilter/*/job_stats"},
+		MdsProcfiles: []string{"/proc/fs/lustre/mdt/*/job_stats"},
+		JobidPattern: `^(?P<cluster>[^-]+)-(?P<job>.+)$`,
+		rootdir:      rootdir,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Job IDs matching the pattern get the named groups as additional tags
+	// while others are only tagged with the job ID
+	expected := map[string]map[string]string{
+		"cluster-testjob1": {"name": "OST0001", "jobid": "cluster-testjob1", "cluster": "cluster", "job": "testjob1"},
+		"testjob2":         {"name": "OST0001", "jobid": "testjob2"},
+	}
+	var found int
+	for _, m := range acc.GetTelegrafMetrics() {
+		jobid, ok := m.GetTag("jobid")
+		if !ok {
+			continue
+		}
+		require.Equal(t, expected[jobid], m.Tags())
+		require.Equal(t, uint64(25), m.Fields()["jobstats_write_calls"])
+		found++
+	}
+	require.Equal(t, len(expected), found)
+}
+
+func TestLustre2InvalidJobidPattern(t *testing.T) {
+	plugin := &Lustre2{JobidPattern: "(?P<job"}
+	require.ErrorContains(t, plugin.Init(), "compiling 'jobid_pattern' failed")
+}
+
 func TestLustre2CanParseConfiguration(t *testing.T) {
 	config := []byte(`
 [[inputs.lustre2]]
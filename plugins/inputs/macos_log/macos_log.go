@@ -0,0 +1,147 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package macos_log
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/process"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Layout of the timestamps emitted by 'log stream'
+const timestampLayout = "2006-01-02 15:04:05.000000-0700"
+
+type MacOSLog struct {
+	Predicate    string          `toml:"predicate"`
+	Level        string          `toml:"level"`
+	Processes    []string        `toml:"processes"`
+	RestartDelay config.Duration `toml:"restart_delay"`
+	Log          telegraf.Logger `toml:"-"`
+
+	acc     telegraf.Accumulator
+	process *process.Process
+}
+
+// entry is a single log entry as emitted by 'log stream --style ndjson'
+type entry struct {
+	Timestamp        string `json:"timestamp"`
+	EventType        string `json:"eventType"`
+	MessageType      string `json:"messageType"`
+	EventMessage     string `json:"eventMessage"`
+	Subsystem        string `json:"subsystem"`
+	Category         string `json:"category"`
+	ProcessImagePath string `json:"processImagePath"`
+	SenderImagePath  string `json:"senderImagePath"`
+	ProcessID        int64  `json:"processID"`
+	ThreadID         int64  `json:"threadID"`
+}
+
+func (*MacOSLog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (*MacOSLog) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (m *MacOSLog) Stop() {
+	if m.process != nil {
+		m.process.Stop()
+	}
+}
+
+func (m *MacOSLog) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		metric, err := parseLine(scanner.Bytes())
+		if err != nil {
+			m.acc.AddError(err)
+			continue
+		}
+		if metric != nil {
+			m.acc.AddMetric(metric)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+		m.acc.AddError(fmt.Errorf("reading log stream failed: %w", err))
+	}
+}
+
+func (m *MacOSLog) readErr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m.Log.Errorf("stderr: %s", scanner.Text())
+	}
+}
+
+// parseLine converts a single line of 'log stream' output to a metric. Lines
+// not containing a log entry, e.g. the filter header, result in a nil metric.
+func parseLine(line []byte) (telegraf.Metric, error) {
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte("{")) {
+		return nil, nil
+	}
+
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, fmt.Errorf("decoding log entry failed: %w", err)
+	}
+	if e.EventType != "" && e.EventType != "logEvent" {
+		return nil, nil
+	}
+
+	timestamp, err := time.Parse(timestampLayout, e.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("parsing timestamp %q failed: %w", e.Timestamp, err)
+	}
+
+	tags := make(map[string]string, 4)
+	for k, v := range map[string]string{
+		"subsystem":    e.Subsystem,
+		"category":     e.Category,
+		"message_type": e.MessageType,
+	} {
+		if v != "" {
+			tags[k] = v
+		}
+	}
+	if e.ProcessImagePath != "" {
+		tags["process"] = filepath.Base(e.ProcessImagePath)
+	}
+
+	fields := map[string]interface{}{
+		"message":    e.EventMessage,
+		"process_id": e.ProcessID,
+		"thread_id":  e.ThreadID,
+	}
+	if e.SenderImagePath != "" {
+		fields["sender"] = filepath.Base(e.SenderImagePath)
+	}
+
+	return metric.New("macos_log", tags, fields, timestamp), nil
+}
+
+func init() {
+	inputs.Add("macos_log", func() telegraf.Input {
+		return &MacOSLog{
+			Level:        "default",
+			RestartDelay: config.Duration(10 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,46 @@
+//go:build darwin
+
+package macos_log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/internal/process"
+)
+
+func (m *MacOSLog) Init() error {
+	if err := choice.Check(m.Level, []string{"default", "info", "debug"}); err != nil {
+		return fmt.Errorf("invalid 'level': %w", err)
+	}
+	return nil
+}
+
+func (m *MacOSLog) Start(acc telegraf.Accumulator) error {
+	m.acc = acc
+
+	command := []string{"/usr/bin/log", "stream", "--style", "ndjson", "--level", m.Level}
+	if m.Predicate != "" {
+		command = append(command, "--predicate", m.Predicate)
+	}
+	for _, p := range m.Processes {
+		command = append(command, "--process", p)
+	}
+
+	p, err := process.New(command, nil)
+	if err != nil {
+		return fmt.Errorf("creating process failed: %w", err)
+	}
+	p.ReadStdoutFn = m.read
+	p.ReadStderrFn = m.readErr
+	p.RestartDelay = time.Duration(m.RestartDelay)
+	p.Log = m.Log
+	if err := p.Start(); err != nil {
+		return fmt.Errorf("starting log stream failed: %w", err)
+	}
+	m.process = p
+
+	return nil
+}
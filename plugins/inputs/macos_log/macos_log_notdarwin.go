@@ -0,0 +1,14 @@
+//go:build !darwin
+
+package macos_log
+
+import "github.com/influxdata/telegraf"
+
+func (m *MacOSLog) Init() error {
+	m.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*MacOSLog) Start(telegraf.Accumulator) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package macos_log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected telegraf.Metric
+	}{
+		{
+			name: "header",
+			line: `Filtering the log data using "subsystem == "com.apple.xpc""`,
+		},
+		{
+			name: "log event",
+			line: `{"traceID":36934289125687300,"eventMessage":"Backup completed successfully.",` +
+				`"eventType":"logEvent","source":null,"formatString":"%{public}s",` +
+				`"activityIdentifier":0,"subsystem":"com.apple.TimeMachine","category":"BackupScheduling",` +
+				`"threadID":1250131,"senderImageUUID":"8F7C0D3C-5A4B-3F51-A0D5-6C1E3B6F2C11",` +
+				`"backtrace":{"frames":[]},"bootUUID":"","processImagePath":"\/System\/Library\/CoreServices\/backupd.bundle\/Contents\/Resources\/backupd",` +
+				`"timestamp":"2024-05-14 09:12:45.123456+0200","senderImagePath":"\/System\/Library\/PrivateFrameworks\/TimeMachine.framework\/Versions\/A\/TimeMachine",` +
+				`"machTimestamp":1200384913457,"messageType":"Default","processImageUUID":"5E1A7C7E-0F35-3D5C-9A0B-7A6A4C1F0D22",` +
+				`"processID":612,"senderProgramCounter":94788,"parentActivityIdentifier":0,"timezoneName":""}`,
+			expected: metric.New(
+				"macos_log",
+				map[string]string{
+					"subsystem":    "com.apple.TimeMachine",
+					"category":     "BackupScheduling",
+					"message_type": "Default",
+					"process":      "backupd",
+				},
+				map[string]interface{}{
+					"message":    "Backup completed successfully.",
+					"process_id": int64(612),
+					"thread_id":  int64(1250131),
+					"sender":     "TimeMachine",
+				},
+				time.Date(2024, 5, 14, 7, 12, 45, 123456000, time.UTC),
+			),
+		},
+		{
+			name: "empty subsystem",
+			line: `{"eventMessage":"kernel message","eventType":"logEvent","subsystem":"","category":"",` +
+				`"threadID":101,"processImagePath":"\/kernel","timestamp":"2024-05-14 09:12:46.000001+0000",` +
+				`"messageType":"Error","processID":0}`,
+			expected: metric.New(
+				"macos_log",
+				map[string]string{
+					"message_type": "Error",
+					"process":      "kernel",
+				},
+				map[string]interface{}{
+					"message":    "kernel message",
+					"process_id": int64(0),
+					"thread_id":  int64(101),
+				},
+				time.Date(2024, 5, 14, 9, 12, 46, 1000, time.UTC),
+			),
+		},
+		{
+			name: "activity event",
+			line: `{"eventMessage":"activity","eventType":"activityCreateEvent","timestamp":"2024-05-14 09:12:46.000001+0000"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseLine([]byte(tt.line))
+			require.NoError(t, err)
+			if tt.expected == nil {
+				require.Nil(t, actual)
+				return
+			}
+			testutil.RequireMetricEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseLineInvalid(t *testing.T) {
+	_, err := parseLine([]byte(`{"eventType":"logEvent"`))
+	require.ErrorContains(t, err, "decoding log entry failed")
+
+	_, err = parseLine([]byte(`{"eventType":"logEvent","timestamp":"yesterday"}`))
+	require.ErrorContains(t, err, "parsing timestamp")
+}
@@ -48,6 +48,10 @@ func (ms *MemStats) Gather(acc telegraf.Accumulator) error {
 		fields["free"] = vm.Free
 		fields["inactive"] = vm.Inactive
 		fields["wired"] = vm.Wired
+		if level, available, ok := memoryPressure(); ok {
+			fields["pressure_level"] = level
+			fields["pressure_available_percent"] = available
+		}
 	case "openbsd":
 		fields["active"] = vm.Active
 		fields["cached"] = vm.Cached
@@ -62,6 +66,9 @@ func (ms *MemStats) Gather(acc telegraf.Accumulator) error {
 		fields["inactive"] = vm.Inactive
 		fields["laundry"] = vm.Laundry
 		fields["wired"] = vm.Wired
+		if size, ok := zfsARCSize(); ok {
+			fields["arc_size"] = size
+		}
 	case "linux":
 		fields["active"] = vm.Active
 		fields["buffered"] = vm.Buffers
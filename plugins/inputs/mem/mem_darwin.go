@@ -0,0 +1,18 @@
+package mem
+
+import "golang.org/x/sys/unix"
+
+// memoryPressure returns the memory pressure level as reported by the kernel
+// (1 = normal, 2 = warning, 4 = critical) and the percentage of memory
+// considered available by the memory pressure subsystem.
+func memoryPressure() (level, available uint32, ok bool) {
+	level, err := unix.SysctlUint32("kern.memorystatus_vm_pressure_level")
+	if err != nil {
+		return 0, 0, false
+	}
+	available, err = unix.SysctlUint32("kern.memorystatus_level")
+	if err != nil {
+		return 0, 0, false
+	}
+	return level, available, true
+}
@@ -0,0 +1,14 @@
+package mem
+
+import "golang.org/x/sys/unix"
+
+// zfsARCSize returns the size of the ZFS adaptive replacement cache which is
+// accounted as wired memory on FreeBSD. The second return value is false if
+// the ZFS kernel module is not loaded.
+func zfsARCSize() (uint64, bool) {
+	size, err := unix.SysctlUint64("kstat.zfs.misc.arcstats.size")
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
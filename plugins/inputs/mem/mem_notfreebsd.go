@@ -0,0 +1,7 @@
+//go:build !freebsd
+
+package mem
+
+func zfsARCSize() (uint64, bool) {
+	return 0, false
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
@@ -16,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	common_consumer "github.com/influxdata/telegraf/plugins/common/consumer"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/selfstat"
@@ -46,24 +48,31 @@ type Client interface {
 type ClientFactory func(o *mqtt.ClientOptions) Client
 
 type MQTTConsumer struct {
-	Servers                []string             `toml:"servers"`
-	Topics                 []string             `toml:"topics"`
-	TopicTag               *string              `toml:"topic_tag"`
-	TopicParserConfig      []TopicParsingConfig `toml:"topic_parsing"`
-	Username               config.Secret        `toml:"username"`
-	Password               config.Secret        `toml:"password"`
-	QoS                    int                  `toml:"qos"`
-	ConnectionTimeout      config.Duration      `toml:"connection_timeout"`
-	KeepAliveInterval      config.Duration      `toml:"keepalive"`
-	PingTimeout            config.Duration      `toml:"ping_timeout"`
-	MaxUndeliveredMessages int                  `toml:"max_undelivered_messages"`
-	PersistentSession      bool                 `toml:"persistent_session"`
-	ClientTrace            bool                 `toml:"client_trace"`
-	ClientID               string               `toml:"client_id"`
-	Log                    telegraf.Logger      `toml:"-"`
+	Servers                []string                 `toml:"servers"`
+	Topics                 []string                 `toml:"topics"`
+	TopicTag               *string                  `toml:"topic_tag"`
+	TopicParserConfig      []TopicParsingConfig     `toml:"topic_parsing"`
+	TopicFormatConfig      []map[string]interface{} `toml:"topic_format"`
+	Username               config.Secret            `toml:"username"`
+	Password               config.Secret            `toml:"password"`
+	QoS                    int                      `toml:"qos"`
+	ConnectionTimeout      config.Duration          `toml:"connection_timeout"`
+	KeepAliveInterval      config.Duration          `toml:"keepalive"`
+	PingTimeout            config.Duration          `toml:"ping_timeout"`
+	MaxUndeliveredMessages int                      `toml:"max_undelivered_messages"`
+	PersistentSession      bool                     `toml:"persistent_session"`
+	SessionStoreDirectory  string                   `toml:"session_store_directory"`
+	ClientTrace            bool                     `toml:"client_trace"`
+	ClientID               string                   `toml:"client_id"`
+	Log                    telegraf.Logger          `toml:"-"`
 	tls.ClientConfig
+	common_consumer.ErrorPolicyConfig
+	common_consumer.UnwrapConfig
 
 	parser        telegraf.Parser
+	policy        *common_consumer.ErrorPolicy
+	unwrapper     *common_consumer.Unwrapper
+	halted        atomic.Bool
 	clientFactory ClientFactory
 	client        Client
 	opts          *mqtt.ClientOptions
@@ -73,6 +82,7 @@ type MQTTConsumer struct {
 	messagesMutex sync.Mutex
 	topicTagParse string
 	topicParsers  []*TopicParser
+	topicFormats  []*TopicFormat
 	ctx           context.Context
 	cancel        context.CancelFunc
 	payloadSize   selfstat.Stat
@@ -99,6 +109,9 @@ func (m *MQTTConsumer) Init() error {
 	if m.PersistentSession && m.ClientID == "" {
 		return errors.New("persistent_session requires client_id")
 	}
+	if m.SessionStoreDirectory != "" && !m.PersistentSession {
+		return errors.New("session_store_directory requires persistent_session")
+	}
 	if m.QoS > 2 || m.QoS < 0 {
 		return fmt.Errorf("qos value must be 0, 1, or 2: %d", m.QoS)
 	}
@@ -125,6 +138,27 @@ func (m *MQTTConsumer) Init() error {
 		m.topicParsers = append(m.topicParsers, p)
 	}
 
+	m.topicFormats = make([]*TopicFormat, 0, len(m.TopicFormatConfig))
+	for _, cfg := range m.TopicFormatConfig {
+		f, err := newTopicFormat(cfg, m.Log)
+		if err != nil {
+			return fmt.Errorf("config error topic format: %w", err)
+		}
+		m.topicFormats = append(m.topicFormats, f)
+	}
+
+	policy, err := common_consumer.NewErrorPolicy(m.ErrorPolicyConfig, map[string]string{"input": "mqtt_consumer"}, m.Log)
+	if err != nil {
+		return err
+	}
+	m.policy = policy
+
+	unwrapper, err := common_consumer.NewUnwrapper(m.UnwrapConfig)
+	if err != nil {
+		return err
+	}
+	m.unwrapper = unwrapper
+
 	m.payloadSize = selfstat.Register("mqtt_consumer", "payload_size", make(map[string]string))
 	m.messagesRecv = selfstat.Register("mqtt_consumer", "messages_received", make(map[string]string))
 	return nil
@@ -229,7 +263,17 @@ func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
 	m.payloadSize.Incr(int64(payloadBytes))
 	m.messagesRecv.Incr(1)
 
-	metrics, err := m.parser.Parse(msg.Payload())
+	metrics, err := m.policy.Parse(msg.Topic(), msg.Payload(), func() ([]telegraf.Metric, error) {
+		return m.unwrapper.Parse(m.parserFor(msg.Topic()), msg.Payload())
+	})
+	if errors.Is(err, common_consumer.ErrHalt) {
+		// Do not acknowledge the message so a persistent session receives
+		// it again after a restart
+		m.acc.AddError(err)
+		<-m.sem
+		m.halt()
+		return
+	}
 	if err != nil || len(metrics) == 0 {
 		if len(metrics) == 0 {
 			once.Do(func() {
@@ -265,6 +309,30 @@ func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
 	m.messages[id] = msg
 	m.messagesMutex.Unlock()
 }
+
+// parserFor returns the parser of the first topic format matching the topic
+// or the plugin-level parser
+func (m *MQTTConsumer) parserFor(topic string) telegraf.Parser {
+	for _, f := range m.topicFormats {
+		if f.Match(topic) {
+			return f.parser
+		}
+	}
+	return m.parser
+}
+
+// halt disconnects from the broker and prevents reconnecting
+func (m *MQTTConsumer) halt() {
+	if m.halted.Swap(true) {
+		return
+	}
+	m.Log.Error("Stopped consuming due to parse error, restart to resume")
+
+	// Disconnecting waits for the message handlers so it must not block the
+	// handler calling us
+	go m.client.Disconnect(200)
+}
+
 func (m *MQTTConsumer) Stop() {
 	if m.client.IsConnected() {
 		m.Log.Debugf("Disconnecting %v", m.Servers)
@@ -274,9 +342,12 @@ func (m *MQTTConsumer) Stop() {
 	if m.cancel != nil {
 		m.cancel()
 	}
+	if err := m.policy.Close(); err != nil {
+		m.Log.Errorf("Closing dead-letter file failed: %v", err)
+	}
 }
 func (m *MQTTConsumer) Gather(_ telegraf.Accumulator) error {
-	if !m.client.IsConnected() {
+	if !m.client.IsConnected() && !m.halted.Load() {
 		m.Log.Debugf("Connecting %v", m.Servers)
 		return m.connect()
 	}
@@ -338,6 +409,10 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 	opts.SetPingTimeout(time.Duration(m.PingTimeout))
 	opts.SetCleanSession(!m.PersistentSession)
 	opts.SetAutoAckDisabled(m.PersistentSession)
+	if m.SessionStoreDirectory != "" {
+		// Keep the state of in-flight QoS 1 and 2 messages across restarts
+		opts.SetStore(mqtt.NewFileStore(m.SessionStoreDirectory))
+	}
 	opts.SetConnectionLostHandler(m.onConnectionLost)
 	return opts, nil
 }
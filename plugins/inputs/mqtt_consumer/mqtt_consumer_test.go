@@ -3,6 +3,7 @@ package mqtt_consumer
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,8 +14,10 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	_ "github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -162,8 +165,9 @@ func TestPersistentClientIDFail(t *testing.T) {
 }
 
 type Message struct {
-	topic string
-	qos   byte
+	topic   string
+	qos     byte
+	payload []byte
 }
 
 func (m *Message) Duplicate() bool {
@@ -187,6 +191,9 @@ func (m *Message) MessageID() uint16 {
 }
 
 func (m *Message) Payload() []byte {
+	if m.payload != nil {
+		return m.payload
+	}
 	return []byte("cpu time_idle=42i")
 }
 
@@ -569,6 +576,137 @@ func TestTopicTag(t *testing.T) {
 	}
 }
 
+func TestTopicFormat(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(filepath.Join("testdata", "topic_format.conf")))
+	require.Len(t, c.Inputs, 1)
+
+	var handler mqtt.MessageHandler
+	client := &FakeClient{
+		ConnectF: func() mqtt.Token {
+			return &FakeToken{}
+		},
+		AddRouteF: func(callback mqtt.MessageHandler) {
+			handler = callback
+		},
+		SubscribeMultipleF: func() mqtt.Token {
+			return &FakeToken{}
+		},
+		DisconnectF: func() {
+		},
+	}
+	plugin := c.Inputs[0].Input.(*MQTTConsumer)
+	plugin.clientFactory = func(*mqtt.ClientOptions) Client {
+		return client
+	}
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	handler(nil, &Message{topic: "sensors/kitchen/temperature", payload: []byte("21.5")})
+	handler(nil, &Message{topic: "sensors/state/kitchen/window", payload: []byte("open")})
+	handler(nil, &Message{topic: "sensors/kitchen"})
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mqtt_consumer",
+			map[string]string{"topic": "sensors/kitchen/temperature"},
+			map[string]interface{}{"temperature": 21.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mqtt_consumer",
+			map[string]string{"topic": "sensors/state/kitchen/window"},
+			map[string]interface{}{"value": "open"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"topic": "sensors/kitchen"},
+			map[string]interface{}{"time_idle": int64(42)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestTopicFormatInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "missing topic",
+			options:  map[string]interface{}{"data_format": "influx"},
+			expected: "'topic' must be a non-empty string",
+		},
+		{
+			name:     "missing data format",
+			options:  map[string]interface{}{"topic": "a/b"},
+			expected: `'data_format' for topic "a/b" must be a non-empty string`,
+		},
+		{
+			name:     "unknown data format",
+			options:  map[string]interface{}{"topic": "a/b", "data_format": "foo"},
+			expected: `undefined but requested parser "foo"`,
+		},
+		{
+			name:     "unknown parser option",
+			options:  map[string]interface{}{"topic": "a/b", "data_format": "value", "foo": "bar"},
+			expected: `invalid parser options for topic "a/b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := New(nil)
+			plugin.Log = testutil.Logger{}
+			plugin.TopicFormatConfig = []map[string]interface{}{tt.options}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTopicFormatMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		match  bool
+	}{
+		{filter: "a/b/c", topic: "a/b/c", match: true},
+		{filter: "a/b/c", topic: "a/b", match: false},
+		{filter: "a/b", topic: "a/b/c", match: false},
+		{filter: "a/+/c", topic: "a/x/c", match: true},
+		{filter: "a/+/c", topic: "a/x/d", match: false},
+		{filter: "a/#", topic: "a/x/y/z", match: true},
+		{filter: "a/#", topic: "a", match: true},
+		{filter: "#", topic: "a/b", match: true},
+		{filter: "+/+", topic: "/b", match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter+" "+tt.topic, func(t *testing.T) {
+			f := &TopicFormat{filter: strings.Split(tt.filter, "/")}
+			require.Equal(t, tt.match, f.Match(tt.topic))
+		})
+	}
+}
+
+func TestSessionStoreRequiresPersistentSession(t *testing.T) {
+	plugin := New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.SessionStoreDirectory = t.TempDir()
+	require.ErrorContains(t, plugin.Init(), "session_store_directory requires persistent_session")
+
+	plugin.PersistentSession = true
+	plugin.ClientID = "telegraf"
+	require.NoError(t, plugin.Init())
+}
+
 func TestAddRouteCalledForEachTopic(t *testing.T) {
 	client := &FakeClient{
 		ConnectF: func() mqtt.Token {
@@ -0,0 +1,78 @@
+package mqtt_consumer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/toml"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// TopicFormat is a parser used for messages of topics matching the filter
+// instead of the plugin-level parser
+type TopicFormat struct {
+	filter []string
+	parser telegraf.Parser
+}
+
+// newTopicFormat creates the parser from the options of a 'topic_format'
+// table. All options except 'topic' and 'data_format' are passed to the
+// parser.
+func newTopicFormat(options map[string]interface{}, log telegraf.Logger) (*TopicFormat, error) {
+	topic, ok := options["topic"].(string)
+	if !ok || topic == "" {
+		return nil, errors.New("'topic' must be a non-empty string")
+	}
+	dataFormat, ok := options["data_format"].(string)
+	if !ok || dataFormat == "" {
+		return nil, fmt.Errorf("'data_format' for topic %q must be a non-empty string", topic)
+	}
+
+	creator, found := parsers.Parsers[dataFormat]
+	if !found {
+		return nil, fmt.Errorf("undefined but requested parser %q for topic %q", dataFormat, topic)
+	}
+	parser := creator("mqtt_consumer")
+
+	parserOptions := make(map[string]interface{}, len(options))
+	for k, v := range options {
+		if k != "topic" && k != "data_format" {
+			parserOptions[k] = v
+		}
+	}
+	buf, err := toml.Marshal(parserOptions)
+	if err != nil {
+		return nil, fmt.Errorf("encoding parser options for topic %q failed: %w", topic, err)
+	}
+	if err := toml.Unmarshal(buf, parser); err != nil {
+		return nil, fmt.Errorf("invalid parser options for topic %q: %w", topic, err)
+	}
+
+	models.SetLoggerOnPlugin(parser, log)
+	if p, ok := parser.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return nil, fmt.Errorf("initializing parser for topic %q failed: %w", topic, err)
+		}
+	}
+
+	return &TopicFormat{filter: strings.Split(topic, "/"), parser: parser}, nil
+}
+
+// Match checks if the topic matches the filter following the MQTT wildcard
+// rules, i.e. '+' matches a single level and '#' all remaining levels
+func (f *TopicFormat) Match(topic string) bool {
+	levels := strings.Split(topic, "/")
+	for i, part := range f.filter {
+		if part == "#" {
+			return true
+		}
+		if i >= len(levels) || part != "+" && part != levels[i] {
+			return false
+		}
+	}
+	return len(levels) == len(f.filter)
+}
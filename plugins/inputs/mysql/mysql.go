@@ -2,6 +2,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"errors"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/mysql/v1"
@@ -56,12 +58,18 @@ type Mysql struct {
 	PerfSummaryEvents                   []string         `toml:"perf_summary_events"`
 	IntervalSlow                        config.Duration  `toml:"interval_slow"`
 	MetricVersion                       int              `toml:"metric_version"`
+	ConnectionMaxOpen                   int              `toml:"connection_max_open"`
+	ConnectionMaxIdle                   int              `toml:"connection_max_idle"`
+	ConnectionMaxLifetime               config.Duration  `toml:"connection_max_lifetime"`
+	HealthCheckQuery                    string           `toml:"health_check_query"`
 	Log                                 telegraf.Logger  `toml:"-"`
 	tls.ClientConfig
+	common_sql.AuthConfig
 
 	lastT               time.Time
 	getStatusQuery      string
 	loggedConvertFields map[string]bool
+	pools               []*common_sql.Pool
 }
 
 const (
@@ -96,6 +104,10 @@ func (m *Mysql) Init() error {
 
 	m.loggedConvertFields = make(map[string]bool)
 
+	if err := m.AuthConfig.Init(); err != nil {
+		return err
+	}
+
 	// Register the TLS configuration. Due to the registry being a global
 	// one for the mysql package, we need to define unique IDs to avoid
 	// side effects and races between different plugin instances. Therefore,
@@ -145,6 +157,7 @@ func (m *Mysql) Init() error {
 
 		m.Servers[i] = server
 	}
+	m.pools = make([]*common_sql.Pool, len(m.Servers))
 
 	return nil
 }
@@ -153,18 +166,30 @@ func (m *Mysql) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 
 	// Loop through each server and collect metrics
-	for _, server := range m.Servers {
+	for i := range m.Servers {
 		wg.Add(1)
-		go func(s *config.Secret) {
+		go func(idx int) {
 			defer wg.Done()
-			acc.AddError(m.gatherServer(s, acc))
-		}(server)
+			acc.AddError(m.gatherServer(idx, acc))
+		}(i)
 	}
 
 	wg.Wait()
 	return nil
 }
 
+// Stop closes the connection pools kept across gathers
+func (m *Mysql) Stop() {
+	for _, pool := range m.pools {
+		if pool == nil {
+			continue
+		}
+		if err := pool.Close(); err != nil {
+			m.Log.Errorf("Closing connection failed: %v", err)
+		}
+	}
+}
+
 // These are const but can't be declared as such because golang doesn't allow const maps
 var (
 	// status counter
@@ -430,8 +455,8 @@ const (
 	`
 )
 
-func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) error {
-	dsnSecret, err := server.Get()
+func (m *Mysql) gatherServer(idx int, acc telegraf.Accumulator) error {
+	dsnSecret, err := m.Servers[idx].Get()
 	if err != nil {
 		return err
 	}
@@ -439,11 +464,25 @@ func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) er
 	dsnSecret.Destroy()
 	servtag := getDSNTag(dsn)
 
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return err
+	// Keep the connections to the server across gathers
+	if m.pools[idx] == nil {
+		pool, err := common_sql.Open("mysql", dsn, common_sql.PoolConfig{
+			MaxOpen:          m.ConnectionMaxOpen,
+			MaxIdle:          m.ConnectionMaxIdle,
+			MaxLifetime:      time.Duration(m.ConnectionMaxLifetime),
+			HealthCheckQuery: m.HealthCheckQuery,
+			Auth:             &m.AuthConfig,
+		})
+		if err != nil {
+			return err
+		}
+		m.pools[idx] = pool
+	}
+	pool := m.pools[idx]
+	if err := pool.Check(context.Background()); err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", servtag, err)
 	}
-	defer db.Close()
+	db := pool.DB
 
 	err = m.gatherGlobalStatuses(db, servtag, acc)
 	if err != nil {
@@ -1988,6 +2027,7 @@ func init() {
 			PerfEventsStatementsLimit:           defaultPerfEventsStatementsLimit,
 			PerfEventsStatementsTimeLimit:       defaultPerfEventsStatementsTimeLimit,
 			GatherGlobalVars:                    defaultGatherGlobalVars,
+			ConnectionMaxIdle:                   2,
 		}
 	})
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
@@ -550,6 +551,14 @@ func TestGatherGlobalVariables(t *testing.T) {
 	}
 }
 
+func TestMysqlNoServiceInput(t *testing.T) {
+	// Spooling and cluster singletons are not supported for service inputs
+	var plugin telegraf.Input = &Mysql{}
+	_, ok := plugin.(telegraf.ServiceInput)
+	require.False(t, ok)
+	require.Implements(t, (*telegraf.StoppableInput)(nil), plugin)
+}
+
 func TestNewNamespace(t *testing.T) {
 	testCases := []struct {
 		words     []string
@@ -4,6 +4,7 @@ package netstat
 import (
 	_ "embed"
 	"fmt"
+	"strconv"
 	"syscall"
 
 	"github.com/influxdata/telegraf"
@@ -14,8 +15,43 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// TCP states as defined in include/net/tcp_states.h
+var tcpStates = map[uint8]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+}
+
 type NetStats struct {
+	PerProcess bool            `toml:"per_process"`
+	Namespace  string          `toml:"namespace"`
+	Log        telegraf.Logger `toml:"-"`
+
 	PS system.PS
+
+	procDir     string
+	listSockets func(namespace string) ([]socketInfo, error)
+}
+
+// socketInfo describes a socket reported by the sock_diag interface
+type socketInfo struct {
+	udp   bool
+	state uint8
+	inode uint32
+}
+
+// processInfo identifies the process owning a socket
+type processInfo struct {
+	pid  int
+	name string
 }
 
 func (*NetStats) SampleConfig() string {
@@ -23,6 +59,11 @@ func (*NetStats) SampleConfig() string {
 }
 
 func (ns *NetStats) Gather(acc telegraf.Accumulator) error {
+	// Sockets of other namespaces are only available via sock_diag
+	if ns.PerProcess || ns.Namespace != "" {
+		return ns.gatherSockets(acc)
+	}
+
 	netconns, err := ns.PS.NetConnections()
 	if err != nil {
 		return fmt.Errorf("error getting net connections info: %w", err)
@@ -44,7 +85,77 @@ func (ns *NetStats) Gather(acc telegraf.Accumulator) error {
 		counts[netcon.Status] = c + 1
 	}
 
-	fields := map[string]interface{}{
+	acc.AddFields("netstat", countFields(counts), tags)
+
+	return nil
+}
+
+// gatherSockets collects the socket counts using sock_diag and, if enabled,
+// the counts per process
+func (ns *NetStats) gatherSockets(acc telegraf.Accumulator) error {
+	sockets, err := ns.listSockets(ns.Namespace)
+	if err != nil {
+		return fmt.Errorf("listing sockets failed: %w", err)
+	}
+
+	tags := make(map[string]string)
+	if ns.Namespace != "" {
+		tags["namespace"] = ns.Namespace
+	}
+
+	counts := map[string]int{"UDP": 0}
+	for _, s := range sockets {
+		counts[socketState(s)]++
+	}
+	acc.AddFields("netstat", countFields(counts), tags)
+
+	if !ns.PerProcess {
+		return nil
+	}
+
+	owners, err := socketOwners(ns.procDir)
+	if err != nil {
+		return fmt.Errorf("mapping sockets to processes failed: %w", err)
+	}
+
+	// Sockets without an owner, e.g. in TIME_WAIT state, are only part of
+	// the totals above
+	perProcess := make(map[processInfo]map[string]int)
+	for _, s := range sockets {
+		owner, found := owners[s.inode]
+		if !found || s.inode == 0 {
+			continue
+		}
+		if _, found := perProcess[owner]; !found {
+			perProcess[owner] = map[string]int{"UDP": 0}
+		}
+		perProcess[owner][socketState(s)]++
+	}
+
+	for owner, counts := range perProcess {
+		ptags := make(map[string]string, len(tags)+2)
+		for k, v := range tags {
+			ptags[k] = v
+		}
+		ptags["pid"] = strconv.Itoa(owner.pid)
+		ptags["process_name"] = owner.name
+		acc.AddFields("netstat_process", countFields(counts), ptags)
+	}
+	return nil
+}
+
+func socketState(s socketInfo) string {
+	if s.udp {
+		return "UDP"
+	}
+	if state, found := tcpStates[s.state]; found {
+		return state
+	}
+	return "NONE"
+}
+
+func countFields(counts map[string]int) map[string]interface{} {
+	return map[string]interface{}{
 		"tcp_established": counts["ESTABLISHED"],
 		"tcp_syn_sent":    counts["SYN_SENT"],
 		"tcp_syn_recv":    counts["SYN_RECV"],
@@ -59,13 +170,14 @@ func (ns *NetStats) Gather(acc telegraf.Accumulator) error {
 		"tcp_none":        counts["NONE"],
 		"udp_socket":      counts["UDP"],
 	}
-	acc.AddFields("netstat", fields, tags)
-
-	return nil
 }
 
 func init() {
 	inputs.Add("netstat", func() telegraf.Input {
-		return &NetStats{PS: system.NewSystemPS()}
+		return &NetStats{
+			PS:          system.NewSystemPS(),
+			procDir:     "/proc",
+			listSockets: listSockets,
+		}
 	})
 }
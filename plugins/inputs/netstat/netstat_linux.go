@@ -0,0 +1,122 @@
+//go:build linux
+
+package netstat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+func (ns *NetStats) Init() error {
+	if ns.Namespace == "" {
+		return nil
+	}
+
+	// Check the namespace exists to fail early on typos
+	handle, err := netns.GetFromName(ns.Namespace)
+	if err != nil {
+		return fmt.Errorf("opening namespace %q failed: %w", ns.Namespace, err)
+	}
+	return handle.Close()
+}
+
+// listSockets returns the TCP and UDP sockets of the given network namespace
+// or the namespace of the process if empty
+func listSockets(namespace string) ([]socketInfo, error) {
+	handle, err := netlinkHandle(namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	var sockets []socketInfo
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		tcp, err := handle.SocketDiagTCP(family)
+		if err != nil {
+			return nil, fmt.Errorf("querying TCP sockets failed: %w", err)
+		}
+		for _, s := range tcp {
+			sockets = append(sockets, socketInfo{state: s.State, inode: s.INode})
+		}
+
+		udp, err := handle.SocketDiagUDP(family)
+		if err != nil {
+			return nil, fmt.Errorf("querying UDP sockets failed: %w", err)
+		}
+		for _, s := range udp {
+			sockets = append(sockets, socketInfo{udp: true, state: s.State, inode: s.INode})
+		}
+	}
+	return sockets, nil
+}
+
+func netlinkHandle(namespace string) (*netlink.Handle, error) {
+	if namespace == "" {
+		return netlink.NewHandle(syscall.NETLINK_INET_DIAG)
+	}
+
+	ns, err := netns.GetFromName(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("opening namespace %q failed: %w", namespace, err)
+	}
+	defer ns.Close()
+
+	return netlink.NewHandleAt(ns, syscall.NETLINK_INET_DIAG)
+}
+
+// socketOwners maps the socket inodes to the processes holding a file
+// descriptor for the socket. Sockets are global across network namespaces so
+// the mapping works for all namespaces.
+func socketOwners(procDir string) (map[uint32]processInfo, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[uint32]processInfo)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		fds, err := os.ReadDir(filepath.Join(procDir, entry.Name(), "fd"))
+		if err != nil {
+			// The process terminated or we lack the permissions
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+				continue
+			}
+			return nil, err
+		}
+
+		var owner *processInfo
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(procDir, entry.Name(), "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 32)
+			if err != nil {
+				continue
+			}
+
+			if owner == nil {
+				comm, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "comm"))
+				if err != nil {
+					break
+				}
+				owner = &processInfo{pid: pid, name: strings.TrimSpace(string(comm))}
+			}
+			owners[uint32(inode)] = *owner
+		}
+	}
+	return owners, nil
+}
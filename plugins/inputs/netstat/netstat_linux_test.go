@@ -0,0 +1,77 @@
+//go:build linux
+
+package netstat
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestNamespaceNotFound(t *testing.T) {
+	ns := &NetStats{Namespace: "telegraf-does-not-exist"}
+	require.ErrorContains(t, ns.Init(), `opening namespace "telegraf-does-not-exist" failed`)
+}
+
+func TestPerProcess(t *testing.T) {
+	ns := &NetStats{
+		PerProcess: true,
+		procDir:    "testdata/proc",
+		listSockets: func(string) ([]socketInfo, error) {
+			return []socketInfo{
+				{state: 10, inode: 1001},
+				{state: 1, inode: 1002},
+				{state: 1, inode: 1003},
+				{udp: true, state: 7, inode: 2001},
+				{state: 6, inode: 0},
+				{state: 1, inode: 9999},
+			}, nil
+		},
+	}
+	require.NoError(t, ns.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, ns.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"netstat",
+			map[string]string{},
+			countFields(map[string]int{"ESTABLISHED": 3, "LISTEN": 1, "TIME_WAIT": 1, "UDP": 1}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_process",
+			map[string]string{"pid": "100", "process_name": "nginx"},
+			countFields(map[string]int{"ESTABLISHED": 2, "LISTEN": 1}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_process",
+			map[string]string{"pid": "200", "process_name": "chronyd"},
+			countFields(map[string]int{"UDP": 1}),
+			time.Unix(0, 0),
+		),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestPerProcessListError(t *testing.T) {
+	ns := &NetStats{
+		PerProcess: true,
+		procDir:    "testdata/proc",
+		listSockets: func(string) ([]socketInfo, error) {
+			return nil, errors.New("permission denied")
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, ns.Gather(&acc), "listing sockets failed: permission denied")
+}
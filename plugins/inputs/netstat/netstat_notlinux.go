@@ -0,0 +1,20 @@
+//go:build !linux
+
+package netstat
+
+import "errors"
+
+func (ns *NetStats) Init() error {
+	if ns.PerProcess || ns.Namespace != "" {
+		return errors.New("'per_process' and 'namespace' are only supported on Linux")
+	}
+	return nil
+}
+
+func listSockets(string) ([]socketInfo, error) {
+	return nil, errors.New("not supported on this platform")
+}
+
+func socketOwners(string) (map[uint32]processInfo, error) {
+	return nil, errors.New("not supported on this platform")
+}
@@ -0,0 +1,198 @@
+//go:build linux
+
+package nftables
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mdlayher/netlink"
+)
+
+// Netlink constants of the nftables subsystem, see
+// linux/netfilter/nfnetlink.h and linux/netfilter/nf_tables.h
+const (
+	familyNetfilter = 12 // NETLINK_NETFILTER
+
+	subsysNFTables = 10 // NFNL_SUBSYS_NFTABLES
+	msgGetRule     = 7  // NFT_MSG_GETRULE
+
+	attrRuleTable       = 1 // NFTA_RULE_TABLE
+	attrRuleChain       = 2 // NFTA_RULE_CHAIN
+	attrRuleHandle      = 3 // NFTA_RULE_HANDLE
+	attrRuleExpressions = 4 // NFTA_RULE_EXPRESSIONS
+	attrRuleUserdata    = 7 // NFTA_RULE_USERDATA
+
+	attrListElem = 1 // NFTA_LIST_ELEM
+	attrExprName = 1 // NFTA_EXPR_NAME
+	attrExprData = 2 // NFTA_EXPR_DATA
+
+	attrCounterBytes   = 1 // NFTA_COUNTER_BYTES
+	attrCounterPackets = 2 // NFTA_COUNTER_PACKETS
+
+	udataRuleComment = 0 // NFTNL_UDATA_RULE_COMMENT
+)
+
+// Names of the nftables address families
+var families = map[uint8]string{
+	1:  "inet",
+	2:  "ip",
+	3:  "arp",
+	5:  "netdev",
+	7:  "bridge",
+	10: "ip6",
+}
+
+type rule struct {
+	family  string
+	table   string
+	chain   string
+	handle  uint64
+	comment string
+
+	hasCounter bool
+	packets    uint64
+	bytes      uint64
+}
+
+type client interface {
+	rules() ([]rule, error)
+	close() error
+}
+
+type netlinkClient struct {
+	conn *netlink.Conn
+}
+
+func newNetlinkClient() (*netlinkClient, error) {
+	conn, err := netlink.Dial(familyNetfilter, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to netfilter failed: %w", err)
+	}
+	return &netlinkClient{conn: conn}, nil
+}
+
+func (c *netlinkClient) rules() ([]rule, error) {
+	request := netlink.Message{
+		Header: netlink.Header{
+			Type:  netlink.HeaderType(subsysNFTables<<8 | msgGetRule),
+			Flags: netlink.Request | netlink.Dump,
+		},
+		// nfgenmsg header requesting all address families
+		Data: []byte{0, 0, 0, 0},
+	}
+
+	msgs, err := c.conn.Execute(request)
+	if err != nil {
+		return nil, fmt.Errorf("dumping rules failed: %w", err)
+	}
+
+	rules := make([]rule, 0, len(msgs))
+	for _, msg := range msgs {
+		r, err := parseRule(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (c *netlinkClient) close() error {
+	return c.conn.Close()
+}
+
+// parseRule decodes a rule message consisting of the nfgenmsg header
+// followed by the rule attributes
+func parseRule(data []byte) (rule, error) {
+	if len(data) < 4 {
+		return rule{}, fmt.Errorf("rule message too short (%d bytes)", len(data))
+	}
+
+	r := rule{family: families[data[0]]}
+	if r.family == "" {
+		r.family = fmt.Sprintf("unknown(%d)", data[0])
+	}
+
+	ad, err := netlink.NewAttributeDecoder(data[4:])
+	if err != nil {
+		return rule{}, fmt.Errorf("decoding rule failed: %w", err)
+	}
+	ad.ByteOrder = binary.BigEndian
+	for ad.Next() {
+		switch ad.Type() {
+		case attrRuleTable:
+			r.table = ad.String()
+		case attrRuleChain:
+			r.chain = ad.String()
+		case attrRuleHandle:
+			r.handle = ad.Uint64()
+		case attrRuleExpressions:
+			ad.Nested(r.parseExpressions)
+		case attrRuleUserdata:
+			r.comment = parseComment(ad.Bytes())
+		}
+	}
+	if err := ad.Err(); err != nil {
+		return rule{}, fmt.Errorf("decoding rule failed: %w", err)
+	}
+	return r, nil
+}
+
+// parseExpressions sums up the counter expressions of the rule
+func (r *rule) parseExpressions(ad *netlink.AttributeDecoder) error {
+	for ad.Next() {
+		if ad.Type() != attrListElem {
+			continue
+		}
+		ad.Nested(func(ead *netlink.AttributeDecoder) error {
+			var name string
+			var data []byte
+			for ead.Next() {
+				switch ead.Type() {
+				case attrExprName:
+					name = ead.String()
+				case attrExprData:
+					data = ead.Bytes()
+				}
+			}
+			if name != "counter" || data == nil {
+				return nil
+			}
+
+			cad, err := netlink.NewAttributeDecoder(data)
+			if err != nil {
+				return err
+			}
+			cad.ByteOrder = binary.BigEndian
+			for cad.Next() {
+				switch cad.Type() {
+				case attrCounterBytes:
+					r.bytes += cad.Uint64()
+				case attrCounterPackets:
+					r.packets += cad.Uint64()
+				}
+			}
+			r.hasCounter = true
+			return cad.Err()
+		})
+	}
+	return nil
+}
+
+// parseComment extracts the comment from the type-length-value encoded
+// user data of the rule as written by the nft tool
+func parseComment(data []byte) string {
+	for len(data) >= 2 {
+		typ, length := data[0], int(data[1])
+		if len(data) < 2+length {
+			break
+		}
+		if typ == udataRuleComment {
+			return string(bytes.TrimRight(data[2:2+length], "\x00"))
+		}
+		data = data[2+length:]
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package nftables
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Nftables struct {
+	Tables             []string        `toml:"tables"`
+	IncludeUncommented bool            `toml:"include_uncommented"`
+	Log                telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	client client
+}
+
+func (*Nftables) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *Nftables) Init() error {
+	if len(n.Tables) == 0 {
+		n.Tables = []string{"*"}
+	}
+
+	f, err := filter.Compile(n.Tables)
+	if err != nil {
+		return fmt.Errorf("creating table filter failed: %w", err)
+	}
+	n.filter = f
+
+	return nil
+}
+
+func (n *Nftables) Start(telegraf.Accumulator) error {
+	if n.client != nil {
+		return nil
+	}
+
+	c, err := newNetlinkClient()
+	if err != nil {
+		return err
+	}
+	n.client = c
+
+	return nil
+}
+
+func (n *Nftables) Gather(acc telegraf.Accumulator) error {
+	rules, err := n.client.rules()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		if !r.hasCounter || !n.filter.Match(r.table) {
+			continue
+		}
+
+		id := r.comment
+		if id == "" {
+			if !n.IncludeUncommented {
+				continue
+			}
+			id = strconv.FormatUint(r.handle, 10)
+		}
+
+		tags := map[string]string{
+			"family": r.family,
+			"table":  r.table,
+			"chain":  r.chain,
+			"rule":   id,
+		}
+		fields := map[string]interface{}{
+			"packets": r.packets,
+			"bytes":   r.bytes,
+		}
+		acc.AddCounter("nftables", fields, tags)
+	}
+
+	return nil
+}
+
+func (n *Nftables) Stop() {
+	if n.client == nil {
+		return
+	}
+	if err := n.client.close(); err != nil {
+		n.Log.Errorf("Closing connection failed: %v", err)
+	}
+	n.client = nil
+}
+
+func init() {
+	inputs.Add("nftables", func() telegraf.Input {
+		return &Nftables{}
+	})
+}
@@ -0,0 +1,179 @@
+//go:build linux
+
+package nftables
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/netlink"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockClient struct {
+	data []rule
+}
+
+func (m *mockClient) rules() ([]rule, error) {
+	return m.data, nil
+}
+
+func (*mockClient) close() error {
+	return nil
+}
+
+func TestGather(t *testing.T) {
+	rules := []rule{
+		{
+			family:     "inet",
+			table:      "filter",
+			chain:      "input",
+			handle:     4,
+			comment:    "ssh",
+			hasCounter: true,
+			packets:    10,
+			bytes:      1500,
+		},
+		{
+			family:     "inet",
+			table:      "filter",
+			chain:      "input",
+			handle:     5,
+			hasCounter: true,
+			packets:    3,
+			bytes:      120,
+		},
+		{
+			family:  "inet",
+			table:   "filter",
+			chain:   "input",
+			handle:  6,
+			comment: "no counter",
+		},
+		{
+			family:     "ip",
+			table:      "nat",
+			chain:      "postrouting",
+			handle:     2,
+			comment:    "masquerade",
+			hasCounter: true,
+			packets:    42,
+			bytes:      4200,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *Nftables
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "default",
+			plugin: &Nftables{},
+			expected: []telegraf.Metric{
+				metric.New(
+					"nftables",
+					map[string]string{"family": "inet", "table": "filter", "chain": "input", "rule": "ssh"},
+					map[string]interface{}{"packets": uint64(10), "bytes": uint64(1500)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"nftables",
+					map[string]string{"family": "ip", "table": "nat", "chain": "postrouting", "rule": "masquerade"},
+					map[string]interface{}{"packets": uint64(42), "bytes": uint64(4200)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+		{
+			name:   "uncommented in filter table",
+			plugin: &Nftables{Tables: []string{"filter"}, IncludeUncommented: true},
+			expected: []telegraf.Metric{
+				metric.New(
+					"nftables",
+					map[string]string{"family": "inet", "table": "filter", "chain": "input", "rule": "ssh"},
+					map[string]interface{}{"packets": uint64(10), "bytes": uint64(1500)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+				metric.New(
+					"nftables",
+					map[string]string{"family": "inet", "table": "filter", "chain": "input", "rule": "5"},
+					map[string]interface{}{"packets": uint64(3), "bytes": uint64(120)},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.Log = testutil.Logger{}
+			plugin.client = &mockClient{data: rules}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+			require.NoError(t, plugin.Gather(&acc))
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	ae := netlink.NewAttributeEncoder()
+	ae.ByteOrder = binary.BigEndian
+	ae.String(attrRuleTable, "filter")
+	ae.String(attrRuleChain, "input")
+	ae.Uint64(attrRuleHandle, 4)
+	ae.Nested(attrRuleExpressions, func(nae *netlink.AttributeEncoder) error {
+		nae.Nested(attrListElem, func(eae *netlink.AttributeEncoder) error {
+			eae.String(attrExprName, "meta")
+			return nil
+		})
+		nae.Nested(attrListElem, func(eae *netlink.AttributeEncoder) error {
+			eae.String(attrExprName, "counter")
+			eae.Nested(attrExprData, func(cae *netlink.AttributeEncoder) error {
+				cae.Uint64(attrCounterBytes, 1500)
+				cae.Uint64(attrCounterPackets, 10)
+				return nil
+			})
+			return nil
+		})
+		return nil
+	})
+	ae.Bytes(attrRuleUserdata, append([]byte{udataRuleComment, 4}, "ssh\x00"...))
+	attrs, err := ae.Encode()
+	require.NoError(t, err)
+
+	// Prepend the nfgenmsg header for the inet family
+	data := append([]byte{1, 0, 0, 0}, attrs...)
+
+	r, err := parseRule(data)
+	require.NoError(t, err)
+	expected := rule{
+		family:     "inet",
+		table:      "filter",
+		chain:      "input",
+		handle:     4,
+		comment:    "ssh",
+		hasCounter: true,
+		packets:    10,
+		bytes:      1500,
+	}
+	require.Equal(t, expected, r)
+
+	_, err = parseRule([]byte{1, 0})
+	require.ErrorContains(t, err, "too short")
+}
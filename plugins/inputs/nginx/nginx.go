@@ -3,6 +3,7 @@ package nginx
 
 import (
 	"bufio"
+	"context"
 	_ "embed"
 	"fmt"
 	"net"
@@ -10,11 +11,11 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/fanout"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -26,6 +27,7 @@ type Nginx struct {
 	Urls            []string
 	ResponseTimeout config.Duration
 	tls.ClientConfig
+	fanout.Config
 
 	// HTTP client
 	client *http.Client
@@ -36,8 +38,6 @@ func (*Nginx) SampleConfig() string {
 }
 
 func (n *Nginx) Gather(acc telegraf.Accumulator) error {
-	var wg sync.WaitGroup
-
 	// Create an HTTP client that is re-used for each
 	// collection interval
 	if n.client == nil {
@@ -48,21 +48,17 @@ func (n *Nginx) Gather(acc telegraf.Accumulator) error {
 		n.client = client
 	}
 
+	addrs := make([]*url.URL, 0, len(n.Urls))
 	for _, u := range n.Urls {
 		addr, err := url.Parse(u)
 		if err != nil {
 			acc.AddError(fmt.Errorf("unable to parse address %q: %w", u, err))
 			continue
 		}
-
-		wg.Add(1)
-		go func(addr *url.URL) {
-			defer wg.Done()
-			acc.AddError(n.gatherURL(addr, acc))
-		}(addr)
+		addrs = append(addrs, addr)
 	}
 
-	wg.Wait()
+	fanout.Gather(n.Config, acc, addrs, (*url.URL).String, n.gatherURL)
 	return nil
 }
 
@@ -86,8 +82,12 @@ func (n *Nginx) createHTTPClient() (*http.Client, error) {
 	return client, nil
 }
 
-func (n *Nginx) gatherURL(addr *url.URL, acc telegraf.Accumulator) error {
-	resp, err := n.client.Get(addr.String())
+func (n *Nginx) gatherURL(ctx context.Context, acc telegraf.Accumulator, addr *url.URL) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error making HTTP request to %q: %w", addr.String(), err)
 	}
@@ -4,13 +4,19 @@ package nomad
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -22,6 +28,10 @@ var sampleConfig string
 type Nomad struct {
 	URL string `toml:"url"`
 
+	TokenFile string   `toml:"token_file"`
+	Token     string   `toml:"token"`
+	Collect   []string `toml:"collect"`
+
 	ResponseTimeout config.Duration `toml:"response_timeout"`
 
 	tls.ClientConfig
@@ -48,6 +58,25 @@ func (n *Nomad) Init() error {
 		n.URL = "http://127.0.0.1:4646"
 	}
 
+	if n.TokenFile != "" && n.Token != "" {
+		return errors.New("config error: both token_file and token are set")
+	}
+
+	if n.TokenFile != "" {
+		token, err := os.ReadFile(n.TokenFile)
+		if err != nil {
+			return fmt.Errorf("reading file failed: %w", err)
+		}
+		n.Token = strings.TrimSpace(string(token))
+	}
+
+	if len(n.Collect) == 0 {
+		n.Collect = []string{"metrics"}
+	}
+	if err := choice.CheckSlice(n.Collect, []string{"metrics", "allocations", "evaluations"}); err != nil {
+		return fmt.Errorf(`cannot verify "collect" setting: %w`, err)
+	}
+
 	tlsCfg, err := n.ClientConfig.TLSConfig()
 	if err != nil {
 		return fmt.Errorf("setting up TLS configuration failed: %w", err)
@@ -63,34 +92,128 @@ func (n *Nomad) Init() error {
 }
 
 func (n *Nomad) Gather(acc telegraf.Accumulator) error {
-	summaryMetrics := &metricsSummary{}
-	err := n.loadJSON(n.URL+"/v1/metrics", summaryMetrics)
-	if err != nil {
+	if choice.Contains("metrics", n.Collect) {
+		summaryMetrics := &metricsSummary{}
+		if err := n.loadJSON(n.URL+"/v1/metrics", summaryMetrics); err != nil {
+			acc.AddError(err)
+		} else if err := buildNomadMetrics(acc, summaryMetrics); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if choice.Contains("allocations", n.Collect) {
+		if err := n.gatherAllocations(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if choice.Contains("evaluations", n.Collect) {
+		if err := n.gatherEvaluations(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+// gatherAllocations collects the resource usage of the tasks of all
+// allocations running on the client node of the agent
+func (n *Nomad) gatherAllocations(acc telegraf.Accumulator) error {
+	var self agentSelf
+	if err := n.loadJSON(n.URL+"/v1/agent/self", &self); err != nil {
 		return err
 	}
+	nodeID := self.Stats.Client.NodeID
+	if nodeID == "" {
+		return errors.New("agent is not running in client mode")
+	}
 
-	err = buildNomadMetrics(acc, summaryMetrics)
-	if err != nil {
+	var allocations []allocation
+	if err := n.loadJSON(n.URL+"/v1/node/"+url.PathEscape(nodeID)+"/allocations", &allocations); err != nil {
 		return err
 	}
 
+	for _, alloc := range allocations {
+		if alloc.ClientStatus != "running" {
+			continue
+		}
+
+		var stats allocationStats
+		if err := n.loadJSON(n.URL+"/v1/client/allocation/"+url.PathEscape(alloc.ID)+"/stats", &stats); err != nil {
+			// The allocation might have stopped in the meantime
+			acc.AddError(err)
+			continue
+		}
+		buildAllocationMetrics(acc, nodeID, &alloc, &stats)
+	}
+
 	return nil
 }
 
-func (n *Nomad) loadJSON(url string, v interface{}) error {
-	req, err := http.NewRequest("GET", url, nil)
+// gatherEvaluations counts the evaluations waiting to be processed by the
+// schedulers across all namespaces
+func (n *Nomad) gatherEvaluations(acc telegraf.Accumulator) error {
+	query := url.Values{}
+	query.Set("namespace", "*")
+	query.Set("filter", `Status == "pending" or Status == "blocked"`)
+
+	var evaluations []evaluation
+	if err := n.loadJSON(n.URL+"/v1/evaluations?"+query.Encode(), &evaluations); err != nil {
+		return err
+	}
+
+	type queueKey struct {
+		namespace, jobType, status string
+	}
+	counts := make(map[queueKey]int)
+	for _, eval := range evaluations {
+		counts[queueKey{eval.Namespace, eval.Type, eval.Status}]++
+	}
+
+	keys := make([]queueKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		if keys[i].jobType != keys[j].jobType {
+			return keys[i].jobType < keys[j].jobType
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	for _, k := range keys {
+		tags := map[string]string{
+			"namespace": k.namespace,
+			"type":      k.jobType,
+			"status":    k.status,
+		}
+		acc.AddGauge("nomad_evaluations", map[string]interface{}{"count": counts[k]}, tags)
+	}
+
+	return nil
+}
+
+func (n *Nomad) loadJSON(address string, v interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
 	if err != nil {
 		return err
 	}
 
+	if n.Token != "" {
+		req.Header.Set("X-Nomad-Token", n.Token)
+	}
+
 	resp, err := n.roundTripper.RoundTrip(req)
 	if err != nil {
-		return fmt.Errorf("error making HTTP request to %q: %w", url, err)
+		return fmt.Errorf("error making HTTP request to %q: %w", address, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s returned HTTP status %s", url, resp.Status)
+		return fmt.Errorf("%s returned HTTP status %s", address, resp.Status)
 	}
 
 	err = json.NewDecoder(resp.Body).Decode(v)
@@ -161,3 +284,46 @@ func buildNomadMetrics(acc telegraf.Accumulator, summaryMetrics *metricsSummary)
 
 	return nil
 }
+
+// buildAllocationMetrics adds the resource usage of every task of the allocation
+func buildAllocationMetrics(acc telegraf.Accumulator, nodeID string, alloc *allocation, stats *allocationStats) {
+	tasks := make([]string, 0, len(stats.Tasks))
+	for name := range stats.Tasks {
+		tasks = append(tasks, name)
+	}
+	sort.Strings(tasks)
+
+	for _, name := range tasks {
+		task := stats.Tasks[name]
+		tags := map[string]string{
+			"node_id":    nodeID,
+			"alloc_id":   alloc.ID,
+			"alloc_name": alloc.Name,
+			"namespace":  alloc.Namespace,
+			"job":        alloc.JobID,
+			"task_group": alloc.TaskGroup,
+			"task":       name,
+		}
+
+		usage := task.ResourceUsage
+		fields := map[string]interface{}{
+			"cpu_percent":           usage.CPUStats.Percent,
+			"cpu_system_mode":       usage.CPUStats.SystemMode,
+			"cpu_user_mode":         usage.CPUStats.UserMode,
+			"cpu_total_ticks":       usage.CPUStats.TotalTicks,
+			"cpu_throttled_periods": usage.CPUStats.ThrottledPeriods,
+			"cpu_throttled_time":    usage.CPUStats.ThrottledTime,
+			"memory_rss":            usage.MemoryStats.RSS,
+			"memory_cache":          usage.MemoryStats.Cache,
+			"memory_swap":           usage.MemoryStats.Swap,
+			"memory_usage":          usage.MemoryStats.Usage,
+			"memory_max_usage":      usage.MemoryStats.MaxUsage,
+		}
+
+		t := time.Unix(0, task.Timestamp)
+		if task.Timestamp == 0 {
+			t = time.Unix(0, stats.Timestamp)
+		}
+		acc.AddGauge("nomad_allocation", fields, tags, t)
+	}
+}
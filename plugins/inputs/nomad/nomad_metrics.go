@@ -51,3 +51,54 @@ type label struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
 }
+
+type agentSelf struct {
+	Stats struct {
+		Client struct {
+			NodeID string `json:"node_id"`
+		} `json:"client"`
+	} `json:"stats"`
+}
+
+type allocation struct {
+	ID           string `json:"ID"`
+	Name         string `json:"Name"`
+	Namespace    string `json:"Namespace"`
+	JobID        string `json:"JobID"`
+	TaskGroup    string `json:"TaskGroup"`
+	ClientStatus string `json:"ClientStatus"`
+}
+
+type allocationStats struct {
+	Tasks     map[string]taskStats `json:"Tasks"`
+	Timestamp int64                `json:"Timestamp"`
+}
+
+type taskStats struct {
+	ResourceUsage resourceUsage `json:"ResourceUsage"`
+	Timestamp     int64         `json:"Timestamp"`
+}
+
+type resourceUsage struct {
+	MemoryStats struct {
+		RSS      uint64 `json:"RSS"`
+		Cache    uint64 `json:"Cache"`
+		Swap     uint64 `json:"Swap"`
+		Usage    uint64 `json:"Usage"`
+		MaxUsage uint64 `json:"MaxUsage"`
+	} `json:"MemoryStats"`
+	CPUStats struct {
+		SystemMode       float64 `json:"SystemMode"`
+		UserMode         float64 `json:"UserMode"`
+		TotalTicks       float64 `json:"TotalTicks"`
+		ThrottledPeriods uint64  `json:"ThrottledPeriods"`
+		ThrottledTime    uint64  `json:"ThrottledTime"`
+		Percent          float64 `json:"Percent"`
+	} `json:"CpuStats"`
+}
+
+type evaluation struct {
+	Namespace string `json:"Namespace"`
+	Type      string `json:"Type"`
+	Status    string `json:"Status"`
+}
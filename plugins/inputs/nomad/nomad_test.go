@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -111,3 +112,114 @@ func TestNomadStats(t *testing.T) {
 		})
 	}
 }
+
+func TestNomadAllocationsAndEvaluations(t *testing.T) {
+	endpoints := map[string]string{
+		"/v1/agent/self": "testdata/response_agent_self.json",
+		"/v1/node/2bbff078-8473-a9de-6c5e-42b4e053e12f/allocations":        "testdata/response_node_allocations.json",
+		"/v1/client/allocation/a8198d79-cfdb-6593-a999-1e9adabcba2e/stats": "testdata/response_allocation_stats.json",
+		"/v1/evaluations": "testdata/response_evaluations.json",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "4a8d3b79-4d2c-0c5e-2e42-bd0a9c3f8e21" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path == "/v1/evaluations" && r.URL.Query().Get("namespace") != "*" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fn, found := endpoints[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		response, err := os.ReadFile(fn)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nomad_allocation",
+			map[string]string{
+				"node_id":    "2bbff078-8473-a9de-6c5e-42b4e053e12f",
+				"alloc_id":   "a8198d79-cfdb-6593-a999-1e9adabcba2e",
+				"alloc_name": "example.cache[0]",
+				"namespace":  "default",
+				"job":        "example",
+				"task_group": "cache",
+				"task":       "redis",
+			},
+			map[string]interface{}{
+				"cpu_percent":           float64(1.25),
+				"cpu_system_mode":       float64(0.3),
+				"cpu_user_mode":         float64(0.9),
+				"cpu_total_ticks":       float64(38.2),
+				"cpu_throttled_periods": uint64(0),
+				"cpu_throttled_time":    uint64(0),
+				"memory_rss":            uint64(6529024),
+				"memory_cache":          uint64(0),
+				"memory_swap":           uint64(0),
+				"memory_usage":          uint64(7036928),
+				"memory_max_usage":      uint64(9867264),
+			},
+			time.Unix(1636843140, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"nomad_evaluations",
+			map[string]string{
+				"namespace": "analytics",
+				"type":      "batch",
+				"status":    "blocked",
+			},
+			map[string]interface{}{"count": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"nomad_evaluations",
+			map[string]string{
+				"namespace": "default",
+				"type":      "service",
+				"status":    "pending",
+			},
+			map[string]interface{}{"count": 2},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	plugin := &Nomad{
+		URL:     ts.URL,
+		Token:   "4a8d3b79-4d2c-0c5e-2e42-bd0a9c3f8e21",
+		Collect: []string{"allocations", "evaluations"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	// Only the allocation statistics carry a timestamp
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, len(expected))
+	testutil.RequireMetricsEqual(t, expected[:1], actual[:1])
+	testutil.RequireMetricsEqual(t, expected[1:], actual[1:], testutil.IgnoreTime())
+}
+
+func TestInitBothTokens(t *testing.T) {
+	plugin := &Nomad{
+		Token:     "4a8d3b79-4d2c-0c5e-2e42-bd0a9c3f8e21",
+		TokenFile: "testdata/token",
+	}
+	require.ErrorContains(t, plugin.Init(), "both token_file and token are set")
+}
@@ -0,0 +1,244 @@
+package ntpq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebook/time/ntp/control"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Characters used by ntpq to mark the peer selection state, indexed by the
+// selection field of the peer status word
+const selectionPrefix = " x.-+#*o"
+
+// queryNative reads the peer variables of all associations from the given
+// server using NTP mode 6 control messages (RFC 1305, Appendix B) as done by
+// 'ntpq -p' without requiring the ntpq executable.
+func (n *NTPQ) queryNative(server string) ([]peer, error) {
+	addr := server
+	if addr == "" {
+		addr = "localhost"
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "123")
+	}
+
+	timeout := time.Duration(n.Timeout)
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	client := &control.NTPClient{Connection: conn}
+
+	// Get the list of associations
+	msg, err := client.Communicate(&control.NTPControlMsgHead{
+		VnMode: control.MakeVnMode(2, control.Mode),
+		REMOp:  control.MakeREMOp(false, false, false, control.OpReadStatus),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading associations failed: %w", err)
+	}
+	if msg.HasError() {
+		return nil, errors.New("reading associations failed: server returned an error")
+	}
+
+	// The library only evaluates the count of the last packet so decode the
+	// combined data ourselves to handle large association lists
+	peers := make([]peer, 0, len(msg.Data)/4)
+	for i := 0; i+4 <= len(msg.Data); i += 4 {
+		id := binary.BigEndian.Uint16(msg.Data[i : i+2])
+		status := control.ReadPeerStatusWord(binary.BigEndian.Uint16(msg.Data[i+2 : i+4]))
+
+		msg, err := client.Communicate(&control.NTPControlMsgHead{
+			VnMode:        control.MakeVnMode(2, control.Mode),
+			REMOp:         control.MakeREMOp(false, false, false, control.OpReadVariables),
+			AssociationID: id,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading variables of association %d failed: %w", id, err)
+		}
+		if msg.HasError() {
+			return nil, fmt.Errorf("reading variables of association %d failed: server returned an error", id)
+		}
+		variables, err := msg.GetAssociationInfo()
+		if err != nil {
+			return nil, fmt.Errorf("decoding variables of association %d failed: %w", id, err)
+		}
+		peers = append(peers, peer{selection: status.PeerSelection, variables: variables})
+	}
+
+	return peers, nil
+}
+
+type peer struct {
+	selection uint8
+	variables map[string]string
+}
+
+func (n *NTPQ) gatherServerNative(acc telegraf.Accumulator, server string) {
+	var msgPrefix string
+	if server != "" {
+		msgPrefix = fmt.Sprintf("[%s] ", server)
+	}
+	peers, err := n.queryNative(server)
+	if err != nil {
+		acc.AddError(fmt.Errorf("%s%w", msgPrefix, err))
+		return
+	}
+
+	now := time.Now()
+	for _, p := range peers {
+		tags := make(map[string]string)
+		fields := make(map[string]interface{})
+
+		if prefix := selectionPrefix[p.selection&0x07]; prefix != ' ' {
+			tags["state_prefix"] = string(prefix)
+		}
+		if server != "" {
+			tags["source"] = server
+		}
+
+		srcadr := p.variables["srcadr"]
+		tags["remote"] = srcadr
+		if v, found := p.variables["refid"]; found {
+			tags["refid"] = v
+		}
+		if v, found := p.variables["stratum"]; found {
+			tags["stratum"] = v
+		}
+		if v := peerType(srcadr, p.variables["hmode"]); v != "" {
+			tags["type"] = v
+		}
+
+		for _, name := range []string{"delay", "jitter", "offset"} {
+			raw, found := p.variables[name]
+			if !found {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				msg := fmt.Sprintf("%sparsing %q (%v) as float failed", msgPrefix, name, raw)
+				acc.AddError(fmt.Errorf("%s: %w", msg, err))
+				continue
+			}
+			fields[name] = value
+		}
+
+		if raw, found := p.variables["reach"]; found {
+			value, err := strconv.ParseUint(raw, 0, 64)
+			if err != nil {
+				acc.AddError(fmt.Errorf("%sparsing \"reach\" (%v) as int failed: %w", msgPrefix, raw, err))
+			} else {
+				fields["reach"] = n.formatReach(value)
+			}
+		}
+
+		if poll, found := pollInterval(p.variables); found {
+			fields["poll"] = poll
+		}
+
+		if raw, found := p.variables["rec"]; found {
+			rec, err := parseNTPTimestamp(raw)
+			if err != nil {
+				acc.AddError(fmt.Errorf("%sparsing \"rec\" (%v) as timestamp failed: %w", msgPrefix, raw, err))
+			} else if !rec.IsZero() {
+				fields["when"] = int64(now.Sub(rec).Seconds())
+			}
+		}
+
+		acc.AddFields("ntpq", fields, tags)
+	}
+}
+
+// formatReach converts the reach register to the configured output format
+// matching the values reported in ntpq mode.
+func (n *NTPQ) formatReach(value uint64) interface{} {
+	switch n.ReachFormat {
+	case "decimal":
+		return int64(value)
+	case "count":
+		return bits.OnesCount64(value)
+	case "ratio":
+		return float64(bits.OnesCount64(value)) / float64(8)
+	}
+
+	// Octal representation interpreted as decimal number as shown by ntpq
+	v, _ := strconv.ParseInt(strconv.FormatUint(value, 8), 10, 64)
+	return v
+}
+
+// peerType returns the type character shown by ntpq for the given peer
+func peerType(srcadr, hmode string) string {
+	if strings.HasPrefix(srcadr, "127.127.") {
+		return "l"
+	}
+
+	switch hmode {
+	case "1", "2":
+		return "s"
+	case "3":
+		return "u"
+	case "5":
+		if ip := net.ParseIP(srcadr); ip != nil && ip.IsMulticast() {
+			return "M"
+		}
+		return "B"
+	case "6":
+		return "b"
+	}
+	return ""
+}
+
+// pollInterval returns the effective poll interval in seconds being the
+// minimum of the host and peer poll exponents
+func pollInterval(variables map[string]string) (int64, bool) {
+	exponents := make([]int64, 0, 2)
+	for _, name := range []string{"hpoll", "ppoll"} {
+		if raw, found := variables[name]; found {
+			if v, err := strconv.ParseInt(raw, 10, 64); err == nil && v >= 0 && v < 32 {
+				exponents = append(exponents, v)
+			}
+		}
+	}
+	if len(exponents) == 0 {
+		return 0, false
+	}
+	return int64(1) << slices.Min(exponents), true
+}
+
+// parseNTPTimestamp decodes a hexadecimal NTP timestamp of the form
+// "0xseconds.fraction". A zero timestamp results in a zero time.
+func parseNTPTimestamp(raw string) (time.Time, error) {
+	secRaw, fracRaw, _ := strings.Cut(strings.TrimPrefix(raw, "0x"), ".")
+	seconds, err := strconv.ParseUint(secRaw, 16, 32)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var fraction uint64
+	if fracRaw != "" {
+		if fraction, err = strconv.ParseUint(fracRaw, 16, 32); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if seconds == 0 && fraction == 0 {
+		return time.Time{}, nil
+	}
+
+	// Seconds between the NTP epoch (1900) and the Unix epoch (1970)
+	const ntpEpochOffset = 2208988800
+	return time.Unix(int64(seconds)-ntpEpochOffset, int64((fraction*1e9)>>32)), nil
+}
@@ -12,10 +12,12 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kballard/go-shellquote"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -64,10 +66,12 @@ var fieldElements = map[string]elementType{
 }
 
 type NTPQ struct {
-	DNSLookup   bool     `toml:"dns_lookup" deprecated:"1.24.0;1.35.0;add '-n' to 'options' instead to skip DNS lookup"`
-	Options     string   `toml:"options"`
-	Servers     []string `toml:"servers"`
-	ReachFormat string   `toml:"reach_format"`
+	DNSLookup   bool            `toml:"dns_lookup" deprecated:"1.24.0;1.35.0;add '-n' to 'options' instead to skip DNS lookup"`
+	Options     string          `toml:"options"`
+	Servers     []string        `toml:"servers"`
+	ReachFormat string          `toml:"reach_format"`
+	Method      string          `toml:"method"`
+	Timeout     config.Duration `toml:"timeout"`
 
 	runQ func(string) ([]byte, error)
 }
@@ -81,6 +85,17 @@ func (n *NTPQ) Init() error {
 		n.Servers = []string{""}
 	}
 
+	switch n.Method {
+	case "", "exec":
+		n.Method = "exec"
+	case "native":
+		if n.Timeout <= 0 {
+			n.Timeout = config.Duration(5 * time.Second)
+		}
+	default:
+		return fmt.Errorf("unknown 'method' %q", n.Method)
+	}
+
 	if n.runQ == nil {
 		options, err := shellquote.Split(n.Options)
 		if err != nil {
@@ -139,6 +154,10 @@ func (n *NTPQ) Init() error {
 
 func (n *NTPQ) Gather(acc telegraf.Accumulator) error {
 	for _, server := range n.Servers {
+		if n.Method == "native" {
+			n.gatherServerNative(acc, server)
+			continue
+		}
 		n.gatherServer(acc, server)
 	}
 	return nil
@@ -1,18 +1,24 @@
 package ntpq
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/facebook/time/ntp/control"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -29,6 +35,11 @@ func TestInitInvalid(t *testing.T) {
 			plugin:   &NTPQ{ReachFormat: "garbage"},
 			expected: `unknown 'reach_format' "garbage"`,
 		},
+		{
+			name:     "invalid method",
+			plugin:   &NTPQ{Method: "garbage"},
+			expected: `unknown 'method' "garbage"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -119,6 +130,111 @@ func TestCases(t *testing.T) {
 	}
 }
 
+func TestNative(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ts := time.Now().Add(-42 * time.Second)
+	rec := fmt.Sprintf("0x%08x.%08x", ts.Unix()+2208988800, (int64(ts.Nanosecond())<<32)/1e9)
+	peers := map[uint16]string{
+		1: "srcadr=192.168.1.10, refid=GPS, stratum=1, hmode=3, hpoll=6, ppoll=6, reach=0xff, " +
+			"delay=0.512, offset=-0.031, jitter=0.012, rec=" + rec,
+		2: "srcadr=192.168.1.11, refid=192.168.1.10, stratum=2, hmode=3, hpoll=10, ppoll=7, reach=0x1f, " +
+			"delay=1.215, offset=0.442, jitter=0.101, rec=0x00000000.00000000",
+	}
+
+	// Fake NTP server answering the association list and peer variables
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var request control.NTPControlMsgHead
+			if err := binary.Read(bytes.NewReader(buf[:n]), binary.BigEndian, &request); err != nil {
+				return
+			}
+
+			var data []byte
+			switch request.GetOperation() {
+			case control.OpReadStatus:
+				// Association 1 is the system peer (6), association 2 a candidate (4)
+				data = []byte{0x00, 0x01, 0x96, 0x14, 0x00, 0x02, 0x94, 0x14}
+			case control.OpReadVariables:
+				data = []byte(peers[request.AssociationID])
+			}
+
+			response := request
+			response.REMOp = control.MakeREMOp(true, false, false, int(request.GetOperation()))
+			response.Count = uint16(len(data))
+			var out bytes.Buffer
+			if err := binary.Write(&out, binary.BigEndian, response); err != nil {
+				return
+			}
+			out.Write(data)
+			if _, err := conn.WriteTo(out.Bytes(), addr); err != nil {
+				return
+			}
+		}
+	}()
+
+	server := conn.LocalAddr().String()
+	plugin := &NTPQ{
+		Servers: []string{server},
+		Method:  "native",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ntpq",
+			map[string]string{
+				"remote":       "192.168.1.10",
+				"refid":        "GPS",
+				"stratum":      "1",
+				"type":         "u",
+				"state_prefix": "*",
+				"source":       server,
+			},
+			map[string]interface{}{
+				"delay":  0.512,
+				"offset": -0.031,
+				"jitter": 0.012,
+				"reach":  int64(377),
+				"poll":   int64(64),
+				"when":   int64(42),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ntpq",
+			map[string]string{
+				"remote":       "192.168.1.11",
+				"refid":        "192.168.1.10",
+				"stratum":      "2",
+				"type":         "u",
+				"state_prefix": "+",
+				"source":       server,
+			},
+			map[string]interface{}{
+				"delay":  1.215,
+				"offset": 0.442,
+				"jitter": 0.101,
+				"reach":  int64(37),
+				"poll":   int64(128),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func readInputData(path string) (map[string][]byte, map[string]error, error) {
 	// Get all elements in the testcase directory
 	entries, err := os.ReadDir(path)
@@ -9,6 +9,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+
+	"github.com/influxdata/telegraf/plugins/common/otlp"
 )
 
 type traceService struct {
@@ -40,6 +42,8 @@ func (s *traceService) Export(ctx context.Context, req ptraceotlp.ExportRequest)
 type metricsService struct {
 	pmetricotlp.UnimplementedGRPCServer
 	exporter *otel2influx.OtelMetricsToLineProtocol
+	writer   *writeToAccumulator
+	native   bool
 }
 
 var _ pmetricotlp.GRPCServer = (*metricsService)(nil)
@@ -49,7 +53,7 @@ var metricsSchemata = map[string]common.MetricsSchema{
 	"prometheus-v2": common.MetricsSchemaTelegrafPrometheusV2,
 }
 
-func newMetricsService(logger common.Logger, writer *writeToAccumulator, schema string) (*metricsService, error) {
+func newMetricsService(logger common.Logger, writer *writeToAccumulator, schema string, native bool) (*metricsService, error) {
 	ms, found := metricsSchemata[schema]
 	if !found {
 		return nil, fmt.Errorf("schema %q not recognized", schema)
@@ -65,12 +69,30 @@ func newMetricsService(logger common.Logger, writer *writeToAccumulator, schema
 	}
 	return &metricsService{
 		exporter: exp,
+		writer:   writer,
+		native:   native,
 	}, nil
 }
 
 func (s *metricsService) Export(ctx context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
-	err := s.exporter.WriteMetrics(ctx, req.Metrics())
-	return pmetricotlp.NewExportResponse(), err
+	if !s.native {
+		err := s.exporter.WriteMetrics(ctx, req.Metrics())
+		return pmetricotlp.NewExportResponse(), err
+	}
+
+	// Convert each data point separately to attach its OTLP representation
+	// to the resulting metrics
+	for _, n := range otlp.Split(req.Metrics()) {
+		c := &collector{}
+		if err := s.exporter.WriteMetrics(context.WithValue(ctx, collectorKey{}, c), n.Metrics()); err != nil {
+			return pmetricotlp.NewExportResponse(), err
+		}
+		n.Attach(c.metrics)
+		for _, m := range c.metrics {
+			s.writer.accumulator.AddMetric(m)
+		}
+	}
+	return pmetricotlp.NewExportResponse(), nil
 }
 
 type logsService struct {
@@ -31,6 +31,7 @@ type OpenTelemetry struct {
 	LogRecordDimensions []string        `toml:"log_record_dimensions"`
 	ProfileDimensions   []string        `toml:"profile_dimensions"`
 	MetricsSchema       string          `toml:"metrics_schema"`
+	NativeMetrics       bool            `toml:"native_metrics"`
 	MaxMsgSize          config.Size     `toml:"max_msg_size"`
 	Timeout             config.Duration `toml:"timeout"`
 	Log                 telegraf.Logger `toml:"-"`
@@ -89,7 +90,7 @@ func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
 	}
 	ptraceotlp.RegisterGRPCServer(o.grpcServer, traceSvc)
 
-	metricsSvc, err := newMetricsService(logger, influxWriter, o.MetricsSchema)
+	metricsSvc, err := newMetricsService(logger, influxWriter, o.MetricsSchema, o.NativeMetrics)
 	if err != nil {
 		return err
 	}
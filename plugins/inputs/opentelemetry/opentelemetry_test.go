@@ -13,6 +13,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
@@ -27,6 +30,7 @@ import (
 	"github.com/influxdata/influxdb-observability/otel2influx"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	telegraf_metric "github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -110,6 +114,59 @@ func TestOpenTelemetry(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, options...)
 }
 
+func TestNativeMetrics(t *testing.T) {
+	var acc testutil.Accumulator
+	service, err := newMetricsService(&otelLogger{testutil.Logger{}}, &writeToAccumulator{&acc}, "prometheus-v1", true)
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "shop")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("checkout")
+	m := sm.Metrics().AppendEmpty()
+	m.SetName("requests")
+	m.SetUnit("1")
+	gauge := m.SetEmptyGauge()
+	for _, method := range []string{"GET", "POST"} {
+		dp := gauge.DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(1700000000, 0)))
+		dp.SetIntValue(3)
+		dp.Attributes().PutStr("method", method)
+	}
+
+	_, err = service.Export(context.Background(), pmetricotlp.NewExportRequestFromMetrics(md))
+	require.NoError(t, err)
+
+	// The metrics must be the same as without the option
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"otel.library.name": "checkout", "service.name": "shop", "method": "GET"},
+			map[string]interface{}{"gauge": int64(3)},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"otel.library.name": "checkout", "service.name": "shop", "method": "POST"},
+			map[string]interface{}{"gauge": int64(3)},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Each metric carries its own data point
+	first := telegraf_metric.GetNative(actual[0])
+	second := telegraf_metric.GetNative(actual[1])
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	require.Equal(t, "otlp", first.Format())
+	require.NotSame(t, first, second)
+}
+
 func TestCases(t *testing.T) {
 	// Get all directories in testdata
 	folders, err := os.ReadDir("testcases")
@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/influxdb-observability/otel2influx"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 )
 
 var (
@@ -20,18 +21,46 @@ type writeToAccumulator struct {
 	accumulator telegraf.Accumulator
 }
 
+// collector receives the points instead of the accumulator if passed in the
+// context of the conversion
+type collector struct {
+	metrics []telegraf.Metric
+}
+
+type collectorKey struct{}
+
 func (w *writeToAccumulator) NewBatch() otel2influx.InfluxWriterBatch {
 	return w
 }
 
 func (w *writeToAccumulator) EnqueuePoint(
-	_ context.Context,
+	ctx context.Context,
 	measurement string,
 	tags map[string]string,
 	fields map[string]interface{},
 	ts time.Time,
 	vType common.InfluxMetricValueType,
 ) error {
+	if c, ok := ctx.Value(collectorKey{}).(*collector); ok {
+		var tp telegraf.ValueType
+		switch vType {
+		case common.InfluxMetricValueTypeUntyped:
+			tp = telegraf.Untyped
+		case common.InfluxMetricValueTypeGauge:
+			tp = telegraf.Gauge
+		case common.InfluxMetricValueTypeSum:
+			tp = telegraf.Counter
+		case common.InfluxMetricValueTypeHistogram:
+			tp = telegraf.Histogram
+		case common.InfluxMetricValueTypeSummary:
+			tp = telegraf.Summary
+		default:
+			return fmt.Errorf("unrecognized InfluxMetricValueType %q", vType)
+		}
+		c.metrics = append(c.metrics, metric.New(measurement, tags, fields, ts, tp))
+		return nil
+	}
+
 	switch vType {
 	case common.InfluxMetricValueTypeUntyped:
 		w.accumulator.AddFields(measurement, fields, tags, ts)
@@ -0,0 +1,92 @@
+package oracle
+
+// The builtin queries use the GV$ views and join GV$INSTANCE to tag the
+// metrics with the instance. The "%s" placeholder is replaced by the instance
+// predicate to either restrict the query to the connected instance or to
+// collect all instances of a RAC cluster.
+const instanceQuery = `
+SELECT
+	i.instance_name,
+	i.host_name,
+	i.status,
+	i.database_status,
+	ROUND((SYSDATE - i.startup_time) * 86400) AS uptime,
+	(SELECT COUNT(*) FROM gv$session s WHERE s.inst_id = i.inst_id) AS sessions,
+	(SELECT COUNT(*) FROM gv$session s
+		WHERE s.inst_id = i.inst_id AND s.type = 'USER' AND s.status = 'ACTIVE') AS active_sessions
+FROM gv$instance i
+WHERE %s`
+
+// Time is reported in centiseconds by the view and converted to milliseconds
+const waitClassQuery = `
+SELECT
+	i.instance_name,
+	w.wait_class,
+	w.total_waits,
+	w.time_waited * 10 AS time_waited_ms,
+	w.total_waits_fg,
+	w.time_waited_fg * 10 AS time_waited_fg_ms
+FROM gv$system_wait_class w
+JOIN gv$instance i ON i.inst_id = w.inst_id
+WHERE w.wait_class <> 'Idle' AND %s`
+
+// Tablespaces are shared by all instances of a database so the query is not
+// scoped by instance
+const tablespaceQuery = `
+SELECT
+	m.tablespace_name,
+	t.contents,
+	t.status,
+	m.used_space * t.block_size AS used_bytes,
+	m.tablespace_size * t.block_size AS max_bytes,
+	m.used_percent
+FROM dba_tablespace_usage_metrics m
+JOIN dba_tablespaces t ON t.tablespace_name = m.tablespace_name`
+
+const asmDiskgroupQuery = `
+SELECT
+	i.instance_name,
+	g.name AS diskgroup,
+	g.state,
+	g.type,
+	g.total_mb,
+	g.free_mb,
+	g.usable_file_mb,
+	g.required_mirror_free_mb,
+	g.offline_disks
+FROM gv$asm_diskgroup g
+JOIN gv$instance i ON i.inst_id = g.inst_id
+WHERE %s`
+
+type builtinQuery struct {
+	measurement string
+	sql         string
+	tags        []string
+	scoped      bool
+}
+
+var builtinQueries = map[string]builtinQuery{
+	"instance": {
+		measurement: "oracle_instance",
+		sql:         instanceQuery,
+		tags:        []string{"instance_name", "host_name"},
+		scoped:      true,
+	},
+	"wait_class": {
+		measurement: "oracle_wait_class",
+		sql:         waitClassQuery,
+		tags:        []string{"instance_name", "wait_class"},
+		scoped:      true,
+	},
+	"tablespace": {
+		measurement: "oracle_tablespace",
+		sql:         tablespaceQuery,
+		tags:        []string{"tablespace_name", "contents"},
+	},
+	"asm_diskgroup": {
+		measurement: "oracle_asm_diskgroup",
+		sql:         asmDiskgroupQuery,
+		tags:        []string{"instance_name", "diskgroup", "type"},
+		scoped:      true,
+	},
+}
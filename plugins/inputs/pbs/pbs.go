@@ -0,0 +1,281 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package pbs
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Resource sizes in bytes as used by PBS, e.g. "32gb" or "16777216kb"
+var sizeRe = regexp.MustCompile(`^(\d+)([kmgtp]?)b$`)
+
+var sizeShift = map[string]uint{"": 0, "k": 10, "m": 20, "g": 30, "t": 40, "p": 50}
+
+type PBS struct {
+	QstatBinary    string          `toml:"qstat_binary"`
+	PbsnodesBinary string          `toml:"pbsnodes_binary"`
+	UseSudo        bool            `toml:"use_sudo"`
+	Collect        []string        `toml:"collect"`
+	Timeout        config.Duration `toml:"timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	collect map[string]bool
+	run     func(binary string, args ...string) ([]byte, error)
+}
+
+// report is the JSON output of qstat and pbsnodes with "-F json"
+type report struct {
+	Timestamp int64                             `json:"timestamp"`
+	Server    string                            `json:"pbs_server"`
+	Servers   map[string]map[string]interface{} `json:"Server"`
+	Queues    map[string]map[string]interface{} `json:"Queue"`
+	Nodes     map[string]map[string]interface{} `json:"nodes"`
+}
+
+func (*PBS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PBS) Init() error {
+	if p.QstatBinary == "" {
+		p.QstatBinary = "/opt/pbs/bin/qstat"
+	}
+	if p.PbsnodesBinary == "" {
+		p.PbsnodesBinary = "/opt/pbs/bin/pbsnodes"
+	}
+	if len(p.Collect) == 0 {
+		p.Collect = []string{"server", "queues", "nodes"}
+	}
+	p.collect = make(map[string]bool, len(p.Collect))
+	for _, c := range p.Collect {
+		switch c {
+		case "server", "queues", "nodes":
+			p.collect[c] = true
+		default:
+			return fmt.Errorf("invalid collection %q", c)
+		}
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = config.Duration(5 * time.Second)
+	}
+	p.run = p.execute
+
+	return nil
+}
+
+func (p *PBS) Gather(acc telegraf.Accumulator) error {
+	if p.collect["server"] {
+		if err := p.gatherServer(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering server status failed: %w", err))
+		}
+	}
+	if p.collect["queues"] {
+		if err := p.gatherQueues(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering queue status failed: %w", err))
+		}
+	}
+	if p.collect["nodes"] {
+		if err := p.gatherNodes(acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering node status failed: %w", err))
+		}
+	}
+	return nil
+}
+
+func (p *PBS) gatherServer(acc telegraf.Accumulator) error {
+	r, err := p.query(p.QstatBinary, "-B", "-f", "-F", "json")
+	if err != nil {
+		return err
+	}
+
+	for name, attrs := range r.Servers {
+		fields := make(map[string]interface{})
+		if v, ok := attrs["server_state"].(string); ok {
+			fields["state"] = v
+		}
+		addJobCounts(fields, attrs)
+		addResources(fields, "assigned_", attrs["resources_assigned"])
+
+		acc.AddFields("pbs_server", fields, map[string]string{"server": name}, r.time())
+	}
+	return nil
+}
+
+func (p *PBS) gatherQueues(acc telegraf.Accumulator) error {
+	r, err := p.query(p.QstatBinary, "-Q", "-f", "-F", "json")
+	if err != nil {
+		return err
+	}
+
+	for name, attrs := range r.Queues {
+		tags := map[string]string{
+			"server": r.Server,
+			"queue":  name,
+		}
+		if v, ok := attrs["queue_type"].(string); ok {
+			tags["queue_type"] = strings.ToLower(v)
+		}
+
+		fields := make(map[string]interface{})
+		for _, key := range []string{"enabled", "started"} {
+			if v, ok := attrs[key].(string); ok {
+				fields[key] = strings.EqualFold(v, "true")
+			}
+		}
+		addJobCounts(fields, attrs)
+		addResources(fields, "assigned_", attrs["resources_assigned"])
+
+		acc.AddFields("pbs_queue", fields, tags, r.time())
+	}
+	return nil
+}
+
+func (p *PBS) gatherNodes(acc telegraf.Accumulator) error {
+	r, err := p.query(p.PbsnodesBinary, "-a", "-F", "json")
+	if err != nil {
+		return err
+	}
+
+	for name, attrs := range r.Nodes {
+		tags := map[string]string{
+			"server": r.Server,
+			"node":   name,
+		}
+		if v, ok := attrs["queue"].(string); ok {
+			tags["queue"] = v
+		}
+
+		fields := make(map[string]interface{})
+		if v, ok := attrs["state"].(string); ok {
+			fields["state"] = v
+		}
+		jobs, _ := attrs["jobs"].([]interface{})
+		fields["jobs"] = int64(len(jobs))
+		addResources(fields, "available_", attrs["resources_available"])
+		addResources(fields, "assigned_", attrs["resources_assigned"])
+
+		acc.AddFields("pbs_node", fields, tags, r.time())
+	}
+	return nil
+}
+
+func (p *PBS) query(binary string, args ...string) (*report, error) {
+	out, err := p.run(binary, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var r report
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+	if err := decoder.Decode(&r); err != nil {
+		return nil, fmt.Errorf("decoding output failed: %w", err)
+	}
+	return &r, nil
+}
+
+func (r *report) time() time.Time {
+	if r.Timestamp <= 0 {
+		return time.Now()
+	}
+	return time.Unix(r.Timestamp, 0)
+}
+
+// addJobCounts adds the total number of jobs and the number of jobs per
+// state as found in the "state_count" attribute, e.g.
+// "Transit:0 Queued:5 Held:1 Waiting:0 Running:6 Exiting:0 Begun:0"
+func addJobCounts(fields map[string]interface{}, attrs map[string]interface{}) {
+	if v, ok := attrs["total_jobs"].(json.Number); ok {
+		if n, err := v.Int64(); err == nil {
+			fields["total_jobs"] = n
+		}
+	}
+
+	counts, ok := attrs["state_count"].(string)
+	if !ok {
+		return
+	}
+	for _, entry := range strings.Fields(counts) {
+		state, value, found := strings.Cut(entry, ":")
+		if !found {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields["jobs_"+strings.ToLower(state)] = n
+		}
+	}
+}
+
+// addResources adds the numeric resources and sizes of the given resource
+// list using the prefix; sizes are converted to bytes
+func addResources(fields map[string]interface{}, prefix string, resources interface{}) {
+	list, ok := resources.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name, raw := range list {
+		switch v := raw.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				fields[prefix+name] = n
+			} else if f, err := v.Float64(); err == nil {
+				fields[prefix+name] = f
+			}
+		case string:
+			if n, ok := parseSize(v); ok {
+				fields[prefix+name] = n
+			}
+		}
+	}
+}
+
+// parseSize converts PBS size values to bytes, values given in words are
+// ignored as their size depends on the architecture
+func parseSize(s string) (int64, bool) {
+	match := sizeRe.FindStringSubmatch(strings.ToLower(s))
+	if match == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v << sizeShift[match[2]], true
+}
+
+func (p *PBS) execute(binary string, args ...string) ([]byte, error) {
+	name := binary
+	if p.UseSudo {
+		args = append([]string{name}, args...)
+		name = "sudo"
+	}
+
+	cmd := exec.Command(name, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := internal.RunTimeout(cmd, time.Duration(p.Timeout)); err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", strings.Join(cmd.Args, " "), err)
+	}
+	return out.Bytes(), nil
+}
+
+func init() {
+	inputs.Add("pbs", func() telegraf.Input {
+		return &PBS{}
+	})
+}
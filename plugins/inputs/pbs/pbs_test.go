@@ -0,0 +1,175 @@
+package pbs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func mockRun(outputs map[string]string) func(string, ...string) ([]byte, error) {
+	return func(binary string, args ...string) ([]byte, error) {
+		fn, found := outputs[filepath.Base(binary)+" "+strings.Join(args, " ")]
+		if !found {
+			return nil, errors.New("unexpected command")
+		}
+		return os.ReadFile(filepath.Join("testdata", fn))
+	}
+}
+
+func TestGather(t *testing.T) {
+	plugin := &PBS{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.run = mockRun(map[string]string{
+		"qstat -B -f -F json": "qstat_server.json",
+		"qstat -Q -f -F json": "qstat_queues.json",
+		"pbsnodes -a -F json": "pbsnodes.json",
+	})
+
+	ts := time.Unix(1723466497, 0)
+	expected := []telegraf.Metric{
+		metric.New(
+			"pbs_server",
+			map[string]string{"server": "pbs01"},
+			map[string]interface{}{
+				"state":           "Active",
+				"total_jobs":      int64(12),
+				"jobs_transit":    int64(0),
+				"jobs_queued":     int64(5),
+				"jobs_held":       int64(1),
+				"jobs_waiting":    int64(0),
+				"jobs_running":    int64(6),
+				"jobs_exiting":    int64(0),
+				"jobs_begun":      int64(0),
+				"assigned_mem":    int64(48 * 1024 * 1024 * 1024),
+				"assigned_ncpus":  int64(24),
+				"assigned_nodect": int64(3),
+			},
+			ts,
+		),
+		metric.New(
+			"pbs_queue",
+			map[string]string{"server": "pbs01", "queue": "workq", "queue_type": "execution"},
+			map[string]interface{}{
+				"enabled":         true,
+				"started":         true,
+				"total_jobs":      int64(10),
+				"jobs_transit":    int64(0),
+				"jobs_queued":     int64(4),
+				"jobs_held":       int64(0),
+				"jobs_waiting":    int64(0),
+				"jobs_running":    int64(6),
+				"jobs_exiting":    int64(0),
+				"jobs_begun":      int64(0),
+				"assigned_mem":    int64(48 * 1024 * 1024 * 1024),
+				"assigned_ncpus":  int64(24),
+				"assigned_nodect": int64(3),
+			},
+			ts,
+		),
+		metric.New(
+			"pbs_queue",
+			map[string]string{"server": "pbs01", "queue": "gpu", "queue_type": "execution"},
+			map[string]interface{}{
+				"enabled":      true,
+				"started":      false,
+				"total_jobs":   int64(2),
+				"jobs_transit": int64(0),
+				"jobs_queued":  int64(1),
+				"jobs_held":    int64(1),
+				"jobs_waiting": int64(0),
+				"jobs_running": int64(0),
+				"jobs_exiting": int64(0),
+				"jobs_begun":   int64(0),
+			},
+			ts,
+		),
+		metric.New(
+			"pbs_node",
+			map[string]string{"server": "pbs01", "node": "node01"},
+			map[string]interface{}{
+				"state":                       "job-busy",
+				"jobs":                        int64(2),
+				"available_mem":               int64(65536 * 1024 * 1024),
+				"available_ncpus":             int64(16),
+				"assigned_accelerator_memory": int64(0),
+				"assigned_mem":                int64(32 * 1024 * 1024 * 1024),
+				"assigned_ncpus":              int64(16),
+				"assigned_vmem":               int64(0),
+			},
+			ts,
+		),
+		metric.New(
+			"pbs_node",
+			map[string]string{"server": "pbs01", "node": "node02", "queue": "gpu"},
+			map[string]interface{}{
+				"state":           "down,offline",
+				"jobs":            int64(0),
+				"available_mem":   int64(32 * 1024 * 1024 * 1024),
+				"available_ncpus": int64(8),
+				"available_ngpus": int64(2),
+			},
+			ts,
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestGatherPartialFailure(t *testing.T) {
+	plugin := &PBS{
+		Collect: []string{"queues", "nodes"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.run = mockRun(map[string]string{
+		"pbsnodes -a -F json": "pbsnodes.json",
+	})
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "gathering queue status failed")
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &PBS{
+		Collect: []string{"jobs"},
+		Log:     testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid collection")
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		ok       bool
+	}{
+		{"0kb", 0, true},
+		{"512b", 512, true},
+		{"16777216kb", 16 * 1024 * 1024 * 1024, true},
+		{"2TB", 2 << 40, true},
+		{"100w", 0, false},
+		{"linux", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, ok := parseSize(tt.input)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, v)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/common/appserver"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -77,10 +79,12 @@ type metricStat map[string]int64
 type poolStat map[string]metricStat
 
 type phpfpm struct {
-	Format  string          `toml:"format"`
-	Timeout config.Duration `toml:"timeout"`
-	Urls    []string        `toml:"urls"`
-	Log     telegraf.Logger `toml:"-"`
+	Format      string          `toml:"format"`
+	Timeout     config.Duration `toml:"timeout"`
+	Urls        []string        `toml:"urls"`
+	PoolConfigs []string        `toml:"pool_configs"`
+	Schema      string          `toml:"schema"`
+	Log         telegraf.Logger `toml:"-"`
 	tls.ClientConfig
 
 	client *http.Client
@@ -91,10 +95,17 @@ func (*phpfpm) SampleConfig() string {
 }
 
 func (p *phpfpm) Init() error {
-	if len(p.Urls) == 0 {
+	if len(p.Urls) == 0 && len(p.PoolConfigs) == 0 {
 		p.Urls = []string{"http://127.0.0.1/status"}
 	}
 
+	if p.Schema == "" {
+		p.Schema = appserver.SchemaNative
+	}
+	if err := appserver.CheckSchema(p.Schema); err != nil {
+		return err
+	}
+
 	tlsCfg, err := p.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
@@ -121,8 +132,21 @@ func (p *phpfpm) Init() error {
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (p *phpfpm) Gather(acc telegraf.Accumulator) error {
+	addrs := expandUrls(acc, p.Urls)
+	if len(p.PoolConfigs) > 0 {
+		discovered, err := p.discoverPools(p.PoolConfigs)
+		if err != nil {
+			acc.AddError(err)
+		}
+		for _, addr := range discovered {
+			if !slices.Contains(addrs, addr) {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
 	var wg sync.WaitGroup
-	for _, serv := range expandUrls(acc, p.Urls) {
+	for _, serv := range addrs {
 		wg.Add(1)
 		go func(serv string) {
 			defer wg.Done()
@@ -153,14 +177,13 @@ func (p *phpfpm) gatherServer(addr string, acc telegraf.Accumulator) error {
 		if err != nil {
 			return fmt.Errorf("unable parse server address %q: %w", addr, err)
 		}
-		socketAddr := strings.Split(u.Host, ":")
-		if len(socketAddr) < 2 {
+		if u.Port() == "" {
 			return fmt.Errorf("url does not follow required 'address:port' format: %s", u.Host)
 		}
-		fcgiIP := socketAddr[0]
-		fcgiPort, err := strconv.Atoi(socketAddr[1])
+		fcgiIP := u.Hostname()
+		fcgiPort, err := strconv.Atoi(u.Port())
 		if err != nil {
-			return fmt.Errorf("unable to parse server port %q: %w", socketAddr[1], err)
+			return fmt.Errorf("unable to parse server port %q: %w", u.Port(), err)
 		}
 		fcgi, err = newFcgiClient(time.Duration(p.Timeout), fcgiIP, fcgiPort)
 		if err != nil {
@@ -236,11 +259,11 @@ func (p *phpfpm) importMetric(r io.Reader, acc telegraf.Accumulator, addr string
 	if p.Format == "json" {
 		p.parseJSON(r, acc, addr)
 	} else {
-		parseLines(r, acc, addr)
+		p.parseLines(r, acc, addr)
 	}
 }
 
-func parseLines(r io.Reader, acc telegraf.Accumulator, addr string) {
+func (p *phpfpm) parseLines(r io.Reader, acc telegraf.Accumulator, addr string) {
 	stats := make(poolStat)
 	var currentPool string
 
@@ -280,6 +303,25 @@ func parseLines(r io.Reader, acc telegraf.Accumulator, addr string) {
 		}
 	}
 
+	if p.Schema == appserver.SchemaUnified {
+		timestamp := time.Now()
+		for pool, stat := range stats {
+			unified := &appserver.Pool{
+				Server:        "phpfpm",
+				Name:          pool,
+				Address:       addr,
+				Workers:       stat[PfTotalProcesses],
+				BusyWorkers:   appserver.Int64(stat[PfActiveProcesses]),
+				IdleWorkers:   appserver.Int64(stat[PfIdleProcesses]),
+				QueueDepth:    appserver.Int64(stat[PfListenQueue]),
+				MaxQueueDepth: appserver.Int64(stat[PfListenQueueLen]),
+				Requests:      appserver.Int64(stat[PfAcceptedConn]),
+			}
+			unified.Add(acc, timestamp)
+		}
+		return
+	}
+
 	// Finally, we push the pool metric
 	for pool := range stats {
 		tags := map[string]string{
@@ -302,6 +344,36 @@ func (p *phpfpm) parseJSON(r io.Reader, acc telegraf.Accumulator, addr string) {
 	}
 	timestamp := time.Now()
 
+	if p.Schema == appserver.SchemaUnified {
+		unified := &appserver.Pool{
+			Server:        "phpfpm",
+			Name:          metrics.Pool,
+			Address:       addr,
+			Workers:       int64(metrics.TotalProcesses),
+			BusyWorkers:   appserver.Int64(int64(metrics.ActiveProcesses)),
+			IdleWorkers:   appserver.Int64(int64(metrics.IdleProcesses)),
+			QueueDepth:    appserver.Int64(int64(metrics.ListenQueue)),
+			MaxQueueDepth: appserver.Int64(int64(metrics.ListenQueueLen)),
+			Requests:      appserver.Int64(int64(metrics.AcceptedConn)),
+		}
+		for _, process := range metrics.Processes {
+			state := "busy"
+			if process.State == "Idle" {
+				state = "idle"
+			}
+			duration := time.Duration(process.RequestDuration) * time.Microsecond
+			unified.WorkerStats = append(unified.WorkerStats, appserver.Worker{
+				ID:              strconv.Itoa(process.Pid),
+				PID:             int64(process.Pid),
+				State:           state,
+				Requests:        appserver.Int64(int64(process.Requests)),
+				RequestDuration: &duration,
+			})
+		}
+		unified.Add(acc, timestamp)
+		return
+	}
+
 	tags := map[string]string{
 		"pool": metrics.Pool,
 		"url":  addr,
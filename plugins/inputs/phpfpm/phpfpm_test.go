@@ -493,3 +493,97 @@ func TestGatherDespiteUnavailable(t *testing.T) {
 	require.ErrorContains(t, acc.GatherError(r.Gather), "socket doesn't exist")
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
+
+func TestDiscoverPools(t *testing.T) {
+	plugin := &phpfpm{Log: &testutil.Logger{}}
+	addrs, err := plugin.discoverPools([]string{"testdata/pool.d/*.conf"})
+	require.NoError(t, err)
+
+	expected := []string{
+		"fcgi://127.0.0.1:9101/fpm-status",
+		"fcgi://127.0.0.1:9002/status",
+		"/run/php/php8.2-fpm-www.sock:status",
+	}
+	require.Equal(t, expected, addrs)
+}
+
+func TestPhpFpmUnifiedSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("json") {
+			w.Header().Set("Content-Type", "text/json")
+			if _, err := w.Write(outputSampleJSON); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		if _, err := fmt.Fprint(w, outputSample); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	// Status format only provides pool statistics
+	plugin := &phpfpm{
+		Urls:   []string{server.URL},
+		Schema: "unified",
+		Log:    &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"app_server",
+			map[string]string{"server": "phpfpm", "pool": "www", "address": server.URL},
+			map[string]interface{}{
+				"workers":      int64(2),
+				"workers_busy": int64(1),
+				"workers_idle": int64(1),
+				"queue_depth":  int64(1),
+				"queue_max":    int64(0),
+				"requests":     int64(3),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// JSON format additionally provides per-worker statistics
+	plugin = &phpfpm{
+		Urls:   []string{server.URL + "?full&json"},
+		Format: "json",
+		Schema: "unified",
+		Log:    &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.True(t, acc.HasMeasurement("app_server"))
+	require.True(t, acc.HasMeasurement("app_server_worker"))
+	acc.AssertContainsTaggedFields(t,
+		"app_server_worker",
+		map[string]interface{}{
+			"pid":                 int64(585),
+			"state":               "idle",
+			"requests":            int64(389),
+			"request_duration_us": int64(9530),
+		},
+		map[string]string{
+			"server":  "phpfpm",
+			"pool":    "www",
+			"address": server.URL + "?full&json",
+			"worker":  "585",
+		},
+	)
+}
+
+func TestInvalidSchema(t *testing.T) {
+	plugin := &phpfpm{Schema: "foo", Log: &testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "invalid schema")
+}
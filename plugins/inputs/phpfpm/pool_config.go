@@ -0,0 +1,141 @@
+package phpfpm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/influxdata/telegraf/internal/globpath"
+)
+
+// poolConfig holds the settings of a php-fpm pool relevant for gathering its
+// status page
+type poolConfig struct {
+	name         string
+	listen       string
+	statusListen string
+	statusPath   string
+}
+
+// discoverPools returns the status page addresses of the pools defined in the
+// php-fpm configuration files matching the given glob patterns. Pools without
+// a status path are skipped as their status is not available.
+func (p *phpfpm) discoverPools(patterns []string) ([]string, error) {
+	var addrs []string
+	for _, pattern := range patterns {
+		glob, err := globpath.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("could not compile glob %q: %w", pattern, err)
+		}
+		for _, fn := range glob.Match() {
+			pools, err := readPoolConfig(fn)
+			if err != nil {
+				return nil, err
+			}
+			for _, pool := range pools {
+				addr := pool.address()
+				if addr == "" {
+					p.Log.Debugf("Skipping pool %q in %q without listen address or status path", pool.name, fn)
+					continue
+				}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs, nil
+}
+
+func readPoolConfig(fn string) ([]poolConfig, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("opening pool configuration failed: %w", err)
+	}
+	defer f.Close()
+
+	pools, err := parsePoolConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading pool configuration %q failed: %w", fn, err)
+	}
+	return pools, nil
+}
+
+// parsePoolConfig extracts the pool definitions from a php-fpm configuration
+// file in INI format. The global section is ignored.
+func parsePoolConfig(r io.Reader) ([]poolConfig, error) {
+	var pools []poolConfig
+	var current *poolConfig
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "global" {
+				current = nil
+				continue
+			}
+			pools = append(pools, poolConfig{name: name})
+			current = &pools[len(pools)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		value = strings.ReplaceAll(value, "$pool", current.name)
+		switch strings.TrimSpace(key) {
+		case "listen":
+			current.listen = value
+		case "pm.status_listen":
+			current.statusListen = value
+		case "pm.status_path":
+			current.statusPath = value
+		}
+	}
+
+	return pools, scanner.Err()
+}
+
+// address returns the address of the pool's status page in the format used
+// by the 'urls' setting or an empty string if the status is unavailable
+func (c *poolConfig) address() string {
+	listen := c.listen
+	if c.statusListen != "" {
+		listen = c.statusListen
+	}
+	if listen == "" || c.statusPath == "" {
+		return ""
+	}
+	statusPath := strings.TrimPrefix(c.statusPath, "/")
+
+	// Unix sockets are given as absolute path
+	if strings.HasPrefix(listen, "/") {
+		return listen + ":" + statusPath
+	}
+
+	// Network addresses are either 'port' or 'address:port' where wildcard
+	// addresses listen on all interfaces including the loopback interface
+	host, port, found := strings.Cut(listen, ":")
+	if !found {
+		host, port = "", listen
+	} else if idx := strings.LastIndex(listen, ":"); idx != len(host) {
+		// IPv6 addresses such as '[::]:9000'
+		host, port = listen[:idx], listen[idx+1:]
+	}
+	switch host {
+	case "", "*", "0.0.0.0", "[::]":
+		host = "127.0.0.1"
+	}
+	return "fcgi://" + host + ":" + port + "/" + statusPath
+}
@@ -0,0 +1,127 @@
+package powermetrics
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodePlist decodes a property list in XML format as produced by
+// powermetrics into generic values, i.e. dictionaries become
+// map[string]interface{}, arrays []interface{} and numbers float64.
+func decodePlist(data []byte) (interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	// Skip everything up to the root element
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no plist root element found")
+			}
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "plist" {
+			break
+		}
+	}
+
+	// Decode the first value inside the root element
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return decodePlistValue(d, t)
+		case xml.EndElement:
+			return nil, errors.New("empty plist")
+		}
+	}
+}
+
+func decodePlistValue(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodePlistDict(d)
+	case "array":
+		return decodePlistArray(d)
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "string", "data":
+		return text, nil
+	case "integer", "real":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", text, err)
+		}
+		return v, nil
+	case "date":
+		return time.Parse(time.RFC3339, text)
+	}
+	return nil, fmt.Errorf("unknown plist element %q", start.Name.Local)
+}
+
+func decodePlistDict(d *xml.Decoder) (map[string]interface{}, error) {
+	dict := make(map[string]interface{})
+	var key string
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				if err := d.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			v, err := decodePlistValue(d, t)
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = v
+		case xml.EndElement:
+			return dict, nil
+		}
+	}
+}
+
+func decodePlistArray(d *xml.Decoder) ([]interface{}, error) {
+	var array []interface{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := decodePlistValue(d, t)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		case xml.EndElement:
+			return array, nil
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package powermetrics
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Powermetrics struct {
+	Binary         string          `toml:"binary"`
+	UseSudo        bool            `toml:"use_sudo"`
+	Samplers       []string        `toml:"samplers"`
+	SampleDuration config.Duration `toml:"sample_duration"`
+	Log            telegraf.Logger `toml:"-"`
+
+	path string
+}
+
+func (*Powermetrics) SampleConfig() string {
+	return sampleConfig
+}
+
+// parse converts a single powermetrics sample in plist format to metrics
+func parse(acc telegraf.Accumulator, data []byte) error {
+	// Samples are separated by NUL characters
+	data = bytes.Trim(data, "\x00\n")
+	if len(data) == 0 {
+		return errors.New("no sample data")
+	}
+
+	v, err := decodePlist(data)
+	if err != nil {
+		return fmt.Errorf("decoding sample failed: %w", err)
+	}
+	sample, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected sample type %T", v)
+	}
+
+	timestamp := time.Now()
+	if ts, ok := sample["timestamp"].(time.Time); ok {
+		timestamp = ts
+	}
+	tags := make(map[string]string)
+	if model, ok := sample["hw_model"].(string); ok {
+		tags["hw_model"] = model
+	}
+
+	fields := make(map[string]interface{})
+	if pressure, ok := sample["thermal_pressure"].(string); ok {
+		fields["thermal_pressure"] = pressure
+	}
+
+	// Power of the CPU, GPU and Apple Neural Engine taken from IOReport
+	if processor, ok := sample["processor"].(map[string]interface{}); ok {
+		for _, key := range []string{"cpu_power", "gpu_power", "ane_power", "combined_power"} {
+			if v, ok := processor[key].(float64); ok {
+				fields[key] = v
+			}
+		}
+
+		clusters, _ := processor["clusters"].([]interface{})
+		for _, c := range clusters {
+			cluster, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, ok := cluster["name"].(string)
+			if !ok {
+				continue
+			}
+			ctags := map[string]string{"cluster": name}
+			for k, v := range tags {
+				ctags[k] = v
+			}
+			cfields := make(map[string]interface{})
+			if v, ok := cluster["freq_hz"].(float64); ok {
+				cfields["frequency"] = v
+			}
+			if v, ok := cluster["idle_ratio"].(float64); ok {
+				cfields["idle_ratio"] = v
+			}
+			if len(cfields) > 0 {
+				acc.AddFields("powermetrics_cluster", cfields, ctags, timestamp)
+			}
+		}
+	}
+
+	if gpu, ok := sample["gpu"].(map[string]interface{}); ok {
+		if v, ok := gpu["freq_hz"].(float64); ok {
+			fields["gpu_frequency"] = v
+		}
+		if v, ok := gpu["idle_ratio"].(float64); ok {
+			fields["gpu_idle_ratio"] = v
+		}
+	}
+
+	// Temperatures and fan speed read from the SMC (Intel only)
+	if smc, ok := sample["smc"].(map[string]interface{}); ok {
+		for key, field := range map[string]string{
+			"fan":     "fan_speed",
+			"cpu_die": "cpu_die_temperature",
+			"gpu_die": "gpu_die_temperature",
+		} {
+			if v, ok := smc[key].(float64); ok {
+				fields[field] = v
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("powermetrics", fields, tags, timestamp)
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("powermetrics", func() telegraf.Input {
+		return &Powermetrics{
+			Binary:         "/usr/bin/powermetrics",
+			Samplers:       []string{"cpu_power", "gpu_power", "ane_power", "thermal"},
+			SampleDuration: config.Duration(time.Second),
+		}
+	})
+}
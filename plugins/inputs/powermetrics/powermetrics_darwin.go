@@ -0,0 +1,57 @@
+//go:build darwin
+
+package powermetrics
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+var execCommand = exec.Command // execCommand is used to mock commands in tests.
+
+func (p *Powermetrics) Init() error {
+	if len(p.Samplers) == 0 {
+		return errors.New("no samplers configured")
+	}
+	if p.SampleDuration < config.Duration(100*time.Millisecond) {
+		return errors.New("'sample_duration' must be at least 100ms")
+	}
+
+	path, err := exec.LookPath(p.Binary)
+	if err != nil {
+		return fmt.Errorf("looking up %q failed: %w", p.Binary, err)
+	}
+	p.path = path
+
+	return nil
+}
+
+func (p *Powermetrics) Gather(acc telegraf.Accumulator) error {
+	args := []string{
+		"--samplers", strings.Join(p.Samplers, ","),
+		"--sample-count", "1",
+		"--sample-rate", strconv.FormatInt(time.Duration(p.SampleDuration).Milliseconds(), 10),
+		"--format", "plist",
+	}
+
+	cmd := execCommand(p.path, args...)
+	if p.UseSudo {
+		cmd = execCommand("sudo", append([]string{"-n", p.path}, args...)...)
+	}
+
+	timeout := time.Duration(p.SampleDuration) + 5*time.Second
+	out, err := internal.StdOutputTimeout(cmd, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to run command %q: %w - %s", strings.Join(cmd.Args, " "), err, string(out))
+	}
+
+	return parse(acc, out)
+}
@@ -0,0 +1,14 @@
+//go:build !darwin
+
+package powermetrics
+
+import "github.com/influxdata/telegraf"
+
+func (p *Powermetrics) Init() error {
+	p.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*Powermetrics) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package powermetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParse(t *testing.T) {
+	ts := time.Date(2023, 11, 2, 8, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		file     string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "apple silicon",
+			file: "apple_silicon.plist",
+			expected: []telegraf.Metric{
+				metric.New(
+					"powermetrics_cluster",
+					map[string]string{"hw_model": "Mac14,2", "cluster": "E-Cluster"},
+					map[string]interface{}{"frequency": 1.11827e+09, "idle_ratio": 0.732907},
+					ts,
+				),
+				metric.New(
+					"powermetrics_cluster",
+					map[string]string{"hw_model": "Mac14,2", "cluster": "P-Cluster"},
+					map[string]interface{}{"frequency": 6.605e+08, "idle_ratio": 0.987216},
+					ts,
+				),
+				metric.New(
+					"powermetrics",
+					map[string]string{"hw_model": "Mac14,2"},
+					map[string]interface{}{
+						"thermal_pressure": "Nominal",
+						"cpu_power":        148.271,
+						"gpu_power":        7.96069,
+						"ane_power":        0.0,
+						"combined_power":   156.232,
+						"gpu_frequency":    3.89e+08,
+						"gpu_idle_ratio":   0.98765,
+					},
+					ts,
+				),
+			},
+		},
+		{
+			name: "intel",
+			file: "intel.plist",
+			expected: []telegraf.Metric{
+				metric.New(
+					"powermetrics",
+					map[string]string{"hw_model": "MacBookPro16,1"},
+					map[string]interface{}{
+						"fan_speed":           1798.0,
+						"cpu_die_temperature": 58.4375,
+						"gpu_die_temperature": 52.0,
+					},
+					ts,
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			require.NoError(t, err)
+
+			var acc testutil.Accumulator
+			require.NoError(t, parse(&acc, data))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	var acc testutil.Accumulator
+	require.ErrorContains(t, parse(&acc, []byte("\x00")), "no sample data")
+	require.ErrorContains(t, parse(&acc, []byte("powermetrics: unrecognized sampler: smc")), "no plist root element")
+}
@@ -0,0 +1,295 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux || windows
+
+package process_flows
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/resolver"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ProcessFlows struct {
+	Protocols       []string        `toml:"protocols"`
+	Processes       []string        `toml:"processes"`
+	IncludeLoopback bool            `toml:"include_loopback"`
+	RemotePort      bool            `toml:"remote_port"`
+	PidTag          bool            `toml:"pid_tag"`
+	ResolveNames    bool            `toml:"resolve_names"`
+	CacheTTL        config.Duration `toml:"cache_ttl"`
+	LookupTimeout   config.Duration `toml:"lookup_timeout"`
+	Log             telegraf.Logger `toml:"-"`
+	resolver.Config
+
+	collector     collector
+	protocols     map[string]bool
+	processFilter filter.Filter
+	last          map[string]counters
+	dns           *resolver.DNS
+	failed        map[string]time.Time
+
+	// Mockable process name lookup for testing
+	processName func(pid int32) (string, error)
+}
+
+// collector lists the connections of the system with their owning process
+type collector interface {
+	sockets() ([]socket, error)
+}
+
+// socket is a connection of a process
+type socket struct {
+	// Identifier of the connection, unique during its lifetime
+	id       string
+	protocol string
+	pid      int32
+	local    netip.AddrPort
+	remote   netip.AddrPort
+
+	// Number of bytes transferred since the connection was established, only
+	// valid if hasBytes is set
+	hasBytes bool
+	counters
+}
+
+type counters struct {
+	sent     uint64
+	received uint64
+}
+
+type flowKey struct {
+	pid      int32
+	process  string
+	protocol string
+	remote   netip.Addr
+	port     uint16
+}
+
+type flow struct {
+	connections int64
+	hasBytes    bool
+	counters
+}
+
+func (*ProcessFlows) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *ProcessFlows) Init() error {
+	if len(p.Protocols) == 0 {
+		p.Protocols = []string{"tcp4", "tcp6"}
+	}
+	p.protocols = make(map[string]bool, len(p.Protocols))
+	for _, proto := range p.Protocols {
+		switch proto {
+		case "tcp4", "tcp6":
+			p.protocols[proto] = true
+		default:
+			return fmt.Errorf("invalid protocol %q", proto)
+		}
+	}
+
+	f, err := filter.Compile(p.Processes)
+	if err != nil {
+		return fmt.Errorf("creating process filter failed: %w", err)
+	}
+	p.processFilter = f
+
+	if p.processName == nil {
+		p.processName = lookupProcessName
+	}
+	if p.collector == nil {
+		c, err := newCollector(p.Log)
+		if err != nil {
+			return err
+		}
+		p.collector = c
+	}
+
+	return nil
+}
+
+func (p *ProcessFlows) Start(telegraf.Accumulator) error {
+	if p.ResolveNames {
+		p.dns = resolver.SharedDNS(p.Config, p.Log)
+		p.failed = make(map[string]time.Time)
+	}
+	return nil
+}
+
+func (p *ProcessFlows) Stop() {
+	if p.dns != nil {
+		p.dns.Release()
+	}
+}
+
+func (p *ProcessFlows) Gather(acc telegraf.Accumulator) error {
+	sockets, err := p.collector.sockets()
+	if err != nil {
+		return fmt.Errorf("listing connections failed: %w", err)
+	}
+
+	// The bytes of connections already established when gathering for the
+	// first time are not attributed to the first interval
+	first := p.last == nil
+
+	names := make(map[int32]string)
+	flows := make(map[flowKey]*flow)
+	current := make(map[string]counters, len(sockets))
+	for _, s := range sockets {
+		if !p.protocols[s.protocol] || s.pid <= 0 {
+			continue
+		}
+		if !p.IncludeLoopback && s.remote.Addr().IsLoopback() {
+			continue
+		}
+
+		name, found := names[s.pid]
+		if !found {
+			if name, err = p.processName(s.pid); err != nil {
+				// The process might have terminated in the meantime
+				p.Log.Tracef("Getting name of process %d failed: %v", s.pid, err)
+			}
+			names[s.pid] = name
+		}
+		if p.processFilter != nil && !p.processFilter.Match(name) {
+			continue
+		}
+
+		key := flowKey{
+			process:  name,
+			protocol: s.protocol,
+			remote:   s.remote.Addr(),
+		}
+		if p.PidTag {
+			key.pid = s.pid
+		}
+		if p.RemotePort {
+			key.port = s.remote.Port()
+		}
+		f, found := flows[key]
+		if !found {
+			f = &flow{}
+			flows[key] = f
+		}
+		f.connections++
+
+		if !s.hasBytes {
+			continue
+		}
+		f.hasBytes = true
+		current[s.id] = s.counters
+		if first {
+			continue
+		}
+		delta := s.counters
+		if previous, found := p.last[s.id]; found {
+			delta.sent = difference(s.sent, previous.sent)
+			delta.received = difference(s.received, previous.received)
+		}
+		f.sent += delta.sent
+		f.received += delta.received
+	}
+	p.last = current
+
+	now := time.Now()
+	for key, f := range flows {
+		tags := map[string]string{
+			"protocol":       key.protocol,
+			"remote_address": key.remote.String(),
+		}
+		if key.process != "" {
+			tags["process"] = key.process
+		}
+		if p.PidTag {
+			tags["pid"] = strconv.FormatInt(int64(key.pid), 10)
+		}
+		if p.RemotePort {
+			tags["remote_port"] = strconv.FormatUint(uint64(key.port), 10)
+		}
+		if host := p.lookup(key.remote); host != "" {
+			tags["remote_host"] = host
+		}
+
+		fields := map[string]interface{}{
+			"connections": f.connections,
+		}
+		if f.hasBytes {
+			fields["bytes_sent"] = f.sent
+			fields["bytes_received"] = f.received
+		}
+		acc.AddGauge("process_flows", fields, tags, now)
+	}
+
+	return nil
+}
+
+// lookup returns the name of the address or an empty string if the
+// resolution is disabled or failed
+func (p *ProcessFlows) lookup(addr netip.Addr) string {
+	if p.dns == nil {
+		return ""
+	}
+
+	// Errors are not cached by the resolver, so remember failed addresses to
+	// not query them again on each gather
+	ip := addr.String()
+	if retry, found := p.failed[ip]; found {
+		if time.Now().Before(retry) {
+			return ""
+		}
+		delete(p.failed, ip)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.LookupTimeout))
+	defer cancel()
+	names, err := p.dns.LookupAddr(ctx, ip, time.Duration(p.CacheTTL))
+	if err != nil || len(names) == 0 {
+		p.Log.Tracef("Resolving %q failed: %v", ip, err)
+		p.failed[ip] = time.Now().Add(time.Duration(p.CacheTTL))
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
+
+// difference returns the increase of the counter or the current value if the
+// counter was reset, e.g. if the identifier got reused by a new connection
+func difference(current, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+func lookupProcessName(pid int32) (string, error) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return "", err
+	}
+	return proc.Name()
+}
+
+func init() {
+	inputs.Add("process_flows", func() telegraf.Input {
+		return &ProcessFlows{
+			ResolveNames:  true,
+			CacheTTL:      config.Duration(time.Hour),
+			LookupTimeout: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux && !windows
+
+package process_flows
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ProcessFlows struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (p *ProcessFlows) Init() error {
+	p.Log.Warn("current platform is not supported")
+	return nil
+}
+func (*ProcessFlows) SampleConfig() string                { return sampleConfig }
+func (*ProcessFlows) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("process_flows", func() telegraf.Input {
+		return &ProcessFlows{}
+	})
+}
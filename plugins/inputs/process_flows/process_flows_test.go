@@ -0,0 +1,261 @@
+//go:build linux || windows
+
+package process_flows
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/resolver"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockCollector struct {
+	calls [][]socket
+}
+
+func (m *mockCollector) sockets() ([]socket, error) {
+	if len(m.calls) == 0 {
+		return nil, errors.New("no more calls")
+	}
+	s := m.calls[0]
+	m.calls = m.calls[1:]
+	return s, nil
+}
+
+type mockResolver map[string][]string
+
+func (m mockResolver) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	if names, found := m[addr]; found {
+		return names, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (mockResolver) LookupHost(context.Context, string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newSocket(id string, pid int32, remote string, sent, received uint64) socket {
+	addr := netip.MustParseAddrPort(remote)
+	protocol := "tcp4"
+	if addr.Addr().Is6() {
+		protocol = "tcp6"
+	}
+	return socket{
+		id:       id,
+		protocol: protocol,
+		pid:      pid,
+		local:    netip.MustParseAddrPort("192.168.1.10:50000"),
+		remote:   addr,
+		hasBytes: true,
+		counters: counters{sent: sent, received: received},
+	}
+}
+
+var processNames = map[int32]string{
+	100: "firefox",
+	200: "curl",
+	300: "curl",
+}
+
+func mockProcessName(pid int32) (string, error) {
+	if name, found := processNames[pid]; found {
+		return name, nil
+	}
+	return "", errors.New("process not found")
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &ProcessFlows{
+		Protocols: []string{"tcp4", "udp4"},
+		collector: &mockCollector{},
+		Log:       testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid protocol "udp4"`)
+}
+
+func TestGather(t *testing.T) {
+	collector := &mockCollector{
+		calls: [][]socket{
+			{
+				newSocket("1", 100, "93.184.216.34:443", 1000, 50000),
+				newSocket("2", 200, "93.184.216.34:443", 10, 20),
+				newSocket("3", 100, "127.0.0.1:8080", 10, 10),
+			},
+			{
+				newSocket("1", 100, "93.184.216.34:443", 3000, 90000),
+				newSocket("2", 200, "93.184.216.34:443", 10, 20),
+				newSocket("3", 100, "127.0.0.1:8080", 20, 20),
+				newSocket("4", 100, "93.184.216.34:443", 500, 700),
+				newSocket("5", 300, "[2001:db8::1]:443", 100, 200),
+				newSocket("6", 400, "10.0.0.1:22", 1, 1),
+			},
+		},
+	}
+
+	plugin := &ProcessFlows{
+		Processes:   []string{"firefox", "curl"},
+		collector:   collector,
+		processName: mockProcessName,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The bytes of already established connections are not reported in the
+	// first gather
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "firefox", "protocol": "tcp4", "remote_address": "93.184.216.34"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(0), "bytes_received": uint64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "protocol": "tcp4", "remote_address": "93.184.216.34"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(0), "bytes_received": uint64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Connections of the same process to the same address are combined, new
+	// connections are reported with all their bytes
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "firefox", "protocol": "tcp4", "remote_address": "93.184.216.34"},
+			map[string]interface{}{"connections": int64(2), "bytes_sent": uint64(2500), "bytes_received": uint64(40700)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "protocol": "tcp4", "remote_address": "93.184.216.34"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(0), "bytes_received": uint64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "protocol": "tcp6", "remote_address": "2001:db8::1"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(100), "bytes_received": uint64(200)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherPerPidAndPort(t *testing.T) {
+	collector := &mockCollector{
+		calls: [][]socket{
+			{
+				newSocket("1", 200, "10.0.0.1:443", 10, 20),
+				newSocket("2", 300, "10.0.0.1:443", 10, 20),
+				newSocket("3", 300, "10.0.0.1:80", 10, 20),
+			},
+		},
+	}
+	for i := range collector.calls[0] {
+		collector.calls[0][i].hasBytes = false
+	}
+
+	plugin := &ProcessFlows{
+		PidTag:      true,
+		RemotePort:  true,
+		collector:   collector,
+		processName: mockProcessName,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "pid": "200", "protocol": "tcp4", "remote_address": "10.0.0.1", "remote_port": "443"},
+			map[string]interface{}{"connections": int64(1)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "pid": "300", "protocol": "tcp4", "remote_address": "10.0.0.1", "remote_port": "443"},
+			map[string]interface{}{"connections": int64(1)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "curl", "pid": "300", "protocol": "tcp4", "remote_address": "10.0.0.1", "remote_port": "80"},
+			map[string]interface{}{"connections": int64(1)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestResolveNames(t *testing.T) {
+	collector := &mockCollector{
+		calls: [][]socket{
+			{
+				newSocket("1", 100, "93.184.216.34:443", 10, 20),
+				newSocket("2", 100, "10.0.0.1:443", 10, 20),
+			},
+		},
+	}
+
+	plugin := &ProcessFlows{
+		ResolveNames:  true,
+		CacheTTL:      config.Duration(time.Hour),
+		LookupTimeout: config.Duration(time.Second),
+		collector:     collector,
+		processName:   mockProcessName,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	plugin.dns = &resolver.DNS{
+		Cache:    resolver.New(t.Name(), testutil.Logger{}),
+		Resolver: mockResolver{"93.184.216.34": {"example.com."}},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "firefox", "protocol": "tcp4", "remote_address": "93.184.216.34", "remote_host": "example.com"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(0), "bytes_received": uint64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"process_flows",
+			map[string]string{"process": "firefox", "protocol": "tcp4", "remote_address": "10.0.0.1"},
+			map[string]interface{}{"connections": int64(1), "bytes_sent": uint64(0), "bytes_received": uint64(0)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+	require.Contains(t, plugin.failed, "10.0.0.1")
+}
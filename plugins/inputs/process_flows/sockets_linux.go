@@ -0,0 +1,224 @@
+//go:build linux
+
+package process_flows
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Definitions of the sock_diag netlink interface, see linux/inet_diag.h
+const (
+	inetDiagInfo = 2
+
+	// Size of struct inet_diag_msg preceding the attributes
+	inetDiagMsgLen = 72
+
+	// Offsets of the byte counters in struct tcp_info
+	tcpInfoBytesAcked    = 120
+	tcpInfoBytesReceived = 128
+
+	tcpListen   = 10
+	tcpTimeWait = 6
+	tcpClose    = 7
+)
+
+// netlinkCollector queries the TCP connections including the transferred
+// bytes via sock_diag and maps the socket inodes to processes using the
+// file descriptors listed in procfs
+type netlinkCollector struct {
+	procPath string
+	log      telegraf.Logger
+}
+
+func newCollector(log telegraf.Logger) (collector, error) {
+	procPath := os.Getenv("HOST_PROC")
+	if procPath == "" {
+		procPath = "/proc"
+	}
+	return &netlinkCollector{procPath: procPath, log: log}, nil
+}
+
+func (c *netlinkCollector) sockets() ([]socket, error) {
+	owners, err := socketOwners(c.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets []socket
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		messages, err := queryDiag(family)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range messages {
+			s, inode, err := parseDiagMessage(msg)
+			if err != nil {
+				c.log.Debugf("Parsing socket information failed: %v", err)
+				continue
+			}
+			s.pid = owners[inode]
+			sockets = append(sockets, s)
+		}
+	}
+	return sockets, nil
+}
+
+// queryDiag returns the inet_diag_msg messages of all TCP connections of the
+// given address family
+func queryDiag(family uint8) ([][]byte, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return nil, fmt.Errorf("creating netlink socket failed: %w", err)
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Sendto(fd, diagRequest(family), 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("sending request failed: %w", err)
+	}
+
+	var messages [][]byte
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("receiving response failed: %w", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("parsing response failed: %w", err)
+		}
+		for _, msg := range msgs {
+			switch msg.Header.Type {
+			case unix.NLMSG_DONE:
+				return messages, nil
+			case unix.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(msg.Data)); errno != 0 {
+						return nil, fmt.Errorf("request failed: %w", unix.Errno(-errno))
+					}
+				}
+				return messages, nil
+			case unix.SOCK_DIAG_BY_FAMILY:
+				messages = append(messages, append([]byte(nil), msg.Data...))
+			}
+		}
+	}
+}
+
+// diagRequest creates a netlink message containing a inet_diag_req_v2
+// requesting the TCP information of all connected sockets
+func diagRequest(family uint8) []byte {
+	const length = unix.NLMSG_HDRLEN + 56
+	states := ^uint32(0) &^ (1<<tcpListen | 1<<tcpTimeWait | 1<<tcpClose)
+
+	buf := make([]byte, length)
+	binary.NativeEndian.PutUint32(buf[0:], length)
+	binary.NativeEndian.PutUint16(buf[4:], unix.SOCK_DIAG_BY_FAMILY)
+	binary.NativeEndian.PutUint16(buf[6:], unix.NLM_F_REQUEST|unix.NLM_F_DUMP)
+	binary.NativeEndian.PutUint32(buf[8:], 1)
+	buf[16] = family
+	buf[17] = unix.IPPROTO_TCP
+	buf[18] = 1 << (inetDiagInfo - 1)
+	binary.NativeEndian.PutUint32(buf[20:], states)
+	return buf
+}
+
+// parseDiagMessage decodes a inet_diag_msg and returns the connection and
+// the inode of the socket
+func parseDiagMessage(msg []byte) (socket, uint32, error) {
+	if len(msg) < inetDiagMsgLen {
+		return socket{}, 0, errors.New("message too short")
+	}
+
+	var s socket
+	var local, remote netip.Addr
+	switch msg[0] {
+	case unix.AF_INET:
+		s.protocol = "tcp4"
+		local = netip.AddrFrom4([4]byte(msg[8:12]))
+		remote = netip.AddrFrom4([4]byte(msg[24:28]))
+	case unix.AF_INET6:
+		s.protocol = "tcp6"
+		local = netip.AddrFrom16([16]byte(msg[8:24])).Unmap()
+		remote = netip.AddrFrom16([16]byte(msg[24:40])).Unmap()
+	default:
+		return socket{}, 0, fmt.Errorf("unknown address family %d", msg[0])
+	}
+	s.local = netip.AddrPortFrom(local, binary.BigEndian.Uint16(msg[4:6]))
+	s.remote = netip.AddrPortFrom(remote, binary.BigEndian.Uint16(msg[6:8]))
+	s.id = strconv.FormatUint(binary.NativeEndian.Uint64(msg[44:52]), 10)
+	inode := binary.NativeEndian.Uint32(msg[68:72])
+
+	// Walk the attributes to find the TCP information
+	for attrs := msg[inetDiagMsgLen:]; len(attrs) >= unix.SizeofRtAttr; {
+		length := int(binary.NativeEndian.Uint16(attrs[0:]))
+		kind := binary.NativeEndian.Uint16(attrs[2:])
+		if length < unix.SizeofRtAttr || length > len(attrs) {
+			return socket{}, 0, errors.New("invalid attribute length")
+		}
+		data := attrs[unix.SizeofRtAttr:length]
+		if kind == inetDiagInfo && len(data) >= tcpInfoBytesReceived+8 {
+			s.hasBytes = true
+			s.sent = binary.NativeEndian.Uint64(data[tcpInfoBytesAcked:])
+			s.received = binary.NativeEndian.Uint64(data[tcpInfoBytesReceived:])
+		}
+		aligned := (length + unix.RTA_ALIGNTO - 1) &^ (unix.RTA_ALIGNTO - 1)
+		if aligned >= len(attrs) {
+			break
+		}
+		attrs = attrs[aligned:]
+	}
+
+	return s, inode, nil
+}
+
+// socketOwners returns the process owning each socket inode by listing the
+// file descriptors of all processes. Processes not accessible, e.g. due to
+// missing permissions, are skipped.
+func socketOwners(procPath string) (map[uint32]int32, error) {
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing processes failed: %w", err)
+	}
+
+	owners := make(map[uint32]int32)
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		fdPath := filepath.Join(procPath, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdPath)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, found := strings.CutPrefix(target, "socket:[")
+			if !found {
+				continue
+			}
+			n, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 32)
+			if err != nil {
+				continue
+			}
+			owners[uint32(n)] = int32(pid)
+		}
+	}
+	return owners, nil
+}
@@ -0,0 +1,79 @@
+package process_flows
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseDiagMessage(t *testing.T) {
+	msg := make([]byte, inetDiagMsgLen)
+	msg[0] = unix.AF_INET6
+	msg[1] = 1 // established
+	binary.BigEndian.PutUint16(msg[4:], 50000)
+	binary.BigEndian.PutUint16(msg[6:], 443)
+	copy(msg[8:24], netip.MustParseAddr("2001:db8::10").AsSlice())
+	copy(msg[24:40], netip.MustParseAddr("::ffff:93.184.216.34").AsSlice())
+	binary.NativeEndian.PutUint64(msg[44:], 4711)
+	binary.NativeEndian.PutUint32(msg[68:], 12345)
+
+	// Another attribute followed by the TCP information
+	other := make([]byte, unix.SizeofRtAttr+2)
+	binary.NativeEndian.PutUint16(other[0:], uint16(len(other)))
+	binary.NativeEndian.PutUint16(other[2:], 1)
+	msg = append(msg, other...)
+	msg = append(msg, 0, 0)
+
+	info := make([]byte, unix.SizeofRtAttr+232)
+	binary.NativeEndian.PutUint16(info[0:], uint16(len(info)))
+	binary.NativeEndian.PutUint16(info[2:], inetDiagInfo)
+	binary.NativeEndian.PutUint64(info[unix.SizeofRtAttr+tcpInfoBytesAcked:], 1500)
+	binary.NativeEndian.PutUint64(info[unix.SizeofRtAttr+tcpInfoBytesReceived:], 64000)
+	msg = append(msg, info...)
+
+	s, inode, err := parseDiagMessage(msg)
+	require.NoError(t, err)
+	require.Equal(t, uint32(12345), inode)
+	require.Equal(t, socket{
+		id:       "4711",
+		protocol: "tcp6",
+		local:    netip.MustParseAddrPort("[2001:db8::10]:50000"),
+		remote:   netip.MustParseAddrPort("93.184.216.34:443"),
+		hasBytes: true,
+		counters: counters{sent: 1500, received: 64000},
+	}, s)
+}
+
+func TestParseDiagMessageInvalid(t *testing.T) {
+	_, _, err := parseDiagMessage(make([]byte, 10))
+	require.ErrorContains(t, err, "message too short")
+
+	msg := make([]byte, inetDiagMsgLen)
+	msg[0] = 42
+	_, _, err = parseDiagMessage(msg)
+	require.ErrorContains(t, err, "unknown address family 42")
+}
+
+func TestSocketOwners(t *testing.T) {
+	procPath := t.TempDir()
+	for pid, links := range map[string][]string{
+		"100":  {"socket:[111]", "/dev/null", "socket:[112]"},
+		"200":  {"pipe:[999]", "socket:[221]"},
+		"self": {"socket:[333]"},
+	} {
+		fdPath := filepath.Join(procPath, pid, "fd")
+		require.NoError(t, os.MkdirAll(fdPath, 0750))
+		for i, link := range links {
+			require.NoError(t, os.Symlink(link, filepath.Join(fdPath, string(rune('0'+i)))))
+		}
+	}
+
+	owners, err := socketOwners(procPath)
+	require.NoError(t, err)
+	require.Equal(t, map[uint32]int32{111: 100, 112: 100, 221: 200}, owners)
+}
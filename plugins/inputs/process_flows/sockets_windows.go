@@ -0,0 +1,262 @@
+//go:build windows
+
+package process_flows
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Definitions of the IP Helper API, see
+// https://learn.microsoft.com/en-us/windows/win32/api/iphlpapi/
+const (
+	tcpTableOwnerPidConnections = 4
+	tcpConnectionEstatsData     = 1
+
+	mibTCPStateClosed    = 1
+	mibTCPStateTimeWait  = 11
+	mibTCPStateDeleteTCB = 12
+)
+
+// mibTCPRowOwnerPID mirrors MIB_TCPROW_OWNER_PID, its first part matches
+// MIB_TCPROW
+type mibTCPRowOwnerPID struct {
+	State      uint32
+	LocalAddr  [4]byte
+	LocalPort  [4]byte
+	RemoteAddr [4]byte
+	RemotePort [4]byte
+	OwningPID  uint32
+}
+
+// mibTCPRow mirrors MIB_TCPROW
+type mibTCPRow struct {
+	State      uint32
+	LocalAddr  [4]byte
+	LocalPort  [4]byte
+	RemoteAddr [4]byte
+	RemotePort [4]byte
+}
+
+// mibTCP6RowOwnerPID mirrors MIB_TCP6ROW_OWNER_PID
+type mibTCP6RowOwnerPID struct {
+	LocalAddr     [16]byte
+	LocalScopeID  uint32
+	LocalPort     [4]byte
+	RemoteAddr    [16]byte
+	RemoteScopeID uint32
+	RemotePort    [4]byte
+	State         uint32
+	OwningPID     uint32
+}
+
+// mibTCP6Row mirrors MIB_TCP6ROW
+type mibTCP6Row struct {
+	State         uint32
+	LocalAddr     [16]byte
+	LocalScopeID  uint32
+	LocalPort     [4]byte
+	RemoteAddr    [16]byte
+	RemoteScopeID uint32
+	RemotePort    [4]byte
+}
+
+// tcpEstatsDataRodV0 mirrors TCP_ESTATS_DATA_ROD_v0 with explicit padding
+// to match the layout on 32-bit platforms
+type tcpEstatsDataRodV0 struct {
+	DataBytesOut      uint64
+	DataSegsOut       uint64
+	DataBytesIn       uint64
+	DataSegsIn        uint64
+	SegsOut           uint64
+	SegsIn            uint64
+	SoftErrors        uint32
+	SoftErrorReason   uint32
+	SndUna            uint32
+	SndNxt            uint32
+	SndMax            uint32
+	_                 uint32
+	ThruBytesAcked    uint64
+	RcvNxt            uint32
+	_                 uint32
+	ThruBytesReceived uint64
+}
+
+var (
+	modiphlpapi = windows.NewLazySystemDLL("iphlpapi.dll")
+
+	procGetExtendedTCPTable        = modiphlpapi.NewProc("GetExtendedTcpTable")
+	procGetPerTCPConnectionEStats  = modiphlpapi.NewProc("GetPerTcpConnectionEStats")
+	procSetPerTCPConnectionEStats  = modiphlpapi.NewProc("SetPerTcpConnectionEStats")
+	procGetPerTCP6ConnectionEStats = modiphlpapi.NewProc("GetPerTcp6ConnectionEStats")
+	procSetPerTCP6ConnectionEStats = modiphlpapi.NewProc("SetPerTcp6ConnectionEStats")
+)
+
+// iphlpapiCollector lists the TCP connections with their owning process and
+// reads the transferred bytes from the extended statistics of each
+// connection. Collecting the statistics is enabled for each new connection,
+// so the bytes of a connection are available from the second gather on.
+type iphlpapiCollector struct {
+	log telegraf.Logger
+
+	// Connections the collection of statistics was enabled for
+	enabled map[string]bool
+	warned  bool
+}
+
+func newCollector(log telegraf.Logger) (collector, error) {
+	return &iphlpapiCollector{log: log, enabled: make(map[string]bool)}, nil
+}
+
+func (c *iphlpapiCollector) sockets() ([]socket, error) {
+	buf4, err := extendedTCPTable(windows.AF_INET)
+	if err != nil {
+		return nil, fmt.Errorf("getting IPv4 connections failed: %w", err)
+	}
+	buf6, err := extendedTCPTable(windows.AF_INET6)
+	if err != nil {
+		return nil, fmt.Errorf("getting IPv6 connections failed: %w", err)
+	}
+
+	enabled := make(map[string]bool, len(c.enabled))
+	var sockets []socket
+
+	count := binary.LittleEndian.Uint32(buf4)
+	for i := range uintptr(count) {
+		row := (*mibTCPRowOwnerPID)(unsafe.Pointer(&buf4[4+i*unsafe.Sizeof(mibTCPRowOwnerPID{})])) //nolint:gosec // G103: Valid use of unsafe call to interpret the table
+		if !connected(row.State) {
+			continue
+		}
+		s := socket{
+			protocol: "tcp4",
+			pid:      int32(row.OwningPID),
+			local:    netip.AddrPortFrom(netip.AddrFrom4(row.LocalAddr), binary.BigEndian.Uint16(row.LocalPort[:])),
+			remote:   netip.AddrPortFrom(netip.AddrFrom4(row.RemoteAddr), binary.BigEndian.Uint16(row.RemotePort[:])),
+		}
+		s.id = fmt.Sprintf("%d/%s/%s", s.pid, s.local, s.remote)
+
+		r := &mibTCPRow{
+			State:      row.State,
+			LocalAddr:  row.LocalAddr,
+			LocalPort:  row.LocalPort,
+			RemoteAddr: row.RemoteAddr,
+			RemotePort: row.RemotePort,
+		}
+		c.statistics(&s, enabled, procGetPerTCPConnectionEStats, procSetPerTCPConnectionEStats, unsafe.Pointer(r)) //nolint:gosec // G103: Valid use of unsafe call to pass the row
+		sockets = append(sockets, s)
+	}
+
+	count = binary.LittleEndian.Uint32(buf6)
+	for i := range uintptr(count) {
+		row := (*mibTCP6RowOwnerPID)(unsafe.Pointer(&buf6[4+i*unsafe.Sizeof(mibTCP6RowOwnerPID{})])) //nolint:gosec // G103: Valid use of unsafe call to interpret the table
+		if !connected(row.State) {
+			continue
+		}
+		s := socket{
+			protocol: "tcp6",
+			pid:      int32(row.OwningPID),
+			local:    netip.AddrPortFrom(netip.AddrFrom16(row.LocalAddr).Unmap(), binary.BigEndian.Uint16(row.LocalPort[:])),
+			remote:   netip.AddrPortFrom(netip.AddrFrom16(row.RemoteAddr).Unmap(), binary.BigEndian.Uint16(row.RemotePort[:])),
+		}
+		s.id = fmt.Sprintf("%d/%s/%s", s.pid, s.local, s.remote)
+
+		r := &mibTCP6Row{
+			State:         row.State,
+			LocalAddr:     row.LocalAddr,
+			LocalScopeID:  row.LocalScopeID,
+			LocalPort:     row.LocalPort,
+			RemoteAddr:    row.RemoteAddr,
+			RemoteScopeID: row.RemoteScopeID,
+			RemotePort:    row.RemotePort,
+		}
+		c.statistics(&s, enabled, procGetPerTCP6ConnectionEStats, procSetPerTCP6ConnectionEStats, unsafe.Pointer(r)) //nolint:gosec // G103: Valid use of unsafe call to pass the row
+		sockets = append(sockets, s)
+	}
+	c.enabled = enabled
+
+	return sockets, nil
+}
+
+// statistics reads the transferred bytes of the connection and enables the
+// collection of statistics for new connections
+func (c *iphlpapiCollector) statistics(s *socket, enabled map[string]bool, get, set *windows.LazyProc, row unsafe.Pointer) {
+	if c.enabled[s.id] {
+		var rod tcpEstatsDataRodV0
+		r, _, _ := syscall.SyscallN(
+			get.Addr(),
+			uintptr(row),
+			tcpConnectionEstatsData,
+			0, 0, 0,
+			0, 0, 0,
+			uintptr(unsafe.Pointer(&rod)), //nolint:gosec // G103: Valid use of unsafe call to pass the statistics
+			0,
+			unsafe.Sizeof(rod),
+		)
+		if r == 0 {
+			s.hasBytes = true
+			s.sent = rod.DataBytesOut
+			s.received = rod.DataBytesIn
+			enabled[s.id] = true
+			return
+		}
+	}
+
+	// Enabling the statistics requires administrator privileges
+	enable := uint8(1)
+	r, _, _ := syscall.SyscallN(
+		set.Addr(),
+		uintptr(row),
+		tcpConnectionEstatsData,
+		uintptr(unsafe.Pointer(&enable)), //nolint:gosec // G103: Valid use of unsafe call to pass the setting
+		0,
+		unsafe.Sizeof(enable),
+		0,
+	)
+	if r != 0 {
+		if !c.warned {
+			c.log.Warnf("Cannot collect transferred bytes, enabling extended statistics failed: %v", syscall.Errno(r))
+			c.warned = true
+		}
+		return
+	}
+	enabled[s.id] = true
+}
+
+func connected(state uint32) bool {
+	return state != mibTCPStateClosed && state != mibTCPStateTimeWait && state != mibTCPStateDeleteTCB
+}
+
+// extendedTCPTable returns the MIB_TCPTABLE_OWNER_PID or
+// MIB_TCP6TABLE_OWNER_PID of the connections of the given address family
+func extendedTCPTable(family uint32) ([]byte, error) {
+	size := uint32(16 * 1024)
+	for {
+		buf := make([]byte, size)
+		r, _, _ := syscall.SyscallN(
+			procGetExtendedTCPTable.Addr(),
+			uintptr(unsafe.Pointer(&buf[0])), //nolint:gosec // G103: Valid use of unsafe call to pass the buffer
+			uintptr(unsafe.Pointer(&size)),   //nolint:gosec // G103: Valid use of unsafe call to pass the size
+			0,
+			uintptr(family),
+			tcpTableOwnerPidConnections,
+			0,
+		)
+		switch syscall.Errno(r) {
+		case 0:
+			return buf, nil
+		case windows.ERROR_INSUFFICIENT_BUFFER:
+			// The table might have grown in the meantime, retry with the
+			// updated size
+			continue
+		default:
+			return nil, syscall.Errno(r)
+		}
+	}
+}
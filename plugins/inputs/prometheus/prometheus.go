@@ -26,6 +26,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/models"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/resolver"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/openmetrics"
 	parsers_prometheus "github.com/influxdata/telegraf/plugins/parsers/prometheus"
@@ -69,6 +70,7 @@ type Prometheus struct {
 	PodNamespace                string              `toml:"monitor_kubernetes_pods_namespace"`
 	PodNamespaceLabelName       string              `toml:"pod_namespace_label_name"`
 	KubernetesServices          []string            `toml:"kubernetes_services"`
+	KubernetesServicesDNSTTL    config.Duration     `toml:"kubernetes_services_dns_ttl"`
 	KubeConfig                  string              `toml:"kube_config"`
 	KubernetesLabelSelector     string              `toml:"kubernetes_label_selector"`
 	KubernetesFieldSelector     string              `toml:"kubernetes_field_selector"`
@@ -93,6 +95,7 @@ type Prometheus struct {
 	client      *http.Client
 	headers     map[string]string
 	contentType string
+	dns         *resolver.DNS
 
 	nsStore          cache.Store
 	nsAnnotationPass []models.TagFilter
@@ -328,7 +331,7 @@ func (p *Prometheus) GetAllURLs() (map[string]URLAndAddress, error) {
 			return nil, err
 		}
 
-		resolvedAddresses, err := net.LookupHost(address.Hostname())
+		resolvedAddresses, err := p.lookupHost(address.Hostname())
 		if err != nil {
 			p.Log.Errorf("Could not resolve %q, skipping it. Error: %s", address.Host, err.Error())
 			continue
@@ -345,6 +348,15 @@ func (p *Prometheus) GetAllURLs() (map[string]URLAndAddress, error) {
 	return allURLs, nil
 }
 
+// lookupHost resolves the host using the DNS cache shared with other plugins
+// if the plugin is started
+func (p *Prometheus) lookupHost(host string) ([]string, error) {
+	if p.dns == nil {
+		return net.LookupHost(host)
+	}
+	return p.dns.LookupHost(context.Background(), host, time.Duration(p.KubernetesServicesDNSTTL))
+}
+
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (p *Prometheus) Gather(acc telegraf.Accumulator) error {
@@ -604,6 +616,9 @@ func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 	p.wg = sync.WaitGroup{}
 	ctx, p.cancel = context.WithCancel(context.Background())
 
+	if len(p.KubernetesServices) > 0 {
+		p.dns = resolver.SharedDNS(resolver.Config{}, p.Log)
+	}
 	if p.ConsulConfig.Enabled && len(p.ConsulConfig.Queries) > 0 {
 		if err := p.startConsul(ctx); err != nil {
 			return err
@@ -624,6 +639,10 @@ func (p *Prometheus) Stop() {
 	if p.client != nil {
 		p.client.CloseIdleConnections()
 	}
+	if p.dns != nil {
+		p.dns.Release()
+		p.dns = nil
+	}
 }
 
 func init() {
@@ -7,17 +7,18 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/fanout"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -38,6 +39,7 @@ type Redis struct {
 	Password string          `toml:"password"`
 
 	tls.ClientConfig
+	fanout.Config
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -309,19 +311,19 @@ func (r *Redis) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
-	var wg sync.WaitGroup
+	fanout.Gather(r.Config, acc, r.clients, clientName, func(_ context.Context, acc telegraf.Accumulator, client Client) error {
+		acc.AddError(r.gatherServer(client, acc))
+		return r.gatherCommandValues(client, acc)
+	})
+	return nil
+}
 
-	for _, client := range r.clients {
-		wg.Add(1)
-		go func(client Client) {
-			defer wg.Done()
-			acc.AddError(r.gatherServer(client, acc))
-			acc.AddError(r.gatherCommandValues(client, acc))
-		}(client)
+func clientName(client Client) string {
+	tags := client.BaseTags()
+	if socket, found := tags["socket"]; found {
+		return socket
 	}
-
-	wg.Wait()
-	return nil
+	return net.JoinHostPort(tags["server"], tags["port"])
 }
 
 func (r *Redis) gatherCommandValues(client Client, acc telegraf.Accumulator) error {
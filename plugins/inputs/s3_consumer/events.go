@@ -0,0 +1,84 @@
+package s3_consumer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// object identifies an S3 object referenced by an event notification
+type object struct {
+	bucket string
+	key    string
+}
+
+// S3 event notification, see
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/notification-content-structure.html
+type s3Event struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+		EventName   string `json:"eventName"`
+		S3          struct {
+			Bucket struct {
+				Name string `json:"name"`
+			} `json:"bucket"`
+			Object struct {
+				Key string `json:"key"`
+			} `json:"object"`
+		} `json:"s3"`
+	} `json:"Records"`
+
+	// Set for test events sent when configuring the notification
+	Event string `json:"Event"`
+
+	// Set for notifications delivered via SNS
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+
+	// Set for notifications delivered via EventBridge
+	DetailType string `json:"detail-type"`
+	Detail     struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key string `json:"key"`
+		} `json:"object"`
+	} `json:"detail"`
+}
+
+// parseEvent returns the objects created according to the given message body.
+// Other events, e.g. deletions or test events, result in no objects.
+func parseEvent(body []byte) ([]object, error) {
+	var event s3Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decoding event failed: %w", err)
+	}
+
+	// Unwrap notifications delivered via SNS without raw message delivery
+	if event.Type == "Notification" {
+		return parseEvent([]byte(event.Message))
+	}
+
+	// EventBridge keys are not URL encoded
+	if event.DetailType != "" {
+		if event.DetailType != "Object Created" {
+			return nil, nil
+		}
+		return []object{{bucket: event.Detail.Bucket.Name, key: event.Detail.Object.Key}}, nil
+	}
+
+	objects := make([]object, 0, len(event.Records))
+	for _, r := range event.Records {
+		if r.EventSource != "aws:s3" || !strings.HasPrefix(r.EventName, "ObjectCreated:") {
+			continue
+		}
+		key, err := url.QueryUnescape(r.S3.Object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("decoding key %q failed: %w", r.S3.Object.Key, err)
+		}
+		objects = append(objects, object{bucket: r.S3.Bucket.Name, key: key})
+	}
+	return objects, nil
+}
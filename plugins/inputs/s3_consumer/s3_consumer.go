@@ -0,0 +1,429 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package s3_consumer
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var once sync.Once
+
+const (
+	defaultMaxUndeliveredMessages = 10
+	defaultMaxObjectSize          = 500 * 1024 * 1024
+	defaultRetryInterval          = time.Second
+	maxRetryInterval              = time.Minute
+)
+
+type S3Consumer struct {
+	QueueURL               string          `toml:"queue_url"`
+	MaxNumberOfMessages    int32           `toml:"max_number_of_messages"`
+	WaitTime               config.Duration `toml:"wait_time"`
+	VisibilityTimeout      config.Duration `toml:"visibility_timeout"`
+	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
+	ForcePathStyle         bool            `toml:"force_path_style"`
+	ObjectKeys             []string        `toml:"object_keys"`
+	MaxObjectSize          config.Size     `toml:"max_object_size"`
+	ContentEncoding        string          `toml:"content_encoding"`
+	IncludeObjectMetadata  []string        `toml:"include_object_metadata"`
+	Log                    telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	queue         sqsClient
+	storage       s3Client
+	parser        telegraf.Parser
+	keyFilter     filter.Filter
+	acc           telegraf.TrackingAccumulator
+	sem           chan struct{}
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	retryInterval time.Duration
+
+	pending    map[telegraf.TrackingID]string
+	pendingTex sync.Mutex
+
+	messagesReceived selfstat.Stat
+	objectsRead      selfstat.Stat
+	bytesRead        selfstat.Stat
+	objectErrors     selfstat.Stat
+	receiveErrors    selfstat.Stat
+	deleteErrors     selfstat.Stat
+}
+
+// sqsClient contains the SQS API used, implemented by sqs.Client
+type sqsClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// s3Client contains the S3 API used, implemented by s3.Client
+type s3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+func (*S3Consumer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *S3Consumer) Init() error {
+	if s.QueueURL == "" {
+		return errors.New("'queue_url' required")
+	}
+	if s.MaxNumberOfMessages == 0 {
+		s.MaxNumberOfMessages = 10
+	}
+	if s.MaxNumberOfMessages < 1 || s.MaxNumberOfMessages > 10 {
+		return errors.New("'max_number_of_messages' must be between 1 and 10")
+	}
+	if s.WaitTime < 0 || time.Duration(s.WaitTime) > 20*time.Second {
+		return errors.New("'wait_time' must be between 0s and 20s")
+	}
+	if s.VisibilityTimeout < 0 || time.Duration(s.VisibilityTimeout) > 12*time.Hour {
+		return errors.New("'visibility_timeout' must be between 0s and 12h")
+	}
+	if s.MaxUndeliveredMessages <= 0 {
+		s.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+	if s.MaxObjectSize == 0 {
+		s.MaxObjectSize = config.Size(defaultMaxObjectSize)
+	}
+
+	if s.ContentEncoding == "" {
+		s.ContentEncoding = "auto"
+	}
+	if _, err := internal.NewContentDecoder(s.ContentEncoding); err != nil {
+		return fmt.Errorf("unknown content encoding %q", s.ContentEncoding)
+	}
+
+	for _, item := range s.IncludeObjectMetadata {
+		switch item {
+		case "bucket", "key":
+		default:
+			return fmt.Errorf("invalid object metadata %q", item)
+		}
+	}
+
+	if len(s.ObjectKeys) > 0 {
+		f, err := filter.Compile(s.ObjectKeys, '/')
+		if err != nil {
+			return fmt.Errorf("compiling object key patterns failed: %w", err)
+		}
+		s.keyFilter = f
+	}
+
+	if s.retryInterval <= 0 {
+		s.retryInterval = defaultRetryInterval
+	}
+
+	tags := map[string]string{"queue_url": s.QueueURL}
+	s.messagesReceived = selfstat.Register("s3_consumer", "messages_received", tags)
+	s.objectsRead = selfstat.Register("s3_consumer", "objects_read", tags)
+	s.bytesRead = selfstat.Register("s3_consumer", "bytes_read", tags)
+	s.objectErrors = selfstat.Register("s3_consumer", "object_errors", tags)
+	s.receiveErrors = selfstat.Register("s3_consumer", "receive_errors", tags)
+	s.deleteErrors = selfstat.Register("s3_consumer", "delete_errors", tags)
+
+	return nil
+}
+
+func (s *S3Consumer) SetParser(parser telegraf.Parser) {
+	s.parser = parser
+}
+
+func (s *S3Consumer) Start(acc telegraf.Accumulator) error {
+	if s.queue == nil || s.storage == nil {
+		httpClient, err := s.ClientConfig.CreateClient()
+		if err != nil {
+			return err
+		}
+		s.CredentialConfig.HTTPClient = httpClient
+
+		cfg, err := s.CredentialConfig.Credentials()
+		if err != nil {
+			return err
+		}
+		s.queue = sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+			if s.EndpointURL != "" {
+				o.BaseEndpoint = &s.EndpointURL
+			}
+		})
+		s.storage = s3.NewFromConfig(cfg, func(o *s3.Options) {
+			if s.EndpointURL != "" {
+				o.BaseEndpoint = &s.EndpointURL
+			}
+			o.UsePathStyle = s.ForcePathStyle
+		})
+	}
+
+	s.acc = acc.WithTracking(s.MaxUndeliveredMessages)
+	s.sem = make(chan struct{}, s.MaxUndeliveredMessages)
+	s.pending = make(map[telegraf.TrackingID]string, s.MaxUndeliveredMessages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.onDelivery(ctx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.receive(ctx)
+	}()
+
+	return nil
+}
+
+func (*S3Consumer) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *S3Consumer) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// receive polls the queue until the context is done. Each received message
+// takes one of the undelivered message slots.
+func (s *S3Consumer) receive(ctx context.Context) {
+	var attempt int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s.sem <- struct{}{}:
+		}
+		slots := int32(1)
+	acquire:
+		for slots < s.MaxNumberOfMessages {
+			select {
+			case s.sem <- struct{}{}:
+				slots++
+			default:
+				break acquire
+			}
+		}
+
+		input := &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(s.QueueURL),
+			MaxNumberOfMessages: slots,
+			WaitTimeSeconds:     int32(time.Duration(s.WaitTime).Seconds()),
+		}
+		if s.VisibilityTimeout > 0 {
+			input.VisibilityTimeout = int32(time.Duration(s.VisibilityTimeout).Seconds())
+		}
+		var messages []types.Message
+		out, err := s.queue.ReceiveMessage(ctx, input)
+		if err == nil {
+			messages = out.Messages
+		}
+		for range int(slots) - len(messages) {
+			<-s.sem
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.receiveErrors.Incr(1)
+			delay := maxRetryInterval
+			if attempt < 32 && s.retryInterval<<attempt < maxRetryInterval {
+				delay = s.retryInterval << attempt
+			}
+			attempt++
+			s.Log.Errorf("Receiving messages failed: %v; retrying in %s", err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			continue
+		}
+		attempt = 0
+		s.messagesReceived.Incr(int64(len(messages)))
+
+		for _, msg := range messages {
+			s.onMessage(ctx, msg)
+		}
+	}
+}
+
+// onMessage reads all objects referenced by the message and adds the
+// resulting metrics as one tracking group. If reading any of the objects
+// fails, the message is kept so it is received again after the visibility
+// timeout.
+func (s *S3Consumer) onMessage(ctx context.Context, msg types.Message) {
+	receiptHandle := aws.ToString(msg.ReceiptHandle)
+
+	objects, err := parseEvent([]byte(aws.ToString(msg.Body)))
+	if err != nil {
+		<-s.sem
+		s.objectErrors.Incr(1)
+		s.Log.Errorf("Processing message %q failed: %v", aws.ToString(msg.MessageId), err)
+		return
+	}
+
+	var metrics []telegraf.Metric
+	for _, obj := range objects {
+		if s.keyFilter != nil && !s.keyFilter.Match(obj.key) {
+			s.Log.Tracef("Skipping object %q of bucket %q", obj.key, obj.bucket)
+			continue
+		}
+		ms, err := s.readObject(ctx, obj)
+		if err != nil {
+			<-s.sem
+			s.objectErrors.Incr(1)
+			s.Log.Errorf("Reading object %q of bucket %q failed: %v", obj.key, obj.bucket, err)
+			return
+		}
+		metrics = append(metrics, ms...)
+	}
+
+	// Messages without metrics, e.g. test events or skipped objects, are done
+	if len(metrics) == 0 {
+		if len(objects) > 0 {
+			once.Do(func() {
+				s.Log.Debug(internal.NoMetricsCreatedMsg)
+			})
+		}
+		<-s.sem
+		s.deleteMessage(ctx, receiptHandle)
+		return
+	}
+
+	s.pendingTex.Lock()
+	id := s.acc.AddTrackingMetricGroup(metrics)
+	s.pending[id] = receiptHandle
+	s.pendingTex.Unlock()
+}
+
+// readObject downloads, decodes and parses the given object
+func (s *S3Consumer) readObject(ctx context.Context, obj object) ([]telegraf.Metric, error) {
+	out, err := s.storage.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(obj.bucket),
+		Key:    aws.String(obj.key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	// Limit the download in case the object is not compressed
+	data, err := io.ReadAll(io.LimitReader(out.Body, int64(s.MaxObjectSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading failed: %w", err)
+	}
+	if int64(len(data)) > int64(s.MaxObjectSize) {
+		return nil, fmt.Errorf("object exceeds maximum size of %d bytes", s.MaxObjectSize)
+	}
+	s.objectsRead.Incr(1)
+	s.bytesRead.Incr(int64(len(data)))
+
+	decoder, err := internal.NewContentDecoder(s.ContentEncoding, internal.WithMaxDecompressionSize(int64(s.MaxObjectSize)))
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := decoder.(*internal.AutoDecoder); ok {
+		if aws.ToString(out.ContentEncoding) == "gzip" || strings.HasSuffix(obj.key, ".gz") {
+			d.SetEncoding("gzip")
+		}
+	}
+	data, err = decoder.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding failed: %w", err)
+	}
+
+	metrics, err := s.parser.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing failed: %w", err)
+	}
+
+	for _, item := range s.IncludeObjectMetadata {
+		for _, m := range metrics {
+			switch item {
+			case "bucket":
+				m.AddTag("bucket", obj.bucket)
+			case "key":
+				m.AddTag("key", obj.key)
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// onDelivery deletes the messages once the metrics of all their objects are
+// delivered. Undelivered messages are received again after the visibility
+// timeout.
+func (s *S3Consumer) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info := <-s.acc.Delivered():
+			s.pendingTex.Lock()
+			receiptHandle, found := s.pending[info.ID()]
+			if !found {
+				s.pendingTex.Unlock()
+				continue
+			}
+			delete(s.pending, info.ID())
+			s.pendingTex.Unlock()
+			<-s.sem
+
+			if !info.Delivered() {
+				s.Log.Debug("Metric group failed to process")
+				continue
+			}
+			s.deleteMessage(ctx, receiptHandle)
+		}
+	}
+}
+
+func (s *S3Consumer) deleteMessage(ctx context.Context, receiptHandle string) {
+	_, err := s.queue.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.QueueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	if err != nil {
+		s.deleteErrors.Incr(1)
+		s.Log.Errorf("Deleting message failed: %v", err)
+	}
+}
+
+func init() {
+	inputs.Add("s3_consumer", func() telegraf.Input {
+		return &S3Consumer{
+			MaxNumberOfMessages:    10,
+			WaitTime:               config.Duration(20 * time.Second),
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+			ContentEncoding:        "auto",
+		}
+	})
+}
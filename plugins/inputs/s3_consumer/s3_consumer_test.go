@@ -0,0 +1,327 @@
+package s3_consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const flowLogKey = "AWSLogs/123456789012/vpcflowlogs/us-east-1/2024/10/01/flow log=1.log.gz"
+
+// mockQueue serves the queued messages and records deleted messages
+type mockQueue struct {
+	messages []types.Message
+	deleted  []string
+	sync.Mutex
+}
+
+func (q *mockQueue) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	q.Lock()
+	n := min(int(params.MaxNumberOfMessages), len(q.messages))
+	messages := q.messages[:n]
+	q.messages = q.messages[n:]
+	q.Unlock()
+
+	if len(messages) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (q *mockQueue) DeleteMessage(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	q.Lock()
+	defer q.Unlock()
+	q.deleted = append(q.deleted, *params.ReceiptHandle)
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (q *mockQueue) getDeleted() []string {
+	q.Lock()
+	defer q.Unlock()
+	return append([]string(nil), q.deleted...)
+}
+
+// mockStorage serves the objects stored by bucket and key
+type mockStorage struct {
+	objects map[string][]byte
+}
+
+func (s *mockStorage) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, found := s.objects[*params.Bucket+"/"+*params.Key]
+	if !found {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func newMessage(t *testing.T, i int, filename string) types.Message {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", filename))
+	require.NoError(t, err)
+	return types.Message{
+		MessageId:     aws.String("msg-" + strconv.Itoa(i)),
+		ReceiptHandle: aws.String("handle-" + strconv.Itoa(i)),
+		Body:          aws.String(string(body)),
+	}
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestParseEvent(t *testing.T) {
+	expected := []object{{bucket: "logs", key: flowLogKey}}
+
+	tests := []struct {
+		filename string
+		expected []object
+	}{
+		{filename: "s3_event.json", expected: expected},
+		{filename: "sns_event.json", expected: expected},
+		{filename: "eventbridge_event.json", expected: expected},
+		{filename: "test_event.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.filename))
+			require.NoError(t, err)
+
+			actual, err := parseEvent(body)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDeleteAfterDelivery(t *testing.T) {
+	queue := &mockQueue{
+		messages: []types.Message{
+			newMessage(t, 0, "s3_event.json"),
+			newMessage(t, 1, "test_event.json"),
+		},
+	}
+	storage := &mockStorage{
+		objects: map[string][]byte{
+			"logs/" + flowLogKey: gzipped(t, "flow bytes=100i 1700000000000000000\nflow bytes=200i 1700000001000000000\n"),
+		},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &S3Consumer{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf-s3",
+		Log:      testutil.Logger{},
+		queue:    queue,
+		storage:  storage,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.IncludeObjectMetadata = []string{"bucket", "key"}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// The test event is deleted immediately
+	require.Eventually(t, func() bool {
+		return len(queue.getDeleted()) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"handle-1"}, queue.getDeleted())
+
+	tags := map[string]string{"bucket": "logs", "key": flowLogKey}
+	expected := []telegraf.Metric{
+		metric.New("flow", tags, map[string]interface{}{"bytes": int64(100)}, time.Unix(1700000000, 0)),
+		metric.New("flow", tags, map[string]interface{}{"bytes": int64(200)}, time.Unix(1700000001, 0)),
+	}
+	metrics := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// The message is deleted only after all metrics of the object are delivered
+	metrics[0].Accept()
+	require.Never(t, func() bool {
+		return len(queue.getDeleted()) > 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	metrics[1].Accept()
+	require.Eventually(t, func() bool {
+		return len(queue.getDeleted()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"handle-1", "handle-0"}, queue.getDeleted())
+}
+
+func TestObjectKeys(t *testing.T) {
+	queue := &mockQueue{messages: []types.Message{newMessage(t, 0, "s3_event.json")}}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &S3Consumer{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf-s3",
+		Log:      testutil.Logger{},
+		queue:    queue,
+		storage:  &mockStorage{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.ObjectKeys = []string{"AWSLogs/*/elasticloadbalancing/**"}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Notifications of objects not matching are deleted without reading the
+	// object
+	require.Eventually(t, func() bool {
+		return len(queue.getDeleted()) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Zero(t, acc.NMetrics())
+}
+
+func TestReadErrorKeepsMessage(t *testing.T) {
+	queue := &mockQueue{messages: []types.Message{newMessage(t, 0, "s3_event.json")}}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &S3Consumer{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf-s3",
+		Log:      testutil.Logger{},
+		queue:    queue,
+		storage:  &mockStorage{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	objectErrors := plugin.objectErrors.Get()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return plugin.objectErrors.Get() == objectErrors+1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Empty(t, queue.getDeleted())
+	require.Zero(t, acc.NMetrics())
+}
+
+func TestReadObject(t *testing.T) {
+	data := "flow bytes=100i 1700000000000000000\n"
+
+	tests := []struct {
+		name     string
+		key      string
+		data     []byte
+		encoding string
+		maxSize  int64
+		err      string
+	}{
+		{
+			name: "plain",
+			key:  "flow.log",
+			data: []byte(data),
+		},
+		{
+			name: "gzip by suffix",
+			key:  "flow.log.gz",
+			data: gzipped(t, data),
+		},
+		{
+			name:     "explicit gzip",
+			key:      "flow.log",
+			data:     gzipped(t, data),
+			encoding: "gzip",
+		},
+		{
+			name:    "too large",
+			key:     "flow.log",
+			data:    []byte(data),
+			maxSize: 10,
+			err:     "object exceeds maximum size of 10 bytes",
+		},
+		{
+			name:    "decompressed too large",
+			key:     "flow.log.gz",
+			data:    gzipped(t, strings.Repeat(data, 100)),
+			maxSize: 200,
+			err:     "decoding failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &mockStorage{objects: map[string][]byte{"logs/" + tt.key: tt.data}}
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+
+			plugin := &S3Consumer{
+				QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf-s3",
+				Log:      testutil.Logger{},
+				queue:    &mockQueue{},
+				storage:  storage,
+			}
+			plugin.SetParser(parser)
+			require.NoError(t, plugin.Init())
+			if tt.encoding != "" {
+				plugin.ContentEncoding = tt.encoding
+			}
+			if tt.maxSize > 0 {
+				plugin.MaxObjectSize = config.Size(tt.maxSize)
+			}
+
+			metrics, err := plugin.readObject(context.Background(), object{bucket: "logs", key: tt.key})
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, metrics, 1)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &S3Consumer{}
+	require.ErrorContains(t, plugin.Init(), "'queue_url' required")
+
+	plugin = &S3Consumer{QueueURL: "queue", IncludeObjectMetadata: []string{"size"}}
+	require.ErrorContains(t, plugin.Init(), `invalid object metadata "size"`)
+
+	plugin = &S3Consumer{QueueURL: "queue", ContentEncoding: "brotli"}
+	require.ErrorContains(t, plugin.Init(), `unknown content encoding "brotli"`)
+}
@@ -7,6 +7,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/hwmon"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -31,6 +33,8 @@ var (
 type Sensors struct {
 	RemoveNumbers bool            `toml:"remove_numbers"`
 	Timeout       config.Duration `toml:"timeout"`
+	Method        string          `toml:"method"`
+	Log           telegraf.Logger `toml:"-"`
 	path          string
 }
 
@@ -41,13 +45,32 @@ func (*Sensors) SampleConfig() string {
 }
 
 func (s *Sensors) Init() error {
-	// Set defaults
-	if s.path == "" {
-		path, err := exec.LookPath(cmd)
-		if err != nil {
-			return fmt.Errorf("looking up %q failed: %w", cmd, err)
+	switch s.Method {
+	case "", "auto":
+		// Prefer lm-sensors if installed for compatibility and fall back to
+		// reading the hwmon interface directly
+		if s.path == "" {
+			path, err := exec.LookPath(cmd)
+			if err != nil {
+				s.Log.Debugf("Looking up %q failed, using sysfs: %v", cmd, err)
+				s.Method = "sysfs"
+				return nil
+			}
+			s.path = path
 		}
-		s.path = path
+		s.Method = "lm-sensors"
+	case "lm-sensors":
+		if s.path == "" {
+			path, err := exec.LookPath(cmd)
+			if err != nil {
+				return fmt.Errorf("looking up %q failed: %w", cmd, err)
+			}
+			s.path = path
+		}
+	case "sysfs":
+		return nil
+	default:
+		return fmt.Errorf("invalid 'method' %q", s.Method)
 	}
 
 	// Check parameters
@@ -59,6 +82,10 @@ func (s *Sensors) Init() error {
 }
 
 func (s *Sensors) Gather(acc telegraf.Accumulator) error {
+	if s.Method == "sysfs" {
+		return s.gatherSysfs(acc)
+	}
+
 	if len(s.path) == 0 {
 		return errors.New("sensors not found: verify that lm-sensors package is installed and that sensors is in your PATH")
 	}
@@ -106,9 +133,7 @@ func (s *Sensors) parse(acc telegraf.Accumulator) error {
 		} else {
 			splitted := strings.Split(line, ":")
 			fieldName := strings.TrimSpace(splitted[0])
-			if s.RemoveNumbers {
-				fieldName = numberRegp.ReplaceAllString(fieldName, "")
-			}
+			fieldName = s.fieldName(fieldName)
 			fieldValue, err := strconv.ParseFloat(strings.TrimSpace(splitted[1]), 64)
 			if err != nil {
 				return err
@@ -120,6 +145,46 @@ func (s *Sensors) parse(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// gatherSysfs reads the sensors from the hwmon interface in sysfs and reports
+// them in the same format as lm-sensors.
+func (s *Sensors) gatherSysfs(acc telegraf.Accumulator) error {
+	// Honor the HOST_SYS environment variable
+	path := os.Getenv("HOST_SYS")
+	if path == "" {
+		path = "/sys"
+	}
+
+	sensors, err := hwmon.Gather(path)
+	if err != nil {
+		return fmt.Errorf("getting sensors failed: %w", err)
+	}
+
+	for _, sensor := range sensors {
+		feature := sensor.Name()
+		if sensor.Label != "" {
+			feature = snake(sensor.Label)
+		}
+		tags := map[string]string{
+			"chip":    sensor.ChipName(),
+			"feature": feature,
+		}
+		fields := make(map[string]interface{}, len(sensor.Values))
+		for attribute, value := range sensor.Values {
+			fields[s.fieldName(sensor.Name()+"_"+attribute)] = value
+		}
+		acc.AddFields("sensors", fields, tags)
+	}
+
+	return nil
+}
+
+func (s *Sensors) fieldName(name string) string {
+	if s.RemoveNumbers {
+		return numberRegp.ReplaceAllString(name, "")
+	}
+	return name
+}
+
 // snake converts string to snake case
 func snake(input string) string {
 	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input), " ", "_"))
@@ -0,0 +1,196 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build freebsd || openbsd
+
+package sensors
+
+import (
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var (
+	execCommand    = exec.Command // execCommand is used to mock commands in tests.
+	numberRegp     = regexp.MustCompile("[0-9]+")
+	defaultTimeout = config.Duration(5 * time.Second)
+)
+
+// Sensor types of OpenBSD mapped to the names used by lm-sensors
+var openbsdSensorTypes = map[string]string{
+	"temp":  "temp",
+	"fan":   "fan",
+	"volt":  "in",
+	"amps":  "curr",
+	"watts": "power",
+}
+
+type Sensors struct {
+	RemoveNumbers bool            `toml:"remove_numbers"`
+	Timeout       config.Duration `toml:"timeout"`
+	Method        string          `toml:"method"`
+	Log           telegraf.Logger `toml:"-"`
+	path          string
+}
+
+const cmd = "sysctl"
+
+func (*Sensors) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sensors) Init() error {
+	if s.Method != "" && s.Method != "auto" {
+		s.Log.Warn("Ignoring 'method' on non-Linux platforms!")
+	}
+
+	if s.path == "" {
+		path, err := exec.LookPath(cmd)
+		if err != nil {
+			return fmt.Errorf("looking up %q failed: %w", cmd, err)
+		}
+		s.path = path
+	}
+	return nil
+}
+
+// Gather forks the command
+//
+//	sysctl -i dev.cpu hw.acpi.thermal  (FreeBSD)
+//	sysctl hw.sensors                  (OpenBSD)
+//
+// and parses the output to add it to the telegraf.Accumulator.
+func (s *Sensors) Gather(acc telegraf.Accumulator) error {
+	args := []string{"hw.sensors"}
+	if runtime.GOOS == "freebsd" {
+		args = []string{"-i", "dev.cpu", "hw.acpi.thermal"}
+	}
+
+	cmd := execCommand(s.path, args...)
+	out, err := internal.StdOutputTimeout(cmd, time.Duration(s.Timeout))
+	if err != nil {
+		return fmt.Errorf("failed to run command %q: %w - %s", strings.Join(cmd.Args, " "), err, string(out))
+	}
+
+	if runtime.GOOS == "freebsd" {
+		s.parseFreeBSD(acc, string(out))
+	} else {
+		s.parseOpenBSD(acc, string(out))
+	}
+	return nil
+}
+
+// parseFreeBSD parses the temperatures of the CPU cores and the ACPI thermal
+// zones given as
+//
+//	dev.cpu.0.temperature: 45.0C
+//	hw.acpi.thermal.tz0.temperature: 27.9C
+func (s *Sensors) parseFreeBSD(acc telegraf.Accumulator, out string) {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, value, found := strings.Cut(line, ": ")
+		if !found || !strings.HasSuffix(name, ".temperature") || !strings.HasSuffix(value, "C") {
+			continue
+		}
+		temperature, err := strconv.ParseFloat(strings.TrimSuffix(value, "C"), 64)
+		if err != nil {
+			continue
+		}
+
+		var chip string
+		parts := strings.Split(name, ".")
+		switch {
+		case len(parts) == 4 && parts[0] == "dev" && parts[1] == "cpu":
+			chip = "cpu" + parts[2]
+		case len(parts) == 5 && parts[0] == "hw" && parts[1] == "acpi" && parts[2] == "thermal":
+			chip = "acpi_" + parts[3]
+		default:
+			continue
+		}
+
+		tags := map[string]string{
+			"chip":    chip,
+			"feature": "temp1",
+		}
+		acc.AddFields("sensors", map[string]interface{}{s.fieldName("temp1_input"): temperature}, tags)
+	}
+}
+
+// parseOpenBSD parses the sensors framework output given as
+//
+//	hw.sensors.cpu0.temp0=48.00 degC
+//	hw.sensors.it0.fan1=2234 RPM
+//	hw.sensors.it0.volt0=1.25 VDC (VCORE_A)
+//
+// Sensors with non-numeric values such as drive states are skipped.
+func (s *Sensors) parseOpenBSD(acc telegraf.Accumulator, out string) {
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		parts := strings.Split(name, ".")
+		if len(parts) != 4 || parts[0] != "hw" || parts[1] != "sensors" {
+			continue
+		}
+		chip, sensor := parts[2], parts[3]
+
+		sensorType := strings.TrimRight(sensor, "0123456789")
+		typeName, ok := openbsdSensorTypes[sensorType]
+		if !ok {
+			continue
+		}
+		index := strings.TrimPrefix(sensor, sensorType)
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+
+		tags := map[string]string{
+			"chip":    chip,
+			"feature": sensor,
+		}
+		// Use the description of the sensor as feature name if present
+		if start := strings.Index(value, "("); start >= 0 && strings.HasSuffix(value, ")") {
+			tags["feature"] = snake(value[start+1 : len(value)-1])
+		}
+		acc.AddFields("sensors", map[string]interface{}{s.fieldName(typeName + index + "_input"): v}, tags)
+	}
+}
+
+func (s *Sensors) fieldName(name string) string {
+	if s.RemoveNumbers {
+		return numberRegp.ReplaceAllString(name, "")
+	}
+	return name
+}
+
+// snake converts string to snake case
+func snake(input string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(input), " ", "_"))
+}
+
+func init() {
+	inputs.Add("sensors", func() telegraf.Input {
+		return &Sensors{
+			RemoveNumbers: true,
+			Timeout:       defaultTimeout,
+		}
+	})
+}
@@ -0,0 +1,51 @@
+//go:build freebsd || openbsd
+
+package sensors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseFreeBSD(t *testing.T) {
+	out := `dev.cpu.0.temperature: 45.0C
+dev.cpu.0.freq: 2400
+dev.cpu.1.temperature: 47.5C
+hw.acpi.thermal.tz0.temperature: 27.9C
+hw.acpi.thermal.tz0._CRT: 100.0C
+hw.acpi.thermal.polling_rate: 10
+`
+	expected := []telegraf.Metric{
+		metric.New("sensors", map[string]string{"chip": "cpu0", "feature": "temp1"}, map[string]interface{}{"temp_input": 45.0}, time.Unix(0, 0)),
+		metric.New("sensors", map[string]string{"chip": "cpu1", "feature": "temp1"}, map[string]interface{}{"temp_input": 47.5}, time.Unix(0, 0)),
+		metric.New("sensors", map[string]string{"chip": "acpi_tz0", "feature": "temp1"}, map[string]interface{}{"temp_input": 27.9}, time.Unix(0, 0)),
+	}
+
+	s := &Sensors{RemoveNumbers: true}
+	var acc testutil.Accumulator
+	s.parseFreeBSD(&acc, out)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseOpenBSD(t *testing.T) {
+	out := `hw.sensors.cpu0.temp0=48.00 degC
+hw.sensors.it0.fan1=2234 RPM
+hw.sensors.it0.volt0=1.25 VDC (VCORE_A)
+hw.sensors.softraid0.drive0=online (sd1), OK
+hw.sensors.acpibat0.raw0=0 (battery idle), OK
+`
+	expected := []telegraf.Metric{
+		metric.New("sensors", map[string]string{"chip": "cpu0", "feature": "temp0"}, map[string]interface{}{"temp0_input": 48.0}, time.Unix(0, 0)),
+		metric.New("sensors", map[string]string{"chip": "it0", "feature": "fan1"}, map[string]interface{}{"fan1_input": 2234.0}, time.Unix(0, 0)),
+		metric.New("sensors", map[string]string{"chip": "it0", "feature": "vcore_a"}, map[string]interface{}{"in0_input": 1.25}, time.Unix(0, 0)),
+	}
+
+	s := &Sensors{RemoveNumbers: false}
+	var acc testutil.Accumulator
+	s.parseOpenBSD(&acc, out)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
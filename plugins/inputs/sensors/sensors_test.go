@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -290,6 +294,65 @@ func TestGatherNotRemoveNumbers(t *testing.T) {
 	}
 }
 
+func TestGatherSysfs(t *testing.T) {
+	t.Setenv("HOST_SYS", filepath.Join("testdata", "sys"))
+
+	s := Sensors{
+		RemoveNumbers: true,
+		Method:        "sysfs",
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"sensors",
+			map[string]string{
+				"chip":    "coretemp-isa-0000",
+				"feature": "package_id_0",
+			},
+			map[string]interface{}{
+				"temp_input":      77.0,
+				"temp_max":        82.0,
+				"temp_crit":       92.0,
+				"temp_crit_alarm": 0.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{
+				"chip":    "coretemp-isa-0000",
+				"feature": "core_0",
+			},
+			map[string]interface{}{
+				"temp_input": 75.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"sensors",
+			map[string]string{
+				"chip":    "rpi_volt-virtual-0",
+				"feature": "under-voltage",
+			},
+			map[string]interface{}{
+				"in_lcrit_alarm": 0.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInvalidMethod(t *testing.T) {
+	s := Sensors{Method: "foo", Log: &testutil.Logger{}}
+	require.ErrorContains(t, s.Init(), "invalid 'method'")
+}
+
 // fackeExecCommand is a helper function that mock
 // the exec.Command call (and call the test binary)
 func fakeExecCommand(command string, args ...string) *exec.Cmd {
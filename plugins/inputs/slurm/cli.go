@@ -0,0 +1,259 @@
+package slurm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Output formats used for the command line tools, fields are separated by
+// a pipe character as it cannot occur in partition, node or state names.
+const (
+	squeueFormat         = "%P|%T|%C|%D"
+	sinfoPartitionFormat = "%R|%a|%F|%C"
+	sinfoNodeFormat      = "%N|%T|%C|%m|%e"
+)
+
+type queueKey struct {
+	partition string
+	state     string
+}
+
+type queueStats struct {
+	jobs  int64
+	cpus  int64
+	nodes int64
+}
+
+func (s *Slurm) gatherCLI(acc telegraf.Accumulator) error {
+	if s.endpointMap["queue"] {
+		out, err := s.run(s.SqueueBinary, "--all", "--noheader", "--format="+squeueFormat)
+		if err != nil {
+			return fmt.Errorf("error getting queue: %w", err)
+		}
+		stats, err := parseSqueue(out)
+		if err != nil {
+			return fmt.Errorf("error parsing queue: %w", err)
+		}
+		addQueueMetrics(acc, stats, nil)
+	}
+
+	if s.endpointMap["nodes"] {
+		out, err := s.run(s.SinfoBinary, "--all", "--noheader", "--Node", "--format="+sinfoNodeFormat)
+		if err != nil {
+			return fmt.Errorf("error getting nodes: %w", err)
+		}
+		if err := gatherSinfoNodes(acc, out); err != nil {
+			return fmt.Errorf("error parsing nodes: %w", err)
+		}
+	}
+
+	if s.endpointMap["partitions"] {
+		out, err := s.run(s.SinfoBinary, "--all", "--noheader", "--format="+sinfoPartitionFormat)
+		if err != nil {
+			return fmt.Errorf("error getting partitions: %w", err)
+		}
+		if err := gatherSinfoPartitions(acc, out); err != nil {
+			return fmt.Errorf("error parsing partitions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func parseSqueue(out []byte) (map[queueKey]*queueStats, error) {
+	stats := make(map[queueKey]*queueStats)
+	err := forEachRecord(out, 4, func(parts []string) error {
+		cpus, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid CPU count %q: %w", parts[2], err)
+		}
+		nodes, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid node count %q: %w", parts[3], err)
+		}
+
+		// Jobs submitted to multiple partitions are listed comma-separated
+		// while pending, account them for each of the partitions
+		for _, partition := range strings.Split(parts[0], ",") {
+			key := queueKey{partition: partition, state: parts[1]}
+			if _, found := stats[key]; !found {
+				stats[key] = &queueStats{}
+			}
+			stats[key].jobs++
+			stats[key].cpus += cpus
+			stats[key].nodes += nodes
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func addQueueMetrics(acc telegraf.Accumulator, stats map[queueKey]*queueStats, tags map[string]string) {
+	for key, v := range stats {
+		t := make(map[string]string, len(tags)+2)
+		for k, v := range tags {
+			t[k] = v
+		}
+		t["partition"] = key.partition
+		t["state"] = key.state
+
+		fields := map[string]interface{}{
+			"jobs":  v.jobs,
+			"cpus":  v.cpus,
+			"nodes": v.nodes,
+		}
+		acc.AddFields("slurm_queue", fields, t)
+	}
+}
+
+func gatherSinfoNodes(acc telegraf.Accumulator, out []byte) error {
+	// Nodes belonging to multiple partitions are listed once per partition
+	seen := make(map[string]bool)
+	return forEachRecord(out, 5, func(parts []string) error {
+		if seen[parts[0]] {
+			return nil
+		}
+		seen[parts[0]] = true
+
+		cpus, err := parseCounts(parts[2])
+		if err != nil {
+			return fmt.Errorf("invalid CPU counts %q: %w", parts[2], err)
+		}
+
+		records := map[string]interface{}{
+			"state":     parts[1],
+			"alloc_cpu": cpus[0],
+			"idle_cpu":  cpus[1],
+			"cpus":      cpus[3],
+		}
+		if v, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			records["real_memory"] = v
+		}
+		if v, err := strconv.ParseInt(parts[4], 10, 64); err == nil {
+			records["free_memory"] = v
+		}
+
+		acc.AddFields("slurm_nodes", records, map[string]string{"name": parts[0]})
+		return nil
+	})
+}
+
+func gatherSinfoPartitions(acc telegraf.Accumulator, out []byte) error {
+	type partition struct {
+		state string
+		nodes [4]int64
+		cpus  [4]int64
+	}
+
+	// The output contains one line per partition and node state, so sum up
+	// the counts of all lines of a partition
+	var order []string
+	partitions := make(map[string]*partition)
+	err := forEachRecord(out, 4, func(parts []string) error {
+		nodes, err := parseCounts(parts[2])
+		if err != nil {
+			return fmt.Errorf("invalid node counts %q: %w", parts[2], err)
+		}
+		cpus, err := parseCounts(parts[3])
+		if err != nil {
+			return fmt.Errorf("invalid CPU counts %q: %w", parts[3], err)
+		}
+
+		p, found := partitions[parts[0]]
+		if !found {
+			p = &partition{state: strings.ToUpper(parts[1])}
+			partitions[parts[0]] = p
+			order = append(order, parts[0])
+		}
+		for i := range p.nodes {
+			p.nodes[i] += nodes[i]
+			p.cpus[i] += cpus[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		p := partitions[name]
+		records := map[string]interface{}{
+			"state":       p.state,
+			"alloc_nodes": p.nodes[0],
+			"idle_nodes":  p.nodes[1],
+			"other_nodes": p.nodes[2],
+			"total_nodes": p.nodes[3],
+			"alloc_cpu":   p.cpus[0],
+			"idle_cpu":    p.cpus[1],
+			"other_cpu":   p.cpus[2],
+			"total_cpu":   p.cpus[3],
+		}
+		if p.cpus[3] > 0 {
+			records["cpu_utilization"] = 100 * float64(p.cpus[0]) / float64(p.cpus[3])
+		}
+		acc.AddFields("slurm_partitions", records, map[string]string{"name": name})
+	}
+	return nil
+}
+
+// parseCounts parses the "allocated/idle/other/total" notation used by sinfo
+func parseCounts(s string) ([4]int64, error) {
+	var counts [4]int64
+	parts := strings.Split(s, "/")
+	if len(parts) != len(counts) {
+		return counts, fmt.Errorf("expected %d values but got %d", len(counts), len(parts))
+	}
+	for i, p := range parts {
+		v, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return counts, err
+		}
+		counts[i] = v
+	}
+	return counts, nil
+}
+
+// forEachRecord calls the given function for every non-empty line of the
+// output after splitting it into the expected number of fields
+func forEachRecord(out []byte, n int, fn func(parts []string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, "|")
+		if len(parts) != n {
+			return fmt.Errorf("unexpected line %q", line)
+		}
+		if err := fn(parts); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Slurm) execute(binary string, args ...string) ([]byte, error) {
+	cmd := exec.Command(binary, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	var err error
+	if timeout := time.Duration(s.ResponseTimeout); timeout > 0 {
+		err = internal.RunTimeout(cmd, timeout)
+	} else {
+		err = cmd.Run()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running %q failed: %w", strings.Join(cmd.Args, " "), err)
+	}
+	return out.Bytes(), nil
+}
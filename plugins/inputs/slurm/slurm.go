@@ -26,9 +26,12 @@ import (
 var sampleConfig string
 
 type Slurm struct {
+	Method           string          `toml:"method"`
 	URL              string          `toml:"url"`
 	Username         string          `toml:"username"`
 	Token            string          `toml:"token"`
+	SqueueBinary     string          `toml:"squeue_binary"`
+	SinfoBinary      string          `toml:"sinfo_binary"`
 	EnabledEndpoints []string        `toml:"enabled_endpoints"`
 	ResponseTimeout  config.Duration `toml:"response_timeout"`
 	Log              telegraf.Logger `toml:"-"`
@@ -37,6 +40,7 @@ type Slurm struct {
 	client      *goslurm.APIClient
 	baseURL     *url.URL
 	endpointMap map[string]bool
+	run         func(binary string, args ...string) ([]byte, error)
 }
 
 func (*Slurm) SampleConfig() string {
@@ -44,6 +48,15 @@ func (*Slurm) SampleConfig() string {
 }
 
 func (s *Slurm) Init() error {
+	switch s.Method {
+	case "", "rest":
+		s.Method = "rest"
+	case "cli":
+		return s.initCLI()
+	default:
+		return fmt.Errorf("invalid method %q", s.Method)
+	}
+
 	if len(s.EnabledEndpoints) == 0 {
 		s.EnabledEndpoints = []string{"diag", "jobs", "nodes", "partitions", "reservations"}
 	}
@@ -51,7 +64,7 @@ func (s *Slurm) Init() error {
 	s.endpointMap = make(map[string]bool, len(s.EnabledEndpoints))
 	for _, endpoint := range s.EnabledEndpoints {
 		switch e := strings.ToLower(endpoint); e {
-		case "diag", "jobs", "nodes", "partitions", "reservations":
+		case "diag", "jobs", "nodes", "partitions", "queue", "reservations":
 			s.endpointMap[e] = true
 		default:
 			return fmt.Errorf("unknown endpoint %q", endpoint)
@@ -103,6 +116,34 @@ func (s *Slurm) Init() error {
 	return nil
 }
 
+func (s *Slurm) initCLI() error {
+	if len(s.EnabledEndpoints) == 0 {
+		s.EnabledEndpoints = []string{"nodes", "partitions", "queue"}
+	}
+
+	s.endpointMap = make(map[string]bool, len(s.EnabledEndpoints))
+	for _, endpoint := range s.EnabledEndpoints {
+		switch e := strings.ToLower(endpoint); e {
+		case "nodes", "partitions", "queue":
+			s.endpointMap[e] = true
+		case "diag", "jobs", "reservations":
+			return fmt.Errorf("endpoint %q not supported by the %q method", endpoint, s.Method)
+		default:
+			return fmt.Errorf("unknown endpoint %q", endpoint)
+		}
+	}
+
+	if s.SqueueBinary == "" {
+		s.SqueueBinary = "squeue"
+	}
+	if s.SinfoBinary == "" {
+		s.SinfoBinary = "sinfo"
+	}
+	s.run = s.execute
+
+	return nil
+}
+
 func (s *Slurm) parseTres(tres string) map[string]interface{} {
 	tresKVs := strings.Split(tres, ",")
 	parsedValues := make(map[string]interface{}, len(tresKVs))
@@ -267,6 +308,24 @@ func (s *Slurm) gatherJobsMetrics(acc telegraf.Accumulator, jobs []goslurm.V0038
 	}
 }
 
+func (s *Slurm) gatherQueueMetrics(acc telegraf.Accumulator, jobs []goslurm.V0038JobResponseProperties) {
+	stats := make(map[queueKey]*queueStats)
+	for i := range jobs {
+		key := queueKey{
+			partition: jobs[i].GetPartition(),
+			state:     jobs[i].GetJobState(),
+		}
+		if _, found := stats[key]; !found {
+			stats[key] = &queueStats{}
+		}
+		stats[key].jobs++
+		stats[key].cpus += int64(jobs[i].GetCpus())
+		stats[key].nodes += int64(jobs[i].GetNodeCount())
+	}
+
+	addQueueMetrics(acc, stats, map[string]string{"source": s.baseURL.Hostname()})
+}
+
 func (s *Slurm) gatherNodesMetrics(acc telegraf.Accumulator, nodes []goslurm.V0038Node) {
 	for _, node := range nodes {
 		records := make(map[string]interface{}, 13)
@@ -400,6 +459,10 @@ func (s *Slurm) gatherReservationsMetrics(acc telegraf.Accumulator, reservations
 }
 
 func (s *Slurm) Gather(acc telegraf.Accumulator) (err error) {
+	if s.Method == "cli" {
+		return s.gatherCLI(acc)
+	}
+
 	auth := context.WithValue(
 		context.Background(),
 		goslurm.ContextAPIKeys,
@@ -420,13 +483,18 @@ func (s *Slurm) Gather(acc telegraf.Accumulator) (err error) {
 		respRaw.Body.Close()
 	}
 
-	if s.endpointMap["jobs"] {
+	if s.endpointMap["jobs"] || s.endpointMap["queue"] {
 		jobsResp, respRaw, err := s.client.SlurmAPI.SlurmV0038GetJobs(auth).Execute()
 		if err != nil {
 			return fmt.Errorf("error getting jobs: %w", err)
 		}
 		if jobs, ok := jobsResp.GetJobsOk(); ok {
-			s.gatherJobsMetrics(acc, jobs)
+			if s.endpointMap["jobs"] {
+				s.gatherJobsMetrics(acc, jobs)
+			}
+			if s.endpointMap["queue"] {
+				s.gatherQueueMetrics(acc, jobs)
+			}
 		}
 		respRaw.Body.Close()
 	}
@@ -1,15 +1,19 @@
 package slurm
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -158,3 +162,156 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestCLI(t *testing.T) {
+	outputs := map[string]string{
+		"squeue":       "squeue.txt",
+		"sinfo --Node": "sinfo_nodes.txt",
+		"sinfo":        "sinfo_partitions.txt",
+	}
+
+	plugin := &Slurm{
+		Method: "cli",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.run = func(binary string, args ...string) ([]byte, error) {
+		key := binary
+		if slices.Contains(args, "--Node") {
+			key += " --Node"
+		}
+		fn, found := outputs[key]
+		if !found {
+			return nil, fmt.Errorf("unexpected command %s %v", binary, args)
+		}
+		return os.ReadFile(filepath.Join("testdata", fn))
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"slurm_queue",
+			map[string]string{"partition": "atlas", "state": "RUNNING"},
+			map[string]interface{}{"jobs": int64(2), "cpus": int64(4), "nodes": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_queue",
+			map[string]string{"partition": "atlas", "state": "PENDING"},
+			map[string]interface{}{"jobs": int64(2), "cpus": int64(12), "nodes": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_queue",
+			map[string]string{"partition": "debug", "state": "PENDING"},
+			map[string]interface{}{"jobs": int64(1), "cpus": int64(4), "nodes": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_queue",
+			map[string]string{"partition": "debug", "state": "RUNNING"},
+			map[string]interface{}{"jobs": int64(1), "cpus": int64(16), "nodes": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_nodes",
+			map[string]string{"name": "naboo145"},
+			map[string]interface{}{
+				"state":       "idle",
+				"cpus":        int64(36),
+				"alloc_cpu":   int64(0),
+				"idle_cpu":    int64(36),
+				"real_memory": int64(94791),
+				"free_memory": int64(86450),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_nodes",
+			map[string]string{"name": "naboo147"},
+			map[string]interface{}{
+				"state":       "allocated",
+				"cpus":        int64(36),
+				"alloc_cpu":   int64(36),
+				"idle_cpu":    int64(0),
+				"real_memory": int64(94793),
+				"free_memory": int64(1607),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_nodes",
+			map[string]string{"name": "naboo216"},
+			map[string]interface{}{
+				"state":       "mixed",
+				"cpus":        int64(8),
+				"alloc_cpu":   int64(4),
+				"idle_cpu":    int64(4),
+				"real_memory": int64(31877),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_nodes",
+			map[string]string{"name": "naboo219"},
+			map[string]interface{}{
+				"state":       "down",
+				"cpus":        int64(16),
+				"alloc_cpu":   int64(0),
+				"idle_cpu":    int64(0),
+				"real_memory": int64(31875),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_partitions",
+			map[string]string{"name": "atlas"},
+			map[string]interface{}{
+				"state":           "UP",
+				"total_cpu":       int64(80),
+				"alloc_cpu":       int64(40),
+				"idle_cpu":        int64(40),
+				"other_cpu":       int64(0),
+				"cpu_utilization": float64(50),
+				"total_nodes":     int64(3),
+				"alloc_nodes":     int64(2),
+				"idle_nodes":      int64(1),
+				"other_nodes":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"slurm_partitions",
+			map[string]string{"name": "debug"},
+			map[string]interface{}{
+				"state":           "DOWN",
+				"total_cpu":       int64(16),
+				"alloc_cpu":       int64(0),
+				"idle_cpu":        int64(0),
+				"other_cpu":       int64(16),
+				"cpu_utilization": float64(0),
+				"total_nodes":     int64(1),
+				"alloc_nodes":     int64(0),
+				"idle_nodes":      int64(0),
+				"other_nodes":     int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestCLIInvalidEndpoints(t *testing.T) {
+	for _, endpoint := range []string{"diag", "jobs", "reservations", "foo"} {
+		t.Run(endpoint, func(t *testing.T) {
+			plugin := &Slurm{
+				Method:           "cli",
+				EnabledEndpoints: []string{endpoint},
+			}
+			require.Error(t, plugin.Init())
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -222,12 +223,14 @@ type SQL struct {
 	MaxLifetime                 config.Duration `toml:"connection_max_life_time"`
 	MaxOpenConnections          int             `toml:"connection_max_open"`
 	MaxIdleConnections          int             `toml:"connection_max_idle"`
+	HealthCheckQuery            string          `toml:"health_check_query"`
 	Queries                     []Query         `toml:"query"`
 	Log                         telegraf.Logger `toml:"-"`
 	DisconnectedServersBehavior string          `toml:"disconnected_servers_behavior"`
+	common_sql.AuthConfig
 
 	driverName      string
-	db              *dbsql.DB
+	db              *common_sql.Pool
 	serverConnected bool
 }
 
@@ -364,6 +367,13 @@ func (s *SQL) Init() error {
 		return fmt.Errorf("driver %q not supported use one of %v", s.Driver, availDrivers)
 	}
 
+	if err := s.AuthConfig.Init(); err != nil {
+		return err
+	}
+	if err := s.AuthConfig.CheckDriver(s.driverName); err != nil {
+		return err
+	}
+
 	if s.DisconnectedServersBehavior == "" {
 		s.DisconnectedServersBehavior = "error"
 	}
@@ -385,27 +395,23 @@ func (s *SQL) setupConnection() error {
 	dsnSecret.Destroy()
 
 	s.Log.Debug("Connecting...")
-	s.db, err = dbsql.Open(s.driverName, dsn)
-	if err != nil {
-		// should return since the error is most likely with invalid DSN string format
-		return err
-	}
-
-	// Set the connection limits
-	// s.db.SetConnMaxIdleTime(time.Duration(s.MaxIdleTime)) // Requires go >= 1.15
-	s.db.SetConnMaxLifetime(time.Duration(s.MaxLifetime))
-	s.db.SetMaxOpenConns(s.MaxOpenConnections)
-	s.db.SetMaxIdleConns(s.MaxIdleConnections)
-	return nil
+	s.db, err = common_sql.Open(s.driverName, dsn, common_sql.PoolConfig{
+		MaxOpen:          s.MaxOpenConnections,
+		MaxIdle:          s.MaxIdleConnections,
+		MaxLifetime:      time.Duration(s.MaxLifetime),
+		MaxIdleTime:      time.Duration(s.MaxIdleTime),
+		HealthCheckQuery: s.HealthCheckQuery,
+		Timeout:          time.Duration(s.Timeout),
+		Auth:             &s.AuthConfig,
+	})
+	// should return since the error is most likely with invalid DSN string format
+	return err
 }
 
 func (s *SQL) ping() error {
 	// Test if the connection can be established
 	s.Log.Debug("Testing connectivity...")
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
-	err := s.db.PingContext(ctx)
-	cancel()
-	if err != nil {
+	if err := s.db.Check(context.Background()); err != nil {
 		return fmt.Errorf("unable to connect to database: %w", err)
 	}
 	s.serverConnected = true
@@ -416,7 +422,7 @@ func (s *SQL) prepareStatements() {
 	// Prepare the statements
 	for i, q := range s.Queries {
 		s.Log.Debugf("Preparing statement %q...", q.Query)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+		ctx, cancel := s.db.WithTimeout(context.Background())
 		stmt, err := s.db.PrepareContext(ctx, q.Query)
 		cancel()
 		if err != nil {
@@ -485,7 +491,7 @@ func (s *SQL) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(q Query) {
 			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+			ctx, cancel := s.db.WithTimeout(context.Background())
 			defer cancel()
 			if err := s.executeQuery(ctx, acc, q, tstart); err != nil {
 				acc.AddError(err)
@@ -522,7 +528,7 @@ func (s *SQL) executeQuery(ctx context.Context, acc telegraf.Accumulator, q Quer
 	} else {
 		// Fallback to unprepared query
 		var err error
-		rows, err = s.db.Query(q.Query)
+		rows, err = s.db.QueryContext(ctx, q.Query)
 		if err != nil {
 			return err
 		}
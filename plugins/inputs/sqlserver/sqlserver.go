@@ -118,6 +118,10 @@ func (s *SQLServer) initQueries() error {
 		queries["AzureSQLMIPerformanceCounters"] = Query{ScriptName: "AzureSQLMIPerformanceCounters", Script: sqlAzureMIPerformanceCounters, ResultByRow: false}
 		queries["AzureSQLMIRequests"] = Query{ScriptName: "AzureSQLMIRequests", Script: sqlAzureMIRequests, ResultByRow: false}
 		queries["AzureSQLMISchedulers"] = Query{ScriptName: "AzureSQLMISchedulers", Script: sqlAzureMISchedulers, ResultByRow: false}
+		queries["AzureSQLMIQueryStoreTopQueries"] =
+			Query{ScriptName: "AzureSQLMIQueryStoreTopQueries", Script: sqlAzureMIQueryStoreTopQueries, ResultByRow: false}
+		queries["AzureSQLMIReplicaLatency"] = Query{ScriptName: "AzureSQLMIReplicaLatency", Script: sqlAzureMIReplicaLatency, ResultByRow: false}
+		queries["AzureSQLMITempdbContention"] = Query{ScriptName: "AzureSQLMITempdbContention", Script: sqlAzureMITempdbContention, ResultByRow: false}
 	} else if s.DatabaseType == typeAzureSQLPool {
 		queries["AzureSQLPoolResourceStats"] = Query{ScriptName: "AzureSQLPoolResourceStats", Script: sqlAzurePoolResourceStats, ResultByRow: false}
 		queries["AzureSQLPoolResourceGovernance"] =
@@ -154,6 +158,11 @@ func (s *SQLServer) initQueries() error {
 		queries["SQLServerRecentBackups"] = Query{ScriptName: "SQLServerRecentBackups", Script: sqlServerRecentBackups, ResultByRow: false}
 		queries["SQLServerPersistentVersionStore"] =
 			Query{ScriptName: "SQLServerPersistentVersionStore", Script: sqlServerPersistentVersionStore, ResultByRow: false}
+		queries["SQLServerQueryStoreTopQueries"] =
+			Query{ScriptName: "SQLServerQueryStoreTopQueries", Script: sqlServerQueryStoreTopQueries, ResultByRow: false}
+		queries["SQLServerAvailabilityGroupLatency"] =
+			Query{ScriptName: "SQLServerAvailabilityGroupLatency", Script: sqlServerAvailabilityGroupLatency, ResultByRow: false}
+		queries["SQLServerTempdbContention"] = Query{ScriptName: "SQLServerTempdbContention", Script: sqlServerTempdbContention, ResultByRow: false}
 	} else {
 		// If this is an AzureDB instance, grab some extra metrics
 		if s.AzureDB {
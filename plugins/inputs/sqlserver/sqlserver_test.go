@@ -44,6 +44,36 @@ func TestSqlServer_QueriesInclusionExclusion(t *testing.T) {
 	}
 }
 
+func TestSqlServer_QueriesInclusionDatabaseType(t *testing.T) {
+	tests := []struct {
+		databaseType string
+		include      []string
+	}{
+		{
+			databaseType: "SQLServer",
+			include:      []string{"SQLServerQueryStoreTopQueries", "SQLServerAvailabilityGroupLatency", "SQLServerTempdbContention"},
+		},
+		{
+			databaseType: "AzureSQLManagedInstance",
+			include:      []string{"AzureSQLMIQueryStoreTopQueries", "AzureSQLMIReplicaLatency", "AzureSQLMITempdbContention"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.databaseType, func(t *testing.T) {
+			s := SQLServer{
+				DatabaseType: tt.databaseType,
+				IncludeQuery: tt.include,
+				Log:          testutil.Logger{},
+			}
+			require.NoError(t, s.initQueries())
+			require.Len(t, s.queries, len(tt.include))
+			for _, query := range tt.include {
+				require.Contains(t, s.queries, query)
+			}
+		})
+	}
+}
+
 func TestSqlServer_ParseMetrics(t *testing.T) {
 	var acc testutil.Accumulator
 
@@ -1480,3 +1480,158 @@ IF @MajorMinorVersion >= 1500 BEGIN
 	    and d.is_accelerated_database_recovery_on = 1
 END;
 `
+
+const sqlServerQueryStoreTopQueries string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterprise,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+DECLARE
+	 @SqlStatement AS nvarchar(max) = N''
+	,@MajorMinorVersion AS int = CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int)*100 + CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),3) AS int)
+
+/*Query Store is available from SQL Server 2016*/
+IF @MajorMinorVersion < 1300 BEGIN
+	RETURN
+END
+
+CREATE TABLE #query_store (
+	 [database_name] nvarchar(128)
+	,[query_id] bigint
+	,[query_hash] varchar(18)
+	,[plan_count] int
+	,[execution_count] bigint
+	,[total_duration_us] float
+	,[max_duration_us] bigint
+	,[total_cpu_time_us] float
+	,[total_logical_io_reads] float
+	,[total_physical_io_reads] float
+	,[total_rowcount] float
+)
+
+/*Top 10 queries by CPU time of the last hour for each database with Query Store enabled*/
+SELECT @SqlStatement += N'
+INSERT INTO #query_store
+SELECT TOP 10
+	 ' + QUOTENAME([name], '''') + N'
+	,q.[query_id]
+	,CONVERT(varchar(18), q.[query_hash], 1)
+	,COUNT(DISTINCT p.[plan_id])
+	,SUM(rs.[count_executions])
+	,SUM(rs.[avg_duration] * rs.[count_executions])
+	,MAX(rs.[max_duration])
+	,SUM(rs.[avg_cpu_time] * rs.[count_executions])
+	,SUM(rs.[avg_logical_io_reads] * rs.[count_executions])
+	,SUM(rs.[avg_physical_io_reads] * rs.[count_executions])
+	,SUM(rs.[avg_rowcount] * rs.[count_executions])
+FROM ' + QUOTENAME([name]) + N'.sys.query_store_runtime_stats AS rs
+INNER JOIN ' + QUOTENAME([name]) + N'.sys.query_store_runtime_stats_interval AS rsi
+	ON rsi.[runtime_stats_interval_id] = rs.[runtime_stats_interval_id]
+INNER JOIN ' + QUOTENAME([name]) + N'.sys.query_store_plan AS p
+	ON p.[plan_id] = rs.[plan_id]
+INNER JOIN ' + QUOTENAME([name]) + N'.sys.query_store_query AS q
+	ON q.[query_id] = p.[query_id]
+WHERE rsi.[end_time] > DATEADD(HOUR, -1, SYSUTCDATETIME())
+GROUP BY q.[query_id], q.[query_hash]
+ORDER BY SUM(rs.[avg_cpu_time] * rs.[count_executions]) DESC;'
+FROM sys.databases
+WHERE [is_query_store_on] = 1 AND [state] = 0 AND HAS_DBACCESS([name]) = 1
+
+EXEC sp_executesql @SqlStatement
+
+SELECT
+	 'sqlserver_query_store' AS [measurement]
+	,REPLACE(@@SERVERNAME,'\',':') AS [sql_instance]
+	,[database_name]
+	,CAST([query_id] AS nvarchar(20)) AS [query_id]
+	,[query_hash]
+	,[plan_count]
+	,[execution_count]
+	,[total_duration_us] / 1000.0 AS [total_duration_ms]
+	,[total_duration_us] / NULLIF([execution_count], 0) / 1000.0 AS [avg_duration_ms]
+	,[max_duration_us] / 1000.0 AS [max_duration_ms]
+	,[total_cpu_time_us] / 1000.0 AS [total_cpu_time_ms]
+	,[total_cpu_time_us] / NULLIF([execution_count], 0) / 1000.0 AS [avg_cpu_time_ms]
+	,CAST([total_logical_io_reads] AS bigint) AS [total_logical_io_reads]
+	,CAST([total_physical_io_reads] AS bigint) AS [total_physical_io_reads]
+	,CAST([total_rowcount] AS bigint) AS [total_rowcount]
+FROM #query_store
+
+DROP TABLE #query_store
+`
+
+const sqlServerAvailabilityGroupLatency string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterprise,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+/*is_primary_replica is available from SQL Server 2014, so the statement is compiled dynamically*/
+IF SERVERPROPERTY('IsHadrEnabled') = 1 AND CAST(PARSENAME(CAST(SERVERPROPERTY('ProductVersion') AS nvarchar),4) AS int) >= 12 BEGIN
+	/*Queue sizes are in KB and rates in KB/s, so the quotient estimates the seconds to catch up.
+	  The primary reports all secondary replicas, a secondary only reports itself.*/
+	EXEC sp_executesql N'
+	SELECT
+		 ''sqlserver_hadr_replica_latency'' AS [measurement]
+		,REPLACE(@@SERVERNAME,''\'','':'') AS [sql_instance]
+		,ag.[name] AS [ag_name]
+		,ar.[replica_server_name]
+		,DB_NAME(drs.[database_id]) AS [database_name]
+		,ar.[availability_mode_desc] AS [availability_mode]
+		,drs.[log_send_queue_size] AS [log_send_queue_size_kb]
+		,drs.[log_send_rate] AS [log_send_rate_kb_per_sec]
+		,CAST(drs.[log_send_queue_size] AS float) / NULLIF(drs.[log_send_rate], 0) AS [send_latency_sec]
+		,drs.[redo_queue_size] AS [redo_queue_size_kb]
+		,drs.[redo_rate] AS [redo_rate_kb_per_sec]
+		,CAST(drs.[redo_queue_size] AS float) / NULLIF(drs.[redo_rate], 0) AS [redo_latency_sec]
+		,DATEDIFF(SECOND, drs.[last_commit_time], pdrs.[last_commit_time]) AS [commit_lag_sec]
+	FROM sys.dm_hadr_database_replica_states AS drs
+	INNER JOIN sys.availability_replicas AS ar
+		ON ar.[replica_id] = drs.[replica_id]
+	INNER JOIN sys.availability_groups AS ag
+		ON ag.[group_id] = drs.[group_id]
+	LEFT JOIN sys.dm_hadr_database_replica_states AS pdrs
+		ON pdrs.[group_database_id] = drs.[group_database_id]
+		AND pdrs.[is_primary_replica] = 1
+	WHERE drs.[is_primary_replica] = 0'
+END
+`
+
+const sqlServerTempdbContention string = `
+SET DEADLOCK_PRIORITY -10;
+IF SERVERPROPERTY('EngineEdition') NOT IN (2,3,4) BEGIN /*NOT IN Standard,Enterprise,Express*/
+	DECLARE @ErrorMessage AS nvarchar(500) = 'Telegraf - Connection string Server:'+ @@ServerName + ',Database:' + DB_NAME() +' is not a SQL Server Standard,Enterprise or Express. Check the database_type parameter in the telegraf configuration.';
+	RAISERROR (@ErrorMessage,11,1)
+	RETURN
+END
+
+/*Tasks currently waiting for latches on the allocation pages (PFS, GAM, SGAM) of tempdb*/
+WITH [waits] AS (
+	SELECT
+		 wt.[wait_duration_ms]
+		,CAST(PARSENAME(REPLACE(wt.[resource_description], ':', '.'), 1) AS bigint) AS [page_id]
+	FROM sys.dm_os_waiting_tasks AS wt
+	WHERE
+		wt.[wait_type] LIKE 'PAGELATCH[_]%'
+		AND wt.[resource_description] LIKE '2:%:%'
+)
+SELECT
+	 'sqlserver_tempdb_contention' AS [measurement]
+	,REPLACE(@@SERVERNAME,'\',':') AS [sql_instance]
+	,(SELECT COUNT(*) FROM tempdb.sys.database_files WHERE [type] = 0) AS [data_files]
+	,SUM(CASE WHEN [page_id] = 1 OR [page_id] % 8088 = 0 THEN 1 ELSE 0 END) AS [pfs_waiting_tasks]
+	,SUM(CASE WHEN [page_id] = 2 OR [page_id] % 511232 = 0 THEN 1 ELSE 0 END) AS [gam_waiting_tasks]
+	,SUM(CASE WHEN [page_id] = 3 OR ([page_id] - 1) % 511232 = 0 THEN 1 ELSE 0 END) AS [sgam_waiting_tasks]
+	,COUNT(*) AS [pagelatch_waiting_tasks]
+	,ISNULL(MAX([wait_duration_ms]), 0) AS [max_wait_duration_ms]
+	,(SELECT SUM([user_object_reserved_page_count]) * 8 FROM tempdb.sys.dm_db_file_space_usage) AS [user_objects_kb]
+	,(SELECT SUM([internal_object_reserved_page_count]) * 8 FROM tempdb.sys.dm_db_file_space_usage) AS [internal_objects_kb]
+	,(SELECT SUM([version_store_reserved_page_count]) * 8 FROM tempdb.sys.dm_db_file_space_usage) AS [version_store_kb]
+	,(SELECT SUM([unallocated_extent_page_count]) * 8 FROM tempdb.sys.dm_db_file_space_usage) AS [free_space_kb]
+FROM [waits]
+`
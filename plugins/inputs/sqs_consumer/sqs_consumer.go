@@ -0,0 +1,446 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sqs_consumer
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var once sync.Once
+
+const (
+	defaultMaxUndeliveredMessages = 1000
+	defaultRetryInterval          = time.Second
+	maxRetryInterval              = time.Minute
+)
+
+type SQSConsumer struct {
+	QueueURL               string          `toml:"queue_url"`
+	MaxNumberOfMessages    int32           `toml:"max_number_of_messages"`
+	WaitTime               config.Duration `toml:"wait_time"`
+	VisibilityTimeout      config.Duration `toml:"visibility_timeout"`
+	MaxVisibilityExtension config.Duration `toml:"max_visibility_extension"`
+	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
+	MessageAttributeTags   []string        `toml:"message_attribute_tags"`
+	ContentEncoding        string          `toml:"content_encoding"`
+	Log                    telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client        sqsClient
+	parser        telegraf.Parser
+	decoder       internal.ContentDecoder
+	acc           telegraf.TrackingAccumulator
+	sem           chan struct{}
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	retryInterval time.Duration
+
+	pending    map[telegraf.TrackingID]*pendingMessage
+	pendingTex sync.Mutex
+
+	messagesReceived   selfstat.Stat
+	messagesDeleted    selfstat.Stat
+	parseErrors        selfstat.Stat
+	receiveErrors      selfstat.Stat
+	deleteErrors       selfstat.Stat
+	visibilityExtended selfstat.Stat
+}
+
+// sqsClient contains the SQS API used, implemented by sqs.Client
+type sqsClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibilityBatch(
+		ctx context.Context,
+		params *sqs.ChangeMessageVisibilityBatchInput,
+		optFns ...func(*sqs.Options),
+	) (*sqs.ChangeMessageVisibilityBatchOutput, error)
+}
+
+// pendingMessage is a message received but not yet deleted as its metrics
+// are not delivered
+type pendingMessage struct {
+	receiptHandle string
+	received      time.Time
+}
+
+func (*SQSConsumer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SQSConsumer) Init() error {
+	if s.QueueURL == "" {
+		return errors.New("'queue_url' required")
+	}
+	if s.MaxNumberOfMessages == 0 {
+		s.MaxNumberOfMessages = 10
+	}
+	if s.MaxNumberOfMessages < 1 || s.MaxNumberOfMessages > 10 {
+		return errors.New("'max_number_of_messages' must be between 1 and 10")
+	}
+	if s.WaitTime < 0 || time.Duration(s.WaitTime) > 20*time.Second {
+		return errors.New("'wait_time' must be between 0s and 20s")
+	}
+	if s.VisibilityTimeout < 0 || time.Duration(s.VisibilityTimeout) > 12*time.Hour {
+		return errors.New("'visibility_timeout' must be between 0s and 12h")
+	}
+	if s.MaxVisibilityExtension < 0 {
+		return errors.New("'max_visibility_extension' must not be negative")
+	}
+	if s.MaxVisibilityExtension > 0 && time.Duration(s.VisibilityTimeout) < 2*time.Second {
+		return errors.New("'max_visibility_extension' requires a 'visibility_timeout' of at least 2s")
+	}
+	if s.MaxUndeliveredMessages <= 0 {
+		s.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+
+	decoder, err := internal.NewContentDecoder(s.ContentEncoding)
+	if err != nil || s.ContentEncoding == "auto" {
+		return fmt.Errorf("unknown content encoding %q", s.ContentEncoding)
+	}
+	s.decoder = decoder
+
+	if s.retryInterval <= 0 {
+		s.retryInterval = defaultRetryInterval
+	}
+
+	tags := map[string]string{"queue_url": s.QueueURL}
+	s.messagesReceived = selfstat.Register("sqs_consumer", "messages_received", tags)
+	s.messagesDeleted = selfstat.Register("sqs_consumer", "messages_deleted", tags)
+	s.parseErrors = selfstat.Register("sqs_consumer", "parse_errors", tags)
+	s.receiveErrors = selfstat.Register("sqs_consumer", "receive_errors", tags)
+	s.deleteErrors = selfstat.Register("sqs_consumer", "delete_errors", tags)
+	s.visibilityExtended = selfstat.Register("sqs_consumer", "visibility_extended", tags)
+
+	return nil
+}
+
+func (s *SQSConsumer) SetParser(parser telegraf.Parser) {
+	s.parser = parser
+}
+
+func (s *SQSConsumer) Start(acc telegraf.Accumulator) error {
+	if s.client == nil {
+		httpClient, err := s.ClientConfig.CreateClient()
+		if err != nil {
+			return err
+		}
+		s.CredentialConfig.HTTPClient = httpClient
+
+		cfg, err := s.CredentialConfig.Credentials()
+		if err != nil {
+			return err
+		}
+		s.client = sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+			if s.EndpointURL != "" {
+				o.BaseEndpoint = &s.EndpointURL
+			}
+		})
+	}
+
+	s.acc = acc.WithTracking(s.MaxUndeliveredMessages)
+	s.sem = make(chan struct{}, s.MaxUndeliveredMessages)
+	s.pending = make(map[telegraf.TrackingID]*pendingMessage, s.MaxUndeliveredMessages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.onDelivery(ctx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.receive(ctx)
+	}()
+
+	if s.MaxVisibilityExtension > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.extendVisibility(ctx)
+		}()
+	}
+
+	return nil
+}
+
+func (*SQSConsumer) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *SQSConsumer) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// receive polls the queue until the context is done. Each received message
+// takes one of the undelivered message slots, so polling blocks while the
+// outputs do not keep up.
+func (s *SQSConsumer) receive(ctx context.Context) {
+	var attempt int
+	for {
+		// Wait for at least one free slot and take as many slots as can be
+		// received with one request
+		select {
+		case <-ctx.Done():
+			return
+		case s.sem <- struct{}{}:
+		}
+		slots := int32(1)
+	acquire:
+		for slots < s.MaxNumberOfMessages {
+			select {
+			case s.sem <- struct{}{}:
+				slots++
+			default:
+				break acquire
+			}
+		}
+
+		messages, err := s.receiveMessages(ctx, slots)
+		for range int(slots) - len(messages) {
+			<-s.sem
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.receiveErrors.Incr(1)
+			delay := backoff(s.retryInterval, attempt)
+			attempt++
+			s.Log.Errorf("Receiving messages failed: %v; retrying in %s", err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			continue
+		}
+		attempt = 0
+
+		for _, msg := range messages {
+			s.onMessage(ctx, msg)
+		}
+	}
+}
+
+func (s *SQSConsumer) receiveMessages(ctx context.Context, n int32) ([]types.Message, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(s.QueueURL),
+		MaxNumberOfMessages:   n,
+		WaitTimeSeconds:       int32(time.Duration(s.WaitTime).Seconds()),
+		MessageAttributeNames: s.MessageAttributeTags,
+	}
+	if s.VisibilityTimeout > 0 {
+		input.VisibilityTimeout = int32(time.Duration(s.VisibilityTimeout).Seconds())
+	}
+
+	out, err := s.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	s.messagesReceived.Incr(int64(len(out.Messages)))
+	return out.Messages, nil
+}
+
+// onMessage parses the message and adds the resulting metrics as one tracking
+// group. Messages failing to parse are not deleted so they become visible
+// again and end up in the dead-letter queue, if configured.
+func (s *SQSConsumer) onMessage(ctx context.Context, msg types.Message) {
+	metrics, err := s.parse(msg)
+	if err != nil {
+		<-s.sem
+		s.parseErrors.Incr(1)
+		s.Log.Errorf("Parsing message %q failed: %v", aws.ToString(msg.MessageId), err)
+		return
+	}
+
+	// Messages without metrics are done immediately
+	if len(metrics) == 0 {
+		once.Do(func() {
+			s.Log.Debug(internal.NoMetricsCreatedMsg)
+		})
+		<-s.sem
+		s.deleteMessage(ctx, aws.ToString(msg.ReceiptHandle))
+		return
+	}
+
+	s.pendingTex.Lock()
+	id := s.acc.AddTrackingMetricGroup(metrics)
+	s.pending[id] = &pendingMessage{
+		receiptHandle: aws.ToString(msg.ReceiptHandle),
+		received:      time.Now(),
+	}
+	s.pendingTex.Unlock()
+}
+
+func (s *SQSConsumer) parse(msg types.Message) ([]telegraf.Metric, error) {
+	body := []byte(aws.ToString(msg.Body))
+	if s.ContentEncoding != "" && s.ContentEncoding != "identity" {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("decoding base64 failed: %w", err)
+		}
+		body = decoded
+	}
+
+	data, err := s.decoder.Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := s.parser.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range s.MessageAttributeTags {
+		attr, found := msg.MessageAttributes[name]
+		if !found || attr.StringValue == nil {
+			continue
+		}
+		for _, m := range metrics {
+			m.AddTag(name, *attr.StringValue)
+		}
+	}
+
+	return metrics, nil
+}
+
+// onDelivery deletes the messages once their metrics are delivered.
+// Undelivered messages are kept and become visible again after the visibility
+// timeout, so they are received again.
+func (s *SQSConsumer) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info := <-s.acc.Delivered():
+			s.pendingTex.Lock()
+			msg, found := s.pending[info.ID()]
+			if !found {
+				s.pendingTex.Unlock()
+				continue
+			}
+			delete(s.pending, info.ID())
+			s.pendingTex.Unlock()
+			<-s.sem
+
+			if !info.Delivered() {
+				s.Log.Debug("Metric group failed to process")
+				continue
+			}
+			s.deleteMessage(ctx, msg.receiptHandle)
+		}
+	}
+}
+
+func (s *SQSConsumer) deleteMessage(ctx context.Context, receiptHandle string) {
+	_, err := s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.QueueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	})
+	if err != nil {
+		s.deleteErrors.Incr(1)
+		s.Log.Errorf("Deleting message failed: %v", err)
+		return
+	}
+	s.messagesDeleted.Incr(1)
+}
+
+// extendVisibility resets the visibility timeout of the pending messages
+// every half of the timeout until the maximum extension is reached
+func (s *SQSConsumer) extendVisibility(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.VisibilityTimeout) / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.extendPending(ctx)
+		}
+	}
+}
+
+func (s *SQSConsumer) extendPending(ctx context.Context) {
+	timeout := int32(time.Duration(s.VisibilityTimeout).Seconds())
+
+	s.pendingTex.Lock()
+	entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, 0, len(s.pending))
+	for _, msg := range s.pending {
+		if time.Since(msg.received) >= time.Duration(s.MaxVisibilityExtension) {
+			continue
+		}
+		entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(len(entries))),
+			ReceiptHandle:     aws.String(msg.receiptHandle),
+			VisibilityTimeout: timeout,
+		})
+	}
+	s.pendingTex.Unlock()
+
+	// The API accepts at most ten entries per request
+	for len(entries) > 0 {
+		n := min(len(entries), 10)
+		out, err := s.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(s.QueueURL),
+			Entries:  entries[:n],
+		})
+		entries = entries[n:]
+		if err != nil {
+			s.Log.Errorf("Extending visibility timeout failed: %v", err)
+			continue
+		}
+		for _, failed := range out.Failed {
+			s.Log.Debugf("Extending visibility timeout of entry %s failed: %s", aws.ToString(failed.Id), aws.ToString(failed.Message))
+		}
+		s.visibilityExtended.Incr(int64(len(out.Successful)))
+	}
+}
+
+// backoff returns the delay for the given retry attempt doubling the initial
+// delay up to one minute
+func backoff(initial time.Duration, attempt int) time.Duration {
+	if attempt < 32 && initial<<attempt < maxRetryInterval {
+		return initial << attempt
+	}
+	return maxRetryInterval
+}
+
+func init() {
+	inputs.Add("sqs_consumer", func() telegraf.Input {
+		return &SQSConsumer{
+			MaxNumberOfMessages:    10,
+			WaitTime:               config.Duration(20 * time.Second),
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+			ContentEncoding:        "identity",
+		}
+	})
+}
@@ -0,0 +1,369 @@
+package sqs_consumer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// mockClient serves the queued messages and records the API calls
+type mockClient struct {
+	messages   []types.Message
+	receiveErr error
+	deleted    []string
+	extended   []string
+	received   []int32
+	sync.Mutex
+}
+
+func (c *mockClient) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	c.Lock()
+	if c.receiveErr != nil {
+		err := c.receiveErr
+		c.receiveErr = nil
+		c.Unlock()
+		return nil, err
+	}
+	c.received = append(c.received, params.MaxNumberOfMessages)
+	n := min(int(params.MaxNumberOfMessages), len(c.messages))
+	messages := c.messages[:n]
+	c.messages = c.messages[n:]
+	c.Unlock()
+
+	// Simulate long polling on an empty queue
+	if len(messages) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (c *mockClient) DeleteMessage(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.deleted = append(c.deleted, *params.ReceiptHandle)
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (c *mockClient) ChangeMessageVisibilityBatch(
+	_ context.Context,
+	params *sqs.ChangeMessageVisibilityBatchInput,
+	_ ...func(*sqs.Options),
+) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	out := &sqs.ChangeMessageVisibilityBatchOutput{}
+	for _, e := range params.Entries {
+		c.extended = append(c.extended, *e.ReceiptHandle)
+		out.Successful = append(out.Successful, types.ChangeMessageVisibilityBatchResultEntry{Id: e.Id})
+	}
+	return out, nil
+}
+
+func (c *mockClient) getDeleted() []string {
+	c.Lock()
+	defer c.Unlock()
+	return append([]string(nil), c.deleted...)
+}
+
+func newMessage(i int, body string) types.Message {
+	return types.Message{
+		MessageId:     aws.String("msg-" + strconv.Itoa(i)),
+		ReceiptHandle: aws.String("handle-" + strconv.Itoa(i)),
+		Body:          aws.String(body),
+	}
+}
+
+func TestDeleteAfterDelivery(t *testing.T) {
+	client := &mockClient{
+		messages: []types.Message{
+			newMessage(0, "cpu value=1 1700000000000000000\n"),
+			newMessage(1, "cpu value=2 1700000000000000000\nmem value=3 1700000000000000000\n"),
+			newMessage(2, "invalid"),
+			newMessage(3, ""),
+		},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 3
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Only the message without metrics is deleted before delivery
+	require.Equal(t, []string{"handle-3"}, client.getDeleted())
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1700000000, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(1700000000, 0)),
+	}
+	metrics := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// A message is only deleted if all of its metrics are delivered, rejected
+	// messages are kept to be received again
+	metrics[0].Reject()
+	metrics[1].Accept()
+	require.Never(t, func() bool {
+		return len(client.getDeleted()) > 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	metrics[2].Accept()
+	require.Eventually(t, func() bool {
+		return len(client.getDeleted()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"handle-3", "handle-1"}, client.getDeleted())
+
+	// The unparsable message is neither pending nor deleted
+	plugin.pendingTex.Lock()
+	defer plugin.pendingTex.Unlock()
+	require.Empty(t, plugin.pending)
+}
+
+func TestMaxUndelivered(t *testing.T) {
+	client := &mockClient{}
+	for i := range 20 {
+		client.messages = append(client.messages, newMessage(i, "cpu value=1 1700000000000000000\n"))
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.MaxUndeliveredMessages = 5
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Receiving stops once the undelivered limit is reached
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 5
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool {
+		return acc.NMetrics() > 5
+	}, 100*time.Millisecond, 10*time.Millisecond)
+
+	// Delivering metrics frees the slots
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 10
+	}, 3*time.Second, 10*time.Millisecond)
+
+	client.Lock()
+	defer client.Unlock()
+	for _, n := range client.received {
+		require.LessOrEqual(t, n, int32(5))
+	}
+}
+
+func TestMessageAttributeTags(t *testing.T) {
+	msg := newMessage(0, "cpu value=1 1700000000000000000\n")
+	msg.MessageAttributes = map[string]types.MessageAttributeValue{
+		"source": {DataType: aws.String("String"), StringValue: aws.String("collector-1")},
+		"other":  {DataType: aws.String("String"), StringValue: aws.String("ignored")},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 &mockClient{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.MessageAttributeTags = []string{"source", "missing"}
+
+	metrics, err := plugin.parse(msg)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{"source": "collector-1"}, metrics[0].Tags())
+}
+
+func TestContentEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("cpu value=1 1700000000000000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 &mockClient{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.ContentEncoding = "gzip"
+	require.NoError(t, plugin.Init())
+
+	metrics, err := plugin.parse(newMessage(0, base64.StdEncoding.EncodeToString(buf.Bytes())))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	_, err = plugin.parse(newMessage(1, "not base64!"))
+	require.ErrorContains(t, err, "decoding base64 failed")
+}
+
+func TestVisibilityExtension(t *testing.T) {
+	client := &mockClient{
+		messages: []types.Message{newMessage(0, "cpu value=1 1700000000000000000\n")},
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.VisibilityTimeout = config.Duration(2 * time.Second)
+	plugin.MaxVisibilityExtension = config.Duration(time.Hour)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Messages exceeding the maximum extension are not extended anymore
+	plugin.pendingTex.Lock()
+	for _, msg := range plugin.pending {
+		msg.received = msg.received.Add(-2 * time.Hour)
+	}
+	plugin.pending[0] = &pendingMessage{receiptHandle: "handle-other", received: time.Now()}
+	plugin.pendingTex.Unlock()
+
+	plugin.extendPending(context.Background())
+	client.Lock()
+	require.Equal(t, []string{"handle-other"}, client.extended)
+	client.Unlock()
+}
+
+func TestReceiveRetry(t *testing.T) {
+	client := &mockClient{
+		messages:   []types.Message{newMessage(0, "cpu value=1 1700000000000000000\n")},
+		receiveErr: errors.New("service unavailable"),
+	}
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &SQSConsumer{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MaxNumberOfMessages:    10,
+		MaxUndeliveredMessages: 100,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+	plugin.retryInterval = 10 * time.Millisecond
+	receiveErrors := plugin.receiveErrors.Get()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	require.Equal(t, receiveErrors+1, plugin.receiveErrors.Get())
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SQSConsumer
+		expected string
+	}{
+		{
+			name:     "missing queue",
+			plugin:   &SQSConsumer{},
+			expected: "'queue_url' required",
+		},
+		{
+			name:     "too many messages",
+			plugin:   &SQSConsumer{QueueURL: "queue", MaxNumberOfMessages: 11},
+			expected: "'max_number_of_messages' must be between 1 and 10",
+		},
+		{
+			name:     "wait time",
+			plugin:   &SQSConsumer{QueueURL: "queue", WaitTime: config.Duration(time.Minute)},
+			expected: "'wait_time' must be between 0s and 20s",
+		},
+		{
+			name:     "extension without timeout",
+			plugin:   &SQSConsumer{QueueURL: "queue", MaxVisibilityExtension: config.Duration(time.Hour)},
+			expected: "'max_visibility_extension' requires a 'visibility_timeout'",
+		},
+		{
+			name:     "encoding",
+			plugin:   &SQSConsumer{QueueURL: "queue", ContentEncoding: "brotli"},
+			expected: `unknown content encoding "brotli"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -405,9 +405,39 @@ func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
 					}
 				}
 			}
+
+			// Add type specific information
+			switch s.UnitType {
+			case "timer":
+				fields["next_elapse_realtime"] = usecOrZero(properties["NextElapseUSecRealtime"])
+				fields["next_elapse_monotonic"] = usecOrZero(properties["NextElapseUSecMonotonic"])
+				fields["last_trigger"] = usecOrZero(properties["LastTriggerUSec"])
+				fields["last_trigger_monotonic"] = usecOrZero(properties["LastTriggerUSecMonotonic"])
+			case "socket":
+				fields["accepted"] = properties["NAccepted"]
+				fields["connections"] = properties["NConnections"]
+				fields["refused"] = properties["NRefused"]
+			}
+
+			// Detect unit files changed on disk requiring a daemon-reload
+			if v, err := s.client.GetUnitPropertyContext(ctx, state.Name, "NeedDaemonReload"); err == nil {
+				if needReload, ok := v.Value.Value().(bool); ok {
+					fields["need_daemon_reload"] = needReload
+				}
+			}
 		}
 		acc.AddFields("systemd_units", fields, tags)
 	}
 
 	return nil
 }
+
+// usecOrZero returns the given timestamp in microseconds or zero if the
+// timestamp is unset, i.e. is zero or the maximum value
+func usecOrZero(value interface{}) uint64 {
+	v, ok := value.(uint64)
+	if !ok || v == math.MaxUint64 {
+		return 0
+	}
+	return v
+}
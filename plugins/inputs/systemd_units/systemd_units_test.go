@@ -30,6 +30,7 @@ type properties struct {
 	state      *sdbus.UnitStatus
 	ufPreset   string
 	ufState    string
+	needReload *bool
 	properties map[string]interface{}
 }
 
@@ -597,6 +598,145 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShowTimerSocket(t *testing.T) {
+	needReload := true
+	tests := []struct {
+		name       string
+		unitType   string
+		properties map[string]properties
+		expected   []telegraf.Metric
+	}{
+		{
+			name:     "timer",
+			unitType: "timer",
+			properties: map[string]properties{
+				"example.timer": {
+					utype: "Timer",
+					state: &sdbus.UnitStatus{
+						Name:        "example.timer",
+						LoadState:   "loaded",
+						ActiveState: "active",
+						SubState:    "waiting",
+					},
+					ufPreset:   "enabled",
+					ufState:    "enabled",
+					needReload: &needReload,
+					properties: map[string]interface{}{
+						"Id":                       "example.timer",
+						"NextElapseUSecRealtime":   uint64(1700003600000000),
+						"NextElapseUSecMonotonic":  uint64(math.MaxUint64),
+						"LastTriggerUSec":          uint64(1700000000000000),
+						"LastTriggerUSecMonotonic": uint64(0),
+					},
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"systemd_units",
+					map[string]string{
+						"name":   "example.timer",
+						"load":   "loaded",
+						"active": "active",
+						"sub":    "waiting",
+						"state":  "enabled",
+						"preset": "enabled",
+					},
+					map[string]interface{}{
+						"load_code":              0,
+						"active_code":            0,
+						"sub_code":               0x0010,
+						"mem_current":            uint64(0),
+						"mem_peak":               uint64(0),
+						"swap_current":           uint64(0),
+						"swap_peak":              uint64(0),
+						"mem_avail":              uint64(0),
+						"next_elapse_realtime":   uint64(1700003600000000),
+						"next_elapse_monotonic":  uint64(0),
+						"last_trigger":           uint64(1700000000000000),
+						"last_trigger_monotonic": uint64(0),
+						"need_daemon_reload":     true,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:     "socket",
+			unitType: "socket",
+			properties: map[string]properties{
+				"example.socket": {
+					utype: "Socket",
+					state: &sdbus.UnitStatus{
+						Name:        "example.socket",
+						LoadState:   "loaded",
+						ActiveState: "active",
+						SubState:    "listening",
+					},
+					ufPreset: "enabled",
+					ufState:  "enabled",
+					properties: map[string]interface{}{
+						"Id":           "example.socket",
+						"NAccepted":    uint32(42),
+						"NConnections": uint32(3),
+						"NRefused":     uint32(1),
+					},
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"systemd_units",
+					map[string]string{
+						"name":   "example.socket",
+						"load":   "loaded",
+						"active": "active",
+						"sub":    "listening",
+						"state":  "enabled",
+						"preset": "enabled",
+					},
+					map[string]interface{}{
+						"load_code":    0,
+						"active_code":  0,
+						"sub_code":     0x0072,
+						"mem_current":  uint64(0),
+						"mem_peak":     uint64(0),
+						"swap_current": uint64(0),
+						"swap_peak":    uint64(0),
+						"mem_avail":    uint64(0),
+						"accepted":     uint32(42),
+						"connections":  uint32(3),
+						"refused":      uint32(1),
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &SystemdUnits{
+				Pattern:  "examp*",
+				UnitType: tt.unitType,
+				Details:  true,
+				Timeout:  config.Duration(time.Second),
+				Log:      testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			client := &fakeClient{
+				units:     tt.properties,
+				connected: true,
+			}
+			plugin.client = client
+			defer plugin.Stop()
+
+			var acc testutil.Accumulator
+			require.NoError(t, acc.GatherError(plugin.Gather))
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
 func TestMultiInstance(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -985,6 +1125,10 @@ func (c *fakeClient) GetUnitPropertyContext(_ context.Context, unit, propertyNam
 		return &sdbus.Property{Name: propertyName, Value: dbus.MakeVariant(u.ufState)}, nil
 	case "UnitFilePreset":
 		return &sdbus.Property{Name: propertyName, Value: dbus.MakeVariant(u.ufPreset)}, nil
+	case "NeedDaemonReload":
+		if u.needReload != nil {
+			return &sdbus.Property{Name: propertyName, Value: dbus.MakeVariant(*u.needReload)}, nil
+		}
 	}
 	return nil, errors.New("unknown property")
 }
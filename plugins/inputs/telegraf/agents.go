@@ -0,0 +1,83 @@
+package telegraf
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// agentState is the liveness information of a sending agent
+type agentState struct {
+	connections int
+	lastSeen    time.Time
+	batches     int64
+	metrics     int64
+}
+
+// registerAgent assigns the connection to the agent with the given name
+func (t *Telegraf) registerAgent(c *connection, name string) {
+	t.agentsTex.Lock()
+	defer t.agentsTex.Unlock()
+
+	c.agent = name
+	a, found := t.agents[name]
+	if !found {
+		a = &agentState{}
+		t.agents[name] = a
+	}
+	a.connections++
+	a.lastSeen = time.Now()
+}
+
+func (t *Telegraf) unregisterAgent(c *connection) {
+	if c.agent == "" {
+		return
+	}
+
+	t.agentsTex.Lock()
+	defer t.agentsTex.Unlock()
+	if a, found := t.agents[c.agent]; found {
+		a.connections--
+	}
+}
+
+func (t *Telegraf) agentSeen(c *connection, metrics int) {
+	t.agentsTex.Lock()
+	defer t.agentsTex.Unlock()
+
+	a := t.agents[c.agent]
+	a.lastSeen = time.Now()
+	a.batches++
+	a.metrics += int64(metrics)
+}
+
+// Gather reports the liveness of the agents sending to this instance.
+// Disconnected agents are reported until the expiration time passed.
+func (t *Telegraf) Gather(acc telegraf.Accumulator) error {
+	if !t.AgentStatistics {
+		return nil
+	}
+
+	t.agentsTex.Lock()
+	defer t.agentsTex.Unlock()
+
+	now := time.Now()
+	for name, a := range t.agents {
+		idle := now.Sub(a.lastSeen)
+		if a.connections == 0 && idle > time.Duration(t.AgentExpiration) {
+			delete(t.agents, name)
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"connected":        a.connections > 0,
+			"connections":      int64(a.connections),
+			"batches_received": a.batches,
+			"metrics_received": a.metrics,
+			"idle_seconds":     idle.Seconds(),
+		}
+		acc.AddFields("telegraf_agent", fields, map[string]string{"agent": name}, now)
+	}
+
+	return nil
+}
@@ -0,0 +1,408 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package telegraf
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/common/transport"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	defaultMaxUndeliveredBatches = 16
+	defaultAgentExpiration       = 24 * time.Hour
+)
+
+type Telegraf struct {
+	ServiceAddress        string          `toml:"service_address"`
+	MaxConnections        int             `toml:"max_connections"`
+	MaxFrameSize          config.Size     `toml:"max_frame_size"`
+	ReadTimeout           config.Duration `toml:"read_timeout"`
+	AckMode               string          `toml:"ack_mode"`
+	MaxUndeliveredBatches int             `toml:"max_undelivered_batches"`
+	AgentTag              string          `toml:"agent_tag"`
+	AgentStatistics       bool            `toml:"agent_statistics"`
+	AgentExpiration       config.Duration `toml:"agent_expiration"`
+	Log                   telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	tlsCfg   *tls.Config
+	listener net.Listener
+	acc      telegraf.Accumulator
+	tacc     telegraf.TrackingAccumulator
+	sem      chan struct{}
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	conns    map[*connection]bool
+	connsTex sync.Mutex
+
+	pending    map[telegraf.TrackingID]*pendingBatch
+	pendingTex sync.Mutex
+
+	agents    map[string]*agentState
+	agentsTex sync.Mutex
+
+	connectionsAccepted selfstat.Stat
+	connectionsRejected selfstat.Stat
+	batchesReceived     selfstat.Stat
+	metricsReceived     selfstat.Stat
+	decodeErrors        selfstat.Stat
+}
+
+// connection is a client connection with its per-connection decoding state
+type connection struct {
+	net.Conn
+	decoder      *transport.Decoder
+	decompressor map[uint8]internal.ContentDecoder
+	agent        string
+
+	// Acknowledgements are sent from the delivery handler as well
+	writeTex sync.Mutex
+}
+
+// pendingBatch is a batch received but not yet acknowledged as its metrics
+// are not delivered
+type pendingBatch struct {
+	conn *connection
+	seq  uint64
+}
+
+func (*Telegraf) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Telegraf) Init() error {
+	if t.ServiceAddress == "" {
+		t.ServiceAddress = ":8099"
+	}
+	if t.MaxFrameSize <= 0 {
+		t.MaxFrameSize = config.Size(transport.DefaultMaxFrameSize)
+	}
+	if t.MaxUndeliveredBatches <= 0 {
+		t.MaxUndeliveredBatches = defaultMaxUndeliveredBatches
+	}
+	if t.AgentExpiration <= 0 {
+		t.AgentExpiration = config.Duration(defaultAgentExpiration)
+	}
+
+	switch t.AckMode {
+	case "":
+		t.AckMode = "received"
+	case "received", "delivered":
+	default:
+		return fmt.Errorf("invalid 'ack_mode' %q", t.AckMode)
+	}
+
+	tlsCfg, err := t.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	t.tlsCfg = tlsCfg
+
+	tags := map[string]string{"address": t.ServiceAddress}
+	t.connectionsAccepted = selfstat.Register("telegraf_transport", "connections_accepted", tags)
+	t.connectionsRejected = selfstat.Register("telegraf_transport", "connections_rejected", tags)
+	t.batchesReceived = selfstat.Register("telegraf_transport", "batches_received", tags)
+	t.metricsReceived = selfstat.Register("telegraf_transport", "metrics_received", tags)
+	t.decodeErrors = selfstat.Register("telegraf_transport", "decode_errors", tags)
+
+	return nil
+}
+
+func (t *Telegraf) Start(acc telegraf.Accumulator) error {
+	listener, err := net.Listen("tcp", t.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	if t.tlsCfg != nil {
+		listener = tls.NewListener(listener, t.tlsCfg)
+	}
+	t.listener = listener
+	t.Log.Infof("Listening on %s", listener.Addr())
+
+	t.acc = acc
+	t.conns = make(map[*connection]bool)
+	t.agents = make(map[string]*agentState)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	if t.AckMode == "delivered" {
+		t.tacc = acc.WithTracking(t.MaxUndeliveredBatches)
+		t.sem = make(chan struct{}, t.MaxUndeliveredBatches)
+		t.pending = make(map[telegraf.TrackingID]*pendingBatch, t.MaxUndeliveredBatches)
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.onDelivery(ctx)
+		}()
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.accept(ctx)
+	}()
+
+	return nil
+}
+
+func (t *Telegraf) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	if t.listener != nil {
+		t.listener.Close()
+	}
+
+	t.connsTex.Lock()
+	for c := range t.conns {
+		c.Close()
+	}
+	t.connsTex.Unlock()
+
+	t.wg.Wait()
+}
+
+func (t *Telegraf) accept(ctx context.Context) {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				t.Log.Errorf("Accepting connection failed: %v", err)
+			}
+			return
+		}
+
+		t.connsTex.Lock()
+		if t.MaxConnections > 0 && len(t.conns) >= t.MaxConnections {
+			t.connsTex.Unlock()
+			t.connectionsRejected.Incr(1)
+			t.Log.Warnf("Rejecting connection from %s: maximum number of connections reached", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		c := &connection{
+			Conn:         conn,
+			decoder:      transport.NewDecoder(),
+			decompressor: make(map[uint8]internal.ContentDecoder),
+		}
+		t.conns[c] = true
+		t.connsTex.Unlock()
+		t.connectionsAccepted.Incr(1)
+
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer func() {
+				t.connsTex.Lock()
+				delete(t.conns, c)
+				t.connsTex.Unlock()
+				c.Close()
+				t.unregisterAgent(c)
+			}()
+
+			if err := t.handle(ctx, c); err != nil && ctx.Err() == nil {
+				t.Log.Errorf("Connection from %s: %v", c.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handle reads batches from the connection until it is closed. Reading from
+// the connection blocks while the metrics of the previous batch are processed,
+// so slow outputs on this side slow down the sending side.
+func (t *Telegraf) handle(ctx context.Context, c *connection) error {
+	if err := t.setReadDeadline(c); err != nil {
+		return err
+	}
+	if err := transport.ReadHello(c); err != nil {
+		return fmt.Errorf("receiving hello failed: %w", err)
+	}
+	if err := t.write(c, func(w io.Writer) error { return transport.WriteHello(w) }); err != nil {
+		return fmt.Errorf("sending hello failed: %w", err)
+	}
+
+	for {
+		if err := t.setReadDeadline(c); err != nil {
+			return err
+		}
+		ft, payload, err := transport.ReadFrame(c, int(t.MaxFrameSize))
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		switch ft {
+		case transport.FrameAgent:
+			if c.agent != "" {
+				return errors.New("agent name sent after first batch")
+			}
+			t.registerAgent(c, string(payload))
+			continue
+		case transport.FrameBatch:
+		default:
+			return fmt.Errorf("unexpected frame type %d", ft)
+		}
+
+		var batch transport.Batch
+		if err := batch.Unmarshal(payload); err != nil {
+			return err
+		}
+		t.batchesReceived.Incr(1)
+
+		// The dictionary of the decoder is undefined after an error so the
+		// client has to reconnect
+		metrics, err := t.decode(c, &batch)
+		if err != nil {
+			t.decodeErrors.Incr(1)
+			return fmt.Errorf("decoding batch %d failed: %w", batch.Seq, err)
+		}
+		t.metricsReceived.Incr(int64(len(metrics)))
+
+		// Clients not sending their name are identified by their address
+		if c.agent == "" {
+			host, _, err := net.SplitHostPort(c.RemoteAddr().String())
+			if err != nil {
+				host = c.RemoteAddr().String()
+			}
+			t.registerAgent(c, host)
+		}
+		t.agentSeen(c, len(metrics))
+		if t.AgentTag != "" {
+			for _, m := range metrics {
+				m.AddTag(t.AgentTag, c.agent)
+			}
+		}
+
+		if t.AckMode == "received" || len(metrics) == 0 {
+			for _, m := range metrics {
+				t.acc.AddMetric(m)
+			}
+			if err := t.ack(c, batch.Seq, transport.AckOK, ""); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Wait for a free slot before adding the batch, the client waits for
+		// the acknowledgement in the meantime
+		select {
+		case <-ctx.Done():
+			return nil
+		case t.sem <- struct{}{}:
+		}
+		t.pendingTex.Lock()
+		id := t.tacc.AddTrackingMetricGroup(metrics)
+		t.pending[id] = &pendingBatch{conn: c, seq: batch.Seq}
+		t.pendingTex.Unlock()
+	}
+}
+
+func (t *Telegraf) decode(c *connection, batch *transport.Batch) ([]telegraf.Metric, error) {
+	decompressor, found := c.decompressor[batch.Compression]
+	if !found {
+		name, err := transport.CompressionName(batch.Compression)
+		if err != nil {
+			return nil, err
+		}
+		decompressor, err = internal.NewContentDecoder(name, internal.WithMaxDecompressionSize(int64(t.MaxFrameSize)))
+		if err != nil {
+			return nil, err
+		}
+		c.decompressor[batch.Compression] = decompressor
+	}
+
+	data, err := decompressor.Decode(batch.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing failed: %w", err)
+	}
+	return c.decoder.Decode(data)
+}
+
+// onDelivery acknowledges the batches once their metrics are delivered
+func (t *Telegraf) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info := <-t.tacc.Delivered():
+			t.pendingTex.Lock()
+			batch, found := t.pending[info.ID()]
+			if !found {
+				t.pendingTex.Unlock()
+				continue
+			}
+			delete(t.pending, info.ID())
+			t.pendingTex.Unlock()
+			<-t.sem
+
+			status, msg := transport.AckOK, ""
+			if !info.Delivered() {
+				status, msg = transport.AckError, "metrics not delivered"
+			}
+			if err := t.ack(batch.conn, batch.seq, status, msg); err != nil {
+				// The client resends the batch after reconnecting
+				t.Log.Debugf("Acknowledging batch %d failed: %v", batch.seq, err)
+				batch.conn.Close()
+			}
+		}
+	}
+}
+
+func (t *Telegraf) ack(c *connection, seq uint64, status transport.AckStatus, msg string) error {
+	ack := &transport.Ack{Seq: seq, Status: status, Message: msg}
+	return t.write(c, func(w io.Writer) error {
+		return transport.WriteFrame(w, transport.FrameAck, ack.Marshal())
+	})
+}
+
+func (t *Telegraf) write(c *connection, fn func(io.Writer) error) error {
+	c.writeTex.Lock()
+	defer c.writeTex.Unlock()
+
+	if t.ReadTimeout > 0 {
+		if err := c.SetWriteDeadline(time.Now().Add(time.Duration(t.ReadTimeout))); err != nil {
+			return err
+		}
+	}
+	return fn(c)
+}
+
+func (t *Telegraf) setReadDeadline(c *connection) error {
+	if t.ReadTimeout <= 0 {
+		return nil
+	}
+	return c.SetReadDeadline(time.Now().Add(time.Duration(t.ReadTimeout)))
+}
+
+func init() {
+	inputs.Add("telegraf", func() telegraf.Input {
+		return &Telegraf{
+			ServiceAddress:        ":8099",
+			MaxFrameSize:          config.Size(transport.DefaultMaxFrameSize),
+			AckMode:               "received",
+			MaxUndeliveredBatches: defaultMaxUndeliveredBatches,
+			AgentExpiration:       config.Duration(defaultAgentExpiration),
+		}
+	})
+}
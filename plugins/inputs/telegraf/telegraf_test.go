@@ -0,0 +1,266 @@
+package telegraf
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/transport"
+	outputs_telegraf "github.com/influxdata/telegraf/plugins/outputs/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newOutput(t *testing.T, address, compression string) *outputs_telegraf.Telegraf {
+	t.Helper()
+
+	output := &outputs_telegraf.Telegraf{
+		Address:     address,
+		Compression: compression,
+		Timeout:     config.Duration(3 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	return output
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 99.5, "online": true},
+			time.Unix(1700000000, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"net",
+			map[string]string{"host": "a", "interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(12345), "drops": int64(3), "state": "up"},
+			time.Unix(1700000000, 0),
+			telegraf.Counter,
+		),
+	}
+}
+
+func TestForward(t *testing.T) {
+	for _, compression := range []string{"identity", "gzip", "zstd", "snappy", "lz4"} {
+		t.Run(compression, func(t *testing.T) {
+			plugin := &Telegraf{
+				ServiceAddress: "127.0.0.1:0",
+				AckMode:        "received",
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			output := newOutput(t, plugin.listener.Addr().String(), compression)
+			defer output.Close()
+
+			// Send the batch twice to use the dictionary of the connection
+			expected := testMetrics()
+			require.NoError(t, output.Write(expected))
+			require.NoError(t, output.Write(expected))
+			require.NoError(t, output.Write(nil))
+
+			// Metrics are added before acknowledging the batch
+			expected = append(expected, testMetrics()...)
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestAckDelivered(t *testing.T) {
+	plugin := &Telegraf{
+		ServiceAddress: "127.0.0.1:0",
+		AckMode:        "delivered",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	output := newOutput(t, plugin.listener.Addr().String(), "zstd")
+	defer output.Close()
+
+	// The write only returns after all metrics of the batch are delivered
+	done := make(chan error, 1)
+	go func() {
+		done <- output.Write(testMetrics())
+	}()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	metrics := acc.GetTelegrafMetrics()
+	metrics[0].Accept()
+	select {
+	case err := <-done:
+		require.FailNow(t, "write returned before delivery", "error: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	metrics[1].Accept()
+	require.NoError(t, <-done)
+
+	// Undelivered batches are rejected so the sending side keeps the metrics
+	acc.ClearMetrics()
+	go func() {
+		done <- output.Write(testMetrics())
+	}()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Reject()
+	}
+	require.ErrorContains(t, <-done, "batch rejected: metrics not delivered")
+
+	// The connection stays usable
+	acc.ClearMetrics()
+	go func() {
+		done <- output.Write(testMetrics())
+	}()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	require.NoError(t, <-done)
+}
+
+func TestMaxConnections(t *testing.T) {
+	plugin := &Telegraf{
+		ServiceAddress: "127.0.0.1:0",
+		AckMode:        "received",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.MaxConnections = 1
+	rejected := plugin.connectionsRejected.Get()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	output := newOutput(t, plugin.listener.Addr().String(), "zstd")
+	defer output.Close()
+
+	second := &outputs_telegraf.Telegraf{
+		Address: plugin.listener.Addr().String(),
+		Timeout: config.Duration(3 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, second.Init())
+	require.ErrorContains(t, second.Connect(), "receiving hello failed")
+	require.Equal(t, rejected+1, plugin.connectionsRejected.Get())
+}
+
+func TestInvalidData(t *testing.T) {
+	plugin := &Telegraf{
+		ServiceAddress: "127.0.0.1:0",
+		AckMode:        "received",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	decodeErrors := plugin.decodeErrors.Get()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(3*time.Second)))
+
+	require.NoError(t, transport.WriteHello(conn))
+	require.NoError(t, transport.ReadHello(conn))
+
+	// A reference to a dictionary entry never sent
+	batch := &transport.Batch{Seq: 1, Data: []byte{1, 5, 0, 0, 0, 0}}
+	require.NoError(t, transport.WriteFrame(conn, transport.FrameBatch, batch.Marshal()))
+
+	// The connection is closed without acknowledgement
+	_, _, err = transport.ReadFrame(conn, transport.DefaultMaxFrameSize)
+	require.Error(t, err)
+	require.Equal(t, decodeErrors+1, plugin.decodeErrors.Get())
+	require.Zero(t, acc.NMetrics())
+}
+
+func TestAgents(t *testing.T) {
+	plugin := &Telegraf{
+		ServiceAddress: "127.0.0.1:0",
+		AckMode:        "received",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.AgentTag = "agent"
+	plugin.AgentStatistics = true
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	output := &outputs_telegraf.Telegraf{
+		Address:   plugin.listener.Addr().String(),
+		AgentName: "edge-01",
+		Timeout:   config.Duration(3 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	require.NoError(t, output.Write(testMetrics()))
+
+	// Forwarded metrics are tagged with the agent name
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "edge-01", m.Tags()["agent"])
+	}
+	acc.ClearMetrics()
+
+	require.NoError(t, plugin.Gather(&acc))
+	expected := []telegraf.Metric{
+		metric.New(
+			"telegraf_agent",
+			map[string]string{"agent": "edge-01"},
+			map[string]interface{}{
+				"connected":        true,
+				"connections":      int64(1),
+				"batches_received": int64(1),
+				"metrics_received": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{testutil.IgnoreTime(), testutil.IgnoreFields("idle_seconds")}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// Disconnected agents are reported until they expire
+	require.NoError(t, output.Close())
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, plugin.Gather(&acc))
+		return acc.HasField("telegraf_agent", "connected") && !acc.GetTelegrafMetrics()[0].Fields()["connected"].(bool)
+	}, 3*time.Second, 10*time.Millisecond)
+
+	plugin.AgentExpiration = config.Duration(time.Nanosecond)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Zero(t, acc.NMetrics())
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Telegraf{AckMode: "written"}
+	require.ErrorContains(t, plugin.Init(), `invalid 'ack_mode' "written"`)
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/hwmon"
 )
 
 const scalingFactor = float64(1000.0)
@@ -107,102 +108,35 @@ func (t *Temperature) createMetricsV2(acc telegraf.Accumulator, temperatures []T
 	}
 }
 
-func (t *Temperature) gatherHwmon(syspath string) ([]TemperatureStat, error) {
-	// Get all hwmon devices
-	sensors, err := filepath.Glob(filepath.Join(syspath, "class", "hwmon", "hwmon*", "temp*_input"))
+func (*Temperature) gatherHwmon(syspath string) ([]TemperatureStat, error) {
+	sensors, err := hwmon.Gather(syspath)
 	if err != nil {
 		return nil, fmt.Errorf("getting sensors failed: %w", err)
 	}
 
-	// Handle CentOS special path containing an additional "device" directory
-	// see https://github.com/shirou/gopsutil/blob/master/host/host_linux.go
-	if len(sensors) == 0 {
-		sensors, err = filepath.Glob(filepath.Join(syspath, "class", "hwmon", "hwmon*", "device", "temp*_input"))
-		if err != nil {
-			return nil, fmt.Errorf("getting sensors on CentOS failed: %w", err)
-		}
-	}
-
-	// Exit early if we cannot find any device
-	if len(sensors) == 0 {
-		return nil, nil
-	}
-
-	// Collect the sensor information
 	stats := make([]TemperatureStat, 0, len(sensors))
 	for _, s := range sensors {
-		// Get the sensor directory and the temperature prefix from the path
-		path := filepath.Dir(s)
-		prefix := strings.SplitN(filepath.Base(s), "_", 2)[0]
-
-		// Read the sensor and device name
-		deviceName, err := os.Readlink(filepath.Join(path, "device"))
-		if err == nil {
-			deviceName = filepath.Base(deviceName)
-		}
-
-		// Read the sensor name and use the device name as fallback
-		name := deviceName
-		n, err := os.ReadFile(filepath.Join(path, "name"))
-		if err == nil {
-			name = strings.TrimSpace(string(n))
-		}
-
-		// Get the sensor label
-		var label string
-		if buf, err := os.ReadFile(filepath.Join(path, prefix+"_label")); err == nil {
-			label = strings.TrimSpace(string(buf))
-		}
-
-		// Do the actual sensor readings
-		temp := TemperatureStat{
-			Name:       name,
-			Label:      strings.ToLower(label),
-			Device:     deviceName,
-			Additional: make(map[string]interface{}),
-		}
-
-		// Temperature (mandatory)
-		fn := filepath.Join(path, prefix+"_input")
-		buf, err := os.ReadFile(fn)
-		if err != nil {
-			t.Log.Debugf("Couldn't read temperature from %q: %v", fn, err)
+		// Skip non-temperature sensors and sensors we cannot read
+		if s.Type != "temp" {
 			continue
 		}
-		if v, err := strconv.ParseFloat(strings.TrimSpace(string(buf)), 64); err == nil {
-			temp.Temperature = v / scalingFactor
-		}
-
-		// Read all possible values of the sensor
-		matches, err := filepath.Glob(filepath.Join(path, prefix+"_*"))
-		if err != nil {
-			t.Log.Warnf("Couldn't read files from %q: %v", filepath.Join(path, prefix+"_*"), err)
+		v, found := s.Values["input"]
+		if !found {
 			continue
 		}
-		for _, fn := range matches {
-			buf, err = os.ReadFile(fn)
-			if err != nil {
-				continue
-			}
-			parts := strings.SplitN(filepath.Base(fn), "_", 2)
-			if len(parts) != 2 {
-				continue
-			}
-			measurement := parts[1]
-
-			// Skip already added values
-			switch measurement {
-			case "label", "input":
-				continue
-			}
 
-			v, err := strconv.ParseFloat(strings.TrimSpace(string(buf)), 64)
-			if err != nil {
-				continue
+		temp := TemperatureStat{
+			Name:        s.Chip,
+			Label:       strings.ToLower(s.Label),
+			Device:      s.Device,
+			Temperature: v,
+			Additional:  make(map[string]interface{}, len(s.Values)-1),
+		}
+		for measurement, value := range s.Values {
+			if measurement != "input" {
+				temp.Additional[measurement] = value
 			}
-			temp.Additional[measurement] = v / scalingFactor
 		}
-
 		stats = append(stats, temp)
 	}
 
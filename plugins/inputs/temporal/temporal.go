@@ -0,0 +1,287 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package temporal
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Names of the gRPC services registered with the health server of the
+// respective Temporal service
+const (
+	frontendService = "temporal.api.workflowservice.v1.WorkflowService"
+	historyService  = "temporal.server.api.historyservice.v1.HistoryService"
+)
+
+var taskQueueTypes = map[string]string{
+	"workflow": "TASK_QUEUE_TYPE_WORKFLOW",
+	"activity": "TASK_QUEUE_TYPE_ACTIVITY",
+}
+
+type Temporal struct {
+	FrontendAddress  string          `toml:"frontend_address"`
+	HistoryAddresses []string        `toml:"history_addresses"`
+	URL              string          `toml:"url"`
+	Namespace        string          `toml:"namespace"`
+	TaskQueues       []string        `toml:"task_queues"`
+	APIKey           config.Secret   `toml:"api_key"`
+	Timeout          config.Duration `toml:"timeout"`
+	Log              telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	targets []*healthTarget
+	client  *http.Client
+}
+
+type healthTarget struct {
+	role    string
+	address string
+	service string
+	conn    *grpc.ClientConn
+	client  grpc_health_v1.HealthClient
+}
+
+func (*Temporal) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Temporal) Init() error {
+	if t.FrontendAddress == "" && len(t.HistoryAddresses) == 0 && len(t.TaskQueues) == 0 {
+		return errors.New("no 'frontend_address', 'history_addresses' or 'task_queues' configured")
+	}
+	if len(t.TaskQueues) > 0 {
+		if t.URL == "" {
+			return errors.New("'url' is required for querying task queues")
+		}
+		if _, err := url.Parse(t.URL); err != nil {
+			return fmt.Errorf("parsing 'url' failed: %w", err)
+		}
+	}
+	if t.Namespace == "" {
+		t.Namespace = "default"
+	}
+
+	tlsCfg, err := t.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	if t.FrontendAddress != "" {
+		t.targets = append(t.targets, &healthTarget{role: "frontend", address: t.FrontendAddress, service: frontendService})
+	}
+	for _, address := range t.HistoryAddresses {
+		t.targets = append(t.targets, &healthTarget{role: "history", address: address, service: historyService})
+	}
+
+	var creds credentials.TransportCredentials
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	} else {
+		creds = insecure.NewCredentials()
+	}
+	for _, target := range t.targets {
+		// The connection is only established on first use
+		conn, err := grpc.NewClient(target.address, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return fmt.Errorf("creating client for %q failed: %w", target.address, err)
+		}
+		target.conn = conn
+		target.client = grpc_health_v1.NewHealthClient(conn)
+	}
+
+	t.client = &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsCfg},
+		Timeout:   time.Duration(t.Timeout),
+	}
+
+	return nil
+}
+
+func (*Temporal) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (t *Temporal) Gather(acc telegraf.Accumulator) error {
+	for _, target := range t.targets {
+		if err := t.gatherHealth(acc, target); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	for _, queue := range t.TaskQueues {
+		for _, kind := range []string{"workflow", "activity"} {
+			if err := t.gatherTaskQueue(acc, queue, kind); err != nil {
+				acc.AddError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *Temporal) Stop() {
+	for _, target := range t.targets {
+		if target.conn != nil {
+			target.conn.Close()
+		}
+	}
+	if t.client != nil {
+		t.client.CloseIdleConnections()
+	}
+}
+
+func (t *Temporal) gatherHealth(acc telegraf.Accumulator, target *healthTarget) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(t.Timeout))
+	defer cancel()
+
+	if !t.APIKey.Empty() {
+		key, err := t.APIKey.Get()
+		if err != nil {
+			return fmt.Errorf("getting API key failed: %w", err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+key.String())
+		key.Destroy()
+	}
+
+	tags := map[string]string{
+		"service": target.role,
+		"address": target.address,
+	}
+
+	start := time.Now()
+	resp, err := target.client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: target.service})
+	if err != nil {
+		// Report unreachable services instead of dropping the metric to allow
+		// alerting on them
+		t.Log.Debugf("Health check of %s service at %q failed: %v", target.role, target.address, err)
+		acc.AddFields("temporal_health", map[string]interface{}{
+			"serving": false,
+			"status":  "UNREACHABLE",
+		}, tags)
+		return nil
+	}
+
+	acc.AddFields("temporal_health", map[string]interface{}{
+		"serving":          resp.Status == grpc_health_v1.HealthCheckResponse_SERVING,
+		"status":           resp.Status.String(),
+		"response_time_ms": float64(time.Since(start)) / float64(time.Millisecond),
+	}, tags)
+	return nil
+}
+
+// taskQueueDescription is the subset of the DescribeTaskQueue response
+// returned by the HTTP API, 64-bit integers and durations are encoded as
+// strings
+type taskQueueDescription struct {
+	Pollers []struct {
+		Identity string `json:"identity"`
+	} `json:"pollers"`
+	Stats *struct {
+		ApproximateBacklogCount string  `json:"approximateBacklogCount"`
+		ApproximateBacklogAge   string  `json:"approximateBacklogAge"`
+		TasksAddRate            float64 `json:"tasksAddRate"`
+		TasksDispatchRate       float64 `json:"tasksDispatchRate"`
+	} `json:"stats"`
+}
+
+func (t *Temporal) gatherTaskQueue(acc telegraf.Accumulator, queue, kind string) error {
+	query := url.Values{}
+	query.Set("taskQueueType", taskQueueTypes[kind])
+	query.Set("reportStats", "true")
+	address := strings.TrimRight(t.URL, "/") + "/api/v1/namespaces/" + url.PathEscape(t.Namespace) +
+		"/task-queues/" + url.PathEscape(queue) + "?" + query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if !t.APIKey.Empty() {
+		key, err := t.APIKey.Get()
+		if err != nil {
+			return fmt.Errorf("getting API key failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+key.String())
+		key.Destroy()
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("querying task queue %q failed: %w", queue, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("querying task queue %q returned HTTP status %s", queue, resp.Status)
+	}
+
+	var desc taskQueueDescription
+	if err := json.NewDecoder(resp.Body).Decode(&desc); err != nil {
+		return fmt.Errorf("decoding task queue %q failed: %w", queue, err)
+	}
+
+	tags := map[string]string{
+		"namespace":       t.Namespace,
+		"task_queue":      queue,
+		"task_queue_type": kind,
+	}
+	fields := map[string]interface{}{
+		"pollers": len(desc.Pollers),
+	}
+
+	// Statistics are only reported by Temporal v1.25 and later
+	if desc.Stats != nil {
+		fields["tasks_add_rate"] = desc.Stats.TasksAddRate
+		fields["tasks_dispatch_rate"] = desc.Stats.TasksDispatchRate
+		fields["backlog_count"] = int64(0)
+		fields["backlog_age_ms"] = float64(0)
+		if desc.Stats.ApproximateBacklogCount != "" {
+			count, err := strconv.ParseInt(desc.Stats.ApproximateBacklogCount, 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing backlog count of %q failed: %w", queue, err)
+			}
+			fields["backlog_count"] = count
+		}
+		if desc.Stats.ApproximateBacklogAge != "" {
+			age, err := time.ParseDuration(desc.Stats.ApproximateBacklogAge)
+			if err != nil {
+				return fmt.Errorf("parsing backlog age of %q failed: %w", queue, err)
+			}
+			fields["backlog_age_ms"] = float64(age) / float64(time.Millisecond)
+		}
+	}
+
+	acc.AddGauge("temporal_task_queue", fields, tags)
+	return nil
+}
+
+func init() {
+	inputs.Add("temporal", func() telegraf.Input {
+		return &Temporal{
+			Namespace: "default",
+			Timeout:   config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package temporal
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestHealth(t *testing.T) {
+	// Setup a server reporting the frontend to be serving and the history to
+	// be shutting down
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(frontendService, grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(historyService, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener) //nolint:errcheck // test will fail anyway if the server fails
+	defer server.Stop()
+
+	// Use a second, unused address for an unreachable history instance
+	unused, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := unused.Addr().String()
+	require.NoError(t, unused.Close())
+
+	plugin := &Temporal{
+		FrontendAddress:  listener.Addr().String(),
+		HistoryAddresses: []string{listener.Addr().String(), unreachable},
+		Timeout:          config.Duration(time.Second),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"temporal_health",
+			map[string]string{"service": "frontend", "address": listener.Addr().String()},
+			map[string]interface{}{"serving": true, "status": "SERVING"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"temporal_health",
+			map[string]string{"service": "history", "address": listener.Addr().String()},
+			map[string]interface{}{"serving": false, "status": "NOT_SERVING"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"temporal_health",
+			map[string]string{"service": "history", "address": unreachable},
+			map[string]interface{}{"serving": false, "status": "UNREACHABLE"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(), testutil.IgnoreFields("response_time_ms"))
+}
+
+func TestTaskQueues(t *testing.T) {
+	responses := map[string]string{
+		"TASK_QUEUE_TYPE_WORKFLOW": `{
+			"pollers": [
+				{"lastAccessTime": "2024-10-17T08:12:41.302Z", "identity": "1@worker-1", "ratePerSecond": 100000},
+				{"lastAccessTime": "2024-10-17T08:12:40.113Z", "identity": "1@worker-2", "ratePerSecond": 100000}
+			],
+			"stats": {
+				"approximateBacklogCount": "12",
+				"approximateBacklogAge": "3.500s",
+				"tasksAddRate": 4.2,
+				"tasksDispatchRate": 3.9
+			}
+		}`,
+		// Older servers do not report statistics
+		"TASK_QUEUE_TYPE_ACTIVITY": `{"pollers": []}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/payments/task-queues/orders" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.Query().Get("reportStats") != "true" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, err := w.Write([]byte(responses[r.URL.Query().Get("taskQueueType")])); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Temporal{
+		URL:        server.URL,
+		Namespace:  "payments",
+		TaskQueues: []string{"orders"},
+		APIKey:     config.NewSecret([]byte("secret")),
+		Timeout:    config.Duration(time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"temporal_task_queue",
+			map[string]string{"namespace": "payments", "task_queue": "orders", "task_queue_type": "workflow"},
+			map[string]interface{}{
+				"pollers":             2,
+				"backlog_count":       int64(12),
+				"backlog_age_ms":      float64(3500),
+				"tasks_add_rate":      float64(4.2),
+				"tasks_dispatch_rate": float64(3.9),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"temporal_task_queue",
+			map[string]string{"namespace": "payments", "task_queue": "orders", "task_queue_type": "activity"},
+			map[string]interface{}{"pollers": 0},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitInvalid(t *testing.T) {
+	require.ErrorContains(t, (&Temporal{}).Init(), "no 'frontend_address'")
+	require.ErrorContains(t, (&Temporal{TaskQueues: []string{"orders"}}).Init(), "'url' is required")
+}
@@ -13,12 +13,16 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/common/appserver"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -29,6 +33,7 @@ var sampleConfig string
 type Uwsgi struct {
 	Servers []string        `toml:"servers"`
 	Timeout config.Duration `toml:"timeout"`
+	Schema  string          `toml:"schema"`
 
 	client *http.Client
 }
@@ -37,6 +42,13 @@ func (*Uwsgi) SampleConfig() string {
 	return sampleConfig
 }
 
+func (u *Uwsgi) Init() error {
+	if u.Schema == "" {
+		u.Schema = appserver.SchemaNative
+	}
+	return appserver.CheckSchema(u.Schema)
+}
+
 // Gather collect data from uWSGI Server
 func (u *Uwsgi) Gather(acc telegraf.Accumulator) error {
 	if u.client == nil {
@@ -46,7 +58,7 @@ func (u *Uwsgi) Gather(acc telegraf.Accumulator) error {
 	}
 	wg := &sync.WaitGroup{}
 
-	for _, s := range u.Servers {
+	for _, s := range expandServers(acc, u.Servers) {
 		wg.Add(1)
 		go func(s string) {
 			defer wg.Done()
@@ -106,11 +118,92 @@ func (u *Uwsgi) gatherServer(acc telegraf.Accumulator, address *url.URL) error {
 		return fmt.Errorf("failed to decode json payload from %q: %w", address.String(), err)
 	}
 
+	if u.Schema == appserver.SchemaUnified {
+		gatherUnified(acc, address, &s)
+		return nil
+	}
 	u.gatherStatServer(acc, &s)
 
 	return err
 }
 
+// expandServers resolves glob patterns in the path of unix socket servers to
+// discover the stats sockets of e.g. all vassals of an emperor
+func expandServers(acc telegraf.Accumulator, servers []string) []string {
+	expanded := make([]string, 0, len(servers))
+	for _, s := range servers {
+		path, found := strings.CutPrefix(s, "unix://")
+		if !found || !strings.ContainsAny(path, "*?[") {
+			expanded = append(expanded, s)
+			continue
+		}
+		glob, err := globpath.Compile(path)
+		if err != nil {
+			acc.AddError(fmt.Errorf("could not compile glob %q: %w", path, err))
+			continue
+		}
+		matches := glob.Match()
+		if len(matches) == 0 {
+			acc.AddError(fmt.Errorf("no stats socket matching %q", path))
+			continue
+		}
+		for _, match := range matches {
+			expanded = append(expanded, "unix://"+match)
+		}
+	}
+	return expanded
+}
+
+// gatherUnified adds the statistics using the common application server
+// schema. The pool is named after the stats socket file for unix sockets and
+// after the host otherwise.
+func gatherUnified(acc telegraf.Accumulator, address *url.URL, s *StatsServer) {
+	name := address.Host
+	if address.Scheme == "unix" {
+		name = strings.TrimSuffix(filepath.Base(address.Path), filepath.Ext(address.Path))
+	}
+
+	pool := &appserver.Pool{
+		Server:     "uwsgi",
+		Name:       name,
+		Address:    address.String(),
+		Workers:    int64(len(s.Workers)),
+		QueueDepth: appserver.Int64(int64(s.ListenQueue)),
+	}
+	if len(s.Sockets) > 0 {
+		var maxQueue int64
+		for _, socket := range s.Sockets {
+			maxQueue += int64(socket.MaxQueue)
+		}
+		pool.MaxQueueDepth = &maxQueue
+	}
+
+	var busy, idle, requests int64
+	for _, w := range s.Workers {
+		switch w.Status {
+		case "busy":
+			busy++
+		case "idle":
+			idle++
+		}
+		requests += int64(w.Requests)
+
+		duration := time.Duration(w.AvgRt) * time.Microsecond
+		pool.WorkerStats = append(pool.WorkerStats, appserver.Worker{
+			ID:              strconv.Itoa(w.WorkerID),
+			PID:             int64(w.PID),
+			State:           w.Status,
+			Requests:        appserver.Int64(int64(w.Requests)),
+			RequestDuration: &duration,
+		})
+	}
+	pool.BusyWorkers = &busy
+	pool.IdleWorkers = &idle
+	pool.Requests = &requests
+
+	pool.Add(acc, time.Now())
+}
+
 func (u *Uwsgi) gatherStatServer(acc telegraf.Accumulator, s *StatsServer) {
 	fields := map[string]interface{}{
 		"listen_queue":        s.ListenQueue,
@@ -128,11 +221,29 @@ func (u *Uwsgi) gatherStatServer(acc telegraf.Accumulator, s *StatsServer) {
 	}
 	acc.AddFields("uwsgi_overview", fields, tags)
 
+	u.gatherSockets(acc, s)
 	u.gatherWorkers(acc, s)
 	u.gatherApps(acc, s)
 	u.gatherCores(acc, s)
 }
 
+func (u *Uwsgi) gatherSockets(acc telegraf.Accumulator, s *StatsServer) {
+	for _, socket := range s.Sockets {
+		fields := map[string]interface{}{
+			"queue":       socket.Queue,
+			"max_queue":   socket.MaxQueue,
+			"shared":      socket.Shared,
+			"can_offload": socket.CanOffload,
+		}
+		tags := map[string]string{
+			"name":   socket.Name,
+			"proto":  socket.Proto,
+			"source": s.source,
+		}
+		acc.AddFields("uwsgi_sockets", fields, tags)
+	}
+}
+
 func (u *Uwsgi) gatherWorkers(acc telegraf.Accumulator, s *StatsServer) {
 	for _, w := range s.Workers {
 		fields := map[string]interface{}{
@@ -226,9 +337,23 @@ type StatsServer struct {
 	SignalQueue       int `json:"signal_queue"`
 	Load              int `json:"load"`
 
+	Sockets []*Socket `json:"sockets"`
 	Workers []*Worker `json:"workers"`
 }
 
+// Socket defines the socket metric structure.
+type Socket struct {
+	// Tags
+	Name  string `json:"name"`
+	Proto string `json:"proto"`
+
+	// Fields
+	Queue      int `json:"queue"`
+	MaxQueue   int `json:"max_queue"`
+	Shared     int `json:"shared"`
+	CanOffload int `json:"can_offload"`
+}
+
 // Worker defines the worker metric structure.
 type Worker struct {
 	// Tags
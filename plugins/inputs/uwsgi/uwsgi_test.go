@@ -1,14 +1,20 @@
 package uwsgi_test
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs/uwsgi"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -195,3 +201,90 @@ func TestUnixSocketError(t *testing.T) {
 	require.NoError(t, plugin.Gather(&acc))
 	require.Len(t, acc.Errors, 1)
 }
+
+func TestUnifiedSchemaUnixSocketGlob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows due to missing unix socket support")
+	}
+
+	stats, err := os.ReadFile(filepath.Join("testdata", "stats.json"))
+	require.NoError(t, err)
+
+	// Serve the stats on two sockets similar to the stats sockets of vassals
+	dir := t.TempDir()
+	for _, name := range []string{"blog", "shop"} {
+		listener, err := net.Listen("unix", filepath.Join(dir, name+".stats"))
+		require.NoError(t, err)
+		defer listener.Close()
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				_, _ = conn.Write(stats)
+				conn.Close()
+			}
+		}()
+	}
+
+	plugin := &uwsgi.Uwsgi{
+		Servers: []string{"unix://" + filepath.Join(dir, "*.stats")},
+		Timeout: config.Duration(5 * time.Second),
+		Schema:  "unified",
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	var expected []telegraf.Metric
+	for _, name := range []string{"blog", "shop"} {
+		address := "unix://" + filepath.Join(dir, name+".stats")
+		expected = append(expected,
+			metric.New(
+				"app_server",
+				map[string]string{"server": "uwsgi", "pool": name, "address": address},
+				map[string]interface{}{
+					"workers":      int64(2),
+					"workers_busy": int64(1),
+					"workers_idle": int64(1),
+					"queue_depth":  int64(3),
+					"queue_max":    int64(100),
+					"requests":     int64(3008),
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"app_server_worker",
+				map[string]string{"server": "uwsgi", "pool": name, "address": address, "worker": "1"},
+				map[string]interface{}{
+					"pid":                 int64(4712),
+					"state":               "busy",
+					"requests":            int64(1520),
+					"request_duration_us": int64(60200),
+				},
+				time.Unix(0, 0),
+			),
+			metric.New(
+				"app_server_worker",
+				map[string]string{"server": "uwsgi", "pool": name, "address": address, "worker": "2"},
+				map[string]interface{}{
+					"pid":                 int64(4713),
+					"state":               "idle",
+					"requests":            int64(1488),
+					"request_duration_us": int64(58900),
+				},
+				time.Unix(0, 0),
+			),
+		)
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestInvalidSchema(t *testing.T) {
+	plugin := &uwsgi.Uwsgi{Schema: "foo"}
+	require.ErrorContains(t, plugin.Init(), "invalid schema")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -27,6 +28,7 @@ type Vault struct {
 	URL       string          `toml:"url"`
 	TokenFile string          `toml:"token_file"`
 	Token     string          `toml:"token"`
+	Collect   []string        `toml:"collect"`
 	Log       telegraf.Logger `toml:"-"`
 	common_http.HTTPClientConfig
 
@@ -52,6 +54,13 @@ func (n *Vault) Init() error {
 		return errors.New("both token_file and token are set")
 	}
 
+	if len(n.Collect) == 0 {
+		n.Collect = []string{"metrics"}
+	}
+	if err := choice.CheckSlice(n.Collect, []string{"metrics", "seal_status", "replication"}); err != nil {
+		return fmt.Errorf(`cannot verify "collect" setting: %w`, err)
+	}
+
 	if n.TokenFile != "" {
 		token, err := os.ReadFile(n.TokenFile)
 		if err != nil {
@@ -76,12 +85,34 @@ func (n *Vault) Start(_ telegraf.Accumulator) error {
 
 // Gather, collects metrics from Vault endpoint
 func (n *Vault) Gather(acc telegraf.Accumulator) error {
-	sysMetrics, err := n.loadJSON(n.URL + "/v1/sys/metrics")
-	if err != nil {
-		return err
+	if choice.Contains("metrics", n.Collect) {
+		var sysMetrics SysMetrics
+		if err := n.loadJSON(n.URL+"/v1/sys/metrics", &sysMetrics); err != nil {
+			acc.AddError(err)
+		} else if err := buildVaultMetrics(acc, &sysMetrics); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if choice.Contains("seal_status", n.Collect) {
+		var status sealStatus
+		if err := n.loadJSON(n.URL+"/v1/sys/seal-status", &status); err != nil {
+			acc.AddError(err)
+		} else {
+			buildSealStatus(acc, &status)
+		}
 	}
 
-	return buildVaultMetrics(acc, sysMetrics)
+	if choice.Contains("replication", n.Collect) {
+		var status replicationStatus
+		if err := n.loadJSON(n.URL+"/v1/sys/replication/status", &status); err != nil {
+			acc.AddError(err)
+		} else {
+			buildReplicationStatus(acc, &status)
+		}
+	}
+
+	return nil
 }
 
 func (n *Vault) Stop() {
@@ -90,10 +121,10 @@ func (n *Vault) Stop() {
 	}
 }
 
-func (n *Vault) loadJSON(url string) (*SysMetrics, error) {
+func (n *Vault) loadJSON(url string, v interface{}) error {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Set("X-Vault-Token", n.Token)
@@ -101,21 +132,19 @@ func (n *Vault) loadJSON(url string) (*SysMetrics, error) {
 
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making HTTP request to %q: %w", url, err)
+		return fmt.Errorf("error making HTTP request to %q: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s returned HTTP status %s", url, resp.Status)
+		return fmt.Errorf("%s returned HTTP status %s", url, resp.Status)
 	}
 
-	var metrics SysMetrics
-	err = json.NewDecoder(resp.Body).Decode(&metrics)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing json response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error parsing json response: %w", err)
 	}
 
-	return &metrics, nil
+	return nil
 }
 
 // buildVaultMetrics, it builds all the metrics and adds them to the accumulator
@@ -189,9 +218,70 @@ func buildVaultMetrics(acc telegraf.Accumulator, sysMetrics *SysMetrics) error {
 	return nil
 }
 
+// buildSealStatus adds the seal state of the node
+func buildSealStatus(acc telegraf.Accumulator, status *sealStatus) {
+	tags := map[string]string{
+		"type": status.Type,
+	}
+	if status.ClusterName != "" {
+		tags["cluster"] = status.ClusterName
+	}
+	if status.Version != "" {
+		tags["version"] = status.Version
+	}
+
+	fields := map[string]interface{}{
+		"sealed":      status.Sealed,
+		"initialized": status.Initialized,
+		"threshold":   status.Threshold,
+		"shares":      status.Shares,
+		"progress":    status.Progress,
+	}
+	acc.AddGauge("vault_seal_status", fields, tags)
+}
+
+// buildReplicationStatus adds the state of the disaster-recovery and
+// performance replication, replication being disabled is skipped
+func buildReplicationStatus(acc telegraf.Accumulator, status *replicationStatus) {
+	states := []struct {
+		kind  string
+		state *replicationState
+	}{
+		{"dr", status.Data.DR},
+		{"performance", status.Data.Performance},
+	}
+	for _, entry := range states {
+		kind, s := entry.kind, entry.state
+		if s == nil || s.Mode == "" || s.Mode == "disabled" {
+			continue
+		}
+
+		tags := map[string]string{
+			"type": kind,
+			"mode": s.Mode,
+		}
+		if s.State != "" {
+			tags["state"] = s.State
+		}
+		if s.ClusterID != "" {
+			tags["cluster_id"] = s.ClusterID
+		}
+
+		fields := map[string]interface{}{
+			"last_wal":          s.LastWAL,
+			"known_secondaries": len(s.KnownSecondaries),
+		}
+		if s.Mode == "secondary" {
+			fields["last_remote_wal"] = s.LastRemoteWAL
+		}
+		acc.AddGauge("vault_replication", fields, tags)
+	}
+}
+
 func init() {
 	inputs.Add("vault", func() telegraf.Input {
 		return &Vault{
+			Collect: []string{"metrics"},
 			HTTPClientConfig: common_http.HTTPClientConfig{
 				ResponseHeaderTimeout: config.Duration(5 * time.Second),
 			},
@@ -38,3 +38,30 @@ type summary struct {
 	Mean   float64 `json:"Mean"`
 	Stddev float64 `json:"Stddev"`
 }
+
+type sealStatus struct {
+	Type        string `json:"type"`
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	Threshold   int    `json:"t"`
+	Shares      int    `json:"n"`
+	Progress    int    `json:"progress"`
+	Version     string `json:"version"`
+	ClusterName string `json:"cluster_name"`
+}
+
+type replicationStatus struct {
+	Data struct {
+		DR          *replicationState `json:"dr"`
+		Performance *replicationState `json:"performance"`
+	} `json:"data"`
+}
+
+type replicationState struct {
+	Mode             string   `json:"mode"`
+	State            string   `json:"state"`
+	ClusterID        string   `json:"cluster_id"`
+	LastWAL          uint64   `json:"last_wal"`
+	LastRemoteWAL    uint64   `json:"last_remote_wal"`
+	KnownSecondaries []string `json:"known_secondaries"`
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/go-connections/nat"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -188,6 +189,90 @@ func TestRedirect(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual)
 }
 
+func TestSealStatusAndReplication(t *testing.T) {
+	endpoints := map[string]string{
+		"/v1/sys/seal-status":        "testdata/response_seal_status.json",
+		"/v1/sys/replication/status": "testdata/response_replication_status.json",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.CDDrgg5zPv5ssI0Z2P4qxJj2" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fn, found := endpoints[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		response, err := os.ReadFile(fn)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"vault_seal_status",
+			map[string]string{
+				"type":    "shamir",
+				"cluster": "vault-cluster-23b671c7",
+				"version": "1.13.3",
+			},
+			map[string]interface{}{
+				"sealed":      false,
+				"initialized": true,
+				"threshold":   3,
+				"shares":      5,
+				"progress":    0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"vault_replication",
+			map[string]string{
+				"type":       "dr",
+				"mode":       "secondary",
+				"state":      "stream-wals",
+				"cluster_id": "5c3b5f17-5f6b-26c5-b0ee-d2e2b8e0a4c5",
+			},
+			map[string]interface{}{
+				"last_wal":          uint64(1438),
+				"last_remote_wal":   uint64(1432),
+				"known_secondaries": 0,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	plugin := &Vault{
+		URL:     server.URL,
+		Token:   "s.CDDrgg5zPv5ssI0Z2P4qxJj2",
+		Collect: []string{"seal_status", "replication"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInitInvalidCollect(t *testing.T) {
+	plugin := &Vault{
+		Token:   "s.CDDrgg5zPv5ssI0Z2P4qxJj2",
+		Collect: []string{"metrics", "leases"},
+	}
+	require.ErrorContains(t, plugin.Init(), `cannot verify "collect" setting`)
+}
+
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -0,0 +1,246 @@
+//go:build windows
+
+package win_etw
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Input types of event properties, see TDH_IN_TYPE in tdh.h
+const (
+	inTypeUnicodeString        = 1
+	inTypeAnsiString           = 2
+	inTypeInt8                 = 3
+	inTypeUint8                = 4
+	inTypeInt16                = 5
+	inTypeUint16               = 6
+	inTypeInt32                = 7
+	inTypeUint32               = 8
+	inTypeInt64                = 9
+	inTypeUint64               = 10
+	inTypeFloat                = 11
+	inTypeDouble               = 12
+	inTypeBoolean              = 13
+	inTypeBinary               = 14
+	inTypeGUID                 = 15
+	inTypePointer              = 16
+	inTypeFiletime             = 17
+	inTypeSystemtime           = 18
+	inTypeSID                  = 19
+	inTypeHexInt32             = 20
+	inTypeHexInt64             = 21
+	inTypeCountedString        = 300
+	inTypeCountedAnsiString    = 301
+	inTypeManifestCountedStr   = 22
+	inTypeManifestCountedAnsi  = 23
+	inTypeManifestCountedBytes = 25
+)
+
+// Output types changing the interpretation of the data, see TDH_OUT_TYPE
+const (
+	outTypePort          = 22
+	outTypeIPv4          = 23
+	outTypeIPv6          = 24
+	outTypeSocketAddress = 25
+)
+
+// Address families of socket addresses
+const (
+	afInet  = 2
+	afInet6 = 23
+)
+
+var errShortData = errors.New("data too short")
+
+// decodeProperty converts the raw data of an event property to a value
+// suitable for a tag or field
+func decodeProperty(inType, outType uint16, data []byte) (interface{}, error) {
+	switch inType {
+	case inTypeUnicodeString:
+		return decodeUTF16(data), nil
+	case inTypeAnsiString:
+		return decodeANSI(data), nil
+	case inTypeCountedString, inTypeManifestCountedStr:
+		if len(data) < 2 {
+			return nil, errShortData
+		}
+		n := min(int(binary.LittleEndian.Uint16(data)), len(data)-2)
+		return decodeUTF16(data[2 : 2+n]), nil
+	case inTypeCountedAnsiString, inTypeManifestCountedAnsi:
+		if len(data) < 2 {
+			return nil, errShortData
+		}
+		n := min(int(binary.LittleEndian.Uint16(data)), len(data)-2)
+		return decodeANSI(data[2 : 2+n]), nil
+	case inTypeInt8:
+		if len(data) < 1 {
+			return nil, errShortData
+		}
+		return int64(int8(data[0])), nil
+	case inTypeUint8:
+		if len(data) < 1 {
+			return nil, errShortData
+		}
+		return uint64(data[0]), nil
+	case inTypeInt16:
+		if len(data) < 2 {
+			return nil, errShortData
+		}
+		return int64(int16(binary.LittleEndian.Uint16(data))), nil
+	case inTypeUint16:
+		if len(data) < 2 {
+			return nil, errShortData
+		}
+		if outType == outTypePort {
+			return uint64(binary.BigEndian.Uint16(data)), nil
+		}
+		return uint64(binary.LittleEndian.Uint16(data)), nil
+	case inTypeInt32:
+		if len(data) < 4 {
+			return nil, errShortData
+		}
+		return int64(int32(binary.LittleEndian.Uint32(data))), nil
+	case inTypeUint32, inTypeHexInt32:
+		if len(data) < 4 {
+			return nil, errShortData
+		}
+		if outType == outTypeIPv4 {
+			return net.IP(data[:4]).String(), nil
+		}
+		return uint64(binary.LittleEndian.Uint32(data)), nil
+	case inTypeInt64:
+		if len(data) < 8 {
+			return nil, errShortData
+		}
+		return int64(binary.LittleEndian.Uint64(data)), nil
+	case inTypeUint64, inTypeHexInt64:
+		if len(data) < 8 {
+			return nil, errShortData
+		}
+		return binary.LittleEndian.Uint64(data), nil
+	case inTypePointer:
+		switch len(data) {
+		case 4:
+			return uint64(binary.LittleEndian.Uint32(data)), nil
+		case 8:
+			return binary.LittleEndian.Uint64(data), nil
+		}
+		return nil, fmt.Errorf("invalid pointer size %d", len(data))
+	case inTypeFloat:
+		if len(data) < 4 {
+			return nil, errShortData
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), nil
+	case inTypeDouble:
+		if len(data) < 8 {
+			return nil, errShortData
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+	case inTypeBoolean:
+		if len(data) < 4 {
+			return nil, errShortData
+		}
+		return binary.LittleEndian.Uint32(data) != 0, nil
+	case inTypeBinary, inTypeManifestCountedBytes:
+		switch outType {
+		case outTypeIPv6:
+			if len(data) < 16 {
+				return nil, errShortData
+			}
+			return net.IP(data[:16]).String(), nil
+		case outTypeSocketAddress:
+			return decodeSocketAddress(data)
+		}
+		return hex.EncodeToString(data), nil
+	case inTypeGUID:
+		if len(data) < 16 {
+			return nil, errShortData
+		}
+		guid := windows.GUID{
+			Data1: binary.LittleEndian.Uint32(data),
+			Data2: binary.LittleEndian.Uint16(data[4:]),
+			Data3: binary.LittleEndian.Uint16(data[6:]),
+		}
+		copy(guid.Data4[:], data[8:16])
+		return guid.String(), nil
+	case inTypeFiletime:
+		if len(data) < 8 {
+			return nil, errShortData
+		}
+		ft := windows.Filetime{
+			LowDateTime:  binary.LittleEndian.Uint32(data),
+			HighDateTime: binary.LittleEndian.Uint32(data[4:]),
+		}
+		return time.Unix(0, ft.Nanoseconds()).UTC().Format(time.RFC3339Nano), nil
+	case inTypeSystemtime:
+		if len(data) < 16 {
+			return nil, errShortData
+		}
+		field := func(i int) int { return int(binary.LittleEndian.Uint16(data[2*i:])) }
+		t := time.Date(field(0), time.Month(field(1)), field(3), field(4), field(5), field(6), field(7)*int(time.Millisecond), time.UTC)
+		return t.Format(time.RFC3339Nano), nil
+	case inTypeSID:
+		if len(data) < 8 {
+			return nil, errShortData
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&data[0])) //nolint:gosec // G103: Valid use of unsafe call to interpret the SID
+		if !sid.IsValid() {
+			return nil, errors.New("invalid SID")
+		}
+		return sid.String(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %d", inType)
+}
+
+func decodeUTF16(data []byte) string {
+	s := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		c := binary.LittleEndian.Uint16(data[i:])
+		if c == 0 {
+			break
+		}
+		s = append(s, c)
+	}
+	return string(utf16.Decode(s))
+}
+
+func decodeANSI(data []byte) string {
+	for i, c := range data {
+		if c == 0 {
+			return string(data[:i])
+		}
+	}
+	return string(data)
+}
+
+func decodeSocketAddress(data []byte) (string, error) {
+	if len(data) < 2 {
+		return "", errShortData
+	}
+	switch binary.LittleEndian.Uint16(data) {
+	case afInet:
+		if len(data) < 8 {
+			return "", errShortData
+		}
+		port := binary.BigEndian.Uint16(data[2:])
+		return net.JoinHostPort(net.IP(data[4:8]).String(), strconv.Itoa(int(port))), nil
+	case afInet6:
+		if len(data) < 24 {
+			return "", errShortData
+		}
+		port := binary.BigEndian.Uint16(data[2:])
+		return net.JoinHostPort(net.IP(data[8:24]).String(), strconv.Itoa(int(port))), nil
+	}
+	return hex.EncodeToString(data), nil
+}
@@ -0,0 +1,184 @@
+//go:build windows
+
+package win_etw
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var levels = map[string]uint8{
+	"critical":    1,
+	"error":       2,
+	"warning":     3,
+	"information": 4,
+	"verbose":     5,
+}
+
+var levelNames = map[uint8]string{
+	0: "always",
+	1: "critical",
+	2: "error",
+	3: "warning",
+	4: "information",
+	5: "verbose",
+}
+
+// Provider configures the events of a provider enabled in the session
+type Provider struct {
+	Name            string            `toml:"name"`
+	Level           string            `toml:"level"`
+	MatchAnyKeyword string            `toml:"match_any_keyword"`
+	MatchAllKeyword string            `toml:"match_all_keyword"`
+	EventIDs        []uint16          `toml:"event_ids"`
+	Measurement     string            `toml:"measurement"`
+	TagProperties   []string          `toml:"tag_properties"`
+	FieldProperties []string          `toml:"field_properties"`
+	PropertyNames   map[string]string `toml:"property_names"`
+
+	guid        windows.GUID
+	level       uint8
+	matchAny    uint64
+	matchAll    uint64
+	eventIDs    map[uint16]bool
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+}
+
+// event contains the decoded information of an event record
+type event struct {
+	id         uint16
+	level      uint8
+	name       string
+	task       string
+	opcode     string
+	processID  uint32
+	threadID   uint32
+	timestamp  time.Time
+	properties []property
+}
+
+type property struct {
+	name  string
+	value interface{}
+}
+
+func (p *Provider) init(lookup func(string) (windows.GUID, error)) error {
+	if p.Name == "" {
+		return errors.New("provider name required")
+	}
+	if strings.HasPrefix(p.Name, "{") {
+		guid, err := windows.GUIDFromString(p.Name)
+		if err != nil {
+			return fmt.Errorf("parsing GUID failed: %w", err)
+		}
+		p.guid = guid
+	} else {
+		guid, err := lookup(p.Name)
+		if err != nil {
+			return err
+		}
+		p.guid = guid
+	}
+
+	if p.Level == "" {
+		p.Level = "information"
+	}
+	level, found := levels[p.Level]
+	if !found {
+		return fmt.Errorf("invalid level %q", p.Level)
+	}
+	p.level = level
+
+	var err error
+	if p.matchAny, err = parseKeyword(p.MatchAnyKeyword); err != nil {
+		return fmt.Errorf("invalid match_any_keyword: %w", err)
+	}
+	if p.matchAll, err = parseKeyword(p.MatchAllKeyword); err != nil {
+		return fmt.Errorf("invalid match_all_keyword: %w", err)
+	}
+
+	if len(p.EventIDs) > 0 {
+		p.eventIDs = make(map[uint16]bool, len(p.EventIDs))
+		for _, id := range p.EventIDs {
+			p.eventIDs[id] = true
+		}
+	}
+
+	if p.Measurement == "" {
+		p.Measurement = "win_etw"
+	}
+	if p.tagFilter, err = filter.Compile(p.TagProperties); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if p.FieldProperties == nil {
+		p.FieldProperties = []string{"*"}
+	}
+	if p.fieldFilter, err = filter.Compile(p.FieldProperties); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func parseKeyword(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 0, 64)
+}
+
+// accepts returns true if the event with the given ID should be converted
+func (p *Provider) accepts(id uint16) bool {
+	return p.eventIDs == nil || p.eventIDs[id]
+}
+
+// metric converts the event to a metric with the properties selected by the
+// tag and field filters
+func (p *Provider) metric(e *event) telegraf.Metric {
+	tags := map[string]string{
+		"provider": p.Name,
+		"event_id": strconv.FormatUint(uint64(e.id), 10),
+		"level":    levelNames[e.level],
+	}
+	if tags["level"] == "" {
+		tags["level"] = strconv.FormatUint(uint64(e.level), 10)
+	}
+	if e.name != "" {
+		tags["event"] = e.name
+	}
+	if e.task != "" {
+		tags["task"] = e.task
+	}
+	if e.opcode != "" {
+		tags["opcode"] = e.opcode
+	}
+
+	fields := map[string]interface{}{
+		"process_id": uint64(e.processID),
+		"thread_id":  uint64(e.threadID),
+	}
+	for _, prop := range e.properties {
+		name := prop.name
+		if rename, found := p.PropertyNames[name]; found {
+			name = rename
+		}
+		switch {
+		case p.tagFilter != nil && p.tagFilter.Match(prop.name):
+			tags[name] = fmt.Sprint(prop.value)
+		case p.fieldFilter != nil && p.fieldFilter.Match(prop.name):
+			fields[name] = prop.value
+		}
+	}
+
+	return metric.New(p.Measurement, tags, fields, e.timestamp)
+}
@@ -0,0 +1,373 @@
+//go:build windows
+
+package win_etw
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Definitions of the Event Tracing for Windows API, see
+// https://learn.microsoft.com/en-us/windows/win32/api/evntrace/
+// The structures match the layout on 64-bit platforms only.
+
+const (
+	wnodeFlagTracedGUID = 0x00020000
+
+	eventTraceRealTimeMode = 0x00000100
+	eventTraceControlStop  = 1
+
+	eventControlCodeEnableProvider  = 1
+	eventControlCodeDisableProvider = 0
+
+	processTraceModeRealTime    = 0x00000100
+	processTraceModeEventRecord = 0x10000000
+
+	invalidProcessTraceHandle = ^uint64(0)
+
+	eventHeaderFlagStringOnly = 0x0004
+
+	propertyStruct     = 0x1
+	propertyParamCount = 0x2
+	propertyParamLen   = 0x4
+)
+
+// wnodeHeader mirrors WNODE_HEADER
+type wnodeHeader struct {
+	BufferSize        uint32
+	ProviderID        uint32
+	HistoricalContext uint64
+	TimeStamp         int64
+	GUID              windows.GUID
+	ClientContext     uint32
+	Flags             uint32
+}
+
+// eventTraceProperties mirrors EVENT_TRACE_PROPERTIES
+type eventTraceProperties struct {
+	Wnode               wnodeHeader
+	BufferSize          uint32
+	MinimumBuffers      uint32
+	MaximumBuffers      uint32
+	MaximumFileSize     uint32
+	LogFileMode         uint32
+	FlushTimer          uint32
+	EnableFlags         uint32
+	AgeLimit            int32
+	NumberOfBuffers     uint32
+	FreeBuffers         uint32
+	EventsLost          uint32
+	BuffersWritten      uint32
+	LogBuffersLost      uint32
+	RealTimeBuffersLost uint32
+	LoggerThreadID      windows.Handle
+	LogFileNameOffset   uint32
+	LoggerNameOffset    uint32
+}
+
+// sessionProperties is an EVENT_TRACE_PROPERTIES structure followed by the
+// space for the session name as required by StartTrace and ControlTrace
+type sessionProperties struct {
+	eventTraceProperties
+	loggerName [1024]uint16
+}
+
+func newSessionProperties(bufferSize uint32) *sessionProperties {
+	p := &sessionProperties{}
+	p.Wnode.BufferSize = uint32(unsafe.Sizeof(*p))
+	p.Wnode.ClientContext = 1 // query performance counter timestamps
+	p.Wnode.Flags = wnodeFlagTracedGUID
+	p.BufferSize = bufferSize
+	p.LogFileMode = eventTraceRealTimeMode
+	p.LoggerNameOffset = uint32(unsafe.Sizeof(p.eventTraceProperties))
+	return p
+}
+
+// eventTraceHeader mirrors EVENT_TRACE_HEADER
+type eventTraceHeader struct {
+	Size          uint16
+	FieldType     uint16
+	Version       uint32
+	ThreadID      uint32
+	ProcessID     uint32
+	TimeStamp     int64
+	GUID          windows.GUID
+	ProcessorTime uint64
+}
+
+// eventTrace mirrors EVENT_TRACE
+type eventTrace struct {
+	Header           eventTraceHeader
+	InstanceID       uint32
+	ParentInstanceID uint32
+	ParentGUID       windows.GUID
+	MofData          uintptr
+	MofLength        uint32
+	ClientContext    uint32
+}
+
+// traceLogfileHeader mirrors TRACE_LOGFILE_HEADER
+type traceLogfileHeader struct {
+	BufferSize         uint32
+	Version            uint32
+	ProviderVersion    uint32
+	NumberOfProcessors uint32
+	EndTime            int64
+	TimerResolution    uint32
+	MaximumFileSize    uint32
+	LogFileMode        uint32
+	BuffersWritten     uint32
+	LogInstanceGUID    windows.GUID
+	LoggerName         *uint16
+	LogFileName        *uint16
+	TimeZone           windows.Timezoneinformation
+	BootTime           int64
+	PerfFreq           int64
+	StartTime          int64
+	ReservedFlags      uint32
+	BuffersLost        uint32
+}
+
+// eventTraceLogfile mirrors EVENT_TRACE_LOGFILEW
+type eventTraceLogfile struct {
+	LogFileName         *uint16
+	LoggerName          *uint16
+	CurrentTime         int64
+	BuffersRead         uint32
+	ProcessTraceMode    uint32
+	CurrentEvent        eventTrace
+	LogfileHeader       traceLogfileHeader
+	BufferCallback      uintptr
+	BufferSize          uint32
+	Filled              uint32
+	EventsLost          uint32
+	EventRecordCallback uintptr
+	IsKernelTrace       uint32
+	Context             uintptr
+}
+
+// eventDescriptor mirrors EVENT_DESCRIPTOR
+type eventDescriptor struct {
+	ID      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+// eventHeader mirrors EVENT_HEADER
+type eventHeader struct {
+	Size            uint16
+	HeaderType      uint16
+	Flags           uint16
+	EventProperty   uint16
+	ThreadID        uint32
+	ProcessID       uint32
+	TimeStamp       int64
+	ProviderID      windows.GUID
+	EventDescriptor eventDescriptor
+	ProcessorTime   uint64
+	ActivityID      windows.GUID
+}
+
+// eventRecord mirrors EVENT_RECORD
+type eventRecord struct {
+	EventHeader       eventHeader
+	ProcessorIndex    uint16
+	LoggerID          uint16
+	ExtendedDataCount uint16
+	UserDataLength    uint16
+	ExtendedData      uintptr
+	UserData          unsafe.Pointer
+	UserContext       uintptr
+}
+
+// traceEventInfo mirrors the fixed part of TRACE_EVENT_INFO, the property
+// information follows in the buffer
+type traceEventInfo struct {
+	ProviderGUID          windows.GUID
+	EventGUID             windows.GUID
+	EventDescriptor       eventDescriptor
+	DecodingSource        uint32
+	ProviderNameOffset    uint32
+	LevelNameOffset       uint32
+	ChannelNameOffset     uint32
+	KeywordsNameOffset    uint32
+	TaskNameOffset        uint32
+	OpcodeNameOffset      uint32
+	EventMessageOffset    uint32
+	ProviderMessageOffset uint32
+	BinaryXMLOffset       uint32
+	BinaryXMLSize         uint32
+	EventNameOffset       uint32
+	EventAttributesOffset uint32
+	PropertyCount         uint32
+	TopLevelPropertyCount uint32
+	Flags                 uint32
+}
+
+// eventPropertyInfo mirrors EVENT_PROPERTY_INFO for non-struct properties
+type eventPropertyInfo struct {
+	Flags         uint32
+	NameOffset    uint32
+	InType        uint16
+	OutType       uint16
+	MapNameOffset uint32
+	Count         uint16
+	Length        uint16
+	Tags          uint32
+}
+
+// propertyDataDescriptor mirrors PROPERTY_DATA_DESCRIPTOR
+type propertyDataDescriptor struct {
+	PropertyName uint64
+	ArrayIndex   uint32
+	Reserved     uint32
+}
+
+// traceProviderInfo mirrors TRACE_PROVIDER_INFO
+type traceProviderInfo struct {
+	ProviderGUID       windows.GUID
+	SchemaSource       uint32
+	ProviderNameOffset uint32
+}
+
+var (
+	modadvapi32 = windows.NewLazySystemDLL("advapi32.dll")
+	modtdh      = windows.NewLazySystemDLL("tdh.dll")
+
+	procStartTraceW      = modadvapi32.NewProc("StartTraceW")
+	procControlTraceW    = modadvapi32.NewProc("ControlTraceW")
+	procEnableTraceEx2   = modadvapi32.NewProc("EnableTraceEx2")
+	procOpenTraceW       = modadvapi32.NewProc("OpenTraceW")
+	procProcessTrace     = modadvapi32.NewProc("ProcessTrace")
+	procCloseTrace       = modadvapi32.NewProc("CloseTrace")
+	procTdhGetEventInfo  = modtdh.NewProc("TdhGetEventInformation")
+	procTdhGetPropSize   = modtdh.NewProc("TdhGetPropertySize")
+	procTdhGetProperty   = modtdh.NewProc("TdhGetProperty")
+	procTdhEnumProviders = modtdh.NewProc("TdhEnumerateProviders")
+)
+
+// The trace functions return the error code instead of setting the last error
+func errorFromCode(r uintptr) error {
+	if r == 0 {
+		return nil
+	}
+	return syscall.Errno(r)
+}
+
+func startTrace(handle *uint64, name *uint16, properties *sessionProperties) error {
+	r, _, _ := syscall.SyscallN(
+		procStartTraceW.Addr(),
+		uintptr(unsafe.Pointer(handle)),     //nolint:gosec // G103: Valid use of unsafe call to pass handle
+		uintptr(unsafe.Pointer(name)),       //nolint:gosec // G103: Valid use of unsafe call to pass name
+		uintptr(unsafe.Pointer(properties)), //nolint:gosec // G103: Valid use of unsafe call to pass properties
+	)
+	return errorFromCode(r)
+}
+
+func controlTrace(handle uint64, name *uint16, properties *sessionProperties, code uint32) error {
+	r, _, _ := syscall.SyscallN(
+		procControlTraceW.Addr(),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(name)),       //nolint:gosec // G103: Valid use of unsafe call to pass name
+		uintptr(unsafe.Pointer(properties)), //nolint:gosec // G103: Valid use of unsafe call to pass properties
+		uintptr(code),
+	)
+	return errorFromCode(r)
+}
+
+//nolint:revive //argument-limit conditionally more arguments allowed
+func enableTraceEx2(handle uint64, provider *windows.GUID, code uint32, level uint8, matchAny, matchAll uint64) error {
+	r, _, _ := syscall.SyscallN(
+		procEnableTraceEx2.Addr(),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(provider)), //nolint:gosec // G103: Valid use of unsafe call to pass provider
+		uintptr(code),
+		uintptr(level),
+		uintptr(matchAny),
+		uintptr(matchAll),
+		0,
+		0,
+	)
+	return errorFromCode(r)
+}
+
+func openTrace(logfile *eventTraceLogfile) (uint64, error) {
+	r, _, e := syscall.SyscallN(
+		procOpenTraceW.Addr(),
+		uintptr(unsafe.Pointer(logfile)), //nolint:gosec // G103: Valid use of unsafe call to pass logfile
+	)
+	if uint64(r) == invalidProcessTraceHandle {
+		return 0, e
+	}
+	return uint64(r), nil
+}
+
+func processTrace(handle *uint64) error {
+	r, _, _ := syscall.SyscallN(
+		procProcessTrace.Addr(),
+		uintptr(unsafe.Pointer(handle)), //nolint:gosec // G103: Valid use of unsafe call to pass handle
+		1,
+		0,
+		0,
+	)
+	return errorFromCode(r)
+}
+
+func closeTrace(handle uint64) error {
+	r, _, _ := syscall.SyscallN(procCloseTrace.Addr(), uintptr(handle))
+	return errorFromCode(r)
+}
+
+func tdhGetEventInformation(record *eventRecord, buffer *byte, size *uint32) error {
+	r, _, _ := syscall.SyscallN(
+		procTdhGetEventInfo.Addr(),
+		uintptr(unsafe.Pointer(record)), //nolint:gosec // G103: Valid use of unsafe call to pass record
+		0,
+		0,
+		uintptr(unsafe.Pointer(buffer)), //nolint:gosec // G103: Valid use of unsafe call to pass buffer
+		uintptr(unsafe.Pointer(size)),   //nolint:gosec // G103: Valid use of unsafe call to pass size
+	)
+	return errorFromCode(r)
+}
+
+func tdhGetPropertySize(record *eventRecord, descriptor *propertyDataDescriptor, size *uint32) error {
+	r, _, _ := syscall.SyscallN(
+		procTdhGetPropSize.Addr(),
+		uintptr(unsafe.Pointer(record)), //nolint:gosec // G103: Valid use of unsafe call to pass record
+		0,
+		0,
+		1,
+		uintptr(unsafe.Pointer(descriptor)), //nolint:gosec // G103: Valid use of unsafe call to pass descriptor
+		uintptr(unsafe.Pointer(size)),       //nolint:gosec // G103: Valid use of unsafe call to pass size
+	)
+	return errorFromCode(r)
+}
+
+func tdhGetProperty(record *eventRecord, descriptor *propertyDataDescriptor, buffer []byte) error {
+	r, _, _ := syscall.SyscallN(
+		procTdhGetProperty.Addr(),
+		uintptr(unsafe.Pointer(record)), //nolint:gosec // G103: Valid use of unsafe call to pass record
+		0,
+		0,
+		1,
+		uintptr(unsafe.Pointer(descriptor)), //nolint:gosec // G103: Valid use of unsafe call to pass descriptor
+		uintptr(len(buffer)),
+		uintptr(unsafe.Pointer(&buffer[0])), //nolint:gosec // G103: Valid use of unsafe call to pass buffer
+	)
+	return errorFromCode(r)
+}
+
+func tdhEnumerateProviders(buffer *byte, size *uint32) error {
+	r, _, _ := syscall.SyscallN(
+		procTdhEnumProviders.Addr(),
+		uintptr(unsafe.Pointer(buffer)), //nolint:gosec // G103: Valid use of unsafe call to pass buffer
+		uintptr(unsafe.Pointer(size)),   //nolint:gosec // G103: Valid use of unsafe call to pass size
+	)
+	return errorFromCode(r)
+}
@@ -0,0 +1,299 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build windows
+
+package win_etw
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// The callback is shared by all sessions as the number of callbacks created
+// by syscall.NewCallback is limited
+var (
+	eventCallback = sync.OnceValue(func() uintptr { return syscall.NewCallback(handleEventRecord) })
+	sessions      sync.Map
+	sessionID     atomic.Uintptr
+)
+
+type WinETW struct {
+	SessionName string          `toml:"session_name"`
+	BufferSize  uint32          `toml:"buffer_size"`
+	Providers   []*Provider     `toml:"provider"`
+	Log         telegraf.Logger `toml:"-"`
+
+	acc       telegraf.Accumulator
+	id        uintptr
+	name      *uint16
+	session   uint64
+	consumer  uint64
+	providers map[windows.GUID]*Provider
+	wg        sync.WaitGroup
+}
+
+func (*WinETW) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinETW) Init() error {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		return errors.New("only supported on 64-bit platforms")
+	}
+
+	if w.SessionName == "" {
+		w.SessionName = "Telegraf-ETW"
+	}
+	name, err := windows.UTF16PtrFromString(w.SessionName)
+	if err != nil {
+		return fmt.Errorf("invalid session name: %w", err)
+	}
+	w.name = name
+
+	if w.BufferSize == 0 {
+		w.BufferSize = 64
+	}
+
+	if len(w.Providers) == 0 {
+		return errors.New("no provider configured")
+	}
+	w.providers = make(map[windows.GUID]*Provider, len(w.Providers))
+	for _, p := range w.Providers {
+		if err := p.init(lookupProvider); err != nil {
+			return fmt.Errorf("provider %q: %w", p.Name, err)
+		}
+		if _, found := w.providers[p.guid]; found {
+			return fmt.Errorf("duplicate provider %q", p.Name)
+		}
+		w.providers[p.guid] = p
+	}
+
+	return nil
+}
+
+func (w *WinETW) Start(acc telegraf.Accumulator) error {
+	w.acc = acc
+
+	// Sessions are not stopped when Telegraf terminates unexpectedly, so
+	// take over a session left behind by a previous run
+	err := startTrace(&w.session, w.name, newSessionProperties(w.BufferSize))
+	if errors.Is(err, windows.ERROR_ALREADY_EXISTS) {
+		w.Log.Infof("Stopping existing session %q", w.SessionName)
+		if err := controlTrace(0, w.name, newSessionProperties(0), eventTraceControlStop); err != nil {
+			return fmt.Errorf("stopping existing session failed: %w", err)
+		}
+		err = startTrace(&w.session, w.name, newSessionProperties(w.BufferSize))
+	}
+	if err != nil {
+		return fmt.Errorf("starting session failed: %w", err)
+	}
+
+	for _, p := range w.Providers {
+		if err := enableTraceEx2(w.session, &p.guid, eventControlCodeEnableProvider, p.level, p.matchAny, p.matchAll); err != nil {
+			w.stopSession()
+			return fmt.Errorf("enabling provider %q failed: %w", p.Name, err)
+		}
+	}
+
+	w.id = sessionID.Add(1)
+	sessions.Store(w.id, w)
+	logfile := &eventTraceLogfile{
+		LoggerName:          w.name,
+		ProcessTraceMode:    processTraceModeRealTime | processTraceModeEventRecord,
+		EventRecordCallback: eventCallback(),
+		Context:             w.id,
+	}
+	w.consumer, err = openTrace(logfile)
+	if err != nil {
+		sessions.Delete(w.id)
+		w.stopSession()
+		return fmt.Errorf("opening session failed: %w", err)
+	}
+
+	// ProcessTrace blocks until the session is stopped or the trace closed
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := processTrace(&w.consumer); err != nil && !errors.Is(err, windows.ERROR_CANCELLED) {
+			acc.AddError(fmt.Errorf("processing events failed: %w", err))
+		}
+	}()
+
+	return nil
+}
+
+func (*WinETW) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (w *WinETW) Stop() {
+	if err := closeTrace(w.consumer); err != nil && !errors.Is(err, windows.ERROR_CTX_CLOSE_PENDING) {
+		w.Log.Errorf("Closing trace failed: %v", err)
+	}
+	w.stopSession()
+	w.wg.Wait()
+	sessions.Delete(w.id)
+}
+
+func (w *WinETW) stopSession() {
+	for _, p := range w.Providers {
+		//nolint:errcheck // stopping the session disables the providers anyway
+		enableTraceEx2(w.session, &p.guid, eventControlCodeDisableProvider, 0, 0, 0)
+	}
+	if err := controlTrace(w.session, nil, newSessionProperties(0), eventTraceControlStop); err != nil {
+		w.Log.Errorf("Stopping session failed: %v", err)
+	}
+}
+
+func handleEventRecord(record *eventRecord) uintptr {
+	s, found := sessions.Load(record.UserContext)
+	if !found {
+		return 0
+	}
+	w := s.(*WinETW)
+
+	p, found := w.providers[record.EventHeader.ProviderID]
+	if !found || !p.accepts(record.EventHeader.EventDescriptor.ID) {
+		return 0
+	}
+
+	e, err := parseEvent(record)
+	if err != nil {
+		w.Log.Debugf("Parsing event %d of provider %q failed: %v", record.EventHeader.EventDescriptor.ID, p.Name, err)
+		return 0
+	}
+	w.acc.AddMetric(p.metric(e))
+	return 0
+}
+
+// parseEvent decodes the event record using the schema registered by the
+// provider
+func parseEvent(record *eventRecord) (*event, error) {
+	header := &record.EventHeader
+	ft := windows.Filetime{
+		LowDateTime:  uint32(header.TimeStamp),
+		HighDateTime: uint32(header.TimeStamp >> 32),
+	}
+	e := &event{
+		id:        header.EventDescriptor.ID,
+		level:     header.EventDescriptor.Level,
+		processID: header.ProcessID,
+		threadID:  header.ThreadID,
+		timestamp: time.Unix(0, ft.Nanoseconds()),
+	}
+
+	// Events written with EventWriteString contain a single string
+	if header.Flags&eventHeaderFlagStringOnly != 0 {
+		if record.UserDataLength > 0 {
+			data := unsafe.Slice((*byte)(record.UserData), record.UserDataLength) //nolint:gosec // G103: Valid use of unsafe call to access the event data
+			e.properties = append(e.properties, property{name: "message", value: decodeUTF16(data)})
+		}
+		return e, nil
+	}
+
+	size := uint32(4096)
+	buf := make([]byte, size)
+	err := tdhGetEventInformation(record, &buf[0], &size)
+	if errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+		buf = make([]byte, size)
+		err = tdhGetEventInformation(record, &buf[0], &size)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting event information failed: %w", err)
+	}
+	info := (*traceEventInfo)(unsafe.Pointer(&buf[0])) //nolint:gosec // G103: Valid use of unsafe call to interpret the buffer
+
+	e.name = strings.TrimSpace(utf16At(buf, info.EventNameOffset))
+	e.task = strings.TrimSpace(utf16At(buf, info.TaskNameOffset))
+	e.opcode = strings.TrimSpace(utf16At(buf, info.OpcodeNameOffset))
+
+	offset := unsafe.Sizeof(*info)
+	for i := range uintptr(info.TopLevelPropertyCount) {
+		pinfo := (*eventPropertyInfo)(unsafe.Pointer(&buf[offset+i*unsafe.Sizeof(eventPropertyInfo{})])) //nolint:gosec // G103: Valid use of unsafe call to interpret the buffer
+
+		// Only scalar properties are supported
+		if pinfo.Flags&(propertyStruct|propertyParamCount) != 0 || pinfo.Count > 1 {
+			continue
+		}
+
+		descriptor := &propertyDataDescriptor{
+			PropertyName: uint64(uintptr(unsafe.Pointer(&buf[pinfo.NameOffset]))), //nolint:gosec // G103: Valid use of unsafe call to pass the name
+			ArrayIndex:   ^uint32(0),
+		}
+		var propSize uint32
+		if err := tdhGetPropertySize(record, descriptor, &propSize); err != nil {
+			return nil, fmt.Errorf("getting size of property %q failed: %w", utf16At(buf, pinfo.NameOffset), err)
+		}
+		if propSize == 0 {
+			continue
+		}
+		data := make([]byte, propSize)
+		if err := tdhGetProperty(record, descriptor, data); err != nil {
+			return nil, fmt.Errorf("getting property %q failed: %w", utf16At(buf, pinfo.NameOffset), err)
+		}
+
+		name := utf16At(buf, pinfo.NameOffset)
+		value, err := decodeProperty(pinfo.InType, pinfo.OutType, data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding property %q failed: %w", name, err)
+		}
+		e.properties = append(e.properties, property{name: name, value: value})
+	}
+
+	return e, nil
+}
+
+// utf16At returns the null-terminated string at the given offset of the
+// buffer
+func utf16At(buf []byte, offset uint32) string {
+	if offset == 0 || int(offset) >= len(buf) {
+		return ""
+	}
+	return decodeUTF16(buf[offset:])
+}
+
+// lookupProvider returns the GUID of the provider with the given name
+// registered on the system
+func lookupProvider(name string) (windows.GUID, error) {
+	// The list of providers might grow between the calls
+	var buf []byte
+	size := uint32(64 * 1024)
+	var err error = windows.ERROR_INSUFFICIENT_BUFFER
+	for errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+		buf = make([]byte, size)
+		err = tdhEnumerateProviders(&buf[0], &size)
+	}
+	if err != nil {
+		return windows.GUID{}, fmt.Errorf("enumerating providers failed: %w", err)
+	}
+
+	count := *(*uint32)(unsafe.Pointer(&buf[0])) //nolint:gosec // G103: Valid use of unsafe call to interpret the buffer
+	offset := uintptr(8)
+	for i := range uintptr(count) {
+		info := (*traceProviderInfo)(unsafe.Pointer(&buf[offset+i*unsafe.Sizeof(traceProviderInfo{})])) //nolint:gosec // G103: Valid use of unsafe call to interpret the buffer
+		if strings.EqualFold(utf16At(buf, info.ProviderNameOffset), name) {
+			return info.ProviderGUID, nil
+		}
+	}
+	return windows.GUID{}, fmt.Errorf("provider %q not found", name)
+}
+
+func init() {
+	inputs.Add("win_etw", func() telegraf.Input {
+		return &WinETW{}
+	})
+}
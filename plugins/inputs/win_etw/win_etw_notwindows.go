@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !windows
+
+package win_etw
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WinETW struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (w *WinETW) Init() error {
+	w.Log.Warn("current platform is not supported")
+	return nil
+}
+func (*WinETW) SampleConfig() string                { return sampleConfig }
+func (*WinETW) Gather(_ telegraf.Accumulator) error { return nil }
+func (*WinETW) Start(_ telegraf.Accumulator) error  { return nil }
+func (*WinETW) Stop()                               {}
+
+func init() {
+	inputs.Add("win_etw", func() telegraf.Input {
+		return &WinETW{}
+	})
+}
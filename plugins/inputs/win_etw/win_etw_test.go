@@ -0,0 +1,254 @@
+//go:build windows
+
+package win_etw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *Provider
+		expected string
+	}{
+		{
+			name:     "missing name",
+			provider: &Provider{},
+			expected: "provider name required",
+		},
+		{
+			name:     "invalid GUID",
+			provider: &Provider{Name: "{2F07E2EE-15DB}"},
+			expected: "parsing GUID failed",
+		},
+		{
+			name:     "invalid level",
+			provider: &Provider{Name: "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}", Level: "debug"},
+			expected: `invalid level "debug"`,
+		},
+		{
+			name:     "invalid keyword",
+			provider: &Provider{Name: "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}", MatchAnyKeyword: "0xZZ"},
+			expected: "invalid match_any_keyword",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &WinETW{
+				Providers: []*Provider{tt.provider},
+				Log:       testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDuplicateProvider(t *testing.T) {
+	plugin := &WinETW{
+		Providers: []*Provider{
+			{Name: "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}"},
+			{Name: "{2f07e2ee-15db-40f1-90ef-9d7ba282188a}"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "duplicate provider")
+}
+
+func TestProviderMetric(t *testing.T) {
+	p := &Provider{
+		Name:          "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}",
+		Level:         "verbose",
+		EventIDs:      []uint16{1033},
+		TagProperties: []string{"LocalAddress", "RemoteAddress"},
+		PropertyNames: map[string]string{
+			"LocalAddress": "local_address",
+			"NewState":     "state",
+		},
+		FieldProperties: []string{"NewState", "*Time"},
+	}
+	require.NoError(t, p.init(nil))
+	require.Equal(t, uint8(5), p.level)
+	require.True(t, p.accepts(1033))
+	require.False(t, p.accepts(1034))
+
+	e := &event{
+		id:        1033,
+		level:     4,
+		name:      "TcpConnectionStateChange",
+		task:      "TcpipConnection",
+		processID: 1234,
+		threadID:  42,
+		timestamp: time.Unix(1700000000, 0),
+		properties: []property{
+			{name: "LocalAddress", value: "10.0.0.1:49152"},
+			{name: "RemoteAddress", value: "10.0.0.2:443"},
+			{name: "NewState", value: uint64(5)},
+			{name: "RoundTripTime", value: uint64(12)},
+			{name: "Tcb", value: uint64(0xffffa20c5d3b8010)},
+		},
+	}
+
+	expected := metric.New(
+		"win_etw",
+		map[string]string{
+			"provider":      "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}",
+			"event_id":      "1033",
+			"level":         "information",
+			"event":         "TcpConnectionStateChange",
+			"task":          "TcpipConnection",
+			"local_address": "10.0.0.1:49152",
+			"RemoteAddress": "10.0.0.2:443",
+		},
+		map[string]interface{}{
+			"process_id":    uint64(1234),
+			"thread_id":     uint64(42),
+			"state":         uint64(5),
+			"RoundTripTime": uint64(12),
+		},
+		time.Unix(1700000000, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{p.metric(e)})
+}
+
+func TestDecodeProperty(t *testing.T) {
+	tests := []struct {
+		name     string
+		inType   uint16
+		outType  uint16
+		data     []byte
+		expected interface{}
+	}{
+		{
+			name:     "unicode string",
+			inType:   inTypeUnicodeString,
+			data:     []byte{'h', 0, 'o', 0, 's', 0, 't', 0, 0, 0},
+			expected: "host",
+		},
+		{
+			name:     "ansi string",
+			inType:   inTypeAnsiString,
+			data:     []byte{'d', 'n', 's', 0},
+			expected: "dns",
+		},
+		{
+			name:     "counted string",
+			inType:   inTypeCountedString,
+			data:     []byte{4, 0, 'o', 0, 'k', 0},
+			expected: "ok",
+		},
+		{
+			name:     "int16",
+			inType:   inTypeInt16,
+			data:     []byte{0xfe, 0xff},
+			expected: int64(-2),
+		},
+		{
+			name:     "uint32",
+			inType:   inTypeUint32,
+			data:     []byte{0x01, 0x02, 0x00, 0x00},
+			expected: uint64(513),
+		},
+		{
+			name:     "port",
+			inType:   inTypeUint16,
+			outType:  outTypePort,
+			data:     []byte{0x01, 0xbb},
+			expected: uint64(443),
+		},
+		{
+			name:     "ipv4",
+			inType:   inTypeUint32,
+			outType:  outTypeIPv4,
+			data:     []byte{192, 168, 1, 10},
+			expected: "192.168.1.10",
+		},
+		{
+			name:     "ipv6",
+			inType:   inTypeBinary,
+			outType:  outTypeIPv6,
+			data:     []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			expected: "2001:db8::1",
+		},
+		{
+			name:     "socket address v4",
+			inType:   inTypeBinary,
+			outType:  outTypeSocketAddress,
+			data:     []byte{2, 0, 0x00, 0x35, 10, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+			expected: "10.0.0.1:53",
+		},
+		{
+			name:     "socket address v6",
+			inType:   inTypeBinary,
+			outType:  outTypeSocketAddress,
+			data:     []byte{23, 0, 0x01, 0xbb, 0, 0, 0, 0, 0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0},
+			expected: "[fe80::1]:443",
+		},
+		{
+			name:     "binary",
+			inType:   inTypeBinary,
+			data:     []byte{0xde, 0xad},
+			expected: "dead",
+		},
+		{
+			name:     "boolean",
+			inType:   inTypeBoolean,
+			data:     []byte{1, 0, 0, 0},
+			expected: true,
+		},
+		{
+			name:     "double",
+			inType:   inTypeDouble,
+			data:     []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f},
+			expected: 1.5,
+		},
+		{
+			name:     "guid",
+			inType:   inTypeGUID,
+			data:     []byte{0xee, 0xe2, 0x07, 0x2f, 0xdb, 0x15, 0xf1, 0x40, 0x90, 0xef, 0x9d, 0x7b, 0xa2, 0x82, 0x18, 0x8a},
+			expected: "{2F07E2EE-15DB-40F1-90EF-9D7BA282188A}",
+		},
+		{
+			name:     "filetime",
+			inType:   inTypeFiletime,
+			data:     []byte{0x00, 0x00, 0x05, 0x69, 0x36, 0xc0, 0xd5, 0x01},
+			expected: "2020-01-01T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := decodeProperty(tt.inType, tt.outType, tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDecodePropertyInvalid(t *testing.T) {
+	_, err := decodeProperty(inTypeUint64, 0, []byte{1, 2, 3})
+	require.ErrorIs(t, err, errShortData)
+
+	_, err = decodeProperty(1000, 0, []byte{1})
+	require.ErrorContains(t, err, "unsupported type 1000")
+}
+
+func TestLookupProvider(t *testing.T) {
+	guid, err := lookupProvider("Microsoft-Windows-Kernel-Process")
+	require.NoError(t, err)
+	expected, err := windows.GUIDFromString("{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}")
+	require.NoError(t, err)
+	require.Equal(t, expected, guid)
+
+	_, err = lookupProvider("Telegraf-Non-Existing-Provider")
+	require.ErrorContains(t, err, "not found")
+}
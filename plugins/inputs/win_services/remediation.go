@@ -0,0 +1,123 @@
+//go:build windows
+
+package win_services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+
+	"github.com/influxdata/telegraf"
+)
+
+// stateTracker keeps the last known state of the services and the times the
+// services stopped for detecting flapping services.
+type stateTracker struct {
+	sync.Mutex
+	states      map[string]int
+	stops       map[string][]time.Time
+	remediating map[string]bool
+}
+
+func newStateTracker() *stateTracker {
+	return &stateTracker{
+		states:      make(map[string]int),
+		stops:       make(map[string][]time.Time),
+		remediating: make(map[string]bool),
+	}
+}
+
+// observe records the state of the service, emits a state-change event if
+// requested and triggers the remediation of flapping services.
+func (m *WinServices) observe(acc telegraf.Accumulator, name string, state int, ts time.Time) {
+	if m.tracker == nil {
+		return
+	}
+
+	m.tracker.Lock()
+	defer m.tracker.Unlock()
+
+	previous, known := m.tracker.states[name]
+	m.tracker.states[name] = state
+	if !known || previous == state {
+		return
+	}
+
+	if m.WatchStateChanges {
+		tags := map[string]string{"service_name": name}
+		fields := map[string]interface{}{
+			"state":          state,
+			"previous_state": previous,
+		}
+		acc.AddFields("win_services_state_change", fields, tags, ts)
+	}
+
+	// Ignore the transitions caused by our own restart
+	if m.remediationFilter == nil || m.tracker.remediating[name] || state != int(svc.Stopped) {
+		return
+	}
+	if !m.remediationFilter.Match(strings.ToLower(name)) {
+		return
+	}
+
+	// Only keep the stops within the flapping window
+	cutoff := ts.Add(-time.Duration(m.RemediationFlapWindow))
+	stops := append(m.tracker.stops[name], ts)
+	for len(stops) > 0 && stops[0].Before(cutoff) {
+		stops = stops[1:]
+	}
+	m.tracker.stops[name] = stops
+	if len(stops) < m.RemediationFlapThreshold {
+		return
+	}
+
+	m.tracker.remediating[name] = true
+	delete(m.tracker.stops, name)
+
+	m.wg.Add(1)
+	go func(flaps int) {
+		defer m.wg.Done()
+		m.remediate(acc, name, flaps)
+	}(len(stops))
+}
+
+// remediate restarts the given service and reports the outcome as audit
+// metric.
+func (m *WinServices) remediate(acc telegraf.Accumulator, name string, flaps int) {
+	defer func() {
+		m.tracker.Lock()
+		delete(m.tracker.remediating, name)
+		m.tracker.Unlock()
+	}()
+
+	m.Log.Infof("Restarting service %q after %d stops within %s", name, flaps, time.Duration(m.RemediationFlapWindow))
+
+	tags := map[string]string{
+		"service_name": name,
+		"action":       "restart",
+	}
+	fields := map[string]interface{}{
+		"flaps":   flaps,
+		"success": true,
+	}
+
+	err := m.restart(name)
+	if err != nil {
+		m.Log.Errorf("Restarting service %q failed: %v", name, err)
+		fields["success"] = false
+		fields["error"] = err.Error()
+	}
+	acc.AddFields("win_services_remediation", fields, tags)
+}
+
+func (m *WinServices) restart(name string) error {
+	scmgr, err := m.mgrProvider.Connect()
+	if err != nil {
+		return err
+	}
+	defer scmgr.Disconnect()
+
+	return scmgr.RestartService(name, time.Duration(m.RemediationTimeout))
+}
@@ -0,0 +1,83 @@
+//go:build windows
+
+package win_services
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+)
+
+// stateSubscriber allows to get notified on state changes of a service. The
+// returned function cancels the subscription.
+type stateSubscriber interface {
+	subscribe(name string, notify func(svc.State)) (func(), error)
+}
+
+// The number of callbacks is limited in Windows so we use a single callback
+// dispatching the notifications to the subscriptions by their context.
+var (
+	callbackOnce   sync.Once
+	callback       uintptr
+	subscriptions  sync.Map
+	subscriptionID atomic.Uintptr
+)
+
+func statusChangeCallback(_ uint32, context uintptr) uintptr {
+	if f, found := subscriptions.Load(context); found {
+		f.(func())()
+	}
+	return 0
+}
+
+// scmSubscriber subscribes to the status-change events of the service
+// control manager
+type scmSubscriber struct{}
+
+func (*scmSubscriber) subscribe(name string, notify func(svc.State)) (func(), error) {
+	callbackOnce.Do(func() {
+		callback = windows.NewCallback(statusChangeCallback)
+	})
+
+	serviceName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert service name %q: %w", name, err)
+	}
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.OpenService(scm, serviceName, windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		windows.CloseServiceHandle(scm)
+		return nil, err
+	}
+
+	id := subscriptionID.Add(1)
+	subscriptions.Store(id, func() {
+		var status windows.SERVICE_STATUS
+		if err := windows.QueryServiceStatus(h, &status); err == nil {
+			notify(svc.State(status.CurrentState))
+		}
+	})
+
+	var subscription uintptr
+	if err := windows.SubscribeServiceChangeNotifications(h, windows.SC_EVENT_STATUS_CHANGE, callback, id, &subscription); err != nil {
+		subscriptions.Delete(id)
+		windows.CloseServiceHandle(h)
+		windows.CloseServiceHandle(scm)
+		return nil, err
+	}
+
+	unsubscribe := func() {
+		windows.UnsubscribeServiceChangeNotifications(subscription)
+		subscriptions.Delete(id)
+		windows.CloseServiceHandle(h)
+		windows.CloseServiceHandle(scm)
+	}
+	return unsubscribe, nil
+}
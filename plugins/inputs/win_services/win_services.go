@@ -9,13 +9,16 @@ import (
 	"fmt"
 	"io/fs"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -46,6 +49,9 @@ type WinService interface {
 	Close() error
 	Config() (mgr.Config, error)
 	Query() (svc.Status, error)
+	RecoveryActions() ([]mgr.RecoveryAction, error)
+	ResetPeriod() (uint32, error)
+	RecoveryActionsOnNonCrashFailures() (bool, error)
 }
 
 // ManagerProvider sets interface for acquiring manager instance, like mgr.Mgr
@@ -58,6 +64,7 @@ type WinServiceManager interface {
 	Disconnect() error
 	OpenService(name string) (WinService, error)
 	ListServices() ([]string, error)
+	RestartService(name string, timeout time.Duration) error
 }
 
 // winSvcMgr is wrapper for mgr.Mgr implementing WinServiceManager interface
@@ -85,6 +92,49 @@ func (m *winSvcMgr) ListServices() ([]string, error) {
 	return m.realMgr.ListServices()
 }
 
+// RestartService stops the service if it is not stopped already, waits for
+// the service to stop and starts it again.
+func (m *winSvcMgr) RestartService(name string, timeout time.Duration) error {
+	serviceName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return fmt.Errorf("cannot convert service name %q: %w", name, err)
+	}
+	access := uint32(windows.SERVICE_QUERY_STATUS | windows.SERVICE_START | windows.SERVICE_STOP)
+	h, err := windows.OpenService(m.realMgr.Handle, serviceName, access)
+	if err != nil {
+		return err
+	}
+	srv := &mgr.Service{Name: name, Handle: h}
+	defer srv.Close()
+
+	status, err := srv.Query()
+	if err != nil {
+		return fmt.Errorf("querying status failed: %w", err)
+	}
+	if status.State != svc.Stopped {
+		if status.State != svc.StopPending {
+			if _, err := srv.Control(svc.Stop); err != nil {
+				return fmt.Errorf("stopping failed: %w", err)
+			}
+		}
+		deadline := time.Now().Add(timeout)
+		for status.State != svc.Stopped {
+			if time.Now().After(deadline) {
+				return errors.New("timeout waiting for service to stop")
+			}
+			time.Sleep(250 * time.Millisecond)
+			if status, err = srv.Query(); err != nil {
+				return fmt.Errorf("querying status failed: %w", err)
+			}
+		}
+	}
+
+	if err := srv.Start(); err != nil {
+		return fmt.Errorf("starting failed: %w", err)
+	}
+	return nil
+}
+
 // mgProvider is an implementation of WinServiceManagerProvider interface returning winSvcMgr
 type mgProvider struct {
 }
@@ -102,11 +152,24 @@ func (rmr *mgProvider) Connect() (WinServiceManager, error) {
 type WinServices struct {
 	Log telegraf.Logger
 
-	ServiceNames         []string `toml:"service_names"`
-	ServiceNamesExcluded []string `toml:"excluded_service_names"`
-	mgrProvider          ManagerProvider
-
-	servicesFilter filter.Filter
+	ServiceNames             []string        `toml:"service_names"`
+	ServiceNamesExcluded     []string        `toml:"excluded_service_names"`
+	IncludeDetails           bool            `toml:"include_details"`
+	WatchStateChanges        bool            `toml:"watch_state_changes"`
+	RemediationServices      []string        `toml:"remediation_services"`
+	RemediationFlapThreshold int             `toml:"remediation_flap_threshold"`
+	RemediationFlapWindow    config.Duration `toml:"remediation_flap_window"`
+	RemediationTimeout       config.Duration `toml:"remediation_timeout"`
+	mgrProvider              ManagerProvider
+	subscriber               stateSubscriber
+
+	servicesFilter    filter.Filter
+	remediationFilter filter.Filter
+
+	acc           telegraf.Accumulator
+	unsubscribers []func()
+	tracker       *stateTracker
+	wg            sync.WaitGroup
 }
 
 type serviceInfo struct {
@@ -114,6 +177,13 @@ type serviceInfo struct {
 	DisplayName string
 	State       int
 	StartUpMode int
+
+	// Details only collected if requested
+	StartupType          string
+	Dependencies         []string
+	RecoveryActions      []mgr.RecoveryAction
+	RecoveryResetPeriod  uint32
+	RecoveryOnNonCrashes bool
 }
 
 func (*WinServices) SampleConfig() string {
@@ -138,6 +208,57 @@ func (m *WinServices) Init() error {
 	}
 	m.servicesFilter = f
 
+	if len(m.RemediationServices) > 0 {
+		remediate := make([]string, 0, len(m.RemediationServices))
+		for _, s := range m.RemediationServices {
+			remediate = append(remediate, strings.ToLower(s))
+		}
+		f, err := filter.Compile(remediate)
+		if err != nil {
+			return fmt.Errorf("compiling 'remediation_services' failed: %w", err)
+		}
+		m.remediationFilter = f
+
+		if m.RemediationFlapThreshold < 1 {
+			return errors.New("'remediation_flap_threshold' must be positive")
+		}
+		if m.RemediationFlapWindow <= 0 {
+			return errors.New("'remediation_flap_window' must be positive")
+		}
+	}
+	m.tracker = newStateTracker()
+
+	return nil
+}
+
+func (m *WinServices) Start(acc telegraf.Accumulator) error {
+	m.acc = acc
+	if !m.WatchStateChanges {
+		return nil
+	}
+
+	scmgr, err := m.mgrProvider.Connect()
+	if err != nil {
+		return fmt.Errorf("could not open service manager: %w", err)
+	}
+	defer scmgr.Disconnect()
+
+	serviceNames, err := m.listServices(scmgr)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range serviceNames {
+		unsubscribe, err := m.subscriber.subscribe(name, func(state svc.State) {
+			m.observe(m.acc, name, int(state), time.Now())
+		})
+		if err != nil {
+			m.Log.Errorf("Subscribing to state changes of %q failed: %v", name, err)
+			continue
+		}
+		m.unsubscribers = append(m.unsubscribers, unsubscribe)
+	}
+
 	return nil
 }
 
@@ -154,7 +275,7 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 	}
 
 	for _, srvName := range serviceNames {
-		service, err := collectServiceInfo(scmgr, srvName)
+		service, err := collectServiceInfo(scmgr, srvName, m.IncludeDetails)
 		if err != nil {
 			if IsPermission(err) {
 				m.Log.Debug(err.Error())
@@ -176,12 +297,31 @@ func (m *WinServices) Gather(acc telegraf.Accumulator) error {
 			"state":        service.State,
 			"startup_mode": service.StartUpMode,
 		}
+		if m.IncludeDetails {
+			fields["startup_type"] = service.StartupType
+			fields["dependencies"] = strings.Join(service.Dependencies, ",")
+			fields["recovery_actions"] = formatRecoveryActions(service.RecoveryActions)
+			fields["recovery_reset_period"] = service.RecoveryResetPeriod
+			fields["recovery_on_non_crash_failures"] = service.RecoveryOnNonCrashes
+		}
 		acc.AddFields("win_services", fields, tags)
+
+		m.observe(acc, service.ServiceName, service.State, time.Now())
 	}
 
 	return nil
 }
 
+func (m *WinServices) Stop() {
+	for _, unsubscribe := range m.unsubscribers {
+		unsubscribe()
+	}
+	m.unsubscribers = nil
+
+	// Wait for running remediations to finish
+	m.wg.Wait()
+}
+
 // listServices returns a list of services to gather.
 func (m *WinServices) listServices(scmgr WinServiceManager) ([]string, error) {
 	names, err := scmgr.ListServices()
@@ -202,7 +342,7 @@ func (m *WinServices) listServices(scmgr WinServiceManager) ([]string, error) {
 }
 
 // collectServiceInfo gathers info about a service.
-func collectServiceInfo(scmgr WinServiceManager, serviceName string) (*serviceInfo, error) {
+func collectServiceInfo(scmgr WinServiceManager, serviceName string, details bool) (*serviceInfo, error) {
 	srv, err := scmgr.OpenService(serviceName)
 	if err != nil {
 		return nil, &serviceError{
@@ -237,13 +377,92 @@ func collectServiceInfo(scmgr WinServiceManager, serviceName string) (*serviceIn
 		StartUpMode: int(srvCfg.StartType),
 		State:       int(srvStatus.State),
 	}
+	if !details {
+		return serviceInfo, nil
+	}
+
+	serviceInfo.StartupType = startupType(srvCfg)
+	serviceInfo.Dependencies = srvCfg.Dependencies
+
+	serviceInfo.RecoveryActions, err = srv.RecoveryActions()
+	if err != nil {
+		return nil, &serviceError{
+			Message: "could not get recovery actions of service",
+			Service: serviceName,
+			Err:     err,
+		}
+	}
+	serviceInfo.RecoveryResetPeriod, err = srv.ResetPeriod()
+	if err != nil {
+		return nil, &serviceError{
+			Message: "could not get recovery reset period of service",
+			Service: serviceName,
+			Err:     err,
+		}
+	}
+	serviceInfo.RecoveryOnNonCrashes, err = srv.RecoveryActionsOnNonCrashFailures()
+	if err != nil {
+		return nil, &serviceError{
+			Message: "could not get recovery flags of service",
+			Service: serviceName,
+			Err:     err,
+		}
+	}
+
 	return serviceInfo, nil
 }
 
+// startupType returns a textual representation of the service's start type
+func startupType(cfg mgr.Config) string {
+	switch cfg.StartType {
+	case windows.SERVICE_BOOT_START:
+		return "boot"
+	case windows.SERVICE_SYSTEM_START:
+		return "system"
+	case windows.SERVICE_AUTO_START:
+		if cfg.DelayedAutoStart {
+			return "auto_delayed"
+		}
+		return "auto"
+	case windows.SERVICE_DEMAND_START:
+		return "manual"
+	case windows.SERVICE_DISABLED:
+		return "disabled"
+	}
+	return "unknown"
+}
+
+// formatRecoveryActions returns the recovery actions as comma-separated list
+// of "<action>/<delay>" entries
+func formatRecoveryActions(actions []mgr.RecoveryAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		var name string
+		switch a.Type {
+		case mgr.NoAction:
+			name = "none"
+		case mgr.ComputerReboot:
+			name = "reboot"
+		case mgr.ServiceRestart:
+			name = "restart"
+		case mgr.RunCommand:
+			name = "run_command"
+		default:
+			name = "unknown"
+		}
+		parts = append(parts, name+"/"+a.Delay.String())
+	}
+	return strings.Join(parts, ",")
+}
+
 func init() {
 	inputs.Add("win_services", func() telegraf.Input {
 		return &WinServices{
-			mgrProvider: &mgProvider{},
+			RemediationFlapThreshold: 3,
+			RemediationFlapWindow:    config.Duration(10 * time.Minute),
+			RemediationTimeout:       config.Duration(30 * time.Second),
+			mgrProvider:              &mgProvider{},
+			subscriber:               &scmSubscriber{},
 		}
 	})
 }
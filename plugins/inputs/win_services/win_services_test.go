@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -62,6 +65,10 @@ func (m *FakeSvcMgr) ListServices() ([]string, error) {
 	return m.testData.queryServiceList, nil
 }
 
+func (m *FakeSvcMgr) RestartService(string, time.Duration) error {
+	return nil
+}
+
 type FakeMgProvider struct {
 	testData testData
 }
@@ -91,13 +98,26 @@ func (m *FakeWinSvc) Config() (mgr.Config, error) {
 		BinaryPathName:   "",
 		LoadOrderGroup:   "",
 		TagId:            0,
-		Dependencies:     nil,
+		Dependencies:     []string{"RpcSs", "Tcpip"},
 		ServiceStartName: m.testData.serviceName,
 		DisplayName:      m.testData.displayName,
 		Password:         "",
 		Description:      "",
+		DelayedAutoStart: true,
 	}, nil
 }
+func (m *FakeWinSvc) RecoveryActions() ([]mgr.RecoveryAction, error) {
+	return []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+		{Type: mgr.NoAction},
+	}, nil
+}
+func (m *FakeWinSvc) ResetPeriod() (uint32, error) {
+	return 86400, nil
+}
+func (m *FakeWinSvc) RecoveryActionsOnNonCrashFailures() (bool, error) {
+	return true, nil
+}
 func (m *FakeWinSvc) Query() (svc.Status, error) {
 	if m.testData.serviceQueryError != nil {
 		return svc.Status{}, m.testData.serviceQueryError
@@ -236,3 +256,143 @@ func TestExcludingNamesTag(t *testing.T) {
 		acc1.AssertDoesNotContainsTaggedFields(t, "win_services", fields, tags)
 	}
 }
+
+func TestGatherDetails(t *testing.T) {
+	winServices := &WinServices{
+		Log:            testutil.Logger{},
+		ServiceNames:   []string{"Service 1"},
+		IncludeDetails: true,
+		mgrProvider:    &FakeMgProvider{testSimpleData[0]},
+	}
+	require.NoError(t, winServices.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, winServices.Gather(&acc))
+
+	tags := map[string]string{
+		"service_name": "Service 1",
+		"display_name": "Fake service 1",
+	}
+	fields := map[string]interface{}{
+		"state":                          1,
+		"startup_mode":                   2,
+		"startup_type":                   "auto_delayed",
+		"dependencies":                   "RpcSs,Tcpip",
+		"recovery_actions":               "restart/1m0s,none/0s",
+		"recovery_reset_period":          uint32(86400),
+		"recovery_on_non_crash_failures": true,
+	}
+	acc.AssertContainsTaggedFields(t, "win_services", fields, tags)
+}
+
+type fakeSubscriber struct {
+	notify map[string]func(svc.State)
+}
+
+func (s *fakeSubscriber) subscribe(name string, notify func(svc.State)) (func(), error) {
+	s.notify[name] = notify
+	return func() { delete(s.notify, name) }, nil
+}
+
+type fakeRestartMgr struct {
+	FakeSvcMgr
+	restarted chan string
+}
+
+func (m *fakeRestartMgr) RestartService(name string, _ time.Duration) error {
+	m.restarted <- name
+	return nil
+}
+
+type fakeRestartProvider struct {
+	testData  testData
+	restarted chan string
+}
+
+func (m *fakeRestartProvider) Connect() (WinServiceManager, error) {
+	return &fakeRestartMgr{FakeSvcMgr{m.testData}, m.restarted}, nil
+}
+
+func TestStateChangeEvents(t *testing.T) {
+	subscriber := &fakeSubscriber{notify: make(map[string]func(svc.State))}
+	winServices := &WinServices{
+		Log:               testutil.Logger{},
+		ServiceNames:      []string{"Service*"},
+		WatchStateChanges: true,
+		mgrProvider:       &FakeMgProvider{testSimpleData[0]},
+		subscriber:        subscriber,
+	}
+	require.NoError(t, winServices.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, winServices.Start(&acc))
+	require.Len(t, subscriber.notify, 2)
+
+	// Initial states are not reported as change
+	require.NoError(t, winServices.Gather(&acc))
+	subscriber.notify["Service 1"](svc.Running)
+	subscriber.notify["Service 1"](svc.Running)
+	subscriber.notify["Service 2"](svc.Stopped)
+
+	winServices.Stop()
+	require.Empty(t, subscriber.notify)
+
+	changes := acc.GetTelegrafMetrics()[2:]
+	require.Len(t, changes, 1)
+	require.Equal(t, "win_services_state_change", changes[0].Name())
+	require.Equal(t, map[string]string{"service_name": "Service 1"}, changes[0].Tags())
+	require.Equal(t, map[string]interface{}{
+		"state":          int64(svc.Running),
+		"previous_state": int64(svc.Stopped),
+	}, changes[0].Fields())
+}
+
+func TestRemediation(t *testing.T) {
+	subscriber := &fakeSubscriber{notify: make(map[string]func(svc.State))}
+	provider := &fakeRestartProvider{testData: testSimpleData[0], restarted: make(chan string, 10)}
+	winServices := &WinServices{
+		Log:                      testutil.Logger{},
+		ServiceNames:             []string{"Service*"},
+		WatchStateChanges:        true,
+		RemediationServices:      []string{"service 1"},
+		RemediationFlapThreshold: 2,
+		RemediationFlapWindow:    config.Duration(time.Minute),
+		mgrProvider:              provider,
+		subscriber:               subscriber,
+	}
+	require.NoError(t, winServices.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, winServices.Start(&acc))
+	require.NoError(t, winServices.Gather(&acc))
+
+	// Flap both services but only restart the allow-listed one
+	for _, name := range []string{"Service 1", "Service 2"} {
+		subscriber.notify[name](svc.Running)
+		subscriber.notify[name](svc.Stopped)
+		subscriber.notify[name](svc.Running)
+		subscriber.notify[name](svc.Stopped)
+	}
+	winServices.Stop()
+
+	require.Len(t, provider.restarted, 1)
+	require.Equal(t, "Service 1", <-provider.restarted)
+
+	var audit []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "win_services_remediation" {
+			audit = append(audit, m)
+		}
+	}
+	require.Len(t, audit, 1)
+	require.Equal(t, map[string]string{"service_name": "Service 1", "action": "restart"}, audit[0].Tags())
+	require.Equal(t, map[string]interface{}{"flaps": int64(2), "success": true}, audit[0].Fields())
+}
+
+func TestRemediationInvalid(t *testing.T) {
+	winServices := &WinServices{
+		RemediationServices: []string{"Service 1"},
+		mgrProvider:         &FakeMgProvider{},
+	}
+	require.ErrorContains(t, winServices.Init(), "'remediation_flap_threshold' must be positive")
+}
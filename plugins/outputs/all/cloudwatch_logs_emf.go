@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.cloudwatch_logs_emf
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/cloudwatch_logs_emf" // register plugin
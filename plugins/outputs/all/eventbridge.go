@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.eventbridge
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/eventbridge" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.s3
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/s3" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.sqs
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/sqs" // register plugin
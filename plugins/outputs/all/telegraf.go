@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.telegraf
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/telegraf" // register plugin
@@ -74,6 +74,7 @@ type CloudWatchLogs struct {
 	Log telegraf.Logger `toml:"-"`
 
 	common_aws.CredentialConfig
+	common_aws.ClientConfig
 }
 
 const (
@@ -144,6 +145,12 @@ func (c *CloudWatchLogs) Connect() error {
 	var logGroupsOutput = &cloudwatchlogs.DescribeLogGroupsOutput{NextToken: &dummyToken}
 	var err error
 
+	httpClient, err := c.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	c.CredentialConfig.HTTPClient = httpClient
+
 	awsCreds, awsErr := c.CredentialConfig.Credentials()
 	if awsErr != nil {
 		return awsErr
@@ -154,6 +161,9 @@ func (c *CloudWatchLogs) Connect() error {
 		return err
 	}
 	cfg.Credentials = awsCreds.Credentials
+	if httpClient != nil {
+		cfg.HTTPClient = httpClient
+	}
 
 	if c.CredentialConfig.EndpointURL != "" && c.CredentialConfig.Region != "" {
 		c.svc = cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
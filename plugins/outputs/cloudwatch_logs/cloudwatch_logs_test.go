@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -104,8 +105,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "",
 				LogStream:    "tag:source",
@@ -122,8 +123,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "",
@@ -140,8 +141,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "tag:source",
@@ -158,8 +159,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "tag:source",
@@ -177,8 +178,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "tag:source",
@@ -196,8 +197,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "tag:source",
@@ -213,8 +214,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:    "eu-central-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 				LogGroup:     "TestLogGroup",
 				LogStream:    "tag:source",
@@ -230,8 +231,8 @@ func TestInit(t *testing.T) {
 			plugin: &CloudWatchLogs{
 				CredentialConfig: aws.CredentialConfig{
 					Region:      "eu-central-1",
-					AccessKey:   "dummy",
-					SecretKey:   "dummy",
+					AccessKey:   config.NewSecret([]byte("dummy")),
+					SecretKey:   config.NewSecret([]byte("dummy")),
 					EndpointURL: "https://test.com",
 				},
 				LogGroup:     "TestLogGroup",
@@ -279,8 +280,8 @@ func TestConnect(t *testing.T) {
 	plugin := &CloudWatchLogs{
 		CredentialConfig: aws.CredentialConfig{
 			Region:      "eu-central-1",
-			AccessKey:   "dummy",
-			SecretKey:   "dummy",
+			AccessKey:   config.NewSecret([]byte("dummy")),
+			SecretKey:   config.NewSecret([]byte("dummy")),
 			EndpointURL: ts.URL,
 		},
 		LogGroup:     "TestLogGroup",
@@ -319,8 +320,8 @@ func TestWrite(t *testing.T) {
 	plugin := &CloudWatchLogs{
 		CredentialConfig: aws.CredentialConfig{
 			Region:      "eu-central-1",
-			AccessKey:   "dummy",
-			SecretKey:   "dummy",
+			AccessKey:   config.NewSecret([]byte("dummy")),
+			SecretKey:   config.NewSecret([]byte("dummy")),
 			EndpointURL: ts.URL,
 		},
 		LogGroup:     "TestLogGroup",
@@ -0,0 +1,385 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cloudwatch_logs_emf
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits set by AWS, see
+// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const (
+	maxEventsPerRequest  = 10000
+	maxRequestSize       = 1048576
+	eventOverhead        = 26
+	maxEventSize         = 262144 - eventOverhead
+	maxRequestTimeSpan   = 24 * time.Hour
+	maxFutureEventOffset = 2 * time.Hour
+	maxPastEventOffset   = 14 * 24 * time.Hour
+	maxMetricsPerEntry   = 100
+	maxDimensions        = 30
+)
+
+type CloudWatchLogsEMF struct {
+	LogGroup              string          `toml:"log_group"`
+	LogStream             string          `toml:"log_stream"`
+	Namespace             string          `toml:"namespace"`
+	DimensionTags         []string        `toml:"dimension_tags"`
+	HighResolutionMetrics bool            `toml:"high_resolution_metrics"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client          cloudWatchLogsClient
+	dimensionFilter filter.Filter
+	streamCreated   bool
+}
+
+// cloudWatchLogsClient contains the CloudWatch Logs API used, implemented by
+// cloudwatchlogs.Client
+type cloudWatchLogsClient interface {
+	CreateLogStream(
+		ctx context.Context,
+		params *cloudwatchlogs.CreateLogStreamInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(
+		ctx context.Context,
+		params *cloudwatchlogs.PutLogEventsInput,
+		optFns ...func(*cloudwatchlogs.Options),
+	) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// metadata is the '_aws' member of an EMF event instructing CloudWatch to
+// extract the referenced members as metrics
+type metadata struct {
+	Timestamp         int64       `json:"Timestamp"`
+	CloudWatchMetrics []directive `json:"CloudWatchMetrics"`
+}
+
+type directive struct {
+	Namespace  string             `json:"Namespace"`
+	Dimensions [][]string         `json:"Dimensions"`
+	Metrics    []metricDefinition `json:"Metrics"`
+}
+
+type metricDefinition struct {
+	Name              string `json:"Name"`
+	StorageResolution int    `json:"StorageResolution,omitempty"`
+}
+
+// event collects the members of all metrics of the same series and timestamp
+type event struct {
+	timestamp  int64
+	dimensions []string
+	metrics    []string
+	members    map[string]interface{}
+}
+
+func (*CloudWatchLogsEMF) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CloudWatchLogsEMF) Init() error {
+	if c.LogGroup == "" {
+		return errors.New("'log_group' is required")
+	}
+	if c.LogStream == "" {
+		return errors.New("'log_stream' is required")
+	}
+	if c.Namespace == "" {
+		return errors.New("'namespace' is required")
+	}
+
+	if c.DimensionTags == nil {
+		c.DimensionTags = []string{"*"}
+	}
+	f, err := filter.Compile(c.DimensionTags)
+	if err != nil {
+		return fmt.Errorf("invalid 'dimension_tags': %w", err)
+	}
+	c.dimensionFilter = f
+
+	return nil
+}
+
+func (c *CloudWatchLogsEMF) Connect() error {
+	if c.client == nil {
+		httpClient, err := c.ClientConfig.CreateClient()
+		if err != nil {
+			return err
+		}
+		c.CredentialConfig.HTTPClient = httpClient
+
+		cfg, err := c.CredentialConfig.Credentials()
+		if err != nil {
+			return err
+		}
+		c.client = cloudwatchlogs.NewFromConfig(cfg, func(o *cloudwatchlogs.Options) {
+			if c.EndpointURL != "" {
+				o.BaseEndpoint = &c.EndpointURL
+			}
+		})
+	}
+
+	return c.createLogStream()
+}
+
+func (*CloudWatchLogsEMF) Close() error {
+	return nil
+}
+
+// Write converts the metrics into EMF events and puts them into the log
+// stream using as few requests as the limits allow. Events that cannot be
+// accepted by CloudWatch are dropped, all other failures cause the whole
+// batch to be written again.
+func (c *CloudWatchLogsEMF) Write(metrics []telegraf.Metric) error {
+	if err := c.createLogStream(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	minTime, maxTime := now.Add(-maxPastEventOffset), now.Add(maxFutureEventOffset)
+
+	events := c.collect(metrics, minTime, maxTime)
+	logEvents := make([]types.InputLogEvent, 0, len(events))
+	for _, e := range events {
+		message, err := c.serialize(e)
+		if err != nil {
+			c.Log.Errorf("Dropping event: %v", err)
+			continue
+		}
+		if len(message) > maxEventSize {
+			c.Log.Errorf("Dropping event: size %d exceeds limit of %d bytes", len(message), maxEventSize)
+			continue
+		}
+		logEvents = append(logEvents, types.InputLogEvent{
+			Message:   aws.String(string(message)),
+			Timestamp: aws.Int64(e.timestamp),
+		})
+	}
+
+	// Events within a request must be in chronological order
+	sort.SliceStable(logEvents, func(i, j int) bool {
+		return *logEvents[i].Timestamp < *logEvents[j].Timestamp
+	})
+
+	var start, size int
+	for i, e := range logEvents {
+		eventSize := len(*e.Message) + eventOverhead
+		if i-start == maxEventsPerRequest || size+eventSize > maxRequestSize ||
+			time.Duration(*e.Timestamp-*logEvents[start].Timestamp)*time.Millisecond > maxRequestTimeSpan {
+			if err := c.put(logEvents[start:i]); err != nil {
+				return err
+			}
+			start, size = i, 0
+		}
+		size += eventSize
+	}
+	if start < len(logEvents) {
+		return c.put(logEvents[start:])
+	}
+	return nil
+}
+
+// collect groups the fields of metrics with the same name, tags and
+// timestamp into a single event. Metrics outside the time range accepted by
+// CloudWatch Logs are dropped.
+func (c *CloudWatchLogsEMF) collect(metrics []telegraf.Metric, minTime, maxTime time.Time) []*event {
+	type key struct {
+		series    uint64
+		timestamp int64
+	}
+
+	events := make([]*event, 0, len(metrics))
+	index := make(map[key]*event, len(metrics))
+	for _, m := range metrics {
+		if m.Time().Before(minTime) || m.Time().After(maxTime) {
+			c.Log.Debugf("Dropping metric %q: timestamp %v is outside of the accepted range", m.Name(), m.Time())
+			continue
+		}
+
+		k := key{series: m.HashID(), timestamp: m.Time().UnixMilli()}
+		e, found := index[k]
+		if !found {
+			e = &event{
+				timestamp: k.timestamp,
+				members:   make(map[string]interface{}, len(m.TagList())+len(m.FieldList())),
+			}
+			for _, tag := range m.TagList() {
+				e.members[tag.Key] = tag.Value
+				if tag.Value != "" && c.dimensionFilter.Match(tag.Key) {
+					e.dimensions = append(e.dimensions, tag.Key)
+				}
+			}
+			// CloudWatch rejects events exceeding the dimension limit, so only
+			// keep the first tags in alphabetical order like the tag list
+			if len(e.dimensions) > maxDimensions {
+				e.dimensions = e.dimensions[:maxDimensions]
+			}
+			index[k] = e
+			events = append(events, e)
+		}
+
+		for _, field := range m.FieldList() {
+			name := m.Name() + "_" + field.Key
+			if _, found := e.members[name]; found {
+				continue
+			}
+			switch v := field.Value.(type) {
+			case string:
+				// Strings are kept as searchable properties of the log event
+				e.members[name] = v
+			default:
+				value, ok := convert(v)
+				if !ok {
+					continue
+				}
+				e.members[name] = value
+				e.metrics = append(e.metrics, name)
+			}
+		}
+	}
+	return events
+}
+
+// serialize creates the EMF log message of the event, splitting the metric
+// definitions into directives of at most 100 metrics each
+func (c *CloudWatchLogsEMF) serialize(e *event) ([]byte, error) {
+	sort.Strings(e.metrics)
+
+	var resolution int
+	if c.HighResolutionMetrics {
+		resolution = 1
+	}
+
+	dimensions := e.dimensions
+	if dimensions == nil {
+		dimensions = make([]string, 0)
+	}
+
+	meta := metadata{
+		Timestamp:         e.timestamp,
+		CloudWatchMetrics: make([]directive, 0, (len(e.metrics)+maxMetricsPerEntry-1)/maxMetricsPerEntry),
+	}
+	for start := 0; start < len(e.metrics); start += maxMetricsPerEntry {
+		names := e.metrics[start:min(start+maxMetricsPerEntry, len(e.metrics))]
+		definitions := make([]metricDefinition, 0, len(names))
+		for _, name := range names {
+			definitions = append(definitions, metricDefinition{Name: name, StorageResolution: resolution})
+		}
+		meta.CloudWatchMetrics = append(meta.CloudWatchMetrics, directive{
+			Namespace:  c.Namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    definitions,
+		})
+	}
+	e.members["_aws"] = meta
+
+	message, err := json.Marshal(e.members)
+	if err != nil {
+		return nil, fmt.Errorf("serialization failed: %w", err)
+	}
+	return message, nil
+}
+
+func (c *CloudWatchLogsEMF) put(events []types.InputLogEvent) error {
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(c.LogGroup),
+		LogStreamName: aws.String(c.LogStream),
+		LogEvents:     events,
+	}
+	out, err := c.client.PutLogEvents(context.Background(), input)
+	if err != nil {
+		return fmt.Errorf("putting log events failed: %w", err)
+	}
+
+	if info := out.RejectedLogEventsInfo; info != nil {
+		if info.TooOldLogEventEndIndex != nil {
+			c.Log.Errorf("Log events up to index %d rejected as too old", *info.TooOldLogEventEndIndex)
+		}
+		if info.TooNewLogEventStartIndex != nil {
+			c.Log.Errorf("Log events starting at index %d rejected as too new", *info.TooNewLogEventStartIndex)
+		}
+		if info.ExpiredLogEventEndIndex != nil {
+			c.Log.Errorf("Log events up to index %d rejected as expired", *info.ExpiredLogEventEndIndex)
+		}
+	}
+	return nil
+}
+
+// createLogStream creates the log stream if not done yet, an existing log
+// stream is not an error
+func (c *CloudWatchLogsEMF) createLogStream() error {
+	if c.streamCreated {
+		return nil
+	}
+
+	input := &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(c.LogGroup),
+		LogStreamName: aws.String(c.LogStream),
+	}
+	if _, err := c.client.CreateLogStream(context.Background(), input); err != nil {
+		var exists *types.ResourceAlreadyExistsException
+		if !errors.As(err, &exists) {
+			return fmt.Errorf("creating log stream %q in log group %q failed: %w", c.LogStream, c.LogGroup, err)
+		}
+	}
+	c.streamCreated = true
+	return nil
+}
+
+// convert returns the value as float64 if it can be represented as a
+// CloudWatch metric value
+func convert(v interface{}) (float64, bool) {
+	var value float64
+	switch t := v.(type) {
+	case int64:
+		value = float64(t)
+	case uint64:
+		value = float64(t)
+	case float64:
+		value = t
+	case bool:
+		if t {
+			value = 1
+		}
+	default:
+		return 0, false
+	}
+
+	// Constraints at: http://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_MetricDatum.html
+	switch {
+	case math.IsNaN(value), math.IsInf(value, 0):
+		return 0, false
+	case value > 0 && value < 8.515920e-109, value > 1.174271e+108:
+		return 0, false
+	}
+	return value, true
+}
+
+func init() {
+	outputs.Add("cloudwatch_logs_emf", func() telegraf.Output {
+		return &CloudWatchLogsEMF{}
+	})
+}
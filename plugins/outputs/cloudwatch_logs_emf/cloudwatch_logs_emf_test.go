@@ -0,0 +1,324 @@
+package cloudwatch_logs_emf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestWriteEvent(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		DimensionTags: []string{"host"},
+		LogGroup:      "telegraf",
+		LogStream:     "test",
+		Namespace:     "Test",
+		Log:           testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	now := time.Now().Truncate(time.Millisecond)
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5, "busy": true, "state": "ok"},
+			now,
+		),
+		// Fields of the same series and timestamp end up in the same event
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0"},
+			map[string]interface{}{"count": int64(4)},
+			now,
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, client.requests, 1)
+	require.Equal(t, "telegraf", aws.ToString(client.requests[0].LogGroupName))
+	require.Equal(t, "test", aws.ToString(client.requests[0].LogStreamName))
+	events := client.requests[0].LogEvents
+	require.Len(t, events, 1)
+	require.Equal(t, now.UnixMilli(), aws.ToInt64(events[0].Timestamp))
+
+	expected := `{
+		"_aws": {
+			"Timestamp": ` + strconv.FormatInt(now.UnixMilli(), 10) + `,
+			"CloudWatchMetrics": [{
+				"Namespace": "Test",
+				"Dimensions": [["host"]],
+				"Metrics": [{"Name": "cpu_busy"}, {"Name": "cpu_count"}, {"Name": "cpu_usage_idle"}]
+			}]
+		},
+		"cpu": "cpu0",
+		"cpu_busy": 1,
+		"cpu_count": 4,
+		"cpu_state": "ok",
+		"cpu_usage_idle": 98.5,
+		"host": "server01"
+	}`
+	require.JSONEq(t, expected, aws.ToString(events[0].Message))
+}
+
+func TestWriteMetricLimit(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		HighResolutionMetrics: true,
+		LogGroup:              "telegraf",
+		LogStream:             "test",
+		Namespace:             "Test",
+		Log:                   testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	fields := make(map[string]interface{}, 250)
+	for i := range 250 {
+		fields["field"+strconv.Itoa(i)] = int64(i)
+	}
+	m := metric.New("test", map[string]string{}, fields, time.Now())
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	require.Len(t, client.requests, 1)
+	require.Len(t, client.requests[0].LogEvents, 1)
+
+	var event struct {
+		AWS metadata `json:"_aws"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(aws.ToString(client.requests[0].LogEvents[0].Message)), &event))
+	require.Len(t, event.AWS.CloudWatchMetrics, 3)
+	require.Len(t, event.AWS.CloudWatchMetrics[0].Metrics, 100)
+	require.Len(t, event.AWS.CloudWatchMetrics[1].Metrics, 100)
+	require.Len(t, event.AWS.CloudWatchMetrics[2].Metrics, 50)
+	require.Equal(t, [][]string{{}}, event.AWS.CloudWatchMetrics[0].Dimensions)
+	require.Equal(t, 1, event.AWS.CloudWatchMetrics[0].Metrics[0].StorageResolution)
+}
+
+func TestWriteBatches(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, maxEventsPerRequest+10)
+	for i := range maxEventsPerRequest + 10 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "host-" + strconv.Itoa(i)},
+			map[string]interface{}{"value": int64(i)},
+			now.Add(-time.Duration(i)*time.Millisecond),
+		))
+	}
+	// Metrics outside the accepted time range are dropped
+	metrics = append(metrics,
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-15*24*time.Hour)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(3*time.Hour)),
+	)
+	require.NoError(t, plugin.Write(metrics))
+
+	// Events must be in chronological order
+	var last int64
+	var count int
+	for _, r := range client.requests {
+		require.LessOrEqual(t, len(r.LogEvents), maxEventsPerRequest)
+		count += len(r.LogEvents)
+		for _, e := range r.LogEvents {
+			require.GreaterOrEqual(t, aws.ToInt64(e.Timestamp), last)
+			last = aws.ToInt64(e.Timestamp)
+		}
+	}
+	require.Equal(t, maxEventsPerRequest+10, count)
+}
+
+func TestWriteRequestSize(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Only four events fit into a request
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, 6)
+	for i := range 5 {
+		metrics = append(metrics, metric.New(
+			"log",
+			map[string]string{"id": strconv.Itoa(i)},
+			map[string]interface{}{"message": strings.Repeat("x", 250000)},
+			now,
+		))
+	}
+	// Events exceeding the limit are dropped
+	metrics = append(metrics, metric.New(
+		"log",
+		map[string]string{},
+		map[string]interface{}{"message": strings.Repeat("x", maxEventSize)},
+		now,
+	))
+
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, client.requests, 2)
+	require.Len(t, client.requests[0].LogEvents, 4)
+	require.Len(t, client.requests[1].LogEvents, 1)
+}
+
+func TestConnectLogStream(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.Equal(t, 1, client.created)
+
+	// An existing log stream is fine
+	plugin = &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client = &mockClient{createErr: &types.ResourceAlreadyExistsException{}}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	require.Equal(t, 1, client.created)
+
+	// Failing to create the log stream is retried on write
+	plugin = &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client = &mockClient{createErr: &types.ResourceNotFoundException{}}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Connect(), `creating log stream "test" in log group "telegraf" failed`)
+
+	client.createErr = nil
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Now())
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Equal(t, 2, client.created)
+	require.Len(t, client.requests, 1)
+}
+
+func TestWriteFailed(t *testing.T) {
+	plugin := &CloudWatchLogsEMF{
+		LogGroup:  "telegraf",
+		LogStream: "test",
+		Namespace: "Test",
+		Log:       testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	client.putErr = errors.New("throttled")
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Now())
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "putting log events failed: throttled")
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CloudWatchLogsEMF
+		expected string
+	}{
+		{
+			name:     "no log group",
+			plugin:   &CloudWatchLogsEMF{LogStream: "test", Namespace: "Test"},
+			expected: "'log_group' is required",
+		},
+		{
+			name:     "no log stream",
+			plugin:   &CloudWatchLogsEMF{LogGroup: "telegraf", Namespace: "Test"},
+			expected: "'log_stream' is required",
+		},
+		{
+			name:     "no namespace",
+			plugin:   &CloudWatchLogsEMF{LogGroup: "telegraf", LogStream: "test"},
+			expected: "'namespace' is required",
+		},
+		{
+			name: "invalid dimension filter",
+			plugin: &CloudWatchLogsEMF{
+				LogGroup:      "telegraf",
+				LogStream:     "test",
+				Namespace:     "Test",
+				DimensionTags: []string{"[a"},
+			},
+			expected: "invalid 'dimension_tags'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+type mockClient struct {
+	created   int
+	createErr error
+	putErr    error
+	requests  []*cloudwatchlogs.PutLogEventsInput
+}
+
+func (c *mockClient) CreateLogStream(
+	context.Context,
+	*cloudwatchlogs.CreateLogStreamInput,
+	...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	c.created++
+	if c.createErr != nil {
+		return nil, c.createErr
+	}
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (c *mockClient) PutLogEvents(
+	_ context.Context,
+	input *cloudwatchlogs.PutLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if c.putErr != nil {
+		return nil, c.putErr
+	}
+	c.requests = append(c.requests, input)
+	return &cloudwatchlogs.PutLogEventsOutput{}, nil
+}
@@ -0,0 +1,96 @@
+package eventbridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// entry is a single event of a PutEvents request, see
+// https://docs.aws.amazon.com/eventbridge/latest/APIReference/API_PutEventsRequestEntry.html
+type entry struct {
+	Source       string   `json:"Source"`
+	DetailType   string   `json:"DetailType"`
+	Detail       string   `json:"Detail"`
+	EventBusName string   `json:"EventBusName,omitempty"`
+	Resources    []string `json:"Resources,omitempty"`
+	Time         float64  `json:"Time,omitempty"`
+}
+
+// resultEntry is the result of a single event, containing the error for
+// events not accepted by EventBridge
+type resultEntry struct {
+	EventID      string `json:"EventId"`
+	ErrorCode    string `json:"ErrorCode"`
+	ErrorMessage string `json:"ErrorMessage"`
+}
+
+type putEventsResponse struct {
+	FailedEntryCount int           `json:"FailedEntryCount"`
+	Entries          []resultEntry `json:"Entries"`
+}
+
+// eventsClient contains the EventBridge API used, implemented by apiClient
+type eventsClient interface {
+	PutEvents(ctx context.Context, entries []entry) (*putEventsResponse, error)
+}
+
+// apiClient calls the PutEvents action of the EventBridge JSON API using
+// signed HTTP requests
+type apiClient struct {
+	endpoint string
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   aws.HTTPClient
+}
+
+func (c *apiClient) PutEvents(ctx context.Context, entries []entry) (*putEventsResponse, error) {
+	body, err := json.Marshal(map[string][]entry{"Entries": entries})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSEvents.PutEvents")
+
+	creds, err := c.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving credentials failed: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "events", c.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("signing request failed: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("received status %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var response putEventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return &response, nil
+}
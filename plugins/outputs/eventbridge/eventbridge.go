@@ -0,0 +1,262 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package eventbridge
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits set by AWS, see
+// https://docs.aws.amazon.com/eventbridge/latest/userguide/eb-putevent-size.html
+const (
+	maxEntriesPerRequest = 10
+	maxRequestSize       = 256 * 1024
+	timeSize             = 14
+)
+
+type EventBridge struct {
+	EventBusName string          `toml:"event_bus_name"`
+	Source       string          `toml:"source"`
+	DetailType   string          `toml:"detail_type"`
+	Resources    []string        `toml:"resources"`
+	Timeout      config.Duration `toml:"timeout"`
+	Log          telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client     eventsClient
+	source     *template.Template
+	detailType *template.Template
+}
+
+// detail is the content of the events, i.e. the metric without its
+// timestamp which is used as event time
+type detail struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+func (*EventBridge) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EventBridge) Init() error {
+	if e.Source == "" {
+		return errors.New("'source' is required")
+	}
+	if strings.HasPrefix(e.Source, "aws.") {
+		return errors.New("'source' must not start with \"aws.\"")
+	}
+	if e.DetailType == "" {
+		e.DetailType = "{{.Name}}"
+	}
+	if e.Timeout <= 0 {
+		e.Timeout = config.Duration(5 * time.Second)
+	}
+
+	var err error
+	if e.source, err = template.New("source").Parse(e.Source); err != nil {
+		return fmt.Errorf("parsing 'source' template failed: %w", err)
+	}
+	if e.detailType, err = template.New("detail_type").Parse(e.DetailType); err != nil {
+		return fmt.Errorf("parsing 'detail_type' template failed: %w", err)
+	}
+
+	return nil
+}
+
+func (e *EventBridge) Connect() error {
+	if e.client != nil {
+		return nil
+	}
+
+	httpClient, err := e.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	e.CredentialConfig.HTTPClient = httpClient
+
+	cfg, err := e.CredentialConfig.Credentials()
+	if err != nil {
+		return err
+	}
+	if cfg.Region == "" {
+		return errors.New("'region' is required")
+	}
+
+	endpoint := e.EndpointURL
+	if endpoint == "" {
+		endpoint = "https://events." + cfg.Region + ".amazonaws.com"
+	}
+	e.client = &apiClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   cfg.Region,
+		creds:    cfg.Credentials,
+		signer:   v4.NewSigner(),
+		client:   httpClient,
+	}
+
+	return nil
+}
+
+func (*EventBridge) Close() error {
+	return nil
+}
+
+// Write sends each metric as an event to the event bus, using as few
+// PutEvents requests as the entry and size limits allow. Metrics failing to
+// convert or rejected by EventBridge due to their content are dropped, all
+// other failures cause the whole batch to be written again.
+func (e *EventBridge) Write(metrics []telegraf.Metric) error {
+	entries := make([]entry, 0, maxEntriesPerRequest)
+	var size int
+	var failed int
+	for _, m := range metrics {
+		event, err := e.createEntry(m)
+		if err != nil {
+			e.Log.Errorf("Dropping metric %q: %v", m.Name(), err)
+			continue
+		}
+
+		eventSize := entrySize(event)
+		if eventSize > maxRequestSize {
+			e.Log.Errorf("Dropping metric %q: event size %d exceeds limit of %d bytes", m.Name(), eventSize, maxRequestSize)
+			continue
+		}
+
+		if len(entries) == maxEntriesPerRequest || size+eventSize > maxRequestSize {
+			n, err := e.send(entries)
+			if err != nil {
+				return err
+			}
+			failed += n
+			entries, size = entries[:0], 0
+		}
+		entries = append(entries, *event)
+		size += eventSize
+	}
+
+	if len(entries) > 0 {
+		n, err := e.send(entries)
+		if err != nil {
+			return err
+		}
+		failed += n
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("sending %d event(s) failed", failed)
+	}
+	return nil
+}
+
+func (e *EventBridge) createEntry(m telegraf.Metric) (*entry, error) {
+	body, err := json.Marshal(detail{
+		Name:   m.Name(),
+		Tags:   m.Tags(),
+		Fields: m.Fields(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding detail failed: %w", err)
+	}
+
+	source, err := execute(e.source, m)
+	if err != nil {
+		return nil, err
+	}
+	detailType, err := execute(e.detailType, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry{
+		Source:       source,
+		DetailType:   detailType,
+		Detail:       string(body),
+		EventBusName: e.EventBusName,
+		Resources:    e.Resources,
+		Time:         float64(m.Time().UnixMilli()) / 1000,
+	}, nil
+}
+
+// send sends the entries and returns the number of entries failing due to
+// an error not caused by the event itself
+func (e *EventBridge) send(entries []entry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
+	defer cancel()
+
+	out, err := e.client.PutEvents(ctx, entries)
+	if err != nil {
+		return 0, fmt.Errorf("sending events failed: %w", err)
+	}
+	if out.FailedEntryCount == 0 {
+		return 0, nil
+	}
+
+	var failed int
+	for _, r := range out.Entries {
+		switch r.ErrorCode {
+		case "":
+		case "InternalFailure", "InternalException", "ThrottlingException":
+			e.Log.Debugf("Sending event failed: %s: %s", r.ErrorCode, r.ErrorMessage)
+			failed++
+		default:
+			e.Log.Errorf("Event rejected: %s: %s", r.ErrorCode, r.ErrorMessage)
+		}
+	}
+	return failed, nil
+}
+
+func execute(tmpl *template.Template, m telegraf.Metric) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return "", fmt.Errorf("executing %s template failed: %w", tmpl.Name(), err)
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("%s template resulted in empty value", tmpl.Name())
+	}
+	return buf.String(), nil
+}
+
+// entrySize returns the size of the event as counted by EventBridge, i.e.
+// the size of the time, source, detail type, detail and resources
+func entrySize(event *entry) int {
+	size := timeSize + len(event.Source) + len(event.DetailType) + len(event.Detail)
+	for _, resource := range event.Resources {
+		size += len(resource)
+	}
+	return size
+}
+
+func init() {
+	outputs.Add("eventbridge", func() telegraf.Output {
+		return &EventBridge{
+			EventBusName: "default",
+			Source:       "telegraf",
+			Timeout:      config.Duration(5 * time.Second),
+		}
+	})
+}
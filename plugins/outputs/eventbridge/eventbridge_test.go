@@ -0,0 +1,226 @@
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func testMetrics(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "host-" + strconv.Itoa(i%2)},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(int64(i), 500*int64(time.Millisecond)),
+		))
+	}
+	return metrics
+}
+
+func TestWriteBatches(t *testing.T) {
+	plugin := &EventBridge{
+		EventBusName: "alerts",
+		Source:       `telegraf.{{.Tag "host"}}`,
+		Resources:    []string{"arn:aws:ec2:us-east-1:123456789012:instance/i-1234567890abcdef0"},
+		Log:          testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics(25)))
+	require.Len(t, client.requests, 3)
+	require.Len(t, client.requests[0], 10)
+	require.Len(t, client.requests[1], 10)
+	require.Len(t, client.requests[2], 5)
+
+	event := client.requests[1][3]
+	require.Equal(t, "telegraf.host-1", event.Source)
+	require.Equal(t, "cpu", event.DetailType)
+	require.Equal(t, "alerts", event.EventBusName)
+	require.Equal(t, plugin.Resources, event.Resources)
+	require.InDelta(t, 13.5, event.Time, 1e-9)
+	require.JSONEq(t, `{"name":"cpu","tags":{"host":"host-1"},"fields":{"value":13}}`, event.Detail)
+}
+
+func TestWriteRequestSize(t *testing.T) {
+	plugin := &EventBridge{
+		Source: "telegraf",
+		Log:    testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := make([]telegraf.Metric, 0, 4)
+	for i := range 4 {
+		metrics = append(metrics, metric.New(
+			"large",
+			map[string]string{},
+			map[string]interface{}{"value": strings.Repeat(strconv.Itoa(i), 100*1024)},
+			time.Unix(0, 0),
+		))
+	}
+	// Exceeds the size limit of a single event and is dropped
+	metrics = append(metrics, metric.New(
+		"huge",
+		map[string]string{},
+		map[string]interface{}{"value": strings.Repeat("x", 300*1024)},
+		time.Unix(0, 0),
+	))
+	// Cannot be encoded as JSON and is dropped
+	metrics = append(metrics, metric.New(
+		"infinite",
+		map[string]string{},
+		map[string]interface{}{"value": math.Inf(1)},
+		time.Unix(0, 0),
+	))
+
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, client.requests, 2)
+	for _, request := range client.requests {
+		var size int
+		for i := range request {
+			size += entrySize(&request[i])
+		}
+		require.LessOrEqual(t, size, maxRequestSize)
+		require.Len(t, request, 2)
+	}
+}
+
+func TestWriteFailed(t *testing.T) {
+	plugin := &EventBridge{
+		Source: "telegraf",
+		Log:    testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Events rejected due to their content are dropped
+	client.results = []resultEntry{
+		{ErrorCode: "MalformedDetail", ErrorMessage: "Detail is malformed."},
+		{EventID: "1"},
+	}
+	require.NoError(t, plugin.Write(testMetrics(2)))
+
+	// Other failures are retried with the whole batch
+	client.results = []resultEntry{
+		{EventID: "0"},
+		{ErrorCode: "ThrottlingException", ErrorMessage: "Rate exceeded"},
+	}
+	require.ErrorContains(t, plugin.Write(testMetrics(2)), "sending 1 event(s) failed")
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *EventBridge
+		expected string
+	}{
+		{
+			name:     "no source",
+			plugin:   &EventBridge{},
+			expected: "'source' is required",
+		},
+		{
+			name:     "reserved source",
+			plugin:   &EventBridge{Source: "aws.ec2"},
+			expected: `'source' must not start with "aws."`,
+		},
+		{
+			name:     "invalid source template",
+			plugin:   &EventBridge{Source: "{{.Name"},
+			expected: "parsing 'source' template failed",
+		},
+		{
+			name:     "invalid detail type template",
+			plugin:   &EventBridge{Source: "telegraf", DetailType: "{{.Name"},
+			expected: "parsing 'detail_type' template failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAPIClient(t *testing.T) {
+	var received struct {
+		Entries []entry `json:"Entries"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AWSEvents.PutEvents" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/events/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if _, err := w.Write([]byte(`{"FailedEntryCount":0,"Entries":[{"EventId":"abc"}]}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	client := &apiClient{
+		endpoint: server.URL,
+		region:   "us-east-1",
+		creds:    aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("key", "secret", "")),
+		signer:   v4.NewSigner(),
+		client:   server.Client(),
+	}
+	entries := []entry{{Source: "telegraf", DetailType: "cpu", Detail: `{"name":"cpu"}`, Time: 1.5}}
+	out, err := client.PutEvents(context.Background(), entries)
+	require.NoError(t, err)
+	require.Equal(t, []resultEntry{{EventID: "abc"}}, out.Entries)
+	require.Equal(t, entries, received.Entries)
+}
+
+type mockClient struct {
+	requests [][]entry
+	results  []resultEntry
+	sync.Mutex
+}
+
+func (c *mockClient) PutEvents(_ context.Context, entries []entry) (*putEventsResponse, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.requests = append(c.requests, append([]entry(nil), entries...))
+	out := &putEventsResponse{Entries: c.results}
+	for _, r := range c.results {
+		if r.ErrorCode != "" {
+			out.FailedEntryCount++
+		}
+	}
+	return out, nil
+}
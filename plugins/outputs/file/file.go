@@ -3,9 +3,13 @@ package file
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -25,6 +29,7 @@ type File struct {
 	RotationMaxSize      config.Size     `toml:"rotation_max_size"`
 	RotationMaxArchives  int             `toml:"rotation_max_archives"`
 	UseBatchFormat       bool            `toml:"use_batch_format"`
+	FilePerBatch         bool            `toml:"file_per_batch"`
 	CompressionAlgorithm string          `toml:"compression_algorithm"`
 	CompressionLevel     int             `toml:"compression_level"`
 	Log                  telegraf.Logger `toml:"-"`
@@ -58,11 +63,23 @@ func (f *File) Init() error {
 		options = append(options, internal.WithCompressionLevel(f.CompressionLevel))
 	}
 	f.encoder, err = internal.NewContentEncoder(f.CompressionAlgorithm, options...)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if f.FilePerBatch && !f.UseBatchFormat {
+		return errors.New("'file_per_batch' requires 'use_batch_format' to be enabled")
+	}
+
+	return nil
 }
 
 func (f *File) Connect() error {
+	if f.FilePerBatch {
+		// Files are created on each write
+		return nil
+	}
+
 	var writers []io.Writer
 
 	for _, file := range f.Files {
@@ -97,6 +114,10 @@ func (f *File) Close() error {
 func (f *File) Write(metrics []telegraf.Metric) error {
 	var writeErr error
 
+	if f.FilePerBatch {
+		return f.writeBatchFiles(metrics)
+	}
+
 	if f.UseBatchFormat {
 		octets, err := f.serializer.SerializeBatch(metrics)
 		if err != nil {
@@ -134,6 +155,41 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 	return writeErr
 }
 
+// writeBatchFiles writes the batch to new files named after the configured
+// files with the current unix time in nanoseconds appended to the stem,
+// e.g. "/tmp/metrics-1700000000000000000.parquet". This is required for
+// formats producing self-contained files which cannot be appended to.
+func (f *File) writeBatchFiles(metrics []telegraf.Metric) error {
+	octets, err := f.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return fmt.Errorf("could not serialize metrics: %w", err)
+	}
+	if len(octets) == 0 {
+		return nil
+	}
+	octets, err = f.encoder.Encode(octets)
+	if err != nil {
+		return fmt.Errorf("could not compress metrics: %w", err)
+	}
+
+	suffix := "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	for _, file := range f.Files {
+		if file == "stdout" {
+			if _, err := os.Stdout.Write(octets); err != nil {
+				return fmt.Errorf("failed to write to stdout: %w", err)
+			}
+			continue
+		}
+
+		ext := filepath.Ext(file)
+		filename := strings.TrimSuffix(file, ext) + suffix + ext
+		if err := os.WriteFile(filename, octets, rotate.FilePerm); err != nil {
+			return fmt.Errorf("failed to write file %q: %w", filename, err)
+		}
+	}
+	return nil
+}
+
 func init() {
 	outputs.Add("file", func() telegraf.Output {
 		return &File{
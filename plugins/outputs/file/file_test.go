@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -291,6 +292,42 @@ func TestFileStdout(t *testing.T) {
 	require.Equal(t, expNewFile, out.str)
 }
 
+func TestFilePerBatch(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:            []string{filepath.Join(dir, "metrics.out")},
+		UseBatchFormat:   true,
+		FilePerBatch:     true,
+		serializer:       s,
+		CompressionLevel: -1,
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+
+	require.NoError(t, f.Write(testutil.MockMetrics()))
+	require.NoError(t, f.Write(testutil.MockMetrics()))
+	require.NoError(t, f.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "metrics-*.out"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, fn := range files {
+		validateFile(t, fn, expNewFile)
+	}
+}
+
+func TestFilePerBatchRequiresBatchFormat(t *testing.T) {
+	f := File{
+		Files:            []string{"stdout"},
+		FilePerBatch:     true,
+		CompressionLevel: -1,
+	}
+	require.ErrorContains(t, f.Init(), "requires 'use_batch_format'")
+}
+
 func createFile(t *testing.T) *os.File {
 	f, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
@@ -36,6 +36,9 @@ type Graphite struct {
 	GraphiteTagSanitizeMode string `toml:"graphite_tag_sanitize_mode"`
 	GraphiteSeparator       string `toml:"graphite_separator"`
 	GraphiteStrictRegex     string `toml:"graphite_strict_sanitize_regex"`
+	GraphiteFloatFormat     string `toml:"graphite_float_format"`
+	GraphiteFloatPrecision  int    `toml:"graphite_float_precision"`
+	GraphiteIntegerOverflow string `toml:"graphite_integer_overflow"`
 	// URL is only for backwards compatibility
 	Servers   []string        `toml:"servers"`
 	LocalAddr string          `toml:"local_address"`
@@ -63,6 +66,9 @@ func (g *Graphite) Init() error {
 		TagSanitizeMode: g.GraphiteTagSanitizeMode,
 		Separator:       g.GraphiteSeparator,
 		Templates:       g.Templates,
+		FloatFormat:     g.GraphiteFloatFormat,
+		FloatPrecision:  g.GraphiteFloatPrecision,
+		IntegerOverflow: g.GraphiteIntegerOverflow,
 	}
 	if err := s.Init(); err != nil {
 		return err
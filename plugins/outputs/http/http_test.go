@@ -751,8 +751,8 @@ func TestAwsCredentials(t *testing.T) {
 				AwsService: "aps",
 				CredentialConfig: common_aws.CredentialConfig{
 					Region:    "us-east-1",
-					AccessKey: "dummy",
-					SecretKey: "dummy",
+					AccessKey: config.NewSecret([]byte("dummy")),
+					SecretKey: config.NewSecret([]byte("dummy")),
 				},
 			},
 			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
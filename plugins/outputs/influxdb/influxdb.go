@@ -15,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/breaker"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -62,8 +63,10 @@ type InfluxDB struct {
 	Precision                 string            `toml:"precision" deprecated:"1.0.0;1.35.0;option is ignored"`
 	Log                       telegraf.Logger   `toml:"-"`
 	tls.ClientConfig
+	breaker.Config
 
-	clients []Client
+	clients  []Client
+	breakers []*breaker.Breaker
 
 	CreateHTTPClientF func(config *HTTPConfig) (Client, error)
 	CreateUDPClientF  func(config *UDPConfig) (Client, error)
@@ -134,6 +137,7 @@ func (i *InfluxDB) Connect() error {
 			}
 
 			i.clients = append(i.clients, c)
+			i.breakers = append(i.breakers, breaker.New(i.Config, parts.Redacted(), map[string]string{"output": "influxdb"}, i.Log))
 		case "http", "https", "unix":
 			var c Client
 			var err error
@@ -147,6 +151,7 @@ func (i *InfluxDB) Connect() error {
 			}
 
 			i.clients = append(i.clients, c)
+			i.breakers = append(i.breakers, breaker.New(i.Config, parts.Redacted(), map[string]string{"output": "influxdb"}, i.Log))
 		default:
 			return fmt.Errorf("unsupported scheme [%q]: %q", u, parts.Scheme)
 		}
@@ -168,12 +173,19 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	ctx := context.Background()
 
 	allErrorsAreDatabaseNotFoundErrors := true
+	var attempted bool
 	var err error
 	p := rand.Perm(len(i.clients))
 	for _, n := range p {
 		client := i.clients[n]
+		if !i.breakers[n].Allow() {
+			continue
+		}
+		attempted = true
+
 		err = client.Write(ctx, metrics)
 		if err == nil {
+			i.breakers[n].Success()
 			return nil
 		}
 
@@ -181,6 +193,8 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 
 		var apiError *DatabaseNotFoundError
 		if errors.As(err, &apiError) {
+			// The server is responding so the endpoint is healthy
+			i.breakers[n].Success()
 			if i.SkipDatabaseCreation {
 				continue
 			}
@@ -191,10 +205,14 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 			}
 			i.Log.Errorf("When writing to [%s]: database %q not found and failed to recreate", client.URL(), apiError.Database)
 		} else {
+			i.breakers[n].Failure()
 			allErrorsAreDatabaseNotFoundErrors = false
 		}
 	}
 
+	if !attempted {
+		return errors.New("circuits of all addresses are open")
+	}
 	if allErrorsAreDatabaseNotFoundErrors {
 		// return nil because we should not be retrying this
 		return nil
@@ -2,6 +2,7 @@ package influxdb_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/breaker"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs/influxdb"
 	"github.com/influxdata/telegraf/testutil"
@@ -234,3 +236,58 @@ func TestInfluxDBLocalAddress(t *testing.T) {
 
 	require.NoError(t, output.Connect())
 }
+
+func TestWriteCircuitBreaker(t *testing.T) {
+	writes := make(map[string]int)
+	healthy := map[string]bool{
+		"http://failing:8086": false,
+		"http://healthy:8086": true,
+	}
+	output := influxdb.InfluxDB{
+		URLs:                 []string{"http://failing:8086", "http://healthy:8086"},
+		SkipDatabaseCreation: true,
+		Config: breaker.Config{
+			CircuitFailureThreshold: 1,
+			CircuitOpenTimeout:      config.Duration(time.Hour),
+		},
+		CreateHTTPClientF: func(cfg *influxdb.HTTPConfig) (influxdb.Client, error) {
+			u := cfg.URL.String()
+			return &MockClient{
+				URLF: func() string { return u },
+				WriteF: func() error {
+					writes[u]++
+					if !healthy[u] {
+						return errors.New("connection refused")
+					}
+					return nil
+				},
+				CloseF: func() {},
+			}, nil
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, output.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// Write until the failing address was picked as the order is random
+	for writes["http://failing:8086"] == 0 {
+		require.NoError(t, output.Write(metrics))
+	}
+
+	// The failing address is skipped once its circuit is open
+	for range 20 {
+		require.NoError(t, output.Write(metrics))
+	}
+	require.Equal(t, 1, writes["http://failing:8086"])
+
+	// Writing fails without trying any address if all circuits are open
+	healthy["http://healthy:8086"] = false
+	require.ErrorContains(t, output.Write(metrics), "could not write any address")
+	n := writes["http://healthy:8086"]
+	require.ErrorContains(t, output.Write(metrics), "circuits of all addresses are open")
+	require.Equal(t, 1, writes["http://failing:8086"])
+	require.Equal(t, n, writes["http://healthy:8086"])
+}
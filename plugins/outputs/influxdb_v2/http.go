@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -21,8 +22,10 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 type APIError struct {
@@ -38,9 +41,28 @@ func (e APIError) Error() string {
 	return e.Title
 }
 
+var errRetryPending = errors.New("retry time has not elapsed")
+
+// serverError wraps errors indicating an unhealthy server, i.e. transport
+// errors and 5xx responses, in contrast to errors caused by the client
+type serverError struct {
+	err error
+}
+
+func (e *serverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *serverError) Unwrap() error {
+	return e.err
+}
+
 const (
 	defaultMaxWaitSeconds           = 60
 	defaultMaxWaitRetryAfterSeconds = 10 * 60
+
+	// Smallest delay between requests when pacing due to rate limiting
+	minPacingDelay = 100 * time.Millisecond
 )
 
 type httpClient struct {
@@ -51,6 +73,9 @@ type httpClient struct {
 	bucket           string
 	bucketTag        string
 	excludeBucketTag bool
+	allowedBuckets   filter.Filter
+	verifyRate       float64
+	maxPacingDelay   time.Duration
 	timeout          time.Duration
 	headers          map[string]string
 	proxy            *url.URL
@@ -65,9 +90,39 @@ type httpClient struct {
 	params           url.Values
 	retryTime        time.Time
 	retryCount       int
+	queryURL         *url.URL
+	rejectedBuckets  map[string]bool
+	pacingDelay      time.Duration
+	lastRequest      time.Time
+	stats            *writeStats
 	log              telegraf.Logger
 }
 
+// writeStats are the write statistics reported as internal metrics
+type writeStats struct {
+	writes               selfstat.Stat
+	writeErrors          selfstat.Stat
+	metricsWritten       selfstat.Stat
+	bytesWritten         selfstat.Stat
+	rateLimited          selfstat.Stat
+	pacingDelay          selfstat.Stat
+	verifications        selfstat.Stat
+	verificationFailures selfstat.Stat
+}
+
+func newWriteStats(tags map[string]string) *writeStats {
+	return &writeStats{
+		writes:               selfstat.Register("influxdb_v2", "writes", tags),
+		writeErrors:          selfstat.Register("influxdb_v2", "write_errors", tags),
+		metricsWritten:       selfstat.Register("influxdb_v2", "metrics_written", tags),
+		bytesWritten:         selfstat.Register("influxdb_v2", "bytes_written", tags),
+		rateLimited:          selfstat.Register("influxdb_v2", "rate_limited", tags),
+		pacingDelay:          selfstat.Register("influxdb_v2", "pacing_delay_ms", tags),
+		verifications:        selfstat.Register("influxdb_v2", "verifications", tags),
+		verificationFailures: selfstat.Register("influxdb_v2", "verification_failures", tags),
+	}
+}
+
 func (c *httpClient) Init() error {
 	token, err := c.token.Get()
 	if err != nil {
@@ -127,6 +182,13 @@ func (c *httpClient) Init() error {
 		return err
 	}
 
+	queryURL := *preppedURL
+	queryURL.Path = strings.TrimSuffix(queryURL.Path, "/write") + "/query"
+	c.queryURL = &queryURL
+
+	c.stats = newWriteStats(map[string]string{"url": c.url.Redacted()})
+	c.rejectedBuckets = make(map[string]bool)
+
 	c.url = preppedURL
 	c.client = &http.Client{
 		Timeout:   c.timeout,
@@ -156,7 +218,7 @@ func (g genericRespError) Error() string {
 
 func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error {
 	if c.retryTime.After(time.Now()) {
-		return errors.New("retry time has not elapsed")
+		return errRetryPending
 	}
 
 	batches := make(map[string][]telegraf.Metric)
@@ -175,7 +237,7 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	} else {
 		for _, metric := range metrics {
 			bucket, ok := metric.GetTag(c.bucketTag)
-			if !ok {
+			if !ok || !c.isAllowed(bucket) {
 				bucket = c.bucket
 			}
 
@@ -210,6 +272,49 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	return nil
 }
 
+// isAllowed checks if the bucket taken from the bucket tag is allowed to be
+// written to. Rejected buckets are logged once.
+func (c *httpClient) isAllowed(bucket string) bool {
+	if c.allowedBuckets == nil || c.allowedBuckets.Match(bucket) {
+		return true
+	}
+	if !c.rejectedBuckets[bucket] {
+		c.rejectedBuckets[bucket] = true
+		c.log.Warnf("Bucket %q is not allowed, writing metrics to %q instead", bucket, c.bucket)
+	}
+	return false
+}
+
+// pace delays the request to keep the pacing delay between requests after
+// the server limited the request rate
+func (c *httpClient) pace(ctx context.Context) error {
+	if c.pacingDelay > 0 {
+		if wait := time.Until(c.lastRequest.Add(c.pacingDelay)); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+	c.lastRequest = time.Now()
+	return nil
+}
+
+// adaptPacing doubles the pacing delay if the request was rate limited and
+// halves it otherwise
+func (c *httpClient) adaptPacing(limited bool) {
+	if c.maxPacingDelay <= 0 {
+		return
+	}
+	if limited {
+		c.pacingDelay = min(max(2*c.pacingDelay, minPacingDelay), c.maxPacingDelay)
+	} else if c.pacingDelay /= 2; c.pacingDelay < minPacingDelay {
+		c.pacingDelay = 0
+	}
+	c.stats.pacingDelay.Set(c.pacingDelay.Milliseconds())
+}
+
 func (c *httpClient) splitAndWriteBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
 	c.log.Warnf("Retrying write after splitting metric payload in half to reduce batch size")
 	midpoint := len(metrics) / 2
@@ -249,12 +354,17 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 	c.addHeaders(req)
 
 	// Execute the request
+	if err := c.pace(ctx); err != nil {
+		return err
+	}
 	resp, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
 		internal.OnClientError(c.client, err)
-		return err
+		c.stats.writeErrors.Incr(1)
+		return &serverError{err: err}
 	}
 	defer resp.Body.Close()
+	c.adaptPacing(resp.StatusCode == http.StatusTooManyRequests)
 
 	// Check for success
 	switch resp.StatusCode {
@@ -269,8 +379,18 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 		http.StatusMultiStatus,
 		http.StatusAlreadyReported:
 		c.retryCount = 0
+		c.stats.writes.Incr(1)
+		c.stats.metricsWritten.Incr(int64(len(metrics)))
+		c.stats.bytesWritten.Incr(int64(len(body)))
+		if c.verifyRate > 0 && len(metrics) > 0 && rand.Float64() < c.verifyRate {
+			c.verify(ctx, bucket, metrics[rand.Intn(len(metrics))])
+		}
 		return nil
 	}
+	c.stats.writeErrors.Incr(1)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.stats.rateLimited.Incr(1)
+	}
 
 	// We got an error and now try to decode further
 	writeResp := &genericRespError{}
@@ -310,7 +430,11 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 		retryDuration := c.getRetryDuration(resp.Header)
 		c.retryTime = time.Now().Add(retryDuration)
 		c.log.Warnf("Failed to write to %s; will retry in %s. (%s)\n", bucket, retryDuration, resp.Status)
-		return fmt.Errorf("waiting %s for server (%s) before sending metric again", retryDuration, bucket)
+		err := fmt.Errorf("waiting %s for server (%s) before sending metric again", retryDuration, bucket)
+		if resp.StatusCode >= 500 {
+			return &serverError{err: err}
+		}
+		return err
 	}
 
 	// if it's any other 4xx code, the client should not retry as it's the client's mistake.
@@ -326,11 +450,15 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 		desc = fmt.Sprintf("%s; %s", desc, xErr)
 	}
 
-	return &APIError{
+	apiErr := &APIError{
 		StatusCode:  resp.StatusCode,
 		Title:       resp.Status,
 		Description: desc,
 	}
+	if resp.StatusCode >= 500 {
+		return &serverError{err: apiErr}
+	}
+	return apiErr
 }
 
 // retryDuration takes the longer of the Retry-After header and our own back-off calculation
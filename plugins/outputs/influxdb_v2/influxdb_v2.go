@@ -16,7 +16,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/breaker"
 	commontls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -33,6 +35,9 @@ type InfluxDB struct {
 	Bucket           string            `toml:"bucket"`
 	BucketTag        string            `toml:"bucket_tag"`
 	ExcludeBucketTag bool              `toml:"exclude_bucket_tag"`
+	AllowedBuckets   []string          `toml:"allowed_buckets"`
+	VerifyRate       float64           `toml:"write_verification_rate"`
+	MaxPacingDelay   config.Duration   `toml:"max_pacing_delay"`
 	Timeout          config.Duration   `toml:"timeout"`
 	HTTPHeaders      map[string]string `toml:"http_headers"`
 	HTTPProxy        string            `toml:"http_proxy"`
@@ -44,11 +49,14 @@ type InfluxDB struct {
 	ReadIdleTimeout  config.Duration   `toml:"read_idle_timeout"`
 	Log              telegraf.Logger   `toml:"-"`
 	commontls.ClientConfig
+	breaker.Config
 
-	clients    []*httpClient
-	encoder    internal.ContentEncoder
-	serializer *influx.Serializer
-	tlsCfg     *tls.Config
+	clients        []*httpClient
+	breakers       []*breaker.Breaker
+	encoder        internal.ContentEncoder
+	serializer     *influx.Serializer
+	tlsCfg         *tls.Config
+	allowedBuckets filter.Filter
 }
 
 func (*InfluxDB) SampleConfig() string {
@@ -79,6 +87,24 @@ func (i *InfluxDB) Init() error {
 		return fmt.Errorf("invalid content encoding %q", i.ContentEncoding)
 	}
 
+	if len(i.AllowedBuckets) > 0 {
+		if i.BucketTag == "" {
+			return errors.New("'allowed_buckets' requires 'bucket_tag' to be set")
+		}
+		f, err := filter.Compile(i.AllowedBuckets)
+		if err != nil {
+			return fmt.Errorf("invalid 'allowed_buckets': %w", err)
+		}
+		i.allowedBuckets = f
+	}
+
+	if i.VerifyRate < 0 || i.VerifyRate > 1 {
+		return fmt.Errorf("'write_verification_rate' must be between 0 and 1, got %v", i.VerifyRate)
+	}
+	if i.VerifyRate > 0 && i.OmitTimestamp {
+		return errors.New("'write_verification_rate' cannot be used with 'influx_omit_timestamp'")
+	}
+
 	// Setup the limited serializer
 	i.serializer = &influx.Serializer{
 		UintSupport:   i.UintSupport,
@@ -150,6 +176,9 @@ func (i *InfluxDB) Connect() error {
 				bucket:           i.Bucket,
 				bucketTag:        i.BucketTag,
 				excludeBucketTag: i.ExcludeBucketTag,
+				allowedBuckets:   i.allowedBuckets,
+				verifyRate:       i.VerifyRate,
+				maxPacingDelay:   time.Duration(i.MaxPacingDelay),
 				timeout:          time.Duration(i.Timeout),
 				headers:          i.HTTPHeaders,
 				proxy:            proxy,
@@ -168,6 +197,7 @@ func (i *InfluxDB) Connect() error {
 			}
 
 			i.clients = append(i.clients, c)
+			i.breakers = append(i.breakers, breaker.New(i.Config, parts.Redacted(), map[string]string{"output": "influxdb_v2"}, i.Log))
 		default:
 			return fmt.Errorf("unsupported scheme [%q]: %q", u, parts.Scheme)
 		}
@@ -188,15 +218,33 @@ func (i *InfluxDB) Close() error {
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	ctx := context.Background()
 
+	var attempted bool
 	for _, n := range rand.Perm(len(i.clients)) {
 		client := i.clients[n]
+		if !i.breakers[n].Allow() {
+			continue
+		}
+		attempted = true
+
 		if err := client.Write(ctx, metrics); err != nil {
 			i.Log.Errorf("When writing to [%s]: %v", client.url, err)
+			// Only an unreachable or failing server counts against the
+			// circuit, throttled or rejected requests do not
+			var serr *serverError
+			if errors.As(err, &serr) {
+				i.breakers[n].Failure()
+			} else {
+				i.breakers[n].Cancel()
+			}
 			continue
 		}
+		i.breakers[n].Success()
 		return nil
 	}
 
+	if !attempted {
+		return errors.New("circuits of all configured server(s) are open")
+	}
 	return errors.New("failed to send metrics to any configured server(s)")
 }
 
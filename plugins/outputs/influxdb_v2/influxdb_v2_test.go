@@ -1,19 +1,23 @@
 package influxdb_v2_test
 
 import (
+	"encoding/json"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/breaker"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	influxdb "github.com/influxdata/telegraf/plugins/outputs/influxdb_v2"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -330,3 +334,279 @@ func TestTooLargeWriteRetry(t *testing.T) {
 	}
 	require.Error(t, plugin.Write(hugeMetrics))
 }
+
+func TestWriteCircuitBreaker(t *testing.T) {
+	// Setup a failing and a healthy server
+	var failingWrites, healthyWrites atomic.Int64
+	failing := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			failingWrites.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}),
+	)
+	defer failing.Close()
+	healthy := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			healthyWrites.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer healthy.Close()
+
+	// Setup plugin and connect
+	plugin := &influxdb.InfluxDB{
+		URLs:            []string{failing.URL, healthy.URL},
+		Bucket:          "telegraf",
+		ContentEncoding: "identity",
+		Config: breaker.Config{
+			CircuitFailureThreshold: 2,
+			CircuitOpenTimeout:      config.Duration(time.Hour),
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// Write until the failing server was picked twice as the order is random
+	for failingWrites.Load() < 2 {
+		require.NoError(t, plugin.Write(metrics))
+	}
+
+	// The failing server is skipped once its circuit is open
+	written := healthyWrites.Load()
+	for range 20 {
+		require.NoError(t, plugin.Write(metrics))
+	}
+	require.Equal(t, int64(2), failingWrites.Load())
+	require.Equal(t, written+20, healthyWrites.Load())
+}
+
+func TestWriteCircuitBreakerClientErrors(t *testing.T) {
+	// Rejected requests do not indicate an unhealthy server
+	var writes atomic.Int64
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if writes.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		}),
+	)
+	defer ts.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:            []string{ts.URL},
+		Bucket:          "telegraf",
+		ContentEncoding: "identity",
+		Config: breaker.Config{
+			CircuitFailureThreshold: 1,
+			CircuitOpenTimeout:      config.Duration(time.Hour),
+		},
+		Log: &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// The server is contacted again after the rate-limit as the circuit
+	// stays closed on the throttling and authorization errors
+	require.ErrorContains(t, plugin.Write(metrics), "failed to send metrics")
+	require.Eventually(t, func() bool {
+		err := plugin.Write(metrics)
+		return err != nil && writes.Load() == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	for range 3 {
+		require.ErrorContains(t, plugin.Write(metrics), "failed to send metrics")
+	}
+	require.Equal(t, int64(5), writes.Load())
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *influxdb.InfluxDB
+		expected string
+	}{
+		{
+			name:     "allowed buckets without bucket tag",
+			plugin:   &influxdb.InfluxDB{AllowedBuckets: []string{"telegraf"}},
+			expected: "'allowed_buckets' requires 'bucket_tag' to be set",
+		},
+		{
+			name:     "invalid verification rate",
+			plugin:   &influxdb.InfluxDB{VerifyRate: 1.5},
+			expected: "'write_verification_rate' must be between 0 and 1",
+		},
+		{
+			name:     "verification without timestamp",
+			plugin:   &influxdb.InfluxDB{VerifyRate: 0.1, OmitTimestamp: true},
+			expected: "'write_verification_rate' cannot be used with 'influx_omit_timestamp'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteAllowedBuckets(t *testing.T) {
+	var buckets []string
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			buckets = append(buckets, r.Form.Get("bucket"))
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:            []string{ts.URL},
+		Bucket:          "telegraf",
+		BucketTag:       "bucket",
+		AllowedBuckets:  []string{"app_*"},
+		ContentEncoding: "identity",
+		Log:             &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The not allowed bucket falls back to the default bucket
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"bucket": "app_shop"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"bucket": "_monitoring"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.ElementsMatch(t, []string{"app_shop", "telegraf"}, buckets)
+}
+
+func TestWriteVerification(t *testing.T) {
+	var query string
+	var found atomic.Bool
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v2/write":
+				w.WriteHeader(http.StatusNoContent)
+			case "/api/v2/query":
+				require.Equal(t, "influx", r.URL.Query().Get("org"))
+				var body struct {
+					Query string `json:"query"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				query = body.Query
+
+				w.Header().Set("Content-Type", "text/csv")
+				if found.Load() {
+					_, err := w.Write([]byte(",result,table,_time,_value\r\n,_result,0,1970-01-01T00:00:01Z,42\r\n\r\n"))
+					require.NoError(t, err)
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:            []string{ts.URL},
+		Organization:    "influx",
+		Bucket:          "telegraf",
+		VerifyRate:      1,
+		ContentEncoding: "identity",
+		Log:             &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": `a"b`}, map[string]interface{}{"value": 42.0}, time.Unix(1, 0)),
+	}
+
+	// Failed verifications are reported in the statistics
+	require.NoError(t, plugin.Write(metrics))
+	expected := `from(bucket: "telegraf") |> range(start: 1970-01-01T00:00:01Z, stop: 1970-01-01T00:00:01.000000001Z)` +
+		` |> filter(fn: (r) => r._measurement == "cpu" and r._field == "value" and r["host"] == "a\"b") |> limit(n: 1)`
+	require.Equal(t, expected, query)
+	require.Equal(t, int64(1), writeStat(t, ts.URL, "verification_failures"))
+
+	found.Store(true)
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, int64(2), writeStat(t, ts.URL, "verifications"))
+	require.Equal(t, int64(1), writeStat(t, ts.URL, "verification_failures"))
+	require.Equal(t, int64(2), writeStat(t, ts.URL, "writes"))
+	require.Equal(t, int64(2), writeStat(t, ts.URL, "metrics_written"))
+}
+
+func TestWriteRateLimitPacing(t *testing.T) {
+	var requests []time.Time
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests = append(requests, time.Now())
+			if len(requests) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:            []string{ts.URL},
+		Bucket:          "telegraf",
+		MaxPacingDelay:  config.Duration(time.Second),
+		ContentEncoding: "identity",
+		Log:             &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+	require.Error(t, plugin.Write(metrics))
+	require.Equal(t, int64(1), writeStat(t, ts.URL, "rate_limited"))
+	require.Equal(t, int64(100), writeStat(t, ts.URL, "pacing_delay_ms"))
+
+	// Wait for the retry time to elapse, the request is paced nevertheless
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, requests, 2)
+	require.GreaterOrEqual(t, requests[1].Sub(requests[0]), 100*time.Millisecond)
+	require.Equal(t, int64(0), writeStat(t, ts.URL, "pacing_delay_ms"))
+}
+
+// writeStat returns the value of the write statistic of the given URL
+func writeStat(t *testing.T, u, field string) int64 {
+	t.Helper()
+	for _, m := range selfstat.Metrics() {
+		if m.Name() != "internal_influxdb_v2" {
+			continue
+		}
+		if tag, _ := m.GetTag("url"); tag != u {
+			continue
+		}
+		if v, found := m.GetField(field); found {
+			return v.(int64)
+		}
+	}
+	require.Failf(t, "statistic not found", "field %q", field)
+	return 0
+}
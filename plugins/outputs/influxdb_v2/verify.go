@@ -0,0 +1,106 @@
+package influxdb_v2
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// verify queries the written metric from the bucket and logs a warning if
+// it cannot be found. Verification failures do not fail the write.
+func (c *httpClient) verify(ctx context.Context, bucket string, m telegraf.Metric) {
+	c.stats.verifications.Incr(1)
+	found, err := c.query(ctx, verificationQuery(bucket, m))
+	if err != nil {
+		c.stats.verificationFailures.Incr(1)
+		c.log.Warnf("Verifying write to %q failed: %v", bucket, err)
+		return
+	}
+	if !found {
+		c.stats.verificationFailures.Incr(1)
+		c.log.Warnf("Verifying write to %q failed: metric %q at %s not found", bucket, m.Name(), m.Time().UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// query executes the Flux query and returns true if the result contains at
+// least one record
+func (c *httpClient) query(ctx context.Context, flux string) (bool, error) {
+	body, err := json.Marshal(map[string]string{"query": flux, "type": "flux"})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", makeQueryURL(*c.queryURL, c.organization), bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/csv")
+	c.addHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("query returned status %q", resp.Status)
+	}
+
+	// The annotated CSV response contains a header line followed by the
+	// records, an empty result has no non-empty lines at all
+	var lines int
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			lines++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("reading response failed: %w", err)
+	}
+	return lines > 1, nil
+}
+
+// verificationQuery returns a Flux query selecting the first field of the
+// metric with the exact tags and timestamp
+func verificationQuery(bucket string, m telegraf.Metric) string {
+	conditions := []string{"r._measurement == " + fluxString(m.Name())}
+	if fields := m.FieldList(); len(fields) > 0 {
+		conditions = append(conditions, "r._field == "+fluxString(fields[0].Key))
+	}
+	for _, tag := range m.TagList() {
+		conditions = append(conditions, "r["+fluxString(tag.Key)+"] == "+fluxString(tag.Value))
+	}
+
+	ts := m.Time().UTC()
+	return fmt.Sprintf(
+		"from(bucket: %s) |> range(start: %s, stop: %s) |> filter(fn: (r) => %s) |> limit(n: 1)",
+		fluxString(bucket),
+		ts.Format(time.RFC3339Nano),
+		ts.Add(time.Nanosecond).Format(time.RFC3339Nano),
+		strings.Join(conditions, " and "),
+	)
+}
+
+// fluxString returns the string as Flux string literal
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func makeQueryURL(loc url.URL, org string) string {
+	params := loc.Query()
+	params.Set("org", org)
+	loc.RawQuery = params.Encode()
+	return loc.String()
+}
@@ -5,14 +5,19 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/Masterminds/sprig/v3"
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	telegraf_metric "github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -31,16 +36,20 @@ var ValidTopicSuffixMethods = []string{
 var zeroTime = time.Unix(0, 0)
 
 type Kafka struct {
-	Brokers           []string        `toml:"brokers"`
-	Topic             string          `toml:"topic"`
-	TopicTag          string          `toml:"topic_tag"`
-	ExcludeTopicTag   bool            `toml:"exclude_topic_tag"`
-	TopicSuffix       TopicSuffix     `toml:"topic_suffix"`
-	RoutingTag        string          `toml:"routing_tag"`
-	RoutingKey        string          `toml:"routing_key"`
-	ProducerTimestamp string          `toml:"producer_timestamp"`
-	MetricNameHeader  string          `toml:"metric_name_header"`
-	Log               telegraf.Logger `toml:"-"`
+	Brokers            []string          `toml:"brokers"`
+	Topic              string            `toml:"topic"`
+	TopicTag           string            `toml:"topic_tag"`
+	ExcludeTopicTag    bool              `toml:"exclude_topic_tag"`
+	TopicSuffix        TopicSuffix       `toml:"topic_suffix"`
+	RoutingTag         string            `toml:"routing_tag"`
+	RoutingKey         string            `toml:"routing_key"`
+	ProducerTimestamp  string            `toml:"producer_timestamp"`
+	MetricNameHeader   string            `toml:"metric_name_header"`
+	ProvenanceHeaders  bool              `toml:"provenance_headers"`
+	TopicTemplate      string            `toml:"topic_template"`
+	RoutingKeyTemplate string            `toml:"routing_key_template"`
+	TopicSerializers   []topicSerializer `toml:"topic_serializer"`
+	Log                telegraf.Logger   `toml:"-"`
 	proxy.Socks5ProxyConfig
 	kafka.WriteConfig
 
@@ -57,6 +66,8 @@ type Kafka struct {
 	producer     sarama.SyncProducer
 
 	serializer serializers.Serializer
+	topicTmpl  *template.Template
+	keyTmpl    *template.Template
 }
 
 type TopicSuffix struct {
@@ -79,6 +90,17 @@ func (*Kafka) SampleConfig() string {
 }
 
 func (k *Kafka) GetTopicName(metric telegraf.Metric) (telegraf.Metric, string) {
+	// The template takes precedence and falls back to the static settings
+	// if it results in an empty topic
+	if k.topicTmpl != nil {
+		topic, err := execute(k.topicTmpl, metric)
+		if err != nil {
+			k.Log.Debugf("Creating topic failed, using fallback: %v", err)
+		} else if topic = sanitizeTopic(topic); topic != "" {
+			return metric, topic
+		}
+	}
+
 	topic := k.Topic
 	if k.TopicTag != "" {
 		if t, ok := metric.GetTag(k.TopicTag); ok {
@@ -156,6 +178,27 @@ func (k *Kafka) Init() error {
 		return fmt.Errorf("unknown producer_timestamp option: %s", k.ProducerTimestamp)
 	}
 
+	if k.TopicTemplate != "" {
+		tmpl, err := template.New("topic").Funcs(sprig.TxtFuncMap()).Parse(k.TopicTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing 'topic_template' failed: %w", err)
+		}
+		k.topicTmpl = tmpl
+	}
+	if k.RoutingKeyTemplate != "" {
+		tmpl, err := template.New("routing key").Funcs(sprig.TxtFuncMap()).Parse(k.RoutingKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing 'routing_key_template' failed: %w", err)
+		}
+		k.keyTmpl = tmpl
+	}
+
+	for i := range k.TopicSerializers {
+		if err := k.TopicSerializers[i].init(); err != nil {
+			return fmt.Errorf("topic serializer %d: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
 
@@ -176,6 +219,15 @@ func (k *Kafka) Close() error {
 }
 
 func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
+	if k.keyTmpl != nil {
+		key, err := execute(k.keyTmpl, metric)
+		if err != nil {
+			k.Log.Debugf("Creating routing key failed, using fallback: %v", err)
+		} else if key != "" {
+			return key, nil
+		}
+	}
+
 	if k.RoutingTag != "" {
 		key, ok := metric.GetTag(k.RoutingTag)
 		if ok {
@@ -199,7 +251,7 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 	for _, metric := range metrics {
 		metric, topic := k.GetTopicName(metric)
 
-		buf, err := k.serializer.Serialize(metric)
+		buf, err := k.serializerFor(topic).Serialize(metric)
 		if err != nil {
 			k.Log.Debugf("Could not serialize metric: %v", err)
 			continue
@@ -219,6 +271,16 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
+		if k.ProvenanceHeaders {
+			fields := telegraf_metric.ProvenanceFields(metric, "provenance_")
+			for _, key := range slices.Sorted(maps.Keys(fields)) {
+				m.Headers = append(m.Headers, sarama.RecordHeader{
+					Key:   []byte(key),
+					Value: []byte(fields[key]),
+				})
+			}
+		}
+
 		// Negative timestamps are not allowed by the Kafka protocol.
 		if k.ProducerTimestamp == "metric" && !metric.Time().Before(zeroTime) {
 			m.Timestamp = metric.Time()
@@ -261,6 +323,47 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// serializerFor returns the serializer of the first matching topic override
+// or the default serializer
+func (k *Kafka) serializerFor(topic string) serializers.Serializer {
+	for _, ts := range k.TopicSerializers {
+		if ts.filter.Match(topic) {
+			return ts.serializer
+		}
+	}
+	return k.serializer
+}
+
+func execute(tmpl *template.Template, metric telegraf.Metric) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, metric); err != nil {
+		return "", fmt.Errorf("executing %s template failed: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// sanitizeTopic replaces characters not allowed in Kafka topic names and
+// truncates the name to the maximum length
+func sanitizeTopic(topic string) string {
+	const maxLength = 249
+
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, topic)
+	if len(sanitized) > maxLength {
+		sanitized = sanitized[:maxLength]
+	}
+	// The names "." and ".." are not allowed
+	if strings.Trim(sanitized, ".") == "" {
+		return ""
+	}
+	return sanitized
+}
+
 func init() {
 	outputs.Add("kafka", func() telegraf.Output {
 		return &Kafka{
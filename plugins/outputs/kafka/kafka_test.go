@@ -2,16 +2,19 @@ package kafka
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/require"
 	kafkacontainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	_ "github.com/influxdata/telegraf/plugins/serializers/json"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -312,3 +315,138 @@ func TestTopicTag(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplates(t *testing.T) {
+	plugin := &Kafka{
+		Brokers:            []string{"127.0.0.1"},
+		Topic:              "telegraf",
+		TopicTemplate:      `{{.Name}}.{{.Tag "region"}}`,
+		RoutingKeyTemplate: `{{.Tag "host"}}/{{.Field "device" | default "none"}}`,
+		RoutingKey:         "fallback",
+		producerFunc:       NewMockProducer,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+
+	require.NoError(t, plugin.Connect())
+	producer := &MockProducer{}
+	plugin.producer = producer
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"region": "eu west/1", "host": "a"},
+			map[string]interface{}{"device": "sda"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, producer.sent, 2)
+
+	// Invalid characters in the topic are replaced
+	require.Equal(t, "cpu.eu_west_1", producer.sent[0].Topic)
+	key, err := producer.sent[0].Key.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "a/sda", string(key))
+
+	// Missing tags and fields result in empty values
+	require.Equal(t, "cpu.", producer.sent[1].Topic)
+	key, err = producer.sent[1].Key.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "/none", string(key))
+}
+
+func TestTemplateFallback(t *testing.T) {
+	plugin := &Kafka{
+		Topic:              "telegraf",
+		TopicTemplate:      `{{.Tag "topic"}}`,
+		RoutingKeyTemplate: `{{.Tag "key"}}`,
+		RoutingTag:         "host",
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	_, topic := plugin.GetTopicName(m)
+	require.Equal(t, "telegraf", topic)
+	key, err := plugin.routingKey(m)
+	require.NoError(t, err)
+	require.Equal(t, "a", key)
+
+	m.AddTag("topic", "..")
+	_, topic = plugin.GetTopicName(m)
+	require.Equal(t, "telegraf", topic)
+
+	m.AddTag("topic", strings.Repeat("x", 300))
+	_, topic = plugin.GetTopicName(m)
+	require.Len(t, topic, 249)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	plugin := &Kafka{
+		TopicTemplate: `{{.Name`,
+		Log:           testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "parsing 'topic_template' failed")
+}
+
+func TestTopicSerializer(t *testing.T) {
+	cfg := `
+brokers = ["127.0.0.1"]
+topic_tag = "topic"
+topic = "telegraf"
+
+[[topic_serializer]]
+  topics = ["events.*"]
+  data_format = "json"
+  [topic_serializer.options]
+    json_timestamp_units = "1s"
+`
+	plugin := &Kafka{
+		producerFunc: NewMockProducer,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, toml.Unmarshal([]byte(cfg), plugin))
+	require.NoError(t, plugin.Init())
+
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+
+	require.NoError(t, plugin.Connect())
+	producer := &MockProducer{}
+	plugin.producer = producer
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+		testutil.MustMetric("login", map[string]string{"topic": "events.auth"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, producer.sent, 2)
+
+	encoded, err := producer.sent[0].Value.Encode()
+	require.NoError(t, err)
+	require.Equal(t, "cpu value=42 0\n", string(encoded))
+
+	encoded, err = producer.sent[1].Value.Encode()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"fields":{"value":1},"name":"login","tags":{"topic":"events.auth"},"timestamp":0}`, string(encoded))
+}
+
+func TestTopicSerializerInvalid(t *testing.T) {
+	plugin := &Kafka{
+		TopicSerializers: []topicSerializer{{Topics: []string{"events"}, DataFormat: "unknown"}},
+		Log:              testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "topic serializer 1: undefined but requested serializer: unknown")
+}
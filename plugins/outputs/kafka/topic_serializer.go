@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/toml"
+
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// topicSerializer overrides the serializer for the matching topics
+type topicSerializer struct {
+	Topics     []string               `toml:"topics"`
+	DataFormat string                 `toml:"data_format"`
+	Options    map[string]interface{} `toml:"options"`
+
+	filter     filter.Filter
+	serializer serializers.Serializer
+}
+
+func (t *topicSerializer) init() error {
+	if len(t.Topics) == 0 {
+		return errors.New("no topics given")
+	}
+	f, err := filter.Compile(t.Topics)
+	if err != nil {
+		return fmt.Errorf("creating topic filter failed: %w", err)
+	}
+	t.filter = f
+
+	creator, found := serializers.Serializers[t.DataFormat]
+	if !found {
+		return fmt.Errorf("undefined but requested serializer: %s", t.DataFormat)
+	}
+	serializer := creator()
+
+	// Apply the options by round-tripping through TOML to get the same
+	// decoding as for serializers configured in the plugin section
+	if len(t.Options) > 0 {
+		buf, err := toml.Marshal(t.Options)
+		if err != nil {
+			return fmt.Errorf("encoding options failed: %w", err)
+		}
+		if err := toml.Unmarshal(buf, serializer); err != nil {
+			return fmt.Errorf("applying options failed: %w", err)
+		}
+	}
+
+	running := models.NewRunningSerializer(serializer, &models.SerializerConfig{
+		Parent:     "kafka",
+		DataFormat: t.DataFormat,
+	})
+	if err := running.Init(); err != nil {
+		return fmt.Errorf("initializing serializer failed: %w", err)
+	}
+	t.serializer = running
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/common/kpl"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -20,8 +21,13 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
-// Limit set by AWS (https://docs.aws.amazon.com/kinesis/latest/APIReference/API_PutRecords.html)
-const maxRecordsPerRequest uint32 = 500
+// Limits set by AWS (https://docs.aws.amazon.com/kinesis/latest/APIReference/API_PutRecords.html)
+const (
+	maxRecordsPerRequest = 500
+	maxRequestSize       = 5 * 1024 * 1024
+	maxRecordSize        = 1024 * 1024
+	maxPartitionKeySize  = 256
+)
 
 type (
 	KinesisOutput struct {
@@ -29,6 +35,7 @@ type (
 		PartitionKey       string     `toml:"partitionkey" deprecated:"1.5.0;1.35.0;use 'partition.key' instead"`
 		RandomPartitionKey bool       `toml:"use_random_partitionkey" deprecated:"1.5.0;1.35.0;use 'partition.method' instead"`
 		Partition          *Partition `toml:"partition"`
+		Aggregation        bool       `toml:"aggregation"`
 		Debug              bool       `toml:"debug"`
 
 		Log        telegraf.Logger `toml:"-"`
@@ -36,6 +43,7 @@ type (
 		svc        kinesisClient
 
 		common_aws.CredentialConfig
+		common_aws.ClientConfig
 	}
 
 	Partition struct {
@@ -64,6 +72,12 @@ func (k *KinesisOutput) Connect() error {
 		k.Log.Infof("Establishing a connection to Kinesis in %s", k.Region)
 	}
 
+	httpClient, err := k.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	k.CredentialConfig.HTTPClient = httpClient
+
 	cfg, err := k.CredentialConfig.Credentials()
 	if err != nil {
 		return err
@@ -151,43 +165,120 @@ func (k *KinesisOutput) getPartitionKey(metric telegraf.Metric) string {
 }
 
 func (k *KinesisOutput) Write(metrics []telegraf.Metric) error {
-	var sz uint32
-
 	if len(metrics) == 0 {
 		return nil
 	}
 
-	r := make([]types.PutRecordsRequestEntry, 0, len(metrics))
+	if k.Aggregation {
+		k.writeAggregated(metrics)
+		return nil
+	}
+
+	var batch recordBatch
 	for _, metric := range metrics {
-		sz++
+		values, err := k.serializer.Serialize(metric)
+		if err != nil {
+			k.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+
+		k.add(&batch, values, k.getPartitionKey(metric))
+	}
+	k.flush(&batch)
+
+	return nil
+}
+
+// writeAggregated packs the serialized metrics into KPL aggregated records.
+// Metrics are aggregated per partition key to keep them on the same shard,
+// except for random partition keys where all metrics share one record.
+func (k *KinesisOutput) writeAggregated(metrics []telegraf.Metric) {
+	random := k.RandomPartitionKey
+	if k.Partition != nil {
+		random = k.Partition.Method == "random"
+	}
 
+	var batch recordBatch
+	var keys []string
+	aggregators := make(map[string]*kpl.Aggregator)
+	for _, metric := range metrics {
 		values, err := k.serializer.Serialize(metric)
 		if err != nil {
 			k.Log.Debugf("Could not serialize metric: %v", err)
 			continue
 		}
 
-		partitionKey := k.getPartitionKey(metric)
+		key := k.getPartitionKey(metric)
+		group := key
+		if random {
+			group = ""
+		}
 
-		d := types.PutRecordsRequestEntry{
-			Data:         values,
-			PartitionKey: aws.String(partitionKey),
+		agg, found := aggregators[group]
+		if !found {
+			agg = kpl.NewAggregator(maxRecordSize - maxPartitionKeySize)
+			aggregators[group] = agg
+			keys = append(keys, group)
+		}
+		if random && agg.Len() > 0 {
+			key = agg.PartitionKey()
 		}
 
-		r = append(r, d)
-		if sz == maxRecordsPerRequest {
-			elapsed := k.writeKinesis(r)
-			k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
-			sz = 0
-			r = nil
+		if agg.Add(key, values) {
+			continue
 		}
+
+		// Start a new record if the current one is full
+		if agg.Len() > 0 {
+			k.add(&batch, agg.Bytes(), agg.PartitionKey())
+			agg.Reset()
+			if random {
+				key = k.getPartitionKey(metric)
+			}
+			if agg.Add(key, values) {
+				continue
+			}
+		}
+		k.Log.Errorf("Dropping metric %q exceeding the maximum record size", metric.Name())
 	}
-	if sz > 0 {
-		elapsed := k.writeKinesis(r)
-		k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
+
+	for _, group := range keys {
+		if agg := aggregators[group]; agg.Len() > 0 {
+			k.add(&batch, agg.Bytes(), agg.PartitionKey())
+		}
 	}
+	k.flush(&batch)
+}
 
-	return nil
+// recordBatch collects the records of a single PutRecords request
+type recordBatch struct {
+	records []types.PutRecordsRequestEntry
+	size    int
+}
+
+// add appends the record to the batch, writing the batch before if the record
+// would exceed the request limits
+func (k *KinesisOutput) add(batch *recordBatch, data []byte, partitionKey string) {
+	size := len(data) + len(partitionKey)
+	if len(batch.records) == maxRecordsPerRequest || batch.size+size > maxRequestSize {
+		k.flush(batch)
+	}
+
+	batch.records = append(batch.records, types.PutRecordsRequestEntry{
+		Data:         data,
+		PartitionKey: aws.String(partitionKey),
+	})
+	batch.size += size
+}
+
+func (k *KinesisOutput) flush(batch *recordBatch) {
+	if len(batch.records) == 0 {
+		return
+	}
+	elapsed := k.writeKinesis(batch.records)
+	k.Log.Debugf("Wrote %d record(s) to Kinesis in %s", len(batch.records), elapsed)
+	batch.records = nil
+	batch.size = 0
 }
 
 func init() {
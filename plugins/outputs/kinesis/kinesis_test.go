@@ -3,7 +3,9 @@ package kinesis
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
@@ -12,6 +14,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/kpl"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -456,6 +460,100 @@ func TestWrite_SerializerError(t *testing.T) {
 	})
 }
 
+func TestWrite_Aggregation(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(2, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "tag",
+			Key:    "host",
+		},
+		Aggregation: true,
+		StreamName:  testStreamName,
+		serializer:  serializer,
+		svc:         svc,
+	}
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	require.NoError(t, k.Write(metrics))
+
+	// Metrics are aggregated per partition key
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 2)
+
+	require.Equal(t, "a", aws.ToString(records[0].PartitionKey))
+	require.True(t, kpl.IsAggregated(records[0].Data))
+	userRecords, err := kpl.Deaggregate(records[0].Data)
+	require.NoError(t, err)
+	require.Equal(t, []kpl.Record{
+		{PartitionKey: "a", Data: []byte("cpu,host=a value=1i 0\n")},
+		{PartitionKey: "a", Data: []byte("mem,host=a value=3i 0\n")},
+	}, userRecords)
+
+	require.Equal(t, "b", aws.ToString(records[1].PartitionKey))
+	userRecords, err = kpl.Deaggregate(records[1].Data)
+	require.NoError(t, err)
+	require.Equal(t, []kpl.Record{
+		{PartitionKey: "b", Data: []byte("cpu,host=b value=2i 0\n")},
+	}, userRecords)
+}
+
+func TestWrite_AggregationRecordSize(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(2, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "random",
+		},
+		Aggregation: true,
+		StreamName:  testStreamName,
+		serializer:  serializer,
+		svc:         svc,
+	}
+
+	// Two metrics fit into a record, the third starts a new record
+	metrics := make([]telegraf.Metric, 0, 3)
+	for i := range 3 {
+		metrics = append(metrics, metric.New(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"message": strings.Repeat("x", maxRecordSize/3)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	require.NoError(t, k.Write(metrics))
+
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 2)
+	for i, expected := range []int{2, 1} {
+		require.LessOrEqual(t, len(records[i].Data)+len(aws.ToString(records[i].PartitionKey)), maxRecordSize)
+		userRecords, err := kpl.Deaggregate(records[i].Data)
+		require.NoError(t, err)
+		require.Len(t, userRecords, expected)
+
+		// All user records share the random partition key of the record
+		for _, r := range userRecords {
+			require.Equal(t, aws.ToString(records[i].PartitionKey), r.PartitionKey)
+		}
+	}
+}
+
 type mockKinesisPutRecordsResponse struct {
 	Output *kinesis.PutRecordsOutput
 	Err    error
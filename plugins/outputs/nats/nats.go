@@ -2,11 +2,15 @@
 package nats
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -31,6 +35,7 @@ type NATS struct {
 	Password    config.Secret `toml:"password"`
 	Credentials string        `toml:"credentials"`
 	Subject     string        `toml:"subject"`
+	HeaderTags  []string      `toml:"header_tags"`
 	Jetstream   *StreamConfig `toml:"jetstream"`
 	tls.ClientConfig
 
@@ -40,6 +45,7 @@ type NATS struct {
 	jetstreamClient       jetstream.JetStream
 	jetstreamStreamConfig *jetstream.StreamConfig
 	serializer            serializers.Serializer
+	subjectTemplate       *template.Template
 }
 
 // StreamConfig is the configuration for creating stream
@@ -77,6 +83,11 @@ type StreamConfig struct {
 	MirrorDirect         bool                              `toml:"mirror_direct"`
 	ConsumerLimits       jetstream.StreamConsumerLimits    `toml:"consumer_limits"`
 	Metadata             map[string]string                 `toml:"metadata"`
+
+	// Publishing options not being part of the stream configuration
+	MessageID      string          `toml:"message_id"`
+	MaxPendingAcks int             `toml:"max_pending_acks"`
+	AckTimeout     config.Duration `toml:"ack_timeout"`
 }
 
 func (*NATS) SampleConfig() string {
@@ -134,7 +145,7 @@ func (n *NATS) Connect() error {
 	}
 
 	if n.Jetstream != nil {
-		n.jetstreamClient, err = jetstream.New(n.conn)
+		n.jetstreamClient, err = jetstream.New(n.conn, jetstream.WithPublishAsyncMaxPending(n.Jetstream.MaxPendingAcks))
 		if err != nil {
 			return fmt.Errorf("failed to connect to jetstream: %w", err)
 		}
@@ -228,19 +239,50 @@ func (n *NATS) getJetstreamConfig() (*jetstream.StreamConfig, error) {
 }
 
 func (n *NATS) Init() error {
+	// Subjects containing template actions are created per metric
+	if strings.Contains(n.Subject, "{{") {
+		tmpl, err := template.New("subject").Parse(n.Subject)
+		if err != nil {
+			return fmt.Errorf("parsing subject template failed: %w", err)
+		}
+		n.subjectTemplate = tmpl
+	}
+
 	if n.Jetstream != nil {
 		if strings.TrimSpace(n.Jetstream.Name) == "" {
 			return errors.New("stream cannot be empty")
 		}
 
-		if len(n.Jetstream.Subjects) == 0 {
-			n.Jetstream.Subjects = []string{n.Subject}
+		if n.subjectTemplate != nil {
+			// The stream must capture all subjects created by the template
+			if len(n.Jetstream.Subjects) == 0 {
+				return errors.New("jetstream subjects required when using a subject template")
+			}
+		} else {
+			if len(n.Jetstream.Subjects) == 0 {
+				n.Jetstream.Subjects = []string{n.Subject}
+			}
+			// If the overall-subject is already present anywhere in the Jetstream subject we go from there,
+			// otherwise we should append the overall-subject as the last element.
+			if !choice.Contains(n.Subject, n.Jetstream.Subjects) {
+				n.Jetstream.Subjects = append(n.Jetstream.Subjects, n.Subject)
+			}
 		}
-		// If the overall-subject is already present anywhere in the Jetstream subject we go from there,
-		// otherwise we should append the overall-subject as the last element.
-		if !choice.Contains(n.Subject, n.Jetstream.Subjects) {
-			n.Jetstream.Subjects = append(n.Jetstream.Subjects, n.Subject)
+
+		switch n.Jetstream.MessageID {
+		case "":
+			n.Jetstream.MessageID = "none"
+		case "none", "hash":
+		default:
+			return fmt.Errorf("invalid 'message_id' setting %q", n.Jetstream.MessageID)
 		}
+		if n.Jetstream.MaxPendingAcks <= 0 {
+			n.Jetstream.MaxPendingAcks = 256
+		}
+		if n.Jetstream.AckTimeout <= 0 {
+			n.Jetstream.AckTimeout = config.Duration(5 * time.Second)
+		}
+
 		var err error
 		n.jetstreamStreamConfig, err = n.getJetstreamConfig()
 		if err != nil {
@@ -259,21 +301,125 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
+
+	var futures []jetstream.PubAckFuture
 	for _, metric := range metrics {
-		buf, err := n.serializer.Serialize(metric)
+		msg, err := n.createMessage(metric)
 		if err != nil {
-			n.Log.Debugf("Could not serialize metric: %v", err)
+			n.Log.Debugf("Could not create message: %v", err)
+			continue
+		}
+
+		if n.jetstreamClient == nil {
+			if err := n.conn.PublishMsg(msg); err != nil {
+				return fmt.Errorf("failed to send NATS message: %w", err)
+			}
 			continue
 		}
-		// use the same Publish API for nats core and jetstream
-		err = n.conn.Publish(n.Subject, buf)
+
+		// Publish asynchronously, waiting for acknowledgements once the
+		// number of pending messages is reached
+		opts := []jetstream.PublishOpt{jetstream.WithStallWait(time.Duration(n.Jetstream.AckTimeout))}
+		if n.Jetstream.MessageID == "hash" {
+			opts = append(opts, jetstream.WithMsgID(messageID(metric, msg)))
+		}
+		future, err := n.jetstreamClient.PublishMsgAsync(msg, opts...)
 		if err != nil {
-			return fmt.Errorf("failed to send NATS message: %w", err)
+			return fmt.Errorf("failed to publish JetStream message: %w", err)
+		}
+		futures = append(futures, future)
+	}
+
+	return n.waitForAcks(futures)
+}
+
+// waitForAcks waits for the acknowledgements of all published messages and
+// fails if any message was not acknowledged. The whole batch is published
+// again in this case, so messages are duplicated unless using message IDs.
+func (n *NATS) waitForAcks(futures []jetstream.PubAckFuture) error {
+	if len(futures) == 0 {
+		return nil
+	}
+
+	timeout := time.NewTimer(time.Duration(n.Jetstream.AckTimeout))
+	defer timeout.Stop()
+
+	var failed int
+	var firstErr error
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+			continue
+		case err := <-future.Err():
+			if firstErr == nil {
+				firstErr = err
+			}
+		case <-timeout.C:
+			return fmt.Errorf("timeout waiting for acknowledgement of %d message(s)", len(futures))
 		}
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d message(s) not acknowledged: %w", failed, len(futures), firstErr)
 	}
 	return nil
 }
 
+func (n *NATS) createMessage(metric telegraf.Metric) (*nats.Msg, error) {
+	buf, err := n.serializer.Serialize(metric)
+	if err != nil {
+		return nil, fmt.Errorf("serialization failed: %w", err)
+	}
+
+	subject := n.Subject
+	if n.subjectTemplate != nil {
+		var b bytes.Buffer
+		if err := n.subjectTemplate.Execute(&b, metric); err != nil {
+			return nil, fmt.Errorf("creating subject failed: %w", err)
+		}
+		subject = b.String()
+		if !validSubject(subject) {
+			return nil, fmt.Errorf("invalid subject %q", subject)
+		}
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = buf
+	for _, key := range n.HeaderTags {
+		if value, found := metric.GetTag(key); found {
+			msg.Header.Set(key, value)
+		}
+	}
+	return msg, nil
+}
+
+// validSubject checks for empty tokens, e.g. due to missing tags, and
+// whitespace not allowed in subjects
+func validSubject(subject string) bool {
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return false
+	}
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// messageID returns the ID used by JetStream to detect duplicate messages.
+// The ID consists of the hash of the series, the timestamp and a hash of the
+// subject and message content, so resending a metric results in the same ID.
+func messageID(metric telegraf.Metric, msg *nats.Msg) string {
+	h := fnv.New64a()
+	h.Write([]byte(msg.Subject))
+	h.Write([]byte{0})
+	h.Write(msg.Data)
+	return strconv.FormatUint(metric.HashID(), 16) + "-" +
+		strconv.FormatInt(metric.Time().UnixNano(), 10) + "-" +
+		strconv.FormatUint(h.Sum64(), 16)
+}
+
 func init() {
 	outputs.Add("nats", func() telegraf.Output {
 		return &NATS{}
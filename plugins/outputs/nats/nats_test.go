@@ -9,12 +9,15 @@ import (
 	"time"
 
 	"github.com/docker/go-connections/nat"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -183,3 +186,104 @@ func TestConfigParsing(t *testing.T) {
 		})
 	}
 }
+
+func startServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+	return srv
+}
+
+func TestJetstreamPublish(t *testing.T) {
+	srv := startServer(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin := &NATS{
+		Servers:    []string{srv.ClientURL()},
+		Subject:    `telegraf.{{.Tag "host"}}.{{.Name}}`,
+		HeaderTags: []string{"host"},
+		Jetstream: &StreamConfig{
+			Name:      "metrics",
+			Subjects:  []string{"telegraf.>"},
+			MessageID: "hash",
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		// Metrics without a valid subject are dropped
+		metric.New("disk", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Writing the batch again does not duplicate the messages
+	require.NoError(t, plugin.Write(metrics))
+
+	js, err := jetstream.New(plugin.conn)
+	require.NoError(t, err)
+	stream, err := js.Stream(context.Background(), "metrics")
+	require.NoError(t, err)
+	info, err := stream.Info(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), info.State.Msgs)
+
+	msg, err := stream.GetMsg(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, "telegraf.a.cpu", msg.Subject)
+	require.Equal(t, "a", msg.Header.Get("host"))
+	require.NotEmpty(t, msg.Header.Get(nats.MsgIdHdr))
+	require.Equal(t, "cpu,host=a value=1 0\n", string(msg.Data))
+}
+
+func TestJetstreamNotAcknowledged(t *testing.T) {
+	srv := startServer(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin := &NATS{
+		Servers: []string{srv.ClientURL()},
+		Subject: "telegraf",
+		Jetstream: &StreamConfig{
+			Name:    "metrics",
+			MaxMsgs: 1,
+			Discard: "new",
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The stream only accepts a single message
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	metrics := append(testutil.MockMetrics(), testutil.MockMetrics()...)
+	require.ErrorContains(t, plugin.Write(metrics), "2 of 2 message(s) not acknowledged")
+}
+
+func TestSubjectTemplateInvalid(t *testing.T) {
+	plugin := &NATS{
+		Subject:   `telegraf.{{.Name}}`,
+		Jetstream: &StreamConfig{Name: "metrics"},
+	}
+	require.ErrorContains(t, plugin.Init(), "jetstream subjects required when using a subject template")
+
+	plugin = &NATS{Subject: `telegraf.{{.Name`}
+	require.ErrorContains(t, plugin.Init(), "parsing subject template failed")
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -20,6 +21,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/otlp"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -150,8 +152,21 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
-	batch := o.metricsConverter.NewBatch()
+	// Use the original data points of metrics received via OTLP where
+	// possible and convert the remaining metrics
+	natives := otlp.NewBatch()
+	converted := make([]telegraf.Metric, 0, len(metrics))
 	for _, metric := range metrics {
+		if !natives.Add(metric) {
+			converted = append(converted, metric)
+		}
+	}
+
+	nativeMetrics := pmetric.NewMetrics()
+	converted = append(converted, natives.Build(nativeMetrics)...)
+
+	batch := o.metricsConverter.NewBatch()
+	for _, metric := range converted {
 		var vType common.InfluxMetricValueType
 		switch metric.Type() {
 		case telegraf.Gauge:
@@ -175,7 +190,9 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 		}
 	}
 
-	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
+	ms := batch.GetMetrics()
+	nativeMetrics.ResourceMetrics().MoveAndAppendTo(ms.ResourceMetrics())
+	md := pmetricotlp.NewExportRequestFromMetrics(ms)
 	if md.Metrics().ResourceMetrics().Len() == 0 {
 		return nil
 	}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/otlp"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -81,6 +82,91 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func TestOpenTelemetryNative(t *testing.T) {
+	ts := pcommon.NewTimestampFromTime(time.Unix(0, 1622848686000000000))
+	received := pmetric.NewMetrics()
+	{
+		rm := received.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", "potato")
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		ilm.Scope().SetName("My Library Name")
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("requests")
+		m.SetUnit("1")
+		sum := m.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		sum.SetIsMonotonic(true)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.Attributes().PutInt("code", 200)
+		dp.SetTimestamp(ts)
+		dp.SetIntValue(5)
+	}
+
+	// Mimic the conversion of the input plugin in native mode
+	natives := otlp.Split(received)
+	require.Len(t, natives, 1)
+	native := testutil.MustMetric(
+		"requests",
+		map[string]string{
+			"code":              "200",
+			"otel.library.name": "My Library Name",
+			"host.name":         "potato",
+		},
+		map[string]interface{}{"gauge": int64(5)},
+		time.Unix(0, 1622848686000000000),
+		telegraf.Gauge,
+	)
+	natives[0].Attach([]telegraf.Metric{native})
+	native.AddTag("region", "eu")
+
+	legacy := testutil.MustMetric(
+		"cpu_temp",
+		map[string]string{"host.name": "potato"},
+		map[string]interface{}{"gauge": 87.332},
+		time.Unix(0, 1622848686000000000),
+		telegraf.Gauge,
+	)
+
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress:       m.Address(),
+		Timeout:              config.Duration(time.Second),
+		Headers:              map[string]string{"test": "header1"},
+		metricsConverter:     metricsConverter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Write([]telegraf.Metric{native, legacy}))
+
+	// The native data point must be sent as received, only the tag added in
+	// the pipeline is added as attribute
+	expect := pmetric.NewMetrics()
+	{
+		rm := expect.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("host.name", "potato")
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("cpu_temp")
+		dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(ts)
+		dp.SetDoubleValue(87.332)
+	}
+	received.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0).Attributes().PutStr("region", "eu")
+	received.ResourceMetrics().MoveAndAppendTo(expect.ResourceMetrics())
+
+	marshaller := pmetric.JSONMarshaler{}
+	expectJSON, err := marshaller.MarshalMetrics(expect)
+	require.NoError(t, err)
+	gotJSON, err := marshaller.MarshalMetrics(m.GotMetrics())
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+}
+
 var _ pmetricotlp.GRPCServer = (*mockOtelService)(nil)
 
 type mockOtelService struct {
@@ -0,0 +1,444 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package s3
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits set by AWS, see
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/qfacts.html
+const (
+	minPartSize = 5 * 1024 * 1024
+	maxParts    = 10000
+)
+
+const defaultKey = `telegraf/{{.Time.Format "2006/01/02/15"}}/{{.Hostname}}-{{.Time.Format "20060102T150405Z"}}-{{.Sequence}}`
+
+type S3 struct {
+	Bucket               string          `toml:"bucket"`
+	Key                  string          `toml:"key"`
+	ForcePathStyle       bool            `toml:"force_path_style"`
+	MaxObjectSize        config.Size     `toml:"max_object_size"`
+	MaxObjectAge         config.Duration `toml:"max_object_age"`
+	UseBatchFormat       bool            `toml:"use_batch_format"`
+	ContentEncoding      string          `toml:"content_encoding"`
+	MultipartPartSize    config.Size     `toml:"multipart_part_size"`
+	ServerSideEncryption string          `toml:"server_side_encryption"`
+	SSEKMSKeyID          string          `toml:"sse_kms_key_id"`
+	Log                  telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client         s3Client
+	serializer     telegraf.Serializer
+	encoder        internal.ContentEncoder
+	encoding       *string
+	sse            types.ServerSideEncryption
+	sseKeyID       *string
+	key            *template.Template
+	perMeasurement bool
+	hostname       string
+	sequence       uint64
+	objects        map[string]*object
+}
+
+// s3Client contains the S3 API used, implemented by s3.Client
+type s3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// object buffers the data of an object until it is uploaded. In batch format
+// the metrics are kept to serialize them as a whole on upload and the size
+// is estimated from the size of the individually serialized batches.
+type object struct {
+	measurement string
+	created     time.Time
+	data        []byte
+	metrics     []telegraf.Metric
+	size        int64
+}
+
+// mark records the state of an object to roll back additions
+type mark struct {
+	data    int
+	metrics int
+	size    int64
+}
+
+// keyInfo contains the data available in the key template
+type keyInfo struct {
+	Time        time.Time
+	Hostname    string
+	Measurement string
+	Sequence    uint64
+}
+
+func (*S3) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *S3) Init() error {
+	if s.Bucket == "" {
+		return errors.New("'bucket' is required")
+	}
+
+	if s.Key == "" {
+		s.Key = defaultKey
+	}
+	key, err := template.New("key").Parse(s.Key)
+	if err != nil {
+		return fmt.Errorf("parsing 'key' template failed: %w", err)
+	}
+	s.key = key
+	s.perMeasurement = strings.Contains(s.Key, ".Measurement")
+
+	if s.MaxObjectSize <= 0 {
+		s.MaxObjectSize = config.Size(64 * 1024 * 1024)
+	}
+	if s.MaxObjectAge <= 0 {
+		s.MaxObjectAge = config.Duration(5 * time.Minute)
+	}
+
+	if s.MultipartPartSize == 0 {
+		s.MultipartPartSize = config.Size(16 * 1024 * 1024)
+	}
+	if s.MultipartPartSize < minPartSize {
+		return fmt.Errorf("'multipart_part_size' must be at least %d bytes", minPartSize)
+	}
+
+	switch s.ContentEncoding {
+	case "", "identity":
+		s.ContentEncoding = "identity"
+	case "gzip", "zstd":
+		s.encoding = aws.String(s.ContentEncoding)
+	default:
+		return fmt.Errorf("invalid 'content_encoding' %q", s.ContentEncoding)
+	}
+	if s.encoder, err = internal.NewContentEncoder(s.ContentEncoding); err != nil {
+		return fmt.Errorf("creating encoder failed: %w", err)
+	}
+
+	switch s.ServerSideEncryption {
+	case "":
+		if s.SSEKMSKeyID != "" {
+			return errors.New("'sse_kms_key_id' requires 'server_side_encryption' to be set to \"aws:kms\"")
+		}
+	case "AES256":
+		s.sse = types.ServerSideEncryptionAes256
+		if s.SSEKMSKeyID != "" {
+			return errors.New("'sse_kms_key_id' requires 'server_side_encryption' to be set to \"aws:kms\"")
+		}
+	case "aws:kms":
+		s.sse = types.ServerSideEncryptionAwsKms
+		if s.SSEKMSKeyID != "" {
+			s.sseKeyID = aws.String(s.SSEKMSKeyID)
+		}
+	default:
+		return fmt.Errorf("invalid 'server_side_encryption' %q", s.ServerSideEncryption)
+	}
+
+	if s.hostname, err = os.Hostname(); err != nil {
+		return fmt.Errorf("getting hostname failed: %w", err)
+	}
+	s.objects = make(map[string]*object)
+
+	return nil
+}
+
+func (s *S3) SetSerializer(serializer telegraf.Serializer) {
+	s.serializer = serializer
+}
+
+func (s *S3) Connect() error {
+	if s.client != nil {
+		return nil
+	}
+
+	httpClient, err := s.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	s.CredentialConfig.HTTPClient = httpClient
+
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return err
+	}
+	s.client = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if s.EndpointURL != "" {
+			o.BaseEndpoint = &s.EndpointURL
+		}
+		o.UsePathStyle = s.ForcePathStyle
+	})
+
+	return nil
+}
+
+// Close uploads all buffered objects
+func (s *S3) Close() error {
+	var errs []error
+	for name, obj := range s.objects {
+		if err := s.upload(obj); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(s.objects, name)
+	}
+	return errors.Join(errs...)
+}
+
+// Write adds the metrics to the buffered objects and uploads the objects
+// exceeding the size or age threshold. If an upload fails, the metrics of
+// this write are removed from the object again and an error is returned so
+// the metrics are retried later. Objects not receiving metrics in this write
+// are kept for the next write on failure.
+func (s *S3) Write(metrics []telegraf.Metric) error {
+	groups := make(map[string][]telegraf.Metric)
+	if s.perMeasurement {
+		for _, m := range metrics {
+			groups[m.Name()] = append(groups[m.Name()], m)
+		}
+	} else {
+		groups[""] = metrics
+	}
+
+	now := time.Now()
+	var errs []error
+	for name, group := range groups {
+		data, err := s.serialize(group)
+		if err != nil {
+			s.Log.Errorf("Dropping %d metric(s): %v", len(group), err)
+			continue
+		}
+
+		obj, found := s.objects[name]
+		if !found {
+			obj = &object{measurement: name, created: now}
+			s.objects[name] = obj
+		}
+		previous := obj.mark()
+		if s.UseBatchFormat {
+			obj.metrics = append(obj.metrics, group...)
+		} else {
+			obj.data = append(obj.data, data...)
+		}
+		obj.size += int64(len(data))
+		if !s.due(obj, now) {
+			continue
+		}
+
+		if err := s.upload(obj); err != nil {
+			obj.rollback(previous)
+			if obj.size == 0 {
+				delete(s.objects, name)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		delete(s.objects, name)
+	}
+
+	// Upload objects exceeding the age without receiving metrics
+	for name, obj := range s.objects {
+		if _, found := groups[name]; found || !s.due(obj, now) {
+			continue
+		}
+		if err := s.upload(obj); err != nil {
+			s.Log.Errorf("Uploading object failed, retrying on next write: %v", err)
+			continue
+		}
+		delete(s.objects, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *S3) serialize(metrics []telegraf.Metric) ([]byte, error) {
+	if s.UseBatchFormat {
+		data, err := s.serializer.SerializeBatch(metrics)
+		if err != nil {
+			return nil, fmt.Errorf("serialization failed: %w", err)
+		}
+		return data, nil
+	}
+
+	var buf []byte
+	for _, m := range metrics {
+		data, err := s.serializer.Serialize(m)
+		if err != nil {
+			s.Log.Errorf("Dropping metric %q: serialization failed: %v", m.Name(), err)
+			continue
+		}
+		buf = append(buf, data...)
+	}
+	return buf, nil
+}
+
+func (s *S3) due(obj *object, now time.Time) bool {
+	return obj.size >= int64(s.MaxObjectSize) || now.Sub(obj.created) >= time.Duration(s.MaxObjectAge)
+}
+
+func (s *S3) upload(obj *object) error {
+	if obj.size == 0 {
+		return nil
+	}
+
+	data := obj.data
+	if s.UseBatchFormat {
+		var err error
+		if data, err = s.serializer.SerializeBatch(obj.metrics); err != nil {
+			return fmt.Errorf("serialization failed: %w", err)
+		}
+	}
+	data, err := s.encoder.Encode(data)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+
+	s.sequence++
+	var buf bytes.Buffer
+	info := keyInfo{
+		Time:        time.Now().UTC(),
+		Hostname:    s.hostname,
+		Measurement: obj.measurement,
+		Sequence:    s.sequence,
+	}
+	if err := s.key.Execute(&buf, info); err != nil {
+		return fmt.Errorf("executing key template failed: %w", err)
+	}
+	if buf.Len() == 0 {
+		return errors.New("key template resulted in empty key")
+	}
+	key := buf.String()
+
+	if int64(len(data)) > int64(s.MultipartPartSize) {
+		err = s.putMultipart(key, data)
+	} else {
+		err = s.put(key, data)
+	}
+	if err != nil {
+		return fmt.Errorf("uploading object %q failed: %w", key, err)
+	}
+	s.Log.Debugf("Uploaded object %q with %d bytes", key, len(data))
+	return nil
+}
+
+func (s *S3) put(key string, data []byte) error {
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:               aws.String(s.Bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(data),
+		ContentLength:        aws.Int64(int64(len(data))),
+		ContentEncoding:      s.encoding,
+		ServerSideEncryption: s.sse,
+		SSEKMSKeyId:          s.sseKeyID,
+	})
+	return err
+}
+
+func (s *S3) putMultipart(key string, data []byte) error {
+	ctx := context.Background()
+
+	// Increase the part size if the object would exceed the part limit
+	partSize := int64(s.MultipartPartSize)
+	if n := (int64(len(data)) + partSize - 1) / partSize; n > maxParts {
+		partSize = (int64(len(data)) + maxParts - 1) / maxParts
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(s.Bucket),
+		Key:                  aws.String(key),
+		ContentEncoding:      s.encoding,
+		ServerSideEncryption: s.sse,
+		SSEKMSKeyId:          s.sseKeyID,
+	})
+	if err != nil {
+		return fmt.Errorf("creating multipart upload failed: %w", err)
+	}
+
+	parts := make([]types.CompletedPart, 0, (int64(len(data))+partSize-1)/partSize)
+	for offset := int64(0); offset < int64(len(data)); offset += partSize {
+		end := min(offset+partSize, int64(len(data)))
+		number := aws.Int32(int32(len(parts) + 1))
+		out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(s.Bucket),
+			Key:           aws.String(key),
+			UploadId:      created.UploadId,
+			PartNumber:    number,
+			Body:          bytes.NewReader(data[offset:end]),
+			ContentLength: aws.Int64(end - offset),
+		})
+		if err != nil {
+			s.abort(key, created.UploadId)
+			return fmt.Errorf("uploading part %d failed: %w", *number, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: out.ETag, PartNumber: number})
+	}
+
+	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(key),
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		s.abort(key, created.UploadId)
+		return fmt.Errorf("completing multipart upload failed: %w", err)
+	}
+	return nil
+}
+
+func (s *S3) abort(key string, uploadID *string) {
+	_, err := s.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.Bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		s.Log.Errorf("Aborting multipart upload of %q failed: %v", key, err)
+	}
+}
+
+func (o *object) mark() mark {
+	return mark{data: len(o.data), metrics: len(o.metrics), size: o.size}
+}
+
+func (o *object) rollback(m mark) {
+	o.data = o.data[:m.data]
+	o.metrics = o.metrics[:m.metrics]
+	o.size = m.size
+}
+
+func init() {
+	outputs.Add("s3", func() telegraf.Output {
+		return &S3{
+			ContentEncoding: "identity",
+		}
+	})
+}
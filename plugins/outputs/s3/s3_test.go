@@ -0,0 +1,388 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v18/parquet/file"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/parquet"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type upload struct {
+	input *s3.PutObjectInput
+	body  []byte
+}
+
+type mockClient struct {
+	fail      bool
+	uploads   []upload
+	multipart *s3.CreateMultipartUploadInput
+	parts     [][]byte
+	completed *s3.CompleteMultipartUploadInput
+	aborted   bool
+}
+
+func (c *mockClient) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if c.fail {
+		return nil, errors.New("service unavailable")
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.uploads = append(c.uploads, upload{input: params, body: body})
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *mockClient) CreateMultipartUpload(_ context.Context, params *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	c.multipart = params
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+
+func (c *mockClient) UploadPart(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if c.fail {
+		return nil, errors.New("service unavailable")
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.parts = append(c.parts, body)
+	return &s3.UploadPartOutput{ETag: aws.String("etag-" + strconv.Itoa(int(*params.PartNumber)))}, nil
+}
+
+func (c *mockClient) CompleteMultipartUpload(_ context.Context, params *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	c.completed = params
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (c *mockClient) AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	c.aborted = true
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func testMetrics(name string, n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			name,
+			map[string]string{"host": "host-" + strconv.Itoa(i%2)},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	return metrics
+}
+
+func TestWriteSizeThreshold(t *testing.T) {
+	plugin := &S3{
+		Bucket:        "telegraf",
+		Key:           "metrics/{{.Hostname}}-{{.Sequence}}.txt",
+		MaxObjectSize: config.Size(100),
+		Log:           testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// The first write stays below the threshold
+	require.NoError(t, plugin.Write(testMetrics("cpu", 2)))
+	require.Empty(t, client.uploads)
+
+	require.NoError(t, plugin.Write(testMetrics("cpu", 2)))
+	require.Len(t, client.uploads, 1)
+
+	u := client.uploads[0]
+	require.Equal(t, "telegraf", aws.ToString(u.input.Bucket))
+	require.Equal(t, "metrics/"+plugin.hostname+"-1.txt", aws.ToString(u.input.Key))
+	require.Nil(t, u.input.ContentEncoding)
+	require.Empty(t, u.input.ServerSideEncryption)
+	expected := "cpu,host=host-0 value=0i 0\ncpu,host=host-1 value=1i 1000000000\n"
+	require.Equal(t, expected+expected, string(u.body))
+	require.Empty(t, plugin.objects)
+}
+
+func TestWriteAgeThreshold(t *testing.T) {
+	plugin := &S3{
+		Bucket:       "telegraf",
+		MaxObjectAge: config.Duration(time.Hour),
+		Log:          testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics("cpu", 2)))
+	require.Empty(t, client.uploads)
+
+	// Age the object to trigger the upload with the next write
+	plugin.objects[""].created = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, plugin.Write(testMetrics("cpu", 1)))
+	require.Len(t, client.uploads, 1)
+	require.True(t, strings.HasPrefix(aws.ToString(client.uploads[0].input.Key), "telegraf/"))
+	require.Equal(t, 3, strings.Count(string(client.uploads[0].body), "\n"))
+}
+
+func TestWritePerMeasurement(t *testing.T) {
+	plugin := &S3{
+		Bucket: "telegraf",
+		Key:    "{{.Measurement}}/{{.Sequence}}",
+		Log:    testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := append(testMetrics("cpu", 2), testMetrics("mem", 3)...)
+	require.NoError(t, plugin.Write(metrics))
+	require.Empty(t, client.uploads)
+	require.Len(t, plugin.objects, 2)
+
+	require.NoError(t, plugin.Close())
+	require.Len(t, client.uploads, 2)
+
+	lines := make(map[string]int)
+	for _, u := range client.uploads {
+		measurement, _, _ := strings.Cut(aws.ToString(u.input.Key), "/")
+		lines[measurement] = strings.Count(string(u.body), measurement+",")
+	}
+	require.Equal(t, map[string]int{"cpu": 2, "mem": 3}, lines)
+}
+
+func TestWriteFailureRetry(t *testing.T) {
+	plugin := &S3{
+		Bucket:        "telegraf",
+		MaxObjectSize: config.Size(100),
+		Log:           testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics("cpu", 2)))
+
+	// The metrics of the failed write must not remain in the object
+	client.fail = true
+	require.ErrorContains(t, plugin.Write(testMetrics("mem", 2)), "service unavailable")
+	require.Len(t, plugin.objects, 1)
+
+	client.fail = false
+	require.NoError(t, plugin.Write(testMetrics("mem", 2)))
+	require.Len(t, client.uploads, 1)
+	body := string(client.uploads[0].body)
+	require.Equal(t, 2, strings.Count(body, "cpu,"))
+	require.Equal(t, 2, strings.Count(body, "mem,"))
+}
+
+func TestWriteEncodingAndEncryption(t *testing.T) {
+	plugin := &S3{
+		Bucket:               "telegraf",
+		MaxObjectSize:        config.Size(1),
+		ContentEncoding:      "gzip",
+		ServerSideEncryption: "aws:kms",
+		SSEKMSKeyID:          "alias/telegraf",
+		Log:                  testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics("cpu", 1)))
+	require.Len(t, client.uploads, 1)
+
+	u := client.uploads[0]
+	require.Equal(t, "gzip", aws.ToString(u.input.ContentEncoding))
+	require.Equal(t, types.ServerSideEncryptionAwsKms, u.input.ServerSideEncryption)
+	require.Equal(t, "alias/telegraf", aws.ToString(u.input.SSEKMSKeyId))
+
+	decoder, err := internal.NewContentDecoder("gzip")
+	require.NoError(t, err)
+	body, err := decoder.Decode(u.body)
+	require.NoError(t, err)
+	require.Equal(t, "cpu,host=host-0 value=0i 0\n", string(body))
+}
+
+func TestWriteMultipart(t *testing.T) {
+	plugin := &S3{
+		Bucket:               "telegraf",
+		MaxObjectSize:        config.Size(minPartSize),
+		MultipartPartSize:    config.Size(minPartSize),
+		ServerSideEncryption: "AES256",
+		Log:                  testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Create metrics with a total size of about 12MiB
+	value := strings.Repeat("x", 1024*1024)
+	metrics := make([]telegraf.Metric, 0, 12)
+	for i := range 12 {
+		metrics = append(metrics, metric.New("log", map[string]string{}, map[string]interface{}{"message": value}, time.Unix(int64(i), 0)))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Empty(t, client.uploads)
+	require.NotNil(t, client.multipart)
+	require.Equal(t, types.ServerSideEncryptionAes256, client.multipart.ServerSideEncryption)
+	require.Len(t, client.parts, 3)
+	require.Len(t, client.parts[0], minPartSize)
+	require.Len(t, client.parts[1], minPartSize)
+	require.NotNil(t, client.completed)
+	require.Len(t, client.completed.MultipartUpload.Parts, 3)
+	require.Equal(t, "etag-3", aws.ToString(client.completed.MultipartUpload.Parts[2].ETag))
+	require.False(t, client.aborted)
+
+	var size int
+	for _, p := range client.parts {
+		size += len(p)
+	}
+	require.Greater(t, size, 12*1024*1024)
+}
+
+func TestWriteMultipartAbort(t *testing.T) {
+	plugin := &S3{
+		Bucket:            "telegraf",
+		MaxObjectSize:     config.Size(1),
+		MultipartPartSize: config.Size(minPartSize),
+		Log:               testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	client.fail = true
+
+	value := strings.Repeat("x", minPartSize)
+	m := metric.New("log", map[string]string{}, map[string]interface{}{"message": value}, time.Unix(0, 0))
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "uploading part 1 failed")
+	require.True(t, client.aborted)
+	require.Empty(t, plugin.objects)
+}
+
+func TestWriteBatchFormatParquet(t *testing.T) {
+	serializer := &parquet.Serializer{
+		MeasurementColumn: "measurement",
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	plugin := &S3{
+		Bucket:         "telegraf",
+		Key:            "{{.Measurement}}/{{.Sequence}}.parquet",
+		UseBatchFormat: true,
+		Log:            testutil.Logger{},
+	}
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics("cpu", 3)))
+	require.NoError(t, plugin.Write(testMetrics("cpu", 4)))
+	require.NoError(t, plugin.Close())
+
+	// Both writes end up in a single, valid parquet file
+	require.Len(t, client.uploads, 1)
+	require.Equal(t, "cpu/1.parquet", aws.ToString(client.uploads[0].input.Key))
+	reader, err := file.NewParquetReader(bytes.NewReader(client.uploads[0].body))
+	require.NoError(t, err)
+	defer reader.Close()
+	require.EqualValues(t, 7, reader.NumRows())
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *S3
+		expected string
+	}{
+		{
+			name:     "no bucket",
+			plugin:   &S3{},
+			expected: "'bucket' is required",
+		},
+		{
+			name:     "invalid key",
+			plugin:   &S3{Bucket: "telegraf", Key: "{{.Time"},
+			expected: "parsing 'key' template failed",
+		},
+		{
+			name:     "part size too small",
+			plugin:   &S3{Bucket: "telegraf", MultipartPartSize: config.Size(1024)},
+			expected: "'multipart_part_size' must be at least",
+		},
+		{
+			name:     "invalid encoding",
+			plugin:   &S3{Bucket: "telegraf", ContentEncoding: "lz4"},
+			expected: "invalid 'content_encoding'",
+		},
+		{
+			name:     "invalid encryption",
+			plugin:   &S3{Bucket: "telegraf", ServerSideEncryption: "kms"},
+			expected: "invalid 'server_side_encryption'",
+		},
+		{
+			name:     "key without kms",
+			plugin:   &S3{Bucket: "telegraf", SSEKMSKeyID: "alias/telegraf"},
+			expected: "'sse_kms_key_id' requires",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package sql
 
 import (
+	"context"
 	gosql "database/sql"
 	_ "embed"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	common_sql "github.com/influxdata/telegraf/plugins/common/sql"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -49,9 +51,12 @@ type SQL struct {
 	ConnectionMaxLifetime config.Duration `toml:"connection_max_lifetime"`
 	ConnectionMaxIdle     int             `toml:"connection_max_idle"`
 	ConnectionMaxOpen     int             `toml:"connection_max_open"`
+	HealthCheckQuery      string          `toml:"health_check_query"`
+	QueryTimeout          config.Duration `toml:"query_timeout"`
 	Log                   telegraf.Logger `toml:"-"`
+	common_sql.AuthConfig
 
-	db     *gosql.DB
+	db     *common_sql.Pool
 	tables map[string]bool
 }
 
@@ -59,25 +64,38 @@ func (*SQL) SampleConfig() string {
 	return sampleConfig
 }
 
-func (p *SQL) Connect() error {
-	db, err := gosql.Open(p.Driver, p.DataSourceName)
-	if err != nil {
+func (p *SQL) Init() error {
+	if err := p.AuthConfig.Init(); err != nil {
 		return err
 	}
+	return p.AuthConfig.CheckDriver(p.Driver)
+}
 
-	err = db.Ping()
+func (p *SQL) Connect() error {
+	db, err := common_sql.Open(p.Driver, p.DataSourceName, common_sql.PoolConfig{
+		MaxOpen:          p.ConnectionMaxOpen,
+		MaxIdle:          p.ConnectionMaxIdle,
+		MaxLifetime:      time.Duration(p.ConnectionMaxLifetime),
+		MaxIdleTime:      time.Duration(p.ConnectionMaxIdleTime),
+		HealthCheckQuery: p.HealthCheckQuery,
+		Timeout:          time.Duration(p.QueryTimeout),
+		Auth:             &p.AuthConfig,
+	})
 	if err != nil {
 		return err
 	}
 
-	db.SetConnMaxIdleTime(time.Duration(p.ConnectionMaxIdleTime))
-	db.SetConnMaxLifetime(time.Duration(p.ConnectionMaxLifetime))
-	db.SetMaxIdleConns(p.ConnectionMaxIdle)
-	db.SetMaxOpenConns(p.ConnectionMaxOpen)
+	if err := db.Check(context.Background()); err != nil {
+		db.Close()
+		return err
+	}
 
 	if p.InitSQL != "" {
-		_, err = db.Exec(p.InitSQL)
+		ctx, cancel := db.WithTimeout(context.Background())
+		_, err = db.ExecContext(ctx, p.InitSQL)
+		cancel()
 		if err != nil {
+			db.Close()
 			return err
 		}
 	}
@@ -196,10 +214,17 @@ func (p *SQL) generateInsert(tablename string, columns []string) string {
 func (p *SQL) tableExists(tableName string) bool {
 	stmt := strings.ReplaceAll(p.TableExistsTemplate, "{TABLE}", quoteIdent(tableName))
 
-	_, err := p.db.Exec(stmt)
+	_, err := p.exec(stmt)
 	return err == nil
 }
 
+// exec executes the statement bound by the configured query timeout
+func (p *SQL) exec(query string, args ...interface{}) (gosql.Result, error) {
+	ctx, cancel := p.db.WithTimeout(context.Background())
+	defer cancel()
+	return p.db.ExecContext(ctx, query, args...)
+}
+
 func (p *SQL) Write(metrics []telegraf.Metric) error {
 	var err error
 
@@ -209,7 +234,7 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 		// create table if needed
 		if !p.tables[tablename] && !p.tableExists(tablename) {
 			createStmt := p.generateCreateTable(metric)
-			_, err := p.db.Exec(createStmt)
+			_, err := p.exec(createStmt)
 			if err != nil {
 				return err
 			}
@@ -258,7 +283,7 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 				return fmt.Errorf("commit failed: %w", err)
 			}
 		default:
-			_, err = p.db.Exec(sql, values...)
+			_, err = p.exec(sql, values...)
 			if err != nil {
 				return fmt.Errorf("execution failed: %w", err)
 			}
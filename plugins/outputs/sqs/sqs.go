@@ -0,0 +1,289 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sqs
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits set by AWS, see
+// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/APIReference/API_SendMessageBatch.html
+const (
+	maxEntriesPerRequest = 10
+	maxRequestSize       = 256 * 1024
+	maxMessageAttributes = 10
+)
+
+type SQS struct {
+	QueueURL               string          `toml:"queue_url"`
+	MessageGroupID         string          `toml:"message_group_id"`
+	MessageDeduplicationID string          `toml:"message_deduplication_id"`
+	MessageAttributeTags   []string        `toml:"message_attribute_tags"`
+	ContentEncoding        string          `toml:"content_encoding"`
+	Log                    telegraf.Logger `toml:"-"`
+
+	common_aws.CredentialConfig
+	common_aws.ClientConfig
+
+	client        sqsClient
+	serializer    telegraf.Serializer
+	encoder       internal.ContentEncoder
+	fifo          bool
+	groupID       *template.Template
+	deduplication *template.Template
+}
+
+// sqsClient contains the SQS API used, implemented by sqs.Client
+type sqsClient interface {
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+}
+
+func (*SQS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SQS) Init() error {
+	if s.QueueURL == "" {
+		return errors.New("'queue_url' is required")
+	}
+	s.fifo = strings.HasSuffix(s.QueueURL, ".fifo")
+
+	if s.fifo && s.MessageGroupID == "" {
+		return errors.New("'message_group_id' is required for FIFO queues")
+	}
+	if !s.fifo && s.MessageDeduplicationID != "" {
+		return errors.New("'message_deduplication_id' is only supported for FIFO queues")
+	}
+
+	var err error
+	if s.MessageGroupID != "" {
+		if s.groupID, err = template.New("message_group_id").Parse(s.MessageGroupID); err != nil {
+			return fmt.Errorf("parsing 'message_group_id' template failed: %w", err)
+		}
+	}
+	if s.MessageDeduplicationID != "" {
+		if s.deduplication, err = template.New("message_deduplication_id").Parse(s.MessageDeduplicationID); err != nil {
+			return fmt.Errorf("parsing 'message_deduplication_id' template failed: %w", err)
+		}
+	}
+
+	if len(s.MessageAttributeTags) > maxMessageAttributes {
+		return fmt.Errorf("at most %d 'message_attribute_tags' are supported", maxMessageAttributes)
+	}
+
+	if s.ContentEncoding == "" {
+		s.ContentEncoding = "identity"
+	}
+	if s.encoder, err = internal.NewContentEncoder(s.ContentEncoding); err != nil {
+		return fmt.Errorf("unknown content encoding %q", s.ContentEncoding)
+	}
+
+	return nil
+}
+
+func (s *SQS) SetSerializer(serializer telegraf.Serializer) {
+	s.serializer = serializer
+}
+
+func (s *SQS) Connect() error {
+	if s.client != nil {
+		return nil
+	}
+
+	httpClient, err := s.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	s.CredentialConfig.HTTPClient = httpClient
+
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return err
+	}
+	s.client = sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		if s.EndpointURL != "" {
+			o.BaseEndpoint = &s.EndpointURL
+		}
+	})
+
+	return nil
+}
+
+func (*SQS) Close() error {
+	return nil
+}
+
+// Write sends each metric as a message to the queue, using as few
+// SendMessageBatch requests as the entry and size limits allow. Metrics
+// failing to serialize or rejected by SQS due to their content are dropped,
+// all other failures cause the whole batch to be written again.
+func (s *SQS) Write(metrics []telegraf.Metric) error {
+	entries := make([]types.SendMessageBatchRequestEntry, 0, maxEntriesPerRequest)
+	var size int
+	var failed int
+	for _, m := range metrics {
+		entry, err := s.createEntry(m, strconv.Itoa(len(entries)))
+		if err != nil {
+			s.Log.Errorf("Dropping metric %q: %v", m.Name(), err)
+			continue
+		}
+
+		entrySize := messageSize(entry)
+		if entrySize > maxRequestSize {
+			s.Log.Errorf("Dropping metric %q: message size %d exceeds limit of %d bytes", m.Name(), entrySize, maxRequestSize)
+			continue
+		}
+
+		if len(entries) == maxEntriesPerRequest || size+entrySize > maxRequestSize {
+			n, err := s.send(entries)
+			if err != nil {
+				return err
+			}
+			failed += n
+			entries, size = entries[:0], 0
+			entry.Id = aws.String("0")
+		}
+		entries = append(entries, *entry)
+		size += entrySize
+	}
+
+	if len(entries) > 0 {
+		n, err := s.send(entries)
+		if err != nil {
+			return err
+		}
+		failed += n
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("sending %d message(s) failed", failed)
+	}
+	return nil
+}
+
+func (s *SQS) createEntry(m telegraf.Metric, id string) (*types.SendMessageBatchRequestEntry, error) {
+	body, err := s.serializer.Serialize(m)
+	if err != nil {
+		return nil, fmt.Errorf("serialization failed: %w", err)
+	}
+
+	// SQS only supports text, so compressed messages are base64 encoded
+	if s.ContentEncoding != "identity" {
+		encoded, err := s.encoder.Encode(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding failed: %w", err)
+		}
+		body = []byte(base64.StdEncoding.EncodeToString(encoded))
+	} else if !utf8.Valid(body) {
+		return nil, errors.New("message body is not valid text, consider setting a 'content_encoding'")
+	}
+
+	entry := &types.SendMessageBatchRequestEntry{
+		Id:          aws.String(id),
+		MessageBody: aws.String(string(body)),
+	}
+
+	if s.groupID != nil {
+		groupID, err := execute(s.groupID, m)
+		if err != nil {
+			return nil, err
+		}
+		entry.MessageGroupId = aws.String(groupID)
+	}
+	if s.deduplication != nil {
+		deduplicationID, err := execute(s.deduplication, m)
+		if err != nil {
+			return nil, err
+		}
+		entry.MessageDeduplicationId = aws.String(deduplicationID)
+	}
+
+	for _, key := range s.MessageAttributeTags {
+		value, found := m.GetTag(key)
+		if !found || value == "" {
+			continue
+		}
+		if entry.MessageAttributes == nil {
+			entry.MessageAttributes = make(map[string]types.MessageAttributeValue, len(s.MessageAttributeTags))
+		}
+		entry.MessageAttributes[key] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	return entry, nil
+}
+
+// send sends the entries and returns the number of entries failing due to
+// an error not caused by the message itself
+func (s *SQS) send(entries []types.SendMessageBatchRequestEntry) (int, error) {
+	input := &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(s.QueueURL),
+		Entries:  entries,
+	}
+	out, err := s.client.SendMessageBatch(context.Background(), input)
+	if err != nil {
+		return 0, fmt.Errorf("sending messages failed: %w", err)
+	}
+
+	var failed int
+	for _, f := range out.Failed {
+		if f.SenderFault {
+			s.Log.Errorf("Message rejected: %s: %s", aws.ToString(f.Code), aws.ToString(f.Message))
+			continue
+		}
+		s.Log.Debugf("Sending message failed: %s: %s", aws.ToString(f.Code), aws.ToString(f.Message))
+		failed++
+	}
+	return failed, nil
+}
+
+func execute(tmpl *template.Template, m telegraf.Metric) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return "", fmt.Errorf("executing %s template failed: %w", tmpl.Name(), err)
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("%s template resulted in empty value", tmpl.Name())
+	}
+	return buf.String(), nil
+}
+
+// messageSize returns the size of the message as counted by SQS, i.e. the
+// size of the body and the names, types and values of the attributes
+func messageSize(entry *types.SendMessageBatchRequestEntry) int {
+	size := len(aws.ToString(entry.MessageBody))
+	for name, attr := range entry.MessageAttributes {
+		size += len(name) + len(aws.ToString(attr.DataType)) + len(aws.ToString(attr.StringValue))
+	}
+	return size
+}
+
+func init() {
+	outputs.Add("sqs", func() telegraf.Output {
+		return &SQS{
+			ContentEncoding: "identity",
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package sqs
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func testMetrics(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "host-" + strconv.Itoa(i%2)},
+			map[string]interface{}{"value": int64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	return metrics
+}
+
+func TestWriteBatches(t *testing.T) {
+	plugin := &SQS{
+		QueueURL:             "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		MessageAttributeTags: []string{"host", "missing"},
+		Log:                  testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics(25)))
+
+	// Requests are limited to ten messages
+	require.Len(t, client.requests, 3)
+	require.Len(t, client.requests[0].Entries, 10)
+	require.Len(t, client.requests[1].Entries, 10)
+	require.Len(t, client.requests[2].Entries, 5)
+
+	entry := client.requests[1].Entries[3]
+	require.Equal(t, "3", aws.ToString(entry.Id))
+	require.Equal(t, "cpu,host=host-1 value=13i 13000000000\n", aws.ToString(entry.MessageBody))
+	require.Nil(t, entry.MessageGroupId)
+	require.Nil(t, entry.MessageDeduplicationId)
+	require.Equal(t, map[string]types.MessageAttributeValue{
+		"host": {DataType: aws.String("String"), StringValue: aws.String("host-1")},
+	}, entry.MessageAttributes)
+}
+
+func TestWriteRequestSize(t *testing.T) {
+	plugin := &SQS{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		Log:      testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Each message takes more than a third of the request limit
+	metrics := make([]telegraf.Metric, 0, 4)
+	for i := range 4 {
+		metrics = append(metrics, metric.New(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"message": strings.Repeat("x", maxRequestSize/3)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	// Messages exceeding the limit are dropped
+	metrics = append(metrics, metric.New(
+		"log",
+		map[string]string{},
+		map[string]interface{}{"message": strings.Repeat("x", maxRequestSize)},
+		time.Unix(0, 0),
+	))
+
+	require.NoError(t, plugin.Write(metrics))
+	require.Len(t, client.requests, 2)
+	require.Len(t, client.requests[0].Entries, 2)
+	require.Len(t, client.requests[1].Entries, 2)
+	require.Equal(t, "0", aws.ToString(client.requests[1].Entries[0].Id))
+}
+
+func TestWriteFIFO(t *testing.T) {
+	plugin := &SQS{
+		QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf.fifo",
+		MessageGroupID:         `{{.Tag "host"}}`,
+		MessageDeduplicationID: `{{.Name}}-{{.Tag "host"}}-{{.Time.Unix}}`,
+		Log:                    testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics(2)))
+	require.Len(t, client.requests, 1)
+
+	entries := client.requests[0].Entries
+	require.Len(t, entries, 2)
+	require.Equal(t, "host-0", aws.ToString(entries[0].MessageGroupId))
+	require.Equal(t, "cpu-host-0-0", aws.ToString(entries[0].MessageDeduplicationId))
+	require.Equal(t, "host-1", aws.ToString(entries[1].MessageGroupId))
+	require.Equal(t, "cpu-host-1-1", aws.ToString(entries[1].MessageDeduplicationId))
+
+	// Metrics without a group ID are dropped
+	client.requests = nil
+	m := metric.New("mem", map[string]string{}, map[string]interface{}{"used": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Empty(t, client.requests)
+}
+
+func TestWriteContentEncoding(t *testing.T) {
+	plugin := &SQS{
+		QueueURL:        "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		ContentEncoding: "gzip",
+		Log:             testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write(testMetrics(1)))
+	require.Len(t, client.requests, 1)
+
+	body, err := base64.StdEncoding.DecodeString(aws.ToString(client.requests[0].Entries[0].MessageBody))
+	require.NoError(t, err)
+	decoder, err := internal.NewContentDecoder("gzip")
+	require.NoError(t, err)
+	decoded, err := decoder.Decode(body)
+	require.NoError(t, err)
+	require.Equal(t, "cpu,host=host-0 value=0i 0\n", string(decoded))
+}
+
+func TestWriteFailed(t *testing.T) {
+	plugin := &SQS{
+		QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+		Log:      testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	client := &mockClient{}
+	plugin.client = client
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Messages rejected due to their content are dropped
+	client.failed = []types.BatchResultErrorEntry{
+		{Id: aws.String("0"), Code: aws.String("InvalidMessageContents"), SenderFault: true},
+	}
+	require.NoError(t, plugin.Write(testMetrics(2)))
+
+	// Other failures are retried with the whole batch
+	client.failed = []types.BatchResultErrorEntry{
+		{Id: aws.String("1"), Code: aws.String("InternalError")},
+	}
+	require.ErrorContains(t, plugin.Write(testMetrics(2)), "sending 1 message(s) failed")
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SQS
+		expected string
+	}{
+		{
+			name:     "no queue",
+			plugin:   &SQS{},
+			expected: "'queue_url' is required",
+		},
+		{
+			name:     "FIFO without group",
+			plugin:   &SQS{QueueURL: "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf.fifo"},
+			expected: "'message_group_id' is required for FIFO queues",
+		},
+		{
+			name: "deduplication for standard queue",
+			plugin: &SQS{
+				QueueURL:               "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+				MessageDeduplicationID: "{{.Name}}",
+			},
+			expected: "only supported for FIFO queues",
+		},
+		{
+			name: "invalid template",
+			plugin: &SQS{
+				QueueURL:       "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf.fifo",
+				MessageGroupID: "{{.Name",
+			},
+			expected: "parsing 'message_group_id' template failed",
+		},
+		{
+			name: "invalid encoding",
+			plugin: &SQS{
+				QueueURL:        "https://sqs.us-east-1.amazonaws.com/123456789012/telegraf",
+				ContentEncoding: "brotli",
+			},
+			expected: `unknown content encoding "brotli"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+type mockClient struct {
+	requests []*sqs.SendMessageBatchInput
+	failed   []types.BatchResultErrorEntry
+	sync.Mutex
+}
+
+func (c *mockClient) SendMessageBatch(_ context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Copy the entries as the plugin reuses the slice
+	input := *params
+	input.Entries = append([]types.SendMessageBatchRequestEntry(nil), params.Entries...)
+	c.requests = append(c.requests, &input)
+
+	return &sqs.SendMessageBatchOutput{Failed: c.failed}, nil
+}
@@ -0,0 +1,185 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package telegraf
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/common/transport"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Telegraf struct {
+	Address     string          `toml:"address"`
+	AgentName   string          `toml:"agent_name"`
+	Compression string          `toml:"compression"`
+	Timeout     config.Duration `toml:"timeout"`
+	Log         telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	compressionID uint8
+	compressor    internal.ContentEncoder
+	tlsCfg        *tls.Config
+
+	conn    net.Conn
+	encoder *transport.Encoder
+	seq     uint64
+}
+
+func (*Telegraf) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Telegraf) Init() error {
+	if t.Address == "" {
+		return errors.New("'address' required")
+	}
+	if t.AgentName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("getting hostname failed: %w", err)
+		}
+		t.AgentName = hostname
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = config.Duration(30 * time.Second)
+	}
+	if t.Compression == "" {
+		t.Compression = "zstd"
+	}
+
+	id, err := transport.CompressionID(t.Compression)
+	if err != nil {
+		return err
+	}
+	t.compressionID = id
+	t.compressor, err = internal.NewContentEncoder(t.Compression)
+	if err != nil {
+		return fmt.Errorf("creating compressor failed: %w", err)
+	}
+
+	t.tlsCfg, err = t.ClientConfig.TLSConfig()
+	return err
+}
+
+func (t *Telegraf) Connect() error {
+	dialer := &net.Dialer{Timeout: time.Duration(t.Timeout)}
+
+	var conn net.Conn
+	var err error
+	if t.tlsCfg == nil {
+		conn, err = dialer.Dial("tcp", t.Address)
+	} else {
+		conn, err = tls.DialWithDialer(dialer, "tcp", t.Address, t.tlsCfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(t.Timeout))); err != nil {
+		conn.Close()
+		return err
+	}
+	if err := transport.WriteHello(conn); err != nil {
+		conn.Close()
+		return fmt.Errorf("sending hello failed: %w", err)
+	}
+	if err := transport.ReadHello(conn); err != nil {
+		conn.Close()
+		return fmt.Errorf("receiving hello failed: %w", err)
+	}
+	if err := transport.WriteFrame(conn, transport.FrameAgent, []byte(t.AgentName)); err != nil {
+		conn.Close()
+		return fmt.Errorf("sending agent name failed: %w", err)
+	}
+
+	t.conn = conn
+	t.encoder = transport.NewEncoder()
+	return nil
+}
+
+func (t *Telegraf) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+func (t *Telegraf) Write(metrics []telegraf.Metric) error {
+	if t.conn == nil {
+		if err := t.Connect(); err != nil {
+			return fmt.Errorf("connecting failed: %w", err)
+		}
+	}
+
+	data, err := t.compressor.Encode(t.encoder.Encode(metrics))
+	if err != nil {
+		// The dictionary of the encoder already contains the new entries so
+		// we need to start over with a new connection
+		t.Close()
+		return fmt.Errorf("compressing batch failed: %w", err)
+	}
+
+	t.seq++
+	batch := &transport.Batch{Seq: t.seq, Compression: t.compressionID, Data: data}
+	ack, err := t.send(batch)
+	if err != nil {
+		t.Close()
+		return err
+	}
+	if ack.Status != transport.AckOK {
+		return fmt.Errorf("batch rejected: %s", ack.Message)
+	}
+	return nil
+}
+
+func (t *Telegraf) send(batch *transport.Batch) (*transport.Ack, error) {
+	// The timeout covers the time the receiving side needs to process the
+	// batch as it only acknowledges after that
+	if err := t.conn.SetDeadline(time.Now().Add(time.Duration(t.Timeout))); err != nil {
+		return nil, err
+	}
+	if err := transport.WriteFrame(t.conn, transport.FrameBatch, batch.Marshal()); err != nil {
+		return nil, fmt.Errorf("sending batch failed: %w", err)
+	}
+
+	ft, payload, err := transport.ReadFrame(t.conn, transport.DefaultMaxFrameSize)
+	if err != nil {
+		return nil, fmt.Errorf("receiving acknowledgement failed: %w", err)
+	}
+	if ft != transport.FrameAck {
+		return nil, fmt.Errorf("unexpected frame type %d", ft)
+	}
+
+	var ack transport.Ack
+	if err := ack.Unmarshal(payload); err != nil {
+		return nil, err
+	}
+	if ack.Seq != batch.Seq {
+		return nil, fmt.Errorf("acknowledgement for batch %d received while waiting for %d", ack.Seq, batch.Seq)
+	}
+	return &ack, nil
+}
+
+func init() {
+	outputs.Add("telegraf", func() telegraf.Output {
+		return &Telegraf{
+			Compression: "zstd",
+			Timeout:     config.Duration(30 * time.Second),
+		}
+	})
+}
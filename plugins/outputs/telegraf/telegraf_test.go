@@ -0,0 +1,144 @@
+package telegraf
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/transport"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// serve accepts connections and answers each batch using the given function.
+// The connection is closed if the function returns nil.
+func serve(t *testing.T, respond func(*transport.Batch) *transport.Ack) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if transport.ReadHello(conn) != nil || transport.WriteHello(conn) != nil {
+					return
+				}
+				for {
+					ft, payload, err := transport.ReadFrame(conn, transport.DefaultMaxFrameSize)
+					if err != nil {
+						return
+					}
+					if ft != transport.FrameBatch {
+						continue
+					}
+					var batch transport.Batch
+					if err := batch.Unmarshal(payload); err != nil {
+						return
+					}
+					ack := respond(&batch)
+					if ack == nil {
+						return
+					}
+					if err := transport.WriteFrame(conn, transport.FrameAck, ack.Marshal()); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+var testMetrics = []telegraf.Metric{
+	metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+}
+
+func TestWriteRejected(t *testing.T) {
+	listener := serve(t, func(b *transport.Batch) *transport.Ack {
+		return &transport.Ack{Seq: b.Seq, Status: transport.AckError, Message: "output failed"}
+	})
+
+	plugin := &Telegraf{
+		Address: listener.Addr().String(),
+		Timeout: config.Duration(3 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testMetrics), "batch rejected: output failed")
+
+	// A rejected batch keeps the connection
+	require.NotNil(t, plugin.conn)
+}
+
+func TestWriteReconnect(t *testing.T) {
+	var received []uint64
+	var mu sync.Mutex
+	listener := serve(t, func(b *transport.Batch) *transport.Ack {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, b.Seq)
+		if len(received) == 1 {
+			return nil
+		}
+		return &transport.Ack{Seq: b.Seq}
+	})
+
+	plugin := &Telegraf{
+		Address: listener.Addr().String(),
+		Timeout: config.Duration(3 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The connection is closed without acknowledgement so the batch fails and
+	// is resent using a new connection
+	require.ErrorContains(t, plugin.Write(testMetrics), "receiving acknowledgement failed")
+	require.Nil(t, plugin.conn)
+	require.NoError(t, plugin.Write(testMetrics))
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []uint64{1, 2}, received)
+}
+
+func TestWriteWrongSequence(t *testing.T) {
+	listener := serve(t, func(b *transport.Batch) *transport.Ack {
+		return &transport.Ack{Seq: b.Seq + 1}
+	})
+
+	plugin := &Telegraf{
+		Address: listener.Addr().String(),
+		Timeout: config.Duration(3 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testMetrics), "acknowledgement for batch 2 received while waiting for 1")
+	require.Nil(t, plugin.conn)
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Telegraf{}
+	require.ErrorContains(t, plugin.Init(), "'address' required")
+
+	plugin = &Telegraf{Address: "localhost:8099", Compression: "brotli"}
+	require.ErrorContains(t, plugin.Init(), `unknown compression "brotli"`)
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,6 +39,8 @@ type (
 
 		UseMultiMeasureRecords            bool   `toml:"use_multi_measure_records"`
 		MeasureNameForMultiMeasureRecords string `toml:"measure_name_for_multi_measure_records"`
+		MultiMeasureNameSeparator         string `toml:"multi_measure_name_separator"`
+		UseCommonAttributes               bool   `toml:"use_common_attributes"`
 
 		CreateTableIfNotExists                        bool              `toml:"create_table_if_not_exists"`
 		CreateTableMagneticStoreRetentionPeriodInDays int64             `toml:"create_table_magnetic_store_retention_period_in_days"`
@@ -48,6 +52,7 @@ type (
 		svc WriteClient
 
 		common_aws.CredentialConfig
+		common_aws.ClientConfig
 	}
 
 	WriteClient interface {
@@ -160,6 +165,10 @@ func (t *Timestream) Connect() error {
 		}
 	}
 
+	if t.MultiMeasureNameSeparator != "" && !t.UseMultiMeasureRecords {
+		return errors.New("MultiMeasureNameSeparator key requires multi-measure records to be enabled")
+	}
+
 	if t.CreateTableIfNotExists {
 		if t.CreateTableMagneticStoreRetentionPeriodInDays < 1 {
 			return errors.New("if Telegraf should create tables, CreateTableMagneticStoreRetentionPeriodInDays key should have a value greater than 0")
@@ -176,6 +185,12 @@ func (t *Timestream) Connect() error {
 
 	t.Log.Infof("Constructing Timestream client for %q mode", t.MappingMode)
 
+	httpClient, err := t.ClientConfig.CreateClient()
+	if err != nil {
+		return err
+	}
+	t.CredentialConfig.HTTPClient = httpClient
+
 	svc, err := WriteFactory(&t.CredentialConfig)
 	if err != nil {
 		return err
@@ -370,7 +385,7 @@ func (t *Timestream) createTable(tableName *string) error {
 }
 
 // TransformMetrics transforms a collection of Telegraf Metrics into write requests to Timestream.
-// Telegraf Metrics are grouped by Name, Tag Keys and Time to use Timestream CommonAttributes.
+// Telegraf Metrics are grouped by table and, if common attributes are used, by their dimensions.
 // Returns collection of write requests to be performed to Timestream.
 func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwrite.WriteRecordsInput {
 	writeRequests := make(map[string]*timestreamwrite.WriteRecordsInput, len(metrics))
@@ -391,7 +406,13 @@ func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwr
 			tableName = m.Name()
 		}
 
-		if curr, ok := writeRequests[tableName]; !ok {
+		// All records of a metric share the same dimensions
+		key := tableName
+		if t.UseCommonAttributes {
+			key += "\x00" + dimensionsKey(records[0].Dimensions)
+		}
+
+		if curr, ok := writeRequests[key]; !ok {
 			newWriteRecord := &timestreamwrite.WriteRecordsInput{
 				DatabaseName:     aws.String(t.DatabaseName),
 				TableName:        aws.String(tableName),
@@ -399,7 +420,7 @@ func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwr
 				CommonAttributes: &types.Record{},
 			}
 
-			writeRequests[tableName] = newWriteRecord
+			writeRequests[key] = newWriteRecord
 		} else {
 			curr.Records = append(curr.Records, records...)
 		}
@@ -414,7 +435,7 @@ func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwr
 					DatabaseName:     writeRequest.DatabaseName,
 					TableName:        writeRequest.TableName,
 					Records:          recordsPartition,
-					CommonAttributes: writeRequest.CommonAttributes,
+					CommonAttributes: &types.Record{},
 				}
 				result = append(result, newWriteRecord)
 			}
@@ -422,9 +443,68 @@ func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwr
 			result = append(result, writeRequest)
 		}
 	}
+
+	if t.UseCommonAttributes {
+		for _, writeRequest := range result {
+			hoistCommonAttributes(writeRequest)
+		}
+	}
 	return result
 }
 
+// dimensionsKey returns a key identifying the set of dimensions independent of their order.
+func dimensionsKey(dimensions []types.Dimension) string {
+	parts := make([]string, 0, len(dimensions))
+	for _, d := range dimensions {
+		parts = append(parts, aws.ToString(d.Name)+"\x00"+aws.ToString(d.Value))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\x00")
+}
+
+// hoistCommonAttributes moves the attributes shared by all records of the request into
+// the common attributes so they are only sent once. The records of a request are
+// expected to have the same dimensions.
+func hoistCommonAttributes(writeRequest *timestreamwrite.WriteRecordsInput) {
+	first := writeRequest.Records[0]
+	sameTime, sameName, sameType := true, true, true
+	for _, r := range writeRequest.Records[1:] {
+		sameTime = sameTime && aws.ToString(r.Time) == aws.ToString(first.Time) && r.TimeUnit == first.TimeUnit
+		sameName = sameName && aws.ToString(r.MeasureName) == aws.ToString(first.MeasureName)
+		sameType = sameType && r.MeasureValueType == first.MeasureValueType
+	}
+
+	common := &types.Record{Dimensions: first.Dimensions}
+	if sameTime {
+		common.Time = first.Time
+		common.TimeUnit = first.TimeUnit
+	}
+	if sameName {
+		common.MeasureName = first.MeasureName
+	}
+	if sameType {
+		common.MeasureValueType = first.MeasureValueType
+	}
+
+	records := make([]types.Record, 0, len(writeRequest.Records))
+	for _, r := range writeRequest.Records {
+		r.Dimensions = nil
+		if sameTime {
+			r.Time = nil
+			r.TimeUnit = ""
+		}
+		if sameName {
+			r.MeasureName = nil
+		}
+		if sameType {
+			r.MeasureValueType = ""
+		}
+		records = append(records, r)
+	}
+	writeRequest.Records = records
+	writeRequest.CommonAttributes = common
+}
+
 func (t *Timestream) buildDimensions(point telegraf.Metric) []types.Dimension {
 	dimensions := make([]types.Dimension, 0, len(point.Tags()))
 	for tagName, tagValue := range point.Tags() {
@@ -482,8 +562,10 @@ func (t *Timestream) buildSingleWriteRecords(point telegraf.Metric) []types.Reco
 	return records
 }
 
+// buildMultiMeasureWriteRecords builds one multi-measure record per measure name. If a
+// separator is configured, the measure name is derived from the field name's prefix.
+// Records without any supported field are skipped.
 func (t *Timestream) buildMultiMeasureWriteRecords(point telegraf.Metric) []types.Record {
-	var records []types.Record
 	dimensions := t.buildDimensions(point)
 
 	multiMeasureName := t.MeasureNameForMultiMeasureRecords
@@ -491,35 +573,46 @@ func (t *Timestream) buildMultiMeasureWriteRecords(point telegraf.Metric) []type
 		multiMeasureName = point.Name()
 	}
 
-	multiMeasures := make([]types.MeasureValue, 0, len(point.Fields()))
-	for fieldName, fieldValue := range point.Fields() {
-		stringFieldValue, stringFieldValueType, ok := convertValue(fieldValue)
+	timeUnit, timeValue := getTimestreamTime(point.Time())
+
+	records := make([]types.Record, 0, 1)
+	indices := make(map[string]int, 1)
+	for _, field := range point.FieldList() {
+		stringFieldValue, stringFieldValueType, ok := convertValue(field.Value)
 		if !ok {
 			t.Log.Warnf("Skipping field %q. The type %q is not supported in Timestream as MeasureValue. "+
 				"Supported values are: [int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool]",
-				fieldName, reflect.TypeOf(fieldValue))
+				field.Key, reflect.TypeOf(field.Value))
 			continue
 		}
-		multiMeasures = append(multiMeasures, types.MeasureValue{
-			Name:  aws.String(fieldName),
+
+		measureName, valueName := multiMeasureName, field.Key
+		if t.MultiMeasureNameSeparator != "" {
+			prefix, suffix, found := strings.Cut(field.Key, t.MultiMeasureNameSeparator)
+			if found && prefix != "" && suffix != "" {
+				measureName, valueName = prefix, suffix
+			}
+		}
+
+		idx, found := indices[measureName]
+		if !found {
+			idx = len(records)
+			indices[measureName] = idx
+			records = append(records, types.Record{
+				MeasureName:      aws.String(measureName),
+				MeasureValueType: "MULTI",
+				Dimensions:       dimensions,
+				Time:             aws.String(timeValue),
+				TimeUnit:         timeUnit,
+			})
+		}
+		records[idx].MeasureValues = append(records[idx].MeasureValues, types.MeasureValue{
+			Name:  aws.String(valueName),
 			Type:  stringFieldValueType,
 			Value: aws.String(stringFieldValue),
 		})
 	}
 
-	timeUnit, timeValue := getTimestreamTime(point.Time())
-
-	record := types.Record{
-		MeasureName:      aws.String(multiMeasureName),
-		MeasureValueType: "MULTI",
-		MeasureValues:    multiMeasures,
-		Dimensions:       dimensions,
-		Time:             aws.String(timeValue),
-		TimeUnit:         timeUnit,
-	}
-
-	records = append(records, record)
-
 	return records
 }
 
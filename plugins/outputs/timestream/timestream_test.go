@@ -1215,6 +1215,197 @@ func TestTransformMetricsUnsupportedFieldsAreSkipped(t *testing.T) {
 		[]*timestreamwrite.WriteRecordsInput{expectedResultMultiTable})
 }
 
+func TestBuildMultiMeasuresWithSeparator(t *testing.T) {
+	input := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_user":   float64(1.5),
+			"usage_system": float64(2.5),
+			"time_idle":    int64(100),
+			"count":        int64(4),
+			"_leading":     int64(1),
+		},
+		time1,
+	)
+
+	plugin := Timestream{
+		MappingMode:                       MappingModeMultiTable,
+		DatabaseName:                      tsDbName,
+		UseMultiMeasureRecords:            true,
+		MeasureNameForMultiMeasureRecords: "default",
+		MultiMeasureNameSeparator:         "_",
+		Log:                               testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+
+	dimensions := []types.Dimension{{Name: aws.String("cpu"), Value: aws.String("cpu0")}}
+	expected := []types.Record{
+		{
+			MeasureName:      aws.String("usage"),
+			MeasureValueType: "MULTI",
+			MeasureValues: []types.MeasureValue{
+				{Name: aws.String("system"), Type: types.MeasureValueTypeDouble, Value: aws.String("2.5")},
+				{Name: aws.String("user"), Type: types.MeasureValueTypeDouble, Value: aws.String("1.5")},
+			},
+			Dimensions: dimensions,
+			Time:       aws.String(time1Epoch),
+			TimeUnit:   timeUnit,
+		},
+		{
+			MeasureName:      aws.String("time"),
+			MeasureValueType: "MULTI",
+			MeasureValues: []types.MeasureValue{
+				{Name: aws.String("idle"), Type: types.MeasureValueTypeBigint, Value: aws.String("100")},
+			},
+			Dimensions: dimensions,
+			Time:       aws.String(time1Epoch),
+			TimeUnit:   timeUnit,
+		},
+		{
+			MeasureName:      aws.String("default"),
+			MeasureValueType: "MULTI",
+			MeasureValues: []types.MeasureValue{
+				{Name: aws.String("_leading"), Type: types.MeasureValueTypeBigint, Value: aws.String("1")},
+				{Name: aws.String("count"), Type: types.MeasureValueTypeBigint, Value: aws.String("4")},
+			},
+			Dimensions: dimensions,
+			Time:       aws.String(time1Epoch),
+			TimeUnit:   timeUnit,
+		},
+	}
+	actual := plugin.buildMultiMeasureWriteRecords(input)
+	for _, r := range actual {
+		sort.Slice(r.MeasureValues, func(i, j int) bool {
+			return *r.MeasureValues[i].Name < *r.MeasureValues[j].Name
+		})
+	}
+	require.ElementsMatch(t, expected, actual)
+}
+
+func TestMultiMeasureNameSeparatorRequiresMultiMeasure(t *testing.T) {
+	plugin := Timestream{
+		MappingMode:               MappingModeMultiTable,
+		DatabaseName:              tsDbName,
+		MultiMeasureNameSeparator: "_",
+		Log:                       testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Connect(), "requires multi-measure records")
+}
+
+func TestTransformMetricsCommonAttributes(t *testing.T) {
+	inputs := []telegraf.Metric{
+		testutil.MustMetric(
+			metricName1,
+			map[string]string{"tag1": "value1"},
+			map[string]interface{}{"value": float64(10)},
+			time1,
+		),
+		testutil.MustMetric(
+			metricName1,
+			map[string]string{"tag1": "value1"},
+			map[string]interface{}{"value": float64(20)},
+			time2,
+		),
+		testutil.MustMetric(
+			metricName1,
+			map[string]string{"tag1": "value2"},
+			map[string]interface{}{"value": float64(30)},
+			time1,
+		),
+	}
+
+	plugin := Timestream{
+		MappingMode:                       MappingModeMultiTable,
+		DatabaseName:                      tsDbName,
+		UseMultiMeasureRecords:            true,
+		MeasureNameForMultiMeasureRecords: "measure",
+		UseCommonAttributes:               true,
+		Log:                               testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+
+	multiValue := func(v string) []types.MeasureValue {
+		return []types.MeasureValue{{Name: aws.String("value"), Type: types.MeasureValueTypeDouble, Value: aws.String(v)}}
+	}
+	expected := []*timestreamwrite.WriteRecordsInput{
+		{
+			// Series with different timestamps share the dimensions and measure name
+			DatabaseName: aws.String(tsDbName),
+			TableName:    aws.String(metricName1),
+			Records: []types.Record{
+				{MeasureValues: multiValue("10"), Time: aws.String(time1Epoch), TimeUnit: timeUnit},
+				{MeasureValues: multiValue("20"), Time: aws.String(time2Epoch), TimeUnit: timeUnit},
+			},
+			CommonAttributes: &types.Record{
+				MeasureName:      aws.String("measure"),
+				MeasureValueType: "MULTI",
+				Dimensions:       []types.Dimension{{Name: aws.String("tag1"), Value: aws.String("value1")}},
+			},
+		},
+		{
+			// Single records share all attributes
+			DatabaseName: aws.String(tsDbName),
+			TableName:    aws.String(metricName1),
+			Records: []types.Record{
+				{MeasureValues: multiValue("30")},
+			},
+			CommonAttributes: &types.Record{
+				MeasureName:      aws.String("measure"),
+				MeasureValueType: "MULTI",
+				Dimensions:       []types.Dimension{{Name: aws.String("tag1"), Value: aws.String("value2")}},
+				Time:             aws.String(time1Epoch),
+				TimeUnit:         timeUnit,
+			},
+		},
+	}
+	require.ElementsMatch(t, expected, plugin.TransformMetrics(inputs))
+}
+
+func TestTransformMetricsCommonAttributesAboveLimitAreSplit(t *testing.T) {
+	inputs := make([]telegraf.Metric, 0, MaxRecordsPerCall+1)
+	for i := range MaxRecordsPerCall + 1 {
+		inputs = append(inputs, testutil.MustMetric(
+			metricName1,
+			map[string]string{"tag1": "value1"},
+			map[string]interface{}{"value_" + strconv.Itoa(i): float64(i)},
+			time1,
+		))
+	}
+
+	plugin := Timestream{
+		MappingMode:         MappingModeMultiTable,
+		DatabaseName:        tsDbName,
+		UseCommonAttributes: true,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+
+	result := plugin.TransformMetrics(inputs)
+	require.Len(t, result, 2)
+	sort.Slice(result, func(i, j int) bool { return len(result[i].Records) > len(result[j].Records) })
+	require.Len(t, result[0].Records, MaxRecordsPerCall)
+	require.Len(t, result[1].Records, 1)
+	require.NotSame(t, result[0].CommonAttributes, result[1].CommonAttributes)
+
+	for _, r := range result {
+		// Each request carries its own common attributes
+		require.Equal(t, []types.Dimension{{Name: aws.String("tag1"), Value: aws.String("value1")}}, r.CommonAttributes.Dimensions)
+		require.Equal(t, time1Epoch, aws.ToString(r.CommonAttributes.Time))
+		require.Equal(t, types.MeasureValueTypeDouble, r.CommonAttributes.MeasureValueType)
+		for _, record := range r.Records {
+			require.Empty(t, record.Dimensions)
+			require.Nil(t, record.Time)
+		}
+	}
+
+	// The measure names differ between the records of the larger request
+	require.Nil(t, result[0].CommonAttributes.MeasureName)
+	for _, record := range result[0].Records {
+		require.NotNil(t, record.MeasureName)
+	}
+}
+
 func TestCustomEndpoint(t *testing.T) {
 	customEndpoint := "http://test.custom.endpoint.com"
 	plugin := Timestream{
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.cloudtrail
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/cloudtrail" // register plugin
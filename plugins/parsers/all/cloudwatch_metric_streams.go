@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.cloudwatch_metric_streams
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/cloudwatch_metric_streams" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.ion
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/ion" // register plugin
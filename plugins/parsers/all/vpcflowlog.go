@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.vpcflowlog
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/vpcflowlog" // register plugin
@@ -0,0 +1,191 @@
+package avro
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/google/uuid"
+	"github.com/linkedin/goavro/v2"
+
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+)
+
+// Framing of the AWS Glue Schema Registry serializer, see
+// https://github.com/awslabs/aws-glue-schema-registry
+const (
+	glueHeaderVersion   = 3
+	glueCompressionNone = 0
+	glueCompressionZlib = 5
+	glueHeaderLength    = 18
+)
+
+type glueRegistry struct {
+	endpoint string
+	region   string
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   *http.Client
+	timeout  time.Duration
+	cache    map[uuid.UUID]*schemaAndCodec
+	mu       sync.RWMutex
+}
+
+func newGlueRegistry(cfg *common_aws.CredentialConfig, timeout time.Duration) (*glueRegistry, error) {
+	if cfg.Region == "" {
+		return nil, errors.New("region required")
+	}
+
+	awsCfg, err := cfg.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting credentials failed: %w", err)
+	}
+
+	endpoint := cfg.EndpointURL
+	if endpoint == "" {
+		endpoint = "https://glue." + cfg.Region + ".amazonaws.com"
+	}
+
+	return &glueRegistry{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		region:   cfg.Region,
+		creds:    awsCfg.Credentials,
+		signer:   v4.NewSigner(),
+		client:   &http.Client{Timeout: timeout},
+		timeout:  timeout,
+		cache:    make(map[uuid.UUID]*schemaAndCodec),
+	}, nil
+}
+
+// isGlueFramed checks if the message is encoded using the Glue Schema
+// Registry wire format
+func isGlueFramed(buf []byte) bool {
+	return len(buf) >= glueHeaderLength && buf[0] == glueHeaderVersion
+}
+
+// decode strips the Glue header from the message, decompresses the data if
+// necessary and returns the payload together with the referenced schema
+func (gr *glueRegistry) decode(buf []byte) ([]byte, *schemaAndCodec, error) {
+	if !isGlueFramed(buf) {
+		return nil, nil, errors.New("message is not in Glue Schema Registry format")
+	}
+
+	id, err := uuid.FromBytes(buf[2:glueHeaderLength])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schema version ID: %w", err)
+	}
+
+	message := buf[glueHeaderLength:]
+	switch buf[1] {
+	case glueCompressionNone:
+	case glueCompressionZlib:
+		r, err := zlib.NewReader(bytes.NewReader(message))
+		if err != nil {
+			return nil, nil, fmt.Errorf("decompressing message failed: %w", err)
+		}
+		defer r.Close()
+		if message, err = io.ReadAll(r); err != nil {
+			return nil, nil, fmt.Errorf("decompressing message failed: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported compression type %d", buf[1])
+	}
+
+	schema, err := gr.getSchemaAndCodec(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return message, schema, nil
+}
+
+func (gr *glueRegistry) getSchemaAndCodec(id uuid.UUID) (*schemaAndCodec, error) {
+	gr.mu.RLock()
+	v, found := gr.cache[id]
+	gr.mu.RUnlock()
+	if found {
+		return v, nil
+	}
+
+	schema, err := gr.getSchemaVersion(id)
+	if err != nil {
+		return nil, fmt.Errorf("getting schema version %s failed: %w", id, err)
+	}
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	retval := &schemaAndCodec{Schema: schema, Codec: codec}
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+	gr.cache[id] = retval
+	return retval, nil
+}
+
+// getSchemaVersion queries the schema definition using the GetSchemaVersion
+// API of AWS Glue
+func (gr *glueRegistry) getSchemaVersion(id uuid.UUID) (string, error) {
+	body, err := json.Marshal(map[string]string{"SchemaVersionId": id.String()})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gr.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gr.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSGlue.GetSchemaVersion")
+
+	creds, err := gr.creds.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("retrieving credentials failed: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := gr.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "glue", gr.region, time.Now()); err != nil {
+		return "", fmt.Errorf("signing request failed: %w", err)
+	}
+
+	resp, err := gr.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("received status %q: %s", resp.Status, string(msg))
+	}
+
+	var response struct {
+		SchemaDefinition string `json:"SchemaDefinition"`
+		DataFormat       string `json:"DataFormat"`
+		Status           string `json:"Status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("decoding response failed: %w", err)
+	}
+	if response.DataFormat != "" && response.DataFormat != "AVRO" {
+		return "", fmt.Errorf("unsupported data format %q", response.DataFormat)
+	}
+	if response.SchemaDefinition == "" {
+		return "", errors.New("empty schema definition")
+	}
+
+	return response.SchemaDefinition, nil
+}
@@ -11,8 +11,10 @@ import (
 	"github.com/linkedin/goavro/v2"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
@@ -23,23 +25,36 @@ import (
 // If Schema is set, we assume the input will be Avro binary format, without
 // an attached schema or schema fingerprint
 
+// If SchemaRegistryType is "glue", we expect the input to be in AWS Glue
+// Schema Registry format and use SchemaRegistry or Schema, if set, as
+// secondary deserializer for messages not framed in that format.
+
 type Parser struct {
-	MetricName       string            `toml:"metric_name"`
-	SchemaRegistry   string            `toml:"avro_schema_registry"`
-	CaCertPath       string            `toml:"avro_schema_registry_cert"`
-	Schema           string            `toml:"avro_schema"`
-	Format           string            `toml:"avro_format"`
-	Measurement      string            `toml:"avro_measurement"`
-	MeasurementField string            `toml:"avro_measurement_field"`
-	Tags             []string          `toml:"avro_tags"`
-	Fields           []string          `toml:"avro_fields"`
-	Timestamp        string            `toml:"avro_timestamp"`
-	TimestampFormat  string            `toml:"avro_timestamp_format"`
-	FieldSeparator   string            `toml:"avro_field_separator"`
-	UnionMode        string            `toml:"avro_union_mode"`
-	DefaultTags      map[string]string `toml:"tags"`
-	Log              telegraf.Logger   `toml:"-"`
-	registryObj      *schemaRegistry
+	MetricName         string            `toml:"metric_name"`
+	SchemaRegistry     string            `toml:"avro_schema_registry"`
+	SchemaRegistryType string            `toml:"avro_schema_registry_type"`
+	CaCertPath         string            `toml:"avro_schema_registry_cert"`
+	GlueRegion         string            `toml:"avro_glue_region"`
+	GlueEndpointURL    string            `toml:"avro_glue_endpoint_url"`
+	GlueAccessKey      config.Secret     `toml:"avro_glue_access_key"`
+	GlueSecretKey      config.Secret     `toml:"avro_glue_secret_key"`
+	GlueRoleARN        string            `toml:"avro_glue_role_arn"`
+	GlueProfile        string            `toml:"avro_glue_profile"`
+	GlueTimeout        config.Duration   `toml:"avro_glue_timeout"`
+	Schema             string            `toml:"avro_schema"`
+	Format             string            `toml:"avro_format"`
+	Measurement        string            `toml:"avro_measurement"`
+	MeasurementField   string            `toml:"avro_measurement_field"`
+	Tags               []string          `toml:"avro_tags"`
+	Fields             []string          `toml:"avro_fields"`
+	Timestamp          string            `toml:"avro_timestamp"`
+	TimestampFormat    string            `toml:"avro_timestamp_format"`
+	FieldSeparator     string            `toml:"avro_field_separator"`
+	UnionMode          string            `toml:"avro_union_mode"`
+	DefaultTags        map[string]string `toml:"tags"`
+	Log                telegraf.Logger   `toml:"-"`
+	registryObj        *schemaRegistry
+	glueObj            *glueRegistry
 }
 
 func (p *Parser) Init() error {
@@ -60,8 +75,35 @@ func (p *Parser) Init() error {
 		return fmt.Errorf("unknown avro_union_mode %q", p.Format)
 	}
 
-	if (p.Schema == "" && p.SchemaRegistry == "") || (p.Schema != "" && p.SchemaRegistry != "") {
-		return errors.New("exactly one of 'schema_registry' or 'schema' must be specified")
+	switch p.SchemaRegistryType {
+	case "", "confluent":
+		p.SchemaRegistryType = "confluent"
+		if (p.Schema == "" && p.SchemaRegistry == "") || (p.Schema != "" && p.SchemaRegistry != "") {
+			return errors.New("exactly one of 'schema_registry' or 'schema' must be specified")
+		}
+	case "glue":
+		// The schema or schema registry are used as secondary deserializer
+		if p.Schema != "" && p.SchemaRegistry != "" {
+			return errors.New("at most one of 'schema_registry' or 'schema' can be specified")
+		}
+		if p.GlueTimeout <= 0 {
+			p.GlueTimeout = config.Duration(10 * time.Second)
+		}
+		cfg := &common_aws.CredentialConfig{
+			Region:      p.GlueRegion,
+			AccessKey:   p.GlueAccessKey,
+			SecretKey:   p.GlueSecretKey,
+			RoleARN:     p.GlueRoleARN,
+			Profile:     p.GlueProfile,
+			EndpointURL: p.GlueEndpointURL,
+		}
+		registry, err := newGlueRegistry(cfg, time.Duration(p.GlueTimeout))
+		if err != nil {
+			return fmt.Errorf("creating Glue schema registry client failed: %w", err)
+		}
+		p.glueObj = registry
+	default:
+		return fmt.Errorf("unknown 'avro_schema_registry_type' %q", p.SchemaRegistryType)
 	}
 	switch p.TimestampFormat {
 	case "":
@@ -89,7 +131,15 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	var message []byte
 	message = buf[:]
 
-	if p.registryObj != nil {
+	if p.glueObj != nil && (isGlueFramed(buf) || (p.Schema == "" && p.registryObj == nil)) {
+		var schemastruct *schemaAndCodec
+		message, schemastruct, err = p.glueObj.decode(buf)
+		if err != nil {
+			return nil, err
+		}
+		schema = schemastruct.Schema
+		codec = schemastruct.Codec
+	} else if p.registryObj != nil {
 		// The input must be Confluent Wire Protocol
 		if buf[0] != 0 {
 			return nil, errors.New("first byte is not 0: not Confluent Wire Protocol")
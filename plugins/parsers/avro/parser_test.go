@@ -1,15 +1,26 @@
 package avro
 
 import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/file"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -155,6 +166,121 @@ func TestBenchmarkDataBinary(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
 }
 
+func TestGlueSchemaRegistry(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "cpu",
+		"fields": [
+			{"name": "host", "type": "string"},
+			{"name": "value", "type": "double"}
+		]
+	}`
+	schemaID := uuid.New()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Amz-Target") != "AWSGlue.GetSchemaVersion" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var request map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request["SchemaVersionId"] != schemaID.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		response := map[string]string{
+			"SchemaVersionId":  schemaID.String(),
+			"SchemaDefinition": schema,
+			"DataFormat":       "AVRO",
+			"Status":           "AVAILABLE",
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Parser{
+		SchemaRegistryType: "glue",
+		Schema:             schema,
+		GlueRegion:         "us-east-1",
+		GlueEndpointURL:    server.URL,
+		GlueAccessKey:      config.NewSecret([]byte("key")),
+		GlueSecretKey:      config.NewSecret([]byte("secret")),
+		Fields:             []string{"value"},
+		Tags:               []string{"host"},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+	payload, err := codec.BinaryFromNative(nil, map[string]interface{}{"host": "server01", "value": 42.0})
+	require.NoError(t, err)
+
+	// Uncompressed message
+	uncompressed := append([]byte{glueHeaderVersion, glueCompressionNone}, schemaID[:]...)
+	uncompressed = append(uncompressed, payload...)
+
+	// Compressed message
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err = w.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	compressed := append([]byte{glueHeaderVersion, glueCompressionZlib}, schemaID[:]...)
+	compressed = append(compressed, buf.Bytes()...)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Messages without Glue header are handled by the secondary deserializer
+	for _, msg := range [][]byte{uncompressed, compressed, payload} {
+		actual, err := plugin.Parse(msg)
+		require.NoError(t, err)
+		testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	}
+
+	// The schema must be queried only once and taken from cache afterwards
+	require.Equal(t, int32(1), requests.Load())
+
+	// Unknown schema versions must fail
+	unknown := uuid.New()
+	msg := append([]byte{glueHeaderVersion, glueCompressionNone}, unknown[:]...)
+	_, err = plugin.Parse(append(msg, payload...))
+	require.ErrorContains(t, err, "getting schema version")
+}
+
+func TestGlueSchemaRegistryInvalid(t *testing.T) {
+	plugin := &Parser{SchemaRegistryType: "glue"}
+	require.ErrorContains(t, plugin.Init(), "region required")
+
+	plugin = &Parser{SchemaRegistryType: "foo"}
+	require.ErrorContains(t, plugin.Init(), "unknown 'avro_schema_registry_type'")
+
+	plugin = &Parser{
+		SchemaRegistryType: "glue",
+		GlueRegion:         "us-east-1",
+		GlueEndpointURL:    "http://localhost:1234",
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	_, err := plugin.Parse([]byte{0, 0, 0, 0, 1, 2})
+	require.ErrorContains(t, err, "not in Glue Schema Registry format")
+}
+
 func BenchmarkParsingBinary(b *testing.B) {
 	plugin := &Parser{
 		Measurement:     "benchmark",
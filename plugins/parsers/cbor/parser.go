@@ -0,0 +1,110 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
+)
+
+// Parser decodes CBOR data and extracts the metrics using the json_v2
+// configuration.
+type Parser struct {
+	json_v2.Parser
+
+	decMode cbor.DecMode
+}
+
+func (p *Parser) Init() error {
+	mode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[interface{}]interface{}(nil)),
+		TimeTagToAny:   cbor.TimeTagToRFC3339Nano,
+		BigIntDec:      cbor.BigIntDecodePointer,
+	}.DecMode()
+	if err != nil {
+		return fmt.Errorf("creating decoder failed: %w", err)
+	}
+	p.decMode = mode
+
+	return p.Parser.Init()
+}
+
+// Parse converts each data item of the CBOR sequence into its JSON
+// equivalent and extracts the metrics using the json_v2 parser.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	decoder := p.decMode.NewDecoder(bytes.NewReader(buf))
+
+	var metrics []telegraf.Metric
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding CBOR data failed: %w", err)
+		}
+
+		doc, err := json.Marshal(convert(value))
+		if err != nil {
+			return nil, fmt.Errorf("converting CBOR data failed: %w", err)
+		}
+		m, err := p.Parser.Parse(doc)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (*Parser) ParseLine(string) (telegraf.Metric, error) {
+	return nil, errors.New("parsing line is not supported by CBOR format")
+}
+
+// convert turns the decoded CBOR value into a value encodable as JSON. Map
+// keys are converted to strings, tags other than timestamps are replaced by
+// their content and non-finite floats are dropped.
+func convert(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = convert(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convert(e)
+		}
+		return v
+	case cbor.Tag:
+		return convert(v.Content)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+func init() {
+	parsers.Add("cbor",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{Parser: json_v2.Parser{DefaultMetricName: defaultMetricName}}
+		},
+	)
+}
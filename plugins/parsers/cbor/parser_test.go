@@ -0,0 +1,88 @@
+package cbor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+
+		t.Run(f.Name(), func(t *testing.T) {
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfig(configFilename))
+			require.Len(t, cfg.Inputs, 1)
+
+			plugin := cfg.Inputs[0].Input.(*test.Plugin)
+			plugin.Path = testcasePath
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			err := plugin.Gather(&acc)
+			if len(plugin.ExpectedErrors) > 0 {
+				require.ErrorContains(t, err, plugin.ExpectedErrors[0])
+			} else {
+				require.NoError(t, err)
+			}
+
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseTimeTag(t *testing.T) {
+	encoder, err := cbor.EncOptions{Time: cbor.TimeUnix, TimeTag: cbor.EncTagRequired}.EncMode()
+	require.NoError(t, err)
+	buf, err := encoder.Marshal(map[string]interface{}{
+		"sensor":      "temp-01",
+		"time":        time.Date(2024, 3, 6, 21, 22, 54, 0, time.UTC),
+		"temperature": float32(21.5),
+	})
+	require.NoError(t, err)
+
+	parser := &Parser{
+		Parser: json_v2.Parser{
+			DefaultMetricName: "cbor",
+			Configs: []json_v2.Config{
+				{
+					TimestampPath:   "time",
+					TimestampFormat: "rfc3339",
+					Tags:            []json_v2.DataSet{{Path: "sensor"}},
+					Fields:          []json_v2.DataSet{{Path: "temperature"}},
+				},
+			},
+		},
+	}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cbor",
+			map[string]string{"sensor": "temp-01"},
+			map[string]interface{}{"temperature": 21.5},
+			time.Date(2024, 3, 6, 21, 22, 54, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
@@ -0,0 +1,223 @@
+package cloudtrail
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	parsers_json "github.com/influxdata/telegraf/plugins/parsers/json"
+)
+
+// Parser decodes AWS CloudTrail events into metrics.
+type Parser struct {
+	RequestParameters []string          `toml:"cloudtrail_request_parameters"`
+	ResponseElements  []string          `toml:"cloudtrail_response_elements"`
+	DefaultTags       map[string]string `toml:"-"`
+
+	metricName     string
+	requestFilter  filter.Filter
+	responseFilter filter.Filter
+}
+
+type bundle struct {
+	Records []*event `json:"Records"`
+}
+
+type event struct {
+	EventVersion       string                 `json:"eventVersion"`
+	EventTime          time.Time              `json:"eventTime"`
+	EventSource        string                 `json:"eventSource"`
+	EventName          string                 `json:"eventName"`
+	EventType          string                 `json:"eventType"`
+	EventCategory      string                 `json:"eventCategory"`
+	EventID            string                 `json:"eventID"`
+	AWSRegion          string                 `json:"awsRegion"`
+	SourceIPAddress    string                 `json:"sourceIPAddress"`
+	UserAgent          string                 `json:"userAgent"`
+	ErrorCode          string                 `json:"errorCode"`
+	ErrorMessage       string                 `json:"errorMessage"`
+	RequestID          string                 `json:"requestID"`
+	ReadOnly           *bool                  `json:"readOnly"`
+	ManagementEvent    *bool                  `json:"managementEvent"`
+	RecipientAccountID string                 `json:"recipientAccountId"`
+	UserIdentity       userIdentity           `json:"userIdentity"`
+	RequestParameters  map[string]interface{} `json:"requestParameters"`
+	ResponseElements   map[string]interface{} `json:"responseElements"`
+}
+
+type userIdentity struct {
+	Type           string `json:"type"`
+	PrincipalID    string `json:"principalId"`
+	ARN            string `json:"arn"`
+	AccountID      string `json:"accountId"`
+	UserName       string `json:"userName"`
+	InvokedBy      string `json:"invokedBy"`
+	SessionContext struct {
+		SessionIssuer struct {
+			UserName string `json:"userName"`
+		} `json:"sessionIssuer"`
+	} `json:"sessionContext"`
+}
+
+func (p *Parser) Init() error {
+	var err error
+	if p.requestFilter, err = filter.Compile(p.RequestParameters); err != nil {
+		return fmt.Errorf("invalid 'cloudtrail_request_parameters': %w", err)
+	}
+	if p.responseFilter, err = filter.Compile(p.ResponseElements); err != nil {
+		return fmt.Errorf("invalid 'cloudtrail_response_elements': %w", err)
+	}
+	return nil
+}
+
+// Parse converts the events of a CloudTrail log file, i.e. an object with a
+// 'Records' array, or a single event into metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	buf = bytes.TrimSpace(buf)
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	var b bundle
+	if err := json.Unmarshal(buf, &b); err != nil {
+		return nil, fmt.Errorf("decoding events failed: %w", err)
+	}
+	if b.Records == nil {
+		var e event
+		if err := json.Unmarshal(buf, &e); err != nil {
+			return nil, fmt.Errorf("decoding event failed: %w", err)
+		}
+		if e.EventName == "" {
+			return nil, errors.New("neither 'Records' nor an event found")
+		}
+		b.Records = []*event{&e}
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(b.Records))
+	for i, e := range b.Records {
+		if e == nil || e.EventName == "" {
+			return nil, fmt.Errorf("record %d is not an event", i)
+		}
+		m, err := p.toMetric(e)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single event into a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) < 1 {
+		return nil, errors.New("no metric in line")
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) toMetric(e *event) (telegraf.Metric, error) {
+	tags := make(map[string]string, len(p.DefaultTags)+12)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	addTag(tags, "event_source", e.EventSource)
+	addTag(tags, "event_name", e.EventName)
+	addTag(tags, "event_type", e.EventType)
+	addTag(tags, "event_category", e.EventCategory)
+	addTag(tags, "aws_region", e.AWSRegion)
+	addTag(tags, "recipient_account_id", e.RecipientAccountID)
+	addTag(tags, "error_code", e.ErrorCode)
+	addTag(tags, "user_type", e.UserIdentity.Type)
+	addTag(tags, "user_arn", e.UserIdentity.ARN)
+	addTag(tags, "user_account_id", e.UserIdentity.AccountID)
+	addTag(tags, "user_principal_id", e.UserIdentity.PrincipalID)
+	addTag(tags, "user_invoked_by", e.UserIdentity.InvokedBy)
+	if e.UserIdentity.UserName != "" {
+		tags["user_name"] = e.UserIdentity.UserName
+	} else {
+		// Use the role name for assumed roles
+		addTag(tags, "user_name", e.UserIdentity.SessionContext.SessionIssuer.UserName)
+	}
+
+	fields := make(map[string]interface{})
+	addField(fields, "event_id", e.EventID)
+	addField(fields, "event_version", e.EventVersion)
+	addField(fields, "request_id", e.RequestID)
+	addField(fields, "source_ip_address", e.SourceIPAddress)
+	addField(fields, "user_agent", e.UserAgent)
+	addField(fields, "error_message", e.ErrorMessage)
+	if e.ReadOnly != nil {
+		fields["read_only"] = *e.ReadOnly
+	}
+	if e.ManagementEvent != nil {
+		fields["management_event"] = *e.ManagementEvent
+	}
+
+	if err := addParameters(fields, "request_", e.RequestParameters, p.requestFilter); err != nil {
+		return nil, fmt.Errorf("flattening request parameters failed: %w", err)
+	}
+	if err := addParameters(fields, "response_", e.ResponseElements, p.responseFilter); err != nil {
+		return nil, fmt.Errorf("flattening response elements failed: %w", err)
+	}
+
+	timestamp := e.EventTime
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return metric.New(p.metricName, tags, fields, timestamp), nil
+}
+
+// addParameters flattens the parameters and adds those matching the filter
+// with the given prefix. Nothing is added without a filter.
+func addParameters(fields map[string]interface{}, prefix string, params map[string]interface{}, f filter.Filter) error {
+	if f == nil || len(params) == 0 {
+		return nil
+	}
+
+	flattener := parsers_json.JSONFlattener{}
+	if err := flattener.FullFlattenJSON("", params, true, true); err != nil {
+		return err
+	}
+	for k, v := range flattener.Fields {
+		if f.Match(k) {
+			fields[prefix+k] = v
+		}
+	}
+	return nil
+}
+
+func addTag(tags map[string]string, key, value string) {
+	if value != "" {
+		tags[key] = value
+	}
+}
+
+func addField(fields map[string]interface{}, key, value string) {
+	if value != "" {
+		fields[key] = value
+	}
+}
+
+func init() {
+	parsers.Add("cloudtrail",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,88 @@
+package cloudtrail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+
+		t.Run(f.Name(), func(t *testing.T) {
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfig(configFilename))
+			require.Len(t, cfg.Inputs, 1)
+
+			plugin := cfg.Inputs[0].Input.(*test.Plugin)
+			plugin.Path = testcasePath
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			err := plugin.Gather(&acc)
+			if len(plugin.ExpectedErrors) > 0 {
+				require.ErrorContains(t, err, plugin.ExpectedErrors[0])
+			} else {
+				require.NoError(t, err)
+			}
+
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{metricName: "cloudtrail", RequestParameters: []string{"bucketName"}}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "test"})
+
+	m, err := parser.ParseLine(`{"eventTime":"2024-03-06T21:22:54Z","eventSource":"s3.amazonaws.com","eventName":"GetBucketAcl",` +
+		`"awsRegion":"us-east-1","userIdentity":{"type":"IAMUser","userName":"Bob"},"requestParameters":{"bucketName":"logs","acl":""}}`)
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"cloudtrail",
+		map[string]string{
+			"source":       "test",
+			"event_source": "s3.amazonaws.com",
+			"event_name":   "GetBucketAcl",
+			"aws_region":   "us-east-1",
+			"user_type":    "IAMUser",
+			"user_name":    "Bob",
+		},
+		map[string]interface{}{
+			"request_bucketName": "logs",
+		},
+		time.Date(2024, 3, 6, 21, 22, 54, 0, time.UTC),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{m})
+}
+
+func TestInvalid(t *testing.T) {
+	parser := &Parser{RequestParameters: []string{"["}}
+	require.ErrorContains(t, parser.Init(), "invalid 'cloudtrail_request_parameters'")
+
+	parser = &Parser{}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse([]byte(`{"Records": [`))
+	require.ErrorContains(t, err, "decoding events failed")
+	_, err = parser.Parse([]byte(`{"foo": "bar"}`))
+	require.ErrorContains(t, err, "neither 'Records' nor an event found")
+	_, err = parser.Parse([]byte(`{"eventName": "Foo", "eventTime": "yesterday"}`))
+	require.ErrorContains(t, err, "decoding event")
+}
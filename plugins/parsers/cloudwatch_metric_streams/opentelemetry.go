@@ -0,0 +1,316 @@
+package cloudwatch_metric_streams
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The OpenTelemetry 0.7 format uses the deprecated 'DoubleSummary' metric
+// type with string labels removed from later versions of the protocol.
+// Therefore, the messages are decoded manually using the field numbers of
+// https://github.com/open-telemetry/opentelemetry-proto/blob/v0.7.0/opentelemetry/proto/metrics/v1/metrics.proto
+const (
+	fieldResourceMetrics = 1 // ExportMetricsServiceRequest
+
+	fieldResource                = 1 // ResourceMetrics
+	fieldInstrumentationLibrary  = 2 // ResourceMetrics
+	fieldResourceAttributes      = 1 // Resource
+	fieldLibraryMetrics          = 2 // InstrumentationLibraryMetrics
+	fieldMetricName              = 1 // Metric
+	fieldMetricUnit              = 3 // Metric
+	fieldMetricDoubleSummary     = 11
+	fieldSummaryDataPoints       = 1 // DoubleSummary
+	fieldDataPointLabels         = 1 // DoubleSummaryDataPoint
+	fieldDataPointTime           = 3
+	fieldDataPointCount          = 4
+	fieldDataPointSum            = 5
+	fieldDataPointQuantileValues = 6
+	fieldKey                     = 1 // KeyValue, StringKeyValue
+	fieldValue                   = 2 // KeyValue, StringKeyValue
+	fieldAnyValueString          = 1 // AnyValue
+	fieldQuantile                = 1 // ValueAtQuantile
+	fieldQuantileValue           = 2
+)
+
+// metricNamePrefix is prepended to the namespace and metric name by AWS
+const metricNamePrefix = "amazonaws.com/"
+
+// decodeOpenTelemetry decodes the size-delimited export requests
+func decodeOpenTelemetry(buf []byte) ([]*datum, error) {
+	var data []*datum
+	for len(buf) > 0 {
+		msg, n := protowire.ConsumeBytes(buf)
+		if n < 0 {
+			return nil, fmt.Errorf("decoding size of request failed: %w", protowire.ParseError(n))
+		}
+		buf = buf[n:]
+
+		err := consumeFields(msg, func(num protowire.Number, typ protowire.Type, value []byte) error {
+			if num != fieldResourceMetrics || typ != protowire.BytesType {
+				return nil
+			}
+			d, err := decodeResourceMetrics(value)
+			if err != nil {
+				return err
+			}
+			data = append(data, d...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("decoding request failed: %w", err)
+		}
+	}
+	return data, nil
+}
+
+func decodeResourceMetrics(buf []byte) ([]*datum, error) {
+	var attributes map[string]string
+	var metrics [][]byte
+	err := consumeFields(buf, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case fieldResource:
+			var err error
+			attributes, err = decodeAttributes(value, fieldResourceAttributes)
+			return err
+		case fieldInstrumentationLibrary:
+			// Resource attributes might follow the metrics, so decoding the
+			// metrics is deferred
+			return consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if num == fieldLibraryMetrics && typ == protowire.BytesType {
+					metrics = append(metrics, value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var data []*datum
+	for _, m := range metrics {
+		d, err := decodeMetric(m, attributes)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, d...)
+	}
+	return data, nil
+}
+
+func decodeMetric(buf []byte, resource map[string]string) ([]*datum, error) {
+	var name, unit string
+	var points [][]byte
+	err := consumeFields(buf, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case fieldMetricName:
+			name = string(value)
+		case fieldMetricUnit:
+			unit = string(value)
+		case fieldMetricDoubleSummary:
+			return consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if num == fieldSummaryDataPoints && typ == protowire.BytesType {
+					points = append(points, value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*datum, 0, len(points))
+	for _, p := range points {
+		d, err := decodeDataPoint(p)
+		if err != nil {
+			return nil, fmt.Errorf("decoding data point of %q failed: %w", name, err)
+		}
+		d.AccountID = resource["cloud.account.id"]
+		d.Region = resource["cloud.region"]
+		d.Unit = unit
+
+		// Fall back to the metric name if the labels are missing
+		if d.Namespace == "" || d.MetricName == "" {
+			idx := strings.LastIndex(name, "/")
+			if !strings.HasPrefix(name, metricNamePrefix) || idx < len(metricNamePrefix) {
+				return nil, fmt.Errorf("cannot determine namespace of metric %q", name)
+			}
+			d.Namespace = name[len(metricNamePrefix):idx]
+			d.MetricName = name[idx+1:]
+		}
+		data = append(data, d)
+	}
+	return data, nil
+}
+
+func decodeDataPoint(buf []byte) (*datum, error) {
+	d := &datum{
+		Dimensions: make(map[string]string),
+		Value:      make(map[string]float64, 4),
+	}
+	err := consumeFields(buf, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case num == fieldDataPointLabels && typ == protowire.BytesType:
+			key, label, err := decodeKeyValue(value)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "Namespace":
+				d.Namespace = label
+			case "MetricName":
+				d.MetricName = label
+			case "Dimensions":
+				parseDimensions(label, d.Dimensions)
+			default:
+				d.Dimensions[key] = label
+			}
+		case num == fieldDataPointTime && typ == protowire.Fixed64Type:
+			v, _ := protowire.ConsumeFixed64(value)
+			d.Timestamp = int64(v / 1e6)
+		case num == fieldDataPointCount && typ == protowire.Fixed64Type:
+			v, _ := protowire.ConsumeFixed64(value)
+			d.Value["count"] = float64(v)
+		case num == fieldDataPointSum && typ == protowire.Fixed64Type:
+			v, _ := protowire.ConsumeFixed64(value)
+			d.Value["sum"] = math.Float64frombits(v)
+		case num == fieldDataPointQuantileValues && typ == protowire.BytesType:
+			quantile, v, err := decodeQuantile(value)
+			if err != nil {
+				return err
+			}
+			// The minimum and maximum are reported as 0 and 1 quantiles
+			switch quantile {
+			case 0:
+				d.Value["min"] = v
+			case 1:
+				d.Value["max"] = v
+			}
+		}
+		return nil
+	})
+	return d, err
+}
+
+// parseDimensions parses dimensions formatted as "{Name=Value, Name=Value}"
+func parseDimensions(s string, dimensions map[string]string) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	for _, pair := range strings.Split(s, ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && k != "" {
+			dimensions[k] = v
+		}
+	}
+}
+
+// decodeAttributes decodes the attributes with string values contained in
+// the given field
+func decodeAttributes(buf []byte, field protowire.Number) (map[string]string, error) {
+	attributes := make(map[string]string)
+	err := consumeFields(buf, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != field || typ != protowire.BytesType {
+			return nil
+		}
+		var key, attribute string
+		err := consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+			if typ != protowire.BytesType {
+				return nil
+			}
+			switch num {
+			case fieldKey:
+				key = string(value)
+			case fieldValue:
+				return consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+					if num == fieldAnyValueString && typ == protowire.BytesType {
+						attribute = string(value)
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		attributes[key] = attribute
+		return nil
+	})
+	return attributes, err
+}
+
+func decodeKeyValue(buf []byte) (key, value string, err error) {
+	err = consumeFields(buf, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case fieldKey:
+			key = string(v)
+		case fieldValue:
+			value = string(v)
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+func decodeQuantile(buf []byte) (quantile, value float64, err error) {
+	err = consumeFields(buf, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.Fixed64Type {
+			return nil
+		}
+		bits, _ := protowire.ConsumeFixed64(v)
+		switch num {
+		case fieldQuantile:
+			quantile = math.Float64frombits(bits)
+		case fieldQuantileValue:
+			value = math.Float64frombits(bits)
+		}
+		return nil
+	})
+	return quantile, value, err
+}
+
+// consumeFields calls the function for each field of the message with the
+// field's value, i.e. the content of length-delimited fields or the raw
+// bytes of all other types
+func consumeFields(buf []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(buf)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+			if n >= 0 {
+				value = buf[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package cloudwatch_metric_streams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Parser decodes the records of AWS CloudWatch Metric Streams in JSON or
+// OpenTelemetry 0.7 format into metrics.
+type Parser struct {
+	Format           string            `toml:"cloudwatch_metric_streams_format"`
+	APICompatibility bool              `toml:"cloudwatch_metric_streams_api_compatibility"`
+	DefaultTags      map[string]string `toml:"-"`
+}
+
+// datum is a single metric value of the stream, see
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-json.html
+type datum struct {
+	MetricStreamName string             `json:"metric_stream_name"`
+	AccountID        string             `json:"account_id"`
+	Region           string             `json:"region"`
+	Namespace        string             `json:"namespace"`
+	MetricName       string             `json:"metric_name"`
+	Dimensions       map[string]string  `json:"dimensions"`
+	Timestamp        int64              `json:"timestamp"`
+	Value            map[string]float64 `json:"value"`
+	Unit             string             `json:"unit"`
+}
+
+func (p *Parser) Init() error {
+	switch p.Format {
+	case "":
+		p.Format = "auto"
+	case "auto", "json", "opentelemetry0.7":
+	default:
+		return fmt.Errorf("invalid 'cloudwatch_metric_streams_format' %q", p.Format)
+	}
+	return nil
+}
+
+// Parse converts all records contained in the buffer. Records in JSON format
+// are separated by newlines while records in OpenTelemetry format are
+// prefixed by their size.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	format := p.Format
+	if format == "auto" {
+		trimmed := bytes.TrimSpace(buf)
+		if len(trimmed) == 0 {
+			return nil, nil
+		}
+		format = "opentelemetry0.7"
+		if trimmed[0] == '{' {
+			format = "json"
+		}
+	}
+
+	var data []*datum
+	var err error
+	switch format {
+	case "json":
+		data, err = decodeJSON(buf)
+	case "opentelemetry0.7":
+		data, err = decodeOpenTelemetry(buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(data))
+	for _, d := range data {
+		metrics = append(metrics, p.toMetric(d))
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single record into a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) < 1 {
+		return nil, errors.New("no metric in line")
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func decodeJSON(buf []byte) ([]*datum, error) {
+	var data []*datum
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	for {
+		var d datum
+		if err := decoder.Decode(&d); err != nil {
+			if errors.Is(err, io.EOF) {
+				return data, nil
+			}
+			return nil, fmt.Errorf("decoding record %d failed: %w", len(data), err)
+		}
+		if d.Namespace == "" || d.MetricName == "" {
+			return nil, fmt.Errorf("record %d is missing the namespace or metric name", len(data))
+		}
+		data = append(data, &d)
+	}
+}
+
+// toMetric converts the datum using the same naming as the
+// cloudwatch_metric_streams input plugin
+func (p *Parser) toMetric(d *datum) telegraf.Metric {
+	namespace := strings.ReplaceAll(d.Namespace, "/", "_")
+	name := strings.ToLower(namespace + "_" + d.MetricName)
+
+	tags := make(map[string]string, len(p.DefaultTags)+len(d.Dimensions)+2)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	if d.AccountID != "" {
+		tags["accountId"] = d.AccountID
+	}
+	if d.Region != "" {
+		tags["region"] = d.Region
+	}
+	for k, v := range d.Dimensions {
+		tags[k] = v
+	}
+
+	fields := make(map[string]interface{}, len(d.Value))
+	for k, v := range d.Value {
+		// Rename the statistics to match the CloudWatch API
+		if p.APICompatibility {
+			switch k {
+			case "max":
+				k = "maximum"
+			case "min":
+				k = "minimum"
+			case "count":
+				k = "samplecount"
+			}
+		}
+		fields[k] = v
+	}
+
+	return metric.New(name, tags, fields, time.UnixMilli(d.Timestamp))
+}
+
+func init() {
+	parsers.Add("cloudwatch_metric_streams",
+		func(string) telegraf.Parser {
+			return &Parser{}
+		},
+	)
+}
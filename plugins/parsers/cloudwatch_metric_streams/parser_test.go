@@ -0,0 +1,191 @@
+package cloudwatch_metric_streams
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+
+		t.Run(f.Name(), func(t *testing.T) {
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfig(configFilename))
+			require.Len(t, cfg.Inputs, 1)
+
+			plugin := cfg.Inputs[0].Input.(*test.Plugin)
+			plugin.Path = testcasePath
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			err := plugin.Gather(&acc)
+			if len(plugin.ExpectedErrors) > 0 {
+				require.ErrorContains(t, err, plugin.ExpectedErrors[0])
+			} else {
+				require.NoError(t, err)
+			}
+
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+// The helpers below encode messages in OpenTelemetry 0.7 format
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	return appendMessage(b, num, []byte(s))
+}
+
+func appendFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
+
+func appendAttribute(b []byte, key, value string) []byte {
+	kv := appendString(nil, fieldKey, key)
+	kv = appendMessage(kv, fieldValue, appendString(nil, fieldAnyValueString, value))
+	return appendMessage(b, fieldResourceAttributes, kv)
+}
+
+func appendLabel(b []byte, key, value string) []byte {
+	kv := appendString(nil, fieldKey, key)
+	kv = appendString(kv, fieldValue, value)
+	return appendMessage(b, fieldDataPointLabels, kv)
+}
+
+func appendQuantile(b []byte, quantile, value float64) []byte {
+	q := appendFixed64(nil, fieldQuantile, math.Float64bits(quantile))
+	q = appendFixed64(q, fieldQuantileValue, math.Float64bits(value))
+	return appendMessage(b, fieldDataPointQuantileValues, q)
+}
+
+func exportRequest(labels [][2]string, ts time.Time) []byte {
+	var dp []byte
+	for _, l := range labels {
+		dp = appendLabel(dp, l[0], l[1])
+	}
+	dp = appendFixed64(dp, 2, uint64(ts.Add(-time.Minute).UnixNano()))
+	dp = appendFixed64(dp, fieldDataPointTime, uint64(ts.UnixNano()))
+	dp = appendFixed64(dp, fieldDataPointCount, 3)
+	dp = appendFixed64(dp, fieldDataPointSum, math.Float64bits(20))
+	dp = appendQuantile(dp, 0, 0)
+	dp = appendQuantile(dp, 1, 18)
+
+	m := appendString(nil, fieldMetricName, "amazonaws.com/AWS/EC2/DiskWriteOps")
+	m = appendString(m, fieldMetricUnit, "s")
+	m = appendMessage(m, fieldMetricDoubleSummary, appendMessage(nil, fieldSummaryDataPoints, dp))
+
+	var resource []byte
+	resource = appendAttribute(resource, "cloud.provider", "aws")
+	resource = appendAttribute(resource, "cloud.account.id", "1234567890")
+	resource = appendAttribute(resource, "cloud.region", "us-east-1")
+
+	rm := appendMessage(nil, fieldResource, resource)
+	rm = appendMessage(rm, fieldInstrumentationLibrary, appendMessage(nil, fieldLibraryMetrics, m))
+
+	return appendMessage(nil, fieldResourceMetrics, rm)
+}
+
+func TestOpenTelemetry(t *testing.T) {
+	ts := time.Unix(1611929698, 0)
+
+	// Multiple requests are delimited by their size
+	var buf []byte
+	buf = protowire.AppendBytes(buf, exportRequest([][2]string{
+		{"Namespace", "AWS/EC2"},
+		{"MetricName", "DiskWriteOps"},
+		{"Dimensions", "{InstanceId=i-123456789012}"},
+	}, ts))
+	buf = protowire.AppendBytes(buf, exportRequest([][2]string{
+		{"InstanceId", "i-210987654321"},
+	}, ts))
+
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "stream"})
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"aws_ec2_diskwriteops",
+			map[string]string{
+				"source":     "stream",
+				"accountId":  "1234567890",
+				"region":     "us-east-1",
+				"InstanceId": "i-123456789012",
+			},
+			map[string]interface{}{"count": 3.0, "sum": 20.0, "min": 0.0, "max": 18.0},
+			ts,
+		),
+		metric.New(
+			"aws_ec2_diskwriteops",
+			map[string]string{
+				"source":     "stream",
+				"accountId":  "1234567890",
+				"region":     "us-east-1",
+				"InstanceId": "i-210987654321",
+			},
+			map[string]interface{}{"count": 3.0, "sum": 20.0, "min": 0.0, "max": 18.0},
+			ts,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestOpenTelemetryInvalid(t *testing.T) {
+	parser := &Parser{Format: "opentelemetry0.7"}
+	require.NoError(t, parser.Init())
+
+	buf := protowire.AppendBytes(nil, exportRequest(nil, time.Now()))
+	_, err := parser.Parse(buf[:len(buf)-3])
+	require.ErrorContains(t, err, "decoding size of request failed")
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+
+	m, err := parser.ParseLine(`{"account_id":"1234567890","region":"eu-west-1","namespace":"AWS/Lambda",` +
+		`"metric_name":"Errors","dimensions":{"FunctionName":"api"},"timestamp":1611929698000,"value":{"sum":2.0}}`)
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"aws_lambda_errors",
+		map[string]string{
+			"accountId":    "1234567890",
+			"region":       "eu-west-1",
+			"FunctionName": "api",
+		},
+		map[string]interface{}{"sum": 2.0},
+		time.Unix(1611929698, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{m})
+}
+
+func TestInitInvalid(t *testing.T) {
+	parser := &Parser{Format: "opentelemetry1.0"}
+	require.ErrorContains(t, parser.Init(), `invalid 'cloudwatch_metric_streams_format' "opentelemetry1.0"`)
+}
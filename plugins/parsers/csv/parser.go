@@ -49,6 +49,9 @@ type Parser struct {
 	MetadataSeparators []string        `toml:"csv_metadata_separators"`
 	MetadataTrimSet    string          `toml:"csv_metadata_trim_set"`
 	ResetMode          string          `toml:"csv_reset_mode"`
+	HeaderPerRecord    bool            `toml:"csv_header_per_record"`
+	SchemaTag          string          `toml:"csv_schema_tag"`
+	Schemas            []Schema        `toml:"csv_schema"`
 	Log                telegraf.Logger `toml:"-"`
 
 	metadataSeparatorList metadataPattern
@@ -66,6 +69,19 @@ type Parser struct {
 	remainingSkipRows     int
 	remainingHeaderRows   int
 	remainingMetadataRows int
+
+	schemas map[string]*Parser
+}
+
+// Schema is a named set of column definitions selected per record
+type Schema struct {
+	Name              string   `toml:"name"`
+	ColumnNames       []string `toml:"column_names"`
+	ColumnTypes       []string `toml:"column_types"`
+	TagColumns        []string `toml:"tag_columns"`
+	MeasurementColumn string   `toml:"measurement_column"`
+	TimestampColumn   string   `toml:"timestamp_column"`
+	TimestampFormat   string   `toml:"timestamp_format"`
 }
 
 type metadataPattern []string
@@ -138,7 +154,7 @@ func (p *Parser) Reset() {
 }
 
 func (p *Parser) Init() error {
-	if p.HeaderRowCount == 0 && len(p.ColumnNames) == 0 {
+	if p.HeaderRowCount == 0 && len(p.ColumnNames) == 0 && len(p.Schemas) == 0 {
 		return errors.New("`csv_header_row_count` must be defined if `csv_column_names` is not specified")
 	}
 
@@ -178,6 +194,56 @@ func (p *Parser) Init() error {
 		p.location = loc
 	}
 
+	if p.HeaderPerRecord && p.HeaderRowCount == 0 {
+		return errors.New("`csv_header_per_record` requires `csv_header_row_count` to be set")
+	}
+
+	if len(p.Schemas) > 0 {
+		if p.SchemaTag == "" {
+			p.SchemaTag = "schema"
+		}
+		p.schemas = make(map[string]*Parser, len(p.Schemas))
+		for i, cfg := range p.Schemas {
+			if cfg.Name == "" {
+				return fmt.Errorf("schema %d: missing name", i+1)
+			}
+			if _, found := p.schemas[cfg.Name]; found {
+				return fmt.Errorf("duplicate schema %q", cfg.Name)
+			}
+			if len(cfg.ColumnNames) == 0 {
+				return fmt.Errorf("schema %q: no column names given", cfg.Name)
+			}
+			if len(cfg.ColumnTypes) > 0 && len(cfg.ColumnNames) != len(cfg.ColumnTypes) {
+				return fmt.Errorf("schema %q: column names count doesn't match with column types", cfg.Name)
+			}
+
+			// The schema uses the settings of the parser except for the
+			// column definitions
+			schema := &Parser{
+				ColumnNames:       cfg.ColumnNames,
+				ColumnTypes:       cfg.ColumnTypes,
+				TagColumns:        cfg.TagColumns,
+				MeasurementColumn: cfg.MeasurementColumn,
+				MetricName:        p.MetricName,
+				SkipColumns:       p.SkipColumns,
+				TagOverwrite:      p.TagOverwrite,
+				TimestampColumn:   cfg.TimestampColumn,
+				TimestampFormat:   cfg.TimestampFormat,
+				TrimSpace:         p.TrimSpace,
+				SkipValues:        p.SkipValues,
+				TimeFunc:          p.TimeFunc,
+				location:          p.location,
+			}
+			if schema.TimestampColumn == "" {
+				schema.TimestampColumn = p.TimestampColumn
+			}
+			if schema.TimestampFormat == "" {
+				schema.TimestampFormat = p.TimestampFormat
+			}
+			p.schemas[cfg.Name] = schema
+		}
+	}
+
 	if p.ResetMode == "" {
 		p.ResetMode = "none"
 	}
@@ -237,6 +303,46 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return metrics, err
 }
 
+// ParseStream parses the CSV data from the reader record by record and calls
+// the given function for each metric. If the delimiter is not supported by
+// the CSV reader, the complete data is read into memory.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	if p.invalidDelimiter {
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		metrics, err := p.Parse(buf)
+		if err != nil {
+			if errors.Is(err, parsers.ErrEOF) {
+				return nil
+			}
+			return err
+		}
+		for _, m := range metrics {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Reset the parser according to the specified mode
+	if p.ResetMode == "always" {
+		p.Reset()
+	}
+
+	csvReader, err := prepareCSV(p, r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	return p.readRecords(csvReader, fn)
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	if len(line) == 0 {
 		if p.remainingSkipRows > 0 {
@@ -265,7 +371,9 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return nil, nil
 }
 
-func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
+// prepareCSV consumes the skipped, metadata and header rows and returns the
+// reader for the remaining records
+func prepareCSV(p *Parser, r io.Reader) (*csv.Reader, error) {
 	lineReader := bufio.NewReader(r)
 	// skip first rows
 	for p.remainingSkipRows > 0 {
@@ -288,6 +396,15 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 		}
 	}
 	csvReader := p.compile(lineReader)
+	if err := p.readHeader(csvReader); err != nil {
+		return nil, err
+	}
+
+	return csvReader, nil
+}
+
+// readHeader consumes the remaining header rows and sets the column names
+func (p *Parser) readHeader(csvReader *csv.Reader) error {
 	// if there is a header, and we did not get DataColumns
 	// set DataColumns to names extracted from the header
 	// we always reread the header to avoid side effects
@@ -296,7 +413,7 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 	for p.remainingHeaderRows > 0 {
 		header, err := csvReader.Read()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		p.remainingHeaderRows--
 		if p.gotColumnNames {
@@ -321,24 +438,100 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 		p.gotColumnNames = true
 	}
 
-	table, err := csvReader.ReadAll()
+	return nil
+}
+
+// readRecords parses the remaining records of the reader and calls the given
+// function for each metric
+func (p *Parser) readRecords(csvReader *csv.Reader, fn func(telegraf.Metric) error) error {
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		m, err := p.parseSchemaRecord(record)
+		if err != nil {
+			if !p.SkipErrors {
+				return err
+			}
+			p.Log.Debugf("Parsing error: %v", err)
+		} else if err := fn(m); err != nil {
+			return err
+		}
+
+		// Each record is preceded by its own header
+		if p.HeaderPerRecord {
+			p.remainingHeaderRows = p.HeaderRowCount
+			p.gotColumnNames = p.gotInitialColumnNames
+			if !p.gotInitialColumnNames {
+				p.ColumnNames = nil
+			}
+			if err := p.readHeader(csvReader); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
+	csvReader, err := prepareCSV(p, r)
 	if err != nil {
 		return nil, err
 	}
 
 	metrics := make([]telegraf.Metric, 0)
-	for _, record := range table {
-		m, err := p.parseRecord(record)
-		if err != nil {
-			if p.SkipErrors {
-				p.Log.Debugf("Parsing error: %v", err)
-				continue
-			}
-			return metrics, err
-		}
+	err = p.readRecords(csvReader, func(m telegraf.Metric) error {
 		metrics = append(metrics, m)
+		return nil
+	})
+	return metrics, err
+}
+
+// parseSchemaRecord parses the record using the schema selected by the
+// schema tag if any schemas are configured
+func (p *Parser) parseSchemaRecord(record []string) (telegraf.Metric, error) {
+	if len(p.schemas) == 0 {
+		return p.parseRecord(record)
 	}
-	return metrics, nil
+
+	// Take the schema name from the default or metadata tags and fall back
+	// to the first column of the record
+	name, found := p.DefaultTags[p.SchemaTag]
+	if !found {
+		name, found = p.metadataTags[p.SchemaTag]
+	}
+	if !found {
+		if len(record) <= p.SkipColumns {
+			return nil, errors.New("missing schema column")
+		}
+		name = record[p.SkipColumns]
+		if p.TrimSpace {
+			name = strings.Trim(name, " ")
+		}
+		record = append(record[:p.SkipColumns:p.SkipColumns], record[p.SkipColumns+1:]...)
+	}
+
+	schema, found := p.schemas[name]
+	if !found {
+		return nil, fmt.Errorf("unknown schema %q", name)
+	}
+	schema.DefaultTags = p.DefaultTags
+	schema.TimeFunc = p.TimeFunc
+	schema.metadataTags = p.metadataTags
+
+	m, err := schema.parseRecord(record)
+	if err != nil {
+		return nil, fmt.Errorf("parsing record with schema %q failed: %w", name, err)
+	}
+	m.AddTag(p.SchemaTag, name)
+	return m, nil
 }
 
 func (p *Parser) parseRecord(record []string) (telegraf.Metric, error) {
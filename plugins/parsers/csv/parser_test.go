@@ -1576,3 +1576,160 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParseStreamReader(t *testing.T) {
+	input := "# generated\nmeasurement,cpu,value\ncpu,cpu0,42\ncpu,cpu1,bad\ncpu,cpu2,3.5\n"
+
+	parser := &Parser{
+		HeaderRowCount:    1,
+		SkipRows:          1,
+		MeasurementColumn: "measurement",
+		TagColumns:        []string{"cpu"},
+		SkipErrors:        true,
+		ColumnTypes:       []string{"string", "string", "float"},
+		TimeFunc:          DefaultTime,
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	var actual []telegraf.Metric
+	require.NoError(t, parser.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"value": 42.0}, DefaultTime()),
+		metric.New("cpu", map[string]string{"cpu": "cpu2"}, map[string]interface{}{"value": 3.5}, DefaultTime()),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Empty input must not produce an error
+	require.NoError(t, parser.ParseStream(strings.NewReader(""), func(telegraf.Metric) error { return nil }))
+}
+
+func TestHeaderPerRecord(t *testing.T) {
+	input := "host,usage\nserver01,42.5\nhost,usage,load\nserver02,13.0,0.7\n"
+
+	expected := []telegraf.Metric{
+		metric.New("csv", map[string]string{"host": "server01"}, map[string]interface{}{"usage": 42.5}, DefaultTime()),
+		metric.New("csv", map[string]string{"host": "server02"}, map[string]interface{}{"usage": 13.0, "load": 0.7}, DefaultTime()),
+	}
+
+	parser := &Parser{
+		MetricName:      "csv",
+		HeaderRowCount:  1,
+		HeaderPerRecord: true,
+		TagColumns:      []string{"host"},
+		TimeFunc:        DefaultTime,
+		ResetMode:       "always",
+	}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Line-wise parsing
+	parser = &Parser{
+		MetricName:      "csv",
+		HeaderRowCount:  1,
+		HeaderPerRecord: true,
+		TagColumns:      []string{"host"},
+		TimeFunc:        DefaultTime,
+	}
+	require.NoError(t, parser.Init())
+
+	actual = make([]telegraf.Metric, 0, 2)
+	for _, line := range strings.Split(strings.TrimSpace(input), "\n") {
+		m, err := parser.ParseLine(line)
+		if errors.Is(err, parsers.ErrEOF) {
+			continue
+		}
+		require.NoError(t, err)
+		if m != nil {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSchemas(t *testing.T) {
+	schemas := []Schema{
+		{
+			Name:            "orders",
+			ColumnNames:     []string{"time", "order_id", "amount"},
+			ColumnTypes:     []string{"int", "string", "float"},
+			TagColumns:      []string{"order_id"},
+			TimestampColumn: "time",
+			TimestampFormat: "unix",
+		},
+		{
+			Name:        "refunds",
+			ColumnNames: []string{"order_id", "reason"},
+			TagColumns:  []string{"order_id"},
+		},
+	}
+
+	parser := &Parser{
+		MetricName: "csv",
+		Schemas:    schemas,
+		TimeFunc:   DefaultTime,
+	}
+	require.NoError(t, parser.Init())
+
+	input := "orders,1700000000,A-17,99.5\nrefunds,A-17,damaged\n"
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New("csv",
+			map[string]string{"schema": "orders", "order_id": "A-17"},
+			map[string]interface{}{"amount": 99.5},
+			time.Unix(1700000000, 0),
+		),
+		metric.New("csv",
+			map[string]string{"schema": "refunds", "order_id": "A-17"},
+			map[string]interface{}{"reason": "damaged"},
+			DefaultTime(),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	_, err = parser.Parse([]byte("returns,A-17\n"))
+	require.ErrorContains(t, err, `unknown schema "returns"`)
+
+	// Select the schema using a default tag
+	parser = &Parser{
+		MetricName: "csv",
+		Schemas:    schemas,
+		SchemaTag:  "stream",
+		TimeFunc:   DefaultTime,
+	}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"stream": "refunds"})
+
+	actual, err = parser.Parse([]byte("A-18,lost\n"))
+	require.NoError(t, err)
+	expected = []telegraf.Metric{
+		metric.New("csv",
+			map[string]string{"stream": "refunds", "order_id": "A-18"},
+			map[string]interface{}{"reason": "lost"},
+			DefaultTime(),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSchemasInvalid(t *testing.T) {
+	parser := &Parser{
+		Schemas: []Schema{{Name: "a"}},
+	}
+	require.ErrorContains(t, parser.Init(), `schema "a": no column names given`)
+
+	parser = &Parser{
+		ColumnNames:     []string{"unused"},
+		HeaderPerRecord: true,
+	}
+	require.ErrorContains(t, parser.Init(), "requires `csv_header_row_count`")
+}
@@ -133,6 +133,23 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
+// ParseStream parses line protocol from the reader line by line and calls
+// the given function for each metric.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	decoder := lineprotocol.NewDecoder(r)
+	for decoder.Next() {
+		m, err := nextMetric(decoder, p.precision, p.defaultTime, p.allowPartial)
+		if err != nil {
+			return convertToParseError(nil, err)
+		}
+		p.applyDefaultTagsSingle(m)
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return decoder.Err()
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	metrics, err := p.Parse([]byte(line))
 	if err != nil {
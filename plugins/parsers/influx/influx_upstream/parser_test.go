@@ -1071,3 +1071,25 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParseStream(t *testing.T) {
+	input := "cpu,host=a value=1 0\n\n# comment\ncpu,host=b value=2i 1000000000\nmem free=3u 2000000000\n"
+
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "stream", "host": "default"})
+	expected, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, expected, 3)
+
+	var actual []telegraf.Metric
+	require.NoError(t, parser.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	err = parser.ParseStream(strings.NewReader("cpu value= 0\n"), func(telegraf.Metric) error { return nil })
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+}
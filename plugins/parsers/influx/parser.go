@@ -114,6 +114,39 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
+// ParseStream parses line protocol from the reader line by line and calls
+// the given function for each metric.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	// Use a separate handler with the parser's settings to allow concurrent
+	// parsing of multiple streams
+	handler := &MetricHandler{
+		timePrecision: p.handler.timePrecision,
+		timeFunc:      p.handler.timeFunc,
+	}
+	sp := &StreamParser{
+		machine: NewStreamMachine(r, handler),
+		handler: handler,
+	}
+
+	for {
+		m, err := sp.Next()
+		if errors.Is(err, EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			continue
+		}
+
+		p.applyDefaultTagsSingle(m)
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	metrics, err := p.Parse([]byte(line))
 	if err != nil {
@@ -1038,3 +1038,46 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestParseStream(t *testing.T) {
+	input := "cpu,host=a value=1 0\n\n# comment\ncpu,host=b value=2i 1000000000\nmem free=3u 2000000000\n"
+
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "stream", "host": "default"})
+	expected, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, expected, 3)
+
+	var actual []telegraf.Metric
+	require.NoError(t, parser.ParseStream(strings.NewReader(input), func(m telegraf.Metric) error {
+		actual = append(actual, m)
+		return nil
+	}))
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseStreamErrors(t *testing.T) {
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+
+	var count int
+	err := parser.ParseStream(strings.NewReader("cpu value=1 0\ncpu value= 0\ncpu value=3 0\n"), func(telegraf.Metric) error {
+		count++
+		return nil
+	})
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, 2, parseErr.LineNumber)
+	require.Equal(t, 1, count)
+
+	// Errors of the callback stop parsing
+	errStop := errors.New("stop")
+	count = 0
+	err = parser.ParseStream(strings.NewReader("cpu value=1 0\ncpu value=2 0\n"), func(telegraf.Metric) error {
+		count++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,121 @@
+package ion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/amazon-ion/ion-go/ion"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
+)
+
+// Parser decodes Amazon Ion data in text or binary encoding and extracts
+// the metrics using the json_v2 configuration.
+type Parser struct {
+	json_v2.Parser
+}
+
+// Parse converts each top-level value of the Ion stream into its JSON
+// equivalent and extracts the metrics using the json_v2 parser.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	decoder := ion.NewDecoder(ion.NewReaderBytes(buf))
+
+	var metrics []telegraf.Metric
+	for {
+		value, err := decoder.Decode()
+		if errors.Is(err, ion.ErrNoInput) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding Ion data failed: %w", err)
+		}
+		// The text reader returns version markers as symbols
+		if symbol, ok := value.(*ion.SymbolToken); ok && symbol.Text != nil && *symbol.Text == "$ion_1_0" {
+			continue
+		}
+
+		doc, err := json.Marshal(convert(value))
+		if err != nil {
+			return nil, fmt.Errorf("converting Ion data failed: %w", err)
+		}
+		m, err := p.Parser.Parse(doc)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (*Parser) ParseLine(string) (telegraf.Metric, error) {
+	return nil, errors.New("parsing line is not supported by Ion format")
+}
+
+// convert turns the decoded Ion value into a value encodable as JSON.
+// Decimals are converted to floats, timestamps to RFC3339 strings and
+// symbols to their text.
+func convert(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = convert(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convert(e)
+		}
+		return v
+	case *float64:
+		if v == nil || math.IsNaN(*v) || math.IsInf(*v, 0) {
+			return nil
+		}
+		return *v
+	case *ion.Decimal:
+		if v == nil {
+			return nil
+		}
+		coefficient, exponent := v.CoEx()
+		f, err := strconv.ParseFloat(coefficient.String()+"e"+strconv.Itoa(int(exponent)), 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	case *big.Int:
+		if v != nil && v.IsInt64() {
+			return v.Int64()
+		}
+		return v
+	case *ion.Timestamp:
+		if v == nil {
+			return nil
+		}
+		return v.GetDateTime().Format(time.RFC3339Nano)
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *ion.SymbolToken:
+		if v == nil || v.Text == nil {
+			return nil
+		}
+		return *v.Text
+	}
+	return value
+}
+
+func init() {
+	parsers.Add("ion",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{Parser: json_v2.Parser{DefaultMetricName: defaultMetricName}}
+		},
+	)
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"time"
 
+	"github.com/dimchansky/utfbom"
 	"github.com/tidwall/gjson"
 
 	"github.com/influxdata/telegraf"
@@ -219,6 +221,109 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	}
 }
 
+// ParseStream parses a stream of JSON documents, e.g. newline delimited
+// JSON, and calls the given function for each metric. The elements of
+// top-level arrays are decoded one by one to avoid holding the whole array
+// in memory. When using a query, each document is decoded completely.
+func (p *Parser) ParseStream(r io.Reader, fn func(telegraf.Metric) error) error {
+	decoder := json.NewDecoder(utfbom.SkipOnly(r))
+	for {
+		var metrics []telegraf.Metric
+		if p.Query != "" {
+			var doc json.RawMessage
+			if err := decoder.Decode(&doc); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			m, err := p.Parse(doc)
+			if err != nil {
+				return err
+			}
+			metrics = m
+		} else {
+			token, err := decoder.Token()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+
+			timestamp := time.Now().UTC()
+			switch token {
+			case json.Delim('{'):
+				metrics, err = p.decodeObject(decoder, timestamp)
+			case json.Delim('['):
+				err = p.decodeArray(decoder, timestamp, fn)
+			case nil:
+			default:
+				err = ErrWrongType
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, m := range metrics {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decodeObject decodes the remainder of an object after its opening
+// delimiter was consumed and returns the resulting metrics
+func (p *Parser) decodeObject(decoder *json.Decoder, timestamp time.Time) ([]telegraf.Metric, error) {
+	data := make(map[string]interface{})
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", token)
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		data[key] = value
+	}
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return p.parseObject(data, timestamp)
+}
+
+// decodeArray decodes the elements of an array one by one after its opening
+// delimiter was consumed and calls the given function for the metrics
+func (p *Parser) decodeArray(decoder *json.Decoder, timestamp time.Time, fn func(telegraf.Metric) error) error {
+	for decoder.More() {
+		var item interface{}
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		metrics, err := p.parseArray([]interface{}{item}, timestamp)
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+	}
+	// Consume the closing delimiter
+	_, err := decoder.Token()
+	return err
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	metrics, err := p.Parse([]byte(line + "\n"))
 
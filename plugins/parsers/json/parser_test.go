@@ -2,6 +2,7 @@ package json
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -1491,3 +1492,68 @@ func FuzzParserJSON(f *testing.F) {
 		parser.Parse(input)
 	})
 }
+
+func TestParseStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		query    string
+		expected []telegraf.Metric
+	}{
+		{
+			name:  "newline delimited",
+			input: "{\"a\": 5, \"b\": {\"c\": 6}}\n\n{\"a\": 7}\nnull\n",
+			expected: []telegraf.Metric{
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 5.0, "b_c": 6.0}, time.Unix(0, 0)),
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 7.0}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:  "arrays",
+			input: "\xef\xbb\xbf" + validJSONArrayMultiple + "[{\"a\": 9}]",
+			expected: []telegraf.Metric{
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 5.0, "b_c": 6.0}, time.Unix(0, 0)),
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 7.0, "b_c": 8.0}, time.Unix(0, 0)),
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 9.0}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:  "query",
+			input: "{\"data\": [{\"a\": 1}, {\"a\": 2}]}\n{\"data\": {\"a\": 3}}\n",
+			query: "data",
+			expected: []telegraf.Metric{
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 1.0}, time.Unix(0, 0)),
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 2.0}, time.Unix(0, 0)),
+				metric.New("json_test", map[string]string{}, map[string]interface{}{"a": 3.0}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				MetricName: "json_test",
+				Query:      tt.query,
+			}
+			require.NoError(t, parser.Init())
+
+			var actual []telegraf.Metric
+			require.NoError(t, parser.ParseStream(strings.NewReader(tt.input), func(m telegraf.Metric) error {
+				actual = append(actual, m)
+				return nil
+			}))
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseStreamInvalid(t *testing.T) {
+	parser := &Parser{MetricName: "json_test", Strict: true}
+	require.NoError(t, parser.Init())
+
+	noop := func(telegraf.Metric) error { return nil }
+	require.Error(t, parser.ParseStream(strings.NewReader(invalidJSON), noop))
+	require.Error(t, parser.ParseStream(strings.NewReader(invalidJSON2), noop))
+	require.ErrorIs(t, parser.ParseStream(strings.NewReader("[1, 2]"), noop), ErrWrongType)
+	require.ErrorIs(t, parser.ParseStream(strings.NewReader("42"), noop), ErrWrongType)
+}
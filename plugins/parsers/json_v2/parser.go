@@ -1,6 +1,7 @@
 package json_v2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/blues/jsonata-go"
 	"github.com/dimchansky/utfbom"
 	"github.com/tidwall/gjson"
 
@@ -37,6 +39,8 @@ type Parser struct {
 	iterateObjects bool
 	// objectConfig contains the config for an object, some info is needed while iterating over the gjson results
 	objectConfig Object
+	// transformations contains the compiled transformation expressions for each config
+	transformations []*jsonata.Expr
 	// parseMutex is here because Parse() is not threadsafe.  If it is made threadsafe at some point, then we won't need it anymore.
 	parseMutex sync.Mutex
 }
@@ -47,6 +51,7 @@ type Config struct {
 	TimestampPath       string `toml:"timestamp_path"`        // OPTIONAL
 	TimestampFormat     string `toml:"timestamp_format"`      // OPTIONAL, but REQUIRED when timestamp_path is defined
 	TimestampTimezone   string `toml:"timestamp_timezone"`    // OPTIONAL, but REQUIRES timestamp_path
+	Transformation      string `toml:"transformation"`        // OPTIONAL
 
 	Fields      []DataSet `toml:"field"`
 	Tags        []DataSet `toml:"tag"`
@@ -105,6 +110,7 @@ func (p *Parser) Init() error {
 		return errors.New("no configuration provided")
 	}
 	// Propagate the default metric name to the configs in case it is not set there
+	p.transformations = make([]*jsonata.Expr, len(p.Configs))
 	for i, cfg := range p.Configs {
 		if cfg.MeasurementName == "" {
 			p.Configs[i].MeasurementName = p.DefaultMetricName
@@ -116,6 +122,13 @@ func (p *Parser) Init() error {
 			}
 			p.Configs[i].Location = loc
 		}
+		if cfg.Transformation != "" {
+			expr, err := jsonata.Compile(cfg.Transformation)
+			if err != nil {
+				return fmt.Errorf("invalid transformation in config %d: %w", i+1, err)
+			}
+			p.transformations[i] = expr
+		}
 	}
 	return nil
 }
@@ -152,11 +165,24 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 
 	var metrics []telegraf.Metric
 
-	for _, c := range p.Configs {
+	for i, c := range p.Configs {
+		// Reshape the input before extracting the data if requested
+		data := input
+		if expr := p.transformations[i]; expr != nil {
+			transformed, err := transform(expr, data)
+			if err != nil {
+				return nil, err
+			}
+			if transformed == nil {
+				continue
+			}
+			data = transformed
+		}
+
 		// Measurement name can either be hardcoded, or parsed from the JSON using a GJSON path expression
 		p.measurementName = c.MeasurementName
 		if c.MeasurementNamePath != "" {
-			result := gjson.GetBytes(input, c.MeasurementNamePath)
+			result := gjson.GetBytes(data, c.MeasurementNamePath)
 			if !result.IsArray() && !result.IsObject() {
 				p.measurementName = result.String()
 			}
@@ -165,10 +191,10 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 		// timestamp defaults to current time, or can be parsed from the JSON using a GJSON path expression
 		timestamp := time.Now()
 		if c.TimestampPath != "" {
-			result := gjson.GetBytes(input, c.TimestampPath)
+			result := gjson.GetBytes(data, c.TimestampPath)
 
 			if result.Type == gjson.Null {
-				p.Log.Debugf("Message: %s", input)
+				p.Log.Debugf("Message: %s", data)
 				return nil, fmt.Errorf("the timestamp path %q returned NULL", c.TimestampPath)
 			}
 			if !result.IsArray() && !result.IsObject() {
@@ -185,17 +211,17 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 			}
 		}
 
-		fields, err := p.processMetric(input, c.Fields, false, timestamp)
+		fields, err := p.processMetric(data, c.Fields, false, timestamp)
 		if err != nil {
 			return nil, err
 		}
 
-		tags, err := p.processMetric(input, c.Tags, true, timestamp)
+		tags, err := p.processMetric(data, c.Tags, true, timestamp)
 		if err != nil {
 			return nil, err
 		}
 
-		objects, err := p.processObjects(input, c.JSONObjects, timestamp)
+		objects, err := p.processObjects(data, c.JSONObjects, timestamp)
 		if err != nil {
 			return nil, err
 		}
@@ -218,6 +244,29 @@ func (p *Parser) parseCriticalPath(input []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
+// transform applies the JSONata expression to the input and returns the
+// resulting JSON document or nil if the expression does not match
+func transform(expr *jsonata.Expr, input []byte) ([]byte, error) {
+	var data interface{}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, fmt.Errorf("decoding input for transformation failed: %w", err)
+	}
+
+	result, err := expr.Eval(data)
+	if err != nil {
+		if errors.Is(err, jsonata.ErrUndefined) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("transformation failed: %w", err)
+	}
+
+	output, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encoding transformation result failed: %w", err)
+	}
+	return output, nil
+}
+
 // processMetric will iterate over all 'field' or 'tag' configs and create metrics for each
 // A field/tag can either be a single value or an array of values, each resulting in its own metric
 // For multiple configs, a set of metrics is created from the cartesian product of each separate config
@@ -105,6 +105,13 @@ func TestParserEmptyConfig(t *testing.T) {
 	require.ErrorContains(t, plugin.Init(), "no configuration provided")
 }
 
+func TestParserInvalidTransformation(t *testing.T) {
+	plugin := &json_v2.Parser{
+		Configs: []json_v2.Config{{Transformation: "$.{"}},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid transformation in config 1")
+}
+
 func BenchmarkParsingSequential(b *testing.B) {
 	inputFilename := filepath.Join("testdata", "benchmark", "input.json")
 
@@ -0,0 +1,32 @@
+package parsers
+
+import (
+	"io"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ParseStream parses the data of the given reader and calls the function for
+// each metric. Parsers implementing the telegraf.StreamingParser interface
+// decode the data incrementally, for all other parsers the complete data is
+// read into memory and parsed at once.
+func ParseStream(parser telegraf.Parser, r io.Reader, fn func(telegraf.Metric) error) error {
+	if p, ok := parser.(telegraf.StreamingParser); ok {
+		return p.ParseStream(r, fn)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	metrics, err := parser.Parse(buf)
+	if err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package vpcflowlog
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindTag
+	kindInt
+	kindIP
+	kindTime
+	kindProtocol
+)
+
+// kinds maps the flow log fields of versions 2 to 7 to their representation
+// in the metric
+var kinds = map[string]kind{
+	// Version 2
+	"version":      kindInt,
+	"account-id":   kindTag,
+	"interface-id": kindTag,
+	"srcaddr":      kindIP,
+	"dstaddr":      kindIP,
+	"srcport":      kindInt,
+	"dstport":      kindInt,
+	"protocol":     kindProtocol,
+	"packets":      kindInt,
+	"bytes":        kindInt,
+	"start":        kindTime,
+	"end":          kindTime,
+	"action":       kindTag,
+	"log-status":   kindTag,
+
+	// Version 3
+	"vpc-id":      kindTag,
+	"subnet-id":   kindTag,
+	"instance-id": kindTag,
+	"tcp-flags":   kindInt,
+	"type":        kindTag,
+	"pkt-srcaddr": kindIP,
+	"pkt-dstaddr": kindIP,
+
+	// Version 4
+	"region":           kindTag,
+	"az-id":            kindTag,
+	"sublocation-type": kindTag,
+	"sublocation-id":   kindTag,
+
+	// Version 5
+	"pkt-src-aws-service": kindTag,
+	"pkt-dst-aws-service": kindTag,
+	"flow-direction":      kindTag,
+	"traffic-path":        kindInt,
+
+	// Version 7
+	"ecs-cluster-arn":            kindString,
+	"ecs-cluster-name":           kindTag,
+	"ecs-container-instance-arn": kindString,
+	"ecs-container-instance-id":  kindString,
+	"ecs-container-id":           kindString,
+	"ecs-second-container-id":    kindString,
+	"ecs-service-name":           kindTag,
+	"ecs-task-definition-arn":    kindString,
+	"ecs-task-arn":               kindString,
+	"ecs-task-id":                kindString,
+}
+
+// protocols maps the IANA protocol numbers of common protocols to their names
+var protocols = map[uint64]string{
+	1:   "icmp",
+	2:   "igmp",
+	4:   "ipv4",
+	6:   "tcp",
+	17:  "udp",
+	41:  "ipv6",
+	47:  "gre",
+	50:  "esp",
+	51:  "ah",
+	58:  "icmpv6",
+	89:  "ospf",
+	132: "sctp",
+}
@@ -0,0 +1,196 @@
+package vpcflowlog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// defaultFields is the field order of the default (version 2) format
+var defaultFields = []string{
+	"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport",
+	"protocol", "packets", "bytes", "start", "end", "action", "log-status",
+}
+
+// Parser decodes AWS VPC Flow Log records into metrics.
+type Parser struct {
+	Fields      []string          `toml:"vpcflowlog_fields"`
+	DefaultTags map[string]string `toml:"-"`
+
+	metricName string
+}
+
+func (p *Parser) Init() error {
+	if len(p.Fields) == 0 {
+		p.Fields = defaultFields
+	}
+	return checkFields(p.Fields)
+}
+
+// Parse converts the flow log records, one per line, into metrics. A header
+// line listing the field names, as contained in flow log files delivered to
+// Amazon S3, replaces the configured field order for the following records
+// of the buffer.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+
+	fields := p.Fields
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		values := strings.Fields(line)
+		if isHeader(values) {
+			if err := checkFields(values); err != nil {
+				return nil, fmt.Errorf("invalid header: %w", err)
+			}
+			fields = values
+			continue
+		}
+
+		m, err := p.parseRecord(fields, values)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// ParseLine converts a single flow log record into a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) < 1 {
+		return nil, errors.New("no metric in line")
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseRecord(names, values []string) (telegraf.Metric, error) {
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("record has %d values but %d fields are defined", len(values), len(names))
+	}
+
+	tags := make(map[string]string, len(p.DefaultTags))
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	fields := make(map[string]interface{}, len(values))
+	var timestamp time.Time
+
+	for i, value := range values {
+		// Missing values, e.g. for NODATA or SKIPDATA records or fields not
+		// applicable to the flow, are marked by a dash
+		if value == "-" {
+			continue
+		}
+		name := names[i]
+		key := strings.ReplaceAll(name, "-", "_")
+
+		switch kinds[name] {
+		case kindTag:
+			tags[key] = value
+		case kindProtocol:
+			number, err := strconv.ParseUint(value, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %q: %w", value, name, err)
+			}
+			if protocol, found := protocols[number]; found {
+				tags[key] = protocol
+			} else {
+				tags[key] = value
+			}
+		case kindInt:
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %q: %w", value, name, err)
+			}
+			fields[key] = v
+		case kindIP:
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %q: %w", value, name, err)
+			}
+			fields[key] = addr.String()
+		case kindTime:
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %q: %w", value, name, err)
+			}
+			if name == "start" {
+				timestamp = time.Unix(v, 0)
+			} else {
+				fields[key] = v
+			}
+		default:
+			fields[key] = value
+		}
+	}
+
+	// Skip records without any field value
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return metric.New(p.metricName, tags, fields, timestamp), nil
+}
+
+func checkFields(fields []string) error {
+	seen := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		if _, found := kinds[name]; !found {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate field %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// isHeader returns true if all values are known field names
+func isHeader(values []string) bool {
+	return !slices.ContainsFunc(values, func(v string) bool {
+		_, found := kinds[v]
+		return !found
+	})
+}
+
+func init() {
+	parsers.Add("vpcflowlog",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,95 @@
+package vpcflowlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+
+		t.Run(f.Name(), func(t *testing.T) {
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfig(configFilename))
+			require.Len(t, cfg.Inputs, 1)
+
+			plugin := cfg.Inputs[0].Input.(*test.Plugin)
+			plugin.Path = testcasePath
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			err := plugin.Gather(&acc)
+			if len(plugin.ExpectedErrors) > 0 {
+				require.ErrorContains(t, err, plugin.ExpectedErrors[0])
+			} else {
+				require.NoError(t, err)
+			}
+
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{metricName: "vpcflowlog"}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "test"})
+
+	m, err := parser.ParseLine("2 123456789010 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 ACCEPT OK")
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"vpcflowlog",
+		map[string]string{
+			"source":       "test",
+			"account_id":   "123456789010",
+			"interface_id": "eni-1235b8ca123456789",
+			"protocol":     "tcp",
+			"action":       "ACCEPT",
+			"log_status":   "OK",
+		},
+		map[string]interface{}{
+			"version": int64(2),
+			"srcaddr": "172.31.16.139",
+			"dstaddr": "172.31.16.21",
+			"srcport": int64(20641),
+			"dstport": int64(22),
+			"packets": int64(20),
+			"bytes":   int64(4249),
+			"end":     int64(1418530070),
+		},
+		time.Unix(1418530010, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{m})
+}
+
+func TestInvalid(t *testing.T) {
+	parser := &Parser{Fields: []string{"version", "foo"}}
+	require.ErrorContains(t, parser.Init(), `unknown field "foo"`)
+
+	parser = &Parser{Fields: []string{"version", "version"}}
+	require.ErrorContains(t, parser.Init(), `duplicate field "version"`)
+
+	parser = &Parser{Fields: []string{"version", "srcaddr"}}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse([]byte("2 300.1.1.1"))
+	require.ErrorContains(t, err, `invalid value "300.1.1.1" for "srcaddr"`)
+	_, err = parser.Parse([]byte("two 10.1.1.1"))
+	require.ErrorContains(t, err, `invalid value "two" for "version"`)
+}
@@ -42,6 +42,9 @@ type Parser struct {
 	ProtobufMessageType  string            `toml:"xpath_protobuf_type"`
 	ProtobufImportPaths  []string          `toml:"xpath_protobuf_import_paths"`
 	ProtobufSkipBytes    int64             `toml:"xpath_protobuf_skip_bytes"`
+	ProtobufDescriptors  string            `toml:"xpath_protobuf_descriptor_set"`
+	ProtobufFraming      string            `toml:"xpath_protobuf_framing"`
+	ProtobufReload       config.Duration   `toml:"xpath_protobuf_reload_interval"`
 	PrintDocument        bool              `toml:"xpath_print_document"`
 	AllowEmptySelection  bool              `toml:"xpath_allow_empty_selection"`
 	NativeTypes          bool              `toml:"xpath_native_types"`
@@ -131,17 +134,20 @@ func (p *Parser) Init() error {
 		if p.ProtobufMessageDef != "" && !slices.Contains(p.ProtobufMessageFiles, p.ProtobufMessageDef) {
 			p.ProtobufMessageFiles = append(p.ProtobufMessageFiles, p.ProtobufMessageDef)
 		}
-		pbdoc := protobufDocument{
-			MessageFiles: p.ProtobufMessageFiles,
-			MessageType:  p.ProtobufMessageType,
-			ImportPaths:  p.ProtobufImportPaths,
-			SkipBytes:    p.ProtobufSkipBytes,
-			Log:          p.Log,
+		pbdoc := &protobufDocument{
+			MessageFiles:   p.ProtobufMessageFiles,
+			MessageType:    p.ProtobufMessageType,
+			ImportPaths:    p.ProtobufImportPaths,
+			DescriptorSet:  p.ProtobufDescriptors,
+			Framing:        p.ProtobufFraming,
+			SkipBytes:      p.ProtobufSkipBytes,
+			ReloadInterval: time.Duration(p.ProtobufReload),
+			Log:            p.Log,
 		}
 		if err := pbdoc.Init(); err != nil {
 			return err
 		}
-		p.document = &pbdoc
+		p.document = pbdoc
 
 		// Required for backward compatibility
 		if len(p.ConfigsProto) > 0 {
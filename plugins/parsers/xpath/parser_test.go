@@ -2,6 +2,8 @@ package xpath
 
 import (
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,7 +12,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/toml"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -1393,6 +1398,100 @@ func TestProtobufImporting(t *testing.T) {
 	require.NoError(t, parser.Init())
 }
 
+func TestProtobufReload(t *testing.T) {
+	// Message definition with only a subset of the fields contained in the data
+	const definitionV1 = `syntax = "proto3";
+package native_type;
+message Message {
+    string a = 1;
+}`
+	const definitionV2 = `syntax = "proto3";
+package native_type;
+message Message {
+    string a = 1;
+    double b = 2;
+}`
+
+	data, err := os.ReadFile(filepath.Join("testcases", "protobuf_descriptor_set", "test.dat"))
+	require.NoError(t, err)
+
+	// Serve the descriptor set of the current message definition
+	var current []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if _, err := w.Write(current); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	current = compileDescriptorSet(t, definitionV1)
+
+	parser := &Parser{
+		DefaultMetricName:   "xpath_protobuf",
+		Format:              "xpath_protobuf",
+		ProtobufDescriptors: server.URL,
+		ProtobufMessageType: "native_type.Message",
+		ProtobufReload:      config.Duration(time.Nanosecond),
+		Configs: []Config{
+			{
+				MetricQuery:    "'test'",
+				FieldSelection: "/*",
+			},
+		},
+		Log: testutil.Logger{Name: "parsers.protobuf"},
+	}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse(data)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"a": "a string"}, actual[0].Fields())
+
+	// Update the definitions and make sure they are picked up
+	current = compileDescriptorSet(t, definitionV2)
+	actual, err = parser.Parse(data)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"a": "a string", "b": "3.1415"}, actual[0].Fields())
+
+	// Invalid definitions must not interrupt parsing
+	current = []byte("invalid")
+	actual, err = parser.Parse(data)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, map[string]interface{}{"a": "a string", "b": "3.1415"}, actual[0].Fields())
+}
+
+func compileDescriptorSet(t *testing.T, definition string) []byte {
+	t.Helper()
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"message.proto": definition}),
+	}
+	fds, err := parser.ParseFiles("message.proto")
+	require.NoError(t, err)
+	buf, err := proto.Marshal(desc.ToFileDescriptorSet(fds...))
+	require.NoError(t, err)
+	return buf
+}
+
+func TestProtobufFramingInvalid(t *testing.T) {
+	parser := &Parser{
+		DefaultMetricName:   "xpath_protobuf",
+		Format:              "xpath_protobuf",
+		ProtobufMessageDef:  "message.proto",
+		ProtobufMessageType: "native_type.Message",
+		ProtobufImportPaths: []string{"testcases/protobuf_confluent_framing"},
+		ProtobufFraming:     "confluent",
+		Log:                 testutil.Logger{Name: "parsers.protobuf"},
+	}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse([]byte{1, 0, 0, 0, 1, 0})
+	require.ErrorContains(t, err, "not in Confluent wire format")
+
+	parser.ProtobufFraming = "foo"
+	require.ErrorContains(t, parser.Init(), "invalid protocol-buffer framing")
+}
+
 func TestMultipleConfigs(t *testing.T) {
 	// Get all directories in testdata
 	folders, err := os.ReadDir("testcases")
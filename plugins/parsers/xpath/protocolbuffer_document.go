@@ -1,13 +1,24 @@
 package xpath
 
 import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	path "github.com/antchfx/xpath"
 	"github.com/jhump/protoreflect/desc"
@@ -17,72 +28,104 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/dynamicpb"
 
 	"github.com/influxdata/telegraf"
 )
 
 type protobufDocument struct {
-	MessageFiles []string
-	MessageType  string
-	ImportPaths  []string
-	SkipBytes    int64
-	Log          telegraf.Logger
+	MessageFiles   []string
+	MessageType    string
+	ImportPaths    []string
+	DescriptorSet  string
+	Framing        string
+	SkipBytes      int64
+	ReloadInterval time.Duration
+	Log            telegraf.Logger
 
 	msg          *dynamicpb.Message
 	unmarshaller proto.UnmarshalOptions
+
+	// State for reloading the message definitions
+	fingerprint string
+	lastCheck   time.Time
+	sync.Mutex
 }
 
 func (d *protobufDocument) Init() error {
 	// Check the message definition and type
-	if len(d.MessageFiles) == 0 {
-		return errors.New("protocol-buffer files not set")
+	if len(d.MessageFiles) == 0 && d.DescriptorSet == "" {
+		return errors.New("protocol-buffer files or descriptor set not set")
+	}
+	if len(d.MessageFiles) > 0 && d.DescriptorSet != "" {
+		return errors.New("protocol-buffer files and descriptor set are mutually exclusive")
 	}
 	if d.MessageType == "" {
 		return errors.New("protocol-buffer message-type not set")
 	}
 
-	// Load the file descriptors from the given protocol-buffer definition
-	parser := protoparse.Parser{
-		ImportPaths:      d.ImportPaths,
-		InferImportPaths: true,
+	switch d.Framing {
+	case "":
+		d.Framing = "none"
+	case "none", "confluent", "glue":
+	default:
+		return fmt.Errorf("invalid protocol-buffer framing %q", d.Framing)
 	}
-	fds, err := parser.ParseFiles(d.MessageFiles...)
+
+	fingerprint, data, err := d.currentFingerprint()
 	if err != nil {
-		return fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+		return err
+	}
+	if err := d.load(data); err != nil {
+		return err
 	}
-	if len(fds) < 1 {
-		return errors.New("files do not contain a file descriptor")
+	d.fingerprint = fingerprint
+	d.lastCheck = time.Now()
+
+	return nil
+}
+
+// load sets up the message template and unmarshaller from the given
+// descriptor set data or, if not given, from the protocol-buffer files
+func (d *protobufDocument) load(data []byte) error {
+	var fds *descriptorpb.FileDescriptorSet
+	if d.DescriptorSet != "" {
+		fds = &descriptorpb.FileDescriptorSet{}
+		if err := proto.Unmarshal(data, fds); err != nil {
+			return fmt.Errorf("decoding descriptor set failed: %w", err)
+		}
+		if len(fds.File) < 1 {
+			return errors.New("descriptor set does not contain a file descriptor")
+		}
+	} else {
+		// Load the file descriptors from the given protocol-buffer definition
+		parser := protoparse.Parser{
+			ImportPaths:      d.ImportPaths,
+			InferImportPaths: true,
+		}
+		files, err := parser.ParseFiles(d.MessageFiles...)
+		if err != nil {
+			return fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+		}
+		if len(files) < 1 {
+			return errors.New("files do not contain a file descriptor")
+		}
+		fds = desc.ToFileDescriptorSet(files...)
 	}
 
 	// Register all definitions in the file in the global registry
-	registry, err := protodesc.NewFiles(desc.ToFileDescriptorSet(fds...))
+	registry, err := protodesc.NewFiles(fds)
 	if err != nil {
 		return fmt.Errorf("constructing registry failed: %w", err)
 	}
-	d.unmarshaller = proto.UnmarshalOptions{
-		RecursionLimit: protowire.DefaultRecursionLimit,
-		Resolver:       dynamicpb.NewTypes(registry),
-	}
 
 	// Lookup given type in the loaded file descriptors
 	msgFullName := protoreflect.FullName(d.MessageType)
 	descriptor, err := registry.FindDescriptorByName(msgFullName)
 	if err != nil {
-		d.Log.Infof("Could not find %q... Known messages:", msgFullName)
-
-		var known []string
-		registry.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
-			name := strings.TrimSpace(string(fd.FullName()))
-			if name != "" {
-				known = append(known, name)
-			}
-			return true
-		})
-		sort.Strings(known)
-		for _, name := range known {
-			d.Log.Infof("  %s", name)
-		}
+		d.logKnownMessages(msgFullName, registry)
 		return err
 	}
 
@@ -92,19 +135,143 @@ func (d *protobufDocument) Init() error {
 		return fmt.Errorf("%q is not a message descriptor (%T)", msgFullName, descriptor)
 	}
 
-	d.msg = dynamicpb.NewMessage(msgDesc)
-	if d.msg == nil {
+	msg := dynamicpb.NewMessage(msgDesc)
+	if msg == nil {
 		return fmt.Errorf("creating message template for %q failed", msgDesc.FullName())
 	}
 
+	d.msg = msg
+	d.unmarshaller = proto.UnmarshalOptions{
+		RecursionLimit: protowire.DefaultRecursionLimit,
+		Resolver:       dynamicpb.NewTypes(registry),
+	}
+
 	return nil
 }
 
+func (d *protobufDocument) logKnownMessages(msgFullName protoreflect.FullName, registry *protoregistry.Files) {
+	d.Log.Infof("Could not find %q... Known messages:", msgFullName)
+
+	var known []string
+	registry.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		name := strings.TrimSpace(string(fd.FullName()))
+		if name != "" {
+			known = append(known, name)
+		}
+		return true
+	})
+	sort.Strings(known)
+	for _, name := range known {
+		d.Log.Infof("  %s", name)
+	}
+}
+
+// currentFingerprint returns an identifier of the current state of the
+// message definitions. For descriptor sets the data is returned as well to
+// avoid fetching it twice.
+func (d *protobufDocument) currentFingerprint() (string, []byte, error) {
+	if d.DescriptorSet != "" {
+		data, err := d.readDescriptorSet()
+		if err != nil {
+			return "", nil, err
+		}
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), data, nil
+	}
+
+	// Use the modification times of the protocol-buffer files as we cannot
+	// easily determine all imported files without parsing
+	var fingerprint strings.Builder
+	for _, fn := range d.MessageFiles {
+		candidates := []string{fn}
+		for _, p := range d.ImportPaths {
+			candidates = append(candidates, filepath.Join(p, fn))
+		}
+		for _, candidate := range candidates {
+			if info, err := os.Stat(candidate); err == nil {
+				fmt.Fprintf(&fingerprint, "%s:%d:%d;", candidate, info.ModTime().UnixNano(), info.Size())
+				break
+			}
+		}
+	}
+	return fingerprint.String(), nil, nil
+}
+
+// readDescriptorSet reads the serialized descriptor set from a file or URL
+func (d *protobufDocument) readDescriptorSet() ([]byte, error) {
+	if !strings.HasPrefix(d.DescriptorSet, "http://") && !strings.HasPrefix(d.DescriptorSet, "https://") {
+		data, err := os.ReadFile(d.DescriptorSet)
+		if err != nil {
+			return nil, fmt.Errorf("reading descriptor set failed: %w", err)
+		}
+		return data, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.DescriptorSet, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching descriptor set failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching descriptor set failed: received status %q", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading descriptor set failed: %w", err)
+	}
+	return data, nil
+}
+
+// reload updates the message definitions if they changed since the last
+// check. Failures are logged and the previous definitions are kept to not
+// interrupt parsing.
+func (d *protobufDocument) reload() {
+	d.lastCheck = time.Now()
+
+	fingerprint, data, err := d.currentFingerprint()
+	if err != nil {
+		d.Log.Errorf("Checking message definitions failed: %v", err)
+		return
+	}
+	if fingerprint == d.fingerprint {
+		return
+	}
+
+	msg, unmarshaller := d.msg, d.unmarshaller
+	if err := d.load(data); err != nil {
+		d.Log.Errorf("Reloading message definitions failed, keeping previous ones: %v", err)
+		d.msg, d.unmarshaller = msg, unmarshaller
+		return
+	}
+	d.fingerprint = fingerprint
+	d.Log.Info("Reloaded message definitions")
+}
+
 func (d *protobufDocument) Parse(buf []byte) (dataNode, error) {
-	msg := d.msg.New()
+	d.Lock()
+	if d.ReloadInterval > 0 && time.Since(d.lastCheck) >= d.ReloadInterval {
+		d.reload()
+	}
+	template, unmarshaller := d.msg, d.unmarshaller
+	d.Unlock()
+
+	data, err := unframe(d.Framing, buf)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) < d.SkipBytes {
+		return nil, fmt.Errorf("message too short to skip %d bytes", d.SkipBytes)
+	}
 
 	// Unmarshal the received buffer
-	if err := d.unmarshaller.Unmarshal(buf[d.SkipBytes:], msg.Interface()); err != nil {
+	msg := template.New()
+	if err := unmarshaller.Unmarshal(data[d.SkipBytes:], msg.Interface()); err != nil {
 		hexbuf := hex.EncodeToString(buf)
 		d.Log.Debugf("raw data (hex): %q (skip %d bytes)", hexbuf, d.SkipBytes)
 		return nil, err
@@ -113,6 +280,50 @@ func (d *protobufDocument) Parse(buf []byte) (dataNode, error) {
 	return protobufquery.Parse(msg)
 }
 
+// unframe removes the schema-registry framing from the message
+func unframe(framing string, buf []byte) ([]byte, error) {
+	switch framing {
+	case "confluent":
+		// Magic byte, 4-byte schema ID and the message-index array encoded
+		// as zig-zag varints where a zero length refers to the first message
+		if len(buf) < 6 || buf[0] != 0 {
+			return nil, errors.New("message is not in Confluent wire format")
+		}
+		data := buf[5:]
+		count, n := binary.Varint(data)
+		if n <= 0 || count < 0 {
+			return nil, errors.New("invalid message-index length in Confluent wire format")
+		}
+		data = data[n:]
+		for i := int64(0); i < count; i++ {
+			_, n := binary.Varint(data)
+			if n <= 0 {
+				return nil, errors.New("invalid message-index in Confluent wire format")
+			}
+			data = data[n:]
+		}
+		return data, nil
+	case "glue":
+		// Header version, compression type and 16-byte schema version UUID
+		if len(buf) < 18 || buf[0] != 3 {
+			return nil, errors.New("message is not in Glue Schema Registry format")
+		}
+		switch buf[1] {
+		case 0:
+			return buf[18:], nil
+		case 5:
+			r, err := zlib.NewReader(bytes.NewReader(buf[18:]))
+			if err != nil {
+				return nil, fmt.Errorf("decompressing message failed: %w", err)
+			}
+			defer r.Close()
+			return io.ReadAll(r)
+		}
+		return nil, fmt.Errorf("unsupported Glue compression type %d", buf[1])
+	}
+	return buf, nil
+}
+
 func (d *protobufDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
 	// If this panics it's a programming error as we changed the document type while processing
 	native, err := protobufquery.QueryAll(node.(*protobufquery.Node), expr)
@@ -0,0 +1,8 @@
+
+g
+message.protonative_type"A
+Message
+a (	Ra
+b (Rb
+c (Rc
+d (Rdbproto3
\ No newline at end of file
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.dedup_key
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/dedup_key" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.json_expand
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/json_expand" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.protect
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/protect" // register plugin
@@ -22,6 +22,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/parallel"
+	"github.com/influxdata/telegraf/plugins/common/resolver"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -40,8 +41,10 @@ type AwsEc2Processor struct {
 	TagCacheSize          int             `toml:"tag_cache_size"`
 	LogCacheStats         bool            `toml:"log_cache_stats"`
 	Log                   telegraf.Logger `toml:"-"`
+	resolver.Config
 
 	tagCache *freecache.Cache
+	metadata *resolver.Cache
 
 	imdsClient          *imds.Client
 	ec2Client           *ec2.Client
@@ -119,11 +122,9 @@ func (r *AwsEc2Processor) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("failed loading default AWS config: %w", err)
 	}
 	r.imdsClient = imds.NewFromConfig(cfg)
+	r.metadata = resolver.Shared("ec2", r.Config, r.Log)
 
-	iido, err := r.imdsClient.GetInstanceIdentityDocument(
-		ctx,
-		&imds.GetInstanceIdentityDocumentInput{},
-	)
+	iido, err := r.identityDocument(ctx)
 	if err != nil {
 		return fmt.Errorf("failed getting instance identity document: %w", err)
 	}
@@ -167,6 +168,20 @@ func (r *AwsEc2Processor) Stop() {
 		r.cancelCleanupWorker()
 		r.cancelCleanupWorker = nil
 	}
+	r.metadata.Release()
+	r.metadata = nil
+}
+
+// identityDocument returns the instance identity document using the metadata
+// cache shared with other plugins
+func (r *AwsEc2Processor) identityDocument(ctx context.Context) (imds.InstanceIdentityDocument, error) {
+	return resolver.Lookup(ctx, r.metadata, "imds/identity", time.Duration(r.CacheTTL), func(ctx context.Context) (imds.InstanceIdentityDocument, error) {
+		out, err := r.imdsClient.GetInstanceIdentityDocument(ctx, &imds.GetInstanceIdentityDocumentInput{})
+		if err != nil {
+			return imds.InstanceIdentityDocument{}, err
+		}
+		return out.InstanceIdentityDocument, nil
+	})
 }
 
 func (r *AwsEc2Processor) logCacheStatistics(ctx context.Context) {
@@ -211,7 +226,7 @@ func (r *AwsEc2Processor) lookupIMDSTags(metric telegraf.Metric) telegraf.Metric
 		return metric
 	}
 
-	doc, err := r.imdsClient.GetInstanceIdentityDocument(ctx, &imds.GetInstanceIdentityDocumentInput{})
+	doc, err := r.identityDocument(ctx)
 	if err != nil {
 		r.Log.Errorf("Error when calling GetInstanceIdentityDocument: %v", err)
 		return metric
@@ -282,15 +297,16 @@ func (r *AwsEc2Processor) lookupMetadata(metric telegraf.Metric) telegraf.Metric
 		}
 
 		// Query the tag with the full path
-		resp, err := r.imdsClient.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
-		if err != nil {
-			r.Log.Errorf("Getting metadata %q failed: %v", path, err)
-			continue
-		}
-
-		value, err := io.ReadAll(resp.Content)
+		value, err := resolver.Lookup(ctx, r.metadata, "imds/metadata/"+path, time.Duration(r.CacheTTL), func(ctx context.Context) ([]byte, error) {
+			resp, err := r.imdsClient.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
+			if err != nil {
+				return nil, fmt.Errorf("getting metadata failed: %w", err)
+			}
+			defer resp.Content.Close()
+			return io.ReadAll(resp.Content)
+		})
 		if err != nil {
-			r.Log.Errorf("Reading metadata reponse for %+v failed: %v", path, err)
+			r.Log.Errorf("Querying metadata %q failed: %v", path, err)
 			continue
 		}
 		if len(value) > 0 {
@@ -3,11 +3,13 @@ package dedup
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/series"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
 	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -18,9 +20,14 @@ var sampleConfig string
 
 type Dedup struct {
 	DedupInterval config.Duration `toml:"dedup_interval"`
+	KeyTag        string          `toml:"key_tag"`
 	FlushTime     time.Time
 	Cache         map[uint64]telegraf.Metric
 	Log           telegraf.Logger `toml:"-"`
+	series.Config
+
+	series *series.Tracker
+	keys   map[string]time.Time
 }
 
 // Remove expired items from cache
@@ -34,9 +41,40 @@ func (d *Dedup) cleanup() {
 	for id, metric := range d.Cache {
 		if time.Since(metric.Time()) < time.Duration(d.DedupInterval) {
 			keep[id] = metric
+		} else {
+			d.series.Remove(id)
 		}
 	}
 	d.Cache = keep
+
+	for key, seen := range d.keys {
+		if time.Since(seen) >= time.Duration(d.DedupInterval) {
+			delete(d.keys, key)
+		}
+	}
+}
+
+// Check the key tag of the metric, if present, against the keys seen within
+// the dedup interval and remove the tag. Returns if the metric should be kept
+// and if the metric carries a key at all.
+func (d *Dedup) applyKey(metric telegraf.Metric) (keep, found bool) {
+	key, found := metric.GetTag(d.KeyTag)
+	if !found {
+		return false, false
+	}
+	metric.RemoveTag(d.KeyTag)
+
+	if seen, ok := d.keys[key]; ok && time.Since(seen) < time.Duration(d.DedupInterval) {
+		return false, true
+	}
+
+	// The keys are created on first use as the state might be restored
+	// before initializing the plugin
+	if d.keys == nil {
+		d.keys = make(map[string]time.Time)
+	}
+	d.keys[key] = metric.Time()
+	return true, true
 }
 
 // Save item to cache
@@ -49,11 +87,44 @@ func (*Dedup) SampleConfig() string {
 	return sampleConfig
 }
 
+func (d *Dedup) Init() error {
+	if d.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+	if d.MaxSeries > 0 || d.SeriesTTL > 0 {
+		d.series = series.NewTracker(d.Config, map[string]string{"processor": "dedup"}, d.Log)
+
+		// The state is restored before initializing the plugin so track the
+		// series already in the cache
+		for id := range d.Cache {
+			for _, evicted := range d.series.Touch(id) {
+				delete(d.Cache, evicted)
+			}
+		}
+	}
+	return nil
+}
+
 // main processing method
 func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 	idx := 0
 	for _, metric := range metrics {
+		if d.KeyTag != "" {
+			if keep, found := d.applyKey(metric); found {
+				if keep {
+					metrics[idx] = metric
+					idx++
+				} else {
+					metric.Drop()
+				}
+				continue
+			}
+		}
+
 		id := metric.HashID()
+		for _, evicted := range d.series.Touch(id) {
+			delete(d.Cache, evicted)
+		}
 		m, ok := d.Cache[id]
 
 		// If not in cache then just save it
@@ -117,6 +188,9 @@ func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 		metric.Drop()
 	}
 	metrics = metrics[:idx]
+	for _, id := range d.series.Expire() {
+		delete(d.Cache, id)
+	}
 	d.cleanup()
 	return metrics
 }
@@ -144,9 +218,10 @@ func (d *Dedup) SetState(state interface{}) error {
 		return fmt.Errorf("state has wrong type %T", state)
 	}
 	metrics, err := p.Parse(data)
-	if err == nil {
-		d.Apply(metrics...)
+	if err != nil {
+		return fmt.Errorf("parsing state failed: %w", err)
 	}
+	d.Apply(metrics...)
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package dedup
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/series"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -457,3 +459,156 @@ func TestTracking(t *testing.T) {
 		return len(input) == len(delivered)
 	}, time.Second, 100*time.Millisecond, "%d delivered but %d expected", len(delivered), len(expected))
 }
+
+func TestMaxSeries(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+		Config:        series.Config{MaxSeries: 2},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("m1", map[string]string{"tag": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("m1", map[string]string{"tag": "b"}, map[string]interface{}{"value": 1}, now),
+		metric.New("m1", map[string]string{"tag": "c"}, map[string]interface{}{"value": 1}, now),
+	}
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 3)
+	require.Len(t, plugin.Cache, 2)
+	require.NotContains(t, plugin.Cache, input[0].HashID())
+
+	// The evicted series is not deduplicated anymore
+	actual = plugin.Apply(
+		metric.New("m1", map[string]string{"tag": "a"}, map[string]interface{}{"value": 1}, now.Add(time.Second)),
+		metric.New("m1", map[string]string{"tag": "c"}, map[string]interface{}{"value": 1}, now.Add(time.Second)),
+	)
+	require.Len(t, actual, 1)
+	require.Equal(t, "a", actual[0].Tags()["tag"])
+	require.Len(t, plugin.Cache, 2)
+}
+
+func TestMaxSeriesRestoredState(t *testing.T) {
+	now := time.Now()
+	source := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+	require.NoError(t, source.Init())
+	source.Apply(
+		metric.New("m1", map[string]string{"tag": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("m1", map[string]string{"tag": "b"}, map[string]interface{}{"value": 1}, now),
+	)
+
+	// The state is restored before initializing the plugin
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+		Config:        series.Config{MaxSeries: 2},
+	}
+	require.NoError(t, plugin.SetState(source.GetState()))
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 2, plugin.series.Len())
+
+	// New series evict the restored ones
+	actual := plugin.Apply(
+		metric.New("m1", map[string]string{"tag": "c"}, map[string]interface{}{"value": 1}, now),
+		metric.New("m1", map[string]string{"tag": "d"}, map[string]interface{}{"value": 1}, now),
+	)
+	require.Len(t, actual, 2)
+	require.Len(t, plugin.Cache, 2)
+	require.Equal(t, 2, plugin.series.Len())
+}
+
+func TestKeyTagRestoredState(t *testing.T) {
+	now := time.Now()
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		KeyTag:        "dedup_key",
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+
+	// The state is restored before initializing the plugin
+	state := fmt.Appendf(nil, "snmp,host=a,dedup_key=k1 value=1 %d\n", now.UnixNano())
+	require.NoError(t, plugin.SetState(state))
+	require.NoError(t, plugin.Init())
+
+	// The restored key is deduplicated
+	actual := plugin.Apply(
+		metric.New("snmp", map[string]string{"host": "b", "dedup_key": "k1"}, map[string]interface{}{"value": 2}, now),
+	)
+	require.Empty(t, actual)
+}
+
+func TestSetStateInvalid(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+	require.ErrorContains(t, plugin.SetState([]byte("snmp value=")), "parsing state failed")
+}
+
+func TestSeriesTTL(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+		Config:        series.Config{SeriesTTL: config.Duration(time.Millisecond)},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	a := metric.New("m1", map[string]string{"tag": "a"}, map[string]interface{}{"value": 1}, now)
+	b := metric.New("m1", map[string]string{"tag": "b"}, map[string]interface{}{"value": 1}, now)
+	require.Len(t, plugin.Apply(a.Copy(), b.Copy()), 2)
+
+	// Series not seen within the time-to-live are removed from the cache
+	time.Sleep(10 * time.Millisecond)
+	require.Empty(t, plugin.Apply(b.Copy()))
+	require.Len(t, plugin.Cache, 1)
+	require.Contains(t, plugin.Cache, b.HashID())
+}
+
+func TestKeyTag(t *testing.T) {
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		KeyTag:        "dedup_key",
+		FlushTime:     time.Now(),
+		Cache:         make(map[uint64]telegraf.Metric),
+	}
+	require.NoError(t, plugin.Init())
+
+	// Metrics of two agents gathering the same source with different values
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("snmp", map[string]string{"host": "a", "dedup_key": "k1"}, map[string]interface{}{"value": 1}, now),
+		metric.New("snmp", map[string]string{"host": "b", "dedup_key": "k1"}, map[string]interface{}{"value": 2}, now),
+		metric.New("snmp", map[string]string{"host": "b", "dedup_key": "k2"}, map[string]interface{}{"value": 3}, now),
+		metric.New("snmp", map[string]string{"host": "a", "dedup_key": "k2"}, map[string]interface{}{"value": 3}, now),
+		metric.New("snmp", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("snmp", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, now),
+		metric.New("snmp", map[string]string{"host": "b"}, map[string]interface{}{"value": 3}, now),
+		metric.New("snmp", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, now),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Len(t, plugin.keys, 2)
+
+	// Expired keys pass again and are removed from the cache
+	plugin.keys["k1"] = now.Add(-time.Hour)
+	plugin.FlushTime = now.Add(-time.Hour)
+	actual = plugin.Apply(
+		metric.New("snmp", map[string]string{"host": "b", "dedup_key": "k1"}, map[string]interface{}{"value": 2}, now.Add(-time.Hour)),
+	)
+	require.Len(t, actual, 1)
+	require.NotContains(t, plugin.keys, "k1")
+	require.Contains(t, plugin.keys, "k2")
+}
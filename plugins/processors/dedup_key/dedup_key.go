@@ -0,0 +1,96 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dedup_key
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DedupKey struct {
+	KeyTag        string          `toml:"key_tag"`
+	ExcludeTags   []string        `toml:"exclude_tags"`
+	TimePrecision config.Duration `toml:"time_precision"`
+}
+
+func (*DedupKey) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DedupKey) Init() error {
+	if d.KeyTag == "" {
+		return errors.New("'key_tag' must not be empty")
+	}
+	if d.TimePrecision < 0 {
+		return errors.New("'time_precision' must not be negative")
+	}
+	return nil
+}
+
+func (d *DedupKey) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		m.AddTag(d.KeyTag, d.key(m))
+	}
+	return in
+}
+
+// key computes a hash of the metric's name, tags, field keys and timestamp.
+// Field values are not included as agents gathering the same source at
+// slightly different times will see different values, e.g. for counters.
+func (d *DedupKey) key(m telegraf.Metric) string {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte{0})
+
+	// The tag list is sorted by key
+	for _, tag := range m.TagList() {
+		if tag.Key == d.KeyTag || slices.Contains(d.ExcludeTags, tag.Key) {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte{0})
+		h.Write([]byte(tag.Value))
+		h.Write([]byte{0})
+	}
+
+	fields := make([]string, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		fields = append(fields, field.Key)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+
+	ts := m.Time()
+	if d.TimePrecision > 0 {
+		ts = ts.Round(time.Duration(d.TimePrecision))
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(ts.UnixNano()))
+	h.Write(buf[:])
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func init() {
+	processors.Add("dedup_key", func() telegraf.Processor {
+		return &DedupKey{
+			KeyTag:      "dedup_key",
+			ExcludeTags: []string{"host"},
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package dedup_key
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestKey(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := &DedupKey{
+		KeyTag:        "dedup_key",
+		ExcludeTags:   []string{"host"},
+		TimePrecision: config.Duration(10 * time.Second),
+	}
+	require.NoError(t, plugin.Init())
+
+	// Metrics of two agents gathering the same source at slightly different
+	// times must share the same key
+	a := metric.New("snmp",
+		map[string]string{"host": "agent-a", "agent_host": "10.0.0.1"},
+		map[string]interface{}{"ifInOctets": 100, "ifOutOctets": 200},
+		now.Add(-20*time.Millisecond),
+	)
+	b := metric.New("snmp",
+		map[string]string{"agent_host": "10.0.0.1", "host": "agent-b"},
+		map[string]interface{}{"ifOutOctets": 210, "ifInOctets": 105},
+		now.Add(30*time.Millisecond),
+	)
+	actual := plugin.Apply(a, b)
+	require.Len(t, actual, 2)
+	keyA, found := actual[0].GetTag("dedup_key")
+	require.True(t, found)
+	require.NotEmpty(t, keyA)
+	keyB, found := actual[1].GetTag("dedup_key")
+	require.True(t, found)
+	require.Equal(t, keyA, keyB)
+
+	// Applying the processor again must not change the key
+	again := plugin.Apply(a.Copy())
+	require.Equal(t, keyA, again[0].Tags()["dedup_key"])
+}
+
+func TestKeyDiffers(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	reference := metric.New("snmp",
+		map[string]string{"agent_host": "10.0.0.1"},
+		map[string]interface{}{"value": 1},
+		now,
+	)
+
+	tests := []struct {
+		name   string
+		metric telegraf.Metric
+	}{
+		{
+			name:   "name",
+			metric: metric.New("snmp2", map[string]string{"agent_host": "10.0.0.1"}, map[string]interface{}{"value": 1}, now),
+		},
+		{
+			name:   "tag",
+			metric: metric.New("snmp", map[string]string{"agent_host": "10.0.0.2"}, map[string]interface{}{"value": 1}, now),
+		},
+		{
+			name:   "field key",
+			metric: metric.New("snmp", map[string]string{"agent_host": "10.0.0.1"}, map[string]interface{}{"other": 1}, now),
+		},
+		{
+			name:   "time",
+			metric: metric.New("snmp", map[string]string{"agent_host": "10.0.0.1"}, map[string]interface{}{"value": 1}, now.Add(time.Second)),
+		},
+	}
+
+	plugin := &DedupKey{KeyTag: "dedup_key"}
+	require.NoError(t, plugin.Init())
+	expected := plugin.Apply(reference.Copy())[0].Tags()["dedup_key"]
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := plugin.Apply(tt.metric)
+			require.NotEqual(t, expected, actual[0].Tags()["dedup_key"])
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	plugin := &DedupKey{}
+	require.ErrorContains(t, plugin.Init(), "'key_tag' must not be empty")
+
+	plugin = &DedupKey{KeyTag: "dedup_key", TimePrecision: config.Duration(-time.Second)}
+	require.ErrorContains(t, plugin.Init(), "'time_precision' must not be negative")
+}
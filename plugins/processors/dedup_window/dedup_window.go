@@ -0,0 +1,218 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dedup_window
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DedupWindow struct {
+	Window       config.Duration `toml:"window"`
+	KeyTags      []string        `toml:"key_tags"`
+	KeyFields    string          `toml:"key_fields"`
+	Fields       []string        `toml:"fields"`
+	KeyTimestamp bool            `toml:"key_timestamp"`
+	MaxKeys      int             `toml:"max_keys"`
+	Log          telegraf.Logger `toml:"-"`
+
+	tagFilter   filter.Filter
+	fieldFilter filter.Filter
+	seen        map[uint64]time.Time
+	order       []entry
+	dropped     selfstat.Stat
+
+	// Mockable time for testing
+	now func() time.Time
+}
+
+// entry is a key in the order it was first seen
+type entry struct {
+	key  uint64
+	seen time.Time
+}
+
+func (*DedupWindow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DedupWindow) Init() error {
+	if d.Window <= 0 {
+		return errors.New("'window' must be positive")
+	}
+	if d.MaxKeys < 0 {
+		return errors.New("'max_keys' must not be negative")
+	}
+
+	switch d.KeyFields {
+	case "":
+		d.KeyFields = "values"
+	case "none", "names", "values":
+	default:
+		return fmt.Errorf("invalid 'key_fields' value %q", d.KeyFields)
+	}
+
+	if len(d.KeyTags) == 0 {
+		d.KeyTags = []string{"*"}
+	}
+	var err error
+	if d.tagFilter, err = filter.Compile(d.KeyTags); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if len(d.Fields) == 0 {
+		d.Fields = []string{"*"}
+	}
+	if d.fieldFilter, err = filter.Compile(d.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	d.seen = make(map[uint64]time.Time)
+	d.dropped = selfstat.Register("dedup_window", "dropped", map[string]string{})
+	if d.now == nil {
+		d.now = time.Now
+	}
+
+	return nil
+}
+
+func (d *DedupWindow) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	now := d.now()
+	d.expire(now)
+
+	idx := 0
+	for _, m := range metrics {
+		key := d.key(m)
+		if _, found := d.seen[key]; found {
+			d.dropped.Incr(1)
+			m.Drop()
+			continue
+		}
+		d.seen[key] = now
+		d.order = append(d.order, entry{key: key, seen: now})
+		if d.MaxKeys > 0 && len(d.order) > d.MaxKeys {
+			delete(d.seen, d.order[0].key)
+			d.order = d.order[1:]
+		}
+
+		metrics[idx] = m
+		idx++
+	}
+	return metrics[:idx]
+}
+
+// expire removes the keys seen before the window. As keys are only added
+// and the time is monotonic, the keys are ordered by the time they were seen.
+func (d *DedupWindow) expire(now time.Time) {
+	cutoff := now.Add(-time.Duration(d.Window))
+	n := sort.Search(len(d.order), func(i int) bool {
+		return d.order[i].seen.After(cutoff)
+	})
+	if n == 0 {
+		return
+	}
+	for _, e := range d.order[:n] {
+		delete(d.seen, e.key)
+	}
+	// Copy the remaining keys to release the memory of the expired ones
+	d.order = append(make([]entry, 0, len(d.order)-n), d.order[n:]...)
+}
+
+// key computes a hash of the metric name, the selected tags and optionally
+// the fields and timestamp
+func (d *DedupWindow) key(m telegraf.Metric) uint64 {
+	h := fnv.New64a()
+	writeString(h, m.Name())
+
+	// The tag list is sorted by key
+	for _, tag := range m.TagList() {
+		if !d.tagFilter.Match(tag.Key) {
+			continue
+		}
+		writeString(h, tag.Key)
+		writeString(h, tag.Value)
+	}
+
+	if d.KeyFields != "none" {
+		fields := make([]*telegraf.Field, 0, len(m.FieldList()))
+		for _, field := range m.FieldList() {
+			if d.fieldFilter.Match(field.Key) {
+				fields = append(fields, field)
+			}
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+		for _, field := range fields {
+			writeString(h, field.Key)
+			if d.KeyFields == "values" {
+				writeValue(h, field.Value)
+			}
+		}
+	}
+
+	if d.KeyTimestamp {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(m.Time().UnixNano()))
+		h.Write(buf[:])
+	}
+
+	return h.Sum64()
+}
+
+func writeString(h hash.Hash64, s string) {
+	h.Write([]byte(s))
+	h.Write([]byte{0})
+}
+
+// writeValue writes the value including its type, so values of different
+// types are not considered equal
+func writeValue(h hash.Hash64, v interface{}) {
+	var buf [9]byte
+	switch v := v.(type) {
+	case int64:
+		buf[0] = 'i'
+		binary.BigEndian.PutUint64(buf[1:], uint64(v))
+	case uint64:
+		buf[0] = 'u'
+		binary.BigEndian.PutUint64(buf[1:], v)
+	case float64:
+		buf[0] = 'f'
+		binary.BigEndian.PutUint64(buf[1:], math.Float64bits(v))
+	case bool:
+		buf[0] = 'b'
+		if v {
+			buf[1] = 1
+		}
+	case string:
+		h.Write([]byte{'s'})
+		writeString(h, v)
+		return
+	default:
+		h.Write([]byte{'?'})
+		writeString(h, fmt.Sprint(v))
+		return
+	}
+	h.Write(buf[:])
+}
+
+func init() {
+	processors.Add("dedup_window", func() telegraf.Processor {
+		return &DedupWindow{
+			Window:       config.Duration(5 * time.Minute),
+			KeyTimestamp: true,
+		}
+	})
+}
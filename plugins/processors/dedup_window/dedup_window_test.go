@@ -0,0 +1,203 @@
+package dedup_window
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DedupWindow
+		expected string
+	}{
+		{
+			name:     "missing window",
+			plugin:   &DedupWindow{},
+			expected: "'window' must be positive",
+		},
+		{
+			name:     "negative max keys",
+			plugin:   &DedupWindow{Window: config.Duration(time.Minute), MaxKeys: -1},
+			expected: "'max_keys' must not be negative",
+		},
+		{
+			name:     "invalid key fields",
+			plugin:   &DedupWindow{Window: config.Duration(time.Minute), KeyFields: "types"},
+			expected: `invalid 'key_fields' value "types"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		plugin   *DedupWindow
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "replayed metrics",
+			plugin: &DedupWindow{KeyTimestamp: true},
+			input: []telegraf.Metric{
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "2"}, map[string]interface{}{"value": 42, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"id": "a", "value": 42}, ts),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42, "id": "a"}, ts.Add(time.Second)),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 43, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42.0, "id": "a"}, ts),
+			},
+			expected: []telegraf.Metric{
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "2"}, map[string]interface{}{"value": 42, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42, "id": "a"}, ts.Add(time.Second)),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 43, "id": "a"}, ts),
+				metric.New("orders", map[string]string{"shard": "1"}, map[string]interface{}{"value": 42.0, "id": "a"}, ts),
+			},
+		},
+		{
+			name:   "keyed by selected tags and fields",
+			plugin: &DedupWindow{KeyTags: []string{"order_*"}, Fields: []string{"id"}},
+			input: []telegraf.Metric{
+				metric.New("orders", map[string]string{"order_region": "eu", "consumer": "a"}, map[string]interface{}{"id": "o1", "amount": 10}, ts),
+				metric.New("orders", map[string]string{"order_region": "eu", "consumer": "b"}, map[string]interface{}{"id": "o1", "amount": 11}, ts.Add(time.Second)),
+				metric.New("orders", map[string]string{"order_region": "us", "consumer": "b"}, map[string]interface{}{"id": "o1", "amount": 11}, ts),
+				metric.New("orders", map[string]string{"order_region": "eu", "consumer": "b"}, map[string]interface{}{"id": "o2", "amount": 11}, ts),
+			},
+			expected: []telegraf.Metric{
+				metric.New("orders", map[string]string{"order_region": "eu", "consumer": "a"}, map[string]interface{}{"id": "o1", "amount": 10}, ts),
+				metric.New("orders", map[string]string{"order_region": "us", "consumer": "b"}, map[string]interface{}{"id": "o1", "amount": 11}, ts),
+				metric.New("orders", map[string]string{"order_region": "eu", "consumer": "b"}, map[string]interface{}{"id": "o2", "amount": 11}, ts),
+			},
+		},
+		{
+			name:   "keyed by field names",
+			plugin: &DedupWindow{KeyFields: "names"},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 10}, ts),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 20}, ts),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 80}, ts),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 10}, ts),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 80}, ts),
+			},
+		},
+		{
+			name:   "keyed by name and tags only",
+			plugin: &DedupWindow{KeyFields: "none"},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 10}, ts),
+				metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"idle": 80}, ts.Add(time.Second)),
+				metric.New("mem", map[string]string{"cpu": "0"}, map[string]interface{}{"idle": 80}, ts),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"cpu": "0"}, map[string]interface{}{"usage": 10}, ts),
+				metric.New("mem", map[string]string{"cpu": "0"}, map[string]interface{}{"idle": 80}, ts),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Window = config.Duration(time.Minute)
+			require.NoError(t, tt.plugin.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, tt.plugin.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWindowExpiry(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	now := ts
+
+	plugin := &DedupWindow{
+		Window:       config.Duration(time.Minute),
+		KeyTimestamp: true,
+		now:          func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("orders", map[string]string{}, map[string]interface{}{"value": 42}, ts)
+	require.Len(t, plugin.Apply(m.Copy()), 1)
+
+	// Duplicates within the window are dropped without extending it
+	now = ts.Add(59 * time.Second)
+	require.Empty(t, plugin.Apply(m.Copy()))
+
+	now = ts.Add(61 * time.Second)
+	require.Len(t, plugin.Apply(m.Copy()), 1)
+	require.Len(t, plugin.seen, 1)
+	require.Len(t, plugin.order, 1)
+}
+
+func TestMaxKeys(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	plugin := &DedupWindow{
+		Window:  config.Duration(time.Hour),
+		MaxKeys: 2,
+	}
+	require.NoError(t, plugin.Init())
+
+	a := metric.New("orders", map[string]string{}, map[string]interface{}{"id": "a"}, ts)
+	b := metric.New("orders", map[string]string{}, map[string]interface{}{"id": "b"}, ts)
+	c := metric.New("orders", map[string]string{}, map[string]interface{}{"id": "c"}, ts)
+	require.Len(t, plugin.Apply(a.Copy(), b.Copy(), c.Copy()), 3)
+
+	// The oldest key was evicted, so its metric passes again
+	require.Len(t, plugin.Apply(a.Copy()), 1)
+	require.Empty(t, plugin.Apply(c.Copy()))
+	require.Len(t, plugin.seen, 2)
+}
+
+func TestTracking(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	plugin := &DedupWindow{Window: config.Duration(time.Minute)}
+	require.NoError(t, plugin.Init())
+
+	first, _ := metric.WithTracking(metric.New("orders", map[string]string{}, map[string]interface{}{"id": "a"}, ts), notify)
+	second, _ := metric.WithTracking(metric.New("orders", map[string]string{}, map[string]interface{}{"id": "a"}, ts), notify)
+	actual := plugin.Apply(first, second)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// The dropped duplicate must be delivered as well
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 2
+	}, time.Second, 10*time.Millisecond)
+}
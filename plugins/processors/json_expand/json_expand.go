@@ -0,0 +1,217 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package json_expand
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JSONExpand struct {
+	Fields       []string        `toml:"fields"`
+	KeepOriginal bool            `toml:"keep_original"`
+	MaxDepth     int             `toml:"max_depth"`
+	ArrayMode    string          `toml:"array_mode"`
+	NameTemplate string          `toml:"name_template"`
+	Separator    string          `toml:"separator"`
+	TagKeys      []string        `toml:"tag_keys"`
+	Integers     bool            `toml:"integers"`
+	Log          telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	tagFilter   filter.Filter
+	tmpl        *template.Template
+}
+
+// element passed to the name template
+type element struct {
+	Field string
+	Path  string
+	Key   string
+}
+
+func (*JSONExpand) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JSONExpand) Init() error {
+	if len(j.Fields) == 0 {
+		return errors.New("no fields given")
+	}
+	f, err := filter.Compile(j.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	j.fieldFilter = f
+
+	if len(j.TagKeys) > 0 {
+		f, err := filter.Compile(j.TagKeys)
+		if err != nil {
+			return fmt.Errorf("creating tag filter failed: %w", err)
+		}
+		j.tagFilter = f
+	}
+
+	if j.MaxDepth < 0 {
+		return errors.New("'max_depth' must not be negative")
+	}
+
+	switch j.ArrayMode {
+	case "":
+		j.ArrayMode = "index"
+	case "index", "json", "skip":
+	default:
+		return fmt.Errorf("invalid array mode %q", j.ArrayMode)
+	}
+
+	if j.NameTemplate == "" {
+		j.NameTemplate = "{{.Field}}_{{.Path}}"
+	}
+	tmpl, err := template.New("name template").Option("missingkey=error").Parse(j.NameTemplate)
+	if err != nil {
+		return fmt.Errorf("creating name template failed: %w", err)
+	}
+	j.tmpl = tmpl
+
+	return nil
+}
+
+func (j *JSONExpand) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		// Copy the field list as fields are added and removed while iterating
+		fields := append([]*telegraf.Field(nil), m.FieldList()...)
+		for _, field := range fields {
+			if !j.fieldFilter.Match(field.Key) {
+				continue
+			}
+			raw, ok := field.Value.(string)
+			if !ok {
+				continue
+			}
+
+			decoder := json.NewDecoder(strings.NewReader(raw))
+			decoder.UseNumber()
+			var doc interface{}
+			if err := decoder.Decode(&doc); err != nil {
+				j.Log.Errorf("Decoding field %q of metric %q failed: %v", field.Key, m.Name(), err)
+				continue
+			}
+
+			if !j.KeepOriginal {
+				m.RemoveField(field.Key)
+			}
+			if err := j.expand(m, field.Key, nil, doc); err != nil {
+				j.Log.Errorf("Expanding field %q of metric %q failed: %v", field.Key, m.Name(), err)
+			}
+		}
+	}
+	return in
+}
+
+func (j *JSONExpand) expand(m telegraf.Metric, field string, path []string, value interface{}) error {
+	depthExceeded := j.MaxDepth > 0 && len(path) >= j.MaxDepth
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if depthExceeded && len(path) > 0 {
+			return j.add(m, field, path, value)
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			child := v[key]
+			if err := j.expand(m, field, append(path, key), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if j.ArrayMode == "skip" {
+			return nil
+		}
+		if j.ArrayMode == "json" || depthExceeded && len(path) > 0 {
+			return j.add(m, field, path, value)
+		}
+		for i, child := range v {
+			if err := j.expand(m, field, append(path, strconv.Itoa(i)), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		return nil
+	}
+	return j.add(m, field, path, value)
+}
+
+func (j *JSONExpand) add(m telegraf.Metric, field string, path []string, value interface{}) error {
+	e := element{
+		Field: field,
+		Path:  strings.Join(path, j.Separator),
+	}
+
+	// Scalar documents replace the original field
+	name := field
+	if len(path) > 0 {
+		e.Key = path[len(path)-1]
+
+		var buf bytes.Buffer
+		if err := j.tmpl.Execute(&buf, e); err != nil {
+			return fmt.Errorf("executing name template failed: %w", err)
+		}
+		name = buf.String()
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		value = j.number(v)
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encoding %q failed: %w", e.Path, err)
+		}
+		value = string(encoded)
+	}
+
+	if j.tagFilter != nil && j.tagFilter.Match(e.Path) {
+		m.AddTag(name, fmt.Sprint(value))
+		return nil
+	}
+	m.AddField(name, value)
+	return nil
+}
+
+func (j *JSONExpand) number(n json.Number) interface{} {
+	if j.Integers {
+		if v, err := n.Int64(); err == nil {
+			return v
+		}
+	}
+	v, err := n.Float64()
+	if err != nil {
+		// Out of range numbers are kept as string
+		return n.String()
+	}
+	return v
+}
+
+func init() {
+	processors.Add("json_expand", func() telegraf.Processor {
+		return &JSONExpand{
+			Separator: "_",
+			Integers:  true,
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package json_expand
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const document = `{"id":"a1","temp":21.5,"count":3,"ok":true,"none":null,"meta":{"site":"north","rack":{"row":2}},"values":[1,2]}`
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONExpand
+		expected string
+	}{
+		{
+			name:     "no fields",
+			plugin:   &JSONExpand{},
+			expected: "no fields given",
+		},
+		{
+			name:     "invalid array mode",
+			plugin:   &JSONExpand{Fields: []string{"payload"}, ArrayMode: "flatten"},
+			expected: `invalid array mode "flatten"`,
+		},
+		{
+			name:     "negative depth",
+			plugin:   &JSONExpand{Fields: []string{"payload"}, MaxDepth: -1},
+			expected: "'max_depth' must not be negative",
+		},
+		{
+			name:     "invalid template",
+			plugin:   &JSONExpand{Fields: []string{"payload"}, NameTemplate: "{{.Path"},
+			expected: "creating name template failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JSONExpand
+		expected telegraf.Metric
+	}{
+		{
+			name: "defaults",
+			plugin: &JSONExpand{
+				Fields:    []string{"payload"},
+				Separator: "_",
+				Integers:  true,
+			},
+			expected: metric.New(
+				"queue",
+				map[string]string{"source": "kinesis"},
+				map[string]interface{}{
+					"payload_id":            "a1",
+					"payload_temp":          21.5,
+					"payload_count":         int64(3),
+					"payload_ok":            true,
+					"payload_meta_site":     "north",
+					"payload_meta_rack_row": int64(2),
+					"payload_values_0":      int64(1),
+					"payload_values_1":      int64(2),
+					"offset":                int64(42),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "depth limit and json arrays",
+			plugin: &JSONExpand{
+				Fields:       []string{"pay*"},
+				KeepOriginal: true,
+				MaxDepth:     2,
+				ArrayMode:    "json",
+				NameTemplate: "{{.Path}}",
+				Separator:    ".",
+			},
+			expected: metric.New(
+				"queue",
+				map[string]string{"source": "kinesis"},
+				map[string]interface{}{
+					"payload":   document,
+					"id":        "a1",
+					"temp":      21.5,
+					"count":     3.0,
+					"ok":        true,
+					"meta.site": "north",
+					"meta.rack": `{"row":2}`,
+					"values":    "[1,2]",
+					"offset":    int64(42),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "tags and skipped arrays",
+			plugin: &JSONExpand{
+				Fields:       []string{"payload"},
+				ArrayMode:    "skip",
+				NameTemplate: "{{.Key}}",
+				Separator:    "_",
+				TagKeys:      []string{"id", "meta_*"},
+				Integers:     true,
+			},
+			expected: metric.New(
+				"queue",
+				map[string]string{"source": "kinesis", "id": "a1", "site": "north", "row": "2"},
+				map[string]interface{}{
+					"temp":   21.5,
+					"count":  int64(3),
+					"ok":     true,
+					"offset": int64(42),
+				},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			input := metric.New(
+				"queue",
+				map[string]string{"source": "kinesis"},
+				map[string]interface{}{"payload": document, "offset": int64(42)},
+				time.Unix(0, 0),
+			)
+			actual := tt.plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestApplyScalarAndInvalid(t *testing.T) {
+	plugin := &JSONExpand{
+		Fields:    []string{"*"},
+		Separator: "_",
+		Integers:  true,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New(
+		"queue",
+		map[string]string{},
+		map[string]interface{}{"number": "42", "broken": "{", "plain": int64(1)},
+		time.Unix(0, 0),
+	)
+	expected := metric.New(
+		"queue",
+		map[string]string{},
+		map[string]interface{}{"number": int64(42), "broken": "{", "plain": int64(1)},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(input))
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 1)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	plugin := &JSONExpand{
+		Fields:    []string{"payload"},
+		Separator: "_",
+		Integers:  true,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("queue", map[string]string{}, map[string]interface{}{"payload": document}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(input, notify)
+
+	actual := plugin.Apply(tm)
+	require.Len(t, actual, 1)
+	require.Equal(t, int64(3), actual[0].Fields()["payload_count"])
+	actual[0].Accept()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 1
+	}, time.Second, 100*time.Millisecond)
+}
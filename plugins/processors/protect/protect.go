@@ -0,0 +1,320 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package protect
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Prefix of encrypted field values
+const encryptedPrefix = "enc:"
+
+type Protect struct {
+	Mode          string          `toml:"mode"`
+	EncryptFields []string        `toml:"encrypt_fields"`
+	EncryptionKey config.Secret   `toml:"encryption_key"`
+	SignatureTag  string          `toml:"signature_tag"`
+	SigningKey    config.Secret   `toml:"signing_key"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fields filter.Filter
+	aead   cipher.AEAD
+	mac    hash.Hash
+}
+
+func (*Protect) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Protect) Init() error {
+	switch p.Mode {
+	case "":
+		p.Mode = "protect"
+	case "protect", "unprotect":
+	default:
+		return fmt.Errorf("invalid mode %q", p.Mode)
+	}
+
+	if len(p.EncryptFields) == 0 && p.SignatureTag == "" {
+		return errors.New("either 'encrypt_fields' or 'signature_tag' must be set")
+	}
+
+	if len(p.EncryptFields) > 0 {
+		if p.EncryptionKey.Empty() {
+			return errors.New("'encryption_key' is required for encrypting fields")
+		}
+		key, err := decodeKey(&p.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("invalid 'encryption_key': %w", err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return fmt.Errorf("invalid 'encryption_key': %w", err)
+		}
+		if p.aead, err = cipher.NewGCM(block); err != nil {
+			return fmt.Errorf("creating cipher failed: %w", err)
+		}
+		if p.fields, err = filter.Compile(p.EncryptFields); err != nil {
+			return fmt.Errorf("creating field filter failed: %w", err)
+		}
+	}
+
+	if p.SignatureTag != "" {
+		if p.SigningKey.Empty() {
+			return errors.New("'signing_key' is required for signing metrics")
+		}
+		key, err := decodeKey(&p.SigningKey)
+		if err != nil {
+			return fmt.Errorf("invalid 'signing_key': %w", err)
+		}
+		if len(key) < 16 {
+			return errors.New("'signing_key' must be at least 128 bits long")
+		}
+		p.mac = hmac.New(sha256.New, key)
+	}
+
+	return nil
+}
+
+// Apply protects or unprotects the metrics depending on the mode. Metrics
+// failing to do so are dropped to neither leak unencrypted values nor pass
+// on forged metrics.
+func (p *Protect) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		var err error
+		if p.Mode == "unprotect" {
+			err = p.unprotect(m)
+		} else {
+			err = p.protect(m)
+		}
+		if err != nil {
+			p.Log.Errorf("Dropping metric %q: %v", m.Name(), err)
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// protect encrypts the selected fields and signs the resulting metric, i.e.
+// the signature covers the encrypted values
+func (p *Protect) protect(m telegraf.Metric) error {
+	if p.aead != nil {
+		for _, field := range m.FieldList() {
+			if !p.fields.Match(field.Key) {
+				continue
+			}
+			encrypted, err := p.encrypt(m.Name(), field.Key, field.Value)
+			if err != nil {
+				return fmt.Errorf("encrypting field %q failed: %w", field.Key, err)
+			}
+			m.AddField(field.Key, encrypted)
+		}
+	}
+
+	if p.mac != nil {
+		m.AddTag(p.SignatureTag, p.sign(m))
+	}
+	return nil
+}
+
+// unprotect verifies the signature of the metric and decrypts the selected
+// fields, the signature tag is removed
+func (p *Protect) unprotect(m telegraf.Metric) error {
+	if p.mac != nil {
+		signature, found := m.GetTag(p.SignatureTag)
+		if !found {
+			return errors.New("signature missing")
+		}
+		m.RemoveTag(p.SignatureTag)
+		expected, err := hex.DecodeString(signature)
+		if err != nil || !hmac.Equal(expected, p.signature(m)) {
+			return errors.New("signature mismatch")
+		}
+	}
+
+	if p.aead != nil {
+		for _, field := range m.FieldList() {
+			if !p.fields.Match(field.Key) {
+				continue
+			}
+			encrypted, ok := field.Value.(string)
+			if !ok || !strings.HasPrefix(encrypted, encryptedPrefix) {
+				continue
+			}
+			value, err := p.decrypt(m.Name(), field.Key, encrypted)
+			if err != nil {
+				return fmt.Errorf("decrypting field %q failed: %w", field.Key, err)
+			}
+			m.AddField(field.Key, value)
+		}
+	}
+	return nil
+}
+
+// encrypt returns the encrypted field value as "enc:<base64>" with the
+// base64 part containing the nonce followed by the sealed value. The value
+// is prefixed by its type to restore it on decryption. The measurement and
+// field name are authenticated to prevent moving values between fields.
+func (p *Protect) encrypt(name, key string, value interface{}) (string, error) {
+	plaintext, err := appendValue(nil, value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, p.aead.NonceSize(), p.aead.NonceSize()+len(plaintext)+p.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce failed: %w", err)
+	}
+	sealed := p.aead.Seal(nonce, nonce, plaintext, additionalData(name, key))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (p *Protect) decrypt(name, key, value string) (interface{}, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decoding value failed: %w", err)
+	}
+	if len(sealed) < p.aead.NonceSize() {
+		return nil, errors.New("value too short")
+	}
+	nonce, ciphertext := sealed[:p.aead.NonceSize()], sealed[p.aead.NonceSize():]
+	plaintext, err := p.aead.Open(nil, nonce, ciphertext, additionalData(name, key))
+	if err != nil {
+		return nil, err
+	}
+	if len(plaintext) == 0 {
+		return nil, errors.New("missing type")
+	}
+
+	raw := string(plaintext[1:])
+	switch plaintext[0] {
+	case 'i':
+		return strconv.ParseInt(raw, 10, 64)
+	case 'u':
+		return strconv.ParseUint(raw, 10, 64)
+	case 'f':
+		return strconv.ParseFloat(raw, 64)
+	case 'b':
+		return strconv.ParseBool(raw)
+	case 's':
+		return raw, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", plaintext[0])
+}
+
+func (p *Protect) sign(m telegraf.Metric) string {
+	return hex.EncodeToString(p.signature(m))
+}
+
+// signature computes the HMAC over the canonical representation of the
+// metric consisting of the name, the tags and fields sorted by key and the
+// timestamp in nanoseconds. The number of tags and fields as well as each
+// name, key and value are prefixed by their length to keep the encoding
+// unambiguous. Field values are prefixed by their type.
+func (p *Protect) signature(m telegraf.Metric) []byte {
+	p.mac.Reset()
+	p.writeBytes([]byte(m.Name()))
+
+	// The tag list is sorted by key
+	tags := make([]*telegraf.Tag, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		if tag.Key != p.SignatureTag {
+			tags = append(tags, tag)
+		}
+	}
+	p.writeLength(len(tags))
+	for _, tag := range tags {
+		p.writeBytes([]byte(tag.Key))
+		p.writeBytes([]byte(tag.Value))
+	}
+
+	fields := make([]*telegraf.Field, len(m.FieldList()))
+	copy(fields, m.FieldList())
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	p.writeLength(len(fields))
+	for _, field := range fields {
+		p.writeBytes([]byte(field.Key))
+		// All field types of metrics are supported, so ignore the error
+		value, _ := appendValue(nil, field.Value)
+		p.writeBytes(value)
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(m.Time().UnixNano()))
+	p.mac.Write(buf[:])
+
+	return p.mac.Sum(nil)
+}
+
+func (p *Protect) writeLength(n int) {
+	var buf [binary.MaxVarintLen64]byte
+	p.mac.Write(buf[:binary.PutUvarint(buf[:], uint64(n))])
+}
+
+func (p *Protect) writeBytes(b []byte) {
+	p.writeLength(len(b))
+	p.mac.Write(b)
+}
+
+// appendValue appends the canonical representation of the field value, i.e.
+// the value formatted as string and prefixed by a character denoting the type
+func appendValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case int64:
+		return strconv.AppendInt(append(buf, 'i'), v, 10), nil
+	case uint64:
+		return strconv.AppendUint(append(buf, 'u'), v, 10), nil
+	case float64:
+		return strconv.AppendFloat(append(buf, 'f'), v, 'g', -1, 64), nil
+	case bool:
+		return strconv.AppendBool(append(buf, 'b'), v), nil
+	case string:
+		return append(append(buf, 's'), v...), nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+func additionalData(name, key string) []byte {
+	return []byte(name + "\x00" + key)
+}
+
+// decodeKey returns the hex-encoded key of the secret
+func decodeKey(secret *config.Secret) ([]byte, error) {
+	buf, err := secret.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting secret failed: %w", err)
+	}
+	defer buf.Destroy()
+
+	return hex.DecodeString(strings.TrimSpace(buf.TemporaryString()))
+}
+
+func init() {
+	processors.Add("protect", func() telegraf.Processor {
+		return &Protect{}
+	})
+}
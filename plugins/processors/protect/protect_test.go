@@ -0,0 +1,275 @@
+package protect
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const (
+	encryptionKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	signingKey    = "202122232425262728292a2b2c2d2e2f"
+)
+
+func testMetric() telegraf.Metric {
+	return metric.New(
+		"reading",
+		map[string]string{"device": "sensor-1"},
+		map[string]interface{}{
+			"secret_int":    int64(-42),
+			"secret_uint":   uint64(42),
+			"secret_float":  float64(21.5),
+			"secret_bool":   true,
+			"secret_string": "classified",
+			"public":        int64(7),
+		},
+		time.Unix(1700000000, 123456789),
+	)
+}
+
+// transfer passes the metric through the influx serializer and parser as an
+// intermediate broker would
+func transfer(t *testing.T, m telegraf.Metric) telegraf.Metric {
+	t.Helper()
+
+	s := &serializer.Serializer{}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	p := &influx.Parser{}
+	require.NoError(t, p.Init())
+	out, err := p.ParseLine(string(buf))
+	require.NoError(t, err)
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	protect := &Protect{
+		Mode:          "protect",
+		EncryptFields: []string{"secret_*"},
+		EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+		SignatureTag:  "signature",
+		SigningKey:    config.NewSecret([]byte(signingKey)),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, protect.Init())
+
+	unprotect := &Protect{
+		Mode:          "unprotect",
+		EncryptFields: []string{"secret_*"},
+		EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+		SignatureTag:  "signature",
+		SigningKey:    config.NewSecret([]byte(signingKey)),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, unprotect.Init())
+
+	protected := protect.Apply(testMetric())
+	require.Len(t, protected, 1)
+
+	m := protected[0]
+	signature, found := m.GetTag("signature")
+	require.True(t, found)
+	require.Len(t, signature, 64)
+	for _, field := range m.FieldList() {
+		if strings.HasPrefix(field.Key, "secret_") {
+			require.IsType(t, "", field.Value)
+			require.True(t, strings.HasPrefix(field.Value.(string), encryptedPrefix))
+		}
+	}
+	public, found := m.GetField("public")
+	require.True(t, found)
+	require.Equal(t, int64(7), public)
+
+	actual := unprotect.Apply(transfer(t, m))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{testMetric()}, actual)
+}
+
+func TestSignatureOnly(t *testing.T) {
+	plugin := &Protect{
+		SignatureTag: "signature",
+		SigningKey:   config.NewSecret([]byte(signingKey)),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The signature must be deterministic
+	a := plugin.Apply(testMetric())
+	b := plugin.Apply(testMetric())
+	require.Equal(t, a[0].Tags()["signature"], b[0].Tags()["signature"])
+	require.Equal(t, testMetric().Fields(), a[0].Fields())
+}
+
+func TestSignatureNoCollision(t *testing.T) {
+	plugin := &Protect{
+		SignatureTag: "signature",
+		SigningKey:   config.NewSecret([]byte(signingKey)),
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tm := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		a, b telegraf.Metric
+	}{
+		{
+			name: "fields merged into string",
+			a:    metric.New("test", nil, map[string]interface{}{"a": "x", "b": int64(1)}, tm),
+			b:    metric.New("test", nil, map[string]interface{}{"a": "x\x00b\x00i1"}, tm),
+		},
+		{
+			name: "tags merged into value",
+			a:    metric.New("test", map[string]string{"a": "x", "b": "y"}, map[string]interface{}{"v": int64(1)}, tm),
+			b:    metric.New("test", map[string]string{"a": "x\x00b\x00y"}, map[string]interface{}{"v": int64(1)}, tm),
+		},
+		{
+			name: "name merged with tag",
+			a:    metric.New("test", map[string]string{"a": "x"}, map[string]interface{}{"v": int64(1)}, tm),
+			b:    metric.New("test\x00a\x00x", nil, map[string]interface{}{"v": int64(1)}, tm),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NotEqual(t, plugin.sign(tt.a), plugin.sign(tt.b))
+		})
+	}
+}
+
+func TestUnprotectRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m telegraf.Metric)
+	}{
+		{
+			name:   "missing signature",
+			modify: func(m telegraf.Metric) { m.RemoveTag("signature") },
+		},
+		{
+			name:   "modified tag",
+			modify: func(m telegraf.Metric) { m.AddTag("device", "sensor-2") },
+		},
+		{
+			name:   "modified field",
+			modify: func(m telegraf.Metric) { m.AddField("public", int64(8)) },
+		},
+		{
+			name:   "modified timestamp",
+			modify: func(m telegraf.Metric) { m.SetTime(m.Time().Truncate(time.Millisecond)) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protect := &Protect{
+				Mode:          "protect",
+				EncryptFields: []string{"secret_*"},
+				EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+				SignatureTag:  "signature",
+				SigningKey:    config.NewSecret([]byte(signingKey)),
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, protect.Init())
+
+			unprotect := &Protect{
+				Mode:          "unprotect",
+				EncryptFields: []string{"secret_*"},
+				EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+				SignatureTag:  "signature",
+				SigningKey:    config.NewSecret([]byte(signingKey)),
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, unprotect.Init())
+
+			m := protect.Apply(testMetric())[0]
+			tt.modify(m)
+			require.Empty(t, unprotect.Apply(m))
+		})
+	}
+}
+
+func TestMovedValueFailsDecryption(t *testing.T) {
+	protect := &Protect{
+		EncryptFields: []string{"secret_*"},
+		EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, protect.Init())
+
+	unprotect := &Protect{
+		Mode:          "unprotect",
+		EncryptFields: []string{"secret_*"},
+		EncryptionKey: config.NewSecret([]byte(encryptionKey)),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, unprotect.Init())
+
+	m := protect.Apply(testMetric())[0]
+	value, found := m.GetField("secret_int")
+	require.True(t, found)
+	m.AddField("secret_uint", value)
+	require.Empty(t, unprotect.Apply(m))
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Protect
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Protect{Mode: "encrypt", SignatureTag: "signature"},
+			expected: "invalid mode",
+		},
+		{
+			name:     "nothing to do",
+			plugin:   &Protect{},
+			expected: "either 'encrypt_fields' or 'signature_tag' must be set",
+		},
+		{
+			name:     "missing encryption key",
+			plugin:   &Protect{EncryptFields: []string{"value"}},
+			expected: "'encryption_key' is required",
+		},
+		{
+			name: "invalid encryption key length",
+			plugin: &Protect{
+				EncryptFields: []string{"value"},
+				EncryptionKey: config.NewSecret([]byte("0011")),
+			},
+			expected: "invalid 'encryption_key'",
+		},
+		{
+			name: "encryption key not hex",
+			plugin: &Protect{
+				EncryptFields: []string{"value"},
+				EncryptionKey: config.NewSecret([]byte("not a key")),
+			},
+			expected: "invalid 'encryption_key'",
+		},
+		{
+			name: "short signing key",
+			plugin: &Protect{
+				SignatureTag: "signature",
+				SigningKey:   config.NewSecret([]byte("0011")),
+			},
+			expected: "at least 128 bits",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/series"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rate struct {
+	Fields       []string        `toml:"fields"`
+	Compute      []string        `toml:"compute"`
+	RateSuffix   string          `toml:"rate_suffix"`
+	DeltaSuffix  string          `toml:"delta_suffix"`
+	RatePeriod   config.Duration `toml:"rate_period"`
+	CounterReset string          `toml:"counter_reset"`
+	KeepCounters bool            `toml:"keep_counters"`
+	Log          telegraf.Logger `toml:"-"`
+	series.Config
+
+	filter filter.Filter
+	rate   bool
+	delta  bool
+	cache  map[uint64]map[string]sample
+	series *series.Tracker
+}
+
+// sample is the last value of a counter field
+type sample struct {
+	value interface{}
+	time  time.Time
+}
+
+func (*Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Init() error {
+	if len(r.Fields) == 0 {
+		r.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(r.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	r.filter = f
+
+	if len(r.Compute) == 0 {
+		r.Compute = []string{"rate"}
+	}
+	for _, c := range r.Compute {
+		switch c {
+		case "rate":
+			r.rate = true
+		case "delta":
+			r.delta = true
+		default:
+			return fmt.Errorf("invalid 'compute' value %q", c)
+		}
+	}
+	if r.rate && r.RateSuffix == "" || r.delta && r.DeltaSuffix == "" {
+		return errors.New("suffixes must not be empty")
+	}
+	if r.rate && r.delta && r.RateSuffix == r.DeltaSuffix {
+		return errors.New("'rate_suffix' and 'delta_suffix' must differ")
+	}
+
+	if r.RatePeriod <= 0 {
+		return errors.New("'rate_period' must be positive")
+	}
+
+	switch r.CounterReset {
+	case "":
+		r.CounterReset = "skip"
+	case "skip", "restart":
+	default:
+		return fmt.Errorf("invalid 'counter_reset' value %q", r.CounterReset)
+	}
+
+	if r.MaxSeries < 0 {
+		return errors.New("'max_series' must not be negative")
+	}
+	if r.MaxSeries > 0 || r.SeriesTTL > 0 {
+		r.series = series.NewTracker(r.Config, map[string]string{"processor": "rate"}, r.Log)
+	}
+	r.cache = make(map[uint64]map[string]sample)
+
+	return nil
+}
+
+func (r *Rate) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	idx := 0
+	for _, m := range metrics {
+		id := m.HashID()
+		for _, evicted := range r.series.Touch(id) {
+			delete(r.cache, evicted)
+		}
+		last, found := r.cache[id]
+		if !found {
+			last = make(map[string]sample)
+			r.cache[id] = last
+		}
+
+		// Copy the field list as fields are removed while iterating
+		fields := append([]*telegraf.Field(nil), m.FieldList()...)
+		for _, field := range fields {
+			if !r.filter.Match(field.Key) || !isNumeric(field.Value) {
+				continue
+			}
+			current := sample{value: field.Value, time: m.Time()}
+			previous, found := last[field.Key]
+			if found && !current.time.After(previous.time) {
+				// Skip metrics arriving out of order or with the same timestamp
+				continue
+			}
+			last[field.Key] = current
+			if !r.KeepCounters {
+				m.RemoveField(field.Key)
+			}
+			if !found {
+				continue
+			}
+
+			delta, ok := r.difference(previous.value, current.value)
+			if !ok {
+				r.Log.Debugf("Counter reset of field %q in %q", field.Key, m.Name())
+				continue
+			}
+			if r.delta {
+				m.AddField(field.Key+r.DeltaSuffix, delta)
+			}
+			if r.rate {
+				elapsed := current.time.Sub(previous.time)
+				rate := toFloat(delta) / elapsed.Seconds() * time.Duration(r.RatePeriod).Seconds()
+				m.AddField(field.Key+r.RateSuffix, rate)
+			}
+		}
+
+		// Without the counters, the first metric of a series might be empty
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		metrics[idx] = m
+		idx++
+	}
+	metrics = metrics[:idx]
+
+	for _, id := range r.series.Expire() {
+		delete(r.cache, id)
+	}
+
+	return metrics
+}
+
+// difference returns the increase of the counter keeping the integer types.
+// In case the counter decreased, the counter is assumed to be reset and the
+// current value is returned for the 'restart' handling.
+func (r *Rate) difference(previous, current interface{}) (interface{}, bool) {
+	var delta interface{}
+	var reset bool
+	c64, cInt := current.(int64)
+	p64, pInt := previous.(int64)
+	cu64, cUint := current.(uint64)
+	pu64, pUint := previous.(uint64)
+	switch {
+	case cInt && pInt:
+		delta, reset = c64-p64, c64 < p64
+	case cUint && pUint:
+		delta, reset = cu64-pu64, cu64 < pu64
+	default:
+		// Fall back to floats if the type of the field changed
+		c, p := toFloat(current), toFloat(previous)
+		delta, reset = c-p, c < p
+		current = c
+	}
+
+	if !reset {
+		return delta, true
+	}
+	if r.CounterReset == "restart" {
+		return current, true
+	}
+	return nil, false
+}
+
+func isNumeric(v interface{}) bool {
+	switch v.(type) {
+	case int64, uint64, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(v interface{}) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return &Rate{
+			RateSuffix:   "_rate",
+			DeltaSuffix:  "_delta",
+			RatePeriod:   config.Duration(time.Second),
+			KeepCounters: true,
+			Config:       series.Config{SeriesTTL: config.Duration(time.Hour)},
+		}
+	})
+}
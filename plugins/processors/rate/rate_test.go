@@ -0,0 +1,274 @@
+package rate
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/series"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newRate() *Rate {
+	return &Rate{
+		RateSuffix:   "_rate",
+		DeltaSuffix:  "_delta",
+		RatePeriod:   config.Duration(time.Second),
+		KeepCounters: true,
+		Log:          testutil.Logger{},
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(r *Rate)
+		expected string
+	}{
+		{
+			name:     "invalid compute",
+			modify:   func(r *Rate) { r.Compute = []string{"rate", "average"} },
+			expected: `invalid 'compute' value "average"`,
+		},
+		{
+			name:     "empty suffix",
+			modify:   func(r *Rate) { r.RateSuffix = "" },
+			expected: "suffixes must not be empty",
+		},
+		{
+			name: "same suffixes",
+			modify: func(r *Rate) {
+				r.Compute = []string{"rate", "delta"}
+				r.DeltaSuffix = "_rate"
+			},
+			expected: "must differ",
+		},
+		{
+			name:     "invalid period",
+			modify:   func(r *Rate) { r.RatePeriod = 0 },
+			expected: "'rate_period' must be positive",
+		},
+		{
+			name:     "invalid counter reset",
+			modify:   func(r *Rate) { r.CounterReset = "wrap" },
+			expected: `invalid 'counter_reset' value "wrap"`,
+		},
+		{
+			name:     "negative max series",
+			modify:   func(r *Rate) { r.MaxSeries = -1 },
+			expected: "'max_series' must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newRate()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		modify   func(r *Rate)
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "rate",
+			input: []telegraf.Metric{
+				metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_recv": uint64(1000), "up": true}, now),
+				metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes_recv": uint64(50)}, now),
+				metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_recv": uint64(3000), "up": true}, now.Add(10*time.Second)),
+				metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes_recv": uint64(60)}, now.Add(5*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes_recv": uint64(1000), "up": true}, now),
+				metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes_recv": uint64(50)}, now),
+				metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{
+					"bytes_recv":      uint64(3000),
+					"bytes_recv_rate": float64(200),
+					"up":              true,
+				}, now.Add(10*time.Second)),
+				metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{
+					"bytes_recv":      uint64(60),
+					"bytes_recv_rate": float64(2),
+				}, now.Add(5*time.Second)),
+			},
+		},
+		{
+			name: "delta and per-minute rate of selected fields",
+			modify: func(r *Rate) {
+				r.Fields = []string{"requests"}
+				r.Compute = []string{"rate", "delta"}
+				r.RatePeriod = config.Duration(time.Minute)
+				r.KeepCounters = false
+			},
+			input: []telegraf.Metric{
+				metric.New("web", map[string]string{}, map[string]interface{}{"requests": int64(100), "uptime": int64(5)}, now),
+				metric.New("web", map[string]string{}, map[string]interface{}{"requests": int64(130), "uptime": int64(35)}, now.Add(30*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("web", map[string]string{}, map[string]interface{}{"uptime": int64(5)}, now),
+				metric.New("web", map[string]string{}, map[string]interface{}{
+					"requests_delta": int64(30),
+					"requests_rate":  float64(60),
+					"uptime":         int64(35),
+				}, now.Add(30*time.Second)),
+			},
+		},
+		{
+			name: "first metric without other fields dropped",
+			modify: func(r *Rate) {
+				r.Compute = []string{"delta"}
+				r.KeepCounters = false
+			},
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"time_user": 10.5}, now),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"time_user": 12.0}, now.Add(time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"time_user_delta": 1.5}, now.Add(time.Second)),
+			},
+		},
+		{
+			name: "counter reset skipped",
+			modify: func(r *Rate) {
+				r.Compute = []string{"delta"}
+			},
+			input: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(2)}, now.Add(time.Second)),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(5)}, now.Add(2*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(2)}, now.Add(time.Second)),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(5), "errors_delta": uint64(3)}, now.Add(2*time.Second)),
+			},
+		},
+		{
+			name: "counter reset restart",
+			modify: func(r *Rate) {
+				r.Compute = []string{"delta"}
+				r.CounterReset = "restart"
+			},
+			input: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(2)}, now.Add(time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"errors": uint64(2), "errors_delta": uint64(2)}, now.Add(time.Second)),
+			},
+		},
+		{
+			name: "out of order metrics ignored",
+			input: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(5)}, now.Add(-time.Second)),
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(20)}, now.Add(2*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(10)}, now),
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(5)}, now.Add(-time.Second)),
+				metric.New("app", map[string]string{}, map[string]interface{}{"requests": int64(20), "requests_rate": float64(5)}, now.Add(2*time.Second)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newRate()
+			if tt.modify != nil {
+				tt.modify(plugin)
+			}
+			require.NoError(t, plugin.Init())
+
+			// Process the metrics one by one to make sure the state is kept
+			// between calls
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, plugin.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestMaxSeries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := newRate()
+	plugin.Config = series.Config{MaxSeries: 1}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets": int64(1)}, now))
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"packets": int64(1)}, now))
+	require.Len(t, plugin.cache, 1)
+
+	// The state of eth0 was evicted so no rate is computed
+	actual := plugin.Apply(metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets": int64(5)}, now.Add(time.Second)))
+	require.Len(t, actual, 1)
+	require.False(t, actual[0].HasField("packets_rate"))
+}
+
+func TestSeriesTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	plugin := newRate()
+	plugin.Config = series.Config{SeriesTTL: config.Duration(10 * time.Millisecond)}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets": int64(1)}, now))
+	require.Len(t, plugin.cache, 1)
+
+	// Seeing another series expires the state of the previous one
+	time.Sleep(50 * time.Millisecond)
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"packets": int64(1)}, now))
+	require.Len(t, plugin.cache, 1)
+
+	actual := plugin.Apply(metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"packets": int64(5)}, now.Add(time.Second)))
+	require.Len(t, actual, 1)
+	require.False(t, actual[0].HasField("packets_rate"))
+}
+
+func TestTracking(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	plugin := newRate()
+	plugin.KeepCounters = false
+	require.NoError(t, plugin.Init())
+
+	first, _ := metric.WithTracking(metric.New("cpu", map[string]string{}, map[string]interface{}{"time": 1.0}, now), notify)
+	second, _ := metric.WithTracking(metric.New("cpu", map[string]string{}, map[string]interface{}{"time": 2.0}, now.Add(time.Second)), notify)
+	actual := plugin.Apply(first, second)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	// The dropped metric needs to be delivered as well
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 2
+	}, time.Second, 10*time.Millisecond)
+}
@@ -2,12 +2,14 @@
 package reverse_dns
 
 import (
+	"context"
 	_ "embed"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/parallel"
+	"github.com/influxdata/telegraf/plugins/common/resolver"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -22,6 +24,7 @@ type lookupEntry struct {
 
 type ReverseDNS struct {
 	reverseDNSCache *ReverseDNSCache
+	dns             *resolver.DNS
 	acc             telegraf.Accumulator
 	parallel        parallel.Parallel
 
@@ -31,6 +34,18 @@ type ReverseDNS struct {
 	MaxParallelLookups int             `toml:"max_parallel_lookups"`
 	Ordered            bool            `toml:"ordered"`
 	Log                telegraf.Logger `toml:"-"`
+	resolver.Config
+}
+
+// sharedResolver resolves the addresses via the DNS cache shared with other
+// plugins
+type sharedResolver struct {
+	dns *resolver.DNS
+	ttl time.Duration
+}
+
+func (s *sharedResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return s.dns.LookupAddr(ctx, addr, s.ttl)
 }
 
 func (*ReverseDNS) SampleConfig() string {
@@ -44,6 +59,8 @@ func (r *ReverseDNS) Start(acc telegraf.Accumulator) error {
 		time.Duration(r.LookupTimeout),
 		r.MaxParallelLookups, // max parallel reverse-dns lookups
 	)
+	r.dns = resolver.SharedDNS(r.Config, r.Log)
+	r.reverseDNSCache.Resolver = &sharedResolver{dns: r.dns, ttl: time.Duration(r.CacheTTL)}
 	if r.Ordered {
 		r.parallel = parallel.NewOrdered(acc, r.asyncAdd, 10000, r.MaxParallelLookups)
 	} else {
@@ -55,6 +72,7 @@ func (r *ReverseDNS) Start(acc telegraf.Accumulator) error {
 func (r *ReverseDNS) Stop() {
 	r.parallel.Stop()
 	r.reverseDNSCache.Stop()
+	r.dns.Release()
 }
 
 func (r *ReverseDNS) Add(metric telegraf.Metric, _ telegraf.Accumulator) error {
@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Violation types
+const (
+	missingTag         = "missing_tag"
+	missingField       = "missing_field"
+	typeMismatch       = "type_mismatch"
+	extraTag           = "extra_tag"
+	extraField         = "extra_field"
+	unknownMeasurement = "unknown_measurement"
+)
+
+type measurement struct {
+	Name           []string               `toml:"name"`
+	RequiredTags   []string               `toml:"required_tags"`
+	OptionalTags   []string               `toml:"optional_tags"`
+	RequiredFields []string               `toml:"required_fields"`
+	ExtraTags      string                 `toml:"extra_tags"`
+	ExtraFields    string                 `toml:"extra_fields"`
+	Fields         map[string]string      `toml:"fields"`
+	TagDefaults    map[string]string      `toml:"tag_defaults"`
+	FieldDefaults  map[string]interface{} `toml:"field_defaults"`
+
+	nameFilter filter.Filter
+	tags       map[string]bool
+}
+
+// result of checking a metric against the measurement declaration
+type result struct {
+	violations []string
+	coerced    int
+	defaulted  int
+}
+
+func (m *measurement) init() error {
+	if len(m.Name) == 0 {
+		return errors.New("no name given")
+	}
+	f, err := filter.Compile(m.Name)
+	if err != nil {
+		return fmt.Errorf("creating name filter failed: %w", err)
+	}
+	m.nameFilter = f
+
+	for _, setting := range []*string{&m.ExtraTags, &m.ExtraFields} {
+		switch *setting {
+		case "":
+			*setting = "allow"
+		case "allow", "remove", "reject":
+		default:
+			return fmt.Errorf("invalid extra handling %q", *setting)
+		}
+	}
+
+	m.tags = make(map[string]bool, len(m.RequiredTags)+len(m.OptionalTags))
+	for _, key := range m.RequiredTags {
+		m.tags[key] = true
+	}
+	for _, key := range m.OptionalTags {
+		m.tags[key] = true
+	}
+
+	if m.Fields == nil {
+		m.Fields = make(map[string]string, len(m.RequiredFields))
+	}
+	for key, t := range m.Fields {
+		switch t {
+		case "float", "integer", "unsigned", "string", "boolean", "any":
+		default:
+			return fmt.Errorf("invalid type %q for field %q", t, key)
+		}
+	}
+	for _, key := range m.RequiredFields {
+		if _, found := m.Fields[key]; !found {
+			m.Fields[key] = "any"
+		}
+	}
+
+	// Make sure the defaults are of the declared type
+	for key, v := range m.FieldDefaults {
+		t, found := m.Fields[key]
+		if !found {
+			return fmt.Errorf("default for undeclared field %q", key)
+		}
+		converted, err := convert(t, v)
+		if err != nil {
+			return fmt.Errorf("invalid default for field %q: %w", key, err)
+		}
+		m.FieldDefaults[key] = converted
+	}
+
+	return nil
+}
+
+func (m *measurement) check(metric telegraf.Metric, coerce bool) result {
+	var r result
+
+	for _, key := range m.RequiredTags {
+		if metric.HasTag(key) {
+			continue
+		}
+		if v, found := m.TagDefaults[key]; found {
+			metric.AddTag(key, v)
+			r.defaulted++
+			continue
+		}
+		r.violations = append(r.violations, missingTag)
+	}
+
+	if m.ExtraTags != "allow" {
+		tags := append([]*telegraf.Tag(nil), metric.TagList()...)
+		for _, tag := range tags {
+			if m.tags[tag.Key] {
+				continue
+			}
+			if m.ExtraTags == "remove" {
+				metric.RemoveTag(tag.Key)
+				continue
+			}
+			r.violations = append(r.violations, extraTag)
+		}
+	}
+
+	for _, key := range m.RequiredFields {
+		if metric.HasField(key) {
+			continue
+		}
+		if v, found := m.FieldDefaults[key]; found {
+			metric.AddField(key, v)
+			r.defaulted++
+			continue
+		}
+		r.violations = append(r.violations, missingField)
+	}
+
+	// Copy the field list as fields might be removed or replaced
+	fields := append([]*telegraf.Field(nil), metric.FieldList()...)
+	for _, field := range fields {
+		t, found := m.Fields[field.Key]
+		if !found {
+			switch m.ExtraFields {
+			case "remove":
+				metric.RemoveField(field.Key)
+			case "reject":
+				r.violations = append(r.violations, extraField)
+			}
+			continue
+		}
+		if t == "any" || hasType(t, field.Value) {
+			continue
+		}
+		if coerce {
+			if v, err := convert(t, field.Value); err == nil {
+				metric.AddField(field.Key, v)
+				r.coerced++
+				continue
+			}
+		}
+		r.violations = append(r.violations, typeMismatch)
+	}
+
+	return r
+}
+
+func hasType(t string, value interface{}) bool {
+	switch value.(type) {
+	case float64:
+		return t == "float"
+	case int64:
+		return t == "integer"
+	case uint64:
+		return t == "unsigned"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	}
+	return false
+}
+
+func convert(t string, value interface{}) (interface{}, error) {
+	switch t {
+	case "float":
+		return internal.ToFloat64(value)
+	case "integer":
+		return internal.ToInt64(value)
+	case "unsigned":
+		return internal.ToUint64(value)
+	case "string":
+		return internal.ToString(value)
+	case "boolean":
+		return internal.ToBool(value)
+	}
+	return value, nil
+}
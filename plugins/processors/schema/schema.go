@@ -0,0 +1,132 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package schema
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Schema struct {
+	Action                string          `toml:"action"`
+	UnknownMeasurement    string          `toml:"unknown_measurement"`
+	QuarantineMeasurement string          `toml:"quarantine_measurement"`
+	Coerce                bool            `toml:"coerce"`
+	Measurements          []measurement   `toml:"measurement"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	violations  map[string]selfstat.Stat
+	coerced     selfstat.Stat
+	defaulted   selfstat.Stat
+	dropped     selfstat.Stat
+	quarantined selfstat.Stat
+}
+
+func (*Schema) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Schema) Init() error {
+	if s.Action == "" {
+		s.Action = "drop"
+	}
+	if s.UnknownMeasurement == "" {
+		s.UnknownMeasurement = "pass"
+	}
+	for _, action := range []string{s.Action, s.UnknownMeasurement} {
+		switch action {
+		case "drop", "quarantine", "pass":
+		default:
+			return fmt.Errorf("invalid action %q", action)
+		}
+	}
+	if s.QuarantineMeasurement == "" {
+		s.QuarantineMeasurement = "schema_quarantine"
+	}
+
+	if len(s.Measurements) == 0 {
+		return errors.New("no measurement declared")
+	}
+	for i := range s.Measurements {
+		if err := s.Measurements[i].init(); err != nil {
+			return fmt.Errorf("initialization of measurement %d failed: %w", i+1, err)
+		}
+	}
+
+	tags := make(map[string]string)
+	s.violations = make(map[string]selfstat.Stat)
+	for _, v := range []string{missingTag, missingField, typeMismatch, extraTag, extraField, unknownMeasurement} {
+		s.violations[v] = selfstat.Register("schema", v, tags)
+	}
+	s.coerced = selfstat.Register("schema", "coerced", tags)
+	s.defaulted = selfstat.Register("schema", "defaulted", tags)
+	s.dropped = selfstat.Register("schema", "dropped", tags)
+	s.quarantined = selfstat.Register("schema", "quarantined", tags)
+
+	return nil
+}
+
+func (s *Schema) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		action, violations := s.check(m)
+		if len(violations) == 0 {
+			out = append(out, m)
+			continue
+		}
+
+		for _, v := range violations {
+			s.violations[v].Incr(1)
+		}
+		switch action {
+		case "drop":
+			s.Log.Debugf("Dropping metric %q due to %s", m.Name(), strings.Join(violations, ", "))
+			s.dropped.Incr(1)
+			m.Drop()
+		case "quarantine":
+			s.quarantined.Incr(1)
+			slices.Sort(violations)
+			m.AddTag("measurement", m.Name())
+			m.AddTag("violations", strings.Join(slices.Compact(violations), ","))
+			m.SetName(s.QuarantineMeasurement)
+			out = append(out, m)
+		default:
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// check validates the metric against the first matching declaration and
+// returns the action to apply together with the remaining violations
+func (s *Schema) check(m telegraf.Metric) (string, []string) {
+	for i := range s.Measurements {
+		decl := &s.Measurements[i]
+		if !decl.nameFilter.Match(m.Name()) {
+			continue
+		}
+		r := decl.check(m, s.Coerce)
+		s.coerced.Incr(int64(r.coerced))
+		s.defaulted.Incr(int64(r.defaulted))
+		return s.Action, r.violations
+	}
+	if s.UnknownMeasurement == "pass" {
+		return "pass", nil
+	}
+	return s.UnknownMeasurement, []string{unknownMeasurement}
+}
+
+func init() {
+	processors.Add("schema", func() telegraf.Processor {
+		return &Schema{}
+	})
+}
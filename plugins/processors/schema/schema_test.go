@@ -0,0 +1,313 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Schema
+		expected string
+	}{
+		{
+			name:     "no measurement",
+			plugin:   &Schema{},
+			expected: "no measurement declared",
+		},
+		{
+			name:     "invalid action",
+			plugin:   &Schema{Action: "fix", Measurements: []measurement{{Name: []string{"cpu"}}}},
+			expected: `invalid action "fix"`,
+		},
+		{
+			name:     "no name",
+			plugin:   &Schema{Measurements: []measurement{{}}},
+			expected: "no name given",
+		},
+		{
+			name: "invalid type",
+			plugin: &Schema{Measurements: []measurement{{
+				Name:   []string{"cpu"},
+				Fields: map[string]string{"usage": "double"},
+			}}},
+			expected: `invalid type "double" for field "usage"`,
+		},
+		{
+			name: "invalid default",
+			plugin: &Schema{Measurements: []measurement{{
+				Name:          []string{"cpu"},
+				Fields:        map[string]string{"usage": "float"},
+				FieldDefaults: map[string]interface{}{"usage": "none"},
+			}}},
+			expected: `invalid default for field "usage"`,
+		},
+		{
+			name: "invalid extra handling",
+			plugin: &Schema{Measurements: []measurement{{
+				Name:      []string{"cpu"},
+				ExtraTags: "ignore",
+			}}},
+			expected: `invalid extra handling "ignore"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	declaration := measurement{
+		Name:           []string{"cpu*"},
+		RequiredTags:   []string{"host", "region"},
+		OptionalTags:   []string{"cpu"},
+		RequiredFields: []string{"usage"},
+		ExtraTags:      "remove",
+		ExtraFields:    "reject",
+		TagDefaults:    map[string]string{"region": "unknown"},
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		coerce   bool
+		input    telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "conforming",
+			action: "drop",
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "region": "eu", "cpu": "cpu0"},
+				map[string]interface{}{"usage": 42.0, "count": int64(3)},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "region": "eu", "cpu": "cpu0"},
+					map[string]interface{}{"usage": 42.0, "count": int64(3)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "defaults and removed tags",
+			action: "drop",
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "source": "kinesis"},
+				map[string]interface{}{"usage": 42.0},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "region": "unknown"},
+					map[string]interface{}{"usage": 42.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "coerced",
+			action: "drop",
+			coerce: true,
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "region": "eu"},
+				map[string]interface{}{"usage": "42.5", "count": 3.0, "state": true},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "region": "eu"},
+					map[string]interface{}{"usage": 42.5, "count": int64(3), "state": "true"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "not coercible",
+			action: "drop",
+			coerce: true,
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "region": "eu"},
+				map[string]interface{}{"usage": "high"},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:   "dropped type mismatch",
+			action: "drop",
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "region": "eu"},
+				map[string]interface{}{"usage": int64(42)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:   "quarantined",
+			action: "quarantine",
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"usage": int64(42), "load": 1.0},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"schema_quarantine",
+					map[string]string{
+						"region":      "unknown",
+						"measurement": "cpu",
+						"violations":  "extra_field,missing_tag,type_mismatch",
+					},
+					map[string]interface{}{"usage": int64(42), "load": 1.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "passed with violations",
+			action: "pass",
+			input: metric.New(
+				"cpu",
+				map[string]string{"host": "a", "region": "eu"},
+				map[string]interface{}{"count": int64(1)},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "region": "eu"},
+					map[string]interface{}{"count": int64(1)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:   "unknown measurement",
+			action: "drop",
+			input: metric.New(
+				"mem",
+				map[string]string{},
+				map[string]interface{}{"free": int64(42)},
+				time.Unix(0, 0),
+			),
+			expected: []telegraf.Metric{
+				metric.New(
+					"mem",
+					map[string]string{},
+					map[string]interface{}{"free": int64(42)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init modifies the maps so use a fresh copy for each test
+			decl := declaration
+			decl.Fields = map[string]string{"usage": "float", "count": "integer", "state": "string"}
+			decl.FieldDefaults = map[string]interface{}{"count": 0}
+			plugin := &Schema{
+				Action:       tt.action,
+				Coerce:       tt.coerce,
+				Measurements: []measurement{decl},
+				Log:          testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestUnknownMeasurement(t *testing.T) {
+	plugin := &Schema{
+		UnknownMeasurement: "quarantine",
+		Measurements:       []measurement{{Name: []string{"cpu"}}},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The statistics are shared between all instances of the plugin
+	violations := plugin.violations[unknownMeasurement].Get()
+	quarantined := plugin.quarantined.Get()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(1)}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		metric.New(
+			"schema_quarantine",
+			map[string]string{"measurement": "mem", "violations": "unknown_measurement"},
+			map[string]interface{}{"free": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+	require.Equal(t, violations+1, plugin.violations[unknownMeasurement].Get())
+	require.Equal(t, quarantined+1, plugin.quarantined.Get())
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 2)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	plugin := &Schema{
+		Measurements: []measurement{{
+			Name:         []string{"cpu"},
+			RequiredTags: []string{"host"},
+		}},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+	}
+	for i, m := range input {
+		tm, _ := metric.WithTracking(m, notify)
+		input[i] = tm
+	}
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,248 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+type Serializer struct {
+	SchemaRegistry        string          `toml:"avro_schema_registry"`
+	SchemaRegistryCert    string          `toml:"avro_schema_registry_cert"`
+	SchemaRegistryTimeout config.Duration `toml:"avro_schema_registry_timeout"`
+	Subject               string          `toml:"avro_schema_subject"`
+	Namespace             string          `toml:"avro_schema_namespace"`
+	TimestampUnits        string          `toml:"avro_timestamp_units"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	registry *schemaregistry.Client
+	schemas  map[string]*schema
+}
+
+// schema derived from the shape of a metric
+type schema struct {
+	id     int
+	codec  *goavro.Codec
+	tags   map[string]string
+	fields map[string]string
+}
+
+func (s *Serializer) Init() error {
+	if s.SchemaRegistry == "" {
+		return errors.New("'avro_schema_registry' is required")
+	}
+
+	switch s.TimestampUnits {
+	case "":
+		s.TimestampUnits = "us"
+	case "ms", "us", "ns":
+	default:
+		return fmt.Errorf("invalid 'avro_timestamp_units' %q", s.TimestampUnits)
+	}
+
+	if s.Namespace == "" {
+		s.Namespace = "telegraf"
+	}
+	if s.SchemaRegistryTimeout <= 0 {
+		s.SchemaRegistryTimeout = config.Duration(5 * time.Second)
+	}
+
+	registry, err := schemaregistry.NewClient(s.SchemaRegistry, s.SchemaRegistryCert, time.Duration(s.SchemaRegistryTimeout))
+	if err != nil {
+		return fmt.Errorf("creating schema registry client failed: %w", err)
+	}
+	s.registry = registry
+	s.schemas = make(map[string]*schema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	sc, err := s.schema(m)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]interface{}, len(sc.tags))
+	for _, tag := range m.TagList() {
+		tags[sc.tags[tag.Key]] = goavro.Union("string", tag.Value)
+	}
+	fields := make(map[string]interface{}, len(sc.fields))
+	for _, field := range m.FieldList() {
+		value := field.Value
+		if v, ok := value.(uint64); ok {
+			if v > math.MaxInt64 {
+				return nil, fmt.Errorf("value of field %q exceeds the Avro long range", field.Key)
+			}
+			value = int64(v)
+		}
+		fields[sc.fields[field.Key]] = goavro.Union(avroType(field.Value), value)
+	}
+	record := map[string]interface{}{
+		"timestamp": s.timestamp(m.Time()),
+		"tags":      tags,
+		"fields":    fields,
+	}
+
+	buf := schemaregistry.AppendHeader(make([]byte, 0, 64), sc.id)
+	buf, err = sc.codec.BinaryFromNative(buf, record)
+	if err != nil {
+		return nil, fmt.Errorf("encoding metric %q failed: %w", m.Name(), err)
+	}
+	return buf, nil
+}
+
+func (*Serializer) SerializeBatch([]telegraf.Metric) ([]byte, error) {
+	return nil, errors.New("batch serialization is not supported, messages must contain a single metric")
+}
+
+// schema returns the schema for the metric shape, deriving and registering
+// the schema if necessary
+func (s *Serializer) schema(m telegraf.Metric) (*schema, error) {
+	// Fields are ordered by insertion so sort them to get a stable schema
+	fieldList := slices.SortedFunc(slices.Values(m.FieldList()), func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	var key strings.Builder
+	key.WriteString(m.Name())
+	for _, tag := range m.TagList() {
+		key.WriteString("\x00t" + tag.Key)
+	}
+	for _, field := range fieldList {
+		key.WriteString("\x00f" + avroType(field.Value) + ":" + field.Key)
+	}
+	if sc, found := s.schemas[key.String()]; found {
+		return sc, nil
+	}
+
+	name := schemaregistry.Sanitize(m.Name())
+	sc := &schema{
+		tags:   make(map[string]string, len(m.TagList())),
+		fields: make(map[string]string, len(m.FieldList())),
+	}
+
+	seen := make(map[string]bool, len(m.TagList()))
+	tagFields := make([]map[string]interface{}, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		sanitized := schemaregistry.Sanitize(tag.Key)
+		if seen[sanitized] {
+			return nil, fmt.Errorf("tag %q of metric %q collides with another tag after sanitizing", tag.Key, m.Name())
+		}
+		seen[sanitized] = true
+		sc.tags[tag.Key] = sanitized
+		tagFields = append(tagFields, map[string]interface{}{
+			"name":    sanitized,
+			"type":    []string{"null", "string"},
+			"default": nil,
+		})
+	}
+
+	clear(seen)
+	fieldFields := make([]map[string]interface{}, 0, len(fieldList))
+	for _, field := range fieldList {
+		t := avroType(field.Value)
+		if t == "" {
+			return nil, fmt.Errorf("unsupported type %T of field %q", field.Value, field.Key)
+		}
+		sanitized := schemaregistry.Sanitize(field.Key)
+		if seen[sanitized] {
+			return nil, fmt.Errorf("field %q of metric %q collides with another field after sanitizing", field.Key, m.Name())
+		}
+		seen[sanitized] = true
+		sc.fields[field.Key] = sanitized
+		fieldFields = append(fieldFields, map[string]interface{}{
+			"name":    sanitized,
+			"type":    []string{"null", t},
+			"default": nil,
+		})
+	}
+
+	logicalType := map[string]string{"ms": "timestamp-millis", "us": "timestamp-micros", "ns": "timestamp-nanos"}
+	definition := map[string]interface{}{
+		"type":      "record",
+		"name":      name,
+		"namespace": s.Namespace,
+		"fields": []map[string]interface{}{
+			{
+				"name": "timestamp",
+				"type": map[string]string{"type": "long", "logicalType": logicalType[s.TimestampUnits]},
+			},
+			{
+				"name": "tags",
+				"type": map[string]interface{}{"type": "record", "name": name + "_tags", "fields": tagFields},
+			},
+			{
+				"name": "fields",
+				"type": map[string]interface{}{"type": "record", "name": name + "_fields", "fields": fieldFields},
+			},
+		},
+	}
+	text, err := json.Marshal(definition)
+	if err != nil {
+		return nil, fmt.Errorf("creating schema for metric %q failed: %w", m.Name(), err)
+	}
+
+	codec, err := goavro.NewCodec(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for metric %q failed: %w", m.Name(), err)
+	}
+	sc.codec = codec
+
+	// Use the record-name strategy if no subject is given
+	subject := s.Subject
+	if subject == "" {
+		subject = s.Namespace + "." + name
+	}
+	id, err := s.registry.Register(subject, schemaregistry.TypeAvro, codec.Schema())
+	if err != nil {
+		return nil, err
+	}
+	sc.id = id
+	s.schemas[key.String()] = sc
+
+	return sc, nil
+}
+
+func (s *Serializer) timestamp(t time.Time) int64 {
+	switch s.TimestampUnits {
+	case "ms":
+		return t.UnixMilli()
+	case "ns":
+		return t.UnixNano()
+	}
+	return t.UnixMicro()
+}
+
+func avroType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "double"
+	case int64, uint64:
+		return "long"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return ""
+}
+
+func init() {
+	serializers.Add("avro",
+		func() serializers.Serializer {
+			return &Serializer{}
+		},
+	)
+}
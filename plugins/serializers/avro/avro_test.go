@@ -0,0 +1,148 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+type registry struct {
+	subjects []string
+	schemas  []string
+	sync.Mutex
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.SchemaType != "" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.subjects = append(r.subjects, req.URL.Path)
+	r.schemas = append(r.schemas, body.Schema)
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(len(r.schemas)) + `}`))
+}
+
+func TestInitInvalid(t *testing.T) {
+	s := &Serializer{}
+	require.ErrorContains(t, s.Init(), "'avro_schema_registry' is required")
+
+	s = &Serializer{SchemaRegistry: "http://localhost:8081", TimestampUnits: "s"}
+	require.ErrorContains(t, s.Init(), "invalid 'avro_timestamp_units'")
+}
+
+func TestSerialize(t *testing.T) {
+	reg := &registry{}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{SchemaRegistry: server.URL}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu.usage",
+		map[string]string{"host": "server01", "cpu-id": "0"},
+		map[string]interface{}{
+			"idle":    98.5,
+			"count":   int64(-3),
+			"total":   uint64(42),
+			"state":   "ok",
+			"healthy": true,
+		},
+		time.Unix(1700000000, 123456789),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	// Serializing a metric of the same shape must not register the schema again
+	_, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/subjects/telegraf.cpu_usage/versions"}, reg.subjects)
+
+	// Check the wire-format header
+	require.Equal(t, byte(0), buf[0])
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+	codec, err := goavro.NewCodec(reg.schemas[0])
+	require.NoError(t, err)
+	native, remaining, err := codec.NativeFromBinary(buf[5:])
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	record := native.(map[string]interface{})
+	require.Equal(t, time.UnixMicro(1700000000123456).UTC(), record["timestamp"])
+	require.Equal(t, map[string]interface{}{
+		"host":   map[string]interface{}{"string": "server01"},
+		"cpu_id": map[string]interface{}{"string": "0"},
+	}, record["tags"])
+	require.Equal(t, map[string]interface{}{
+		"idle":    map[string]interface{}{"double": 98.5},
+		"count":   map[string]interface{}{"long": int64(-3)},
+		"total":   map[string]interface{}{"long": int64(42)},
+		"state":   map[string]interface{}{"string": "ok"},
+		"healthy": map[string]interface{}{"boolean": true},
+	}, record["fields"])
+}
+
+func TestSerializeSchemaEvolution(t *testing.T) {
+	reg := &registry{}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		Subject:        "metrics-value",
+		TimestampUnits: "ns",
+	}
+	require.NoError(t, s.Init())
+
+	m1 := metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 1.0}, time.Unix(0, 42))
+	buf, err := s.Serialize(m1)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+	m2 := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 1.0}, time.Unix(0, 42))
+	buf, err = s.Serialize(m2)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), binary.BigEndian.Uint32(buf[1:5]))
+	require.Equal(t, []string{"/subjects/metrics-value/versions", "/subjects/metrics-value/versions"}, reg.subjects)
+
+	// Nanosecond timestamps are encoded as plain long values
+	codec, err := goavro.NewCodec(reg.schemas[1])
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(buf[5:])
+	require.NoError(t, err)
+	require.Equal(t, int64(42), native.(map[string]interface{})["timestamp"])
+}
+
+func TestSerializeRegistryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error_code":409,"message":"incompatible schema"}`))
+	}))
+	defer server.Close()
+
+	s := &Serializer{SchemaRegistry: server.URL}
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 1.0}, time.Unix(0, 0))
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, "incompatible schema")
+}
@@ -46,9 +46,13 @@ type GraphiteSerializer struct {
 	TagSanitizeMode string   `toml:"graphite_tag_sanitize_mode"`
 	Separator       string   `toml:"graphite_separator"`
 	Templates       []string `toml:"templates"`
+	FloatFormat     string   `toml:"graphite_float_format"`
+	FloatPrecision  int      `toml:"graphite_float_precision"`
+	IntegerOverflow string   `toml:"graphite_integer_overflow"`
 
 	tmplts             []*GraphiteTemplate
 	strictAllowedChars *regexp.Regexp
+	numberFormat       serializers.NumberFormat
 }
 
 func (s *GraphiteSerializer) Init() error {
@@ -70,6 +74,15 @@ func (s *GraphiteSerializer) Init() error {
 		s.Separator = "."
 	}
 
+	s.numberFormat = serializers.NumberFormat{
+		FloatFormat:     s.FloatFormat,
+		FloatPrecision:  s.FloatPrecision,
+		IntegerOverflow: s.IntegerOverflow,
+	}
+	if err := s.numberFormat.Init(); err != nil {
+		return err
+	}
+
 	if s.StrictRegex == "" {
 		s.strictAllowedChars = regexp.MustCompile(`[^a-zA-Z0-9-:._=\p{L}]`)
 	} else {
@@ -92,7 +105,7 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	switch s.TagSupport {
 	case true:
 		for fieldName, value := range metric.Fields() {
-			fieldValue := formatValue(value)
+			fieldValue := s.formatValue(value)
 			if fieldValue == "" {
 				continue
 			}
@@ -121,7 +134,7 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 		}
 
 		for fieldName, value := range metric.Fields() {
-			fieldValue := formatValue(value)
+			fieldValue := s.formatValue(value)
 			if fieldValue == "" {
 				continue
 			}
@@ -149,7 +162,7 @@ func (s *GraphiteSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte,
 	return batch.Bytes(), nil
 }
 
-func formatValue(value interface{}) string {
+func (s *GraphiteSerializer) formatValue(value interface{}) string {
 	switch v := value.(type) {
 	case string:
 		return ""
@@ -159,6 +172,12 @@ func formatValue(value interface{}) string {
 		}
 		return "0"
 	case uint64:
+		switch cv := s.numberFormat.ConvertUnsigned(v).(type) {
+		case int64:
+			return strconv.FormatInt(cv, 10)
+		case string:
+			return cv
+		}
 		return strconv.FormatUint(v, 10)
 	case int64:
 		return strconv.FormatInt(v, 10)
@@ -170,7 +189,7 @@ func formatValue(value interface{}) string {
 		if math.IsInf(v, 0) {
 			return ""
 		}
-		return strconv.FormatFloat(v, 'f', -1, 64)
+		return s.numberFormat.FormatFloat(v)
 	}
 
 	return ""
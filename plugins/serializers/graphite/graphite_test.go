@@ -1244,3 +1244,98 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestSerializeNumberFormat(t *testing.T) {
+	now := time.Unix(1289430000, 0)
+	tests := []struct {
+		name     string
+		format   string
+		digits   int
+		overflow string
+		value    interface{}
+		expected string
+	}{
+		{
+			name:     "default float",
+			value:    float64(1.0 / 3.0),
+			expected: "0.3333333333333333",
+		},
+		{
+			name:     "default large float",
+			value:    float64(1.5e21),
+			expected: "1500000000000000000000",
+		},
+		{
+			name:     "fixed",
+			format:   "fixed",
+			digits:   2,
+			value:    float64(1.0 / 3.0),
+			expected: "0.33",
+		},
+		{
+			name:     "fixed no decimals",
+			format:   "fixed",
+			value:    float64(2.5001),
+			expected: "3",
+		},
+		{
+			name:     "significant",
+			format:   "significant",
+			digits:   3,
+			value:    float64(123456.789),
+			expected: "123000",
+		},
+		{
+			name:     "significant small",
+			format:   "significant",
+			digits:   2,
+			value:    float64(0.000012345),
+			expected: "0.000012",
+		},
+		{
+			name:     "unsigned overflow kept",
+			value:    uint64(18446744073709551615),
+			expected: "18446744073709551615",
+		},
+		{
+			name:     "unsigned overflow clamped",
+			overflow: "clamp",
+			value:    uint64(18446744073709551615),
+			expected: "9223372036854775807",
+		},
+		{
+			name:     "unsigned in range",
+			overflow: "clamp",
+			value:    uint64(42),
+			expected: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": tt.value}, now)
+
+			s := GraphiteSerializer{
+				Template:        "measurement.field",
+				FloatFormat:     tt.format,
+				FloatPrecision:  tt.digits,
+				IntegerOverflow: tt.overflow,
+			}
+			require.NoError(t, s.Init())
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, "cpu "+tt.expected+" 1289430000\n", string(buf))
+		})
+	}
+}
+
+func TestSerializeNumberFormatInvalid(t *testing.T) {
+	s := GraphiteSerializer{FloatFormat: "scientific"}
+	require.ErrorContains(t, s.Init(), "invalid float format")
+
+	s = GraphiteSerializer{FloatFormat: "significant"}
+	require.ErrorContains(t, s.Init(), "invalid float precision")
+
+	s = GraphiteSerializer{IntegerOverflow: "wrap"}
+	require.ErrorContains(t, s.Init(), "invalid integer overflow handling")
+}
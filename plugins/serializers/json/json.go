@@ -21,8 +21,12 @@ type Serializer struct {
 	Transformation      string          `toml:"json_transformation"`
 	NestedFieldsInclude []string        `toml:"json_nested_fields_include"`
 	NestedFieldsExclude []string        `toml:"json_nested_fields_exclude"`
+	FloatFormat         string          `toml:"json_float_format"`
+	FloatPrecision      int             `toml:"json_float_precision"`
+	IntegerOverflow     string          `toml:"json_integer_overflow"`
 
 	nestedfields filter.Filter
+	numberFormat serializers.NumberFormat
 }
 
 func (s *Serializer) Init() error {
@@ -51,7 +55,12 @@ func (s *Serializer) Init() error {
 		s.nestedfields = f
 	}
 
-	return nil
+	s.numberFormat = serializers.NumberFormat{
+		FloatFormat:     s.FloatFormat,
+		FloatPrecision:  s.FloatPrecision,
+		IntegerOverflow: s.IntegerOverflow,
+	}
+	return s.numberFormat.Init()
 }
 
 func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
@@ -126,6 +135,12 @@ func (s *Serializer) createObject(metric telegraf.Metric) map[string]interface{}
 			if math.IsNaN(fv) || math.IsInf(fv, 0) {
 				continue
 			}
+			// Use a raw JSON number to avoid scientific notation
+			if s.numberFormat.HasFloatFormat() {
+				val = json.Number(s.numberFormat.FormatFloat(fv))
+			}
+		case uint64:
+			val = s.numberFormat.ConvertUnsigned(fv)
 		case string:
 			// Check for nested fields if any
 			if s.nestedfields != nil && s.nestedfields.Match(field.Key) {
@@ -512,3 +512,62 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestSerializeNumberFormat(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"large":    float64(1.5e21),
+			"fraction": float64(1.0 / 3.0),
+			"counter":  uint64(18446744073709551615),
+		},
+		time.Unix(1525478795, 0),
+	)
+
+	tests := []struct {
+		name     string
+		format   string
+		digits   int
+		overflow string
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `{"fields":{"counter":18446744073709551615,"fraction":0.3333333333333333,"large":1.5e+21},"name":"cpu","tags":{},"timestamp":1525478795}`,
+		},
+		{
+			name:     "decimal",
+			format:   "decimal",
+			expected: `{"fields":{"counter":18446744073709551615,"fraction":0.3333333333333333,"large":1500000000000000000000},"name":"cpu","tags":{},"timestamp":1525478795}`,
+		},
+		{
+			name:     "fixed with clamping",
+			format:   "fixed",
+			digits:   3,
+			overflow: "clamp",
+			expected: `{"fields":{"counter":9223372036854775807,"fraction":0.333,"large":1500000000000000000000.000},"name":"cpu","tags":{},"timestamp":1525478795}`,
+		},
+		{
+			name:     "significant with string",
+			format:   "significant",
+			digits:   2,
+			overflow: "string",
+			expected: `{"fields":{"counter":"18446744073709551615","fraction":0.33,"large":1500000000000000000000},"name":"cpu","tags":{},"timestamp":1525478795}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Serializer{
+				FloatFormat:     tt.format,
+				FloatPrecision:  tt.digits,
+				IntegerOverflow: tt.overflow,
+			}
+			require.NoError(t, s.Init())
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected+"\n", string(buf))
+		})
+	}
+}
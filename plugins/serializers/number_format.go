@@ -0,0 +1,97 @@
+package serializers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// NumberFormat controls the representation of numeric field values for
+// serializers supporting it. The zero value keeps the serializer's default
+// representation.
+type NumberFormat struct {
+	// FloatFormat is one of
+	//   "" or "auto"  - serializer default representation
+	//   "decimal"     - shortest representation without scientific notation
+	//   "fixed"       - FloatPrecision digits after the decimal point
+	//   "significant" - rounded to FloatPrecision significant digits,
+	//                   without scientific notation
+	FloatFormat string
+
+	// FloatPrecision is the number of decimals or significant digits
+	FloatPrecision int
+
+	// IntegerOverflow defines the handling of unsigned integers exceeding the
+	// range of signed 64-bit integers, one of
+	//   ""       - keep the value
+	//   "clamp"  - clamp the value to the maximum signed 64-bit integer
+	//   "string" - output the value as string
+	IntegerOverflow string
+}
+
+// Init checks the settings for validity
+func (f *NumberFormat) Init() error {
+	switch f.FloatFormat {
+	case "", "auto", "decimal":
+	case "fixed":
+		if f.FloatPrecision < 0 {
+			return fmt.Errorf("invalid float precision %d for %q format", f.FloatPrecision, f.FloatFormat)
+		}
+	case "significant":
+		if f.FloatPrecision < 1 {
+			return fmt.Errorf("invalid float precision %d for %q format", f.FloatPrecision, f.FloatFormat)
+		}
+	default:
+		return fmt.Errorf("invalid float format %q", f.FloatFormat)
+	}
+
+	switch f.IntegerOverflow {
+	case "", "clamp", "string":
+	default:
+		return fmt.Errorf("invalid integer overflow handling %q", f.IntegerOverflow)
+	}
+
+	return nil
+}
+
+// HasFloatFormat returns true if a float format different from the default
+// is configured
+func (f *NumberFormat) HasFloatFormat() bool {
+	return f.FloatFormat != "" && f.FloatFormat != "auto"
+}
+
+// FormatFloat returns the string representation of the given float according
+// to the configured format. For the default format, the shortest decimal
+// representation is returned.
+func (f *NumberFormat) FormatFloat(v float64) string {
+	switch f.FloatFormat {
+	case "fixed":
+		return strconv.FormatFloat(v, 'f', f.FloatPrecision, 64)
+	case "significant":
+		if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', f.FloatPrecision, 64), 64)
+		if err != nil {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return strconv.FormatFloat(rounded, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ConvertUnsigned applies the configured overflow handling to the given value
+// and returns either an uint64, an int64 or a string.
+func (f *NumberFormat) ConvertUnsigned(v uint64) interface{} {
+	if v <= math.MaxInt64 {
+		return v
+	}
+
+	switch f.IntegerOverflow {
+	case "clamp":
+		return int64(math.MaxInt64)
+	case "string":
+		return strconv.FormatUint(v, 10)
+	}
+	return v
+}
@@ -0,0 +1,296 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/apache/arrow/go/v18/parquet"
+	"github.com/apache/arrow/go/v18/parquet/compress"
+	"github.com/apache/arrow/go/v18/parquet/pqarrow"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+var compressionCodecs = map[string]compress.Compression{
+	"none":   compress.Codecs.Uncompressed,
+	"snappy": compress.Codecs.Snappy,
+	"gzip":   compress.Codecs.Gzip,
+	"brotli": compress.Codecs.Brotli,
+	"zstd":   compress.Codecs.Zstd,
+}
+
+var timestampTypes = map[string]arrow.DataType{
+	"ms": arrow.FixedWidthTypes.Timestamp_ms,
+	"us": arrow.FixedWidthTypes.Timestamp_us,
+	"ns": arrow.FixedWidthTypes.Timestamp_ns,
+}
+
+type Serializer struct {
+	Compression       string          `toml:"parquet_compression"`
+	RowGroupSize      int64           `toml:"parquet_row_group_size"`
+	TimestampColumn   string          `toml:"parquet_timestamp_column"`
+	TimestampUnits    string          `toml:"parquet_timestamp_units"`
+	MeasurementColumn string          `toml:"parquet_measurement_column"`
+	Log               telegraf.Logger `toml:"-"`
+
+	props         *parquet.WriterProperties
+	timestampType arrow.DataType
+}
+
+// column of the generated schema along with the source of its values
+type column struct {
+	name  string
+	isTag bool
+	dtype arrow.DataType
+}
+
+func (s *Serializer) Init() error {
+	if s.Compression == "" {
+		s.Compression = "snappy"
+	}
+	codec, found := compressionCodecs[s.Compression]
+	if !found {
+		return fmt.Errorf("invalid 'parquet_compression' %q", s.Compression)
+	}
+
+	if s.RowGroupSize < 1 {
+		s.RowGroupSize = 65536
+	}
+
+	if s.TimestampColumn == "" {
+		s.TimestampColumn = "timestamp"
+	}
+	if s.TimestampUnits == "" {
+		s.TimestampUnits = "us"
+	}
+	dtype, found := timestampTypes[s.TimestampUnits]
+	if !found {
+		return fmt.Errorf("invalid 'parquet_timestamp_units' %q", s.TimestampUnits)
+	}
+	s.timestampType = dtype
+
+	if s.MeasurementColumn == s.TimestampColumn {
+		return fmt.Errorf("measurement and timestamp column share the name %q", s.TimestampColumn)
+	}
+
+	// Statistics are enabled by default and allow query engines such as
+	// Athena to skip row groups based on the min/max values of the columns
+	s.props = parquet.NewWriterProperties(
+		parquet.WithCompression(codec),
+		parquet.WithMaxRowGroupLength(s.RowGroupSize),
+		parquet.WithCreatedBy("telegraf"),
+	)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch produces a complete parquet file containing all metrics
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	// Order the rows by time to get tight timestamp ranges per row group
+	sorted := slices.Clone(metrics)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time().Before(sorted[j].Time())
+	})
+
+	columns := s.columns(sorted)
+	fields := make([]arrow.Field, 0, len(columns)+2)
+	fields = append(fields, arrow.Field{Name: s.TimestampColumn, Type: s.timestampType})
+	if s.MeasurementColumn != "" {
+		fields = append(fields, arrow.Field{Name: s.MeasurementColumn, Type: arrow.BinaryTypes.String})
+	}
+	for _, c := range columns {
+		fields = append(fields, arrow.Field{Name: c.name, Type: c.dtype, Nullable: true})
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	offset := 0
+	tsBuilder := builder.Field(offset).(*array.TimestampBuilder)
+	for _, m := range sorted {
+		ts, err := arrow.TimestampFromTime(m.Time(), s.timestampType.(*arrow.TimestampType).Unit)
+		if err != nil {
+			return nil, fmt.Errorf("converting timestamp failed: %w", err)
+		}
+		tsBuilder.Append(ts)
+	}
+	offset++
+
+	if s.MeasurementColumn != "" {
+		nameBuilder := builder.Field(offset).(*array.StringBuilder)
+		for _, m := range sorted {
+			nameBuilder.Append(m.Name())
+		}
+		offset++
+	}
+
+	for i, c := range columns {
+		fb := builder.Field(offset + i)
+		for _, m := range sorted {
+			var value interface{}
+			var found bool
+			if c.isTag {
+				value, found = m.GetTag(c.name)
+			} else {
+				value, found = m.GetField(c.name)
+			}
+			if !found {
+				fb.AppendNull()
+				continue
+			}
+			if err := appendValue(fb, value); err != nil {
+				return nil, fmt.Errorf("converting column %q failed: %w", c.name, err)
+			}
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer, err := pqarrow.NewFileWriter(schema, &buf, s.props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("creating writer failed: %w", err)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, fmt.Errorf("writing record failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("closing writer failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// columns determines the tag and field columns of the batch in alphabetical
+// order. Fields of conflicting types are stored as float if all types are
+// numeric and as string otherwise. Tags sharing a name with a field or one of
+// the fixed columns are dropped.
+func (s *Serializer) columns(metrics []telegraf.Metric) []column {
+	fieldTypes := make(map[string]arrow.DataType)
+	tags := make(map[string]bool)
+	for _, m := range metrics {
+		for _, field := range m.FieldList() {
+			fieldTypes[field.Key] = mergeType(fieldTypes[field.Key], arrowType(field.Value))
+		}
+		for _, tag := range m.TagList() {
+			tags[tag.Key] = true
+		}
+	}
+
+	columns := make([]column, 0, len(fieldTypes)+len(tags))
+	for name, dtype := range fieldTypes {
+		if name == s.TimestampColumn || name == s.MeasurementColumn {
+			s.Log.Debugf("Dropping field %q conflicting with fixed column", name)
+			continue
+		}
+		columns = append(columns, column{name: name, dtype: dtype})
+	}
+	for name := range tags {
+		if _, found := fieldTypes[name]; found || name == s.TimestampColumn || name == s.MeasurementColumn {
+			s.Log.Debugf("Dropping tag %q conflicting with another column", name)
+			continue
+		}
+		columns = append(columns, column{name: name, isTag: true, dtype: arrow.BinaryTypes.String})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].name < columns[j].name
+	})
+
+	return columns
+}
+
+func arrowType(value interface{}) arrow.DataType {
+	switch value.(type) {
+	case int64:
+		return arrow.PrimitiveTypes.Int64
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case float64:
+		return arrow.PrimitiveTypes.Float64
+	case bool:
+		return arrow.FixedWidthTypes.Boolean
+	}
+	return arrow.BinaryTypes.String
+}
+
+func mergeType(current, dtype arrow.DataType) arrow.DataType {
+	if current == nil || arrow.TypeEqual(current, dtype) {
+		return dtype
+	}
+	if isNumeric(current) && isNumeric(dtype) {
+		return arrow.PrimitiveTypes.Float64
+	}
+	return arrow.BinaryTypes.String
+}
+
+func isNumeric(dtype arrow.DataType) bool {
+	switch dtype.ID() {
+	case arrow.INT64, arrow.UINT64, arrow.FLOAT64:
+		return true
+	}
+	return false
+}
+
+func appendValue(b array.Builder, value interface{}) error {
+	switch b := b.(type) {
+	case *array.Int64Builder:
+		v, err := internal.ToInt64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.Uint64Builder:
+		v, err := internal.ToUint64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.Float64Builder:
+		v, err := internal.ToFloat64(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.BooleanBuilder:
+		v, err := internal.ToBool(value)
+		if err != nil {
+			return err
+		}
+		b.Append(v)
+	case *array.StringBuilder:
+		v, err := internal.ToString(value)
+		if err != nil {
+			return err
+		}
+		b.Append(strings.ToValidUTF8(v, "�"))
+	default:
+		return fmt.Errorf("unsupported builder type %T", b)
+	}
+	return nil
+}
+
+func init() {
+	serializers.Add("parquet",
+		func() serializers.Serializer {
+			return &Serializer{MeasurementColumn: "measurement"}
+		},
+	)
+}
@@ -0,0 +1,168 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v18/arrow"
+	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/arrow/memory"
+	"github.com/apache/arrow/go/v18/parquet/compress"
+	"github.com/apache/arrow/go/v18/parquet/file"
+	"github.com/apache/arrow/go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{
+				"usage_idle": float64(97.1),
+				"count":      int64(3),
+			},
+			time.Unix(1700000010, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"usage_idle": float64(98.2),
+				"usage_user": float64(1.5),
+				"count":      uint64(2),
+				"online":     true,
+			},
+			time.Unix(1700000000, 0),
+		),
+	}
+
+	serializer := &Serializer{
+		MeasurementColumn: "measurement",
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	defer table.Release()
+
+	expectedColumns := []string{"timestamp", "measurement", "count", "host", "online", "usage_idle", "usage_user"}
+	actualColumns := make([]string, 0, len(table.Schema().Fields()))
+	for _, f := range table.Schema().Fields() {
+		actualColumns = append(actualColumns, f.Name)
+	}
+	require.Equal(t, expectedColumns, actualColumns)
+	require.EqualValues(t, 2, table.NumRows())
+
+	// Mixed integer types are stored as float
+	require.Equal(t, arrow.PrimitiveTypes.Float64, table.Schema().Field(2).Type)
+	require.Equal(t, arrow.TIMESTAMP, table.Schema().Field(0).Type.ID())
+
+	// Rows are ordered by time
+	ts := table.Column(0).Data().Chunk(0).(*array.Timestamp)
+	require.Equal(t, arrow.Timestamp(1700000000_000000), ts.Value(0))
+	require.Equal(t, arrow.Timestamp(1700000010_000000), ts.Value(1))
+
+	hosts := table.Column(3).Data().Chunk(0).(*array.String)
+	require.Equal(t, "a", hosts.Value(0))
+	require.Equal(t, "b", hosts.Value(1))
+
+	counts := table.Column(2).Data().Chunk(0).(*array.Float64)
+	require.InDelta(t, 2.0, counts.Value(0), 1e-9)
+	require.InDelta(t, 3.0, counts.Value(1), 1e-9)
+
+	online := table.Column(4).Data().Chunk(0).(*array.Boolean)
+	require.True(t, online.Value(0))
+	require.True(t, online.IsNull(1))
+
+	user := table.Column(6).Data().Chunk(0).(*array.Float64)
+	require.InDelta(t, 1.5, user.Value(0), 1e-9)
+	require.True(t, user.IsNull(1))
+}
+
+func TestRowGroupsAndCompression(t *testing.T) {
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		metrics = append(metrics, metric.New(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{"used": int64(i)},
+			time.Unix(int64(1700000000+i), 0),
+		))
+	}
+
+	serializer := &Serializer{
+		Compression:  "zstd",
+		RowGroupSize: 2,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.EqualValues(t, 5, reader.NumRows())
+	require.Equal(t, 3, reader.NumRowGroups())
+	require.Equal(t, 2, reader.MetaData().Schema.NumColumns())
+
+	chunk, err := reader.MetaData().RowGroup(0).ColumnChunk(1)
+	require.NoError(t, err)
+	require.Equal(t, compress.Codecs.Zstd, chunk.Compression())
+
+	// Statistics allow to skip row groups when querying
+	stats, err := chunk.Statistics()
+	require.NoError(t, err)
+	require.True(t, stats.HasMinMax())
+}
+
+func TestSerializeEmpty(t *testing.T) {
+	serializer := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, serializer.Init())
+
+	buf, err := serializer.SerializeBatch(nil)
+	require.NoError(t, err)
+	require.Empty(t, buf)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		expected   string
+	}{
+		{
+			name:       "compression",
+			serializer: &Serializer{Compression: "lzo"},
+			expected:   "invalid 'parquet_compression'",
+		},
+		{
+			name:       "timestamp units",
+			serializer: &Serializer{TimestampUnits: "s"},
+			expected:   "invalid 'parquet_timestamp_units'",
+		},
+		{
+			name:       "column conflict",
+			serializer: &Serializer{TimestampColumn: "time", MeasurementColumn: "time"},
+			expected:   "share the name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.serializer.Init(), tt.expected)
+		})
+	}
+}
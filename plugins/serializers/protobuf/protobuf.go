@@ -0,0 +1,312 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// Range of valid field numbers excluding the numbers reserved by Protobuf
+const (
+	maxFieldNumber     = 536870911
+	reservedFieldStart = 19000
+	reservedFieldCount = 1000
+)
+
+type Serializer struct {
+	SchemaRegistry        string          `toml:"protobuf_schema_registry"`
+	SchemaRegistryCert    string          `toml:"protobuf_schema_registry_cert"`
+	SchemaRegistryTimeout config.Duration `toml:"protobuf_schema_registry_timeout"`
+	Subject               string          `toml:"protobuf_schema_subject"`
+	Package               string          `toml:"protobuf_schema_package"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	registry *schemaregistry.Client
+	schemas  map[string]*schema
+}
+
+// schema derived from the shape of a metric
+type schema struct {
+	id        int
+	message   protoreflect.MessageDescriptor
+	timestamp protoreflect.FieldDescriptor
+	tags      protoreflect.FieldDescriptor
+	fields    protoreflect.FieldDescriptor
+	tagKeys   map[string]protoreflect.FieldDescriptor
+	fieldKeys map[string]protoreflect.FieldDescriptor
+}
+
+func (s *Serializer) Init() error {
+	if s.SchemaRegistry == "" {
+		return errors.New("'protobuf_schema_registry' is required")
+	}
+
+	if s.Package == "" {
+		s.Package = "telegraf"
+	}
+	for _, part := range strings.Split(s.Package, ".") {
+		if part == "" || schemaregistry.Sanitize(part) != part {
+			return fmt.Errorf("invalid 'protobuf_schema_package' %q", s.Package)
+		}
+	}
+
+	if s.SchemaRegistryTimeout <= 0 {
+		s.SchemaRegistryTimeout = config.Duration(5 * time.Second)
+	}
+
+	registry, err := schemaregistry.NewClient(s.SchemaRegistry, s.SchemaRegistryCert, time.Duration(s.SchemaRegistryTimeout))
+	if err != nil {
+		return fmt.Errorf("creating schema registry client failed: %w", err)
+	}
+	s.registry = registry
+	s.schemas = make(map[string]*schema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	sc, err := s.schema(m)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(sc.message)
+	msg.Set(sc.timestamp, protoreflect.ValueOfInt64(m.Time().UnixNano()))
+
+	tags := msg.Mutable(sc.tags).Message()
+	for _, tag := range m.TagList() {
+		tags.Set(sc.tagKeys[tag.Key], protoreflect.ValueOfString(tag.Value))
+	}
+
+	fields := msg.Mutable(sc.fields).Message()
+	for _, field := range m.FieldList() {
+		fields.Set(sc.fieldKeys[field.Key], protoreflect.ValueOf(field.Value))
+	}
+
+	// The message index array only contains the first message of the schema
+	// which is encoded as a single zero byte
+	buf := schemaregistry.AppendHeader(make([]byte, 0, 64), sc.id)
+	buf = append(buf, 0)
+	buf, err = proto.MarshalOptions{Deterministic: true}.MarshalAppend(buf, msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding metric %q failed: %w", m.Name(), err)
+	}
+	return buf, nil
+}
+
+func (*Serializer) SerializeBatch([]telegraf.Metric) ([]byte, error) {
+	return nil, errors.New("batch serialization is not supported, messages must contain a single metric")
+}
+
+// schema returns the schema for the metric shape, deriving and registering
+// the schema if necessary
+func (s *Serializer) schema(m telegraf.Metric) (*schema, error) {
+	// Fields are ordered by insertion so sort them to get a stable schema
+	fieldList := slices.SortedFunc(slices.Values(m.FieldList()), func(a, b *telegraf.Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	var key strings.Builder
+	key.WriteString(m.Name())
+	for _, tag := range m.TagList() {
+		key.WriteString("\x00t" + tag.Key)
+	}
+	for _, field := range fieldList {
+		key.WriteString("\x00f" + protoType(field.Value).String() + ":" + field.Key)
+	}
+	if sc, found := s.schemas[key.String()]; found {
+		return sc, nil
+	}
+
+	name := schemaregistry.Sanitize(m.Name())
+
+	tagsMsg := &descriptorpb.DescriptorProto{Name: proto.String("Tags")}
+	tagNames := make(map[string]string, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		fd, err := newField(tagsMsg, tag.Key, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q of metric %q: %w", tag.Key, m.Name(), err)
+		}
+		tagNames[tag.Key] = fd.GetName()
+	}
+
+	fieldsMsg := &descriptorpb.DescriptorProto{Name: proto.String("Fields")}
+	fieldNames := make(map[string]string, len(fieldList))
+	for _, field := range fieldList {
+		t := protoType(field.Value)
+		if t == 0 {
+			return nil, fmt.Errorf("unsupported type %T of field %q", field.Value, field.Key)
+		}
+		fd, err := newField(fieldsMsg, field.Key, t)
+		if err != nil {
+			return nil, fmt.Errorf("field %q of metric %q: %w", field.Key, m.Name(), err)
+		}
+		fieldNames[field.Key] = fd.GetName()
+	}
+
+	prefix := "." + s.Package + "." + name + "."
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(name + ".proto"),
+		Package: proto.String(s.Package),
+		Syntax:  proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String(name),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   proto.String("timestamp"),
+						Number: proto.Int32(1),
+						Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+					},
+					{
+						Name:     proto.String("tags"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(prefix + "Tags"),
+					},
+					{
+						Name:     proto.String("fields"),
+						Number:   proto.Int32(3),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(prefix + "Fields"),
+					},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{tagsMsg, fieldsMsg},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating schema for metric %q failed: %w", m.Name(), err)
+	}
+
+	msg := fd.Messages().Get(0)
+	sc := &schema{
+		message:   msg,
+		timestamp: msg.Fields().ByName("timestamp"),
+		tags:      msg.Fields().ByName("tags"),
+		fields:    msg.Fields().ByName("fields"),
+		tagKeys:   make(map[string]protoreflect.FieldDescriptor, len(tagNames)),
+		fieldKeys: make(map[string]protoreflect.FieldDescriptor, len(fieldNames)),
+	}
+	for k, n := range tagNames {
+		sc.tagKeys[k] = sc.tags.Message().Fields().ByName(protoreflect.Name(n))
+	}
+	for k, n := range fieldNames {
+		sc.fieldKeys[k] = sc.fields.Message().Fields().ByName(protoreflect.Name(n))
+	}
+
+	// Use the record-name strategy if no subject is given
+	subject := s.Subject
+	if subject == "" {
+		subject = s.Package + "." + name
+	}
+	id, err := s.registry.Register(subject, schemaregistry.TypeProtobuf, format(file))
+	if err != nil {
+		return nil, err
+	}
+	sc.id = id
+	s.schemas[key.String()] = sc
+
+	return sc, nil
+}
+
+// newField adds an optional field to the message. The field number is derived
+// from the name to keep the numbers stable across schema versions and restarts
+// as long as no hash collisions occur.
+func newField(msg *descriptorpb.DescriptorProto, key string, t descriptorpb.FieldDescriptorProto_Type) (*descriptorpb.FieldDescriptorProto, error) {
+	name := schemaregistry.Sanitize(key)
+
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	number := int32(h.Sum32()%(maxFieldNumber-reservedFieldCount)) + 1
+	if number >= reservedFieldStart {
+		number += reservedFieldCount
+	}
+
+	for _, existing := range msg.Field {
+		if existing.GetName() == name {
+			return nil, errors.New("collides with another key after sanitizing")
+		}
+		if existing.GetNumber() == number {
+			return nil, fmt.Errorf("field number collides with %q", existing.GetName())
+		}
+	}
+
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   t.Enum(),
+	}
+	msg.Field = append(msg.Field, fd)
+	return fd, nil
+}
+
+func protoType(value interface{}) descriptorpb.FieldDescriptorProto_Type {
+	switch value.(type) {
+	case float64:
+		return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	case int64:
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case uint64:
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT64
+	case string:
+		return descriptorpb.FieldDescriptorProto_TYPE_STRING
+	case bool:
+		return descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	}
+	return 0
+}
+
+// format the file descriptor as Protobuf schema definition as expected by the
+// schema registry
+func format(file *descriptorpb.FileDescriptorProto) string {
+	var b strings.Builder
+	b.WriteString("syntax = \"proto2\";\n")
+	b.WriteString("package " + file.GetPackage() + ";\n")
+	for _, msg := range file.GetMessageType() {
+		formatMessage(&b, msg, "")
+	}
+	return b.String()
+}
+
+func formatMessage(b *strings.Builder, msg *descriptorpb.DescriptorProto, indent string) {
+	b.WriteString("\n" + indent + "message " + msg.GetName() + " {\n")
+	for _, f := range msg.GetField() {
+		typeName := strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+		if f.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			typeName = f.GetTypeName()
+		}
+		fmt.Fprintf(b, "%s  optional %s %s = %d;\n", indent, typeName, f.GetName(), f.GetNumber())
+	}
+	for _, nested := range msg.GetNestedType() {
+		formatMessage(b, nested, indent+"  ")
+	}
+	b.WriteString(indent + "}\n")
+}
+
+func init() {
+	serializers.Add("protobuf",
+		func() serializers.Serializer {
+			return &Serializer{}
+		},
+	)
+}
@@ -0,0 +1,138 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+type registry struct {
+	subjects []string
+	schemas  []string
+	sync.Mutex
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.SchemaType != "PROTOBUF" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.subjects = append(r.subjects, req.URL.Path)
+	r.schemas = append(r.schemas, body.Schema)
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(len(r.schemas)) + `}`))
+}
+
+func TestInitInvalid(t *testing.T) {
+	s := &Serializer{}
+	require.ErrorContains(t, s.Init(), "'protobuf_schema_registry' is required")
+
+	s = &Serializer{SchemaRegistry: "http://localhost:8081", Package: "my-package"}
+	require.ErrorContains(t, s.Init(), "invalid 'protobuf_schema_package'")
+}
+
+func TestSerialize(t *testing.T) {
+	reg := &registry{}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{SchemaRegistry: server.URL, Package: "com.example"}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01", "cpu-id": "0"},
+		map[string]interface{}{
+			"idle":    98.5,
+			"count":   int64(-3),
+			"total":   uint64(42),
+			"state":   "ok",
+			"healthy": true,
+		},
+		time.Unix(1700000000, 123456789),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	// Serializing a metric of the same shape must not register the schema again
+	_, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/subjects/com.example.cpu/versions"}, reg.subjects)
+	require.Contains(t, reg.schemas[0], "syntax = \"proto2\";\npackage com.example;\n\nmessage cpu {\n")
+	require.Contains(t, reg.schemas[0], "  optional int64 timestamp = 1;\n")
+	require.Contains(t, reg.schemas[0], "  optional .com.example.cpu.Tags tags = 2;\n")
+	require.Contains(t, reg.schemas[0], "\n  message Fields {\n")
+
+	// Check the wire-format header including the message index
+	require.Equal(t, byte(0), buf[0])
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+	require.Equal(t, byte(0), buf[5])
+
+	sc := s.schemas[`cpu`+"\x00tcpu-id\x00thost"+
+		"\x00fTYPE_INT64:count\x00fTYPE_BOOL:healthy\x00fTYPE_DOUBLE:idle\x00fTYPE_STRING:state\x00fTYPE_UINT64:total"]
+	require.NotNil(t, sc)
+	msg := dynamicpb.NewMessage(sc.message)
+	require.NoError(t, proto.Unmarshal(buf[6:], msg))
+
+	get := func(m protoreflect.Message, name string) interface{} {
+		return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name))).Interface()
+	}
+	require.Equal(t, int64(1700000000123456789), get(msg, "timestamp"))
+
+	tags := msg.Get(sc.tags).Message()
+	require.Equal(t, "server01", get(tags, "host"))
+	require.Equal(t, "0", get(tags, "cpu_id"))
+
+	fields := msg.Get(sc.fields).Message()
+	require.InDelta(t, 98.5, get(fields, "idle"), 1e-9)
+	require.Equal(t, int64(-3), get(fields, "count"))
+	require.Equal(t, uint64(42), get(fields, "total"))
+	require.Equal(t, "ok", get(fields, "state"))
+	require.Equal(t, true, get(fields, "healthy"))
+}
+
+func TestFieldNumbersStable(t *testing.T) {
+	reg := &registry{}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{SchemaRegistry: server.URL, Subject: "metrics-value"}
+	require.NoError(t, s.Init())
+
+	m1 := metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 1.0}, time.Unix(0, 0))
+	_, err := s.Serialize(m1)
+	require.NoError(t, err)
+
+	m2 := metric.New("cpu", map[string]string{}, map[string]interface{}{"busy": 1.0, "idle": 1.0}, time.Unix(0, 0))
+	_, err = s.Serialize(m2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/subjects/metrics-value/versions", "/subjects/metrics-value/versions"}, reg.subjects)
+
+	// Adding a field must not change the number of existing fields
+	var numbers []protoreflect.FieldNumber
+	for _, sc := range s.schemas {
+		numbers = append(numbers, sc.fields.Message().Fields().ByName("idle").Number())
+	}
+	require.Len(t, numbers, 2)
+	require.Equal(t, numbers[0], numbers[1])
+	require.True(t, numbers[0] < reservedFieldStart || numbers[0] >= reservedFieldStart+reservedFieldCount)
+}